@@ -0,0 +1,409 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// Capabilities are the capabilities of a file system detected by ProbeCapabilities.
+type Capabilities struct {
+	Type     string        `json:"type"`     // Type is the type of the file system.
+	OSType   string        `json:"osType"`   // OSType is the operating system type of the file system.
+	Features []string      `json:"features"` // Features are the names of the features of the file system.
+	Probes   []ProbeResult `json:"probes"`   // Probes are the results of the probes.
+}
+
+// ProbeResult is the result of a single probe.
+type ProbeResult struct {
+	Name      string `json:"name"`             // Name is the name of the probe.
+	Supported bool   `json:"supported"`        // Supported is true if the file system passed the probe.
+	Detail    string `json:"detail,omitempty"` // Detail explains why the probe failed.
+}
+
+// probe is a small test of a file system behavior run in its own directory.
+type probe struct {
+	name string
+	fn   func(vfs VFSBase, dir string) error
+}
+
+// probes are the probes run by ProbeCapabilities.
+var probes = []probe{ //nolint:gochecknoglobals // probes is the list of capability probes.
+	{name: "Hardlink", fn: probeHardlink},
+	{name: "Symlink", fn: probeSymlink},
+	{name: "Chmod", fn: probeChmod},
+	{name: "Chtimes", fn: probeChtimes},
+	{name: "UMask", fn: probeUMask},
+	{name: "CaseSensitive", fn: probeCaseSensitive},
+	{name: "RemoveOpenFile", fn: probeRemoveOpenFile},
+	{name: "RenameReplace", fn: probeRenameReplace},
+	{name: "SparseWrite", fn: probeSparseWrite},
+	{name: "ErrNotExist", fn: probeErrNotExist},
+	{name: "ErrExist", fn: probeErrExist},
+	{name: "ErrNotDir", fn: probeErrNotDir},
+}
+
+// ProbeCapabilities runs small probes against the file system vfs in a temporary directory created in dir
+// and returns the detected capabilities. The probes need a writable file system.
+func ProbeCapabilities(vfs VFSBase, dir string) (*Capabilities, error) {
+	caps := &Capabilities{
+		Type:   vfs.Type(),
+		OSType: vfs.OSType().String(),
+	}
+
+	for feature := FeatHardlink; feature <= FeatStrict; feature <<= 1 {
+		if vfs.HasFeature(feature) {
+			caps.Features = append(caps.Features, featureName(feature))
+		}
+	}
+
+	probeDir, err := vfs.MkdirTemp(dir, "probe")
+	if err != nil {
+		return nil, err
+	}
+
+	defer vfs.RemoveAll(probeDir) //nolint:errcheck // Ignore errors.
+
+	for i, p := range probes {
+		testDir := vfs.Join(probeDir, fmt.Sprintf("%02d%s", i, p.name))
+
+		err = vfs.Mkdir(testDir, DefaultDirPerm)
+		if err != nil {
+			return nil, err
+		}
+
+		pr := ProbeResult{Name: p.name, Supported: true}
+
+		err = p.fn(vfs, testDir)
+		if err != nil {
+			pr.Supported = false
+			pr.Detail = err.Error()
+		}
+
+		caps.Probes = append(caps.Probes, pr)
+	}
+
+	return caps, nil
+}
+
+// CapabilitiesMarkdown returns a markdown table comparing the capabilities of file systems,
+// with one column per file system and one row per feature or probe.
+func CapabilitiesMarkdown(caps ...*Capabilities) string {
+	var sb strings.Builder
+
+	row := func(name string, cell func(c *Capabilities) string) {
+		sb.WriteString(name)
+
+		for _, c := range caps {
+			sb.WriteString("|")
+			sb.WriteString(cell(c))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	row("Capability", func(c *Capabilities) string { return c.Type })
+	row(strings.Repeat("-", len("Capability")), func(c *Capabilities) string { return strings.Repeat("-", len(c.Type)) })
+	row("OSType", func(c *Capabilities) string { return c.OSType })
+
+	for feature := FeatHardlink; feature <= FeatStrict; feature <<= 1 {
+		name := featureName(feature)
+
+		row("Feat"+name, func(c *Capabilities) string {
+			for _, f := range c.Features {
+				if f == name {
+					return "yes"
+				}
+			}
+
+			return "no"
+		})
+	}
+
+	if len(caps) == 0 {
+		return sb.String()
+	}
+
+	for i, p := range caps[0].Probes {
+		row(p.Name, func(c *Capabilities) string {
+			if i >= len(c.Probes) || !c.Probes[i].Supported {
+				return "no"
+			}
+
+			return "yes"
+		})
+	}
+
+	return sb.String()
+}
+
+// featureName returns the name of a single feature without the "Feat" prefix.
+func featureName(feature Features) string {
+	return strings.TrimSuffix(strings.TrimPrefix(feature.String(), "Features("), ")")
+}
+
+// errProbe is the error returned by a probe when the file system does not behave as expected.
+type errProbe string
+
+func (e errProbe) Error() string {
+	return string(e)
+}
+
+// probeHardlink checks that hard links can be created.
+func probeHardlink(vfs VFSBase, dir string) error {
+	file := vfs.Join(dir, "file")
+
+	err := vfs.WriteFile(file, nil, DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	return vfs.Link(file, vfs.Join(dir, "link"))
+}
+
+// probeSymlink checks that symbolic links can be created and read.
+func probeSymlink(vfs VFSBase, dir string) error {
+	link := vfs.Join(dir, "link")
+
+	err := vfs.Symlink("target", link)
+	if err != nil {
+		return err
+	}
+
+	target, err := vfs.Readlink(link)
+	if err != nil {
+		return err
+	}
+
+	if target != "target" {
+		return errProbe("Readlink returns " + target)
+	}
+
+	return nil
+}
+
+// probeChmod checks that the permission bits set by Chmod are returned by Stat.
+func probeChmod(vfs VFSBase, dir string) error {
+	const mode = fs.FileMode(0o640)
+
+	file := vfs.Join(dir, "file")
+
+	err := vfs.WriteFile(file, nil, DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	err = vfs.Chmod(file, mode)
+	if err != nil {
+		return err
+	}
+
+	info, err := vfs.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Perm() != mode {
+		return errProbe(fmt.Sprintf("Stat returns mode %s", info.Mode().Perm()))
+	}
+
+	return nil
+}
+
+// probeChtimes checks that the modification time set by Chtimes is returned by Stat.
+func probeChtimes(vfs VFSBase, dir string) error {
+	file := vfs.Join(dir, "file")
+	mtime := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	err := vfs.WriteFile(file, nil, DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	err = vfs.Chtimes(file, mtime, mtime)
+	if err != nil {
+		return err
+	}
+
+	info, err := vfs.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if !info.ModTime().Equal(mtime) {
+		return errProbe("Stat returns modification time " + info.ModTime().String())
+	}
+
+	return nil
+}
+
+// probeUMask checks that the file mode creation mask is applied to new files.
+func probeUMask(vfs VFSBase, dir string) error {
+	file := vfs.Join(dir, "file")
+
+	err := vfs.WriteFile(file, nil, 0o777)
+	if err != nil {
+		return err
+	}
+
+	info, err := vfs.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if want := 0o777 &^ vfs.UMask(); info.Mode().Perm() != want {
+		return errProbe(fmt.Sprintf("Stat returns mode %s, want %s", info.Mode().Perm(), want))
+	}
+
+	return nil
+}
+
+// probeCaseSensitive checks that file names differing only by case are distinct files.
+func probeCaseSensitive(vfs VFSBase, dir string) error {
+	err := vfs.WriteFile(vfs.Join(dir, "file"), nil, DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	_, err = vfs.Stat(vfs.Join(dir, "FILE"))
+	if err == nil {
+		return errProbe("FILE and file are the same file")
+	}
+
+	return nil
+}
+
+// probeRemoveOpenFile checks that an open file can be removed.
+func probeRemoveOpenFile(vfs VFSBase, dir string) error {
+	file := vfs.Join(dir, "file")
+
+	f, err := vfs.Create(file)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return vfs.Remove(file)
+}
+
+// probeRenameReplace checks that Rename replaces an existing file.
+func probeRenameReplace(vfs VFSBase, dir string) error {
+	oldPath := vfs.Join(dir, "old")
+	newPath := vfs.Join(dir, "new")
+
+	err := vfs.WriteFile(oldPath, []byte("old"), DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	err = vfs.WriteFile(newPath, []byte("new"), DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	return vfs.Rename(oldPath, newPath)
+}
+
+// probeSparseWrite checks that writing beyond the end of a file fills the gap with zeros.
+func probeSparseWrite(vfs VFSBase, dir string) error {
+	file := vfs.Join(dir, "file")
+
+	f, err := vfs.Create(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteAt([]byte{1}, 3)
+	if err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	buf, err := vfs.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(buf, []byte{0, 0, 0, 1}) {
+		return errProbe(fmt.Sprintf("ReadFile returns %v", buf))
+	}
+
+	return nil
+}
+
+// probeErrNotExist checks that opening a non existing file returns a *PathError wrapping fs.ErrNotExist.
+func probeErrNotExist(vfs VFSBase, dir string) error {
+	_, err := vfs.OpenFile(vfs.Join(dir, "missing"), os.O_RDONLY, 0)
+
+	return checkProbeErr(err, fs.ErrNotExist)
+}
+
+// probeErrExist checks that creating an existing directory returns a *PathError wrapping fs.ErrExist.
+func probeErrExist(vfs VFSBase, dir string) error {
+	err := vfs.Mkdir(dir, DefaultDirPerm)
+
+	return checkProbeErr(err, fs.ErrExist)
+}
+
+// probeErrNotDir checks that using a file as a directory returns a *PathError wrapping ErrNotADirectory
+// (ENOENT on Windows).
+func probeErrNotDir(vfs VFSBase, dir string) error {
+	file := vfs.Join(dir, "file")
+
+	err := vfs.WriteFile(file, nil, DefaultFilePerm)
+	if err != nil {
+		return err
+	}
+
+	_, err = vfs.Stat(vfs.Join(file, "child"))
+
+	if vfs.OSType() == OsWindows {
+		return checkProbeErr(err, fs.ErrNotExist)
+	}
+
+	return checkProbeErr(err, ErrNotADirectory)
+}
+
+// checkProbeErr checks that err is a *PathError wrapping target.
+// Errors from the OS and emulated errors are considered equivalent if their messages are identical.
+func checkProbeErr(err, target error) error {
+	var pe *fs.PathError
+
+	switch {
+	case err == nil:
+		return errProbe("no error returned")
+	case !errors.As(err, &pe):
+		return errProbe(fmt.Sprintf("error %T is not a *PathError", err))
+	case !errors.Is(err, target) && pe.Err.Error() != target.Error():
+		return errProbe(fmt.Sprintf("error %v is not %v", err, target))
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/orefafs"
+	"github.com/avfs/avfs/vfs/osfs"
+)
+
+// TestProbeCapabilities tests ProbeCapabilities and CapabilitiesMarkdown functions.
+func TestProbeCapabilities(t *testing.T) {
+	var allCaps []*avfs.Capabilities
+
+	for _, vfs := range []avfs.VFSBase{memfs.New(), orefafs.New(), osfs.New()} {
+		caps, err := avfs.ProbeCapabilities(vfs, vfs.TempDir())
+		if err != nil {
+			t.Fatalf("ProbeCapabilities %s : want error to be nil, got %v", vfs.Type(), err)
+		}
+
+		if caps.Type != vfs.Type() {
+			t.Errorf("ProbeCapabilities : want Type to be %s, got %s", vfs.Type(), caps.Type)
+		}
+
+		probes := make(map[string]avfs.ProbeResult, len(caps.Probes))
+		for _, pr := range caps.Probes {
+			probes[pr.Name] = pr
+		}
+
+		for _, name := range []string{"ErrNotExist", "ErrExist", "ErrNotDir", "RenameReplace", "SparseWrite"} {
+			if pr := probes[name]; !pr.Supported {
+				t.Errorf("ProbeCapabilities %s : want probe %s to be supported, got %s", vfs.Type(), name, pr.Detail)
+			}
+		}
+
+		if got, want := probes["Symlink"].Supported, vfs.HasFeature(avfs.FeatSymlink); got != want {
+			t.Errorf("ProbeCapabilities %s : want Symlink probe to be %t, got %t", vfs.Type(), want, got)
+		}
+
+		if got, want := probes["Hardlink"].Supported, vfs.HasFeature(avfs.FeatHardlink); got != want {
+			t.Errorf("ProbeCapabilities %s : want Hardlink probe to be %t, got %t", vfs.Type(), want, got)
+		}
+
+		buf, err := json.Marshal(caps)
+		if err != nil {
+			t.Fatalf("Marshal : want error to be nil, got %v", err)
+		}
+
+		var gotCaps avfs.Capabilities
+
+		err = json.Unmarshal(buf, &gotCaps)
+		if err != nil || len(gotCaps.Probes) != len(caps.Probes) {
+			t.Errorf("Unmarshal : want %d probes, got %d, %v", len(caps.Probes), len(gotCaps.Probes), err)
+		}
+
+		allCaps = append(allCaps, caps)
+	}
+
+	md := avfs.CapabilitiesMarkdown(allCaps...)
+
+	lines := strings.Split(strings.TrimSpace(md), "\n")
+	if lines[0] != "Capability|MemFS|OrefaFS|OsFS" {
+		t.Errorf("CapabilitiesMarkdown : want header to be %s, got %s", "Capability|MemFS|OrefaFS|OsFS", lines[0])
+	}
+
+	for _, line := range lines {
+		if n := strings.Count(line, "|"); n != len(allCaps) {
+			t.Errorf("CapabilitiesMarkdown : want %d columns, got %d in %s", len(allCaps)+1, n+1, line)
+		}
+	}
+}
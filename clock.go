@@ -0,0 +1,121 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock is the interface that wraps the Now method.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// Clocker is the interface that wraps the SetClock method.
+type Clocker interface {
+	// SetClock sets the clock used for the timestamps of the files and for the names of the temporary files.
+	// A nil clock restores the system clock.
+	SetClock(clock Clock)
+}
+
+// ClockFn provides a clock to a file system.
+type ClockFn struct {
+	clock    Clock     // clock is the clock set by SetClock, nil to use the system clock.
+	tempRand *tempRand // tempRand is a source of random numbers seeded by the clock for the names of temporary files.
+}
+
+// Now returns the current time of the clock of the file system.
+func (cf *ClockFn) Now() time.Time {
+	if cf.clock == nil {
+		return time.Now()
+	}
+
+	return cf.clock.Now()
+}
+
+// SetClock sets the clock used for the timestamps of the files and for the names of the temporary files.
+// Unless a source of random numbers is set by SetTempRand, the names of the temporary files
+// are generated from a source seeded by the time of the clock when it is set.
+// A nil clock restores the system clock.
+// It should be called before the file system is used by concurrent goroutines.
+func (cf *ClockFn) SetClock(clock Clock) {
+	if clock == nil {
+		cf.clock = nil
+		cf.tempRand = nil
+
+		return
+	}
+
+	cf.clock = clock
+	cf.tempRand = &tempRand{src: rand.NewSource(clock.Now().UnixNano())}
+}
+
+// clockRandom returns a random string for the name of a temporary file from the source seeded by the clock,
+// ok is false if no clock is set.
+func (cf *ClockFn) clockRandom() (string, bool) {
+	if cf.tempRand == nil {
+		return "", false
+	}
+
+	return cf.tempRand.next(), true
+}
+
+// clockRandomer is the interface implemented by the file systems embedding ClockFn.
+type clockRandomer interface {
+	clockRandom() (string, bool)
+}
+
+// FakeClock is a deterministic clock, the time it returns only changes by a fixed step after each call to Now
+// or when it is set or advanced. It is safe for concurrent use.
+type FakeClock struct {
+	t    time.Time     // t is the next time returned by Now.
+	step time.Duration // step is the duration added to the time after each call to Now.
+	mu   sync.Mutex    // mu is the mutex used to access t.
+}
+
+// NewFakeClock returns a new deterministic clock starting at t and advancing by step after each call to Now.
+func NewFakeClock(t time.Time, step time.Duration) *FakeClock {
+	return &FakeClock{t: t, step: step}
+}
+
+// Advance advances the time of the clock by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+// Now returns the current time of the clock and advances it by the step of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.t
+	c.t = c.t.Add(c.step)
+
+	return t
+}
+
+// Set sets the time of the clock.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.t = t
+	c.mu.Unlock()
+}
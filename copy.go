@@ -17,10 +17,15 @@
 package avfs
 
 import (
+	"errors"
 	"hash"
 	"io"
+	"io/fs"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
 var copyPool = newCopyPool() //nolint:gochecknoglobals // copyPool is the buffer pool used to copy files.
@@ -39,14 +44,33 @@ func newCopyPool() *sync.Pool {
 }
 
 // CopyFile copies a file between file systems and returns an error if any.
+// The permissions and the access and modification times of the source file are preserved.
 func CopyFile(dstFs, srcFs VFSBase, dstPath, srcPath string) error {
-	_, err := CopyFileHash(dstFs, srcFs, dstPath, srcPath, nil)
+	_, err := copyFile(dstFs, srcFs, dstPath, srcPath, nil, &CopyOptions{})
+
+	return err
+}
+
+// CopyFileWithOptions copies a file between file systems like CopyFile with the selected options,
+// only CopyOptions.PreserveOwners applies to a single file, opts can be nil to use the default options.
+func CopyFileWithOptions(dstFs, srcFs VFSBase, dstPath, srcPath string, opts *CopyOptions) error {
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+
+	_, err := copyFile(dstFs, srcFs, dstPath, srcPath, nil, opts)
 
 	return err
 }
 
 // CopyFileHash copies a file between file systems and returns the hash sum of the source file.
 func CopyFileHash(dstFs, srcFs VFSBase, dstPath, srcPath string, hasher hash.Hash) (sum []byte, err error) {
+	return copyFile(dstFs, srcFs, dstPath, srcPath, hasher, &CopyOptions{})
+}
+
+// copyFile copies a file between file systems and its metadata, and returns the hash sum of the source file
+// if hasher is not nil.
+func copyFile(dstFs, srcFs VFSBase, dstPath, srcPath string, hasher hash.Hash, opts *CopyOptions) ([]byte, error) {
 	src, err := srcFs.OpenFile(srcPath, os.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
@@ -54,17 +78,16 @@ func CopyFileHash(dstFs, srcFs VFSBase, dstPath, srcPath string, hasher hash.Has
 
 	defer src.Close()
 
-	dst, err := dstFs.Create(dstPath)
+	// The source is stated before reading it, reading it can update its access time.
+	info, err := src.Stat()
 	if err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		cerr := dst.Close()
-		if cerr == nil {
-			err = cerr
-		}
-	}()
+	dst, err := dstFs.Create(dstPath)
+	if err != nil {
+		return nil, err
+	}
 
 	var out io.Writer
 
@@ -76,21 +99,21 @@ func CopyFileHash(dstFs, srcFs VFSBase, dstPath, srcPath string, hasher hash.Has
 	}
 
 	_, err = copyBufPool(out, src)
-	if err != nil {
-		return nil, err
+	if err == nil {
+		err = dst.Sync()
 	}
 
-	err = dst.Sync()
-	if err != nil {
-		return nil, err
+	// The file is closed before copying its metadata, some file systems update its times on close.
+	cerr := dst.Close()
+	if err == nil {
+		err = cerr
 	}
 
-	info, err := srcFs.Stat(srcPath)
 	if err != nil {
 		return nil, err
 	}
 
-	err = dstFs.Chmod(dstPath, info.Mode())
+	err = copyMetadata(dstFs, srcFs, dstPath, info, opts.PreserveOwners)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +125,421 @@ func CopyFileHash(dstFs, srcFs VFSBase, dstPath, srcPath string, hasher hash.Has
 	return hasher.Sum(nil), nil
 }
 
+// copyMetadata copies to dstPath the owner if owners is true, the permissions and the times
+// of the source file described by info.
+// The times of a symbolic link are only copied if the destination file system supports it.
+func copyMetadata(dstFs, srcFs VFSBase, dstPath string, info fs.FileInfo, owners bool) error {
+	sst := srcFs.ToSysStat(info)
+
+	if owners && dstFs.OSType() != OsWindows {
+		err := dstFs.Lchown(dstPath, sst.Uid(), sst.Gid())
+		if err != nil {
+			return err
+		}
+	}
+
+	atime := info.ModTime()
+	if at, ok := sst.(AccessTimer); ok {
+		atime = at.Atime()
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		err := Lchtimes(dstFs, dstPath, atime, info.ModTime())
+		if err != nil && !errors.Is(err, ErrNotSupported) {
+			return err
+		}
+
+		return nil
+	}
+
+	err := dstFs.Chmod(dstPath, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky))
+	if err != nil {
+		return err
+	}
+
+	return dstFs.Chtimes(dstPath, atime, info.ModTime())
+}
+
+// DefaultCopyChunkSize is the minimum size of the chunks copied by Copy when the chunk size is not set.
+const DefaultCopyChunkSize = 128 * 1024
+
+// CopyIOOptions defines the options of Copy.
+type CopyIOOptions struct {
+	// Progress is called after each chunk copied with the statistics of the copy, nil to disable.
+	// The next chunk is only read when Progress returns, it can slow down the copy to apply back-pressure.
+	// If Progress returns an error, the copy stops and Copy returns this error.
+	Progress func(stats CopyIOStats) error
+
+	// ChunkSize is the size of the chunks copied, 0 to select it from the block sizes of the files.
+	ChunkSize int
+}
+
+// CopyIOStats are the statistics of a copy made by Copy.
+type CopyIOStats struct {
+	Written   int64         // Written is the number of bytes copied.
+	Elapsed   time.Duration // Elapsed is the duration of the copy.
+	ChunkSize int           // ChunkSize is the size of the chunks copied, 0 if a fast path was used.
+	FastPath  bool          // FastPath is true if the copy used the ReadFrom or WriteTo method of the files.
+}
+
+// Throughput returns the throughput of the copy in bytes per second.
+func (cs CopyIOStats) Throughput() float64 {
+	if cs.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(cs.Written) / cs.Elapsed.Seconds()
+}
+
+// BlockSizer is the optional interface implemented by the values returned by fs.FileInfo.Sys()
+// of file systems providing the preferred block size for file system I/O (see memfs.MemInfo).
+// On Unix systems, the block size of a real file is taken from syscall.Stat_t.
+type BlockSizer interface {
+	// BlockSize returns the preferred block size for file system I/O.
+	BlockSize() int64
+}
+
+// Copy copies src to dst until either EOF is reached on src or an error occurs
+// and returns the statistics of the copy.
+// Without Progress and ChunkSize options, the ReadFrom method of dst or the WriteTo method of src are used
+// if available. Otherwise, the copy is made by chunks of opts.ChunkSize bytes, or if not set, of a multiple
+// of the largest block size of the files of at least DefaultCopyChunkSize bytes.
+// If opts is nil, the default options are used.
+func Copy(dst, src File, opts *CopyIOOptions) (stats CopyIOStats, err error) {
+	if opts == nil {
+		opts = &CopyIOOptions{}
+	}
+
+	start := time.Now()
+
+	defer func() {
+		stats.Elapsed = time.Since(start)
+	}()
+
+	if opts.Progress == nil && opts.ChunkSize <= 0 {
+		if rf, ok := dst.(io.ReaderFrom); ok {
+			stats.FastPath = true
+			stats.Written, err = rf.ReadFrom(src)
+
+			return stats, err
+		}
+
+		if wt, ok := src.(io.WriterTo); ok {
+			stats.FastPath = true
+			stats.Written, err = wt.WriteTo(dst)
+
+			return stats, err
+		}
+	}
+
+	stats.ChunkSize = opts.ChunkSize
+	if stats.ChunkSize <= 0 {
+		stats.ChunkSize = copyChunkSize(dst, src)
+	}
+
+	bufp := copyPool.Get().(*[]byte) //nolint:forcetypeassert // Get() always returns a pointer to a byte slice.
+	defer copyPool.Put(bufp)
+
+	if cap(*bufp) < stats.ChunkSize {
+		*bufp = make([]byte, stats.ChunkSize)
+	}
+
+	buf := (*bufp)[:stats.ChunkSize]
+
+	for {
+		n, rerr := src.Read(buf)
+		if n > 0 {
+			w, werr := dst.Write(buf[:n])
+			stats.Written += int64(w)
+
+			switch {
+			case werr != nil:
+				return stats, werr
+			case w != n:
+				return stats, io.ErrShortWrite
+			}
+
+			if opts.Progress != nil {
+				stats.Elapsed = time.Since(start)
+
+				if perr := opts.Progress(stats); perr != nil {
+					return stats, perr
+				}
+			}
+		}
+
+		if rerr == io.EOF {
+			return stats, nil
+		}
+
+		if rerr != nil {
+			return stats, rerr
+		}
+	}
+}
+
+// copyChunkSize returns the size of the chunks copied by Copy from the block sizes of the files.
+func copyChunkSize(files ...File) int {
+	blockSize := int64(0)
+
+	for _, f := range files {
+		info, err := f.Stat()
+		if err != nil {
+			continue
+		}
+
+		bs, ok := info.Sys().(BlockSizer)
+		if ok {
+			blockSize = max(blockSize, bs.BlockSize())
+		} else {
+			blockSize = max(blockSize, sysBlockSize(info.Sys()))
+		}
+	}
+
+	if blockSize <= 0 {
+		return DefaultCopyChunkSize
+	}
+
+	chunks := (DefaultCopyChunkSize + blockSize - 1) / blockSize
+
+	return int(chunks * blockSize)
+}
+
+// SymlinkPolicy defines how CopyDir copies symbolic links.
+type SymlinkPolicy uint8
+
+const (
+	// SymlinkCopy recreates the symbolic link itself, its target is left unchanged (default).
+	SymlinkCopy SymlinkPolicy = iota
+
+	// SymlinkFollow copies the file or the directory referenced by the symbolic link.
+	SymlinkFollow
+
+	// SymlinkSkip ignores symbolic links.
+	SymlinkSkip
+
+	// SymlinkRewrite recreates the symbolic link with its target converted to the OS type of the destination
+	// file system: separators are replaced and, for absolute targets, the volume name is removed (Windows to Linux)
+	// or set to the volume name of the destination directory (Linux to Windows).
+	SymlinkRewrite
+)
+
+// CopyOptions defines the options of CopyDir and CopyFileWithOptions.
+type CopyOptions struct {
+	Symlinks          SymlinkPolicy // Symlinks defines how symbolic links are copied.
+	PreserveHardlinks bool          // PreserveHardlinks recreates hard links between copied files instead of duplicating them.
+	PreserveOwners    bool          // PreserveOwners sets the owners of the copies to the owners of the source files (not on Windows).
+}
+
+// copyDir holds the state of a recursive copy.
+type copyDir struct {
+	dstFs     VFSBase
+	srcFs     VFSBase
+	opts      CopyOptions
+	hardlinks *hardlinks // hardlinks are the destination paths of the copied files with more than one link.
+	parents   []string   // parents are the real paths of the source directories being copied.
+}
+
+// CopyDir copies recursively the directory srcPath of srcFs to dstPath of dstFs.
+// The permissions and the access and modification times of the files are preserved,
+// the times of the symbolic links only if dstFs implements Lchtimer.
+// The directory dstPath is created if needed, opts can be nil to use the default options.
+// Like cp -r, a directory can't be copied into itself : if both file systems are the same,
+// dstPath can't be srcPath or one of its descendants.
+func CopyDir(dstFs, srcFs VFSBase, dstPath, srcPath string, opts *CopyOptions) error {
+	const op = "copydir"
+
+	cd := &copyDir{dstFs: dstFs, srcFs: srcFs, hardlinks: newHardlinks(srcFs)}
+	if opts != nil {
+		cd.opts = *opts
+	}
+
+	info, err := srcFs.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return &fs.PathError{Op: op, Path: srcPath, Err: ErrNotADirectory}
+	}
+
+	inside, err := cd.isInsideSrc(dstPath, srcPath)
+	if err != nil {
+		return err
+	}
+
+	if inside {
+		return &fs.PathError{Op: op, Path: dstPath, Err: ErrInvalidArgument}
+	}
+
+	return cd.copyDir(dstPath, srcPath, info)
+}
+
+// isInsideSrc returns true if the destination directory dstPath is the source directory srcPath
+// or one of its descendants, the paths being resolved through their symbolic links.
+// It always returns false if the file systems are different, real file systems being the same.
+func (cd *copyDir) isInsideSrc(dstPath, srcPath string) (bool, error) {
+	realFS := cd.dstFs.HasFeature(FeatRealFS) && cd.srcFs.HasFeature(FeatRealFS)
+	if !realFS && cd.dstFs != cd.srcFs {
+		return false, nil
+	}
+
+	srcReal, err := evalExisting(cd.srcFs, srcPath)
+	if err != nil {
+		return false, err
+	}
+
+	dstReal, err := evalExisting(cd.dstFs, dstPath)
+	if err != nil {
+		return false, err
+	}
+
+	sep := string(cd.srcFs.PathSeparator())
+
+	return dstReal == srcReal || strings.HasPrefix(dstReal, strings.TrimSuffix(srcReal, sep)+sep), nil
+}
+
+// evalExisting returns the absolute path of path with the symbolic links of its longest existing
+// parent evaluated, the remaining elements of path are kept as is.
+func evalExisting(vfs VFSBase, path string) (string, error) {
+	absPath, err := vfs.Abs(path)
+	if err != nil || !vfs.HasFeature(FeatSymlink) {
+		return absPath, err
+	}
+
+	rest := ""
+
+	for dir := absPath; ; {
+		realDir, err := vfs.EvalSymlinks(dir)
+		if err == nil {
+			return vfs.Join(realDir, rest), nil
+		}
+
+		parent, base := vfs.Split(dir)
+		parent = vfs.Clean(parent)
+
+		if !IsNotExist(err) || parent == dir {
+			return "", err
+		}
+
+		dir, rest = parent, vfs.Join(base, rest)
+	}
+}
+
+// copyDir copies the directory srcPath and its content to dstPath.
+func (cd *copyDir) copyDir(dstPath, srcPath string, info fs.FileInfo) error {
+	if cd.opts.Symlinks == SymlinkFollow {
+		realPath, err := cd.srcFs.EvalSymlinks(srcPath)
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(cd.parents, realPath) {
+			return &fs.PathError{Op: "copydir", Path: srcPath, Err: ErrTooManySymlinks}
+		}
+
+		cd.parents = append(cd.parents, realPath)
+		defer func() { cd.parents = cd.parents[:len(cd.parents)-1] }()
+	}
+
+	err := cd.dstFs.MkdirAll(dstPath, DefaultDirPerm)
+	if err != nil {
+		return err
+	}
+
+	entries, err := cd.srcFs.ReadDir(srcPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = cd.copyEntry(cd.dstFs.Join(dstPath, entry.Name()), cd.srcFs.Join(srcPath, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return copyMetadata(cd.dstFs, cd.srcFs, dstPath, info, cd.opts.PreserveOwners)
+}
+
+// copyEntry copies a directory entry according to its type.
+func (cd *copyDir) copyEntry(dstPath, srcPath string) error {
+	info, err := cd.srcFs.Lstat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		switch cd.opts.Symlinks {
+		case SymlinkSkip:
+			return nil
+		case SymlinkFollow:
+			info, err = cd.srcFs.Stat(srcPath)
+			if err != nil {
+				return err
+			}
+		default:
+			link, err := cd.srcFs.Readlink(srcPath)
+			if err != nil {
+				return err
+			}
+
+			if cd.opts.Symlinks == SymlinkRewrite {
+				link = cd.rewriteLink(link, dstPath)
+			}
+
+			err = cd.dstFs.Symlink(link, dstPath)
+			if err != nil {
+				return err
+			}
+
+			return copyMetadata(cd.dstFs, cd.srcFs, dstPath, info, cd.opts.PreserveOwners)
+		}
+	}
+
+	switch {
+	case info.IsDir():
+		return cd.copyDir(dstPath, srcPath, info)
+	case !info.Mode().IsRegular():
+		return nil
+	}
+
+	if !cd.opts.PreserveHardlinks || cd.srcFs.ToSysStat(info).Nlink() <= 1 {
+		_, err = copyFile(cd.dstFs, cd.srcFs, dstPath, srcPath, nil, &cd.opts)
+
+		return err
+	}
+
+	first, added := cd.hardlinks.add(dstPath, info)
+	if !added {
+		return cd.dstFs.Link(first, dstPath)
+	}
+
+	_, err = copyFile(cd.dstFs, cd.srcFs, dstPath, srcPath, nil, &cd.opts)
+
+	return err
+}
+
+// rewriteLink converts the target link of a symbolic link from the OS type of the source file system
+// to the OS type of the destination file system, the link being copied to dstPath.
+func (cd *copyDir) rewriteLink(link, dstPath string) string {
+	if cd.srcFs.OSType() == cd.dstFs.OSType() {
+		return link
+	}
+
+	volLen := VolumeNameLen(cd.srcFs, link)
+	path := ToSlash(cd.srcFs, link[volLen:])
+
+	switch {
+	case cd.dstFs.OSType() == OsWindows && IsAbs(cd.srcFs, link):
+		return VolumeName(cd.dstFs, dstPath) + FromSlash(cd.dstFs, path)
+	case volLen > 0 && strings.HasPrefix(path, "/"):
+		return path
+	default:
+		return FromSlash(cd.dstFs, path)
+	}
+}
+
 // HashFile hashes a file and returns the hash sum.
 func HashFile(vfs VFSBase, name string, hasher hash.Hash) (sum []byte, err error) {
 	f, err := vfs.OpenFile(name, os.O_RDONLY, 0)
@@ -0,0 +1,24 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !unix
+
+package avfs
+
+// sysBlockSize returns the preferred block size for file system I/O from the value returned by fs.FileInfo.Sys().
+func sysBlockSize(_ any) int64 {
+	return 0
+}
@@ -0,0 +1,90 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build avfs_setostype
+
+package avfs_test
+
+import (
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/memfs"
+)
+
+// TestCopyDirSymlinkRewrite tests the conversion of symbolic links copied between OS types.
+func TestCopyDirSymlinkRewrite(t *testing.T) {
+	linuxFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+	windowsFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows, DevMode: true})
+	if !test.CanEmulate(windowsFS, avfs.OsWindows) {
+		t.Skip("MemFS can't emulate Windows, use build tag 'avfs_setostype'")
+	}
+
+	type link struct {
+		name, target, want string
+	}
+
+	cases := []struct {
+		srcFS, dstFS avfs.VFS
+		links        []link
+	}{
+		{
+			srcFS: linuxFS, dstFS: windowsFS,
+			links: []link{
+				{name: "abs", target: "/usr/lib/file", want: `C:\usr\lib\file`},
+				{name: "rel", target: "../dir/file", want: `..\dir\file`},
+			},
+		},
+		{
+			srcFS: windowsFS, dstFS: linuxFS,
+			links: []link{
+				{name: "abs", target: `C:\Users\file`, want: "/Users/file"},
+				{name: "rooted", target: `\Users\file`, want: "/Users/file"},
+				{name: "rel", target: `..\dir\file`, want: "../dir/file"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		srcDir := c.srcFS.Join(c.srcFS.TempDir(), "src"+c.dstFS.OSType().String())
+
+		err := c.srcFS.MkdirAll(srcDir, avfs.DefaultDirPerm)
+		test.RequireNoError(t, err, "MkdirAll %s", srcDir)
+
+		for _, l := range c.links {
+			err = c.srcFS.Symlink(l.target, c.srcFS.Join(srcDir, l.name))
+			test.RequireNoError(t, err, "Symlink %s", l.target)
+		}
+
+		dstDir := c.dstFS.Join(c.dstFS.TempDir(), "dst"+c.srcFS.OSType().String())
+
+		err = avfs.CopyDir(c.dstFS, c.srcFS, dstDir, srcDir, &avfs.CopyOptions{Symlinks: avfs.SymlinkRewrite})
+		test.RequireNoError(t, err, "CopyDir %s", srcDir)
+
+		for _, l := range c.links {
+			path := c.dstFS.Join(dstDir, l.name)
+
+			got, err := c.dstFS.Readlink(path)
+			test.RequireNoError(t, err, "Readlink %s", path)
+
+			if got != l.want {
+				t.Errorf("Readlink %s (%s to %s) : want %s, got %s",
+					l.target, c.srcFS.OSType(), c.dstFS.OSType(), l.want, got)
+			}
+		}
+	}
+}
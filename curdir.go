@@ -16,6 +16,11 @@
 
 package avfs
 
+import (
+	"maps"
+	"strings"
+)
+
 // CurDirMgr is the interface that manages the current directory.
 type CurDirMgr interface {
 	// CurDir returns the current directory.
@@ -25,9 +30,16 @@ type CurDirMgr interface {
 	SetCurDir(curDir string) error
 }
 
+// DriveCurDirReader is the interface that wraps the DriveCurDir method.
+type DriveCurDirReader interface {
+	// DriveCurDir returns the current directory of the drive volume (Windows only).
+	DriveCurDir(volume string) string
+}
+
 // CurDirFn provides current directory functions to a file system.
 type CurDirFn struct {
-	curDir string // curDir is the current directory.
+	driveDirs map[string]string // driveDirs are the current directories of each drive (Windows only).
+	curDir    string            // curDir is the current directory.
 }
 
 // CurDir returns the current directory.
@@ -35,9 +47,49 @@ func (cdf *CurDirFn) CurDir() string {
 	return cdf.curDir
 }
 
+// DriveCurDir returns the current directory of the drive volume (Windows only),
+// like Windows, each drive keeps its own current directory.
+// If the current directory was never set on this drive, the root directory of the drive is returned.
+func (cdf *CurDirFn) DriveCurDir(volume string) string {
+	vol := strings.ToUpper(volume)
+	if driveVolume(cdf.curDir) == vol {
+		return cdf.curDir
+	}
+
+	if dir, ok := cdf.driveDirs[vol]; ok {
+		return dir
+	}
+
+	return vol + `\`
+}
+
 // SetCurDir sets the current directory.
 func (cdf *CurDirFn) SetCurDir(curDir string) error {
 	cdf.curDir = curDir
 
+	if vol := driveVolume(curDir); vol != "" {
+		// The map is copied so that copies of a file system don't share their current directories.
+		driveDirs := maps.Clone(cdf.driveDirs)
+		if driveDirs == nil {
+			driveDirs = make(map[string]string)
+		}
+
+		driveDirs[vol] = curDir
+		cdf.driveDirs = driveDirs
+	}
+
 	return nil
 }
+
+// driveVolume returns the upper case drive volume (like "C:") of a Windows path or an empty string.
+func driveVolume(path string) string {
+	if len(path) < 2 || path[1] != ':' {
+		return ""
+	}
+
+	if c := path[0] | 0x20; c < 'a' || c > 'z' {
+		return ""
+	}
+
+	return strings.ToUpper(path[:2])
+}
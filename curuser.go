@@ -10,6 +10,10 @@ type CurUserMgr interface {
 	// If the user is not found, the returned error is of type UnknownUserError.
 	SetUserByName(name string) error
 
+	// SetUserByUid sets the current user by user id and primary group id.
+	// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+	SetUserByUid(uid, gid int) error
+
 	// User returns the current user.
 	User() UserReader
 }
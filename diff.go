@@ -0,0 +1,533 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"bytes"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PatchOp is the operation of a PatchEntry.
+type PatchOp string
+
+const (
+	PatchAdd    PatchOp = "add"    // PatchAdd creates a file, a directory or a symbolic link.
+	PatchModify PatchOp = "modify" // PatchModify changes the content or the permissions of an existing file.
+	PatchDelete PatchOp = "delete" // PatchDelete removes a file or a directory and all its children.
+)
+
+// Patch is the set of changes turning a directory tree into another one (see Diff).
+// It can be serialized as JSON to be applied later or on another file system.
+type Patch struct {
+	Entries []PatchEntry `json:"entries"` // Entries are the changes, deletions first then parents before children.
+}
+
+// PatchEntry is a change of a Patch.
+type PatchEntry struct {
+	Op   PatchOp     `json:"op"`             // Op is the operation.
+	Path string      `json:"path"`           // Path is the slash separated path of the file relative to the root.
+	Mode fs.FileMode `json:"mode,omitempty"` // Mode is the mode of the file (add, modify).
+	Data []byte      `json:"data,omitempty"` // Data is the content of a regular file (add, modify).
+	Link string      `json:"link,omitempty"` // Link is the target of a symbolic link (add, modify).
+}
+
+// Diff compares the directory tree newRoot of newFs with the directory tree oldRoot of oldFs
+// and returns the patch turning the old tree into the new one : the files, directories and symbolic links
+// added, modified (content, permissions or link target) or deleted.
+// A file replaced by a directory (or conversely) is deleted then added.
+// Like rsync, regular files with the same permissions, size and modification time are considered unchanged
+// without reading their content. Owners and hard links are not compared.
+//
+// Combined with CopyDir, it records what a tool would change : copy a tree to a MemFS,
+// run the tool on the copy and compute the patch from the original tree to the copy.
+// An overlay file system records the same changes without copying the tree (see overlayfs.OverlayFS.Export).
+func Diff(oldFs VFSBase, oldRoot string, newFs VFSBase, newRoot string) (*Patch, error) {
+	var adds, deletes []PatchEntry
+
+	err := walkDiff(oldFs, oldRoot, newFs, newRoot,
+		func(rel, oldPath string, oldInfo fs.FileInfo, newPath string, newInfo fs.FileInfo) error {
+			switch {
+			case newInfo == nil:
+				deletes = append(deletes, PatchEntry{Op: PatchDelete, Path: rel})
+
+				return nil
+			case oldInfo == nil:
+			case oldInfo.Mode().Type() != newInfo.Mode().Type():
+				deletes = append(deletes, PatchEntry{Op: PatchDelete, Path: rel})
+			default:
+				entry, changed, err := diffEntry(oldFs, oldPath, oldInfo, newFs, newPath, newInfo)
+				if err != nil || !changed {
+					return err
+				}
+
+				entry.Path = rel
+				adds = append(adds, entry)
+
+				return nil
+			}
+
+			entry, err := newPatchEntry(newFs, newPath, newInfo)
+			if err != nil {
+				return err
+			}
+
+			entry.Path = rel
+			adds = append(adds, entry)
+
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(deletes, func(a, b PatchEntry) int { return cmpSlashPath(a.Path, b.Path) })
+
+	return &Patch{Entries: append(deletes, adds...)}, nil
+}
+
+// diffFunc is the function called by walkDiff for each file of the compared trees,
+// oldInfo is nil for a file only in the new tree and newInfo is nil for a file only in the old tree.
+// Returning fs.SkipDir for a directory of the new tree skips its children.
+type diffFunc func(rel, oldPath string, oldInfo fs.FileInfo, newPath string, newInfo fs.FileInfo) error
+
+// walkDiff walks the directory tree newRoot of newFs then the directory tree oldRoot of oldFs
+// and calls fn for each file of the new tree and for each file only in the old tree, the roots are not compared.
+// The children of a directory only in the new tree (or replacing a file) are passed to fn without looking
+// for them in the old tree, the children of a directory only in the old tree (or replaced by a file) are not.
+func walkDiff(oldFs VFSBase, oldRoot string, newFs VFSBase, newRoot string, fn diffFunc) error {
+	addedDirs := make(map[string]bool)
+
+	err := newFs.WalkDir(newRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == newRoot {
+			return err
+		}
+
+		rel, err := relSlash(newFs, newRoot, path)
+		if err != nil {
+			return err
+		}
+
+		newInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var oldInfo fs.FileInfo
+
+		oldPath := oldFs.Join(oldRoot, oldFs.FromSlash(rel))
+
+		if parent := rel[:max(strings.LastIndexByte(rel, '/'), 0)]; !addedDirs[parent] {
+			oldInfo, err = oldFs.Lstat(oldPath)
+
+			switch {
+			case IsNotExist(err):
+				oldInfo = nil
+			case err != nil:
+				return err
+			}
+		}
+
+		if d.IsDir() && (oldInfo == nil || !oldInfo.IsDir()) {
+			addedDirs[rel] = true
+		}
+
+		return fn(rel, oldPath, oldInfo, path, newInfo)
+	})
+	if err != nil {
+		return err
+	}
+
+	return oldFs.WalkDir(oldRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == oldRoot {
+			return err
+		}
+
+		rel, err := relSlash(oldFs, oldRoot, path)
+		if err != nil {
+			return err
+		}
+
+		newPath := newFs.Join(newRoot, newFs.FromSlash(rel))
+
+		newInfo, err := newFs.Lstat(newPath)
+
+		switch {
+		case IsNotExist(err):
+			oldInfo, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			err = fn(rel, path, oldInfo, newPath, nil)
+			if err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case newInfo.IsDir():
+			return nil
+		}
+
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+
+		return nil
+	})
+}
+
+// Apply applies the patch to the directory tree root of vfs.
+// The permissions of the added files are not restricted by the umask of vfs.
+func (p *Patch) Apply(vfs VFSBase, root string) error {
+	for i := range p.Entries {
+		entry := &p.Entries[i]
+		path := vfs.Join(root, vfs.FromSlash(entry.Path))
+
+		var err error
+
+		switch entry.Op {
+		case PatchDelete:
+			err = vfs.RemoveAll(path)
+		case PatchAdd, PatchModify:
+			err = applyEntry(vfs, path, entry)
+		default:
+			err = &fs.PathError{Op: "patch", Path: path, Err: ErrInvalidArgument}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyEntry creates or modifies the file path of vfs from an entry of a patch.
+func applyEntry(vfs VFSBase, path string, entry *PatchEntry) error {
+	perm := entry.Mode & FileModeMask
+
+	switch entry.Mode.Type() {
+	case fs.ModeDir:
+		if entry.Op == PatchAdd {
+			err := vfs.Mkdir(path, perm)
+			if err != nil {
+				return err
+			}
+		}
+	case fs.ModeSymlink:
+		if entry.Op == PatchModify {
+			err := vfs.Remove(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		return vfs.Symlink(entry.Link, path)
+	default:
+		err := vfs.WriteFile(path, entry.Data, perm)
+		if err != nil {
+			return err
+		}
+	}
+
+	return vfs.Chmod(path, perm)
+}
+
+// diffEntry returns the entry of a patch modifying the old file into the new one
+// and true if they differ, both files have the same type.
+func diffEntry(oldFs VFSBase, oldPath string, oldInfo fs.FileInfo,
+	newFs VFSBase, newPath string, newInfo fs.FileInfo,
+) (entry PatchEntry, changed bool, err error) {
+	entry = PatchEntry{Op: PatchModify, Mode: newInfo.Mode()}
+	changed = oldInfo.Mode() != newInfo.Mode()
+
+	switch newInfo.Mode().Type() {
+	case fs.ModeDir:
+		return entry, changed, nil
+	case fs.ModeSymlink:
+		oldLink, err := oldFs.Readlink(oldPath)
+		if err != nil {
+			return entry, false, err
+		}
+
+		entry.Link, err = newFs.Readlink(newPath)
+		if err != nil {
+			return entry, false, err
+		}
+
+		// The mode of a symbolic link can't be changed, only its target is compared.
+		return entry, oldLink != entry.Link, nil
+	default:
+		sameSize := oldInfo.Size() == newInfo.Size()
+		if !changed && sameSize && oldInfo.ModTime().Equal(newInfo.ModTime()) {
+			return entry, false, nil
+		}
+
+		entry.Data, err = newFs.ReadFile(newPath)
+		if err != nil {
+			return entry, false, err
+		}
+
+		if changed || !sameSize {
+			return entry, true, nil
+		}
+
+		oldData, err := oldFs.ReadFile(oldPath)
+		if err != nil {
+			return entry, false, err
+		}
+
+		return entry, !bytes.Equal(oldData, entry.Data), nil
+	}
+}
+
+// newPatchEntry returns the entry of a patch adding the file path of vfs.
+func newPatchEntry(vfs VFSBase, path string, info fs.FileInfo) (entry PatchEntry, err error) {
+	entry = PatchEntry{Op: PatchAdd, Mode: info.Mode()}
+
+	switch info.Mode().Type() {
+	case fs.ModeDir:
+	case fs.ModeSymlink:
+		entry.Link, err = vfs.Readlink(path)
+	default:
+		entry.Data, err = vfs.ReadFile(path)
+	}
+
+	return entry, err
+}
+
+// relSlash returns the slash separated path of path relative to root.
+func relSlash(vfs VFSBase, root, path string) (string, error) {
+	rel, err := vfs.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+
+	return vfs.ToSlash(rel), nil
+}
+
+// cmpSlashPath compares two slash separated paths so that the children of a directory
+// are sorted before the following siblings of the directory.
+func cmpSlashPath(a, b string) int {
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+}
+
+// DiffKind is the kind of a Difference between two directory trees.
+type DiffKind string
+
+const (
+	DiffMissing DiffKind = "missing" // DiffMissing is a file of the wanted tree missing from the other tree.
+	DiffExtra   DiffKind = "extra"   // DiffExtra is a file of the other tree missing from the wanted tree.
+	DiffType    DiffKind = "type"    // DiffType is a file of a different type (regular file, directory, symbolic link...).
+	DiffContent DiffKind = "content" // DiffContent is a regular file with a different content.
+	DiffLink    DiffKind = "link"    // DiffLink is a symbolic link with a different target.
+	DiffMode    DiffKind = "mode"    // DiffMode is a file with different permissions.
+	DiffOwner   DiffKind = "owner"   // DiffOwner is a file with a different user or group (see DiffFSOptions.Owners).
+	DiffModTime DiffKind = "modtime" // DiffModTime is a file with a different modification time (see DiffFSOptions.ModTime).
+)
+
+// Difference is a difference between two directory trees reported by DiffFS.
+type Difference struct {
+	Kind DiffKind // Kind is the kind of the difference.
+	Path string   // Path is the slash separated path of the file relative to the roots.
+	Want string   // Want is the value of the wanted tree (mode, owner, link target or modification time).
+	Got  string   // Got is the value of the other tree.
+}
+
+// String returns a textual representation of the difference.
+func (d Difference) String() string {
+	if d.Want == "" && d.Got == "" {
+		return string(d.Kind) + " " + d.Path
+	}
+
+	return string(d.Kind) + " " + d.Path + ": want " + d.Want + ", got " + d.Got
+}
+
+// DiffFSOptions are the options of DiffFS.
+type DiffFSOptions struct {
+	// Owners compares the user and group identifiers of the files.
+	Owners bool
+
+	// ModTime compares the modification times of the files and directories, not of the symbolic links.
+	ModTime bool
+
+	// TimePrecision is the precision of the compared modification times, 0 compares them exactly.
+	TimePrecision time.Duration
+}
+
+// DiffFS compares the directory tree gotRoot of gotFs with the wanted directory tree wantRoot of wantFs
+// and returns their differences sorted by path : missing or extra files, files of a different type,
+// regular files with a different content, symbolic links with a different target and files with different
+// permissions, owners or modification times (see DiffFSOptions). The roots themselves are not compared.
+// The children of a missing, extra or replaced directory are not reported.
+// If opts is nil, the owners and the modification times are not compared.
+//
+// Unlike Diff, the result is meant to be checked, for example to assert that a transformation
+// produced the expected tree in a test.
+func DiffFS(wantFs VFSBase, wantRoot string, gotFs VFSBase, gotRoot string, opts *DiffFSOptions) ([]Difference, error) {
+	if opts == nil {
+		opts = &DiffFSOptions{}
+	}
+
+	var diffs []Difference
+
+	err := walkDiff(wantFs, wantRoot, gotFs, gotRoot,
+		func(rel, wantPath string, wantInfo fs.FileInfo, gotPath string, gotInfo fs.FileInfo) error {
+			switch {
+			case gotInfo == nil:
+				diffs = append(diffs, Difference{Kind: DiffMissing, Path: rel})
+			case wantInfo == nil:
+				diffs = append(diffs, Difference{Kind: DiffExtra, Path: rel})
+			case wantInfo.Mode().Type() != gotInfo.Mode().Type():
+				diffs = append(diffs, Difference{
+					Kind: DiffType, Path: rel,
+					Want: fileType(wantInfo.Mode()), Got: fileType(gotInfo.Mode()),
+				})
+			default:
+				fileDiffs, err := diffFile(wantFs, wantPath, wantInfo, gotFs, gotPath, gotInfo, opts)
+				if err != nil {
+					return err
+				}
+
+				for _, fd := range fileDiffs {
+					fd.Path = rel
+					diffs = append(diffs, fd)
+				}
+
+				return nil
+			}
+
+			// The children of an extra directory or of a directory replacing a file are not reported.
+			if gotInfo != nil && gotInfo.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(diffs, func(a, b Difference) int { return cmpSlashPath(a.Path, b.Path) })
+
+	return diffs, nil
+}
+
+// diffFile returns the differences between two files of the same type.
+func diffFile(wantFs VFSBase, wantPath string, wantInfo fs.FileInfo,
+	gotFs VFSBase, gotPath string, gotInfo fs.FileInfo, opts *DiffFSOptions,
+) ([]Difference, error) {
+	var diffs []Difference
+
+	switch wantInfo.Mode().Type() {
+	case fs.ModeDir:
+	case fs.ModeSymlink:
+		wantLink, err := wantFs.Readlink(wantPath)
+		if err != nil {
+			return nil, err
+		}
+
+		gotLink, err := gotFs.Readlink(gotPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if wantLink != gotLink {
+			diffs = append(diffs, Difference{Kind: DiffLink, Want: wantLink, Got: gotLink})
+		}
+	default:
+		equal, err := sameContent(wantFs, wantPath, wantInfo, gotFs, gotPath, gotInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equal {
+			diffs = append(diffs, Difference{Kind: DiffContent})
+		}
+	}
+
+	isSymlink := wantInfo.Mode()&fs.ModeSymlink != 0
+
+	// The permissions of a symbolic link can't be changed, they are not compared.
+	wantMode, gotMode := wantInfo.Mode()&^fs.ModeType, gotInfo.Mode()&^fs.ModeType
+	if !isSymlink && wantMode != gotMode {
+		diffs = append(diffs, Difference{Kind: DiffMode, Want: wantMode.String(), Got: gotMode.String()})
+	}
+
+	if opts.Owners {
+		wantSst, gotSst := wantFs.ToSysStat(wantInfo), gotFs.ToSysStat(gotInfo)
+		wantOwner := strconv.Itoa(wantSst.Uid()) + ":" + strconv.Itoa(wantSst.Gid())
+		gotOwner := strconv.Itoa(gotSst.Uid()) + ":" + strconv.Itoa(gotSst.Gid())
+
+		if wantOwner != gotOwner {
+			diffs = append(diffs, Difference{Kind: DiffOwner, Want: wantOwner, Got: gotOwner})
+		}
+	}
+
+	if opts.ModTime && !isSymlink {
+		wantTime := wantInfo.ModTime().Truncate(opts.TimePrecision)
+		gotTime := gotInfo.ModTime().Truncate(opts.TimePrecision)
+
+		if !wantTime.Equal(gotTime) {
+			diffs = append(diffs, Difference{
+				Kind: DiffModTime,
+				Want: wantTime.Format(time.RFC3339Nano), Got: gotTime.Format(time.RFC3339Nano),
+			})
+		}
+	}
+
+	return diffs, nil
+}
+
+// sameContent returns true if two regular files have the same content.
+func sameContent(wantFs VFSBase, wantPath string, wantInfo fs.FileInfo,
+	gotFs VFSBase, gotPath string, gotInfo fs.FileInfo,
+) (bool, error) {
+	if wantInfo.Size() != gotInfo.Size() {
+		return false, nil
+	}
+
+	wantData, err := wantFs.ReadFile(wantPath)
+	if err != nil {
+		return false, err
+	}
+
+	gotData, err := gotFs.ReadFile(gotPath)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(wantData, gotData), nil
+}
+
+// fileType returns the name of the type of a file mode.
+func fileType(mode fs.FileMode) string {
+	switch mode.Type() {
+	case 0:
+		return "file"
+	case fs.ModeDir:
+		return "directory"
+	case fs.ModeSymlink:
+		return "symlink"
+	default:
+		return mode.Type().String()
+	}
+}
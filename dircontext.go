@@ -0,0 +1,137 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"context"
+	"io/fs"
+	"os"
+)
+
+// MkdirAllContext is like MkdirAll but checks ctx before creating each missing directory.
+// When ctx is done, it returns a *PathError wrapping the context error with the path
+// of the first directory not created, the directories created before are left in place.
+// Otherwise, if there is an error, it is the error of the file system.
+func MkdirAllContext[T VFSBase](ctx context.Context, vfs T, path string, perm fs.FileMode) error {
+	const op = "mkdirall"
+
+	absPath, err := vfs.Abs(path)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	var missing []string
+
+	for p := absPath; ; {
+		info, err := vfs.Stat(p)
+		if err == nil && info.IsDir() {
+			break
+		}
+
+		if err == nil || !IsNotExist(err) {
+			// Let the file system report an existing file or an inaccessible path with its own error.
+			return vfs.MkdirAll(path, perm)
+		}
+
+		missing = append(missing, p)
+
+		parent := vfs.Dir(p)
+		if parent == p {
+			break
+		}
+
+		p = parent
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		dir := missing[i]
+
+		if err := ctx.Err(); err != nil {
+			return &fs.PathError{Op: op, Path: dir, Err: err}
+		}
+
+		err = vfs.Mkdir(dir, perm)
+		if err != nil && !IsExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveAllContext is like RemoveAll but checks ctx before removing each file or directory.
+// When ctx is done, it returns a *PathError wrapping the context error with the path
+// of the first file or directory not removed, the files removed before are not restored.
+// Otherwise, if there is an error, it is the error of the file system for the faulty path.
+// Symbolic links are removed, not followed.
+func RemoveAllContext[T VFSBase](ctx context.Context, vfs T, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	return removeAllContext(ctx, vfs, path)
+}
+
+// removeAllContext removes path and its children, checking ctx before each removal.
+func removeAllContext[T VFSBase](ctx context.Context, vfs T, path string) error {
+	const op = "removeall"
+
+	if err := ctx.Err(); err != nil {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	info, err := vfs.Lstat(path)
+	if err != nil {
+		if IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if info.IsDir() {
+		f, err := vfs.OpenFile(path, os.O_RDONLY, 0)
+		if err != nil {
+			return err
+		}
+
+		names, err := f.Readdirnames(-1)
+		_ = f.Close()
+
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			err = removeAllContext(ctx, vfs, vfs.Join(path, name))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return &fs.PathError{Op: op, Path: path, Err: err}
+		}
+	}
+
+	err = vfs.Remove(path)
+	if err != nil && !IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -16,7 +16,10 @@
 
 package avfs
 
-import "math"
+import (
+	"math"
+	"strconv"
+)
 
 // NotImplementedIdm is the default identity manager for all file systems.
 var NotImplementedIdm = NewDummyIdm() //nolint:gochecknoglobals // Used as default Idm for other file systems.
@@ -58,6 +61,12 @@ func NewUser(userName string, uid, gid int) *DummyUser {
 	return &DummyUser{name: userName, uid: uid, gid: gid}
 }
 
+// NewEphemeralUser returns a user with the specified uid and gid which is not registered in any identity manager.
+// Its name is the decimal representation of uid.
+func NewEphemeralUser(uid, gid int) *DummyUser {
+	return &DummyUser{name: strconv.Itoa(uid), uid: uid, gid: gid}
+}
+
 // Type returns the type of the fileSystem or Identity manager.
 func (idm *DummyIdm) Type() string {
 	return "DummyIdm"
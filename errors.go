@@ -92,12 +92,21 @@ type CustomError uintptr
 //go:generate stringer -type CustomError -linecomment -output errors_custom.go
 
 const (
-	ErrNegativeOffset      CustomError = customErrorBase + 1 // negative offset
-	ErrFileClosing         CustomError = customErrorBase + 2 // use of closed file
-	ErrPatternHasSeparator CustomError = customErrorBase + 3 // pattern contains path separator
-	ErrVolumeAlreadyExists CustomError = customErrorBase + 4 // Volume already exists.
-	ErrVolumeNameInvalid   CustomError = customErrorBase + 5 // Volume name is invalid.
-	ErrVolumeWindows       CustomError = customErrorBase + 6 // Volumes are available for Windows only.
+	ErrNegativeOffset      CustomError = customErrorBase + 1  // negative offset
+	ErrFileClosing         CustomError = customErrorBase + 2  // use of closed file
+	ErrPatternHasSeparator CustomError = customErrorBase + 3  // pattern contains path separator
+	ErrVolumeAlreadyExists CustomError = customErrorBase + 4  // Volume already exists.
+	ErrVolumeNameInvalid   CustomError = customErrorBase + 5  // Volume name is invalid.
+	ErrVolumeWindows       CustomError = customErrorBase + 6  // Volumes are available for Windows only.
+	ErrSecurityWindows     CustomError = customErrorBase + 7  // Security descriptors are available for Windows only.
+	ErrStreamsWindows      CustomError = customErrorBase + 8  // Alternate data streams are available for Windows only.
+	ErrShortNamesWindows   CustomError = customErrorBase + 9  // Short names are available for Windows only.
+	ErrIntegrity           CustomError = customErrorBase + 10 // file content does not match the manifest
+	ErrIllegalSeek         CustomError = customErrorBase + 11 // illegal seek
+	ErrACLLinux            CustomError = customErrorBase + 12 // POSIX ACLs are available for Linux only.
+	ErrWatchOverflow       CustomError = customErrorBase + 13 // watch events lost
+	ErrSameFile            CustomError = customErrorBase + 14 // are the same file
+	ErrVolumeMountOptions  CustomError = customErrorBase + 15 // Volumes can only be mounted from file systems with the same options.
 )
 
 func (i CustomError) Error() string {
@@ -112,29 +121,43 @@ type LinuxError uintptr
 // Errors for Linux operating systems.
 // See https://github.com/torvalds/linux/blob/master/tools/include/uapi/asm-generic/errno-base.h
 const (
-	ErrBadFileDesc     LinuxError = errEBADF     // bad file descriptor
-	ErrCrossDevLink    LinuxError = errEXDEV     // invalid cross-device link
-	ErrDirNotEmpty     LinuxError = errENOTEMPTY // directory not empty
-	ErrFileExists      LinuxError = errEEXIST    // file exists
-	ErrInvalidArgument LinuxError = errEINVAL    // invalid argument
-	ErrIsADirectory    LinuxError = errEISDIR    // is a directory
-	ErrNoSuchFileOrDir LinuxError = errENOENT    // no such file or directory
-	ErrNotADirectory   LinuxError = errENOTDIR   // not a directory
-	ErrOpNotPermitted  LinuxError = errEPERM     // operation not permitted
-	ErrPermDenied      LinuxError = errEACCES    // permission denied
-	ErrTooManySymlinks LinuxError = errELOOP     // too many levels of symbolic links
-
-	errEACCES    = 0xd
-	errEBADF     = 0x9
-	errEEXIST    = 0x11
-	errEINVAL    = 0x16
-	errEISDIR    = 0x15
-	errENOENT    = 0x2
-	errELOOP     = 0x28
-	errENOTDIR   = 0x14
-	errENOTEMPTY = 0x27
-	errEPERM     = 0x1
-	errEXDEV     = 0x12
+	ErrBadFileDesc      LinuxError = errEBADF        // bad file descriptor
+	ErrCrossDevLink     LinuxError = errEXDEV        // invalid cross-device link
+	ErrDeviceBusy       LinuxError = errEBUSY        // device or resource busy
+	ErrDirNotEmpty      LinuxError = errENOTEMPTY    // directory not empty
+	ErrFileExists       LinuxError = errEEXIST       // file exists
+	ErrInterrupted      LinuxError = errEINTR        // interrupted system call
+	ErrInvalidArgument  LinuxError = errEINVAL       // invalid argument
+	ErrIsADirectory     LinuxError = errEISDIR       // is a directory
+	ErrNameTooLong      LinuxError = errENAMETOOLONG // file name too long
+	ErrNoSpace          LinuxError = errENOSPC       // no space left on device
+	ErrNoSuchDevice     LinuxError = errENXIO        // no such device or address
+	ErrNoSuchFileOrDir  LinuxError = errENOENT       // no such file or directory
+	ErrNotADirectory    LinuxError = errENOTDIR      // not a directory
+	ErrNotSupported     LinuxError = errEOPNOTSUPP   // operation not supported
+	ErrOpNotPermitted   LinuxError = errEPERM        // operation not permitted
+	ErrPermDenied       LinuxError = errEACCES       // permission denied
+	ErrTooManyOpenFiles LinuxError = errEMFILE       // too many open files
+	ErrTooManySymlinks  LinuxError = errELOOP        // too many levels of symbolic links
+
+	errEACCES       = 0xd
+	errEBADF        = 0x9
+	errEBUSY        = 0x10
+	errEEXIST       = 0x11
+	errEINTR        = 0x4
+	errEINVAL       = 0x16
+	errEISDIR       = 0x15
+	errENAMETOOLONG = 0x24
+	errENOENT       = 0x2
+	errENOSPC       = 0x1c
+	errELOOP        = 0x28
+	errEMFILE       = 0x18
+	errENOTDIR      = 0x14
+	errENOTEMPTY    = 0x27
+	errENXIO        = 0x6
+	errEOPNOTSUPP   = 0x5f
+	errEPERM        = 0x1
+	errEXDEV        = 0x12
 )
 
 // Error returns the error string of the Linux operating system.
@@ -165,22 +188,27 @@ type WindowsError uintptr
 // Errors for Windows operating systems.
 // See https://learn.microsoft.com/en-us/windows/win32/debug/system-error-codes
 const (
-	ErrWinAccessDenied     WindowsError = 5          // Access is denied.
-	ErrWinAlreadyExists    WindowsError = 183        // Cannot create a file when that file already exists.
-	ErrWinBadNetPath       WindowsError = 53         // Bad network path.
-	ErrWinDirNameInvalid   WindowsError = 0x10B      // The directory name is invalid.
-	ErrWinDirNotEmpty      WindowsError = 145        // The directory is not empty.
-	ErrWinFileExists       WindowsError = 80         // The file exists.
-	ErrWinFileNotFound     WindowsError = 2          // The system cannot find the file specified.
-	ErrWinIncorrectFunc    WindowsError = 1          // Incorrect function.
-	ErrWinIsADirectory     WindowsError = 21         // is a directory
-	ErrWinNegativeSeek     WindowsError = 0x83       // An attempt was made to move the file pointer before the beginning of the file.
-	ErrWinNotReparsePoint  WindowsError = 4390       // The file or directory is not a reparse point.
-	ErrWinInvalidHandle    WindowsError = 6          // The handle is invalid.
-	ErrWinSharingViolation WindowsError = 32         // The process cannot access the file because it is being used by another process.
-	ErrWinNotSupported     WindowsError = 0x20000082 // not supported by windows
-	ErrWinPathNotFound     WindowsError = 3          // The system cannot find the path specified.
-	ErrWinPrivilegeNotHeld WindowsError = 1314       // A required privilege is not held by the client.
+	ErrWinAccessDenied       WindowsError = 5          // Access is denied.
+	ErrWinAlreadyExists      WindowsError = 183        // Cannot create a file when that file already exists.
+	ErrWinBadNetPath         WindowsError = 53         // Bad network path.
+	ErrWinDirNameInvalid     WindowsError = 0x10B      // The directory name is invalid.
+	ErrWinDirNotEmpty        WindowsError = 145        // The directory is not empty.
+	ErrWinDiskFull           WindowsError = 112        // There is not enough space on the disk.
+	ErrWinFileExists         WindowsError = 80         // The file exists.
+	ErrWinFilenameExcedRange WindowsError = 206        // The filename or extension is too long.
+	ErrWinFileNotFound       WindowsError = 2          // The system cannot find the file specified.
+	ErrWinIncorrectFunc      WindowsError = 1          // Incorrect function.
+	ErrWinIsADirectory       WindowsError = 21         // is a directory
+	ErrWinNegativeSeek       WindowsError = 0x83       // An attempt was made to move the file pointer before the beginning of the file.
+	ErrWinNotReparsePoint    WindowsError = 4390       // The file or directory is not a reparse point.
+	ErrWinNotSameDevice      WindowsError = 17         // The system cannot move the file to a different disk drive.
+	ErrWinInvalidHandle      WindowsError = 6          // The handle is invalid.
+	ErrWinInvalidName        WindowsError = 123        // The filename, directory name, or volume label syntax is incorrect.
+	ErrWinSharingViolation   WindowsError = 32         // The process cannot access the file because it is being used by another process.
+	ErrWinTooManyOpenFiles   WindowsError = 4          // The system cannot open the file.
+	ErrWinNotSupported       WindowsError = 0x20000082 // not supported by windows
+	ErrWinPathNotFound       WindowsError = 3          // The system cannot find the path specified.
+	ErrWinPrivilegeNotHeld   WindowsError = 1314       // A required privilege is not held by the client.
 )
 
 // Error returns the error string of the Windows operating system.
@@ -205,17 +233,21 @@ func (i WindowsError) Is(target error) bool {
 
 // Errors regroups errors depending on the OS emulated.
 type Errors struct {
-	BadFileDesc     error // bad file descriptor.
-	DirNotEmpty     error // Directory not empty.
-	FileExists      error // File exists.
-	InvalidArgument error // invalid argument
-	IsADirectory    error // File Is a directory.
-	NoSuchDir       error // No such directory.
-	NoSuchFile      error // No such file.
-	NotADirectory   error // Not a directory.
-	OpNotPermitted  error // operation not permitted.
-	PermDenied      error // Permission denied.
-	TooManySymlinks error // Too many levels of symbolic links.
+	BadFileDesc      error // bad file descriptor.
+	DirNotEmpty      error // Directory not empty.
+	FileExists       error // File exists.
+	InvalidArgument  error // invalid argument
+	IsADirectory     error // File Is a directory.
+	NameTooLong      error // File name too long.
+	NoSpace          error // No space left on device.
+	NoSuchDir        error // No such directory.
+	NoSuchFile       error // No such file.
+	NotADirectory    error // Not a directory.
+	NotSupported     error // Operation not supported.
+	OpNotPermitted   error // operation not permitted.
+	PermDenied       error // Permission denied.
+	TooManyOpenFiles error // Too many open files.
+	TooManySymlinks  error // Too many levels of symbolic links.
 }
 
 // SetOSType sets errors depending on the operating system.
@@ -227,11 +259,15 @@ func (e *Errors) SetOSType(osType OSType) {
 		e.FileExists = ErrWinFileExists
 		e.InvalidArgument = ErrWinNegativeSeek
 		e.IsADirectory = ErrWinIsADirectory
+		e.NameTooLong = ErrWinFilenameExcedRange
+		e.NoSpace = ErrWinDiskFull
 		e.NoSuchDir = ErrWinPathNotFound
 		e.NoSuchFile = ErrWinFileNotFound
 		e.NotADirectory = ErrWinPathNotFound
+		e.NotSupported = ErrWinNotSupported
 		e.OpNotPermitted = ErrWinNotSupported
 		e.PermDenied = ErrWinAccessDenied
+		e.TooManyOpenFiles = ErrWinTooManyOpenFiles
 		e.TooManySymlinks = ErrTooManySymlinks
 	default:
 		e.BadFileDesc = ErrBadFileDesc
@@ -239,11 +275,15 @@ func (e *Errors) SetOSType(osType OSType) {
 		e.FileExists = ErrFileExists
 		e.InvalidArgument = ErrInvalidArgument
 		e.IsADirectory = ErrIsADirectory
+		e.NameTooLong = ErrNameTooLong
+		e.NoSpace = ErrNoSpace
 		e.NoSuchDir = ErrNoSuchFileOrDir
 		e.NoSuchFile = ErrNoSuchFileOrDir
 		e.NotADirectory = ErrNotADirectory
+		e.NotSupported = ErrNotSupported
 		e.OpNotPermitted = ErrOpNotPermitted
 		e.PermDenied = ErrPermDenied
+		e.TooManyOpenFiles = ErrTooManyOpenFiles
 		e.TooManySymlinks = ErrTooManySymlinks
 	}
 }
@@ -14,11 +14,20 @@ func _() {
 	_ = x[ErrVolumeAlreadyExists-2147483652]
 	_ = x[ErrVolumeNameInvalid-2147483653]
 	_ = x[ErrVolumeWindows-2147483654]
+	_ = x[ErrSecurityWindows-2147483655]
+	_ = x[ErrStreamsWindows-2147483656]
+	_ = x[ErrShortNamesWindows-2147483657]
+	_ = x[ErrIntegrity-2147483658]
+	_ = x[ErrIllegalSeek-2147483659]
+	_ = x[ErrACLLinux-2147483660]
+	_ = x[ErrWatchOverflow-2147483661]
+	_ = x[ErrSameFile-2147483662]
+	_ = x[ErrVolumeMountOptions-2147483663]
 }
 
-const _CustomError_name = "negative offsetuse of closed filepattern contains path separatorVolume already exists.Volume name is invalid.Volumes are available for Windows only."
+const _CustomError_name = "negative offsetuse of closed filepattern contains path separatorVolume already exists.Volume name is invalid.Volumes are available for Windows only.Security descriptors are available for Windows only.Alternate data streams are available for Windows only.Short names are available for Windows only.file content does not match the manifestillegal seekPOSIX ACLs are available for Linux only.watch events lostare the same fileVolumes can only be mounted from file systems with the same options."
 
-var _CustomError_index = [...]uint8{0, 15, 33, 64, 86, 109, 148}
+var _CustomError_index = [...]uint16{0, 15, 33, 64, 86, 109, 148, 200, 254, 297, 337, 349, 389, 406, 423, 491}
 
 func (i CustomError) String() string {
 	i -= 2147483649
@@ -10,31 +10,44 @@ func _() {
 	var x [1]struct{}
 	_ = x[ErrBadFileDesc-9]
 	_ = x[ErrCrossDevLink-18]
+	_ = x[ErrDeviceBusy-16]
 	_ = x[ErrDirNotEmpty-39]
 	_ = x[ErrFileExists-17]
+	_ = x[ErrInterrupted-4]
 	_ = x[ErrInvalidArgument-22]
 	_ = x[ErrIsADirectory-21]
+	_ = x[ErrNameTooLong-36]
+	_ = x[ErrNoSpace-28]
+	_ = x[ErrNoSuchDevice-6]
 	_ = x[ErrNoSuchFileOrDir-2]
 	_ = x[ErrNotADirectory-20]
+	_ = x[ErrNotSupported-95]
 	_ = x[ErrOpNotPermitted-1]
 	_ = x[ErrPermDenied-13]
+	_ = x[ErrTooManyOpenFiles-24]
 	_ = x[ErrTooManySymlinks-40]
 }
 
 const (
-	_LinuxError_name_0 = "operation not permittedno such file or directory"
-	_LinuxError_name_1 = "bad file descriptor"
-	_LinuxError_name_2 = "permission denied"
-	_LinuxError_name_3 = "file existsinvalid cross-device link"
-	_LinuxError_name_4 = "not a directoryis a directoryinvalid argument"
-	_LinuxError_name_5 = "directory not emptytoo many levels of symbolic links"
+	_LinuxError_name_0  = "operation not permittedno such file or directory"
+	_LinuxError_name_1  = "interrupted system call"
+	_LinuxError_name_2  = "no such device or address"
+	_LinuxError_name_3  = "bad file descriptor"
+	_LinuxError_name_4  = "permission denied"
+	_LinuxError_name_5  = "device or resource busyfile existsinvalid cross-device link"
+	_LinuxError_name_6  = "not a directoryis a directoryinvalid argument"
+	_LinuxError_name_7  = "too many open files"
+	_LinuxError_name_8  = "no space left on device"
+	_LinuxError_name_9  = "file name too long"
+	_LinuxError_name_10 = "directory not emptytoo many levels of symbolic links"
+	_LinuxError_name_11 = "operation not supported"
 )
 
 var (
-	_LinuxError_index_0 = [...]uint8{0, 23, 48}
-	_LinuxError_index_3 = [...]uint8{0, 11, 36}
-	_LinuxError_index_4 = [...]uint8{0, 15, 29, 45}
-	_LinuxError_index_5 = [...]uint8{0, 19, 52}
+	_LinuxError_index_0  = [...]uint8{0, 23, 48}
+	_LinuxError_index_5  = [...]uint8{0, 23, 34, 59}
+	_LinuxError_index_6  = [...]uint8{0, 15, 29, 45}
+	_LinuxError_index_10 = [...]uint8{0, 19, 52}
 )
 
 func (i LinuxError) String() string {
@@ -42,19 +55,31 @@ func (i LinuxError) String() string {
 	case 1 <= i && i <= 2:
 		i -= 1
 		return _LinuxError_name_0[_LinuxError_index_0[i]:_LinuxError_index_0[i+1]]
-	case i == 9:
+	case i == 4:
 		return _LinuxError_name_1
-	case i == 13:
+	case i == 6:
 		return _LinuxError_name_2
-	case 17 <= i && i <= 18:
-		i -= 17
-		return _LinuxError_name_3[_LinuxError_index_3[i]:_LinuxError_index_3[i+1]]
+	case i == 9:
+		return _LinuxError_name_3
+	case i == 13:
+		return _LinuxError_name_4
+	case 16 <= i && i <= 18:
+		i -= 16
+		return _LinuxError_name_5[_LinuxError_index_5[i]:_LinuxError_index_5[i+1]]
 	case 20 <= i && i <= 22:
 		i -= 20
-		return _LinuxError_name_4[_LinuxError_index_4[i]:_LinuxError_index_4[i+1]]
+		return _LinuxError_name_6[_LinuxError_index_6[i]:_LinuxError_index_6[i+1]]
+	case i == 24:
+		return _LinuxError_name_7
+	case i == 28:
+		return _LinuxError_name_8
+	case i == 36:
+		return _LinuxError_name_9
 	case 39 <= i && i <= 40:
 		i -= 39
-		return _LinuxError_name_5[_LinuxError_index_5[i]:_LinuxError_index_5[i+1]]
+		return _LinuxError_name_10[_LinuxError_index_10[i]:_LinuxError_index_10[i+1]]
+	case i == 95:
+		return _LinuxError_name_11
 	default:
 		return "LinuxError(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
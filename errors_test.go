@@ -80,4 +80,24 @@ func TestErrors(t *testing.T) {
 	if uuiErr.Error() != wantErrStr {
 		t.Errorf("UnknownUserIdError : want error to be %s, got %s", wantErrStr, uuiErr.Error())
 	}
+
+	wantErrStr = "interrupted system call"
+	if avfs.ErrInterrupted.Error() != wantErrStr {
+		t.Errorf("ErrInterrupted : want error to be %s, got %s", wantErrStr, avfs.ErrInterrupted.Error())
+	}
+
+	wantErrStr = "device or resource busy"
+	if avfs.ErrDeviceBusy.Error() != wantErrStr {
+		t.Errorf("ErrDeviceBusy : want error to be %s, got %s", wantErrStr, avfs.ErrDeviceBusy.Error())
+	}
+
+	wantErrStr = "no space left on device"
+	if avfs.ErrNoSpace.Error() != wantErrStr {
+		t.Errorf("ErrNoSpace : want error to be %s, got %s", wantErrStr, avfs.ErrNoSpace.Error())
+	}
+
+	wantErrStr = "There is not enough space on the disk."
+	if avfs.ErrWinDiskFull.Error() != wantErrStr {
+		t.Errorf("ErrWinDiskFull : want error to be %s, got %s", wantErrStr, avfs.ErrWinDiskFull.Error())
+	}
 }
@@ -0,0 +1,100 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"io/fs"
+	"slices"
+	"time"
+)
+
+// EvictOptions defines the options of EvictLRU.
+type EvictOptions struct {
+	// AccessTime returns the last access time of the file path, from a sidecar index for example.
+	// If nil, the access time of the SysStater returned by ToSysStat is used when it implements AccessTimer,
+	// the modification time otherwise.
+	AccessTime func(path string, info fs.FileInfo) time.Time
+}
+
+// AccessTimer is the optional interface implemented by the SysStater of file systems recording access times.
+type AccessTimer interface {
+	// Atime returns the last access time of the file.
+	Atime() time.Time
+}
+
+// ChangeTimer is the optional interface implemented by the SysStater of file systems recording change times.
+type ChangeTimer interface {
+	// Ctime returns the last change time of the content or of the metadata of the file.
+	Ctime() time.Time
+}
+
+// evictFile is a candidate file for the eviction.
+type evictFile struct {
+	atime time.Time
+	path  string
+	size  int64
+}
+
+// EvictLRU removes the least recently used regular files under dir until their total size
+// is lower or equal to budget, opts can be nil to use the default options.
+// Files with the same access time are removed in lexical order.
+// It returns the removed files in the order of their removal, even if an error occurred.
+func EvictLRU[T VFSBase](vfs T, dir string, budget int64, opts *EvictOptions) ([]string, error) {
+	accessTime := func(_ string, info fs.FileInfo) time.Time {
+		if at, ok := vfs.ToSysStat(info).(AccessTimer); ok {
+			return at.Atime()
+		}
+
+		return info.ModTime()
+	}
+
+	if opts != nil && opts.AccessTime != nil {
+		accessTime = opts.AccessTime
+	}
+
+	var files []evictFile
+
+	usage := int64(0)
+
+	err := walkRegular(vfs, dir, func(path string, info fs.FileInfo) {
+		files = append(files, evictFile{atime: accessTime(path, info), path: path, size: info.Size()})
+		usage += info.Size()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(files, func(a, b evictFile) int { return a.atime.Compare(b.atime) })
+
+	var evicted []string
+
+	for _, f := range files {
+		if usage <= budget {
+			break
+		}
+
+		err = vfs.Remove(f.path)
+		if err != nil {
+			return evicted, err
+		}
+
+		evicted = append(evicted, f.path)
+		usage -= f.size
+	}
+
+	return evicted, nil
+}
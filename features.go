@@ -45,6 +45,10 @@ const (
 
 	// FeatSymlink indicates that the file system supports symbolic links (symlink(), evalSymlink() functions).
 	FeatSymlink
+
+	// FeatStrict is set when unsupported operations return an error instead of silently succeeding
+	// (see Options.Strict of MemFS and OrefaFS).
+	FeatStrict
 )
 
 // Featurer is the interface that wraps the Features and HasFeature methods.
@@ -16,9 +16,10 @@ func _() {
 	_ = x[FeatRealFS-32]
 	_ = x[FeatSubFS-64]
 	_ = x[FeatSymlink-128]
+	_ = x[FeatStrict-256]
 }
 
-const _Features_name = "HardlinkIdentityMgrSetOSTypeReadOnlyReadOnlyIdmRealFSSubFSSymlink"
+const _Features_name = "HardlinkIdentityMgrSetOSTypeReadOnlyReadOnlyIdmRealFSSubFSSymlinkStrict"
 
 var _Features_map = map[Features]string{
 	1:   _Features_name[0:8],
@@ -29,6 +30,7 @@ var _Features_map = map[Features]string{
 	32:  _Features_name[47:53],
 	64:  _Features_name[53:58],
 	128: _Features_name[58:65],
+	256: _Features_name[65:71],
 }
 
 func (i Features) String() string {
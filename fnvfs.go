@@ -45,6 +45,7 @@ const (
 	FnFileWriteAt
 	FnGetwd
 	FnLchown
+	FnLchtimes
 	FnLink
 	FnLstat
 	FnMkdir
@@ -59,6 +60,7 @@ const (
 	FnRename
 	FnSetUser
 	FnSetUserByName
+	FnSetUserByUid
 	FnStat
 	FnSub
 	FnSymlink
@@ -31,31 +31,33 @@ func _() {
 	_ = x[FnFileWriteAt-21]
 	_ = x[FnGetwd-22]
 	_ = x[FnLchown-23]
-	_ = x[FnLink-24]
-	_ = x[FnLstat-25]
-	_ = x[FnMkdir-26]
-	_ = x[FnMkdirAll-27]
-	_ = x[FnMkdirTemp-28]
-	_ = x[FnOpenFile-29]
-	_ = x[FnReadDir-30]
-	_ = x[FnReadFile-31]
-	_ = x[FnReadlink-32]
-	_ = x[FnRemove-33]
-	_ = x[FnRemoveAll-34]
-	_ = x[FnRename-35]
-	_ = x[FnSetUser-36]
-	_ = x[FnSetUserByName-37]
-	_ = x[FnStat-38]
-	_ = x[FnSub-39]
-	_ = x[FnSymlink-40]
-	_ = x[FnTruncate-41]
-	_ = x[FnWalkDir-42]
-	_ = x[FnWriteFile-43]
+	_ = x[FnLchtimes-24]
+	_ = x[FnLink-25]
+	_ = x[FnLstat-26]
+	_ = x[FnMkdir-27]
+	_ = x[FnMkdirAll-28]
+	_ = x[FnMkdirTemp-29]
+	_ = x[FnOpenFile-30]
+	_ = x[FnReadDir-31]
+	_ = x[FnReadFile-32]
+	_ = x[FnReadlink-33]
+	_ = x[FnRemove-34]
+	_ = x[FnRemoveAll-35]
+	_ = x[FnRename-36]
+	_ = x[FnSetUser-37]
+	_ = x[FnSetUserByName-38]
+	_ = x[FnSetUserByUid-39]
+	_ = x[FnStat-40]
+	_ = x[FnSub-41]
+	_ = x[FnSymlink-42]
+	_ = x[FnTruncate-43]
+	_ = x[FnWalkDir-44]
+	_ = x[FnWriteFile-45]
 }
 
-const _FnVFS_name = "AbsChdirChmodChownChtimesCreateTempEvalSymlinksFileChdirFileChmodFileChownFileCloseFileReadFileReadAtFileReadDirFileReaddirnamesFileSeekFileStatFileSyncFileTruncateFileWriteFileWriteAtGetwdLchownLinkLstatMkdirMkdirAllMkdirTempOpenFileReadDirReadFileReadlinkRemoveRemoveAllRenameSetUserSetUserByNameStatSubSymlinkTruncateWalkDirWriteFile"
+const _FnVFS_name = "AbsChdirChmodChownChtimesCreateTempEvalSymlinksFileChdirFileChmodFileChownFileCloseFileReadFileReadAtFileReadDirFileReaddirnamesFileSeekFileStatFileSyncFileTruncateFileWriteFileWriteAtGetwdLchownLchtimesLinkLstatMkdirMkdirAllMkdirTempOpenFileReadDirReadFileReadlinkRemoveRemoveAllRenameSetUserSetUserByNameSetUserByUidStatSubSymlinkTruncateWalkDirWriteFile"
 
-var _FnVFS_index = [...]uint16{0, 3, 8, 13, 18, 25, 35, 47, 56, 65, 74, 83, 91, 101, 112, 128, 136, 144, 152, 164, 173, 184, 189, 195, 199, 204, 209, 217, 226, 234, 241, 249, 257, 263, 272, 278, 285, 298, 302, 305, 312, 320, 327, 336}
+var _FnVFS_index = [...]uint16{0, 3, 8, 13, 18, 25, 35, 47, 56, 65, 74, 83, 91, 101, 112, 128, 136, 144, 152, 164, 173, 184, 189, 195, 203, 207, 212, 217, 225, 234, 242, 249, 257, 265, 271, 280, 286, 293, 306, 318, 322, 325, 332, 340, 347, 356}
 
 func (i FnVFS) String() string {
 	i -= 1
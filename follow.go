@@ -0,0 +1,236 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// FollowPollInterval is the interval between two checks of a followed file
+// for new data, truncation or rotation.
+const FollowPollInterval = 100 * time.Millisecond
+
+// follower is the io.ReadCloser returned by Follow.
+type follower struct {
+	vfs         VFSBase          // vfs is the file system of the followed file.
+	f           File             // f is the currently opened file, nil if the file does not exist yet.
+	events      <-chan SizeEvent // events receives the size changes of f if vfs is a SizeWatcher.
+	cancelWatch func()           // cancelWatch cancels the size watcher of f.
+	done        <-chan struct{}  // done is closed when the context passed to Follow is done.
+	ctxErr      func() error     // ctxErr returns the error of the context passed to Follow.
+	closing     chan struct{}    // closing is closed by Close to interrupt a pending Read.
+	path        string           // path is the name of the followed file.
+	offset      int64            // offset is the current read position in f.
+	mu          sync.Mutex       // mu is the mutex used to access the fields of follower.
+	closeOnce   sync.Once        // closeOnce ensures that closing is only closed once.
+}
+
+// Follow returns a reader of the named file which behaves like "tail -F" :
+// instead of returning io.EOF, Read waits for new data to be written to the file.
+// The file is read again from the beginning when it is truncated
+// or when it is rotated (renamed or removed and recreated under the same name).
+// Reading starts at the beginning of the file. If the file does not exist yet,
+// Read waits for its creation.
+//
+// New data is detected immediately on file systems implementing SizeWatcher,
+// other file systems are polled every FollowPollInterval.
+// Read returns the context error once ctx is done.
+// If there is an error, it will be of type *PathError.
+func Follow(ctx context.Context, vfs VFSBase, path string) (io.ReadCloser, error) {
+	fl := &follower{
+		vfs:     vfs,
+		done:    ctx.Done(),
+		ctxErr:  ctx.Err,
+		closing: make(chan struct{}),
+		path:    path,
+	}
+
+	err := fl.open()
+	if err != nil && !IsNotExist(err) {
+		return nil, err
+	}
+
+	return fl, nil
+}
+
+// Close closes the follower, interrupting any pending Read.
+func (fl *follower) Close() error {
+	fl.closeOnce.Do(func() { close(fl.closing) })
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	if fl.f == nil {
+		return nil
+	}
+
+	return fl.closeFile()
+}
+
+// closeFile closes the currently opened file and its size watcher.
+func (fl *follower) closeFile() error {
+	if fl.cancelWatch != nil {
+		fl.cancelWatch()
+		fl.cancelWatch = nil
+		fl.events = nil
+	}
+
+	err := fl.f.Close()
+	fl.f = nil
+
+	return err
+}
+
+// isClosed returns true if Close was called.
+func (fl *follower) isClosed() bool {
+	select {
+	case <-fl.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// open opens the followed file from the beginning.
+func (fl *follower) open() error {
+	f, err := fl.vfs.OpenFile(fl.path, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	fl.f = f
+	fl.offset = 0
+
+	if sw, ok := fl.vfs.(SizeWatcher); ok {
+		events, cancel, err := sw.WatchSize(fl.path)
+		if err == nil {
+			fl.events = events
+			fl.cancelWatch = cancel
+		}
+	}
+
+	return nil
+}
+
+// Read reads up to len(b) bytes from the followed file.
+// It blocks until new data is available, the context is done or the follower is closed.
+func (fl *follower) Read(b []byte) (int, error) {
+	const op = "follow"
+
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	for {
+		if fl.isClosed() {
+			return 0, &fs.PathError{Op: op, Path: fl.path, Err: fs.ErrClosed}
+		}
+
+		if err := fl.ctxErr(); err != nil {
+			return 0, err
+		}
+
+		if fl.f == nil {
+			err := fl.open()
+			if err != nil && !IsNotExist(err) {
+				return 0, err
+			}
+
+			if err != nil {
+				fl.wait()
+
+				continue
+			}
+		}
+
+		n, err := fl.f.Read(b)
+		fl.offset += int64(n)
+
+		if n > 0 {
+			return n, nil
+		}
+
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		reopened, err := fl.reopen()
+		if err != nil {
+			return 0, err
+		}
+
+		if !reopened {
+			fl.wait()
+		}
+	}
+}
+
+// reopen rewinds the followed file if it was truncated or reopens it if it was rotated.
+// It returns true if the file was rewound or reopened.
+func (fl *follower) reopen() (bool, error) {
+	info, err := fl.f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if info.Size() < fl.offset {
+		_, err = fl.f.Seek(0, io.SeekStart)
+		fl.offset = 0
+
+		return true, err
+	}
+
+	pathInfo, err := fl.vfs.Stat(fl.path)
+	if err != nil {
+		if IsNotExist(err) {
+			// The file was removed, wait for its creation.
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if fl.vfs.SameFile(info, pathInfo) {
+		return false, nil
+	}
+
+	_ = fl.closeFile()
+
+	err = fl.open()
+	if err != nil && !IsNotExist(err) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// wait waits for a size change, the next poll, the end of the context or the closing of the follower.
+func (fl *follower) wait() {
+	timer := time.NewTimer(FollowPollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-fl.events:
+	case <-timer.C:
+	case <-fl.done:
+	case <-fl.closing:
+	}
+}
@@ -0,0 +1,62 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import "sync"
+
+// Freezer is the interface that wraps the Freeze and Thaw methods.
+type Freezer interface {
+	// Freeze waits for the mutations in progress to complete and blocks new mutations until Thaw is called.
+	// Reads are still allowed while the file system is frozen.
+	Freeze()
+
+	// Thaw resumes the mutations blocked by Freeze.
+	Thaw()
+}
+
+// FreezeFn provides freeze functions to a file system.
+// Each mutation of the file system must be enclosed by StartMutation and EndMutation.
+type FreezeFn struct {
+	mu sync.RWMutex // mu is read locked by the mutations in progress and locked while the file system is frozen.
+}
+
+// EndMutation ends a mutation started by StartMutation.
+func (fz *FreezeFn) EndMutation() {
+	fz.mu.RUnlock()
+}
+
+// Freeze waits for the mutations in progress to complete and blocks new mutations until Thaw is called.
+// Reads are still allowed while the file system is frozen.
+// A goroutine must not mutate the file system it has frozen before calling Thaw.
+func (fz *FreezeFn) Freeze() {
+	fz.mu.Lock()
+}
+
+// StartMutation waits while the file system is frozen.
+// It is intended to be called at the beginning of an operation modifying the file system :
+//
+//	vfs.StartMutation()
+//	defer vfs.EndMutation()
+func (fz *FreezeFn) StartMutation() {
+	fz.mu.RLock()
+}
+
+// Thaw resumes the mutations blocked by Freeze.
+// It is a run-time error if the file system is not frozen.
+func (fz *FreezeFn) Thaw() {
+	fz.mu.Unlock()
+}
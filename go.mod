@@ -1,3 +1,8 @@
 module github.com/avfs/avfs
 
 go 1.22
+
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	golang.org/x/sys v0.30.0
+)
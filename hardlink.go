@@ -0,0 +1,128 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"io/fs"
+	"slices"
+)
+
+// FindHardlinkGroups returns the groups of paths of the regular files under root sharing the same inode,
+// each group holding at least two paths in lexical order.
+// Files are identified by device and inode when the SysStater returned by ToSysStat implements InodeStater,
+// by SameFile otherwise. File systems reporting a single link for every file (see SysStater.Nlink) have no groups.
+func FindHardlinkGroups[T VFSBase](vfs T, root string) ([][]string, error) {
+	hl := newHardlinks(vfs)
+
+	err := walkRegular(vfs, root, func(path string, info fs.FileInfo) {
+		if vfs.ToSysStat(info).Nlink() > 1 {
+			hl.add(path, info)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := slices.DeleteFunc(hl.groups, func(group []string) bool { return len(group) < 2 })
+
+	return groups, nil
+}
+
+// TreeSize returns the total size of the regular files under root,
+// the size of files linked several times under root being counted once.
+func TreeSize[T VFSBase](vfs T, root string) (int64, error) {
+	hl := newHardlinks(vfs)
+	size := int64(0)
+
+	err := walkRegular(vfs, root, func(path string, info fs.FileInfo) {
+		if vfs.ToSysStat(info).Nlink() > 1 {
+			if _, added := hl.add(path, info); !added {
+				return
+			}
+		}
+
+		size += info.Size()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// hardlinks groups the paths of files sharing the same inode.
+type hardlinks struct {
+	vfs    VFSBase
+	byIno  map[[2]uint64]int // byIno is the index of the group by device and inode.
+	infos  []fs.FileInfo     // infos are the file infos of the first path of each group.
+	groups [][]string        // groups are the paths of each group.
+}
+
+// newHardlinks returns a new empty hardlinks.
+func newHardlinks(vfs VFSBase) *hardlinks {
+	return &hardlinks{vfs: vfs, byIno: make(map[[2]uint64]int)}
+}
+
+// add adds path to the group of its inode, it returns the first path of the group
+// and reports whether path starts a new group.
+func (hl *hardlinks) add(path string, info fs.FileInfo) (first string, added bool) {
+	if is, ok := hl.vfs.ToSysStat(info).(InodeStater); ok {
+		key := [2]uint64{is.Dev(), is.Ino()}
+		if i, ok := hl.byIno[key]; ok {
+			hl.groups[i] = append(hl.groups[i], path)
+
+			return hl.groups[i][0], false
+		}
+
+		hl.byIno[key] = len(hl.groups)
+	} else {
+		for i, firstInfo := range hl.infos {
+			if hl.vfs.SameFile(firstInfo, info) {
+				hl.groups[i] = append(hl.groups[i], path)
+
+				return hl.groups[i][0], false
+			}
+		}
+	}
+
+	hl.infos = append(hl.infos, info)
+	hl.groups = append(hl.groups, []string{path})
+
+	return path, true
+}
+
+// walkRegular calls fn for each regular file under root in lexical order.
+func walkRegular[T VFSBase](vfs T, root string, fn func(path string, info fs.FileInfo)) error {
+	return WalkDir(vfs, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := vfs.Lstat(path)
+		if err != nil {
+			return err
+		}
+
+		fn(path, info)
+
+		return nil
+	})
+}
@@ -0,0 +1,166 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"hash"
+	"io/fs"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// HashTreeOptions are the options of HashTree.
+type HashTreeOptions struct {
+	// Workers is the number of concurrent workers hashing the regular files,
+	// runtime.GOMAXPROCS(0) if it is 0 or less.
+	Workers int
+}
+
+// TreeHash is the result of HashTree.
+type TreeHash struct {
+	// Files are the digests of the regular files and of the slash separated targets of the symbolic links
+	// by slash separated path relative to the root. The other files (named pipes, devices, sockets)
+	// have no digest, only their type is hashed in the aggregate digest.
+	Files map[string][]byte
+
+	// Sum is the aggregate digest of the tree, computed from the paths, the types and the digests of all
+	// the files and directories of the tree sorted by path.
+	Sum []byte
+}
+
+// hashTreeEntry is a file or a directory of a tree hashed by HashTree.
+type hashTreeEntry struct {
+	path  string // path is the slash separated path relative to the root.
+	fType byte   // fType is the type of the file : 'd' for a directory, 'f' for a regular file, 'l' for a symbolic link, 'o' for other files.
+}
+
+// HashTree hashes the regular files and the targets of the symbolic links of the directory tree root of vfs
+// with hashers returned by newHash and returns their digests and the aggregate digest of the tree.
+// The regular files are hashed concurrently (see HashTreeOptions.Workers).
+// Permissions, owners and times are not hashed, the digests of identical trees are the same
+// on all the file systems and operating systems, which makes them usable for golden file comparisons.
+// If opts is nil, the default options are used.
+func HashTree(vfs VFSBase, root string, newHash func() hash.Hash, opts *HashTreeOptions) (*TreeHash, error) {
+	workers := runtime.GOMAXPROCS(0)
+	if opts != nil && opts.Workers > 0 {
+		workers = opts.Workers
+	}
+
+	th := &TreeHash{Files: make(map[string][]byte)}
+
+	var (
+		entries  []hashTreeEntry
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	paths := make(chan string, workers)
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			hasher := newHash()
+
+			for rel := range paths {
+				sum, err := HashFile(vfs, vfs.Join(root, vfs.FromSlash(rel)), hasher)
+
+				mu.Lock()
+
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+
+				th.Files[rel] = sum
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := vfs.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+
+		rel, err := relSlash(vfs, root, path)
+		if err != nil {
+			return err
+		}
+
+		switch d.Type() {
+		case fs.ModeDir:
+			entries = append(entries, hashTreeEntry{path: rel, fType: 'd'})
+		case fs.ModeSymlink:
+			link, err := vfs.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			hasher := newHash()
+			_, _ = hasher.Write([]byte(vfs.ToSlash(link)))
+
+			mu.Lock()
+			th.Files[rel] = hasher.Sum(nil)
+			mu.Unlock()
+
+			entries = append(entries, hashTreeEntry{path: rel, fType: 'l'})
+		case 0:
+			entries = append(entries, hashTreeEntry{path: rel, fType: 'f'})
+			paths <- rel
+		default:
+			// Reading a named pipe or a device could block or never end, only their type is hashed.
+			entries = append(entries, hashTreeEntry{path: rel, fType: 'o'})
+		}
+
+		mu.Lock()
+		err = firstErr
+		mu.Unlock()
+
+		return err
+	})
+
+	close(paths)
+	wg.Wait()
+
+	if err == nil {
+		err = firstErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(entries, func(a, b hashTreeEntry) int { return cmpSlashPath(a.path, b.path) })
+
+	hasher := newHash()
+
+	for _, entry := range entries {
+		_, _ = hasher.Write([]byte(entry.path))
+		_, _ = hasher.Write([]byte{0, entry.fType})
+		_, _ = hasher.Write(th.Files[entry.path])
+		_, _ = hasher.Write([]byte{'\n'})
+	}
+
+	th.Sum = hasher.Sum(nil)
+
+	return th, nil
+}
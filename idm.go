@@ -16,6 +16,11 @@
 
 package avfs
 
+import (
+	"slices"
+	"sync"
+)
+
 // IdentityMgr interface manages identities (users and groups).
 type IdentityMgr interface {
 	Featurer
@@ -61,6 +66,107 @@ type IdentityMgr interface {
 	LookupUserId(uid int) (UserReader, error)
 }
 
+// IdmEventType is the type of change of the users or the groups of an identity manager.
+type IdmEventType uint8
+
+//go:generate stringer -type IdmEventType -linecomment -output idmeventtype_string.go
+
+const (
+	IdmUserAdded        IdmEventType = iota + 1 // user added
+	IdmUserRemoved                              // user removed
+	IdmUserGroupChanged                         // user group changed
+	IdmGroupAdded                               // group added
+	IdmGroupRemoved                             // group removed
+)
+
+// IdmEvent describes a change of the users or the groups of an identity manager.
+type IdmEvent struct {
+	User  UserReader   // User is the user added, removed or whose groups changed, nil for group events.
+	Group GroupReader  // Group is the group added or removed, the primary group of the user for user events or the group joined or left.
+	Type  IdmEventType // Type is the type of change.
+}
+
+// IdmLister is the interface that wraps the Users and Groups methods of identity managers.
+type IdmLister interface {
+	// Groups returns the groups of the identity manager sorted by group id.
+	Groups() []GroupReader
+
+	// Users returns the users of the identity manager sorted by user id.
+	Users() []UserReader
+}
+
+// IdmMembership is the interface that wraps the AddGroupMember and DelGroupMember methods of identity managers.
+type IdmMembership interface {
+	// AddGroupMember adds the user userName to the supplementary group groupName and returns the updated user.
+	// Adding a member of the group does nothing.
+	// If the user is not found, the returned error is of type UnknownUserError.
+	// If the group is not found, the returned error is of type UnknownGroupError.
+	AddGroupMember(userName, groupName string) (UserReader, error)
+
+	// DelGroupMember removes the user userName from the supplementary group groupName and returns the updated user.
+	// Removing a user which is not a member of the group does nothing.
+	// If the user is not found, the returned error is of type UnknownUserError.
+	// If the group is not found, the returned error is of type UnknownGroupError.
+	DelGroupMember(userName, groupName string) (UserReader, error)
+}
+
+// IdmSubscriber is the interface that wraps the Subscribe method of identity managers.
+type IdmSubscriber interface {
+	// Subscribe registers fn to be called after each change of the users or the groups
+	// of the identity manager until cancel is called.
+	// fn is called synchronously, in the order of the subscriptions, by the goroutine making the change
+	// once the identity manager is unlocked : it can look up users and groups but should not modify them.
+	Subscribe(fn func(event IdmEvent)) (cancel func())
+}
+
+// IdmEventsFn provides subscription functions to an identity manager.
+type IdmEventsFn struct {
+	subs   []idmSubscription // subs are the subscriptions in order.
+	lastId int               // lastId is the id of the last subscription.
+	mu     sync.Mutex        // mu is the mutex of the subscriptions.
+}
+
+// idmSubscription is a subscription to the events of an identity manager.
+type idmSubscription struct {
+	fn func(event IdmEvent) // fn is the function called for each event.
+	id int                  // id identifies the subscription.
+}
+
+// Subscribe registers fn to be called after each change of the users or the groups
+// of the identity manager until cancel is called.
+func (ief *IdmEventsFn) Subscribe(fn func(event IdmEvent)) (cancel func()) {
+	ief.mu.Lock()
+	defer ief.mu.Unlock()
+
+	ief.lastId++
+	id := ief.lastId
+	ief.subs = append(ief.subs, idmSubscription{fn: fn, id: id})
+
+	return sync.OnceFunc(func() {
+		ief.mu.Lock()
+		defer ief.mu.Unlock()
+
+		ief.subs = slices.DeleteFunc(ief.subs, func(sub idmSubscription) bool { return sub.id == id })
+	})
+}
+
+// NotifyIdmEvent calls the subscribed functions with event.
+// Events without type are ignored, it can be deferred by identity managers before locking
+// with a pointer to an event set once the change is made.
+func (ief *IdmEventsFn) NotifyIdmEvent(event *IdmEvent) {
+	if event.Type == 0 {
+		return
+	}
+
+	ief.mu.Lock()
+	subs := slices.Clone(ief.subs)
+	ief.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.fn(*event)
+	}
+}
+
 // UserReader reads user information.
 type UserReader interface {
 	GroupIdentifier
@@ -77,6 +183,29 @@ type GroupIdentifier interface {
 	Gid() int
 }
 
+// GroupMember is the interface that wraps the Groups method of users belonging to supplementary groups.
+type GroupMember interface {
+	// Groups returns the ids of the supplementary groups of the user sorted in ascending order.
+	// The returned slice must not be modified.
+	Groups() []int
+}
+
+// InGroup returns true if gid is the primary group of the user u or one of its supplementary groups.
+func InGroup(u UserReader, gid int) bool {
+	if u.Gid() == gid {
+		return true
+	}
+
+	gm, ok := u.(GroupMember)
+	if !ok {
+		return false
+	}
+
+	_, found := slices.BinarySearch(gm.Groups(), gid)
+
+	return found
+}
+
 // GroupReader interface reads group information.
 type GroupReader interface {
 	GroupIdentifier
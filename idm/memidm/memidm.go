@@ -17,7 +17,12 @@
 // Package memidm implements an in memory identity manager.
 package memidm
 
-import "github.com/avfs/avfs"
+import (
+	"cmp"
+	"slices"
+
+	"github.com/avfs/avfs"
+)
 
 // AdminGroup returns the administrator (root) group.
 func (idm *MemIdm) AdminGroup() avfs.GroupReader {
@@ -32,6 +37,9 @@ func (idm *MemIdm) AdminUser() avfs.UserReader {
 // AddGroup creates a new group with the specified name.
 // If the group already exists, the returned error is of type avfs.AlreadyExistsGroupError.
 func (idm *MemIdm) AddGroup(name string) (avfs.GroupReader, error) {
+	var event avfs.IdmEvent
+	defer idm.NotifyIdmEvent(&event)
+
 	idm.grpMu.Lock()
 	defer idm.grpMu.Unlock()
 
@@ -45,6 +53,7 @@ func (idm *MemIdm) AddGroup(name string) (avfs.GroupReader, error) {
 	g := &MemGroup{name: name, gid: gid}
 	idm.groupsByName[name] = g
 	idm.groupsById[gid] = g
+	event = avfs.IdmEvent{Type: avfs.IdmGroupAdded, Group: g}
 
 	return g, nil
 }
@@ -57,6 +66,9 @@ func (idm *MemIdm) AddUser(name, groupName string) (avfs.UserReader, error) {
 		return nil, err
 	}
 
+	var event avfs.IdmEvent
+	defer idm.NotifyIdmEvent(&event)
+
 	idm.usrMu.Lock()
 	defer idm.usrMu.Unlock()
 
@@ -75,6 +87,69 @@ func (idm *MemIdm) AddUser(name, groupName string) (avfs.UserReader, error) {
 
 	idm.usersByName[name] = u
 	idm.usersById[uid] = u
+	event = avfs.IdmEvent{Type: avfs.IdmUserAdded, User: u, Group: g}
+
+	return u, nil
+}
+
+// AddGroupMember adds the user userName to the supplementary group groupName and returns the updated user.
+// The users previously returned for userName are not modified.
+// Adding a member of the group does nothing.
+// If the user is not found, the returned error is of type avfs.UnknownUserError.
+// If the group is not found, the returned error is of type avfs.UnknownGroupError.
+func (idm *MemIdm) AddGroupMember(userName, groupName string) (avfs.UserReader, error) {
+	return idm.setGroupMember(userName, groupName, true)
+}
+
+// DelGroupMember removes the user userName from the supplementary group groupName and returns the updated user.
+// The users previously returned for userName are not modified.
+// Removing a user which is not a member of the group does nothing.
+// If the user is not found, the returned error is of type avfs.UnknownUserError.
+// If the group is not found, the returned error is of type avfs.UnknownGroupError.
+func (idm *MemIdm) DelGroupMember(userName, groupName string) (avfs.UserReader, error) {
+	return idm.setGroupMember(userName, groupName, false)
+}
+
+// setGroupMember adds (member is true) or removes the user userName from the supplementary group groupName.
+func (idm *MemIdm) setGroupMember(userName, groupName string, member bool) (avfs.UserReader, error) {
+	g, err := idm.LookupGroup(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	var event avfs.IdmEvent
+	defer idm.NotifyIdmEvent(&event)
+
+	idm.usrMu.Lock()
+	defer idm.usrMu.Unlock()
+
+	u, ok := idm.usersByName[userName]
+	if !ok {
+		return nil, avfs.UnknownUserError(userName)
+	}
+
+	i, found := slices.BinarySearch(u.groups, g.Gid())
+	if found == member {
+		return u, nil
+	}
+
+	groups := slices.Clone(u.groups)
+	if member {
+		groups = slices.Insert(groups, i, g.Gid())
+	} else {
+		groups = slices.Delete(groups, i, i+1)
+	}
+
+	u = &MemUser{
+		name:   u.name,
+		groups: groups,
+		uid:    u.uid,
+		gid:    u.gid,
+	}
+
+	idm.usersByName[userName] = u
+	idm.usersById[u.uid] = u
+	event = avfs.IdmEvent{Type: avfs.IdmUserGroupChanged, User: u, Group: g}
 
 	return u, nil
 }
@@ -82,6 +157,9 @@ func (idm *MemIdm) AddUser(name, groupName string) (avfs.UserReader, error) {
 // DelGroup deletes an existing group with the specified name.
 // If the group is not found, the returned error is of type avfs.UnknownGroupError.
 func (idm *MemIdm) DelGroup(name string) error {
+	var event avfs.IdmEvent
+	defer idm.NotifyIdmEvent(&event)
+
 	idm.grpMu.Lock()
 	defer idm.grpMu.Unlock()
 
@@ -92,6 +170,7 @@ func (idm *MemIdm) DelGroup(name string) error {
 
 	delete(idm.groupsByName, g.name)
 	delete(idm.groupsById, g.gid)
+	event = avfs.IdmEvent{Type: avfs.IdmGroupRemoved, Group: g}
 
 	return nil
 }
@@ -99,6 +178,9 @@ func (idm *MemIdm) DelGroup(name string) error {
 // DelUser deletes an existing user with the specified name.
 // If the user is not found, the returned error is of type avfs.UnknownUserError.
 func (idm *MemIdm) DelUser(name string) error {
+	var event avfs.IdmEvent
+	defer idm.NotifyIdmEvent(&event)
+
 	idm.usrMu.Lock()
 	defer idm.usrMu.Unlock()
 
@@ -109,10 +191,26 @@ func (idm *MemIdm) DelUser(name string) error {
 
 	delete(idm.usersByName, u.name)
 	delete(idm.usersById, u.uid)
+	event = avfs.IdmEvent{Type: avfs.IdmUserRemoved, User: u}
 
 	return nil
 }
 
+// Groups returns the groups of the identity manager sorted by group id.
+func (idm *MemIdm) Groups() []avfs.GroupReader {
+	idm.grpMu.RLock()
+	defer idm.grpMu.RUnlock()
+
+	groups := make([]avfs.GroupReader, 0, len(idm.groupsById))
+	for _, g := range idm.groupsById {
+		groups = append(groups, g)
+	}
+
+	slices.SortFunc(groups, func(a, b avfs.GroupReader) int { return cmp.Compare(a.Gid(), b.Gid()) })
+
+	return groups
+}
+
 // LookupGroup looks up a group by name.
 // If the group is not found, the returned error is of type avfs.UnknownGroupError.
 func (idm *MemIdm) LookupGroup(name string) (avfs.GroupReader, error) {
@@ -169,6 +267,56 @@ func (idm *MemIdm) LookupUserId(uid int) (avfs.UserReader, error) {
 	return u, nil
 }
 
+// SetUserGroup sets the primary group of the user userName to the group groupName.
+// The users previously returned for userName are not modified, they keep their former primary group.
+// If the user is not found, the returned error is of type avfs.UnknownUserError.
+// If the group is not found, the returned error is of type avfs.UnknownGroupError.
+func (idm *MemIdm) SetUserGroup(userName, groupName string) (avfs.UserReader, error) {
+	g, err := idm.LookupGroup(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	var event avfs.IdmEvent
+	defer idm.NotifyIdmEvent(&event)
+
+	idm.usrMu.Lock()
+	defer idm.usrMu.Unlock()
+
+	u, ok := idm.usersByName[userName]
+	if !ok {
+		return nil, avfs.UnknownUserError(userName)
+	}
+
+	u = &MemUser{
+		name:   u.name,
+		groups: u.groups,
+		uid:    u.uid,
+		gid:    g.Gid(),
+	}
+
+	idm.usersByName[userName] = u
+	idm.usersById[u.uid] = u
+	event = avfs.IdmEvent{Type: avfs.IdmUserGroupChanged, User: u, Group: g}
+
+	return u, nil
+}
+
+// Users returns the users of the identity manager sorted by user id.
+func (idm *MemIdm) Users() []avfs.UserReader {
+	idm.usrMu.RLock()
+	defer idm.usrMu.RUnlock()
+
+	users := make([]avfs.UserReader, 0, len(idm.usersById))
+	for _, u := range idm.usersById {
+		users = append(users, u)
+	}
+
+	slices.SortFunc(users, func(a, b avfs.UserReader) int { return cmp.Compare(a.Uid(), b.Uid()) })
+
+	return users
+}
+
 // MemUser
 
 // Name returns the user name.
@@ -181,6 +329,12 @@ func (u *MemUser) Gid() int {
 	return u.gid
 }
 
+// Groups returns the ids of the supplementary groups of the user sorted in ascending order.
+// The returned slice is shared by the calls and must not be modified.
+func (u *MemUser) Groups() []int {
+	return u.groups
+}
+
 // IsAdmin returns true if the user has administrator (root) privileges.
 func (u *MemUser) IsAdmin() bool {
 	return u.uid == 0 || u.gid == 0
@@ -0,0 +1,143 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memidm
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/avfs/avfs"
+)
+
+// Save writes the users and the groups of the identity manager to the file name of the file system vfs
+// in JSON format, the file is created or truncated.
+func (idm *MemIdm) Save(vfs avfs.VFSBase, name string) error {
+	var data idmData
+
+	for _, g := range idm.Groups() {
+		data.Groups = append(data.Groups, groupData{Name: g.Name(), Gid: g.Gid()})
+	}
+
+	for _, u := range idm.Users() {
+		var groups []int
+		if gm, ok := u.(avfs.GroupMember); ok {
+			groups = gm.Groups()
+		}
+
+		data.Users = append(data.Users, userData{Name: u.Name(), Groups: groups, Uid: u.Uid(), Gid: u.Gid()})
+	}
+
+	buf, err := json.MarshalIndent(&data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return avfs.WriteFile(vfs, name, append(buf, '\n'), avfs.DefaultFilePerm)
+}
+
+// Load adds the users and the groups saved by Save in the file name of the file system vfs
+// to the identity manager, keeping their ids. The users and the groups already defined
+// with the same name and id are left unchanged, so that a file can be loaded by several identity managers
+// or several times by the same one.
+// If a group name or id is already used by another group, the returned error is of type avfs.AlreadyExistsGroupError.
+// If a user name or id is already used by another user, the returned error is of type avfs.AlreadyExistsUserError.
+// In case of error, no user or group is added.
+func (idm *MemIdm) Load(vfs avfs.VFSBase, name string) error {
+	buf, err := avfs.ReadFile(vfs, name)
+	if err != nil {
+		return err
+	}
+
+	var data idmData
+
+	err = json.Unmarshal(buf, &data)
+	if err != nil {
+		return err
+	}
+
+	var events []avfs.IdmEvent
+
+	defer func() {
+		for i := range events {
+			idm.NotifyIdmEvent(&events[i])
+		}
+	}()
+
+	idm.grpMu.Lock()
+	defer idm.grpMu.Unlock()
+
+	idm.usrMu.Lock()
+	defer idm.usrMu.Unlock()
+
+	var (
+		groups []*MemGroup
+		users  []*MemUser
+	)
+
+	for _, gd := range data.Groups {
+		gn, okName := idm.groupsByName[gd.Name]
+		gi, okId := idm.groupsById[gd.Gid]
+
+		switch {
+		case okName && okId && gn == gi:
+			continue
+		case okName || okId:
+			return avfs.AlreadyExistsGroupError(gd.Name)
+		}
+
+		groups = append(groups, &MemGroup{name: gd.Name, gid: gd.Gid})
+	}
+
+	for _, ud := range data.Users {
+		un, okName := idm.usersByName[ud.Name]
+		ui, okId := idm.usersById[ud.Uid]
+
+		switch {
+		case okName && okId && un == ui && un.gid == ud.Gid && slices.Equal(un.groups, ud.Groups):
+			continue
+		case okName || okId:
+			return avfs.AlreadyExistsUserError(ud.Name)
+		}
+
+		groups := slices.Clone(ud.Groups)
+		slices.Sort(groups)
+
+		users = append(users, &MemUser{name: ud.Name, groups: slices.Compact(groups), uid: ud.Uid, gid: ud.Gid})
+	}
+
+	for _, g := range groups {
+		idm.groupsByName[g.name] = g
+		idm.groupsById[g.gid] = g
+		idm.maxGid = max(idm.maxGid, g.gid)
+		events = append(events, avfs.IdmEvent{Type: avfs.IdmGroupAdded, Group: g})
+	}
+
+	for _, u := range users {
+		idm.usersByName[u.name] = u
+		idm.usersById[u.uid] = u
+		idm.maxUid = max(idm.maxUid, u.uid)
+
+		event := avfs.IdmEvent{Type: avfs.IdmUserAdded, User: u}
+		if g, ok := idm.groupsById[u.gid]; ok {
+			event.Group = g
+		}
+
+		events = append(events, event)
+	}
+
+	return nil
+}
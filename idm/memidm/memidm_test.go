@@ -24,6 +24,7 @@ import (
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/idm/memidm"
 	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/memfs"
 )
 
 var (
@@ -35,6 +36,18 @@ var (
 
 	// MemGroup implements avfs.GroupReader interface.
 	_ avfs.GroupReader = &memidm.MemGroup{}
+
+	// MemIdm implements avfs.IdmSubscriber interface.
+	_ avfs.IdmSubscriber = &memidm.MemIdm{}
+
+	// MemIdm implements avfs.IdmLister interface.
+	_ avfs.IdmLister = &memidm.MemIdm{}
+
+	// MemIdm implements avfs.IdmMembership interface.
+	_ avfs.IdmMembership = &memidm.MemIdm{}
+
+	// MemUser implements avfs.GroupMember interface.
+	_ avfs.GroupMember = &memidm.MemUser{}
 )
 
 // TestMemIdmAll run all tests.
@@ -59,3 +72,234 @@ func TestMemIdmFeatures(t *testing.T) {
 		t.Errorf("Features : want Features to be %d, got %d", avfs.FeatIdentityMgr, idm.Features())
 	}
 }
+
+func TestMemIdmSetUserGroup(t *testing.T) {
+	idm := memidm.New()
+	vfs := memfs.NewWithOptions(&memfs.Options{Idm: idm})
+
+	if vfs.OSType() == avfs.OsWindows {
+		t.Skip("SetUserGroup : group permissions are not checked on Windows")
+	}
+
+	const userName, groupName, otherGroupName = "user", "group", "other"
+
+	_, err := idm.AddGroup(groupName)
+	test.RequireNoError(t, err, "AddGroup %s", groupName)
+
+	otherGroup, err := idm.AddGroup(otherGroupName)
+	test.RequireNoError(t, err, "AddGroup %s", otherGroupName)
+
+	_, err = idm.AddUser(userName, groupName)
+	test.RequireNoError(t, err, "AddUser %s", userName)
+
+	path := vfs.Join(vfs.TempDir(), "file")
+
+	err = vfs.WriteFile(path, nil, 0o640)
+	test.RequireNoError(t, err, "WriteFile %s", path)
+
+	err = vfs.Chown(path, 0, otherGroup.Gid())
+	test.RequireNoError(t, err, "Chown %s", path)
+
+	// Permissions are evaluated again for the new primary group of the current user.
+	cancel := idm.Subscribe(func(event avfs.IdmEvent) {
+		if event.Type == avfs.IdmUserGroupChanged && event.User.Uid() == vfs.User().Uid() {
+			_ = vfs.SetUser(event.User)
+		}
+	})
+	defer cancel()
+
+	err = vfs.SetUserByName(userName)
+	test.RequireNoError(t, err, "SetUserByName %s", userName)
+
+	_, err = vfs.ReadFile(path)
+	test.AssertPathError(t, err).Op("open").Path(path).Err(avfs.ErrPermDenied).Test()
+
+	u, err := idm.SetUserGroup(userName, otherGroupName)
+	test.RequireNoError(t, err, "SetUserGroup %s", userName)
+
+	if u.Gid() != otherGroup.Gid() || vfs.User().Gid() != otherGroup.Gid() {
+		t.Errorf("SetUserGroup : want gid to be %d, got %d and %d", otherGroup.Gid(), u.Gid(), vfs.User().Gid())
+	}
+
+	_, err = vfs.ReadFile(path)
+	test.RequireNoError(t, err, "ReadFile %s", path)
+
+	_, err = idm.SetUserGroup("unknown", otherGroupName)
+	if want := avfs.UnknownUserError("unknown"); err != want {
+		t.Errorf("SetUserGroup : want error to be %v, got %v", want, err)
+	}
+
+	_, err = idm.SetUserGroup(userName, "unknown")
+	if want := avfs.UnknownGroupError("unknown"); err != want {
+		t.Errorf("SetUserGroup : want error to be %v, got %v", want, err)
+	}
+}
+
+func TestMemIdmGroupMember(t *testing.T) {
+	idm := memidm.New()
+
+	const userName, groupName, otherName = "user", "group", "other"
+
+	_, err := idm.AddGroup(groupName)
+	test.RequireNoError(t, err, "AddGroup %s", groupName)
+
+	other, err := idm.AddGroup(otherName)
+	test.RequireNoError(t, err, "AddGroup %s", otherName)
+
+	_, err = idm.AddUser(userName, groupName)
+	test.RequireNoError(t, err, "AddUser %s", userName)
+
+	var events int
+
+	cancel := idm.Subscribe(func(avfs.IdmEvent) { events++ })
+	defer cancel()
+
+	for range 2 {
+		_, err = idm.AddGroupMember(userName, otherName)
+		test.RequireNoError(t, err, "AddGroupMember %s", userName)
+	}
+
+	u, err := idm.SetUserGroup(userName, groupName)
+	test.RequireNoError(t, err, "SetUserGroup %s", userName)
+
+	if groups := u.(*memidm.MemUser).Groups(); len(groups) != 1 || groups[0] != other.Gid() {
+		t.Errorf("Groups : want supplementary groups to be [%d], got %v", other.Gid(), groups)
+	}
+
+	vfs := memfs.New()
+	path := vfs.Join(vfs.TempDir(), "idm.json")
+
+	err = idm.Save(vfs, path)
+	test.RequireNoError(t, err, "Save %s", path)
+
+	loadIdm := memidm.New()
+
+	err = loadIdm.Load(vfs, path)
+	test.RequireNoError(t, err, "Load %s", path)
+
+	lu, err := loadIdm.LookupUser(userName)
+	test.RequireNoError(t, err, "LookupUser %s", userName)
+
+	if !avfs.InGroup(lu, other.Gid()) {
+		t.Errorf("Load : want %s to be a member of %s", userName, otherName)
+	}
+
+	for range 2 {
+		u, err = idm.DelGroupMember(userName, otherName)
+		test.RequireNoError(t, err, "DelGroupMember %s", userName)
+	}
+
+	if avfs.InGroup(u, other.Gid()) {
+		t.Errorf("DelGroupMember : want %s not to be a member of %s", userName, otherName)
+	}
+
+	if events != 3 {
+		t.Errorf("Subscribe : want 3 events, got %d", events)
+	}
+}
+
+func TestMemIdmUsersGroups(t *testing.T) {
+	idm := memidm.New()
+
+	const userName, groupName = "user", "group"
+
+	g, err := idm.AddGroup(groupName)
+	test.RequireNoError(t, err, "AddGroup %s", groupName)
+
+	u, err := idm.AddUser(userName, groupName)
+	test.RequireNoError(t, err, "AddUser %s", userName)
+
+	groups := idm.Groups()
+	if len(groups) != 2 || groups[0].Gid() != 0 || groups[1] != g {
+		t.Errorf("Groups : want admin group and %s, got %v", groupName, groups)
+	}
+
+	users := idm.Users()
+	if len(users) != 2 || users[0].Uid() != 0 || users[1] != u {
+		t.Errorf("Users : want admin user and %s, got %v", userName, users)
+	}
+}
+
+func TestMemIdmSaveLoad(t *testing.T) {
+	vfs := memfs.New()
+	idm := memidm.New()
+
+	const userName, groupName = "user", "group"
+
+	g, err := idm.AddGroup(groupName)
+	test.RequireNoError(t, err, "AddGroup %s", groupName)
+
+	u, err := idm.AddUser(userName, groupName)
+	test.RequireNoError(t, err, "AddUser %s", userName)
+
+	path := vfs.Join(vfs.TempDir(), "idm.json")
+
+	err = idm.Save(vfs, path)
+	test.RequireNoError(t, err, "Save %s", path)
+
+	t.Run("LoadNew", func(t *testing.T) {
+		loadIdm := memidm.New()
+
+		var events []avfs.IdmEvent
+
+		cancel := loadIdm.Subscribe(func(event avfs.IdmEvent) { events = append(events, event) })
+		defer cancel()
+
+		err = loadIdm.Load(vfs, path)
+		test.RequireNoError(t, err, "Load %s", path)
+
+		lu, err := loadIdm.LookupUser(userName)
+		test.RequireNoError(t, err, "LookupUser %s", userName)
+
+		if lu.Uid() != u.Uid() || lu.Gid() != g.Gid() {
+			t.Errorf("LookupUser %s : want uid, gid to be %d, %d, got %d, %d", userName, u.Uid(), g.Gid(), lu.Uid(), lu.Gid())
+		}
+
+		if len(events) != 2 || events[0].Type != avfs.IdmGroupAdded || events[1].Type != avfs.IdmUserAdded {
+			t.Errorf("Load : want a group and a user added events, got %v", events)
+		}
+
+		err = loadIdm.Load(vfs, path)
+		test.RequireNoError(t, err, "Load %s", path)
+
+		if len(events) != 2 {
+			t.Errorf("Load : want no event for already loaded identities, got %d events", len(events))
+		}
+
+		const newName = "newUser"
+
+		nu, err := loadIdm.AddUser(newName, groupName)
+		test.RequireNoError(t, err, "AddUser %s", newName)
+
+		if nu.Uid() <= u.Uid() {
+			t.Errorf("AddUser %s : want uid to be greater than %d, got %d", newName, u.Uid(), nu.Uid())
+		}
+	})
+
+	t.Run("LoadConflict", func(t *testing.T) {
+		loadIdm := memidm.New()
+
+		const otherName = "other"
+
+		// The other group gets the same gid as the saved group.
+		_, err = loadIdm.AddGroup(otherName)
+		test.RequireNoError(t, err, "AddGroup %s", otherName)
+
+		err = loadIdm.Load(vfs, path)
+		if want := avfs.AlreadyExistsGroupError(groupName); err != want {
+			t.Errorf("Load : want error to be %v, got %v", want, err)
+		}
+
+		_, err = loadIdm.LookupUser(userName)
+		if want := avfs.UnknownUserError(userName); err != want {
+			t.Errorf("LookupUser : want error to be %v, got %v", want, err)
+		}
+	})
+
+	t.Run("LoadNonExisting", func(t *testing.T) {
+		nonExisting := vfs.Join(vfs.TempDir(), "nonExisting.json")
+
+		err = idm.Load(vfs, nonExisting)
+		test.AssertPathError(t, err).Op("open").Path(nonExisting).Err(avfs.ErrNoSuchFileOrDir).Test()
+	})
+}
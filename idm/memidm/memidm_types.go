@@ -32,18 +32,19 @@ const (
 
 // MemIdm implements an in memory identity manager using the avfs.IdentityMgr interface.
 type MemIdm struct {
-	adminGroup      *MemGroup    // adminGroup is the Administrator Group.
-	adminUser       *MemUser     // adminUser is the Administrator User.
-	groupsByName    groupsByName // groupsByName is the groups map by Name.
-	groupsById      groupsById   // groupsById is the groups map by Id.
-	usersByName     usersByName  // usersByName is the users map by Name.
-	usersById       usersById    // usersById is users map by Id.
-	maxGid          int          // maxGid is the current maximum Gid.
-	maxUid          int          // maxUid is the current maximum Uid.
-	grpMu           sync.RWMutex // grpMu is the groups mutex.
-	usrMu           sync.RWMutex // usrMu is the users mutex.
-	avfs.FeaturesFn              // FeaturesFn provides features functions to a file system or an identity manager.
-	avfs.OSTypeFn                // OSTypeFn provides OS type functions to a file system or an identity manager.
+	adminGroup       *MemGroup    // adminGroup is the Administrator Group.
+	adminUser        *MemUser     // adminUser is the Administrator User.
+	groupsByName     groupsByName // groupsByName is the groups map by Name.
+	groupsById       groupsById   // groupsById is the groups map by Id.
+	usersByName      usersByName  // usersByName is the users map by Name.
+	usersById        usersById    // usersById is users map by Id.
+	maxGid           int          // maxGid is the current maximum Gid.
+	maxUid           int          // maxUid is the current maximum Uid.
+	grpMu            sync.RWMutex // grpMu is the groups mutex.
+	usrMu            sync.RWMutex // usrMu is the users mutex.
+	avfs.FeaturesFn               // FeaturesFn provides features functions to a file system or an identity manager.
+	avfs.IdmEventsFn              // IdmEventsFn provides subscription functions to an identity manager.
+	avfs.OSTypeFn                 // OSTypeFn provides OS type functions to a file system or an identity manager.
 }
 
 // groupsByName is the map of groups by group name.
@@ -60,9 +61,10 @@ type usersById map[int]*MemUser
 
 // MemUser is the implementation of avfs.UserReader.
 type MemUser struct {
-	name string
-	uid  int
-	gid  int
+	name   string
+	groups []int // groups are the ids of the supplementary groups sorted in ascending order.
+	uid    int
+	gid    int
 }
 
 // MemGroup is the implementation of avfs.GroupReader.
@@ -75,3 +77,23 @@ type MemGroup struct {
 type Options struct {
 	OSType avfs.OSType // OSType defines the operating system type.
 }
+
+// idmData is the content of the files written by MemIdm.Save and read by MemIdm.Load.
+type idmData struct {
+	Groups []groupData `json:"groups"` // Groups are the groups sorted by group id.
+	Users  []userData  `json:"users"`  // Users are the users sorted by user id.
+}
+
+// groupData is the saved data of a group.
+type groupData struct {
+	Name string `json:"name"`
+	Gid  int    `json:"gid"`
+}
+
+// userData is the saved data of a user.
+type userData struct {
+	Name   string `json:"name"`
+	Groups []int  `json:"groups,omitempty"`
+	Uid    int    `json:"uid"`
+	Gid    int    `json:"gid"`
+}
@@ -71,6 +71,8 @@ func (idm *OsIdm) AddGroup(groupName string) (avfs.GroupReader, error) {
 		return nil, err
 	}
 
+	idm.NotifyIdmEvent(&avfs.IdmEvent{Type: avfs.IdmGroupAdded, Group: g})
+
 	return g, nil
 }
 
@@ -116,6 +118,9 @@ func (idm *OsIdm) AddUser(userName, groupName string) (avfs.UserReader, error) {
 		return nil, err
 	}
 
+	g, _ := idm.LookupGroup(groupName)
+	idm.NotifyIdmEvent(&avfs.IdmEvent{Type: avfs.IdmUserAdded, User: u, Group: g})
+
 	return u, nil
 }
 
@@ -130,6 +135,8 @@ func (idm *OsIdm) DelGroup(groupName string) error {
 		return avfs.InvalidNameError(groupName)
 	}
 
+	g, _ := idm.LookupGroup(groupName)
+
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
@@ -149,6 +156,8 @@ func (idm *OsIdm) DelGroup(groupName string) error {
 		}
 	}
 
+	idm.NotifyIdmEvent(&avfs.IdmEvent{Type: avfs.IdmGroupRemoved, Group: g})
+
 	return nil
 }
 
@@ -163,6 +172,8 @@ func (idm *OsIdm) DelUser(userName string) error {
 		return avfs.InvalidNameError(userName)
 	}
 
+	u, _ := idm.LookupUser(userName)
+
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
@@ -183,6 +194,8 @@ func (idm *OsIdm) DelUser(userName string) error {
 		}
 	}
 
+	idm.NotifyIdmEvent(&avfs.IdmEvent{Type: avfs.IdmUserRemoved, User: u})
+
 	return nil
 }
 
@@ -267,6 +280,26 @@ func getUser(nameOrId string, notFoundErr error) (*OsUser, error) {
 	return u, nil
 }
 
+// Command returns the exec.Cmd struct to execute the named program with the given arguments
+// as the specified user, without changing the user of the current process.
+// The current user must be root, otherwise avfs.ErrPermDenied is returned.
+// Supplementary groups of the child process are cleared.
+func Command(user avfs.UserReader, name string, arg ...string) (*exec.Cmd, error) {
+	if !isUserAdmin() {
+		return nil, avfs.ErrPermDenied
+	}
+
+	cmd := exec.Command(name, arg...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid: uint32(user.Uid()),
+			Gid: uint32(user.Gid()),
+		},
+	}
+
+	return cmd, nil
+}
+
 // SetUser sets the current user.
 // If the user can't be changed an error is returned.
 func SetUser(user avfs.UserReader) error {
@@ -334,6 +367,7 @@ func SetUserByName(userName string) error {
 }
 
 // User returns the current user of the OS.
+// If the current user is unknown, an ephemeral user is returned.
 func User() avfs.UserReader {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -342,7 +376,7 @@ func User() avfs.UserReader {
 
 	user, err := lookupUserId(uid)
 	if err != nil {
-		return nil
+		return avfs.NewEphemeralUser(uid, syscall.Getegid())
 	}
 
 	return user
@@ -18,7 +18,11 @@
 
 package osidm
 
-import "github.com/avfs/avfs"
+import (
+	"os/exec"
+
+	"github.com/avfs/avfs"
+)
 
 // AddGroup creates a new group with the specified name.
 // If the group already exists, the returned error is of type avfs.AlreadyExistsGroupError.
@@ -68,6 +72,14 @@ func (idm *OsIdm) LookupUserId(uid int) (avfs.UserReader, error) {
 	return nil, avfs.ErrPermDenied
 }
 
+// Command returns the exec.Cmd struct to execute the named program with the given arguments
+// as the specified user, without changing the user of the current process.
+// The current user must be root, otherwise avfs.ErrPermDenied is returned.
+// Supplementary groups of the child process are cleared.
+func Command(user avfs.UserReader, name string, arg ...string) (*exec.Cmd, error) {
+	return nil, avfs.ErrPermDenied
+}
+
 // SetUser sets the current user.
 // If the user can't be changed an error is returned.
 func SetUser(user avfs.UserReader) error {
@@ -58,3 +58,26 @@ func TestOsIdmCfg(t *testing.T) {
 		}
 	}
 }
+
+func TestOsIdmRunAsUser(t *testing.T) {
+	const userName = "nobody"
+
+	if !test.RunAsUser(t, userName) {
+		return
+	}
+
+	u := osidm.User()
+	if u == nil || u.Name() != userName {
+		t.Fatalf("User : want current user to be %s, got %v", userName, u)
+	}
+
+	_, err := osidm.Command(u, "id")
+	if err != avfs.ErrPermDenied {
+		t.Errorf("Command : want error to be %v, got %v", avfs.ErrPermDenied, err)
+	}
+
+	err = osidm.SetUserByName("root")
+	if err == nil {
+		t.Errorf("SetUserByName : want error, got nil")
+	}
+}
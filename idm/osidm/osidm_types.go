@@ -20,9 +20,10 @@ import "github.com/avfs/avfs"
 
 // OsIdm implements a rudimentary identity manager using the avfs.IdentityMgr interface.
 type OsIdm struct {
-	adminGroup      *OsGroup // Administrator group.
-	adminUser       *OsUser  // Administrator user.
-	avfs.FeaturesFn          // FeaturesFn provides features functions to a file system or an identity manager.
+	adminGroup       *OsGroup // Administrator group.
+	adminUser        *OsUser  // Administrator user.
+	avfs.FeaturesFn           // FeaturesFn provides features functions to a file system or an identity manager.
+	avfs.IdmEventsFn          // IdmEventsFn provides subscription functions to an identity manager.
 }
 
 // OsGroup is the implementation of avfs.GroupReader.
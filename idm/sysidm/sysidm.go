@@ -0,0 +1,276 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+// Package sysidm implements a read only identity manager backed by the user database of the operating system.
+//
+// Users and groups are looked up with the os/user package and listed with the getent command,
+// so that a file system like MemFS can use the same uids and gids as the operating system.
+// It is not available on Windows.
+package sysidm
+
+import (
+	"cmp"
+	"errors"
+	"os/exec"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// AdminGroup returns the administrator (root) group.
+func (idm *SysIdm) AdminGroup() avfs.GroupReader {
+	return idm.adminGroup
+}
+
+// AdminUser returns the administrator (root) user.
+func (idm *SysIdm) AdminUser() avfs.UserReader {
+	return idm.adminUser
+}
+
+// AddGroup creates a new group with the specified name.
+// The identity manager is read only, avfs.ErrPermDenied is always returned.
+func (idm *SysIdm) AddGroup(groupName string) (avfs.GroupReader, error) {
+	return nil, avfs.ErrPermDenied
+}
+
+// AddUser creates a new user with the specified userName and the specified primary group groupName.
+// The identity manager is read only, avfs.ErrPermDenied is always returned.
+func (idm *SysIdm) AddUser(userName, groupName string) (avfs.UserReader, error) {
+	return nil, avfs.ErrPermDenied
+}
+
+// DelGroup deletes an existing group with the specified name.
+// The identity manager is read only, avfs.ErrPermDenied is always returned.
+func (idm *SysIdm) DelGroup(groupName string) error {
+	return avfs.ErrPermDenied
+}
+
+// DelUser deletes an existing user with the specified name.
+// The identity manager is read only, avfs.ErrPermDenied is always returned.
+func (idm *SysIdm) DelUser(userName string) error {
+	return avfs.ErrPermDenied
+}
+
+// Groups returns the groups of the identity manager sorted by group id.
+// If the groups can't be listed, nil is returned.
+func (idm *SysIdm) Groups() []avfs.GroupReader {
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		return nil
+	}
+
+	var groups []avfs.GroupReader
+
+	for _, cols := range getentAll("group", 3) {
+		gid, err := strconv.Atoi(cols[2])
+		if err != nil {
+			continue
+		}
+
+		groups = append(groups, &SysGroup{name: cols[0], gid: gid})
+	}
+
+	slices.SortStableFunc(groups, func(a, b avfs.GroupReader) int { return cmp.Compare(a.Gid(), b.Gid()) })
+
+	return groups
+}
+
+// LookupGroup looks up a group by name.
+// If the group is not found, the returned error is of type avfs.UnknownGroupError.
+func (idm *SysIdm) LookupGroup(groupName string) (avfs.GroupReader, error) {
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		return nil, avfs.ErrPermDenied
+	}
+
+	g, err := user.LookupGroup(groupName)
+	if err != nil {
+		if errors.As(err, new(user.UnknownGroupError)) {
+			return nil, avfs.UnknownGroupError(groupName)
+		}
+
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	return newGroup(g)
+}
+
+// LookupGroupId looks up a group by groupid.
+// If the group is not found, the returned error is of type avfs.UnknownGroupIdError.
+func (idm *SysIdm) LookupGroupId(gid int) (avfs.GroupReader, error) {
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		return nil, avfs.ErrPermDenied
+	}
+
+	g, err := user.LookupGroupId(strconv.Itoa(gid))
+	if err != nil {
+		if errors.As(err, new(user.UnknownGroupIdError)) {
+			return nil, avfs.UnknownGroupIdError(gid)
+		}
+
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	return newGroup(g)
+}
+
+// LookupUser looks up a user by username.
+// If the user is not found, the returned error is of type avfs.UnknownUserError.
+func (idm *SysIdm) LookupUser(userName string) (avfs.UserReader, error) {
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		return nil, avfs.ErrPermDenied
+	}
+
+	u, err := user.Lookup(userName)
+	if err != nil {
+		if errors.As(err, new(user.UnknownUserError)) {
+			return nil, avfs.UnknownUserError(userName)
+		}
+
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	return newUser(u)
+}
+
+// LookupUserId looks up a user by userid.
+// If the user is not found, the returned error is of type avfs.UnknownUserIdError.
+func (idm *SysIdm) LookupUserId(uid int) (avfs.UserReader, error) {
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		return nil, avfs.ErrPermDenied
+	}
+
+	u, err := user.LookupId(strconv.Itoa(uid))
+	if err != nil {
+		if errors.As(err, new(user.UnknownUserIdError)) {
+			return nil, avfs.UnknownUserIdError(uid)
+		}
+
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	return newUser(u)
+}
+
+// Users returns the users of the identity manager sorted by user id.
+// If the users can't be listed, nil is returned.
+func (idm *SysIdm) Users() []avfs.UserReader {
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		return nil
+	}
+
+	var users []avfs.UserReader
+
+	for _, cols := range getentAll("passwd", 4) {
+		uid, err := strconv.Atoi(cols[2])
+		if err != nil {
+			continue
+		}
+
+		gid, err := strconv.Atoi(cols[3])
+		if err != nil {
+			continue
+		}
+
+		users = append(users, &SysUser{name: cols[0], uid: uid, gid: gid})
+	}
+
+	slices.SortStableFunc(users, func(a, b avfs.UserReader) int { return cmp.Compare(a.Uid(), b.Uid()) })
+
+	return users
+}
+
+// getentAll returns the fields of all the entries of a database ("passwd" or "group")
+// having at least minCols fields, using the getent command.
+func getentAll(database string, minCols int) [][]string {
+	buf, err := exec.Command("getent", database).Output()
+	if err != nil {
+		return nil
+	}
+
+	var entries [][]string
+
+	for _, line := range strings.Split(string(buf), "\n") {
+		cols := strings.Split(line, ":")
+		if len(cols) < minCols {
+			continue
+		}
+
+		entries = append(entries, cols)
+	}
+
+	return entries
+}
+
+// newGroup returns a SysGroup from a group of the os/user package.
+func newGroup(g *user.Group) (*SysGroup, error) {
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	return &SysGroup{name: g.Name, gid: gid}, nil
+}
+
+// newUser returns a SysUser from a user of the os/user package.
+func newUser(u *user.User) (*SysUser, error) {
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return nil, avfs.UnknownError(err.Error())
+	}
+
+	return &SysUser{name: u.Username, uid: uid, gid: gid}, nil
+}
+
+// SysGroup
+
+// Gid returns the Group ID.
+func (g *SysGroup) Gid() int {
+	return g.gid
+}
+
+// Name returns the Group name.
+func (g *SysGroup) Name() string {
+	return g.name
+}
+
+// SysUser
+
+// Gid returns the primary Group ID of the User.
+func (u *SysUser) Gid() int {
+	return u.gid
+}
+
+// IsAdmin returns true if the user has administrator (root) privileges.
+func (u *SysUser) IsAdmin() bool {
+	return u.uid == 0 || u.gid == 0
+}
+
+// Name returns the username.
+func (u *SysUser) Name() string {
+	return u.name
+}
+
+// Uid returns the User ID.
+func (u *SysUser) Uid() int {
+	return u.uid
+}
@@ -0,0 +1,57 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package sysidm
+
+import (
+	"math"
+
+	"github.com/avfs/avfs"
+)
+
+// New creates a new read only identity manager backed by the user database of the operating system.
+func New() *SysIdm {
+	osType := avfs.CurrentOSType()
+	uid, gid := 0, 0
+	groupName, userName := avfs.AdminGroupName(osType), avfs.AdminUserName(osType)
+	features := avfs.FeatIdentityMgr | avfs.FeatReadOnlyIdm
+
+	// The users and groups of Windows are identified by SIDs and not by numeric ids.
+	if osType == avfs.OsWindows {
+		features = 0
+		uid, gid = math.MaxInt, math.MaxInt
+		groupName, userName = avfs.DefaultName, avfs.DefaultName
+	}
+
+	idm := &SysIdm{
+		adminGroup: &SysGroup{name: groupName, gid: gid},
+		adminUser:  &SysUser{name: userName, uid: uid, gid: gid},
+	}
+
+	_ = idm.SetFeatures(features)
+
+	return idm
+}
+
+// OSType returns the operating system type of the identity manager.
+func (idm *SysIdm) OSType() avfs.OSType {
+	return avfs.CurrentOSType()
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (idm *SysIdm) Type() string {
+	return "SysIdm"
+}
@@ -0,0 +1,127 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package sysidm_test
+
+import (
+	"os/user"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/idm/sysidm"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/memfs"
+)
+
+var (
+	// SysIdm implements avfs.IdentityMgr interface.
+	_ avfs.IdentityMgr = &sysidm.SysIdm{}
+
+	// SysIdm implements avfs.IdmLister interface.
+	_ avfs.IdmLister = &sysidm.SysIdm{}
+
+	// SysUser implements avfs.UserReader interface.
+	_ avfs.UserReader = &sysidm.SysUser{}
+
+	// SysGroup implements avfs.GroupReader interface.
+	_ avfs.GroupReader = &sysidm.SysGroup{}
+)
+
+func TestSysIdmAll(t *testing.T) {
+	idm := sysidm.New()
+
+	ts := test.NewSuiteIdm(t, idm)
+	ts.TestIdmAll(t)
+}
+
+func TestSysIdmCfg(t *testing.T) {
+	idm := sysidm.New()
+
+	wantFeatures := avfs.FeatIdentityMgr | avfs.FeatReadOnlyIdm
+	if idm.OSType() == avfs.OsWindows {
+		wantFeatures = 0
+	}
+
+	if idm.Features() != wantFeatures {
+		t.Errorf("Features : want features to be %s, got %s", wantFeatures, idm.Features())
+	}
+}
+
+// TestSysIdmMemFS tests that a MemFS using SysIdm mirrors the users of the operating system.
+func TestSysIdmMemFS(t *testing.T) {
+	idm := sysidm.New()
+	if !idm.HasFeature(avfs.FeatIdentityMgr) {
+		t.Skipf("SysIdm is not available on %s", idm.OSType())
+	}
+
+	cur, err := user.Current()
+	test.RequireNoError(t, err, "Current")
+
+	wantUid, _ := strconv.Atoi(cur.Uid)
+	wantGid, _ := strconv.Atoi(cur.Gid)
+
+	vfs := memfs.NewWithOptions(&memfs.Options{Idm: idm, OSType: avfs.OsLinux, EtcFiles: true})
+
+	t.Run("SysIdmSetUser", func(t *testing.T) {
+		err = vfs.SetUserByName(cur.Username)
+		test.RequireNoError(t, err, "SetUserByName %s", cur.Username)
+
+		u := vfs.User()
+		if u.Uid() != wantUid || u.Gid() != wantGid {
+			t.Errorf("User : want uid, gid to be %d, %d, got %d, %d", wantUid, wantGid, u.Uid(), u.Gid())
+		}
+
+		u, err = idm.LookupUserId(wantUid)
+		test.RequireNoError(t, err, "LookupUserId %d", wantUid)
+
+		if u.Name() != cur.Username {
+			t.Errorf("LookupUserId %d : want name to be %s, got %s", wantUid, cur.Username, u.Name())
+		}
+
+		err = vfs.SetUser(idm.AdminUser())
+		test.RequireNoError(t, err, "SetUser %s", idm.AdminUser().Name())
+	})
+
+	t.Run("SysIdmReadOnly", func(t *testing.T) {
+		_, err = idm.AddUser("sysIdmUser", cur.Username)
+		if err != avfs.ErrPermDenied {
+			t.Errorf("AddUser : want error to be %v, got %v", avfs.ErrPermDenied, err)
+		}
+
+		if !vfs.HasFeature(avfs.FeatReadOnlyIdm) {
+			t.Errorf("Features : want feature %s to be set, got %s", avfs.FeatReadOnlyIdm, vfs.Features())
+		}
+	})
+
+	t.Run("SysIdmEtcFiles", func(t *testing.T) {
+		users := idm.Users()
+		if len(users) == 0 {
+			t.Skip("Users : the users of the operating system can't be listed")
+		}
+
+		data, err := vfs.ReadFile("/etc/passwd")
+		test.RequireNoError(t, err, "ReadFile %s", "/etc/passwd")
+
+		wantLine := "root:x:0:0:"
+		if !strings.Contains(string(data), wantLine) {
+			t.Errorf("ReadFile %s : want content to contain %q, got %q", "/etc/passwd", wantLine, data)
+		}
+	})
+}
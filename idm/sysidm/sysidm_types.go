@@ -0,0 +1,39 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package sysidm
+
+import "github.com/avfs/avfs"
+
+// SysIdm implements a read only identity manager backed by the user database of the operating system.
+type SysIdm struct {
+	adminGroup      *SysGroup // adminGroup is the Administrator Group.
+	adminUser       *SysUser  // adminUser is the Administrator User.
+	avfs.FeaturesFn           // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// SysGroup is the implementation of avfs.GroupReader.
+type SysGroup struct {
+	name string
+	gid  int
+}
+
+// SysUser is the implementation of avfs.UserReader.
+type SysUser struct {
+	name string
+	uid  int
+	gid  int
+}
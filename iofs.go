@@ -0,0 +1,222 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ioFS is the io/fs adapter of a file system returned by ToIOFS.
+type ioFS struct {
+	vfs  VFS    // vfs is the adapted file system.
+	root string // root is the absolute path of the directory of vfs used as the root of the adapter.
+}
+
+// ToIOFS returns an io/fs file system for the file system vfs, rooted at its root directory
+// (on Windows, the root directory of the volume of the current directory).
+// The returned value also implements fs.GlobFS, fs.ReadDirFS, fs.ReadFileFS, fs.StatFS and fs.SubFS,
+// so that the standard library functions like fs.WalkDir, http.FS or template.ParseFS
+// call the methods of vfs directly.
+// Like os.DirFS, the names are slash-separated unrooted paths validated by fs.ValidPath
+// and the errors of type *PathError report the name given to the adapter.
+func ToIOFS(vfs VFS) fs.FS {
+	// On Windows, the absolute path of the separator is the root directory of the current volume.
+	root, _ := vfs.Abs(string(vfs.PathSeparator()))
+
+	return &ioFS{vfs: vfs, root: root}
+}
+
+// Glob returns the names of all files matching pattern or nil if there is no matching file.
+// The syntax of patterns is the same as in path.Match.
+func (f *ioFS) Glob(pattern string) ([]string, error) {
+	// Check pattern is well-formed.
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	vfs := f.vfs
+	sep := string(vfs.PathSeparator())
+
+	prefix := f.escapeMeta(f.root)
+	if !strings.HasSuffix(prefix, sep) {
+		prefix += sep
+	}
+
+	matches, err := vfs.Glob(prefix + f.toPattern(pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range matches {
+		name := strings.TrimPrefix(strings.TrimPrefix(m, f.root), sep)
+		if name == "" {
+			name = "."
+		}
+
+		matches[i] = ToSlash(vfs, name)
+	}
+
+	return matches, nil
+}
+
+// Open opens the named file for reading.
+func (f *ioFS) Open(name string) (fs.File, error) {
+	const op = "open"
+
+	absPath, err := f.toPath(op, name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := f.vfs.Open(absPath)
+	if err != nil {
+		return nil, f.pathError(err, name)
+	}
+
+	return file, nil
+}
+
+// ReadDir reads the named directory and returns a list of directory entries sorted by filename.
+func (f *ioFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	const op = "readdir"
+
+	absPath, err := f.toPath(op, name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := f.vfs.ReadDir(absPath)
+
+	return entries, f.pathError(err, name)
+}
+
+// ReadFile reads the named file and returns its contents.
+func (f *ioFS) ReadFile(name string) ([]byte, error) {
+	const op = "readfile"
+
+	absPath, err := f.toPath(op, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := f.vfs.ReadFile(absPath)
+
+	return data, f.pathError(err, name)
+}
+
+// Stat returns a FileInfo describing the named file.
+func (f *ioFS) Stat(name string) (fs.FileInfo, error) {
+	const op = "stat"
+
+	absPath, err := f.toPath(op, name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.vfs.Stat(absPath)
+	if err != nil {
+		return nil, f.pathError(err, name)
+	}
+
+	return info, nil
+}
+
+// Sub returns an FS corresponding to the subtree rooted at dir.
+// Like fs.Sub, the existence of dir is not checked.
+func (f *ioFS) Sub(dir string) (fs.FS, error) {
+	const op = "sub"
+
+	absPath, err := f.toPath(op, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "." {
+		return f, nil
+	}
+
+	return &ioFS{vfs: f.vfs, root: absPath}, nil
+}
+
+// escapeMeta returns the path with the meta characters of Glob patterns escaped.
+func (f *ioFS) escapeMeta(path string) string {
+	var sb strings.Builder
+
+	for _, c := range path {
+		switch {
+		case c == '*' || c == '?' || c == '[':
+			sb.WriteByte('[')
+			sb.WriteRune(c)
+			sb.WriteByte(']')
+		case c == '\\' && f.vfs.OSType() != OsWindows:
+			sb.WriteString(`\\`)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// pathError sets the path of err to name if err is a *PathError.
+func (f *ioFS) pathError(err error, name string) error {
+	if e, ok := err.(*fs.PathError); ok {
+		e.Path = name
+	}
+
+	return err
+}
+
+// toPattern returns the Glob pattern of vfs from a slash-separated pattern of path.Match.
+// On Windows, the backslash is the path separator, escaped characters are put in a character class.
+func (f *ioFS) toPattern(pattern string) string {
+	vfs := f.vfs
+	if vfs.OSType() != OsWindows {
+		return pattern
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(f.escapeMeta(pattern[i : i+1]))
+		case c == '/':
+			sb.WriteByte(vfs.PathSeparator())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// toPath returns the absolute path in vfs of the name of the io/fs file system.
+func (f *ioFS) toPath(op, name string) (string, error) {
+	vfs := f.vfs
+
+	if !fs.ValidPath(name) || vfs.OSType() == OsWindows && strings.ContainsAny(name, `\:`) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	return Join(vfs, f.root, FromSlash(vfs, name)), nil
+}
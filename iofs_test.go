@@ -0,0 +1,147 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/osfs"
+)
+
+// TestToIOFS tests ToIOFS function.
+func TestToIOFS(t *testing.T) {
+	vfs := memfs.New()
+
+	fsys := createIOFS(t, vfs, avfs.FromUnixPath(vfs, "/base[1]"))
+
+	t.Run("Interfaces", func(t *testing.T) {
+		for _, f := range []fs.FS{avfs.ToIOFS(vfs), fsys} {
+			if _, ok := f.(interface {
+				fs.GlobFS
+				fs.ReadDirFS
+				fs.ReadFileFS
+				fs.StatFS
+				fs.SubFS
+			}); !ok {
+				t.Errorf("ToIOFS : want %T to implement all io/fs interfaces", f)
+			}
+		}
+	})
+
+	t.Run("TestFS", func(t *testing.T) {
+		err := fstest.TestFS(fsys, ioFiles...)
+		if err != nil {
+			t.Error(err)
+		}
+
+		vfs := osfs.New()
+
+		err = fstest.TestFS(createIOFS(t, vfs, t.TempDir()), ioFiles...)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Glob", func(t *testing.T) {
+		matches, err := fs.Glob(fsys, "dir/*.txt")
+		if err != nil {
+			t.Fatalf("Glob : want error to be nil, got %v", err)
+		}
+
+		if want := []string{"dir/b.txt"}; !slices.Equal(matches, want) {
+			t.Errorf("Glob : want matches to be %v, got %v", want, matches)
+		}
+
+		_, err = fs.Glob(fsys, "[")
+		if !errors.Is(err, path.ErrBadPattern) {
+			t.Errorf("Glob : want error to be %v, got %v", path.ErrBadPattern, err)
+		}
+	})
+
+	t.Run("ReadFile", func(t *testing.T) {
+		for _, name := range ioFiles {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil || string(data) != name {
+				t.Errorf("ReadFile %s : want content to be %s, got %s, %v", name, name, data, err)
+			}
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, name := range []string{"/a.txt", "../a.txt", "dir/", ""} {
+			_, err := fs.ReadFile(fsys, name)
+
+			var e *fs.PathError
+			if !errors.As(err, &e) || e.Path != name || !errors.Is(err, fs.ErrInvalid) {
+				t.Errorf("ReadFile %q : want error to be %v, got %v", name, fs.ErrInvalid, err)
+			}
+		}
+
+		const name = "dir/nonExisting"
+
+		_, err := fs.Stat(fsys, name)
+
+		var e *fs.PathError
+		if !errors.As(err, &e) || e.Path != name || !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat %s : want error to be %v, got %v", name, fs.ErrNotExist, err)
+		}
+	})
+}
+
+// ioFiles are the files created by createIOFS.
+var ioFiles = []string{"a.txt", "dir/b.txt", "dir/c.go", "dir/sub/d.txt"}
+
+// createIOFS creates the files of ioFiles in the directory baseDir of vfs
+// and returns the io/fs file system rooted at baseDir.
+func createIOFS(tb testing.TB, vfs avfs.VFS, baseDir string) fs.FS {
+	tb.Helper()
+
+	for _, file := range ioFiles {
+		path := vfs.Join(baseDir, vfs.FromSlash(file))
+
+		err := vfs.MkdirAll(vfs.Dir(path), avfs.DefaultDirPerm)
+		if err != nil {
+			tb.Fatalf("MkdirAll : want error to be nil, got %v", err)
+		}
+
+		err = vfs.WriteFile(path, []byte(file), avfs.DefaultFilePerm)
+		if err != nil {
+			tb.Fatalf("WriteFile : want error to be nil, got %v", err)
+		}
+	}
+
+	root, _ := vfs.Abs(string(vfs.PathSeparator()))
+
+	rel, err := vfs.Rel(root, baseDir)
+	if err != nil {
+		tb.Fatalf("Rel : want error to be nil, got %v", err)
+	}
+
+	fsys, err := fs.Sub(avfs.ToIOFS(vfs), vfs.ToSlash(rel))
+	if err != nil {
+		tb.Fatalf("Sub : want error to be nil, got %v", err)
+	}
+
+	return fsys
+}
@@ -0,0 +1,109 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// ApplyMetadata applies the metadata changes of ops to the files of vfs,
+// for example to restore the owners, modes and times of the files of an archive.
+// For each operation, the owner is changed first, then the mode and the times,
+// like individual calls to Chown (or Lchown with MetadataNoFollow), Chmod and Chtimes (or Lchtimes with MetadataNoFollow).
+// If vfs implements MetadataApplier, the changes are applied in a batch.
+// All the operations are applied even if some of them fail,
+// the returned error joins the errors of the failed operations.
+func ApplyMetadata[T VFSBase](vfs T, ops []MetadataOp) error {
+	if ma, ok := any(vfs).(MetadataApplier); ok {
+		return ma.ApplyMetadata(ops)
+	}
+
+	var errs []error
+
+	for i := range ops {
+		err := applyMetadata(vfs, &ops[i])
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// applyMetadata applies the metadata changes of a single operation.
+func applyMetadata[T VFSBase](vfs T, mo *MetadataOp) error {
+	if mo.Set&MetadataOwner != 0 {
+		chown := vfs.Chown
+		if mo.Set&MetadataNoFollow != 0 {
+			chown = vfs.Lchown
+		}
+
+		err := chown(mo.Path, mo.Uid, mo.Gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mo.Set&MetadataMode != 0 {
+		err := vfs.Chmod(mo.Path, mo.Mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mo.Set&MetadataTimes != 0 {
+		var err error
+
+		if mo.Set&MetadataNoFollow != 0 {
+			err = Lchtimes(vfs, mo.Path, mo.Atime, mo.Mtime)
+		} else {
+			err = vfs.Chtimes(mo.Path, mo.Atime, mo.Mtime)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lchtimes changes the access and modification times of the named file like Chtimes,
+// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+// If vfs doesn't implement Lchtimer, the times of a file which is not a symbolic link are changed
+// by Chtimes and the error ErrNotSupported is returned for a symbolic link.
+// If there is an error, it will be of type *PathError.
+func Lchtimes[T VFSBase](vfs T, name string, atime, mtime time.Time) error {
+	if lc, ok := any(vfs).(Lchtimer); ok {
+		return lc.Lchtimes(name, atime, mtime)
+	}
+
+	const op = "lchtimes"
+
+	info, err := vfs.Lstat(name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: errors.Unwrap(err)}
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return &fs.PathError{Op: op, Path: name, Err: ErrNotSupported}
+	}
+
+	return vfs.Chtimes(name, atime, mtime)
+}
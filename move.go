@@ -0,0 +1,208 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand"
+	"os"
+	"reflect"
+	"strconv"
+	"syscall"
+)
+
+// Move moves the file or the directory srcPath of srcFs to dstPath of dstFs like the mv command.
+// If dstPath is an existing directory, srcPath is moved into it with the same base name.
+// When dstFs and srcFs share the same files (the same instance, or two real file systems of the same type),
+// srcPath is renamed, and Move fails with ErrSameFile if srcPath and dstPath are the same file.
+// Otherwise, or when the rename fails because the paths are on different devices or volumes,
+// srcPath is copied with its metadata (see CopyDir) to a temporary name next to dstPath,
+// which is then renamed to dstPath before srcPath is removed.
+// Like mv, an existing destination is replaced only if it has the same type as srcPath,
+// and a directory only if it is empty, the directories are never merged.
+// If there is an error, it will be of type *LinkError.
+func Move(dstFs, srcFs VFSBase, dstPath, srcPath string) error {
+	const op = "move"
+
+	info, err := srcFs.Lstat(srcPath)
+	if err != nil {
+		return &os.LinkError{Op: op, Old: srcPath, New: dstPath, Err: unwrapPathError(err)}
+	}
+
+	if dstInfo, err := dstFs.Stat(dstPath); err == nil && dstInfo.IsDir() {
+		dstPath = dstFs.Join(dstPath, srcFs.Base(srcPath))
+	}
+
+	shared := sameFiles(dstFs, srcFs)
+	if shared {
+		if dstInfo, err := dstFs.Lstat(dstPath); err == nil && srcFs.SameFile(info, dstInfo) {
+			return &os.LinkError{Op: op, Old: srcPath, New: dstPath, Err: ErrSameFile}
+		}
+	}
+
+	err = moveCheckDst(dstFs, dstPath, info)
+	if err != nil {
+		return &os.LinkError{Op: op, Old: srcPath, New: dstPath, Err: err}
+	}
+
+	if shared {
+		err = srcFs.Rename(srcPath, dstPath)
+		if err == nil || !isCrossDevice(srcFs, err) {
+			return err
+		}
+	}
+
+	tmpPath := dstFs.Join(dstFs.Dir(dstPath), "."+dstFs.Base(dstPath)+".move"+strconv.FormatUint(uint64(rand.Uint32()), 10))
+
+	err = moveCopy(dstFs, srcFs, tmpPath, srcPath, info)
+	if err == nil {
+		err = moveReplace(dstFs, dstPath, tmpPath, info)
+	}
+
+	if err != nil {
+		_ = dstFs.RemoveAll(tmpPath)
+
+		return &os.LinkError{Op: op, Old: srcPath, New: dstPath, Err: unwrapPathError(err)}
+	}
+
+	err = srcFs.RemoveAll(srcPath)
+	if err != nil {
+		return &os.LinkError{Op: op, Old: srcPath, New: dstPath, Err: unwrapPathError(err)}
+	}
+
+	return nil
+}
+
+// moveReplace renames the copy tmpPath of the source file described by info to dstPath.
+// An existing destination directory, which is empty (see moveCheckDst), is removed first
+// since not all the file systems can replace a directory by a rename.
+func moveReplace(dstFs VFSBase, dstPath, tmpPath string, info fs.FileInfo) error {
+	if info.IsDir() {
+		err := dstFs.Remove(dstPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return dstFs.Rename(tmpPath, dstPath)
+}
+
+// moveCheckDst checks that an existing destination dstPath can be replaced by the source file described by info.
+func moveCheckDst(dstFs VFSBase, dstPath string, info fs.FileInfo) error {
+	dstInfo, err := dstFs.Lstat(dstPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return unwrapPathError(err)
+	}
+
+	var e Errors
+
+	e.SetOSType(dstFs.OSType())
+
+	switch {
+	case info.IsDir() && !dstInfo.IsDir():
+		return e.NotADirectory
+	case !info.IsDir() && dstInfo.IsDir():
+		return e.IsADirectory
+	case dstInfo.IsDir():
+		isEmpty, err := IsEmpty(dstFs, dstPath)
+		if err != nil {
+			return unwrapPathError(err)
+		}
+
+		if !isEmpty {
+			return e.DirNotEmpty
+		}
+	}
+
+	return nil
+}
+
+// moveCopy copies the source file srcPath described by info to dstPath.
+func moveCopy(dstFs, srcFs VFSBase, dstPath, srcPath string, info fs.FileInfo) error {
+	switch {
+	case info.IsDir():
+		return CopyDir(dstFs, srcFs, dstPath, srcPath, &CopyOptions{PreserveHardlinks: true})
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := srcFs.Readlink(srcPath)
+		if err != nil {
+			return err
+		}
+
+		err = dstFs.Symlink(link, dstPath)
+		if err != nil {
+			return err
+		}
+
+		return copyMetadata(dstFs, srcFs, dstPath, info, false)
+	default:
+		return CopyFile(dstFs, srcFs, dstPath, srcPath)
+	}
+}
+
+// isCrossDevice returns true if err is the error of a rename between different devices or volumes of vfs.
+func isCrossDevice(vfs VFSBase, err error) bool {
+	err = unwrapPathError(err)
+	if err == ErrCrossDevLink || err == ErrWinNotSameDevice {
+		return true
+	}
+
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return false
+	}
+
+	if vfs.OSType() == OsWindows {
+		return uint64(errno) == uint64(ErrWinNotSameDevice)
+	}
+
+	return uint64(errno) == uint64(ErrCrossDevLink)
+}
+
+// sameFiles returns true if vfs1 and vfs2 give access to the same files with the same paths:
+// they are the same instance of a file system, or two real file systems of the same type (see OsFS).
+func sameFiles(vfs1, vfs2 VFSBase) bool {
+	v1, v2 := reflect.ValueOf(vfs1), reflect.ValueOf(vfs2)
+	if v1.Type() != v2.Type() {
+		return false
+	}
+
+	if v1.Kind() == reflect.Pointer && v1.Pointer() == v2.Pointer() {
+		return true
+	}
+
+	return vfs1.HasFeature(FeatRealFS) && vfs2.HasFeature(FeatRealFS)
+}
+
+// unwrapPathError returns the error wrapped by a *PathError or a *LinkError, err otherwise.
+func unwrapPathError(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+
+	var le *os.LinkError
+	if errors.As(err, &le) {
+		return le.Err
+	}
+
+	return err
+}
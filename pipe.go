@@ -0,0 +1,361 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// PipeBufferSize is the size of the buffer of a pipe created by Pipe.
+// Writes block when the buffer is full until data is read.
+const PipeBufferSize = 64 * 1024
+
+// pipe is the buffer shared by the two ends of a pipe.
+type pipe struct {
+	buf         []byte      // buf contains the written data not yet read.
+	cond        *sync.Cond  // cond signals the changes of buf or of the state of the ends of the pipe.
+	mtime       time.Time   // mtime is the time of the last write.
+	mode        fs.FileMode // mode is the mode of the pipe.
+	mu          sync.Mutex  // mu is the mutex used to access the fields of pipe.
+	readClosed  bool        // readClosed is true if the read end of the pipe is closed.
+	writeClosed bool        // writeClosed is true if the write end of the pipe is closed.
+}
+
+// pipeFile is one end of a pipe created by Pipe.
+type pipeFile struct {
+	p     *pipe  // p is the pipe.
+	name  string // name is the name of the file ("|0" for the read end, "|1" for the write end).
+	write bool   // write is true for the write end of the pipe.
+}
+
+// pipeInfo is the fs.FileInfo of an end of a pipe.
+type pipeInfo struct {
+	mtime time.Time   // mtime is the time of the last write.
+	name  string      // name is the name of the end of the pipe.
+	mode  fs.FileMode // mode is the mode of the pipe.
+	size  int64       // size is the number of bytes written and not read yet.
+}
+
+// Pipe returns a connected pair of in memory files like os.Pipe :
+// reads from r return bytes written to w. Reads block until data is written
+// and return io.EOF once w is closed and all data is read.
+// Writes block when PipeBufferSize bytes are pending and fail once r is closed.
+// The files are usable wherever a File is accepted, for example to emulate
+// the standard input or output of a component. The error is always nil.
+func Pipe() (r, w File, err error) {
+	p := &pipe{mtime: time.Now(), mode: fs.ModeNamedPipe | 0o600}
+	p.cond = sync.NewCond(&p.mu)
+
+	return &pipeFile{p: p, name: "|0"}, &pipeFile{p: p, name: "|1", write: true}, nil
+}
+
+// closed returns true if this end of the pipe is closed.
+// The pipe must be locked.
+func (f *pipeFile) closed() bool {
+	if f.write {
+		return f.p.writeClosed
+	}
+
+	return f.p.readClosed
+}
+
+// checkOpen returns a *PathError if this end of the pipe is closed.
+func (f *pipeFile) checkOpen(op string) error {
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+
+	if f.closed() {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	return nil
+}
+
+// Chdir changes the current working directory to the file,
+// which must be a directory.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) Chdir() error {
+	const op = "chdir"
+
+	if err := f.checkOpen(op); err != nil {
+		return err
+	}
+
+	return &fs.PathError{Op: op, Path: f.name, Err: ErrNotADirectory}
+}
+
+// Chmod changes the mode of the file to mode.
+// If there is an error, it will be of type *PathError.
+func (f *pipeFile) Chmod(mode fs.FileMode) error {
+	const op = "chmod"
+
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+
+	if f.closed() {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	f.p.mode = fs.ModeNamedPipe | mode&fs.ModePerm
+
+	return nil
+}
+
+// Chown changes the numeric uid and gid of the named file.
+// For a pipe, the owner is not recorded.
+// If there is an error, it will be of type *PathError.
+func (f *pipeFile) Chown(uid, gid int) error {
+	return f.checkOpen("chown")
+}
+
+// Close closes this end of the pipe, pending reads or writes on the other end are unblocked.
+// Close will return an error if it has already been called.
+func (f *pipeFile) Close() error {
+	const op = "close"
+
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+
+	if f.closed() {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	if f.write {
+		f.p.writeClosed = true
+	} else {
+		f.p.readClosed = true
+		f.p.buf = nil
+	}
+
+	f.p.cond.Broadcast()
+
+	return nil
+}
+
+// Fd returns the integer Unix file descriptor referencing the open file.
+// A pipe has no file descriptor.
+func (*pipeFile) Fd() uintptr {
+	return ^(uintptr(0))
+}
+
+// Name returns the name of the file ("|0" for the read end, "|1" for the write end).
+func (f *pipeFile) Name() string {
+	return f.name
+}
+
+// Read reads up to len(b) bytes from the pipe, blocking until data is available.
+// At end of file, when the write end is closed, Read returns 0, io.EOF.
+func (f *pipeFile) Read(b []byte) (n int, err error) {
+	const op = "read"
+
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+
+	switch {
+	case f.closed():
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	case f.write:
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: ErrBadFileDesc}
+	case len(b) == 0:
+		return 0, nil
+	}
+
+	for len(f.p.buf) == 0 {
+		if f.p.writeClosed {
+			return 0, io.EOF
+		}
+
+		f.p.cond.Wait()
+
+		if f.closed() {
+			return 0, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+		}
+	}
+
+	n = copy(b, f.p.buf)
+	f.p.buf = f.p.buf[n:]
+	f.p.cond.Broadcast()
+
+	return n, nil
+}
+
+// ReadAt reads len(b) bytes from the File starting at byte offset off.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) ReadAt(b []byte, off int64) (n int, err error) {
+	return 0, f.seekError("read")
+}
+
+// ReadDir reads the contents of the directory associated with the file f.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	return nil, f.notDirError()
+}
+
+// Readdirnames reads the contents of the directory associated with file.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) Readdirnames(n int) (names []string, err error) {
+	return nil, f.notDirError()
+}
+
+// Seek sets the offset for the next Read or Write on file.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) Seek(offset int64, whence int) (ret int64, err error) {
+	return 0, f.seekError("seek")
+}
+
+// Stat returns the FileInfo structure describing the pipe,
+// its size is the number of bytes written and not read yet.
+// If there is an error, it will be of type *PathError.
+func (f *pipeFile) Stat() (fs.FileInfo, error) {
+	const op = "stat"
+
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+
+	if f.closed() {
+		return nil, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	return &pipeInfo{mtime: f.p.mtime, name: f.name, mode: f.p.mode, size: int64(len(f.p.buf))}, nil
+}
+
+// Sync commits the current contents of the file to stable storage.
+// For a pipe, it does nothing.
+func (f *pipeFile) Sync() error {
+	return f.checkOpen("sync")
+}
+
+// Truncate changes the size of the file.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) Truncate(size int64) error {
+	const op = "truncate"
+
+	if err := f.checkOpen(op); err != nil {
+		return err
+	}
+
+	return &fs.PathError{Op: op, Path: f.name, Err: ErrInvalidArgument}
+}
+
+// Write writes len(b) bytes to the pipe, blocking while the buffer of the pipe is full.
+// It returns the number of bytes written and an error, if any.
+// Write returns a non-nil error when n != len(b), io.ErrClosedPipe if the read end is closed.
+func (f *pipeFile) Write(b []byte) (n int, err error) {
+	const op = "write"
+
+	f.p.mu.Lock()
+	defer f.p.mu.Unlock()
+
+	switch {
+	case f.closed():
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	case !f.write:
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: ErrBadFileDesc}
+	}
+
+	for len(b) > 0 {
+		if f.p.readClosed {
+			return n, &fs.PathError{Op: op, Path: f.name, Err: io.ErrClosedPipe}
+		}
+
+		free := PipeBufferSize - len(f.p.buf)
+		if free == 0 {
+			f.p.cond.Wait()
+
+			if f.closed() {
+				return n, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+			}
+
+			continue
+		}
+
+		m := min(free, len(b))
+		f.p.buf = append(f.p.buf, b[:m]...)
+		f.p.mtime = time.Now()
+		f.p.cond.Broadcast()
+
+		b = b[m:]
+		n += m
+	}
+
+	return n, nil
+}
+
+// WriteAt writes len(b) bytes to the File starting at byte offset off.
+// For a pipe, it always returns an error of type *PathError.
+func (f *pipeFile) WriteAt(b []byte, off int64) (n int, err error) {
+	return 0, f.seekError("write")
+}
+
+// WriteString is like Write, but writes the contents of string s rather than
+// a slice of bytes.
+func (f *pipeFile) WriteString(s string) (n int, err error) {
+	return f.Write([]byte(s))
+}
+
+// notDirError returns the error of the directory functions.
+func (f *pipeFile) notDirError() error {
+	const op = "readdirent"
+
+	if err := f.checkOpen(op); err != nil {
+		return err
+	}
+
+	return &fs.PathError{Op: op, Path: f.name, Err: ErrNotADirectory}
+}
+
+// seekError returns the error of the functions using an offset.
+func (f *pipeFile) seekError(op string) error {
+	if err := f.checkOpen(op); err != nil {
+		return err
+	}
+
+	return &fs.PathError{Op: op, Path: f.name, Err: ErrIllegalSeek}
+}
+
+// IsDir is the abbreviation for Mode().IsDir().
+func (*pipeInfo) IsDir() bool {
+	return false
+}
+
+// ModTime returns the time of the last write to the pipe.
+func (info *pipeInfo) ModTime() time.Time {
+	return info.mtime
+}
+
+// Mode returns the file mode bits of the pipe.
+func (info *pipeInfo) Mode() fs.FileMode {
+	return info.mode
+}
+
+// Name returns the name of the end of the pipe.
+func (info *pipeInfo) Name() string {
+	return info.name
+}
+
+// Size returns the number of bytes written to the pipe and not read yet.
+func (info *pipeInfo) Size() int64 {
+	return info.size
+}
+
+// Sys returns nil.
+func (*pipeInfo) Sys() any {
+	return nil
+}
@@ -0,0 +1,127 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/avfs/avfs"
+)
+
+// TestPipe tests Pipe function.
+func TestPipe(t *testing.T) {
+	t.Run("ReadWrite", func(t *testing.T) {
+		r, w, err := avfs.Pipe()
+		if err != nil {
+			t.Fatalf("Pipe : want error to be nil, got %v", err)
+		}
+
+		lines := []string{"first line", "second line", "third line"}
+
+		go func() {
+			for _, line := range lines {
+				_, _ = w.WriteString(line + "\n")
+			}
+
+			_ = w.Close()
+		}()
+
+		sc := bufio.NewScanner(r)
+		for i := 0; sc.Scan(); i++ {
+			if i >= len(lines) || sc.Text() != lines[i] {
+				t.Errorf("Scan %d : want line to be in %q, got %q", i, lines, sc.Text())
+			}
+		}
+
+		if err = sc.Err(); err != nil {
+			t.Errorf("Scan : want error to be nil, got %v", err)
+		}
+
+		n, err := r.Read(make([]byte, 1))
+		if n != 0 || err != io.EOF {
+			t.Errorf("Read : want 0, io.EOF, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("BufferFull", func(t *testing.T) {
+		r, w, _ := avfs.Pipe()
+		data := []byte(strings.Repeat("0123456789", avfs.PipeBufferSize/4))
+
+		done := make(chan error)
+
+		go func() {
+			_, err := w.Write(data)
+			done <- err
+		}()
+
+		got, err := io.ReadAll(io.LimitReader(r, int64(len(data))))
+		if err != nil || string(got) != string(data) {
+			t.Errorf("ReadAll : want %d bytes, got %d, %v", len(data), len(got), err)
+		}
+
+		if err = <-done; err != nil {
+			t.Errorf("Write : want error to be nil, got %v", err)
+		}
+	})
+
+	t.Run("ReaderClosed", func(t *testing.T) {
+		r, w, _ := avfs.Pipe()
+
+		_ = r.Close()
+
+		_, err := w.Write([]byte("data"))
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("Write : want error to be %v, got %v", io.ErrClosedPipe, err)
+		}
+
+		err = r.Close()
+		if !errors.Is(err, fs.ErrClosed) {
+			t.Errorf("Close : want error to be %v, got %v", fs.ErrClosed, err)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		r, w, _ := avfs.Pipe()
+
+		_, err := r.Seek(0, io.SeekStart)
+		if !errors.Is(err, avfs.ErrIllegalSeek) {
+			t.Errorf("Seek : want error to be %v, got %v", avfs.ErrIllegalSeek, err)
+		}
+
+		_, err = r.Write([]byte("data"))
+		if !errors.Is(err, avfs.ErrBadFileDesc) {
+			t.Errorf("Write : want error to be %v, got %v", avfs.ErrBadFileDesc, err)
+		}
+
+		_, err = w.ReadDir(0)
+		if !errors.Is(err, avfs.ErrNotADirectory) {
+			t.Errorf("ReadDir : want error to be %v, got %v", avfs.ErrNotADirectory, err)
+		}
+
+		_, _ = w.WriteString("abc")
+
+		info, err := r.Stat()
+		if err != nil || info.Size() != 3 || info.Mode().Type() != fs.ModeNamedPipe || info.Name() != "|0" {
+			t.Errorf("Stat : want size 3, named pipe |0, got %v, %v", info, err)
+		}
+	})
+}
@@ -22,13 +22,48 @@ import (
 )
 
 // RndTreeOpts defines the parameters to generate a random file system tree
-// of directories, files and symbolic links.
+// of directories, files, symbolic links and hard links.
 type RndTreeOpts struct {
-	NbDirs      int // NbDirs is the number of directories.
-	NbFiles     int // NbFiles is the number of files.
-	NbSymlinks  int // NbSymlinks is the number of symbolic links.
-	MaxFileSize int // MaxFileSize is maximum size of a file.
-	MaxDepth    int // MaxDepth is the maximum depth of the tree.
+	NbDirs        int     // NbDirs is the number of directories.
+	NbFiles       int     // NbFiles is the number of files.
+	NbSymlinks    int     // NbSymlinks is the number of symbolic links.
+	NbHardlinks   int     // NbHardlinks is the number of hard links.
+	SymlinkRatio  float64 // SymlinkRatio is the number of symbolic links per file if NbSymlinks is 0.
+	HardlinkRatio float64 // HardlinkRatio is the number of hard links per file if NbHardlinks is 0.
+	MaxFileSize   int     // MaxFileSize is maximum size of a file.
+	MaxDepth      int     // MaxDepth is the maximum depth of the tree.
+	DepthDist     RndDist // DepthDist is the distribution of the depths of the parents of the generated files.
+	FileSizeDist  RndDist // FileSizeDist is the distribution of the sizes of the files.
+
+	// Seed is the seed of the random number generator, the same seed generates the same tree.
+	// If Seed is 0, a random seed is used and stored in the Seed field of the RndTree.
+	Seed int64
+}
+
+// RndDist is the distribution of a random integer between 0 and a maximum value.
+type RndDist uint8
+
+const (
+	RndDistUniform RndDist = iota // RndDistUniform draws all the values with the same probability.
+	RndDistLow                    // RndDistLow draws the small values more often (exponential distribution).
+	RndDistHigh                   // RndDistHigh draws the large values more often (reversed exponential distribution).
+)
+
+// intn returns a random integer in [0, n) following the distribution d, or 0 if n <= 0.
+func (d RndDist) intn(rnd *rand.Rand, n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	switch d {
+	case RndDistLow:
+		// The mean of the exponential distribution is a quarter of the interval.
+		return min(int(rnd.ExpFloat64()*float64(n)/4), n-1)
+	case RndDistHigh:
+		return n - 1 - RndDistLow.intn(rnd, n)
+	default:
+		return rnd.Intn(n)
+	}
 }
 
 // RndTreeDir contains parameters to create a directory.
@@ -48,14 +83,20 @@ type RndTreeSymLink struct {
 	OldName, NewName string
 }
 
-// RndTree is a random file system tree generator of directories, files and symbolic links.
+// RndTreeHardLink contains parameters to create a hard link.
+type RndTreeHardLink struct {
+	OldName, NewName string
+}
+
+// RndTree is a random file system tree generator of directories, files, symbolic links and hard links.
 type RndTree struct {
-	vfs         VFSBase           // vfs is the virtual file system.
-	rnd         *rand.Rand        // rnd is a source of random numbers.
-	dirs        []*RndTreeDir     // Dirs contains all directories.
-	files       []*RndTreeFile    // Files contains all files.
-	symLinks    []*RndTreeSymLink // SymLinks contains all symbolic links.
-	RndTreeOpts                   // RndTreeOpts regroups the options of the tree.
+	vfs         VFSBase            // vfs is the virtual file system.
+	rnd         *rand.Rand         // rnd is a source of random numbers.
+	dirs        []*RndTreeDir      // Dirs contains all directories.
+	files       []*RndTreeFile     // Files contains all files.
+	symLinks    []*RndTreeSymLink  // SymLinks contains all symbolic links.
+	hardLinks   []*RndTreeHardLink // HardLinks contains all hard links.
+	RndTreeOpts                    // RndTreeOpts regroups the options of the tree.
 }
 
 // NewRndTree returns a new random tree generator.
@@ -80,16 +121,29 @@ func NewRndTree(vfs VFSBase, opts *RndTreeOpts) *RndTree {
 		opts.MaxFileSize = 0
 	}
 
+	if opts.NbHardlinks < 0 {
+		opts.NbHardlinks = 0
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Int63() //nolint:gosec // No need for a cryptographic random number.
+	}
+
 	rt := &RndTree{
-		vfs: vfs,
-		rnd: rand.New(rand.NewSource(42)),
-		RndTreeOpts: RndTreeOpts{
-			NbDirs:      opts.NbDirs,
-			NbFiles:     opts.NbFiles,
-			NbSymlinks:  opts.NbSymlinks,
-			MaxFileSize: opts.MaxFileSize,
-			MaxDepth:    opts.MaxDepth,
-		},
+		vfs:         vfs,
+		rnd:         rand.New(rand.NewSource(seed)), //nolint:gosec // No need for a cryptographic random number.
+		RndTreeOpts: *opts,
+	}
+
+	rt.Seed = seed
+
+	if rt.NbSymlinks == 0 && rt.SymlinkRatio > 0 {
+		rt.NbSymlinks = int(rt.SymlinkRatio * float64(rt.NbFiles))
+	}
+
+	if rt.NbHardlinks == 0 && rt.HardlinkRatio > 0 {
+		rt.NbHardlinks = int(rt.HardlinkRatio * float64(rt.NbFiles))
 	}
 
 	return rt
@@ -111,11 +165,16 @@ func (rt *RndTree) GenTree() {
 	nbDirs := rt.NbDirs
 	dirs := make([]*RndTreeDir, nbDirs)
 
-	parents := make([]*RndTreeDir, 1, 10)
-	parents[0] = &RndTreeDir{}
+	// parents contains the possible parent directories by depth.
+	parents := [][]*RndTreeDir{{{}}}
+	parent := func() *RndTreeDir {
+		depthParents := parents[rt.DepthDist.intn(rt.rnd, len(parents))]
+
+		return depthParents[rt.rnd.Intn(len(depthParents))]
+	}
 
 	for i := range nbDirs {
-		parent := parents[rand.Intn(len(parents))]
+		parent := parent()
 		path := parent.Name + "/" + name("dir")
 		depth := parent.Depth + 1
 
@@ -123,7 +182,11 @@ func (rt *RndTree) GenTree() {
 		dirs[i] = dir
 
 		if depth < rt.MaxDepth {
-			parents = append(parents, dir)
+			if depth == len(parents) {
+				parents = append(parents, nil)
+			}
+
+			parents[depth] = append(parents[depth], dir)
 		}
 	}
 
@@ -133,18 +196,12 @@ func (rt *RndTree) GenTree() {
 		return
 	}
 
-	nbParents := len(parents)
 	nbFiles := rt.NbFiles
 	files := make([]*RndTreeFile, nbFiles)
 
 	for i := range nbFiles {
-		parent := parents[rand.Intn(nbParents)]
-		fileName := parent.Name + "/" + name("file")
-
-		size := 0
-		if rt.MaxFileSize > 0 {
-			size = rand.Intn(rt.MaxFileSize)
-		}
+		fileName := parent().Name + "/" + name("file")
+		size := rt.FileSizeDist.intn(rt.rnd, rt.MaxFileSize)
 
 		file := &RndTreeFile{Name: fileName, Size: size}
 		files[i] = file
@@ -152,6 +209,19 @@ func (rt *RndTree) GenTree() {
 
 	rt.files = files
 
+	if rt.vfs.HasFeature(FeatHardlink) {
+		hardLinks := make([]*RndTreeHardLink, rt.NbHardlinks)
+
+		for i := range hardLinks {
+			oldName := files[rt.rnd.Intn(nbFiles)].Name
+			newName := parent().Name + "/" + name("hardlink")
+
+			hardLinks[i] = &RndTreeHardLink{OldName: oldName, NewName: newName}
+		}
+
+		rt.hardLinks = hardLinks
+	}
+
 	if !rt.vfs.HasFeature(FeatSymlink) {
 		return
 	}
@@ -160,9 +230,8 @@ func (rt *RndTree) GenTree() {
 	symLinks := make([]*RndTreeSymLink, nbSymlinks)
 
 	for i := range nbSymlinks {
-		oldName := files[rand.Intn(nbFiles)].Name
-		newDir := parents[rand.Intn(nbParents)].Name
-		newName := newDir + "/" + name("symlink")
+		oldName := files[rt.rnd.Intn(nbFiles)].Name
+		newName := parent().Name + "/" + name("symlink")
 
 		sl := &RndTreeSymLink{OldName: oldName, NewName: newName}
 		symLinks[i] = sl
@@ -238,9 +307,34 @@ func (rt *RndTree) CreateSymlinks(baseDir string) error {
 	return nil
 }
 
+// CreateHardlinks creates random hard links.
+func (rt *RndTree) CreateHardlinks(baseDir string) error {
+	err := rt.CreateSymlinks(baseDir)
+	if err != nil {
+		return err
+	}
+
+	vfs := rt.vfs
+	if !vfs.HasFeature(FeatHardlink) {
+		return nil
+	}
+
+	for _, hardlink := range rt.hardLinks {
+		oldPath := vfs.Join(baseDir, hardlink.OldName)
+		newPath := vfs.Join(baseDir, hardlink.NewName)
+
+		err = vfs.Link(oldPath, newPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // CreateTree creates a random tree structure.
 func (rt *RndTree) CreateTree(baseDir string) error {
-	return rt.CreateSymlinks(baseDir)
+	return rt.CreateHardlinks(baseDir)
 }
 
 func (rt *RndTree) Dirs() []*RndTreeDir {
@@ -254,3 +348,7 @@ func (rt *RndTree) Files() []*RndTreeFile {
 func (rt *RndTree) SymLinks() []*RndTreeSymLink {
 	return rt.symLinks
 }
+
+func (rt *RndTree) HardLinks() []*RndTreeHardLink {
+	return rt.hardLinks
+}
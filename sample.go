@@ -0,0 +1,57 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import "time"
+
+// SampleFunc is called after an operation fn of a file system with its duration d and its error err.
+type SampleFunc func(fn FnVFS, d time.Duration, err error)
+
+// Sampler is the interface that wraps the SetSampleFunc method.
+type Sampler interface {
+	// SetSampleFunc sets the function called after each sampled operation of the file system.
+	// A nil function disables sampling.
+	SetSampleFunc(sampleFunc SampleFunc)
+}
+
+// SampleFn provides an operation sampling hook to a file system.
+// Unlike a wrapper file system, sampling costs a single test when no sampling function is set.
+type SampleFn struct {
+	sampleFunc SampleFunc // sampleFunc is called after each sampled operation, nil if sampling is disabled.
+}
+
+// Sample calls the sampling function with the duration elapsed since start and the error pointed by err.
+// It is intended to be deferred at the beginning of an operation :
+//
+//	if vfs.Sampling() {
+//		defer vfs.Sample(avfs.FnStat, time.Now(), &err)
+//	}
+func (sf *SampleFn) Sample(fn FnVFS, start time.Time, err *error) {
+	sf.sampleFunc(fn, time.Since(start), *err)
+}
+
+// Sampling returns true if a sampling function is set.
+func (sf *SampleFn) Sampling() bool {
+	return sf.sampleFunc != nil
+}
+
+// SetSampleFunc sets the function called after each sampled operation of the file system.
+// A nil function disables sampling.
+// It should be called before the file system is used by concurrent goroutines.
+func (sf *SampleFn) SetSampleFunc(sampleFunc SampleFunc) {
+	sf.sampleFunc = sampleFunc
+}
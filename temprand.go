@@ -0,0 +1,101 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+// TempRander is the interface that wraps the SetTempRand method.
+type TempRander interface {
+	// SetTempRand sets the source of random numbers used to generate the names
+	// of the files and directories created by CreateTemp and MkdirTemp.
+	// A seeded source produces reproducible names, a nil source restores the default random names.
+	SetTempRand(src rand.Source)
+}
+
+// TempRandFn provides a source of random numbers for the names of temporary files to a file system.
+type TempRandFn struct {
+	tempRand *tempRand // tempRand is the source of random numbers set by SetTempRand, nil to use the default source.
+}
+
+// tempRand is a source of random numbers safe for concurrent use.
+type tempRand struct {
+	src rand.Source // src is the source of random numbers.
+	mu  sync.Mutex  // mu is the mutex used to access src.
+}
+
+// tempRandomer is the interface implemented by the file systems embedding TempRandFn.
+type tempRandomer interface {
+	tempRandom() (string, bool)
+}
+
+// SetTempRand sets the source of random numbers used to generate the names
+// of the files and directories created by CreateTemp and MkdirTemp.
+// A seeded source produces reproducible names, a nil source restores the default random names.
+// It should be called before the file system is used by concurrent goroutines.
+func (trf *TempRandFn) SetTempRand(src rand.Source) {
+	if src == nil {
+		trf.tempRand = nil
+
+		return
+	}
+
+	trf.tempRand = &tempRand{src: src}
+}
+
+// tempRandom returns a random string for the name of a temporary file from the source set by SetTempRand,
+// ok is false if no source is set.
+func (trf *TempRandFn) tempRandom() (s string, ok bool) {
+	tr := trf.tempRand
+	if tr == nil {
+		return "", false
+	}
+
+	return tr.next(), true
+}
+
+// next returns the next random string of the source.
+func (tr *tempRand) next() string {
+	tr.mu.Lock()
+	r := uint32(tr.src.Int63())
+	tr.mu.Unlock()
+
+	return strconv.FormatUint(uint64(r), 10)
+}
+
+// nextTempRandom returns a random string for the name of a temporary file of the file system vfs,
+// from the source set by SetTempRand if vfs embeds TempRandFn,
+// or from the source seeded by the clock set by SetClock if vfs embeds ClockFn.
+// It is generic like its callers since nextRandom can't be referenced from non-generic functions.
+func nextTempRandom[T VFSBase](vfs T) string {
+	if tr, ok := any(vfs).(tempRandomer); ok {
+		if s, ok := tr.tempRandom(); ok {
+			return s
+		}
+	}
+
+	if cr, ok := any(vfs).(clockRandomer); ok {
+		if s, ok := cr.clockRandom(); ok {
+			return s
+		}
+	}
+
+	return nextRandom()
+}
@@ -18,6 +18,7 @@ package test
 
 import (
 	"io"
+	"io/fs"
 	"math/rand"
 	"os"
 	"strconv"
@@ -31,15 +32,33 @@ const (
 	maxFileSize = 1024 * bufSize
 )
 
+// BenchAll runs the standard benchmarks on vfs (create, stat, read, write, readdir, walk, rename...).
+// It is the entry point to compare the performance of file systems, including those outside this module :
+//
+//	func BenchmarkMyFSAll(b *testing.B) {
+//		test.BenchAll(b, myfs.New())
+//	}
+func BenchAll(b *testing.B, vfs avfs.VFS) {
+	ts := NewSuiteFS(b, vfs, vfs)
+	ts.BenchAll(b)
+}
+
 // BenchAll runs all benchmarks.
 func (ts *Suite) BenchAll(b *testing.B) {
 	ts.RunBenchmarks(b, UsrTest,
+		ts.BenchCorpus,
 		ts.BenchCreate,
 		ts.BenchFileRead,
 		ts.BenchFileWrite,
+		ts.BenchFileWriteAt,
 		ts.BenchMkdir,
 		ts.BenchOpenFile,
+		ts.BenchParallelRead,
+		ts.BenchReadDir,
 		ts.BenchRemove,
+		ts.BenchRename,
+		ts.BenchStat,
+		ts.BenchWalk,
 	)
 }
 
@@ -53,6 +72,45 @@ func (ts *Suite) benchOpenFlags() int {
 	return 0x4000 // syscall.O_DIRECT for linux.
 }
 
+// BenchCorpus benchmarks the creation and the walk of the small and medium standard corpora (see LoadCorpus).
+func (ts *Suite) BenchCorpus(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+
+	for _, name := range []string{CorpusSmall, CorpusMedium} {
+		c, err := LoadCorpus(name)
+		RequireNoError(b, err, "LoadCorpus %s", name)
+
+		// The benchmark function can be called several times, each creation uses a new directory.
+		created := 0
+
+		b.Run("CorpusCreate/"+name, func(b *testing.B) {
+			b.SetBytes(c.Size())
+
+			for n := 0; n < b.N; n++ {
+				created++
+				baseDir := vfs.Join(testDir, "create-"+name+"-"+strconv.Itoa(created))
+
+				err = c.Create(vfs, baseDir)
+				RequireNoError(b, err, "Create %s", baseDir)
+			}
+		})
+
+		baseDir := vfs.Join(testDir, "walk-"+name)
+
+		err = c.Create(vfs, baseDir)
+		RequireNoError(b, err, "Create %s", baseDir)
+
+		b.Run("CorpusWalk/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				err = vfs.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+					return err
+				})
+				RequireNoError(b, err, "WalkDir %s", baseDir)
+			}
+		})
+	}
+}
+
 // BenchCreate benchmarks Create function.
 func (ts *Suite) BenchCreate(b *testing.B, testDir string) {
 	vfs := ts.vfsTest
@@ -151,6 +209,71 @@ func (ts *Suite) BenchFileWrite(b *testing.B, testDir string) {
 	})
 }
 
+// BenchFileWriteAt benchmarks File.WriteAt function with database like access patterns :
+// small writes at random offsets of a large file and writes far beyond the end of the file.
+func (ts *Suite) BenchFileWriteAt(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+	fileName := vfs.Join(testDir, "BenchFileWriteAt.txt")
+
+	f, err := vfs.OpenFile(fileName, os.O_CREATE|os.O_RDWR|ts.benchOpenFlags(), avfs.DefaultFilePerm)
+	RequireNoError(b, err, "OpenFile %s", fileName)
+
+	defer func() {
+		_ = f.Close()
+		_ = vfs.Remove(fileName)
+	}()
+
+	for _, size := range []int{64, 4096} {
+		buf := make([]byte, size)
+
+		b.Run("FileWriteAtRandom"+strconv.Itoa(size), func(b *testing.B) {
+			err = f.Truncate(maxFileSize)
+			RequireNoError(b, err, "Truncate %s", fileName)
+
+			rnd := rand.New(rand.NewSource(1)) //nolint:gosec // No security-sensitive data involved.
+
+			b.SetBytes(int64(size))
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				off := rnd.Int63n(maxFileSize - int64(size))
+
+				_, err = f.WriteAt(buf, off)
+				RequireNoError(b, err, "WriteAt %s", fileName)
+			}
+		})
+	}
+
+	b.Run("FileWriteAtBeyondEOF", func(b *testing.B) {
+		const hole = 16 * bufSize
+
+		buf := make([]byte, 64)
+
+		err = f.Truncate(0)
+		RequireNoError(b, err, "Truncate %s", fileName)
+
+		off := int64(0)
+
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			off += hole
+			if off >= maxFileSize {
+				b.StopTimer()
+
+				off = hole
+				err = f.Truncate(0)
+				RequireNoError(b, err, "Truncate %s", fileName)
+
+				b.StartTimer()
+			}
+
+			_, err = f.WriteAt(buf, off)
+			RequireNoError(b, err, "WriteAt %s", fileName)
+		}
+	})
+}
+
 // BenchMkdir benchmarks Mkdir function.
 func (ts *Suite) BenchMkdir(b *testing.B, testDir string) {
 	vfs := ts.vfsTest
@@ -212,11 +335,138 @@ func (ts *Suite) BenchRemove(b *testing.B, testDir string) {
 
 		b.StartTimer()
 
-		for n := b.N - 1; n > 0; n-- {
-			path := dirs[n].Name
+		for n := b.N - 1; n >= 0; n-- {
+			path := vfs.Join(testDir, dirs[n].Name)
 
 			err = vfs.Remove(path)
 			RequireNoError(b, err, "Remove %s", path)
 		}
 	})
 }
+
+// BenchParallelRead benchmarks Stat and ReadFile functions called concurrently on the same file,
+// run with -cpu to measure how the concurrent readers scale.
+func (ts *Suite) BenchParallelRead(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+	fileName := vfs.Join(testDir, "BenchParallelRead.txt")
+
+	err := vfs.WriteFile(fileName, make([]byte, 4096), avfs.DefaultFilePerm)
+	RequireNoError(b, err, "WriteFile %s", fileName)
+
+	b.ResetTimer()
+
+	b.Run("ParallelStat", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, err := vfs.Stat(fileName)
+				AssertNoError(b, err, "Stat %s", fileName)
+			}
+		})
+	})
+
+	b.Run("ParallelReadFile", func(b *testing.B) {
+		b.SetBytes(4096)
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, err := vfs.ReadFile(fileName)
+				AssertNoError(b, err, "ReadFile %s", fileName)
+			}
+		})
+	})
+}
+
+// BenchReadDir benchmarks ReadDir function on a directory of 100 files.
+func (ts *Suite) BenchReadDir(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+
+	for i := range 100 {
+		ts.createFile(b, vfs.Join(testDir, "file"+strconv.Itoa(i)), avfs.DefaultFilePerm)
+	}
+
+	b.ResetTimer()
+
+	b.Run("ReadDir", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			_, err := vfs.ReadDir(testDir)
+			RequireNoError(b, err, "ReadDir %s", testDir)
+		}
+	})
+}
+
+// BenchRename benchmarks Rename function.
+func (ts *Suite) BenchRename(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+
+	b.Run("Rename", func(b *testing.B) {
+		b.StopTimer()
+
+		oldNames := make([]string, b.N)
+		newNames := make([]string, b.N)
+
+		for n := 0; n < b.N; n++ {
+			name := strconv.Itoa(n)
+			oldNames[n] = vfs.Join(testDir, "old"+name)
+			newNames[n] = vfs.Join(testDir, "new"+name)
+
+			ts.createFile(b, oldNames[n], avfs.DefaultFilePerm)
+		}
+
+		b.StartTimer()
+
+		for n := 0; n < b.N; n++ {
+			err := vfs.Rename(oldNames[n], newNames[n])
+			RequireNoError(b, err, "Rename %s %s", oldNames[n], newNames[n])
+		}
+
+		b.StopTimer()
+
+		for n := 0; n < b.N; n++ {
+			_ = vfs.Remove(newNames[n])
+		}
+
+		b.StartTimer()
+	})
+}
+
+// BenchStat benchmarks Stat function.
+func (ts *Suite) BenchStat(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+	fileName := ts.existingFile(b, testDir, nil)
+
+	b.ResetTimer()
+
+	b.Run("Stat", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			_, err := vfs.Stat(fileName)
+			RequireNoError(b, err, "Stat %s", fileName)
+		}
+	})
+}
+
+// BenchWalk benchmarks WalkDir function on a tree of 10 directories of 10 sub directories of 10 files.
+func (ts *Suite) BenchWalk(b *testing.B, testDir string) {
+	vfs := ts.vfsTest
+
+	for i := range 10 {
+		for j := range 10 {
+			dir := vfs.Join(testDir, "dir"+strconv.Itoa(i), "sub"+strconv.Itoa(j))
+			ts.createDir(b, dir, avfs.DefaultDirPerm)
+
+			for k := range 10 {
+				ts.createFile(b, vfs.Join(dir, "file"+strconv.Itoa(k)), avfs.DefaultFilePerm)
+			}
+		}
+	}
+
+	b.ResetTimer()
+
+	b.Run("Walk", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			err := vfs.WalkDir(testDir, func(path string, d fs.DirEntry, err error) error {
+				return err
+			})
+			RequireNoError(b, err, "WalkDir %s", testDir)
+		}
+	})
+}
@@ -0,0 +1,270 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/avfs/avfs"
+)
+
+const (
+	concurrentWorkers = 8   // concurrentWorkers is the number of goroutines of the concurrent tests.
+	concurrentOps     = 100 // concurrentOps is the number of operations of each goroutine of the concurrent tests.
+)
+
+// TestConcurrent hammers the file system with concurrent operations from many goroutines
+// and checks the invariants of the resulting tree. Run with -race, it checks the locking of the file system.
+func (ts *Suite) TestConcurrent(t *testing.T) {
+	if ts.vfsTest.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	ts.RunTests(t, UsrTest,
+		ts.ConcurrentCreateRemove,
+		ts.ConcurrentMkdirRemove,
+		ts.ConcurrentRename)
+}
+
+// ConcurrentCreateRemove creates and removes files of a directory concurrently while reading the directory.
+func (ts *Suite) ConcurrentCreateRemove(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	ts.concurrentFunc(t, func(worker int) {
+		for i := range concurrentOps {
+			path := vfs.Join(testDir, "w"+strconv.Itoa(worker)+"-"+strconv.Itoa(i%4))
+
+			err := vfs.WriteFile(path, []byte(path), avfs.DefaultFilePerm)
+			if !AssertNoError(t, err, "WriteFile %s", path) {
+				return
+			}
+
+			// The last file of each worker is kept.
+			if i < concurrentOps-1 {
+				err = vfs.Remove(path)
+				if !AssertNoError(t, err, "Remove %s", path) {
+					return
+				}
+			}
+		}
+	}, func(int) {
+		ts.concurrentReadDir(t, testDir)
+	})
+
+	entries, err := vfs.ReadDir(testDir)
+	RequireNoError(t, err, "ReadDir %s", testDir)
+
+	if len(entries) != concurrentWorkers {
+		t.Errorf("ReadDir %s : want number of files to be %d, got %d", testDir, concurrentWorkers, len(entries))
+	}
+
+	for _, entry := range entries {
+		path := vfs.Join(testDir, entry.Name())
+
+		content, err := vfs.ReadFile(path)
+		RequireNoError(t, err, "ReadFile %s", path)
+
+		if string(content) != path {
+			t.Errorf("ReadFile %s : want content to be %s, got %s", path, path, content)
+		}
+	}
+}
+
+// ConcurrentMkdirRemove creates and removes the same directories concurrently while reading their parent.
+func (ts *Suite) ConcurrentMkdirRemove(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	ts.concurrentFunc(t, func(worker int) {
+		for i := range concurrentOps {
+			path := vfs.Join(testDir, "dir"+strconv.Itoa((worker+i)%4))
+
+			if i%2 == 0 {
+				err := vfs.Mkdir(path, avfs.DefaultDirPerm)
+				ts.concurrentErr(t, err, "Mkdir "+path, fs.ErrExist)
+
+				continue
+			}
+
+			err := vfs.Remove(path)
+			ts.concurrentErr(t, err, "Remove "+path, fs.ErrNotExist)
+		}
+	}, func(int) {
+		ts.concurrentReadDir(t, testDir)
+	})
+
+	ts.concurrentCheckTree(t, testDir)
+}
+
+// ConcurrentRename moves files between two directories concurrently while reading the directories.
+// No file must be lost or duplicated.
+func (ts *Suite) ConcurrentRename(t *testing.T, testDir string) {
+	const nbFiles = 16
+
+	vfs := ts.vfsTest
+	dirs := [2]string{vfs.Join(testDir, "a"), vfs.Join(testDir, "b")}
+
+	for _, dir := range dirs {
+		ts.createDir(t, dir, avfs.DefaultDirPerm)
+	}
+
+	for i := range nbFiles {
+		ts.createFile(t, vfs.Join(dirs[0], "f"+strconv.Itoa(i)), avfs.DefaultFilePerm)
+	}
+
+	ts.concurrentFunc(t, func(worker int) {
+		for i := range concurrentOps {
+			name := "f" + strconv.Itoa((worker*7+i)%nbFiles)
+			from := (worker + i) % 2
+			oldPath, newPath := vfs.Join(dirs[from], name), vfs.Join(dirs[1-from], name)
+
+			err := vfs.Rename(oldPath, newPath)
+			ts.concurrentErr(t, err, "Rename "+oldPath+" "+newPath, fs.ErrNotExist)
+		}
+	}, func(worker int) {
+		ts.concurrentReadDir(t, dirs[worker%2])
+	})
+
+	var names []string
+
+	for _, dir := range dirs {
+		entries, err := vfs.ReadDir(dir)
+		RequireNoError(t, err, "ReadDir %s", dir)
+
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+	}
+
+	slices.Sort(names)
+
+	if len(names) != nbFiles || len(slices.Compact(names)) != nbFiles {
+		t.Errorf("ReadDir : want %d distinct files, got %v", nbFiles, names)
+	}
+
+	ts.concurrentCheckTree(t, testDir)
+}
+
+// concurrentFunc runs concurrently writer in concurrentWorkers goroutines and reader in as many goroutines
+// until all the writers are done. The goroutines start simultaneously.
+func (ts *Suite) concurrentFunc(t *testing.T, writer, reader func(worker int)) {
+	var (
+		wgWriters sync.WaitGroup
+		wgReaders sync.WaitGroup
+	)
+
+	start := make(chan struct{})
+	done := make(chan struct{})
+
+	for worker := range concurrentWorkers {
+		wgWriters.Add(1)
+
+		go func() {
+			defer wgWriters.Done()
+
+			<-start
+			writer(worker)
+		}()
+
+		wgReaders.Add(1)
+
+		go func() {
+			defer wgReaders.Done()
+
+			<-start
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					reader(worker)
+				}
+			}
+		}()
+	}
+
+	close(start)
+	wgWriters.Wait()
+	close(done)
+	wgReaders.Wait()
+}
+
+// concurrentErr reports an error if err is not nil and is not one of the errors expected from concurrent operations.
+// On Windows, the access denied errors caused by concurrent accesses to the same file are also expected.
+func (ts *Suite) concurrentErr(tb testing.TB, err error, op string, expected ...error) {
+	tb.Helper()
+
+	if err == nil {
+		return
+	}
+
+	if ts.vfsTest.OSType() == avfs.OsWindows {
+		expected = append(expected, fs.ErrPermission)
+	}
+
+	for _, e := range expected {
+		if errors.Is(err, e) {
+			return
+		}
+	}
+
+	tb.Errorf("%s : want error to be nil or one of %v, got %v", op, expected, err)
+}
+
+// concurrentReadDir reads the directory dir and checks that the names are sorted and distinct.
+func (ts *Suite) concurrentReadDir(tb testing.TB, dir string) {
+	tb.Helper()
+
+	entries, err := ts.vfsTest.ReadDir(dir)
+	if err != nil {
+		tb.Errorf("ReadDir %s : want error to be nil, got %v", dir, err)
+
+		return
+	}
+
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].Name() >= entries[i].Name() {
+			tb.Errorf("ReadDir %s : want names to be sorted and distinct, got %s before %s",
+				dir, entries[i-1].Name(), entries[i].Name())
+
+			return
+		}
+	}
+}
+
+// concurrentCheckTree checks that all the files of the tree testDir can be reached after concurrent operations.
+func (ts *Suite) concurrentCheckTree(tb testing.TB, testDir string) {
+	tb.Helper()
+
+	vfs := ts.vfsTest
+
+	err := vfs.WalkDir(testDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		_, err = vfs.Lstat(path)
+
+		return err
+	})
+	RequireNoError(tb, err, "WalkDir %s", testDir)
+}
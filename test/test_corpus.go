@@ -0,0 +1,188 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// Names of the standard benchmark corpora.
+const (
+	CorpusSmall  = "small"  // CorpusSmall has 16 directories, 128 files (200 KB) and 8 symbolic links.
+	CorpusMedium = "medium" // CorpusMedium has 128 directories, 2048 files (10 MB) and 64 symbolic links.
+	CorpusLarge  = "large"  // CorpusLarge has 512 directories, 8192 files (42 MB) and 256 symbolic links.
+)
+
+// corpusFS contains the manifests of the standard benchmark corpora.
+//
+//go:embed testdata/corpus/*.txt
+var corpusFS embed.FS
+
+// Corpus is a tree of directories, files and symbolic links read from a manifest,
+// used as an identical input to compare the performances of file systems or versions of avfs.
+//
+// A manifest is a text file, each line is either an empty line, a comment starting with '#' or an entry :
+//
+//	d <dir>                     a directory.
+//	f <file> <size>             a file of size bytes.
+//	l <symbolic link> <target>  a symbolic link to a file.
+//
+// All the paths are slash separated and relative to the base directory of the corpus.
+// The manifests of the standard corpora must never change, new corpora must have new names.
+type Corpus struct {
+	Name     string                 // Name is the name of the corpus.
+	Dirs     []*avfs.RndTreeDir     // Dirs are the directories, parents first.
+	Files    []*avfs.RndTreeFile    // Files are the files.
+	SymLinks []*avfs.RndTreeSymLink // SymLinks are the symbolic links.
+}
+
+// CorpusNames returns the names of the standard benchmark corpora from the smallest to the largest.
+func CorpusNames() []string {
+	return []string{CorpusSmall, CorpusMedium, CorpusLarge}
+}
+
+// LoadCorpus loads the standard benchmark corpus name.
+func LoadCorpus(name string) (*Corpus, error) {
+	f, err := corpusFS.Open("testdata/corpus/" + name + ".txt")
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return ParseCorpus(name, f)
+}
+
+// ParseCorpus reads the manifest of a corpus from r.
+func ParseCorpus(name string, r io.Reader) (*Corpus, error) {
+	c := &Corpus{Name: name}
+	s := bufio.NewScanner(r)
+
+	for lineNum := 1; s.Scan(); lineNum++ {
+		line := s.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 2 && fields[0] == "d":
+			c.Dirs = append(c.Dirs, &avfs.RndTreeDir{Name: fields[1], Depth: strings.Count(fields[1], "/")})
+		case len(fields) == 3 && fields[0] == "f":
+			size, err := strconv.Atoi(fields[2])
+			if err != nil || size < 0 {
+				return nil, fmt.Errorf("corpus %s line %d : invalid size %q", name, lineNum, fields[2])
+			}
+
+			c.Files = append(c.Files, &avfs.RndTreeFile{Name: fields[1], Size: size})
+		case len(fields) == 3 && fields[0] == "l":
+			c.SymLinks = append(c.SymLinks, &avfs.RndTreeSymLink{OldName: fields[2], NewName: fields[1]})
+		default:
+			return nil, fmt.Errorf("corpus %s line %d : invalid entry %q", name, lineNum, line)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Size returns the total size of the files of the corpus.
+func (c *Corpus) Size() int64 {
+	var size int64
+
+	for _, file := range c.Files {
+		size += int64(file.Size)
+	}
+
+	return size
+}
+
+// Create creates the directories, files and symbolic links of the corpus in baseDir.
+// The content of the files is the prefix of a fixed pseudo random sequence of bytes.
+// Symbolic links are not created if vfs doesn't support them.
+func (c *Corpus) Create(vfs avfs.VFSBase, baseDir string) error {
+	for _, dir := range c.Dirs {
+		path := vfs.Join(baseDir, dir.Name)
+
+		err := vfs.MkdirAll(path, avfs.DefaultDirPerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	buf := corpusContent(c.maxFileSize())
+
+	for _, file := range c.Files {
+		path := vfs.Join(baseDir, file.Name)
+
+		err := vfs.WriteFile(path, buf[:file.Size], avfs.DefaultFilePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !vfs.HasFeature(avfs.FeatSymlink) {
+		return nil
+	}
+
+	for _, symlink := range c.SymLinks {
+		oldPath := vfs.Join(baseDir, symlink.OldName)
+		newPath := vfs.Join(baseDir, symlink.NewName)
+
+		err := vfs.Symlink(oldPath, newPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxFileSize returns the size of the largest file of the corpus.
+func (c *Corpus) maxFileSize() int {
+	maxSize := 0
+	for _, file := range c.Files {
+		maxSize = max(maxSize, file.Size)
+	}
+
+	return maxSize
+}
+
+// corpusContent returns size bytes of a fixed pseudo random sequence (linear congruential generator),
+// independent of the version of Go.
+func corpusContent(size int) []byte {
+	buf := make([]byte, size)
+	x := uint32(1)
+
+	for i := range buf {
+		x = x*1664525 + 1013904223
+		buf[i] = byte(x >> 24)
+	}
+
+	return buf
+}
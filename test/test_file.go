@@ -22,6 +22,7 @@ import (
 	"io/fs"
 	"os"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
@@ -61,6 +62,19 @@ func (ts *Suite) TestFileChdir(t *testing.T, testDir string) {
 	dirs := ts.createSampleDirs(t, testDir)
 	vfs := ts.vfsTest
 
+	if vfs.HasFeature(avfs.FeatRealFS) && vfs.OSType() == avfs.OsWindows {
+		// Windows doesn't change the current directory from a file handle, only emulated Windows file systems do.
+		f, err := vfs.OpenFile(testDir, os.O_RDONLY, 0)
+		RequireNoError(t, err, "OpenFile %s", testDir)
+
+		defer f.Close()
+
+		err = f.Chdir()
+		AssertPathError(t, err).Op("chdir").Path(testDir).Err(avfs.ErrWinNotSupported).Test()
+
+		return
+	}
+
 	t.Run("FileChdir", func(t *testing.T) {
 		for _, dir := range dirs {
 			f, err := vfs.OpenFile(dir.Path, os.O_RDONLY, 0)
@@ -80,6 +94,35 @@ func (ts *Suite) TestFileChdir(t *testing.T, testDir string) {
 		}
 	})
 
+	t.Run("FileChdirRelative", func(t *testing.T) {
+		err := vfs.Chdir(testDir)
+		RequireNoError(t, err, "Chdir %s", testDir)
+
+		dir := dirs[1].Path
+
+		relDir, err := vfs.Rel(testDir, dir)
+		RequireNoError(t, err, "Rel %s", dir)
+
+		f, err := vfs.OpenFile(relDir, os.O_RDONLY, 0)
+		RequireNoError(t, err, "OpenFile %s", relDir)
+
+		defer f.Close()
+
+		err = vfs.Chdir(dirs[0].Path)
+		RequireNoError(t, err, "Chdir %s", dirs[0].Path)
+
+		// The file is still the directory resolved when it was opened.
+		err = f.Chdir()
+		AssertNoError(t, err, "Chdir %s", relDir)
+
+		curDir, err := vfs.Getwd()
+		AssertNoError(t, err, "Getwd %s", dir)
+
+		if curDir != dir {
+			t.Errorf("Getwd : want current directory to be %s, got %s", dir, curDir)
+		}
+	})
+
 	t.Run("FileChdirOnFile", func(t *testing.T) {
 		f, fileName := ts.openedEmptyFile(t, testDir)
 
@@ -121,6 +164,31 @@ func (ts *Suite) TestFileChmod(t *testing.T, testDir string) {
 		return
 	}
 
+	t.Run("FileChmodOnDir", func(t *testing.T) {
+		const wantMode = fs.FileMode(0o700)
+
+		dir := ts.existingDir(t, testDir)
+
+		f, err := vfs.OpenFile(dir, os.O_RDONLY, 0)
+		RequireNoError(t, err, "OpenFile %s", dir)
+
+		defer f.Close()
+
+		err = f.Chmod(wantMode)
+		RequireNoError(t, err, "Chmod %s", dir)
+
+		if vfs.OSType() == avfs.OsWindows {
+			return
+		}
+
+		info, err := vfs.Stat(dir)
+		RequireNoError(t, err, "Stat %s", dir)
+
+		if info.Mode().Perm() != wantMode {
+			t.Errorf("Chmod %s : want mode to be %s, got %s", dir, wantMode, info.Mode().Perm())
+		}
+	})
+
 	t.Run("FileChmodClosed", func(t *testing.T) {
 		f, fileName := ts.closedFile(t, testDir)
 
@@ -161,6 +229,14 @@ func (ts *Suite) TestFileChown(t *testing.T, testDir string) {
 
 		err := f.Chown(uid, gid)
 
+		if vfs.HasFeature(avfs.FeatStrict) && !vfs.HasFeature(avfs.FeatIdentityMgr) {
+			AssertPathError(t, err).Op("chown").Path(f.Name()).
+				OSType(avfs.OsLinux).Err(avfs.ErrNotSupported).Test().
+				OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+
+			return
+		}
+
 		AssertPathError(t, err).Op("chown").
 			OSType(avfs.OsLinux).NoError().Test().
 			OSType(avfs.OsWindows).Path(f.Name()).Err(avfs.ErrWinNotSupported).Test()
@@ -941,6 +1017,16 @@ func (ts *Suite) TestFileSync(t *testing.T, testDir string) {
 		return
 	}
 
+	t.Run("FileSyncOnDir", func(t *testing.T) {
+		f, err := vfs.OpenFile(testDir, os.O_RDONLY, 0)
+		RequireNoError(t, err, "OpenFile %s", testDir)
+
+		defer f.Close()
+
+		err = f.Sync()
+		RequireNoError(t, err, "Sync %s", testDir)
+	})
+
 	t.Run("FileSyncClosed", func(t *testing.T) {
 		f, fileName := ts.closedFile(t, testDir)
 
@@ -1116,6 +1202,32 @@ func (ts *Suite) TestFileWrite(t *testing.T, testDir string) {
 		}
 	})
 
+	t.Run("FileWriteBeyondEOF", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, data)
+
+		f, err := vfs.OpenFile(path, os.O_RDWR, 0)
+		RequireNoError(t, err, "OpenFile %s", path)
+
+		defer f.Close()
+
+		const hole = 5
+
+		_, err = f.Seek(int64(len(data)+hole), io.SeekStart)
+		RequireNoError(t, err, "Seek %s", path)
+
+		_, err = f.Write(data)
+		RequireNoError(t, err, "Write %s", path)
+
+		want := slices.Concat(data, make([]byte, hole), data)
+
+		rb, err := vfs.ReadFile(path)
+		RequireNoError(t, err, "ReadFile %s", path)
+
+		if !bytes.Equal(rb, want) {
+			t.Errorf("ReadFile : want content to be %q, got %q", want, rb)
+		}
+	})
+
 	t.Run("FileWriteNonExisting", func(t *testing.T) {
 		f := ts.openedNonExistingFile(t, testDir)
 		buf := make([]byte, 0)
@@ -21,6 +21,7 @@ import (
 	"io/fs"
 	"math"
 	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/avfs/avfs"
@@ -34,6 +35,7 @@ func (ts *Suite) TestIdmAll(t *testing.T) {
 	ts.TestGroupAddDel(t)
 	ts.TestUserAddDel(t)
 	ts.TestLookup(t)
+	ts.TestIdmEvents(t)
 }
 
 // TestAdminGroupUser tests AdminGroup and AdminUser.
@@ -323,6 +325,81 @@ func (ts *Suite) TestUserAddDel(t *testing.T) {
 	})
 }
 
+// TestIdmEvents tests the events of identity managers implementing avfs.IdmSubscriber.
+func (ts *Suite) TestIdmEvents(t *testing.T) {
+	idm := ts.idm
+
+	is, ok := idm.(avfs.IdmSubscriber)
+	if !ok || !idm.HasFeature(avfs.FeatIdentityMgr) || idm.HasFeature(avfs.FeatReadOnlyIdm) {
+		return
+	}
+
+	suffix := fmt.Sprintf("IdmEvents%x", rand.Uint32())
+	groupName := "Grp" + suffix
+	userName := "Usr" + suffix
+
+	type event struct {
+		typ       avfs.IdmEventType
+		userName  string
+		groupName string
+	}
+
+	var events []event
+
+	cancel := is.Subscribe(func(e avfs.IdmEvent) {
+		// Subscribers can look up users and groups.
+		_, _ = idm.LookupGroup(groupName)
+
+		ev := event{typ: e.Type}
+		if e.User != nil {
+			ev.userName = e.User.Name()
+		}
+
+		if e.Group != nil {
+			ev.groupName = e.Group.Name()
+		}
+
+		events = append(events, ev)
+	})
+
+	_, err := idm.AddGroup(groupName)
+	RequireNoError(t, err, "AddGroup %s", groupName)
+
+	_, err = idm.AddUser(userName, groupName)
+	RequireNoError(t, err, "AddUser %s", userName)
+
+	_, err = idm.AddUser(userName, groupName)
+	if err != avfs.AlreadyExistsUserError(userName) {
+		t.Errorf("AddUser %s : want error to be %v, got %v", userName, avfs.AlreadyExistsUserError(userName), err)
+	}
+
+	err = idm.DelUser(userName)
+	RequireNoError(t, err, "DelUser %s", userName)
+
+	err = idm.DelGroup(groupName)
+	RequireNoError(t, err, "DelGroup %s", groupName)
+
+	cancel()
+	cancel()
+
+	_, err = idm.AddGroup(groupName)
+	RequireNoError(t, err, "AddGroup %s", groupName)
+
+	err = idm.DelGroup(groupName)
+	RequireNoError(t, err, "DelGroup %s", groupName)
+
+	wantEvents := []event{
+		{typ: avfs.IdmGroupAdded, groupName: groupName},
+		{typ: avfs.IdmUserAdded, userName: userName, groupName: groupName},
+		{typ: avfs.IdmUserRemoved, userName: userName},
+		{typ: avfs.IdmGroupRemoved, groupName: groupName},
+	}
+
+	if !slices.Equal(events, wantEvents) {
+		t.Errorf("Subscribe : want events to be %v, got %v", wantEvents, events)
+	}
+}
+
 // TestLookup tests Lookup* functions.
 func (ts *Suite) TestLookup(t *testing.T) {
 	idm := ts.idm
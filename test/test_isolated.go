@@ -0,0 +1,47 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/vfs/basepathfs"
+	"github.com/avfs/avfs/vfs/osfs"
+)
+
+// IsolatedOsFS returns an OsFS confined to a new temporary root directory removed at the end of the test.
+// Absolute paths, relative paths and ".." elements are all resolved inside the root,
+// the system directories, TempDir and the home directory of the current user are created inside it.
+func IsolatedOsFS(tb testing.TB) *basepathfs.BasePathFS {
+	tb.Helper()
+
+	baseFS := osfs.New()
+	rootDir := TempRoot(tb, baseFS)
+
+	err := avfs.MkSystemDirs(baseFS, avfs.SystemDirs(baseFS, rootDir))
+	RequireNoError(tb, err, "MkSystemDirs %s", rootDir)
+
+	vfs := basepathfs.New(baseFS, rootDir)
+
+	for _, dir := range []string{vfs.TempDir(), avfs.HomeDirUser(vfs, "", vfs.User())} {
+		err = vfs.MkdirAll(dir, avfs.DefaultDirPerm)
+		RequireNoError(tb, err, "MkdirAll %s", dir)
+	}
+
+	return vfs
+}
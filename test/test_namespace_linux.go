@@ -0,0 +1,91 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build linux
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+// RunInNamespaceEnv is the environment variable set in the child process started by RunInNamespace.
+const RunInNamespaceEnv = "AVFS_RUN_IN_NAMESPACE"
+
+// mountTmpOnce mounts a tmpfs on the temporary directory of the child process once.
+var mountTmpOnce sync.Once //nolint:gochecknoglobals // Only one mount per child process.
+
+// RunInNamespace runs the current test as root in new user and mount namespaces (Linux only),
+// so that chroot, chown and permission tests work without real root privileges
+// and without modifying the host.
+//
+// When called from the test process, RunInNamespace re-executes the test binary
+// in new namespaces, restricted to the current test, reports its failures and returns false.
+// When called from the re-executed test binary, it mounts a tmpfs on the temporary directory
+// (see os.TempDir) and returns true : the rest of the test runs as root of the namespace.
+// Mounts are private to the namespace and the tmpfs vanishes when the child process exits.
+//
+// If the current user is root, uids and gids from 0 to 65535 are mapped to the host ones,
+// otherwise only root of the namespace is mapped to the current user.
+// The test is skipped if user namespaces are not available.
+//
+//	func TestChroot(t *testing.T) {
+//		if !test.RunInNamespace(t) {
+//			return
+//		}
+//
+//		// Runs as root in a user namespace.
+//	}
+func RunInNamespace(t *testing.T) bool {
+	t.Helper()
+
+	if os.Getenv(RunInNamespaceEnv) != "" {
+		mountTmpOnce.Do(func() {
+			err := syscall.Mount("tmpfs", os.TempDir(), "tmpfs", 0, "mode=1777")
+			RequireNoError(t, err, "Mount tmpfs %s", os.TempDir())
+		})
+
+		return true
+	}
+
+	exe, err := os.Executable()
+	RequireNoError(t, err, "Executable")
+
+	uid, gid, size := os.Getuid(), os.Getgid(), 1
+	if uid == 0 {
+		size = 65536
+	}
+
+	cmd := exec.Command(exe, childTestArgs(t)...)
+	cmd.Env = append(os.Environ(), RunInNamespaceEnv+"=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:   syscall.CLONE_NEWUSER,
+		Unshareflags: syscall.CLONE_NEWNS,
+		UidMappings:  []syscall.SysProcIDMap{{ContainerID: 0, HostID: uid, Size: size}},
+		GidMappings:  []syscall.SysProcIDMap{{ContainerID: 0, HostID: gid, Size: size}},
+	}
+
+	err = runChildTest(t, cmd, "RunInNamespace")
+	if err != nil {
+		t.Skipf("RunInNamespace : user namespaces are not available : %v", err)
+	}
+
+	return false
+}
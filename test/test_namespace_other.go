@@ -0,0 +1,34 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !linux
+
+package test
+
+import "testing"
+
+// RunInNamespaceEnv is the environment variable set in the child process started by RunInNamespace.
+const RunInNamespaceEnv = "AVFS_RUN_IN_NAMESPACE"
+
+// RunInNamespace runs the current test as root in new user and mount namespaces (Linux only).
+// On other operating systems, the test is skipped.
+func RunInNamespace(t *testing.T) bool {
+	t.Helper()
+
+	t.Skip("RunInNamespace : user namespaces are only available on Linux")
+
+	return false
+}
@@ -0,0 +1,76 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/idm/memidm"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/osfs"
+)
+
+// MatrixOSTypes are the OS types emulated by RunOSMatrix.
+var MatrixOSTypes = []avfs.OSType{avfs.OsLinux, avfs.OsWindows} //nolint:gochecknoglobals // Read only list of OS types.
+
+// CanEmulate returns true if vfs emulates the OS type ost.
+// An OS type other than the one of the host can only be emulated with the path functions
+// of the build tag 'avfs_setostype'.
+func CanEmulate(vfs avfs.VFSBase, ost avfs.OSType) bool {
+	return vfs.OSType() == ost && (ost == avfs.CurrentOSType() || avfs.BuildFeatures()&avfs.FeatSetOSType != 0)
+}
+
+// RunOSMatrix runs fn as a subtest named "MemFS/<OS type>" against a new MemFS emulating each OS type of MatrixOSTypes.
+// The subtests of OS types that can't be emulated without the build tag 'avfs_setostype' are skipped.
+func RunOSMatrix(t *testing.T, fn func(t *testing.T, vfs avfs.VFS)) {
+	t.Helper()
+
+	for _, ost := range MatrixOSTypes {
+		t.Run("MemFS/"+ost.String(), func(t *testing.T) {
+			idm := memidm.NewWithOptions(&memidm.Options{OSType: ost})
+			vfs := memfs.NewWithOptions(&memfs.Options{Idm: idm, OSType: ost})
+			if !CanEmulate(vfs, ost) {
+				t.Skipf("MemFS can't emulate %s, use build tag 'avfs_setostype'", ost)
+			}
+
+			fn(t, vfs)
+		})
+	}
+}
+
+// RunOSMatrixWithOsFS runs fn like RunOSMatrix, then as a subtest named "OsFS/<OS type>" against OsFS
+// if the OS type of the host is one of MatrixOSTypes.
+// Files created by fn on OsFS should be created under a temporary directory (see TempRoot).
+func RunOSMatrixWithOsFS(t *testing.T, fn func(t *testing.T, vfs avfs.VFS)) {
+	t.Helper()
+
+	RunOSMatrix(t, fn)
+
+	ost := avfs.CurrentOSType()
+	t.Run("OsFS/"+ost.String(), func(t *testing.T) {
+		for _, mt := range MatrixOSTypes {
+			if mt == ost {
+				fn(t, osfs.New())
+
+				return
+			}
+		}
+
+		t.Skipf("OsFS : %s is not in the OS types of the matrix", ost)
+	})
+}
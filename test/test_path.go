@@ -0,0 +1,368 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/avfs/avfs"
+)
+
+// pathFunc is a function of a file system called with a single path.
+type pathFunc struct {
+	fn       func(path string) error // fn calls the function with path.
+	name     string                  // name is the name of the function.
+	mutation bool                    // mutation is true if the function modifies the file system.
+}
+
+// TestPaths runs the tests of the edge cases of paths.
+//
+// All file systems follow the contract of the Linux system calls used by the os package :
+//   - an empty path is never found, except by RemoveAll which does nothing
+//     and EvalSymlinks which returns the current directory ".".
+//   - "." is the current directory, ".." the parent directory, "dir/." is dir.
+//   - repeated and trailing path separators are ignored for directories.
+//   - a file followed by a path separator, "/." or "/.." is not a directory.
+//   - a symbolic link followed by a path separator is resolved, even by Lstat.
+//   - a directory can't be removed or renamed by a path ending with "." or "..".
+//   - a path ending with a path separator can't be created as a file.
+//
+// On Windows, "." and ".." are resolved lexically like Clean does.
+func (ts *Suite) TestPaths(t *testing.T) {
+	ts.RunTests(t, UsrTest,
+		ts.TestPathDot,
+		ts.TestPathEmpty,
+		ts.TestPathSeparators)
+}
+
+// pathFuncs returns the functions of the file system called with a single path.
+func (ts *Suite) pathFuncs() []pathFunc {
+	vfs := ts.vfsTest
+
+	pfs := []pathFunc{
+		{name: "Chdir", fn: vfs.Chdir},
+		{name: "Lstat", fn: func(path string) error { _, err := vfs.Lstat(path); return err }},
+		{name: "Open", fn: func(path string) error { return closeFile(vfs.OpenFile(path, os.O_RDONLY, 0)) }},
+		{name: "ReadDir", fn: func(path string) error { _, err := vfs.ReadDir(path); return err }},
+		{name: "ReadFile", fn: func(path string) error { _, err := vfs.ReadFile(path); return err }},
+		{name: "Stat", fn: func(path string) error { _, err := vfs.Stat(path); return err }},
+		{name: "Chmod", fn: func(path string) error { return vfs.Chmod(path, avfs.DefaultDirPerm) }, mutation: true},
+		{name: "Chtimes", fn: func(path string) error { return vfs.Chtimes(path, time.Now(), time.Now()) }, mutation: true},
+		{name: "Create", fn: func(path string) error {
+			return closeFile(vfs.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, avfs.DefaultFilePerm))
+		}, mutation: true},
+		{name: "Mkdir", fn: func(path string) error { return vfs.Mkdir(path, avfs.DefaultDirPerm) }, mutation: true},
+		{name: "MkdirAll", fn: func(path string) error { return vfs.MkdirAll(path, avfs.DefaultDirPerm) }, mutation: true},
+		{name: "Remove", fn: vfs.Remove, mutation: true},
+		{name: "Truncate", fn: func(path string) error { return vfs.Truncate(path, 0) }, mutation: true},
+	}
+
+	if vfs.HasFeature(avfs.FeatSymlink) {
+		pfs = append(pfs, pathFunc{name: "Readlink", fn: func(path string) error { _, err := vfs.Readlink(path); return err }})
+	}
+
+	return pfs
+}
+
+// closeFile closes the file f opened without error.
+func closeFile(f avfs.File, err error) error {
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// TestPathDot tests the paths ending with "." or "..".
+func (ts *Suite) TestPathDot(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	sep := string(vfs.PathSeparator())
+
+	dir := ts.existingDir(t, testDir)
+	dirDot := dir + sep + "."
+	dirDotDot := dir + sep + ".."
+
+	t.Run("PathDotStat", func(t *testing.T) {
+		for _, tc := range []struct{ path, want string }{
+			{path: ".", want: testDir},
+			{path: dirDot, want: dir},
+			{path: dirDotDot, want: testDir},
+		} {
+			info, err := vfs.Stat(tc.path)
+			RequireNoError(t, err, "Stat %s", tc.path)
+
+			want, err := vfs.Stat(tc.want)
+			RequireNoError(t, err, "Stat %s", tc.want)
+
+			if !info.IsDir() || !vfs.SameFile(info, want) {
+				t.Errorf("Stat %s : want %s, got %s with mode %s", tc.path, tc.want, info.Name(), info.Mode())
+			}
+		}
+	})
+
+	t.Run("PathDotReadDir", func(t *testing.T) {
+		entries, err := vfs.ReadDir(dirDotDot)
+		RequireNoError(t, err, "ReadDir %s", dirDotDot)
+
+		if len(entries) != 1 || entries[0].Name() != vfs.Base(dir) {
+			t.Errorf("ReadDir %s : want only %s, got %v", dirDotDot, vfs.Base(dir), entries)
+		}
+	})
+
+	if vfs.HasFeature(avfs.FeatReadOnly) || vfs.OSType() == avfs.OsWindows {
+		return
+	}
+
+	t.Run("PathDotMkdir", func(t *testing.T) {
+		err := vfs.Mkdir(dirDot, avfs.DefaultDirPerm)
+		if !errors.Is(err, fs.ErrExist) {
+			t.Errorf("Mkdir %s : want error to be ErrExist, got %v", dirDot, err)
+		}
+	})
+
+	t.Run("PathDotRemove", func(t *testing.T) {
+		err := vfs.Remove(dirDot)
+		AssertPathError(t, err).Op("remove").Path(dirDot).Err(avfs.ErrInvalidArgument).Test()
+
+		err = vfs.Remove(".")
+		AssertPathError(t, err).Op("remove").Path(".").Err(avfs.ErrInvalidArgument).Test()
+
+		err = vfs.Remove(dirDotDot)
+		AssertPathError(t, err).Op("remove").Path(dirDotDot).Err(avfs.ErrDirNotEmpty).Test()
+
+		err = vfs.RemoveAll(dirDot)
+		AssertPathError(t, err).Op("RemoveAll").Path(dirDot).Err(avfs.ErrInvalidArgument).Test()
+
+		_, err = vfs.Stat(dir)
+		RequireNoError(t, err, "Stat %s", dir)
+	})
+
+	t.Run("PathDotRename", func(t *testing.T) {
+		newDir := vfs.Join(testDir, "newDir")
+
+		err := vfs.Rename(dirDot, newDir)
+		AssertLinkError(t, err).Op("rename").Old(dirDot).New(newDir).Err(avfs.ErrDeviceBusy).Test()
+
+		err = vfs.Rename(dir, dirDot)
+		AssertLinkError(t, err).Op("rename").Old(dir).New(dirDot).Err(avfs.ErrDeviceBusy).Test()
+
+		_, err = vfs.Stat(dir)
+		RequireNoError(t, err, "Stat %s", dir)
+	})
+}
+
+// TestPathEmpty tests the functions called with an empty path.
+func (ts *Suite) TestPathEmpty(t *testing.T, _ string) {
+	vfs := ts.vfsTest
+
+	t.Run("PathEmptyNotFound", func(t *testing.T) {
+		for _, pf := range ts.pathFuncs() {
+			if pf.mutation && vfs.HasFeature(avfs.FeatReadOnly) {
+				continue
+			}
+
+			err := pf.fn("")
+
+			var pe *fs.PathError
+			if !errors.As(err, &pe) || pe.Path != "" || !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("%s : want error to be a PathError with an empty path and ErrNotExist, got %v", pf.name, err)
+			}
+		}
+	})
+
+	t.Run("PathEmptyLinks", func(t *testing.T) {
+		if vfs.HasFeature(avfs.FeatReadOnly) {
+			return
+		}
+
+		existingFile := ts.emptyFile(t, ts.rootDir)
+
+		errs := map[string]error{"Rename": vfs.Rename("", existingFile)}
+
+		if vfs.HasFeature(avfs.FeatHardlink) {
+			errs["Link"] = vfs.Link(existingFile, "")
+		}
+
+		if vfs.HasFeature(avfs.FeatSymlink) {
+			errs["Symlink"] = vfs.Symlink(existingFile, "")
+		}
+
+		for name, err := range errs {
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("%s : want error to be ErrNotExist, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("PathEmptyRemoveAll", func(t *testing.T) {
+		if vfs.HasFeature(avfs.FeatReadOnly) {
+			return
+		}
+
+		err := vfs.RemoveAll("")
+		RequireNoError(t, err, "RemoveAll")
+
+		_, err = vfs.Stat(".")
+		RequireNoError(t, err, "Stat %s", ".")
+	})
+
+	t.Run("PathEmptyEvalSymlinks", func(t *testing.T) {
+		if !vfs.HasFeature(avfs.FeatSymlink) {
+			return
+		}
+
+		path, err := vfs.EvalSymlinks("")
+		if path != "." || err != nil {
+			t.Errorf("EvalSymlinks : want path to be . and error to be nil, got %q, %v", path, err)
+		}
+	})
+}
+
+// TestPathSeparators tests the paths with repeated or trailing path separators.
+func (ts *Suite) TestPathSeparators(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	sep := string(vfs.PathSeparator())
+
+	dir := ts.existingDir(t, testDir)
+	file := ts.existingFile(t, dir, nil)
+	fileName := vfs.Base(file)
+
+	t.Run("PathSeparatorsDir", func(t *testing.T) {
+		for _, path := range []string{dir + sep, dir + sep + sep, dir + sep + "." + sep} {
+			info, err := vfs.Stat(path)
+			RequireNoError(t, err, "Stat %s", path)
+
+			if !info.IsDir() {
+				t.Errorf("Stat %s : want a directory, got mode %s", path, info.Mode())
+			}
+		}
+
+		path := dir + sep + sep + fileName
+
+		info, err := vfs.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+
+		if !info.Mode().IsRegular() {
+			t.Errorf("Stat %s : want a regular file, got mode %s", path, info.Mode())
+		}
+	})
+
+	if vfs.OSType() == avfs.OsWindows {
+		return
+	}
+
+	t.Run("PathSeparatorsFile", func(t *testing.T) {
+		for _, path := range []string{file + sep, file + sep + "."} {
+			for _, pf := range ts.pathFuncs() {
+				if pf.mutation && vfs.HasFeature(avfs.FeatReadOnly) {
+					continue
+				}
+
+				err := pf.fn(path)
+
+				switch {
+				case pf.name == "Mkdir" && path == file+sep:
+					if !errors.Is(err, fs.ErrExist) {
+						t.Errorf("%s %s : want error to be ErrExist, got %v", pf.name, path, err)
+					}
+				case pf.name == "Create" && path == file+sep:
+					AssertPathError(t, err).Path(path).Err(avfs.ErrIsADirectory).Test()
+				case pf.name == "MkdirAll":
+					AssertPathError(t, err).Err(avfs.ErrNotADirectory, avfs.ErrFileExists).Test()
+				default:
+					AssertPathError(t, err).Path(path).Err(avfs.ErrNotADirectory).Test()
+				}
+			}
+		}
+
+		_, err := vfs.Stat(file)
+		RequireNoError(t, err, "Stat %s", file)
+	})
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	t.Run("PathSeparatorsCreate", func(t *testing.T) {
+		newDir := vfs.Join(testDir, "newDir") + sep
+
+		err := vfs.Mkdir(newDir, avfs.DefaultDirPerm)
+		RequireNoError(t, err, "Mkdir %s", newDir)
+
+		newFile := vfs.Join(testDir, "newFile") + sep
+
+		err = closeFile(vfs.OpenFile(newFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, avfs.DefaultFilePerm))
+		AssertPathError(t, err).Op("open").Path(newFile).Err(avfs.ErrIsADirectory).Test()
+
+		err = vfs.Rename(file, newFile)
+		AssertLinkError(t, err).Op("rename").Old(file).New(newFile).Err(avfs.ErrNotADirectory).Test()
+
+		renamedDir := vfs.Join(testDir, "renamedDir") + sep
+
+		err = vfs.Rename(newDir, renamedDir)
+		RequireNoError(t, err, "Rename %s %s", newDir, renamedDir)
+	})
+
+	t.Run("PathSeparatorsSymlink", func(t *testing.T) {
+		if !vfs.HasFeature(avfs.FeatSymlink) {
+			return
+		}
+
+		link := vfs.Join(testDir, "link")
+
+		err := vfs.Symlink(dir, link)
+		RequireNoError(t, err, "Symlink %s %s", dir, link)
+
+		info, err := vfs.Lstat(link + sep)
+		RequireNoError(t, err, "Lstat %s", link+sep)
+
+		if !info.IsDir() {
+			t.Errorf("Lstat %s : want the symbolic link to be resolved, got mode %s", link+sep, info.Mode())
+		}
+
+		_, err = vfs.Readlink(link + sep)
+		AssertPathError(t, err).Op("readlink").Path(link + sep).Err(avfs.ErrInvalidArgument).Test()
+
+		err = vfs.Remove(link + sep)
+		AssertPathError(t, err).Op("remove").Path(link + sep).Err(avfs.ErrNotADirectory).Test()
+
+		newLink := vfs.Join(testDir, "newLink") + sep
+
+		err = vfs.Symlink(dir, newLink)
+		AssertLinkError(t, err).Op("symlink").New(newLink).Err(avfs.ErrNoSuchFileOrDir).Test()
+
+		err = vfs.Symlink(dir, dir+sep)
+		AssertLinkError(t, err).Op("symlink").New(dir + sep).Err(avfs.ErrFileExists).Test()
+
+		_, err = vfs.Lstat(link)
+		RequireNoError(t, err, "Lstat %s", link)
+	})
+
+	t.Run("PathSeparatorsRemoveAll", func(t *testing.T) {
+		err := vfs.RemoveAll(file + sep)
+		RequireNoError(t, err, "RemoveAll %s", file+sep)
+
+		_, err = vfs.Lstat(file)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Lstat %s : want error to be ErrNotExist, got %v", file, err)
+		}
+	})
+}
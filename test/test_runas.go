@@ -0,0 +1,163 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/idm/osidm"
+)
+
+// RunAsUserEnv is the environment variable set in the child process started by RunAsUser.
+// It contains the name of the user running the child process.
+const RunAsUserEnv = "AVFS_RUN_AS_USER"
+
+// RunAsUser runs the current test as the unprivileged user userName
+// without changing the user of the test process itself.
+//
+// When called from the test process, RunAsUser re-executes a copy of the test binary
+// as userName, restricted to the current test, reports its failures and returns false.
+// When called from the re-executed test binary, it returns true and the rest of the test
+// runs as userName. The working directory of the child process is a temporary directory.
+//
+// The test is skipped if the current user is not root or if userName does not exist.
+//
+//	func TestPerm(t *testing.T) {
+//		if !test.RunAsUser(t, "nobody") {
+//			return
+//		}
+//
+//		// Runs as nobody.
+//	}
+func RunAsUser(t *testing.T, userName string) bool {
+	t.Helper()
+
+	if os.Getenv(RunAsUserEnv) == userName {
+		return true
+	}
+
+	idm := osidm.New()
+	if idm.HasFeature(avfs.FeatReadOnlyIdm) || !idm.HasFeature(avfs.FeatIdentityMgr) {
+		t.Skipf("RunAsUser : user %s can't be set by a non root user", userName)
+	}
+
+	u, err := idm.LookupUser(userName)
+	if err != nil {
+		t.Skipf("RunAsUser : user %s : %v", userName, err)
+	}
+
+	dir, exe := copyTestBinary(t)
+
+	cmd, err := osidm.Command(u, exe, childTestArgs(t)...)
+	RequireNoError(t, err, "Command %s", exe)
+
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), RunAsUserEnv+"="+userName)
+
+	err = runChildTest(t, cmd, "RunAsUser")
+	if err != nil {
+		t.Errorf("RunAsUser : test %s as user %s : %v", t.Name(), userName, err)
+	}
+
+	return false
+}
+
+// childTestArgs returns the arguments of a test binary to run only the current test.
+func childTestArgs(t *testing.T) []string {
+	args := []string{"-test.run=" + testRunPattern(t.Name())}
+	if testing.Verbose() {
+		args = append(args, "-test.v")
+	}
+
+	return args
+}
+
+// runChildTest runs the test binary command cmd and reports its output.
+// If the command can't be started, the error is returned.
+func runChildTest(t *testing.T, cmd *exec.Cmd, helper string) error {
+	t.Helper()
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return err
+		}
+
+		t.Errorf("%s : test %s failed : %v\n%s", helper, t.Name(), err, out)
+
+		return nil
+	}
+
+	if testing.Verbose() {
+		t.Logf("%s : test %s\n%s", helper, t.Name(), out)
+	}
+
+	return nil
+}
+
+// copyTestBinary copies the current test binary to a temporary directory
+// readable and executable by any user, since the original binary usually lies in a directory
+// only accessible to the current user.
+func copyTestBinary(t *testing.T) (dir, exe string) {
+	t.Helper()
+
+	src, err := os.Executable()
+	RequireNoError(t, err, "Executable")
+
+	dir, err = os.MkdirTemp("", "avfs-runas")
+	RequireNoError(t, err, "MkdirTemp")
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	err = os.Chmod(dir, 0o755)
+	RequireNoError(t, err, "Chmod %s", dir)
+
+	exe = filepath.Join(dir, filepath.Base(src))
+
+	srcFile, err := os.Open(src)
+	RequireNoError(t, err, "Open %s", src)
+
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(exe, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o755)
+	RequireNoError(t, err, "OpenFile %s", exe)
+
+	_, err = io.Copy(dstFile, srcFile)
+	RequireNoError(t, err, "Copy %s", exe)
+
+	err = dstFile.Close()
+	RequireNoError(t, err, "Close %s", exe)
+
+	return dir, exe
+}
+
+// testRunPattern returns the -test.run pattern matching exactly the test testName.
+func testRunPattern(testName string) string {
+	parts := strings.Split(testName, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -188,33 +188,6 @@ func (ts *Suite) createFile(tb testing.TB, fileName string, mode fs.FileMode) {
 	RequireNoError(tb, err, "WriteFile %s", fileName)
 }
 
-// createRootDir creates tests root directory.
-func (ts *Suite) createRootDir(tb testing.TB) {
-	vfs := ts.vfsSetup
-	rootDir := ""
-
-	if _, ok := tb.(*testing.B); ok && vfs.HasFeature(avfs.FeatRealFS) {
-		// run Benches on real disks, /tmp is usually an in memory file system.
-		rootDir = vfs.Join(avfs.HomeDirUser(vfs, "", vfs.User()), "tmp")
-		ts.createDir(tb, rootDir, avfs.DefaultDirPerm)
-	}
-
-	rootDir, err := vfs.MkdirTemp(rootDir, "avfs")
-	RequireNoError(tb, err, "MkdirTemp %s", rootDir)
-
-	// Make rootDir accessible by anyone.
-	err = vfs.Chmod(rootDir, avfs.DefaultDirPerm)
-	RequireNoError(tb, err, "Chmod %s", rootDir)
-
-	// Ensure rootDir does not include symbolic links.
-	if vfs.HasFeature(avfs.FeatSymlink) {
-		rootDir, err = vfs.EvalSymlinks(rootDir)
-		RequireNoError(tb, err, "EvalSymlinks %s", rootDir)
-	}
-
-	ts.rootDir = rootDir
-}
-
 // emptyFile returns an empty file name.
 func (ts *Suite) emptyFile(tb testing.TB, testDir string) string {
 	const emptyFile = "emptyFile"
@@ -319,6 +292,24 @@ func formatArgs(msgAndArgs []any) string {
 	return fmt.Sprintf(format, msgAndArgs[1:]...)
 }
 
+// nlink checks that the link count of path reported by ToSysStat is want.
+// Windows file systems always report a single link and are not checked.
+func (ts *Suite) nlink(tb testing.TB, path string, want uint64) {
+	tb.Helper()
+
+	vfs := ts.vfsTest
+	if vfs.HasFeature(avfs.FeatRealFS) && vfs.OSType() == avfs.OsWindows {
+		return
+	}
+
+	info, err := vfs.Lstat(path)
+	RequireNoError(tb, err, "Lstat %s", path)
+
+	if got := vfs.ToSysStat(info).Nlink(); got != want {
+		tb.Errorf("Nlink %s : want link count to be %d, got %d", path, want, got)
+	}
+}
+
 // nonExistingFile returns the name of a non-existing file.
 func (ts *Suite) nonExistingFile(tb testing.TB, testDir string) string {
 	vfs := ts.vfsSetup
@@ -370,6 +361,13 @@ func (ts *Suite) randomDir(tb testing.TB, testDir string) *avfs.RndTree {
 	opts := &avfs.RndTreeOpts{NbDirs: 3, NbFiles: 11, NbSymlinks: 4, MaxFileSize: 0, MaxDepth: 0}
 	rt := avfs.NewRndTree(vfs, opts)
 
+	// The seed reproduces the same tree with RndTreeOpts.Seed.
+	tb.Cleanup(func() {
+		if tb.Failed() {
+			tb.Logf("random tree of %s generated with seed %d", testDir, rt.Seed)
+		}
+	})
+
 	err := rt.CreateTree(testDir)
 	RequireNoError(tb, err, "rt.Create %s", testDir)
 
@@ -387,10 +385,7 @@ func (ts *Suite) removeDir(tb testing.TB, testDir string) {
 	// to clean up files with different permissions.
 	ts.setInitUser(tb)
 
-	err = vfs.RemoveAll(testDir)
-	if err != nil && avfs.CurrentOSType() != avfs.OsWindows {
-		tb.Fatalf("RemoveAll %s : want error to be nil, got %v", testDir, err)
-	}
+	RemoveAll(tb, vfs, testDir)
 }
 
 // RequireNoError require that a function returned no error.
@@ -406,7 +401,9 @@ func RequireNoError(tb testing.TB, err error, msgAndArgs ...any) {
 func (ts *Suite) RunBenchmarks(b *testing.B, userName string, BenchFuncs ...func(b *testing.B, testDir string)) {
 	vfs := ts.vfsSetup
 
-	ts.createRootDir(b)
+	ts.rootDir = TempRoot(b, vfs)
+
+	defer ts.setInitUser(b)
 
 	for _, bf := range BenchFuncs {
 		ts.setUser(b, userName)
@@ -421,15 +418,13 @@ func (ts *Suite) RunBenchmarks(b *testing.B, userName string, BenchFuncs ...func
 
 		ts.removeDir(b, testDir)
 	}
-
-	ts.removeDir(b, ts.rootDir)
 }
 
 // RunTests runs all test functions specified as user userName.
 func (ts *Suite) RunTests(t *testing.T, userName string, testFuncs ...func(t *testing.T, testDir string)) {
 	vfs := ts.vfsSetup
 
-	ts.createRootDir(t)
+	ts.rootDir = TempRoot(t, vfs)
 
 	defer ts.setInitUser(t)
 
@@ -448,8 +443,6 @@ func (ts *Suite) RunTests(t *testing.T, userName string, testFuncs ...func(t *te
 
 		ts.removeDir(t, testDir)
 	}
-
-	ts.removeDir(t, ts.rootDir)
 }
 
 // setUser sets the test user to userName.
@@ -482,7 +475,9 @@ func testDataDir() string {
 func (ts *Suite) TestVFSAll(t *testing.T) {
 	ts.TestVFS(t)
 	ts.TestFile(t)
+	ts.TestPaths(t)
 	ts.TestUtils(t)
+	ts.TestConcurrent(t)
 }
 
 // VFSSetup returns the file system used to set up the tests.
@@ -0,0 +1,101 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avfs/avfs"
+)
+
+const (
+	removeRetries = 5                     // removeRetries is the maximum number of retries of RemoveAll on Windows.
+	removeBackoff = 10 * time.Millisecond // removeBackoff is the delay before the first retry, doubled after each retry.
+)
+
+// TempRoot creates a temporary root directory for the test or benchmark tb on any file system
+// and returns its path. The directory is accessible by anyone and its path does not include symbolic links.
+// It is removed with RemoveAll when tb and all its subtests complete.
+func TempRoot(tb testing.TB, vfs avfs.VFSBase) string {
+	tb.Helper()
+
+	rootDir := ""
+
+	if _, ok := tb.(*testing.B); ok && vfs.HasFeature(avfs.FeatRealFS) {
+		// run Benches on real disks, /tmp is usually an in memory file system.
+		rootDir = vfs.Join(avfs.HomeDirUser(vfs, "", vfs.User()), "tmp")
+
+		err := vfs.MkdirAll(rootDir, avfs.DefaultDirPerm)
+		RequireNoError(tb, err, "MkdirAll %s", rootDir)
+	}
+
+	rootDir, err := vfs.MkdirTemp(rootDir, "avfs")
+	RequireNoError(tb, err, "MkdirTemp %s", rootDir)
+
+	// Make rootDir accessible by anyone.
+	err = vfs.Chmod(rootDir, avfs.DefaultDirPerm)
+	RequireNoError(tb, err, "Chmod %s", rootDir)
+
+	// Ensure rootDir does not include symbolic links.
+	if vfs.HasFeature(avfs.FeatSymlink) {
+		rootDir, err = vfs.EvalSymlinks(rootDir)
+		RequireNoError(tb, err, "EvalSymlinks %s", rootDir)
+	}
+
+	tb.Cleanup(func() {
+		// The current directory can't be removed on Windows.
+		if wd, err := vfs.Getwd(); err == nil && strings.HasPrefix(wd, rootDir) {
+			_ = vfs.Chdir(vfs.Dir(rootDir))
+		}
+
+		RemoveAll(tb, vfs, rootDir)
+	})
+
+	return rootDir
+}
+
+// RemoveAll removes path and any children it contains.
+// On Windows, files still open can't be removed, the removal is retried with an exponential backoff
+// and the remaining error is only logged.
+func RemoveAll(tb testing.TB, vfs avfs.VFSBase, path string) {
+	tb.Helper()
+
+	err := vfs.RemoveAll(path)
+	if err == nil {
+		return
+	}
+
+	if avfs.CurrentOSType() != avfs.OsWindows {
+		tb.Fatalf("RemoveAll %s : want error to be nil, got %v", path, err)
+	}
+
+	backoff := removeBackoff
+
+	for range removeRetries {
+		time.Sleep(backoff)
+		backoff *= 2
+
+		err = vfs.RemoveAll(path)
+		if err == nil {
+			return
+		}
+	}
+
+	tb.Logf("RemoveAll %s : %v", path, err)
+}
@@ -17,6 +17,8 @@
 package test
 
 import (
+	"context"
+
 	"github.com/avfs/avfs"
 )
 
@@ -75,3 +77,13 @@ type permError struct {
 	ErrNew  string  `json:"errNew,omitempty"`
 	ErrErr  string  `json:"errErr,omitempty"`
 }
+
+// constSource is a rand.Source always returning the same number,
+// it makes every name generated by CreateTemp or MkdirTemp collide.
+type constSource int64
+
+// cancelAfterCtx is a context canceled after n calls to its Err method.
+type cancelAfterCtx struct {
+	context.Context     // Context is the parent context.
+	n               int // n is the number of calls to Err before the context is canceled.
+}
@@ -0,0 +1,88 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package test
+
+import (
+	"testing"
+
+	"github.com/avfs/avfs"
+)
+
+// CreateUserWithHome creates the user userName with the primary group groupName, created if needed,
+// and the home directory of the user on vfs, they are all removed at the end of the test.
+// The parent of the home directory, created with HomeDirPerm, is made searchable by all users
+// until the end of the test, so that the user can reach its home directory.
+// It returns the user and a view of vfs bound to the user : a clone of vfs if it implements avfs.Cloner,
+// vfs itself otherwise, its current user being restored at the end of the test.
+// The test is skipped if the identity manager of vfs can't create users.
+func CreateUserWithHome(tb testing.TB, vfs avfs.VFS, userName, groupName string) (avfs.VFS, avfs.UserReader) {
+	tb.Helper()
+
+	idm := vfs.Idm()
+	if !idm.HasFeature(avfs.FeatIdentityMgr) || idm.HasFeature(avfs.FeatReadOnlyIdm) {
+		tb.Skipf("CreateUserWithHome : identity manager %s can't create users", idm.Type())
+	}
+
+	if _, err := idm.LookupGroup(groupName); err == avfs.UnknownGroupError(groupName) {
+		_, err = idm.AddGroup(groupName)
+		RequireNoError(tb, err, "AddGroup %s", groupName)
+
+		tb.Cleanup(func() { _ = idm.DelGroup(groupName) })
+	}
+
+	u, err := idm.AddUser(userName, groupName)
+	RequireNoError(tb, err, "AddUser %s", userName)
+
+	tb.Cleanup(func() { _ = idm.DelUser(userName) })
+
+	homeDir, err := avfs.MkHomeDir(vfs, "", u)
+	RequireNoError(tb, err, "MkHomeDir %s", userName)
+
+	tb.Cleanup(func() { RemoveAll(tb, vfs, homeDir) })
+
+	if vfs.OSType() != avfs.OsWindows {
+		parentDir := vfs.Dir(homeDir)
+
+		info, err := vfs.Stat(parentDir)
+		RequireNoError(tb, err, "Stat %s", parentDir)
+
+		if perm := info.Mode().Perm(); perm&0o111 != 0o111 {
+			err = vfs.Chmod(parentDir, perm|0o111)
+			RequireNoError(tb, err, "Chmod %s", parentDir)
+
+			tb.Cleanup(func() { _ = vfs.Chmod(parentDir, perm) })
+		}
+	}
+
+	view := vfs
+
+	if c, ok := vfs.(avfs.Cloner); ok {
+		view = c.Clone()
+	} else {
+		initUser := vfs.User()
+
+		tb.Cleanup(func() {
+			err := vfs.SetUser(initUser)
+			RequireNoError(tb, err, "SetUser %s", initUser.Name())
+		})
+	}
+
+	err = view.SetUser(u)
+	RequireNoError(tb, err, "SetUser %s", userName)
+
+	return view, u
+}
@@ -18,14 +18,23 @@ package test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/vfs/memfs"
@@ -37,15 +46,32 @@ type pathTest struct {
 
 func (ts *Suite) TestUtils(t *testing.T) {
 	ts.RunTests(t, UsrTest,
+		ts.TestApplyMetadata,
+		ts.TestCopy,
+		ts.TestCopyDir,
 		ts.TestCopyFile,
+		ts.TestCreateAnonymousTemp,
+		ts.TestDiff,
+		ts.TestDiffFS,
 		ts.TestDirExists,
+		ts.TestEvictLRU,
 		ts.TestExists,
+		ts.TestFindHardlinkGroups,
+		ts.TestFollow,
 		ts.TestHashFile,
+		ts.TestHashTree,
 		ts.TestIsDir,
 		ts.TestIsEmpty,
 		ts.TestIsPathSeparator,
+		ts.TestMkdirAllContext,
+		ts.TestMove,
+		ts.TestOwnerOf,
+		ts.TestRemoveAllContext,
 		ts.TestRndTree,
-		ts.TestUMask)
+		ts.TestSplitPattern,
+		ts.TestTreeSize,
+		ts.TestUMask,
+		ts.TestWalk)
 }
 
 // TestAbs test Abs function.
@@ -339,66 +365,822 @@ func (ts *Suite) TestClean(t *testing.T, _ string) {
 	}
 }
 
+// TestApplyMetadata tests avfs.ApplyMetadata function.
+func (ts *Suite) TestApplyMetadata(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		err := avfs.ApplyMetadata(vfs, []avfs.MetadataOp{{Path: testDir, Set: avfs.MetadataMode, Mode: 0o700}})
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("ApplyMetadata : want error to be ErrPermission, got %v", err)
+		}
+
+		return
+	}
+
+	_ = ts.createSampleDirs(t, testDir)
+	files := ts.createSampleFiles(t, testDir)
+
+	t.Run("ApplyMetadataModeTimes", func(t *testing.T) {
+		const wantMode = fs.FileMode(0o640)
+
+		mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+
+		ops := []avfs.MetadataOp{{Path: nonExistingFile, Set: avfs.MetadataMode, Mode: wantMode}}
+		for _, file := range files {
+			op := avfs.MetadataOp{Path: file.Path, Set: avfs.MetadataMode | avfs.MetadataTimes, Mode: wantMode, Atime: mtime, Mtime: mtime}
+			ops = append(ops, op)
+		}
+
+		err := avfs.ApplyMetadata(vfs, ops)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ApplyMetadata : want error to be ErrNotExist for %s, got %v", nonExistingFile, err)
+		}
+
+		for _, file := range files {
+			info, err := vfs.Stat(file.Path)
+			RequireNoError(t, err, "Stat %s", file.Path)
+
+			if vfs.OSType() != avfs.OsWindows && info.Mode().Perm() != wantMode {
+				t.Errorf("ApplyMetadata %s : want mode to be %s, got %s", file.Path, wantMode, info.Mode().Perm())
+			}
+
+			if !info.ModTime().Equal(mtime) {
+				t.Errorf("ApplyMetadata %s : want modification time to be %s, got %s", file.Path, mtime, info.ModTime())
+			}
+		}
+	})
+
+	t.Run("ApplyMetadataOwner", func(t *testing.T) {
+		// Changing the owner with ApplyMetadata succeeds or fails like Chown.
+		uid, gid := 0, 0
+		if vfs.HasFeature(avfs.FeatIdentityMgr) {
+			u := vfs.User()
+			uid, gid = u.Uid(), u.Gid()
+		}
+
+		chownErr := vfs.Chown(files[0].Path, uid, gid)
+		err := avfs.ApplyMetadata(vfs, []avfs.MetadataOp{{Path: files[1].Path, Set: avfs.MetadataOwner, Uid: uid, Gid: gid}})
+
+		if (err == nil) != (chownErr == nil) {
+			t.Errorf("ApplyMetadata : want error to be like Chown error %v, got %v", chownErr, err)
+		}
+	})
+}
+
+// TestCopy tests avfs.Copy function.
+func (ts *Suite) TestCopy(t *testing.T, testDir string) {
+	srcFS := ts.vfsSetup
+	dstFS := memfs.New()
+	dstDir := TempRoot(t, dstFS)
+
+	const chunkSize = 1000
+
+	data := bytes.Repeat([]byte("0123456789"), 1234)
+	srcPath := srcFS.Join(testDir, "src")
+
+	err := srcFS.WriteFile(srcPath, data, avfs.DefaultFilePerm)
+	RequireNoError(t, err, "WriteFile %s", srcPath)
+
+	// copyFile copies the source file to a new file of the destination file system and checks its content.
+	copyFile := func(t *testing.T, opts *avfs.CopyIOOptions) (avfs.CopyIOStats, error) {
+		t.Helper()
+
+		src, err := srcFS.OpenFile(srcPath, os.O_RDONLY, 0)
+		RequireNoError(t, err, "Open %s", srcPath)
+
+		defer src.Close()
+
+		dst, err := dstFS.CreateTemp(dstDir, "dst")
+		RequireNoError(t, err, "CreateTemp %s", dstDir)
+
+		defer dst.Close()
+
+		stats, err := avfs.Copy(dst, src, opts)
+		if err != nil {
+			return stats, err
+		}
+
+		got, err := dstFS.ReadFile(dst.Name())
+		RequireNoError(t, err, "ReadFile %s", dst.Name())
+
+		if !bytes.Equal(got, data) {
+			t.Errorf("Copy : want content to be identical, got %d bytes instead of %d", len(got), len(data))
+		}
+
+		return stats, nil
+	}
+
+	t.Run("CopyDefault", func(t *testing.T) {
+		stats, err := copyFile(t, nil)
+		RequireNoError(t, err, "Copy %s", srcPath)
+
+		if stats.Written != int64(len(data)) {
+			t.Errorf("Copy : want written to be %d, got %d", len(data), stats.Written)
+		}
+
+		if !stats.FastPath && stats.ChunkSize < avfs.DefaultCopyChunkSize {
+			t.Errorf("Copy : want chunk size to be at least %d, got %d", avfs.DefaultCopyChunkSize, stats.ChunkSize)
+		}
+	})
+
+	t.Run("CopyProgress", func(t *testing.T) {
+		var written []int64
+
+		stats, err := copyFile(t, &avfs.CopyIOOptions{
+			ChunkSize: chunkSize,
+			Progress: func(stats avfs.CopyIOStats) error {
+				written = append(written, stats.Written)
+
+				return nil
+			},
+		})
+		RequireNoError(t, err, "Copy %s", srcPath)
+
+		if stats.FastPath || stats.ChunkSize != chunkSize {
+			t.Errorf("Copy : want chunks of %d bytes, got %+v", chunkSize, stats)
+		}
+
+		wantCalls := (len(data) + chunkSize - 1) / chunkSize
+		if len(written) != wantCalls || written[len(written)-1] != int64(len(data)) {
+			t.Errorf("Copy : want %d progress calls up to %d bytes, got %v", wantCalls, len(data), written)
+		}
+
+		if stats.Throughput() <= 0 {
+			t.Errorf("Throughput : want a positive throughput, got %f", stats.Throughput())
+		}
+	})
+
+	t.Run("CopyChunkSize", func(t *testing.T) {
+		stats, err := copyFile(t, &avfs.CopyIOOptions{ChunkSize: chunkSize})
+		RequireNoError(t, err, "Copy %s", srcPath)
+
+		if stats.FastPath || stats.ChunkSize != chunkSize {
+			t.Errorf("Copy : want chunks of %d bytes, got %+v", chunkSize, stats)
+		}
+	})
+
+	t.Run("CopyBlockSize", func(t *testing.T) {
+		info, err := dstFS.Stat(dstDir)
+		RequireNoError(t, err, "Stat %s", dstDir)
+
+		bs, ok := info.Sys().(avfs.BlockSizer)
+		if !ok {
+			t.Fatalf("Stat : want Sys() to implement avfs.BlockSizer, got %T", info.Sys())
+		}
+
+		stats, err := copyFile(t, &avfs.CopyIOOptions{Progress: func(avfs.CopyIOStats) error { return nil }})
+		RequireNoError(t, err, "Copy %s", srcPath)
+
+		if stats.ChunkSize < avfs.DefaultCopyChunkSize || int64(stats.ChunkSize)%bs.BlockSize() != 0 {
+			t.Errorf("Copy : want chunks of a multiple of %d bytes, got %d", bs.BlockSize(), stats.ChunkSize)
+		}
+	})
+
+	t.Run("CopyProgressError", func(t *testing.T) {
+		wantErr := errors.New("stop")
+
+		stats, err := copyFile(t, &avfs.CopyIOOptions{
+			ChunkSize: chunkSize,
+			Progress: func(stats avfs.CopyIOStats) error {
+				if stats.Written >= 2*chunkSize {
+					return wantErr
+				}
+
+				return nil
+			},
+		})
+		if err != wantErr {
+			t.Errorf("Copy : want error to be %v, got %v", wantErr, err)
+		}
+
+		if stats.Written != 2*chunkSize {
+			t.Errorf("Copy : want written to be %d, got %d", 2*chunkSize, stats.Written)
+		}
+	})
+}
+
+// TestCopyDir tests avfs.CopyDir function.
+func (ts *Suite) TestCopyDir(t *testing.T, testDir string) {
+	srcFS := ts.vfsSetup
+	content := []byte("CopyDir")
+	srcDir := srcFS.Join(testDir, "src")
+	subDir := srcFS.Join(srcDir, "sub")
+	file := srcFS.Join(subDir, "file")
+	hasHardlink := srcFS.HasFeature(avfs.FeatHardlink)
+	hasSymlink := srcFS.HasFeature(avfs.FeatSymlink)
+
+	ts.createDir(t, subDir, avfs.DefaultDirPerm)
+
+	err := srcFS.WriteFile(file, content, avfs.DefaultFilePerm)
+	RequireNoError(t, err, "WriteFile %s", file)
+
+	if hasHardlink {
+		err = srcFS.Link(file, srcFS.Join(subDir, "hardlink"))
+		RequireNoError(t, err, "Link %s", file)
+	}
+
+	if hasSymlink {
+		err = srcFS.Symlink("file", srcFS.Join(subDir, "symlink"))
+		RequireNoError(t, err, "Symlink %s", file)
+
+		err = srcFS.Symlink("sub", srcFS.Join(srcDir, "dirlink"))
+		RequireNoError(t, err, "Symlink %s", subDir)
+	}
+
+	mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, path := range []string{file, subDir} {
+		err = srcFS.Chtimes(path, mtime, mtime)
+		RequireNoError(t, err, "Chtimes %s", path)
+	}
+
+	copyDir := func(t *testing.T, opts *avfs.CopyOptions) (avfs.VFS, string) {
+		t.Helper()
+
+		dstFS := memfs.New()
+		dstDir := TempRoot(t, dstFS)
+
+		err := avfs.CopyDir(dstFS, srcFS, dstDir, srcDir, opts)
+		RequireNoError(t, err, "CopyDir %s", srcDir)
+
+		gotContent, err := dstFS.ReadFile(dstFS.Join(dstDir, "sub", "file"))
+		RequireNoError(t, err, "ReadFile")
+
+		if !bytes.Equal(gotContent, content) {
+			t.Errorf("ReadFile : want content to be %q, got %q", content, gotContent)
+		}
+
+		return dstFS, dstDir
+	}
+
+	t.Run("CopyDirDefault", func(t *testing.T) {
+		dstFS, dstDir := copyDir(t, nil)
+
+		for _, path := range []string{dstFS.Join(dstDir, "sub"), dstFS.Join(dstDir, "sub", "file")} {
+			info, err := dstFS.Stat(path)
+			RequireNoError(t, err, "Stat %s", path)
+
+			if !info.ModTime().Equal(mtime) {
+				t.Errorf("Stat %s : want modification time to be preserved %s, got %s", path, mtime, info.ModTime())
+			}
+		}
+
+		info, err := dstFS.Stat(dstFS.Join(dstDir, "sub", "file"))
+		RequireNoError(t, err, "Stat")
+
+		if info.Mode().Perm() != avfs.DefaultFilePerm&^srcFS.UMask() {
+			t.Errorf("Stat : want permissions to be preserved %s, got %s",
+				avfs.DefaultFilePerm&^srcFS.UMask(), info.Mode().Perm())
+		}
+
+		if hasHardlink {
+			fi1, _ := dstFS.Stat(dstFS.Join(dstDir, "sub", "file"))
+			fi2, _ := dstFS.Stat(dstFS.Join(dstDir, "sub", "hardlink"))
+
+			if dstFS.SameFile(fi1, fi2) {
+				t.Errorf("SameFile : want hard links to be copied as distinct files")
+			}
+		}
+
+		if !hasSymlink {
+			return
+		}
+
+		link, err := dstFS.Readlink(dstFS.Join(dstDir, "dirlink"))
+		RequireNoError(t, err, "Readlink")
+
+		if link != "sub" {
+			t.Errorf("Readlink : want link to be %s, got %s", "sub", link)
+		}
+	})
+
+	t.Run("CopyDirPreserveHardlinks", func(t *testing.T) {
+		if !hasHardlink {
+			t.Skip("hard links are not supported")
+		}
+
+		dstFS, dstDir := copyDir(t, &avfs.CopyOptions{PreserveHardlinks: true})
+
+		fi1, _ := dstFS.Stat(dstFS.Join(dstDir, "sub", "file"))
+		fi2, _ := dstFS.Stat(dstFS.Join(dstDir, "sub", "hardlink"))
+
+		if !dstFS.SameFile(fi1, fi2) {
+			t.Errorf("SameFile : want hard links to be preserved")
+		}
+	})
+
+	t.Run("CopyDirIntoItself", func(t *testing.T) {
+		dsts := []string{srcDir, srcFS.Join(subDir, "copy")}
+		if hasSymlink {
+			dsts = append(dsts, srcFS.Join(srcDir, "dirlink", "copy"))
+		}
+
+		for _, dst := range dsts {
+			err := avfs.CopyDir(srcFS, srcFS, dst, srcDir, nil)
+			AssertPathError(t, err).Op("copydir").Path(dst).Err(avfs.ErrInvalidArgument).Test()
+		}
+
+		// A sibling sharing the prefix of the source directory is not inside it.
+		sibling := srcDir + "2"
+
+		err := avfs.CopyDir(srcFS, srcFS, sibling, srcDir, nil)
+		RequireNoError(t, err, "CopyDir %s", sibling)
+
+		err = srcFS.RemoveAll(sibling)
+		RequireNoError(t, err, "RemoveAll %s", sibling)
+	})
+
+	t.Run("CopyDirPreserveOwners", func(t *testing.T) {
+		dstFS, dstDir := copyDir(t, &avfs.CopyOptions{PreserveOwners: true})
+		if dstFS.OSType() == avfs.OsWindows {
+			return
+		}
+
+		srcInfo, err := srcFS.Stat(file)
+		RequireNoError(t, err, "Stat %s", file)
+
+		path := dstFS.Join(dstDir, "sub", "file")
+
+		info, err := dstFS.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+
+		wantSst, sst := srcFS.ToSysStat(srcInfo), dstFS.ToSysStat(info)
+		if sst.Uid() != wantSst.Uid() || sst.Gid() != wantSst.Gid() {
+			t.Errorf("Stat %s : want Uid=%d, Gid=%d, got Uid=%d, Gid=%d",
+				path, wantSst.Uid(), wantSst.Gid(), sst.Uid(), sst.Gid())
+		}
+	})
+
+	t.Run("CopyDirSymlinkFollow", func(t *testing.T) {
+		if !hasSymlink {
+			t.Skip("symbolic links are not supported")
+		}
+
+		dstFS, dstDir := copyDir(t, &avfs.CopyOptions{Symlinks: avfs.SymlinkFollow})
+
+		for _, name := range []string{"dirlink", dstFS.Join("sub", "symlink")} {
+			path := dstFS.Join(dstDir, name)
+
+			info, err := dstFS.Lstat(path)
+			RequireNoError(t, err, "Lstat %s", path)
+
+			if info.Mode()&fs.ModeSymlink != 0 {
+				t.Errorf("Lstat %s : want a copy of the target, got a symbolic link", path)
+			}
+		}
+
+		_, err := dstFS.Stat(dstFS.Join(dstDir, "dirlink", "file"))
+		RequireNoError(t, err, "Stat")
+	})
+
+	t.Run("CopyDirSymlinkSkip", func(t *testing.T) {
+		if !hasSymlink {
+			t.Skip("symbolic links are not supported")
+		}
+
+		dstFS, dstDir := copyDir(t, &avfs.CopyOptions{Symlinks: avfs.SymlinkSkip})
+
+		for _, name := range []string{"dirlink", dstFS.Join("sub", "symlink")} {
+			path := dstFS.Join(dstDir, name)
+
+			_, err := dstFS.Lstat(path)
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Lstat %s : want symbolic link to be skipped, got error %v", path, err)
+			}
+		}
+	})
+
+	t.Run("CopyDirSymlinkLoop", func(t *testing.T) {
+		if !hasSymlink {
+			t.Skip("symbolic links are not supported")
+		}
+
+		loop := srcFS.Join(subDir, "loop")
+
+		err := srcFS.Symlink("..", loop)
+		RequireNoError(t, err, "Symlink %s", loop)
+
+		defer srcFS.Remove(loop) //nolint:errcheck // Ignore errors.
+
+		dstFS := memfs.New()
+
+		err = avfs.CopyDir(dstFS, srcFS, dstFS.Join(dstFS.TempDir(), "loop"), srcDir,
+			&avfs.CopyOptions{Symlinks: avfs.SymlinkFollow})
+		AssertPathError(t, err).Op("copydir").Err(avfs.ErrTooManySymlinks).Test()
+	})
+
+	t.Run("CopyDirNonDir", func(t *testing.T) {
+		dstFS := memfs.New()
+
+		err := avfs.CopyDir(dstFS, srcFS, dstFS.TempDir(), file, nil)
+		AssertPathError(t, err).Op("copydir").Path(file).Err(avfs.ErrNotADirectory).Test()
+	})
+}
+
 // TestCopyFile tests avfs.CopyFile function.
 func (ts *Suite) TestCopyFile(t *testing.T, testDir string) {
-	const copyFile = "CopyFile"
+	srcFS := ts.vfsSetup
+	dstFS := memfs.New()
+
+	rt := avfs.NewRndTree(srcFS, &avfs.RndTreeOpts{NbFiles: 32, MaxFileSize: 100 * 1024})
+
+	err := rt.CreateTree(testDir)
+	RequireNoError(t, err, "CreateTree %s")
+
+	h := sha512.New()
+
+	t.Run("CopyFile_WithHashSum", func(t *testing.T) {
+		dstDir := TempRoot(t, dstFS)
+
+		for _, srcFile := range rt.Files() {
+			srcPath := srcFS.Join(testDir, srcFile.Name)
+			fileName := srcFS.Base(srcFile.Name)
+			dstPath := dstFS.Join(dstDir, fileName)
+
+			wantSum, err := avfs.CopyFileHash(dstFS, srcFS, dstPath, srcPath, h)
+			RequireNoError(t, err, "CopyFile (%s)%s, (%s)%s",
+				dstFS.Type(), dstPath, srcFS.Type(), srcFile.Name)
+
+			gotSum, err := avfs.HashFile(dstFS, dstPath, h)
+			RequireNoError(t, err, "HashFile (%s)%s", dstFS.Type(), dstPath)
+
+			if !bytes.Equal(wantSum, gotSum) {
+				t.Errorf("HashFile %s : \nwant : %x\ngot  : %x", fileName, wantSum, gotSum)
+			}
+		}
+	})
+
+	t.Run("CopyFile", func(t *testing.T) {
+		dstDir := TempRoot(t, dstFS)
+
+		for _, srcFile := range rt.Files() {
+			srcPath := srcFS.Join(testDir, srcFile.Name)
+			fileName := srcFS.Base(srcFile.Name)
+			dstPath := dstFS.Join(dstDir, fileName)
+
+			err = avfs.CopyFile(dstFS, srcFS, dstPath, srcPath)
+			RequireNoError(t, err, "CopyFile (%s)%s, (%s)%s",
+				dstFS.Type(), dstPath, srcFS.Type(), srcFile.Name)
+
+			wantSum, err := avfs.HashFile(srcFS, srcPath, h)
+			RequireNoError(t, err, "HashFile (%s)%s", srcFS.Type(), srcFile.Name)
+
+			gotSum, err := avfs.HashFile(dstFS, dstPath, h)
+			RequireNoError(t, err, "HashFile (%s)%s", dstFS.Type(), dstPath)
+
+			if !bytes.Equal(wantSum, gotSum) {
+				t.Errorf("HashFile %s : \nwant : %x\ngot  : %x", fileName, wantSum, gotSum)
+			}
+
+			srcInfo, err := srcFS.Stat(srcPath)
+			RequireNoError(t, err, "Stat (%s)%s", srcFS.Type(), srcPath)
+
+			info, err := dstFS.Stat(dstPath)
+			RequireNoError(t, err, "Stat (%s)%s", dstFS.Type(), dstPath)
+
+			if !info.ModTime().Equal(srcInfo.ModTime()) || info.Mode() != srcInfo.Mode() {
+				t.Errorf("Stat %s : want modification time and mode to be %s %s, got %s %s",
+					fileName, srcInfo.ModTime(), srcInfo.Mode(), info.ModTime(), info.Mode())
+			}
+		}
+	})
+
+	t.Run("CopyFileAtime", func(t *testing.T) {
+		atimeFS := memfs.NewWithOptions(&memfs.Options{Atime: memfs.AtimeStrict})
+		srcPath := atimeFS.Join(atimeFS.TempDir(), "atime")
+		dstPath := dstFS.Join(TempRoot(t, dstFS), "atime")
+		atime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+		mtime := atime.Add(time.Hour)
+
+		err = atimeFS.WriteFile(srcPath, []byte("atime"), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", srcPath)
+
+		err = atimeFS.Chtimes(srcPath, atime, mtime)
+		RequireNoError(t, err, "Chtimes %s", srcPath)
+
+		err = avfs.CopyFile(dstFS, atimeFS, dstPath, srcPath)
+		RequireNoError(t, err, "CopyFile %s", srcPath)
+
+		info, err := dstFS.Stat(dstPath)
+		RequireNoError(t, err, "Stat %s", dstPath)
+
+		at, ok := dstFS.ToSysStat(info).(avfs.AccessTimer)
+		if !ok {
+			return
+		}
+
+		if !at.Atime().Equal(atime) {
+			t.Errorf("Stat %s : want access time to be %s, got %s", dstPath, atime, at.Atime())
+		}
+	})
+}
+
+// TestCreateAnonymousTemp tests CreateAnonymousTemp function.
+func (ts *Suite) TestCreateAnonymousTemp(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	t.Run("CreateAnonymousTemp", func(t *testing.T) {
+		dir := ts.existingDir(t, testDir)
+		data := []byte("anonymous")
+
+		f, err := avfs.CreateAnonymousTemp(vfs, dir)
+		RequireNoError(t, err, "CreateAnonymousTemp %s", dir)
+
+		_, err = f.Write(data)
+		RequireNoError(t, err, "Write")
+
+		buf := make([]byte, len(data))
+
+		_, err = f.ReadAt(buf, 0)
+		RequireNoError(t, err, "ReadAt")
+
+		if !bytes.Equal(buf, data) {
+			t.Errorf("ReadAt : want data to be %s, got %s", data, buf)
+		}
+
+		err = f.Close()
+		RequireNoError(t, err, "Close")
+
+		entries, err := vfs.ReadDir(dir)
+		RequireNoError(t, err, "ReadDir %s", dir)
+
+		if len(entries) != 0 {
+			t.Errorf("ReadDir %s : want no entries, got %d", dir, len(entries))
+		}
+	})
+
+	t.Run("CreateAnonymousTempTmpFile", func(t *testing.T) {
+		if vfs.OSType() != avfs.OsLinux {
+			return
+		}
+
+		f, err := vfs.OpenFile(testDir, os.O_RDWR|avfs.O_TMPFILE, 0o600)
+		if errors.Is(err, avfs.ErrIsADirectory) || errors.Is(err, avfs.ErrNotSupported) ||
+			errors.Is(err, avfs.ErrInvalidArgument) {
+			// O_TMPFILE is not supported, CreateAnonymousTemp falls back to a named file.
+			return
+		}
+
+		RequireNoError(t, err, "OpenFile %s", testDir)
+
+		err = f.Close()
+		RequireNoError(t, err, "Close")
+
+		_, err = vfs.OpenFile(testDir, os.O_RDONLY|avfs.O_TMPFILE, 0)
+		AssertPathError(t, err).Op("open").Path(testDir).Err(avfs.ErrInvalidArgument).Test()
+
+		existingFile := ts.emptyFile(t, testDir)
+
+		_, err = vfs.OpenFile(existingFile, os.O_RDWR|avfs.O_TMPFILE, 0o600)
+		AssertPathError(t, err).Op("open").Path(existingFile).Err(avfs.ErrNotADirectory).Test()
+	})
+}
+
+// TestDiff tests avfs.Diff function and Patch.Apply method.
+func (ts *Suite) TestDiff(t *testing.T, testDir string) {
+	setupFS := ts.vfsSetup
+	vfs := ts.vfsTest
+	oldDir := setupFS.Join(testDir, "old")
+	newDir := setupFS.Join(testDir, "new")
+	hasSymlink := setupFS.HasFeature(avfs.FeatSymlink)
+	canChmod := setupFS.OSType() != avfs.OsWindows
+
+	writeFile := func(dir, name, content string) {
+		path := setupFS.Join(dir, setupFS.FromSlash(name))
+
+		err := setupFS.WriteFile(path, []byte(content), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+	}
+
+	for _, dir := range []string{oldDir, newDir} {
+		for _, name := range []string{"a/b", "d", "h/sub"} {
+			ts.createDir(t, setupFS.Join(dir, setupFS.FromSlash(name)), avfs.DefaultDirPerm)
+		}
+
+		for _, name := range []string{"c", "d/x", "e", "f", "g", "h/sub/file"} {
+			writeFile(dir, name, name)
+		}
+
+		if hasSymlink {
+			err := setupFS.Symlink("c", setupFS.Join(dir, "s"))
+			RequireNoError(t, err, "Symlink")
+		}
+	}
+
+	wantEntries := []string{"delete d", "delete e", "delete h", "add a/b/added", "add d", "add e", "add e/child",
+		"modify f", "modify g", "add n", "add n/m"}
+
+	writeFile(newDir, "a/b/added", "added")
+
+	err := setupFS.RemoveAll(setupFS.Join(newDir, "d"))
+	RequireNoError(t, err, "RemoveAll")
+
+	writeFile(newDir, "d", "d is a file")
+
+	err = setupFS.Remove(setupFS.Join(newDir, "e"))
+	RequireNoError(t, err, "Remove")
+
+	ts.createDir(t, setupFS.Join(newDir, "e"), avfs.DefaultDirPerm)
+	writeFile(newDir, "e/child", "child")
+	writeFile(newDir, "f", "new content")
+
+	if canChmod {
+		err = setupFS.Chmod(setupFS.Join(newDir, "g"), 0o600)
+		RequireNoError(t, err, "Chmod")
+	} else {
+		writeFile(newDir, "g", "G")
+	}
+
+	err = setupFS.RemoveAll(setupFS.Join(newDir, "h"))
+	RequireNoError(t, err, "RemoveAll")
+
+	ts.createDir(t, setupFS.Join(newDir, "n"), avfs.DefaultDirPerm)
+	writeFile(newDir, "n/m", "m")
+
+	if hasSymlink {
+		err = setupFS.Remove(setupFS.Join(newDir, "s"))
+		RequireNoError(t, err, "Remove")
+
+		err = setupFS.Symlink("f", setupFS.Join(newDir, "s"))
+		RequireNoError(t, err, "Symlink")
+
+		wantEntries = append(wantEntries, "modify s")
+	}
+
+	patch, err := avfs.Diff(vfs, oldDir, vfs, newDir)
+	RequireNoError(t, err, "Diff")
+
+	gotEntries := make([]string, len(patch.Entries))
+	for i, entry := range patch.Entries {
+		gotEntries[i] = string(entry.Op) + " " + entry.Path
+	}
+
+	if !slices.Equal(gotEntries, wantEntries) {
+		t.Errorf("Diff : want entries to be\n%v\ngot\n%v", wantEntries, gotEntries)
+	}
+
+	t.Run("Apply", func(t *testing.T) {
+		buf, err := json.Marshal(patch)
+		RequireNoError(t, err, "Marshal")
+
+		var jsonPatch avfs.Patch
+
+		err = json.Unmarshal(buf, &jsonPatch)
+		RequireNoError(t, err, "Unmarshal")
+
+		dstFS := memfs.New()
+		dstDir := TempRoot(t, dstFS)
+
+		err = avfs.CopyDir(dstFS, vfs, dstDir, oldDir, nil)
+		RequireNoError(t, err, "CopyDir %s", oldDir)
+
+		err = jsonPatch.Apply(dstFS, dstDir)
+		RequireNoError(t, err, "Apply")
+
+		patch, err := avfs.Diff(dstFS, dstDir, vfs, newDir)
+		RequireNoError(t, err, "Diff")
+
+		if len(patch.Entries) != 0 {
+			t.Errorf("Diff : want no difference after Apply, got %v", patch.Entries)
+		}
+	})
+
+	t.Run("SameSizeModTime", func(t *testing.T) {
+		oldFile, newFile := setupFS.Join(oldDir, "c"), setupFS.Join(newDir, "c")
+
+		info, err := setupFS.Stat(oldFile)
+		RequireNoError(t, err, "Stat %s", oldFile)
+
+		writeFile(newDir, "c", "C")
 
-	srcFS := ts.vfsSetup
-	dstFS := memfs.New()
+		err = setupFS.Chtimes(newFile, info.ModTime(), info.ModTime())
+		RequireNoError(t, err, "Chtimes %s", newFile)
 
-	rt := avfs.NewRndTree(srcFS, &avfs.RndTreeOpts{NbFiles: 32, MaxFileSize: 100 * 1024})
+		patch, err := avfs.Diff(vfs, oldDir, vfs, newDir)
+		RequireNoError(t, err, "Diff")
 
-	err := rt.CreateTree(testDir)
-	RequireNoError(t, err, "CreateTree %s")
+		// Like rsync, a file with the same size and modification time is considered unchanged without reading it.
+		for _, entry := range patch.Entries {
+			if entry.Path == "c" {
+				t.Errorf("Diff : want %s to be considered unchanged, got %s", newFile, entry.Op)
+			}
+		}
+	})
+}
 
-	h := sha512.New()
+// TestDiffFS tests avfs.DiffFS function.
+func (ts *Suite) TestDiffFS(t *testing.T, testDir string) {
+	setupFS := ts.vfsSetup
+	vfs := ts.vfsTest
+	hasSymlink := setupFS.HasFeature(avfs.FeatSymlink)
 
-	t.Run("CopyFile_WithHashSum", func(t *testing.T) {
-		dstDir, err := dstFS.MkdirTemp("", copyFile)
-		RequireNoError(t, err, "MkdirTemp")
+	ts.createDir(t, setupFS.Join(testDir, "a"), avfs.DefaultDirPerm)
+	ts.createDir(t, setupFS.Join(testDir, "d"), avfs.DefaultDirPerm)
 
-		defer dstFS.RemoveAll(dstDir) //nolint:errcheck // Ignore errors.
+	for _, name := range []string{"a/f1", "c", "d/x", "g"} {
+		path := setupFS.Join(testDir, setupFS.FromSlash(name))
 
-		for _, srcFile := range rt.Files() {
-			srcPath := srcFS.Join(testDir, srcFile.Name)
-			fileName := srcFS.Base(srcFile.Name)
-			dstPath := dstFS.Join(dstDir, fileName)
+		err := setupFS.WriteFile(path, []byte(name), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+	}
 
-			wantSum, err := avfs.CopyFileHash(dstFS, srcFS, dstPath, srcPath, h)
-			RequireNoError(t, err, "CopyFile (%s)%s, (%s)%s",
-				dstFS.Type(), dstPath, srcFS.Type(), srcFile.Name)
+	if hasSymlink {
+		err := setupFS.Symlink("c", setupFS.Join(testDir, "s"))
+		RequireNoError(t, err, "Symlink")
+	}
 
-			gotSum, err := avfs.HashFile(dstFS, dstPath, h)
-			RequireNoError(t, err, "HashFile (%s)%s", dstFS.Type(), dstPath)
+	dstFS := memfs.New()
+	dstDir := TempRoot(t, dstFS)
 
-			if !bytes.Equal(wantSum, gotSum) {
-				t.Errorf("HashFile %s : \nwant : %x\ngot  : %x", fileName, wantSum, gotSum)
-			}
+	err := avfs.CopyDir(dstFS, vfs, dstDir, testDir, &avfs.CopyOptions{PreserveOwners: true})
+	RequireNoError(t, err, "CopyDir %s", testDir)
+
+	opts := &avfs.DiffFSOptions{ModTime: true, Owners: dstFS.OSType() != avfs.OsWindows}
+
+	t.Run("DiffFSSame", func(t *testing.T) {
+		diffs, err := avfs.DiffFS(vfs, testDir, dstFS, dstDir, opts)
+		RequireNoError(t, err, "DiffFS")
+
+		if len(diffs) != 0 {
+			t.Errorf("DiffFS : want no difference, got %v", diffs)
 		}
 	})
 
-	t.Run("CopyFile", func(t *testing.T) {
-		dstDir, err := dstFS.MkdirTemp("", copyFile)
-		RequireNoError(t, err, "MkdirTemp %s", copyFile)
+	t.Run("DiffFSChanges", func(t *testing.T) {
+		err := dstFS.Remove(dstFS.Join(dstDir, "a", "f1"))
+		RequireNoError(t, err, "Remove")
 
-		for _, srcFile := range rt.Files() {
-			srcPath := srcFS.Join(testDir, srcFile.Name)
-			fileName := srcFS.Base(srcFile.Name)
-			dstPath := dstFS.Join(dstDir, fileName)
+		mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
 
-			err = avfs.CopyFile(dstFS, srcFS, dstPath, srcPath)
-			RequireNoError(t, err, "CopyFile (%s)%s, (%s)%s",
-				dstFS.Type(), dstPath, srcFS.Type(), srcFile.Name)
+		err = dstFS.Chtimes(dstFS.Join(dstDir, "a"), mtime, mtime)
+		RequireNoError(t, err, "Chtimes")
 
-			wantSum, err := avfs.HashFile(srcFS, srcPath, h)
-			RequireNoError(t, err, "HashFile (%s)%s", srcFS.Type(), srcFile.Name)
+		info, err := vfs.Stat(vfs.Join(testDir, "c"))
+		RequireNoError(t, err, "Stat")
 
-			gotSum, err := avfs.HashFile(dstFS, dstPath, h)
-			RequireNoError(t, err, "HashFile (%s)%s", dstFS.Type(), dstPath)
+		// Only the content of c differs, its modification time is restored.
+		err = dstFS.WriteFile(dstFS.Join(dstDir, "c"), []byte("new content"), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile")
 
-			if !bytes.Equal(wantSum, gotSum) {
-				t.Errorf("HashFile %s : \nwant : %x\ngot  : %x", fileName, wantSum, gotSum)
+		err = dstFS.Chtimes(dstFS.Join(dstDir, "c"), info.ModTime(), info.ModTime())
+		RequireNoError(t, err, "Chtimes")
+
+		err = dstFS.RemoveAll(dstFS.Join(dstDir, "d"))
+		RequireNoError(t, err, "RemoveAll")
+
+		err = dstFS.WriteFile(dstFS.Join(dstDir, "d"), nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile")
+
+		err = dstFS.Mkdir(dstFS.Join(dstDir, "n"), avfs.DefaultDirPerm)
+		RequireNoError(t, err, "Mkdir")
+
+		wantDiffs := []string{"modtime a", "missing a/f1", "content c", "type d"}
+
+		if dstFS.OSType() != avfs.OsWindows {
+			err = dstFS.Chmod(dstFS.Join(dstDir, "g"), 0o600)
+			RequireNoError(t, err, "Chmod")
+
+			wantDiffs = append(wantDiffs, "mode g")
+		}
+
+		wantDiffs = append(wantDiffs, "extra n")
+
+		if hasSymlink {
+			err = dstFS.Remove(dstFS.Join(dstDir, "s"))
+			RequireNoError(t, err, "Remove")
+
+			err = dstFS.Symlink("g", dstFS.Join(dstDir, "s"))
+			RequireNoError(t, err, "Symlink")
+
+			if opts.Owners {
+				info, err := vfs.Lstat(vfs.Join(testDir, "s"))
+				RequireNoError(t, err, "Lstat")
+
+				sst := vfs.ToSysStat(info)
+
+				err = dstFS.Lchown(dstFS.Join(dstDir, "s"), sst.Uid(), sst.Gid())
+				RequireNoError(t, err, "Lchown")
 			}
+
+			wantDiffs = append(wantDiffs, "link s")
+		}
+
+		diffs, err := avfs.DiffFS(vfs, testDir, dstFS, dstDir, opts)
+		RequireNoError(t, err, "DiffFS")
+
+		gotDiffs := make([]string, len(diffs))
+		for i, diff := range diffs {
+			gotDiffs[i] = string(diff.Kind) + " " + diff.Path
+		}
+
+		if !slices.Equal(gotDiffs, wantDiffs) {
+			t.Errorf("DiffFS : want differences to be\n%v\ngot\n%v", wantDiffs, diffs)
 		}
 	})
 }
@@ -549,6 +1331,94 @@ func (ts *Suite) TestDirExists(t *testing.T, testDir string) {
 	})
 }
 
+// TestEvictLRU tests EvictLRU function.
+func (ts *Suite) TestEvictLRU(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	// createCache creates 4 files of 10 bytes in dir, the first created being the least recently used.
+	createCache := func(t *testing.T, dir string) []string {
+		ts.createDir(t, vfs.Join(dir, "sub"), avfs.DefaultDirPerm)
+
+		paths := []string{vfs.Join(dir, "sub", "c"), vfs.Join(dir, "b"), vfs.Join(dir, "d"), vfs.Join(dir, "a")}
+		at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		for i, path := range paths {
+			err := vfs.WriteFile(path, []byte("0123456789"), avfs.DefaultFilePerm)
+			RequireNoError(t, err, "WriteFile %s", path)
+
+			tm := at.Add(time.Duration(i) * time.Hour)
+
+			err = vfs.Chtimes(path, tm, tm)
+			RequireNoError(t, err, "Chtimes %s", path)
+		}
+
+		return paths
+	}
+
+	t.Run("EvictLRU", func(t *testing.T) {
+		dir := vfs.Join(testDir, "EvictLRU")
+		paths := createCache(t, dir)
+
+		evicted, err := avfs.EvictLRU(vfs, dir, 25, nil)
+		RequireNoError(t, err, "EvictLRU %s", dir)
+
+		if !slices.Equal(evicted, paths[:2]) {
+			t.Errorf("EvictLRU %s : want evicted to be %v, got %v", dir, paths[:2], evicted)
+		}
+
+		for i, path := range paths {
+			_, err = vfs.Stat(path)
+			if exists := err == nil; exists != (i >= 2) {
+				t.Errorf("Stat %s : want file to exist = %t, got error %v", path, i >= 2, err)
+			}
+		}
+	})
+
+	t.Run("EvictLRUUnderBudget", func(t *testing.T) {
+		dir := vfs.Join(testDir, "EvictLRUUnderBudget")
+		_ = createCache(t, dir)
+
+		evicted, err := avfs.EvictLRU(vfs, dir, 40, nil)
+		RequireNoError(t, err, "EvictLRU %s", dir)
+
+		if len(evicted) != 0 {
+			t.Errorf("EvictLRU %s : want no file evicted, got %v", dir, evicted)
+		}
+	})
+
+	t.Run("EvictLRUSidecarIndex", func(t *testing.T) {
+		dir := vfs.Join(testDir, "EvictLRUSidecarIndex")
+		paths := createCache(t, dir)
+
+		// The index reverses the order of the access times, the ties are evicted in lexical order.
+		index := map[string]int{paths[0]: 3, paths[1]: 2, paths[2]: 0, paths[3]: 0}
+		opts := &avfs.EvictOptions{AccessTime: func(path string, _ fs.FileInfo) time.Time {
+			return time.Unix(int64(index[path]), 0)
+		}}
+
+		evicted, err := avfs.EvictLRU(vfs, dir, 10, opts)
+		RequireNoError(t, err, "EvictLRU %s", dir)
+
+		want := []string{paths[3], paths[2], paths[1]}
+		if !slices.Equal(evicted, want) {
+			t.Errorf("EvictLRU %s : want evicted to be %v, got %v", dir, want, evicted)
+		}
+	})
+
+	t.Run("EvictLRUNonExisting", func(t *testing.T) {
+		dir := vfs.Join(testDir, "nonExisting")
+
+		_, err := avfs.EvictLRU(vfs, dir, 0, nil)
+		AssertPathError(t, err).OpLstat().Path(dir).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
 // TestExists tests avfs.Exists function.
 func (ts *Suite) TestExists(t *testing.T, testDir string) {
 	vfs := ts.vfsTest
@@ -678,6 +1548,106 @@ func (ts *Suite) TestGlob(t *testing.T, testDir string) {
 		}
 	})
 
+	t.Run("GlobOrder", func(t *testing.T) {
+		orderDir := vfs.Join(testDir, "order")
+
+		for _, name := range []string{"a-b", "c", "a", "B", "a0"} {
+			path := vfs.Join(orderDir, name)
+
+			if name == "c" || name == "a0" {
+				ts.createFile(t, path, avfs.DefaultFilePerm)
+
+				continue
+			}
+
+			ts.createDir(t, path, avfs.DefaultDirPerm)
+			ts.createFile(t, vfs.Join(path, "x"), avfs.DefaultFilePerm)
+		}
+
+		tests := []struct {
+			pattern string
+			want    []string
+		}{
+			{pattern: "*", want: []string{"B", "a", "a-b", "a0", "c"}},
+			{pattern: "*/x", want: []string{"B/x", "a/x", "a-b/x"}},
+		}
+
+		for _, tt := range tests {
+			pattern := vfs.Join(orderDir, tt.pattern)
+
+			want := make([]string, len(tt.want))
+			for i, name := range tt.want {
+				want[i] = vfs.Join(orderDir, name)
+			}
+
+			matches, err := vfs.Glob(pattern)
+			RequireNoError(t, err, "Glob %s", pattern)
+
+			if !slices.Equal(matches, want) {
+				t.Errorf("Glob %s : want matches to be %v, got %v", pattern, want, matches)
+			}
+
+			matches, err = avfs.GlobWithOptions(vfs, pattern, &avfs.GlobOptions{Unsorted: true})
+			RequireNoError(t, err, "GlobWithOptions %s", pattern)
+
+			slices.Sort(want)
+			slices.Sort(matches)
+
+			if !slices.Equal(matches, want) {
+				t.Errorf("GlobWithOptions %s : want unsorted matches to be %v, got %v", pattern, want, matches)
+			}
+		}
+	})
+
+	t.Run("GlobRecursiveBraces", func(t *testing.T) {
+		recDir := vfs.Join(testDir, "recursive")
+
+		ts.createDir(t, vfs.Join(recDir, "a", "b", "c"), avfs.DefaultDirPerm)
+
+		for _, name := range []string{"a/x.go", "a/b/x.go", "a/b/c/y.md", "z.go"} {
+			ts.createFile(t, vfs.Join(recDir, vfs.FromSlash(name)), avfs.DefaultFilePerm)
+		}
+
+		recursive := &avfs.GlobOptions{Recursive: true}
+		braces := &avfs.GlobOptions{Braces: true}
+
+		tests := []struct {
+			opts    *avfs.GlobOptions
+			pattern string
+			want    []string
+		}{
+			{opts: recursive, pattern: "**/*.go", want: []string{"a/b/x.go", "a/x.go", "z.go"}},
+			{opts: recursive, pattern: "a/**", want: []string{"a", "a/b", "a/b/c", "a/b/c/y.md", "a/b/x.go", "a/x.go"}},
+			{opts: recursive, pattern: "**/c/*", want: []string{"a/b/c/y.md"}},
+			{opts: recursive, pattern: "**/**/x.go", want: []string{"a/b/x.go", "a/x.go"}},
+			{opts: nil, pattern: "**/*.go", want: []string{"a/x.go"}},
+			{opts: braces, pattern: "*.{go,md}", want: []string{"z.go"}},
+			{opts: braces, pattern: "{a/b,a}/*.{go,md}", want: []string{"a/b/x.go", "a/x.go"}},
+			{opts: braces, pattern: "{a,a/b}/{x,{y,z}}.go", want: []string{"a/b/x.go", "a/x.go"}},
+			{opts: braces, pattern: "{z}.go", want: nil},
+			{
+				opts: &avfs.GlobOptions{Recursive: true, Braces: true}, pattern: "**/*.{go,md}",
+				want: []string{"a/b/c/y.md", "a/b/x.go", "a/x.go", "z.go"},
+			},
+		}
+
+		for _, tt := range tests {
+			pattern := vfs.Join(recDir, vfs.FromSlash(tt.pattern))
+
+			var want []string
+			for _, name := range tt.want {
+				want = append(want, vfs.Join(recDir, vfs.FromSlash(name)))
+			}
+
+			matches, err := avfs.GlobWithOptions(vfs, pattern, tt.opts)
+			RequireNoError(t, err, "GlobWithOptions %s", pattern)
+
+			if !slices.Equal(matches, want) {
+				t.Errorf("GlobWithOptions %s : want matches to be %v, got %v", pattern, want, matches)
+			}
+		}
+	})
+
 	t.Run("GlobError", func(t *testing.T) {
 		patterns := []string{
 			"[]",
@@ -693,6 +1663,193 @@ func (ts *Suite) TestGlob(t *testing.T, testDir string) {
 	})
 }
 
+// createHardlinks creates under testDir the files a, d, e and sub/c linked to a, b linked to a and f linked to e.
+// It returns the expected hardlink groups.
+func (ts *Suite) createHardlinks(t *testing.T, testDir string) [][]string {
+	vfs := ts.vfsSetup
+
+	a := vfs.Join(testDir, "a")
+	b := vfs.Join(testDir, "b")
+	c := vfs.Join(testDir, "sub", "c")
+	d := vfs.Join(testDir, "d")
+	e := vfs.Join(testDir, "e")
+	f := vfs.Join(testDir, "f")
+
+	ts.createDir(t, vfs.Dir(c), avfs.DefaultDirPerm)
+
+	for name, data := range map[string]string{a: "aaa", d: "ddddd", e: "eeeeeee"} {
+		err := vfs.WriteFile(name, []byte(data), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", name)
+	}
+
+	for _, link := range [][2]string{{a, b}, {a, c}, {e, f}} {
+		err := vfs.Link(link[0], link[1])
+		RequireNoError(t, err, "Link %s %s", link[0], link[1])
+	}
+
+	return [][]string{{a, b, c}, {e, f}}
+}
+
+// TestFindHardlinkGroups tests FindHardlinkGroups function.
+func (ts *Suite) TestFindHardlinkGroups(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if !vfs.HasFeature(avfs.FeatHardlink) {
+		return
+	}
+
+	wantGroups := ts.createHardlinks(t, testDir)
+
+	groups, err := avfs.FindHardlinkGroups(vfs, testDir)
+	RequireNoError(t, err, "FindHardlinkGroups %s", testDir)
+
+	info, err := vfs.Stat(wantGroups[0][0])
+	RequireNoError(t, err, "Stat %s", wantGroups[0][0])
+
+	if vfs.ToSysStat(info).Nlink() == 1 {
+		// The file system doesn't report the number of links.
+		if len(groups) != 0 {
+			t.Errorf("FindHardlinkGroups %s : want no groups, got %v", testDir, groups)
+		}
+
+		return
+	}
+
+	if !slices.EqualFunc(groups, wantGroups, slices.Equal) {
+		t.Errorf("FindHardlinkGroups %s : want groups to be %v, got %v", testDir, wantGroups, groups)
+	}
+
+	t.Run("FindHardlinkGroupsNonExisting", func(t *testing.T) {
+		path := vfs.Join(testDir, "nonExisting")
+
+		_, err := avfs.FindHardlinkGroups(vfs, path)
+		AssertPathError(t, err).OpLstat().Path(path).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
+// TestFollow tests avfs.Follow function.
+func (ts *Suite) TestFollow(t *testing.T, testDir string) {
+	const timeout = 5 * time.Second
+
+	vfsSetup := ts.vfsSetup
+	vfs := ts.vfsTest
+
+	readString := func(t *testing.T, r io.Reader, want string) {
+		t.Helper()
+
+		got := make([]byte, len(want))
+
+		_, err := io.ReadFull(r, got)
+		RequireNoError(t, err, "ReadFull")
+
+		if string(got) != want {
+			t.Errorf("Read : want content to be %q, got %q", want, got)
+		}
+	}
+
+	appendString := func(t *testing.T, path, s string) {
+		t.Helper()
+
+		// appendString is also called from goroutines, errors must not stop the test.
+		f, err := vfsSetup.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, avfs.DefaultFilePerm)
+		if !AssertNoError(t, err, "OpenFile %s", path) {
+			return
+		}
+
+		_, err = f.WriteString(s)
+		AssertNoError(t, err, "WriteString %s", path)
+
+		err = f.Close()
+		AssertNoError(t, err, "Close %s", path)
+	}
+
+	t.Run("Follow", func(t *testing.T) {
+		path := vfs.Join(testDir, "Follow.log")
+		appendString(t, path, "first")
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		r, err := avfs.Follow(ctx, vfs, path)
+		RequireNoError(t, err, "Follow %s", path)
+
+		defer r.Close()
+
+		readString(t, r, "first")
+
+		go func() {
+			time.Sleep(avfs.FollowPollInterval / 2)
+			appendString(t, path, "second")
+		}()
+
+		readString(t, r, "second")
+
+		err = vfsSetup.Truncate(path, 0)
+		RequireNoError(t, err, "Truncate %s", path)
+
+		appendString(t, path, "third")
+		readString(t, r, "third")
+
+		rotatedPath := path + ".1"
+
+		err = vfsSetup.Rename(path, rotatedPath)
+		RequireNoError(t, err, "Rename %s", path)
+
+		appendString(t, path, "fourth")
+		readString(t, r, "fourth")
+	})
+
+	t.Run("FollowNonExisting", func(t *testing.T) {
+		path := vfs.Join(testDir, "FollowNonExisting.log")
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		r, err := avfs.Follow(ctx, vfs, path)
+		RequireNoError(t, err, "Follow %s", path)
+
+		defer r.Close()
+
+		appendString(t, path, "created")
+		readString(t, r, "created")
+	})
+
+	t.Run("FollowCanceled", func(t *testing.T) {
+		path := ts.emptyFile(t, testDir)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		r, err := avfs.Follow(ctx, vfs, path)
+		RequireNoError(t, err, "Follow %s", path)
+
+		defer r.Close()
+
+		cancel()
+
+		_, err = r.Read(make([]byte, 1))
+		if err != context.Canceled {
+			t.Errorf("Read : want error to be %v, got %v", context.Canceled, err)
+		}
+	})
+
+	t.Run("FollowClosed", func(t *testing.T) {
+		path := ts.emptyFile(t, testDir)
+
+		r, err := avfs.Follow(context.Background(), vfs, path)
+		RequireNoError(t, err, "Follow %s", path)
+
+		go func() {
+			time.Sleep(avfs.FollowPollInterval / 2)
+			r.Close()
+		}()
+
+		_, err = r.Read(make([]byte, 1))
+		AssertPathError(t, err).Op("follow").Path(path).Err(fs.ErrClosed).Test()
+	})
+}
+
 // TestHashFile tests avfs.HashFile function.
 func (ts *Suite) TestHashFile(t *testing.T, testDir string) {
 	vfs := ts.vfsSetup
@@ -729,6 +1886,85 @@ func (ts *Suite) TestHashFile(t *testing.T, testDir string) {
 	}
 }
 
+// TestHashTree tests avfs.HashTree function.
+func (ts *Suite) TestHashTree(t *testing.T, testDir string) {
+	setupFS := ts.vfsSetup
+	vfs := ts.vfsTest
+	rt := avfs.NewRndTree(setupFS, &avfs.RndTreeOpts{NbDirs: 10, NbFiles: 50, NbSymlinks: 10, MaxFileSize: 10 * 1024, MaxDepth: 3})
+
+	err := rt.CreateTree(testDir)
+	RequireNoError(t, err, "CreateTree %s", testDir)
+
+	th, err := avfs.HashTree(vfs, testDir, sha256.New, nil)
+	RequireNoError(t, err, "HashTree %s", testDir)
+
+	t.Run("HashTreeFiles", func(t *testing.T) {
+		wantNbFiles := len(rt.Files())
+		if vfs.HasFeature(avfs.FeatSymlink) {
+			wantNbFiles += len(rt.SymLinks())
+		}
+
+		if len(th.Files) != wantNbFiles {
+			t.Errorf("HashTree : want number of files to be %d, got %d", wantNbFiles, len(th.Files))
+		}
+
+		for _, file := range rt.Files() {
+			path := vfs.Join(testDir, vfs.FromSlash(file.Name))
+
+			content, err := vfs.ReadFile(path)
+			RequireNoError(t, err, "ReadFile %s", path)
+
+			wantSum := sha256.Sum256(content)
+			if gotSum := th.Files[strings.TrimPrefix(file.Name, "/")]; !bytes.Equal(wantSum[:], gotSum) {
+				t.Errorf("HashTree %s : \nwant : %x\ngot  : %x", file.Name, wantSum, gotSum)
+			}
+		}
+	})
+
+	t.Run("HashTreeWorkers", func(t *testing.T) {
+		th1, err := avfs.HashTree(vfs, testDir, sha256.New, &avfs.HashTreeOptions{Workers: 1})
+		RequireNoError(t, err, "HashTree %s", testDir)
+
+		if !bytes.Equal(th1.Sum, th.Sum) {
+			t.Errorf("HashTree : want the same sum with one worker %x, got %x", th.Sum, th1.Sum)
+		}
+	})
+
+	t.Run("HashTreeCopy", func(t *testing.T) {
+		dstFS := memfs.New()
+		dstDir := TempRoot(t, dstFS)
+
+		err := avfs.CopyDir(dstFS, vfs, dstDir, testDir, nil)
+		RequireNoError(t, err, "CopyDir %s", testDir)
+
+		dstTh, err := avfs.HashTree(dstFS, dstDir, sha256.New, nil)
+		RequireNoError(t, err, "HashTree %s", dstDir)
+
+		if !bytes.Equal(dstTh.Sum, th.Sum) {
+			t.Errorf("HashTree : want the sum of the copy to be %x, got %x", th.Sum, dstTh.Sum)
+		}
+
+		err = dstFS.Mkdir(dstFS.Join(dstDir, "emptyDir"), avfs.DefaultDirPerm)
+		RequireNoError(t, err, "Mkdir")
+
+		dstTh, err = avfs.HashTree(dstFS, dstDir, sha256.New, nil)
+		RequireNoError(t, err, "HashTree %s", dstDir)
+
+		if bytes.Equal(dstTh.Sum, th.Sum) {
+			t.Errorf("HashTree : want the sum to change when a directory is added, got %x", dstTh.Sum)
+		}
+	})
+
+	t.Run("HashTreeNonExisting", func(t *testing.T) {
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+
+		_, err := avfs.HashTree(vfs, nonExistingFile, sha256.New, nil)
+		AssertPathError(t, err).OpLstat().Path(nonExistingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
 // TestIsAbs tests IsAbs function.
 func (ts *Suite) TestIsAbs(t *testing.T, _ string) {
 	vfs := ts.vfsTest
@@ -1074,6 +2310,243 @@ func (ts *Suite) TestMatch(t *testing.T, _ string) {
 	}
 }
 
+// TestMkdirAllContext tests avfs.MkdirAllContext function.
+func (ts *Suite) TestMkdirAllContext(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	ctx := context.Background()
+	dirX := vfs.Join(testDir, "x")
+	dirY := vfs.Join(dirX, "y")
+	path := vfs.Join(dirY, "z")
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		err := avfs.MkdirAllContext(ctx, vfs, path, avfs.DefaultDirPerm)
+		AssertPathError(t, err).Op("mkdir").Path(dirX).ErrPermDenied().Test()
+
+		return
+	}
+
+	t.Run("MkdirAllContextCanceled", func(t *testing.T) {
+		err := avfs.MkdirAllContext(&cancelAfterCtx{Context: ctx, n: 1}, vfs, path, avfs.DefaultDirPerm)
+		AssertPathError(t, err).Op("mkdirall").Path(dirY).Err(context.Canceled).Test()
+
+		if ok, err := avfs.DirExists(vfs, dirX); !ok {
+			t.Errorf("DirExists %s : want directory to exist, got %v", dirX, err)
+		}
+
+		_, err = vfs.Stat(dirY)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat %s : want error to be %v, got %v", dirY, fs.ErrNotExist, err)
+		}
+	})
+
+	t.Run("MkdirAllContext", func(t *testing.T) {
+		for range 2 {
+			err := avfs.MkdirAllContext(ctx, vfs, path, avfs.DefaultDirPerm)
+			RequireNoError(t, err, "MkdirAllContext %s", path)
+		}
+
+		if ok, err := avfs.DirExists(vfs, path); !ok {
+			t.Errorf("DirExists %s : want directory to exist, got %v", path, err)
+		}
+	})
+
+	t.Run("MkdirAllContextOnFile", func(t *testing.T) {
+		file := ts.emptyFile(t, testDir)
+
+		err := avfs.MkdirAllContext(ctx, vfs, vfs.Join(file, "dir"), avfs.DefaultDirPerm)
+		if err == nil {
+			t.Errorf("MkdirAllContext : want error on an existing file, got nil")
+		}
+	})
+}
+
+// TestMove tests avfs.Move function.
+func (ts *Suite) TestMove(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	content := []byte("Move")
+
+	// createSrc creates a source directory containing a file.
+	createSrc := func(t *testing.T, name string) string {
+		t.Helper()
+
+		srcDir := vfs.Join(testDir, name)
+		ts.createDir(t, srcDir, avfs.DefaultDirPerm)
+
+		err := vfs.WriteFile(vfs.Join(srcDir, "file"), content, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", srcDir)
+
+		return srcDir
+	}
+
+	// checkMoved checks that srcDir was moved to dstDir of dstFS.
+	checkMoved := func(t *testing.T, dstFS avfs.VFSBase, dstDir, srcDir string) {
+		t.Helper()
+
+		_, err := vfs.Lstat(srcDir)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Lstat %s : want source to be removed, got %v", srcDir, err)
+		}
+
+		path := dstFS.Join(dstDir, "file")
+
+		gotContent, err := dstFS.ReadFile(path)
+		RequireNoError(t, err, "ReadFile %s", path)
+
+		if !bytes.Equal(gotContent, content) {
+			t.Errorf("ReadFile %s : want content to be %q, got %q", path, content, gotContent)
+		}
+	}
+
+	t.Run("MoveSameFS", func(t *testing.T) {
+		srcDir := createSrc(t, "moveSrc")
+		dstDir := vfs.Join(testDir, "moveDst")
+
+		err := avfs.Move(vfs, vfs, dstDir, srcDir)
+		RequireNoError(t, err, "Move %s %s", srcDir, dstDir)
+
+		checkMoved(t, vfs, dstDir, srcDir)
+	})
+
+	t.Run("MoveIntoDir", func(t *testing.T) {
+		srcDir := createSrc(t, "moveIntoSrc")
+		dstDir := ts.existingDir(t, testDir)
+
+		err := avfs.Move(vfs, vfs, dstDir, srcDir)
+		RequireNoError(t, err, "Move %s %s", srcDir, dstDir)
+
+		checkMoved(t, vfs, vfs.Join(dstDir, "moveIntoSrc"), srcDir)
+	})
+
+	t.Run("MoveCrossFS", func(t *testing.T) {
+		srcDir := createSrc(t, "moveCrossSrc")
+		dstFS := memfs.New()
+		dstDir := dstFS.Join(TempRoot(t, dstFS), "moveDst")
+
+		err := avfs.Move(dstFS, vfs, dstDir, srcDir)
+		RequireNoError(t, err, "Move %s %s", srcDir, dstDir)
+
+		checkMoved(t, dstFS, dstDir, srcDir)
+	})
+
+	t.Run("MoveSameFile", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, content)
+
+		paths := []string{srcFile}
+
+		if vfs.HasFeature(avfs.FeatHardlink) {
+			link := vfs.Join(testDir, "moveHardLink")
+
+			err := vfs.Link(srcFile, link)
+			RequireNoError(t, err, "Link %s %s", srcFile, link)
+
+			paths = append(paths, link)
+		}
+
+		for _, dstPath := range paths {
+			err := avfs.Move(vfs, vfs, dstPath, srcFile)
+			AssertLinkError(t, err).Op("move").Old(srcFile).New(dstPath).Err(avfs.ErrSameFile).Test()
+		}
+
+		gotContent, err := vfs.ReadFile(srcFile)
+		RequireNoError(t, err, "ReadFile %s", srcFile)
+
+		if !bytes.Equal(gotContent, content) {
+			t.Errorf("ReadFile %s : want content to be %q, got %q", srcFile, content, gotContent)
+		}
+	})
+
+	t.Run("MoveCrossFSReplace", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, content)
+		dstFS := memfs.New()
+		dstDir := TempRoot(t, dstFS)
+		dstFile := dstFS.Join(dstDir, "moveReplace")
+
+		err := dstFS.WriteFile(dstFile, []byte("replaced"), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", dstFile)
+
+		err = avfs.Move(dstFS, vfs, dstFile, srcFile)
+		RequireNoError(t, err, "Move %s %s", srcFile, dstFile)
+
+		gotContent, err := dstFS.ReadFile(dstFile)
+		RequireNoError(t, err, "ReadFile %s", dstFile)
+
+		if !bytes.Equal(gotContent, content) {
+			t.Errorf("ReadFile %s : want content to be %q, got %q", dstFile, content, gotContent)
+		}
+
+		entries, err := dstFS.ReadDir(dstDir)
+		RequireNoError(t, err, "ReadDir %s", dstDir)
+
+		if len(entries) != 1 {
+			t.Errorf("ReadDir %s : want the temporary copy to be renamed, got %d entries", dstDir, len(entries))
+		}
+	})
+
+	t.Run("MoveNonEmptyDir", func(t *testing.T) {
+		srcDir := createSrc(t, "moveNonEmpty")
+		dstDir := ts.existingDir(t, testDir)
+		_ = createSrc(t, vfs.Join(vfs.Base(dstDir), "moveNonEmpty"))
+
+		err := avfs.Move(vfs, vfs, dstDir, srcDir)
+		AssertLinkError(t, err).Op("move").Old(srcDir).New(vfs.Join(dstDir, "moveNonEmpty")).
+			OSType(avfs.OsLinux).Err(avfs.ErrDirNotEmpty).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinDirNotEmpty).Test()
+	})
+
+	t.Run("MoveDirOnFile", func(t *testing.T) {
+		srcDir := createSrc(t, "moveDirOnFile")
+		existingFile := ts.existingFile(t, testDir, nil)
+
+		err := avfs.Move(vfs, vfs, existingFile, srcDir)
+		AssertLinkError(t, err).Op("move").Old(srcDir).New(existingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNotADirectory).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinPathNotFound).Test()
+	})
+
+	t.Run("MoveNonExisting", func(t *testing.T) {
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+		dstPath := vfs.Join(testDir, "moveNonExisting")
+
+		err := avfs.Move(vfs, vfs, dstPath, nonExistingFile)
+		AssertLinkError(t, err).Op("move").Old(nonExistingFile).New(dstPath).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
+// TestOwnerOf tests OwnerOf function.
+func (ts *Suite) TestOwnerOf(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	path := ts.existingFile(t, testDir, nil)
+
+	info, err := vfs.Stat(path)
+	RequireNoError(t, err, "Stat %s", path)
+
+	sst := vfs.ToSysStat(info)
+	wantUser, wantGroup := strconv.Itoa(sst.Uid()), strconv.Itoa(sst.Gid())
+
+	if vfs.HasFeature(avfs.FeatIdentityMgr) {
+		u := vfs.User()
+		wantUser = u.Name()
+
+		g, err := vfs.Idm().LookupGroupId(u.Gid())
+		RequireNoError(t, err, "LookupGroupId %d", u.Gid())
+
+		wantGroup = g.Name()
+	}
+
+	userName, groupName := avfs.OwnerOf(vfs, info)
+	if userName != wantUser || groupName != wantGroup {
+		t.Errorf("OwnerOf %s : want user %s and group %s, got user %s and group %s",
+			path, wantUser, wantGroup, userName, groupName)
+	}
+}
+
 // TestRel tests Rel function.
 func (ts *Suite) TestRel(t *testing.T, _ string) {
 	vfs := ts.vfsTest
@@ -1162,6 +2635,66 @@ func (ts *Suite) TestRel(t *testing.T, _ string) {
 	}
 }
 
+// TestRemoveAllContext tests avfs.RemoveAllContext function.
+func (ts *Suite) TestRemoveAllContext(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	ctx := context.Background()
+	root := vfs.Join(testDir, "removeAll")
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		err := avfs.RemoveAllContext(ctx, vfs, testDir)
+		if err == nil {
+			t.Errorf("RemoveAllContext : want error on a read only file system, got nil")
+		}
+
+		return
+	}
+
+	ts.createDir(t, vfs.Join(root, "a", "b"), avfs.DefaultDirPerm)
+
+	for _, name := range []string{"f1", "a/f2", "a/b/f3"} {
+		path := vfs.Join(root, vfs.FromSlash(name))
+
+		err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+	}
+
+	t.Run("RemoveAllContextCanceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := avfs.RemoveAllContext(canceled, vfs, root)
+		AssertPathError(t, err).Op("removeall").Path(root).Err(context.Canceled).Test()
+
+		err = avfs.RemoveAllContext(&cancelAfterCtx{Context: ctx, n: 3}, vfs, root)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("RemoveAllContext : want error to be %v, got %v", context.Canceled, err)
+		}
+
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			_, err = vfs.Lstat(pathErr.Path)
+			RequireNoError(t, err, "Lstat %s", pathErr.Path)
+		}
+
+		if ok, err := avfs.DirExists(vfs, root); !ok {
+			t.Errorf("DirExists %s : want directory to exist, got %v", root, err)
+		}
+	})
+
+	t.Run("RemoveAllContext", func(t *testing.T) {
+		for range 2 {
+			err := avfs.RemoveAllContext(ctx, vfs, root)
+			RequireNoError(t, err, "RemoveAllContext %s", root)
+		}
+
+		_, err := vfs.Stat(root)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat %s : want error to be %v, got %v", root, fs.ErrNotExist, err)
+		}
+	})
+}
+
 // TestRndTree tests RndTree methods.
 func (ts *Suite) TestRndTree(t *testing.T, testDir string) {
 	vfs := ts.vfsSetup
@@ -1280,6 +2813,92 @@ func (ts *Suite) TestRndTree(t *testing.T, testDir string) {
 			RequireNoError(t, err, "CreateSymlinks %s", path)
 		}
 	})
+
+	t.Run("RndTreeSeed", func(t *testing.T) {
+		opts := avfs.RndTreeOpts{
+			NbDirs: 10, NbFiles: 20, SymlinkRatio: 0.5, HardlinkRatio: 0.25,
+			MaxFileSize: 1024, MaxDepth: 3, Seed: 1234,
+		}
+
+		var (
+			rts [2]*avfs.RndTree
+			ths [2]*avfs.TreeHash
+		)
+
+		for i := range rts {
+			o := opts
+			rts[i] = avfs.NewRndTree(vfs, &o)
+			path := vfs.Join(testDir, "seed"+strconv.Itoa(i))
+
+			ts.createDir(t, path, avfs.DefaultDirPerm)
+
+			err := rts[i].CreateTree(path)
+			RequireNoError(t, err, "CreateTree %s", path)
+
+			ths[i], err = avfs.HashTree(vfs, path, sha256.New, nil)
+			RequireNoError(t, err, "HashTree %s", path)
+		}
+
+		// The targets of the symbolic links are absolute paths, only the generated entries and the files are compared.
+		if !reflect.DeepEqual(rts[0].Dirs(), rts[1].Dirs()) || !reflect.DeepEqual(rts[0].Files(), rts[1].Files()) ||
+			!reflect.DeepEqual(rts[0].SymLinks(), rts[1].SymLinks()) ||
+			!reflect.DeepEqual(rts[0].HardLinks(), rts[1].HardLinks()) {
+			t.Errorf("GenTree : want trees generated with the same seed to be identical")
+		}
+
+		for _, file := range rts[0].Files() {
+			name := strings.TrimPrefix(file.Name, "/")
+			if !bytes.Equal(ths[0].Files[name], ths[1].Files[name]) {
+				t.Errorf("CreateTree %s : want files generated with the same seed to be identical", name)
+			}
+		}
+
+		rt := avfs.NewRndTree(vfs, &avfs.RndTreeOpts{NbFiles: 1})
+		if rt.Seed == 0 {
+			t.Errorf("NewRndTree : want a random seed to be stored, got 0")
+		}
+	})
+
+	t.Run("RndTreeRatios", func(t *testing.T) {
+		rt := avfs.NewRndTree(vfs, &avfs.RndTreeOpts{NbDirs: 5, NbFiles: 40, SymlinkRatio: 0.5, HardlinkRatio: 0.25})
+		rt.GenTree()
+
+		wantSymlinks, wantHardlinks := 20, 10
+		if !vfs.HasFeature(avfs.FeatSymlink) {
+			wantSymlinks = 0
+		}
+
+		if !vfs.HasFeature(avfs.FeatHardlink) {
+			wantHardlinks = 0
+		}
+
+		if len(rt.SymLinks()) != wantSymlinks {
+			t.Errorf("SymLinks : want number of symbolic links to be %d, got %d", wantSymlinks, len(rt.SymLinks()))
+		}
+
+		if len(rt.HardLinks()) != wantHardlinks {
+			t.Errorf("HardLinks : want number of hard links to be %d, got %d", wantHardlinks, len(rt.HardLinks()))
+		}
+	})
+
+	t.Run("RndTreeDist", func(t *testing.T) {
+		sumSizes := func(dist avfs.RndDist) int {
+			rt := avfs.NewRndTree(vfs, &avfs.RndTreeOpts{NbFiles: 200, MaxFileSize: 1000, FileSizeDist: dist, Seed: 1})
+			rt.GenTree()
+
+			sum := 0
+			for _, file := range rt.Files() {
+				sum += file.Size
+			}
+
+			return sum
+		}
+
+		low, uniform, high := sumSizes(avfs.RndDistLow), sumSizes(avfs.RndDistUniform), sumSizes(avfs.RndDistHigh)
+		if low >= uniform || uniform >= high {
+			t.Errorf("FileSizeDist : want sum of sizes low < uniform < high, got %d, %d, %d", low, uniform, high)
+		}
+	})
 }
 
 // TestSplit tests Split function.
@@ -1359,6 +2978,68 @@ func (ts *Suite) TestSplitAbs(t *testing.T, _ string) {
 	}
 }
 
+// TestSplitPattern tests SplitPattern function.
+func (ts *Suite) TestSplitPattern(t *testing.T, _ string) {
+	vfs := ts.vfsTest
+
+	cases := []struct {
+		pattern string
+		prefix  string
+		suffix  string
+	}{
+		{pattern: "", prefix: "", suffix: ""},
+		{pattern: "file", prefix: "file", suffix: ""},
+		{pattern: "file*", prefix: "file", suffix: ""},
+		{pattern: "*.txt", prefix: "", suffix: ".txt"},
+		{pattern: "file*.txt", prefix: "file", suffix: ".txt"},
+		{pattern: "a*b*c", prefix: "a*b", suffix: "c"},
+	}
+
+	for _, c := range cases {
+		prefix, suffix, err := avfs.SplitPattern(vfs, c.pattern)
+		RequireNoError(t, err, "SplitPattern %s", c.pattern)
+
+		if prefix != c.prefix || suffix != c.suffix {
+			t.Errorf("SplitPattern %s : want prefix, suffix to be %q, %q, got %q, %q",
+				c.pattern, c.prefix, c.suffix, prefix, suffix)
+		}
+	}
+
+	badPattern := "dir" + string(vfs.PathSeparator()) + "file*"
+
+	_, _, err := avfs.SplitPattern(vfs, badPattern)
+	if err != avfs.ErrPatternHasSeparator {
+		t.Errorf("SplitPattern %s : want error to be %v, got %v", badPattern, avfs.ErrPatternHasSeparator, err)
+	}
+}
+
+// TestTreeSize tests TreeSize function.
+func (ts *Suite) TestTreeSize(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if !vfs.HasFeature(avfs.FeatHardlink) {
+		return
+	}
+
+	wantGroups := ts.createHardlinks(t, testDir)
+
+	wantSize := int64(len("aaa") + len("ddddd") + len("eeeeeee"))
+	info, err := vfs.Stat(wantGroups[0][0])
+	RequireNoError(t, err, "Stat %s", wantGroups[0][0])
+
+	if vfs.ToSysStat(info).Nlink() == 1 {
+		// The file system doesn't report the number of links, each link is counted.
+		wantSize += int64(2*len("aaa") + len("eeeeeee"))
+	}
+
+	size, err := avfs.TreeSize(vfs, testDir)
+	RequireNoError(t, err, "TreeSize %s", testDir)
+
+	if size != wantSize {
+		t.Errorf("TreeSize %s : want size to be %d, got %d", testDir, wantSize, size)
+	}
+}
+
 // TestWalkDir tests WalkDir function.
 func (ts *Suite) TestWalkDir(t *testing.T, testDir string) {
 	dirs := ts.createSampleDirs(t, testDir)
@@ -1417,3 +3098,143 @@ func (ts *Suite) TestWalkDir(t *testing.T, testDir string) {
 		RequireNoError(t, err, "WalkDir %s", nonExistingFile)
 	})
 }
+
+// TestWalk tests Walk function.
+func (ts *Suite) TestWalk(t *testing.T, testDir string) {
+	vfsSetup := ts.vfsSetup
+	hasSymlink := vfsSetup.HasFeature(avfs.FeatSymlink)
+
+	ts.createDir(t, vfsSetup.Join(testDir, "a", "b"), avfs.DefaultDirPerm)
+	ts.createDir(t, vfsSetup.Join(testDir, ".hidden"), avfs.DefaultDirPerm)
+
+	for _, name := range []string{"f1", ".h", "a/f2", "a/b/f3", ".hidden/f4"} {
+		ts.createFile(t, vfsSetup.Join(testDir, name), avfs.DefaultFilePerm)
+	}
+
+	if hasSymlink {
+		for _, sl := range [][2]string{{"a", "la"}, {testDir, "a/up"}} {
+			oldname, newname := sl[0], vfsSetup.Join(testDir, sl[1])
+
+			err := vfsSetup.Symlink(vfsSetup.FromSlash(oldname), newname)
+			RequireNoError(t, err, "Symlink %s %s", oldname, newname)
+		}
+	}
+
+	vfs := ts.vfsTest
+
+	// walk returns the paths relative to testDir (in slash form) walked with opts until stop returns true.
+	walk := func(t *testing.T, opts *avfs.WalkOptions, stop func(rel string, d fs.DirEntry) error) []string {
+		var rels []string
+
+		err := avfs.Walk(vfs, testDir, opts, func(path string, d fs.DirEntry, err error) error {
+			RequireNoError(t, err, "Walk %s", path)
+
+			rel, err := vfs.Rel(testDir, path)
+			RequireNoError(t, err, "Rel %s %s", testDir, path)
+
+			rel = vfs.ToSlash(rel)
+			rels = append(rels, rel)
+
+			if stop != nil {
+				return stop(rel, d)
+			}
+
+			return nil
+		})
+		RequireNoError(t, err, "Walk %s", testDir)
+
+		return rels
+	}
+
+	// want returns the names, removing the symbolic links if they are not supported.
+	want := func(names ...string) []string {
+		if hasSymlink {
+			return names
+		}
+
+		return slices.DeleteFunc(names, func(name string) bool { return name == "la" || strings.HasSuffix(name, "up") })
+	}
+
+	t.Run("WalkMaxDepth", func(t *testing.T) {
+		got := walk(t, &avfs.WalkOptions{MaxDepth: 1}, nil)
+
+		wantRels := want(".", ".h", ".hidden", "a", "f1", "la")
+		if !slices.Equal(got, wantRels) {
+			t.Errorf("Walk : want %v, got %v", wantRels, got)
+		}
+	})
+
+	t.Run("WalkTypesMask", func(t *testing.T) {
+		got := walk(t, &avfs.WalkOptions{TypesMask: avfs.WalkTypeRegular}, nil)
+
+		wantRels := []string{".h", ".hidden/f4", "a/b/f3", "a/f2", "f1"}
+		if !slices.Equal(got, wantRels) {
+			t.Errorf("Walk : want %v, got %v", wantRels, got)
+		}
+	})
+
+	t.Run("WalkSkipHidden", func(t *testing.T) {
+		got := walk(t, &avfs.WalkOptions{SkipHidden: true}, nil)
+
+		wantRels := want(".", "a", "a/b", "a/b/f3", "a/f2", "a/up", "f1", "la")
+		if !slices.Equal(got, wantRels) {
+			t.Errorf("Walk : want %v, got %v", wantRels, got)
+		}
+	})
+
+	t.Run("WalkFollowSymlinks", func(t *testing.T) {
+		if !hasSymlink {
+			return
+		}
+
+		opts := &avfs.WalkOptions{TypesMask: avfs.WalkTypeRegular | avfs.WalkTypeSymlink, FollowSymlinks: true, SkipHidden: true}
+		got := walk(t, opts, nil)
+
+		// Links to a parent directory are not followed.
+		wantRels := []string{"a/b/f3", "a/f2", "a/up", "f1", "la/b/f3", "la/f2", "la/up"}
+		if !slices.Equal(got, wantRels) {
+			t.Errorf("Walk : want %v, got %v", wantRels, got)
+		}
+	})
+
+	t.Run("WalkSkipDir", func(t *testing.T) {
+		got := walk(t, &avfs.WalkOptions{SkipHidden: true}, func(rel string, d fs.DirEntry) error {
+			if rel == "a" {
+				return fs.SkipDir
+			}
+
+			return nil
+		})
+
+		wantRels := want(".", "a", "f1", "la")
+		if !slices.Equal(got, wantRels) {
+			t.Errorf("Walk : want %v, got %v", wantRels, got)
+		}
+	})
+
+	t.Run("WalkSkipAll", func(t *testing.T) {
+		got := walk(t, nil, func(rel string, d fs.DirEntry) error {
+			if !d.IsDir() {
+				return fs.SkipAll
+			}
+
+			return nil
+		})
+
+		wantRels := []string{".", ".h"}
+		if !slices.Equal(got, wantRels) {
+			t.Errorf("Walk : want %v, got %v", wantRels, got)
+		}
+	})
+}
+
+// Err returns nil for the first n calls, then context.Canceled.
+func (c *cancelAfterCtx) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+
+	c.n--
+
+	return nil
+}
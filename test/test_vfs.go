@@ -21,9 +21,11 @@ import (
 	"errors"
 	"io"
 	"io/fs"
+	"math/rand"
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -43,16 +45,20 @@ func (ts *Suite) TestVFS(t *testing.T) {
 		ts.TestCreate,
 		ts.TestCreateTemp,
 		ts.TestEvalSymlink,
+		ts.TestFileLock,
+		ts.TestFreeze,
 		ts.TestFromToSlash,
 		ts.TestGlob,
 		ts.TestIsAbs,
 		ts.TestJoin,
+		ts.TestLchtimes,
 		ts.TestLink,
 		ts.TestLstat,
 		ts.TestMatch,
 		ts.TestMkdir,
 		ts.TestMkdirTemp,
 		ts.TestMkdirAll,
+		ts.TestMknod,
 		ts.TestName,
 		ts.TestOpen,
 		ts.TestOpenFileWrite,
@@ -64,16 +70,24 @@ func (ts *Suite) TestVFS(t *testing.T) {
 		ts.TestRemove,
 		ts.TestRemoveAll,
 		ts.TestRename,
+		ts.TestRename2,
 		ts.TestSameFile,
+		ts.TestSampleFunc,
+		ts.TestShortPathName,
 		ts.TestSplit,
 		ts.TestSplitAbs,
 		ts.TestStat,
+		ts.TestStreams,
+		ts.TestSub,
 		ts.TestSymlink,
+		ts.TestSymlinkLoop,
 		ts.TestTempDir,
 		ts.TestToSysStat,
 		ts.TestTruncate,
 		ts.TestUser,
 		ts.TestWalkDir,
+		ts.TestWatchSize,
+		ts.TestWatcher,
 		ts.TestWriteFile,
 		ts.TestWriteString,
 	)
@@ -81,18 +95,210 @@ func (ts *Suite) TestVFS(t *testing.T) {
 	// Tests to be run as root
 	adminUser := ts.idm.AdminUser()
 	ts.RunTests(t, adminUser.Name(),
+		ts.TestACL,
 		ts.TestChmod,
 		ts.TestChown,
 		ts.TestChroot,
 		ts.TestMkSystemDirs,
 		ts.TestCreateHomeDir,
+		ts.TestGroupMember,
 		ts.TestLchown,
+		ts.TestNetworkDrive,
+		ts.TestSecurityInfo,
 		ts.TestSetUserByName,
+		ts.TestSetUserByUid,
+		ts.TestSetgid,
+		ts.TestSticky,
 		ts.TestVolume,
 		ts.TestWriteOnReadOnlyFS,
 	)
 }
 
+// TestACL tests GetACL and SetACL functions.
+func (ts *Suite) TestACL(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	am, ok := vfs.(avfs.ACLManager)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	if vfs.OSType() != avfs.OsLinux {
+		path := ts.emptyFile(t, testDir)
+
+		_, err := am.GetACL(path, avfs.ACLAccess)
+		AssertPathError(t, err).Op("getacl").Path(path).Err(avfs.ErrACLLinux).Test()
+
+		err = am.SetACL(path, avfs.ACLAccess, nil)
+		AssertPathError(t, err).Op("setacl").Path(path).Err(avfs.ErrACLLinux).Test()
+
+		return
+	}
+
+	t.Run("ACLMinimal", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, nil)
+
+		err := vfs.Chmod(path, 0o640)
+		RequireNoError(t, err, "Chmod %s", path)
+
+		acl, err := am.GetACL(path, avfs.ACLAccess)
+		RequireNoError(t, err, "GetACL %s", path)
+
+		want := []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenRead | avfs.OpenWrite},
+			{Tag: avfs.ACLGroupObj, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLOther},
+		}
+
+		if !reflect.DeepEqual(acl, want) {
+			t.Errorf("GetACL %s : want ACL to be %v, got %v", path, want, acl)
+		}
+
+		acl, err = am.GetACL(testDir, avfs.ACLDefault)
+		RequireNoError(t, err, "GetACL %s", testDir)
+
+		if acl != nil {
+			t.Errorf("GetACL %s : want default ACL to be nil, got %v", testDir, acl)
+		}
+	})
+
+	t.Run("ACLInvalid", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, nil)
+
+		noMask := []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLUser, Id: 1, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLGroupObj},
+			{Tag: avfs.ACLOther},
+		}
+
+		err := am.SetACL(path, avfs.ACLAccess, noMask)
+		AssertPathError(t, err).Op("setacl").Path(path).Err(avfs.ErrInvalidArgument).Test()
+
+		err = am.SetACL(path, avfs.ACLAccess, nil)
+		AssertPathError(t, err).Op("setacl").Path(path).Err(avfs.ErrInvalidArgument).Test()
+
+		err = am.SetACL(path, avfs.ACLDefault, noMask[:1])
+		AssertPathError(t, err).Op("setacl").Path(path).Err(avfs.ErrInvalidArgument).Test()
+
+		err = am.SetACL(path, avfs.ACLDefault, nil)
+		AssertPathError(t, err).Op("setacl").Path(path).Err(avfs.ErrPermDenied).Test()
+	})
+
+	if !ts.canTestPerm {
+		return
+	}
+
+	adminUser := ts.idm.AdminUser()
+
+	usrOth, err := ts.idm.LookupUser(UsrOth)
+	RequireNoError(t, err, "LookupUser %s", UsrOth)
+
+	t.Run("ACLNamedUser", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, nil)
+
+		acl := []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenRead | avfs.OpenWrite},
+			{Tag: avfs.ACLUser, Id: usrOth.Uid(), Perm: avfs.OpenRead},
+			{Tag: avfs.ACLGroupObj},
+			{Tag: avfs.ACLMask, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLOther},
+		}
+
+		err = am.SetACL(path, avfs.ACLAccess, acl)
+		RequireNoError(t, err, "SetACL %s", path)
+
+		info, err := vfs.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+
+		if info.Mode().Perm() != 0o640 {
+			t.Errorf("Stat %s : want permissions to be 0o640, got %o", path, info.Mode().Perm())
+		}
+
+		ts.setUser(t, UsrOth)
+
+		_, err = vfs.ReadFile(path)
+		AssertNoError(t, err, "ReadFile %s", path)
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		AssertPathError(t, err).Op("open").Path(path).ErrPermDenied().Test()
+
+		err = am.SetACL(path, avfs.ACLAccess, acl)
+		AssertPathError(t, err).Op("setacl").Path(path).Err(avfs.ErrOpNotPermitted).Test()
+
+		ts.setUser(t, adminUser.Name())
+
+		// Chmod sets the mask of the ACL.
+		err = vfs.Chmod(path, 0o600)
+		RequireNoError(t, err, "Chmod %s", path)
+
+		ts.setUser(t, UsrOth)
+
+		_, err = vfs.ReadFile(path)
+		AssertPathError(t, err).Op("open").Path(path).ErrPermDenied().Test()
+
+		ts.setUser(t, adminUser.Name())
+	})
+
+	t.Run("ACLDefault", func(t *testing.T) {
+		dir := ts.existingDir(t, testDir)
+
+		defaultACL := []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenRead | avfs.OpenWrite | avfs.OpenLookup},
+			{Tag: avfs.ACLUser, Id: usrOth.Uid(), Perm: avfs.OpenRead | avfs.OpenWrite | avfs.OpenLookup},
+			{Tag: avfs.ACLGroupObj, Perm: avfs.OpenRead | avfs.OpenLookup},
+			{Tag: avfs.ACLMask, Perm: avfs.OpenRead | avfs.OpenWrite | avfs.OpenLookup},
+			{Tag: avfs.ACLOther, Perm: avfs.OpenRead | avfs.OpenLookup},
+		}
+
+		err = am.SetACL(dir, avfs.ACLDefault, defaultACL)
+		RequireNoError(t, err, "SetACL %s", dir)
+
+		path := vfs.Join(dir, "file")
+
+		err = vfs.WriteFile(path, nil, 0o640)
+		RequireNoError(t, err, "WriteFile %s", path)
+
+		acl, err := am.GetACL(path, avfs.ACLAccess)
+		RequireNoError(t, err, "GetACL %s", path)
+
+		// The permissions of the new file restrict the owner, the mask and the other entries.
+		want := []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenRead | avfs.OpenWrite},
+			{Tag: avfs.ACLUser, Id: usrOth.Uid(), Perm: avfs.OpenRead | avfs.OpenWrite | avfs.OpenLookup},
+			{Tag: avfs.ACLGroupObj, Perm: avfs.OpenRead | avfs.OpenLookup},
+			{Tag: avfs.ACLMask, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLOther},
+		}
+
+		if !reflect.DeepEqual(acl, want) {
+			t.Errorf("GetACL %s : want ACL to be %v, got %v", path, want, acl)
+		}
+
+		subDir := vfs.Join(dir, "subDir")
+
+		err = vfs.Mkdir(subDir, avfs.DefaultDirPerm)
+		RequireNoError(t, err, "Mkdir %s", subDir)
+
+		acl, err = am.GetACL(subDir, avfs.ACLDefault)
+		RequireNoError(t, err, "GetACL %s", subDir)
+
+		if !reflect.DeepEqual(acl, defaultACL) {
+			t.Errorf("GetACL %s : want default ACL to be %v, got %v", subDir, defaultACL, acl)
+		}
+
+		err = am.SetACL(dir, avfs.ACLDefault, nil)
+		RequireNoError(t, err, "SetACL %s", dir)
+
+		acl, err = am.GetACL(dir, avfs.ACLDefault)
+		RequireNoError(t, err, "GetACL %s", dir)
+
+		if acl != nil {
+			t.Errorf("GetACL %s : want default ACL to be removed, got %v", dir, acl)
+		}
+	})
+}
+
 // TestChdir tests Chdir and Getwd functions.
 func (ts *Suite) TestChdir(t *testing.T, testDir string) {
 	vfs := ts.vfsTest
@@ -162,6 +368,69 @@ func (ts *Suite) TestChdir(t *testing.T, testDir string) {
 			OSType(avfs.OsWindows).Err(avfs.ErrWinDirNameInvalid).Test()
 	})
 
+	t.Run("ChdirDrive", func(t *testing.T) {
+		const otherVolume = "Y:"
+
+		vm, ok := vfs.(avfs.VolumeManager)
+		if !ok || vfs.OSType() != avfs.OsWindows {
+			return
+		}
+
+		err := vm.VolumeAdd(otherVolume)
+		RequireNoError(t, err, "VolumeAdd %s", otherVolume)
+
+		defer vm.VolumeDelete(otherVolume) //nolint:errcheck // Ignore errors.
+		defer vfs.Chdir(testDir)           //nolint:errcheck // Ignore errors.
+
+		otherDir := vfs.Join(otherVolume+`\`, defaultDir)
+
+		err = vfs.Mkdir(otherDir, avfs.DefaultDirPerm)
+		RequireNoError(t, err, "Mkdir %s", otherDir)
+
+		err = vfs.Chdir(otherDir)
+		RequireNoError(t, err, "Chdir %s", otherDir)
+
+		err = vfs.Chdir(testDir)
+		RequireNoError(t, err, "Chdir %s", testDir)
+
+		drivePath := otherVolume + defaultFile
+
+		err = vfs.WriteFile(drivePath, nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", drivePath)
+
+		wantPath := vfs.Join(otherDir, defaultFile)
+
+		_, err = vfs.Stat(wantPath)
+		AssertNoError(t, err, "Stat %s", wantPath)
+
+		absPath, err := vfs.Abs(drivePath)
+		RequireNoError(t, err, "Abs %s", drivePath)
+
+		if absPath != wantPath {
+			t.Errorf("Abs %s : want path to be %s, got %s", drivePath, wantPath, absPath)
+		}
+
+		rootPath := `\` + defaultFile
+		wantPath = avfs.VolumeName(vfs, testDir) + rootPath
+
+		absPath, err = vfs.Abs(rootPath)
+		RequireNoError(t, err, "Abs %s", rootPath)
+
+		if absPath != wantPath {
+			t.Errorf("Abs %s : want path to be %s, got %s", rootPath, wantPath, absPath)
+		}
+
+		err = vfs.Chdir(otherVolume)
+		RequireNoError(t, err, "Chdir %s", otherVolume)
+
+		curDir, err := vfs.Getwd()
+		RequireNoError(t, err, "Getwd")
+
+		if curDir != otherDir {
+			t.Errorf("Getwd : want current dir to be %s, got %s", otherDir, curDir)
+		}
+	})
+
 	t.Run("ChdirPerm", func(t *testing.T) {
 		if !ts.canTestPerm {
 			return
@@ -273,6 +542,16 @@ func (ts *Suite) TestChown(t *testing.T, testDir string) {
 		return
 	}
 
+	if vfs.HasFeature(avfs.FeatStrict) && !vfs.HasFeature(avfs.FeatIdentityMgr) {
+		err := vfs.Chown(testDir, 0, 0)
+
+		AssertPathError(t, err).Op("chown").Path(testDir).
+			OSType(avfs.OsLinux).Err(avfs.ErrNotSupported).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+
+		return
+	}
+
 	t.Run("ChownNonExistingFile", func(t *testing.T) {
 		nonExistingFile := ts.nonExistingFile(t, testDir)
 
@@ -381,11 +660,13 @@ func (ts *Suite) TestChroot(t *testing.T, testDir string) {
 		return
 	}
 
-	if os.Getenv("container") == "podman" {
-		return
-	}
-
 	t.Run("Chroot", func(t *testing.T) {
+		if !vfs.HasFeature(avfs.FeatRealFS) {
+			ts.chrootClone(t, testDir)
+
+			return
+		}
+
 		chrootDir := vfs.Join(testDir, "chroot")
 
 		err := vfs.Mkdir(chrootDir, avfs.DefaultDirPerm)
@@ -434,6 +715,66 @@ func (ts *Suite) TestChroot(t *testing.T, testDir string) {
 	})
 }
 
+// chrootClone tests the Chroot function of a clone of a file system which is not a real file system.
+// The original file system keeps its root directory.
+func (ts *Suite) chrootClone(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	cloner, ok := vfs.(avfs.Cloner)
+	if !ok {
+		return
+	}
+
+	chrootDir := vfs.Join(testDir, "chroot")
+	ts.createDir(t, chrootDir, avfs.DefaultDirPerm)
+
+	const chrootFile = "/file-within-the-chroot.txt"
+	chrootFilePath := vfs.Join(chrootDir, chrootFile)
+	ts.createFile(t, chrootFilePath, avfs.DefaultFilePerm)
+
+	escapeLink := vfs.Join(chrootDir, "escape")
+
+	err := ts.vfsSetup.Symlink(testDir, escapeLink)
+	RequireNoError(t, err, "Symlink %s %s", testDir, escapeLink)
+
+	t.Run("ChrootNotAdmin", func(t *testing.T) {
+		clone := cloner.Clone()
+
+		err = clone.SetUserByName(UsrTest)
+		RequireNoError(t, err, "SetUserByName %s", UsrTest)
+
+		vfsR := clone.(avfs.ChRooter) //nolint:forcetypeassert // the clone has the same type.
+
+		err = vfsR.Chroot(chrootDir)
+		AssertPathError(t, err).Op("chroot").Path(chrootDir).Err(avfs.ErrOpNotPermitted).Test()
+	})
+
+	t.Run("ChrootClone", func(t *testing.T) {
+		clone := cloner.Clone()
+		vfsR := clone.(avfs.ChRooter) //nolint:forcetypeassert // the clone has the same type.
+
+		err = vfsR.Chroot(chrootDir)
+		RequireNoError(t, err, "Chroot %s", chrootDir)
+
+		for _, path := range []string{chrootFile, "/../.." + chrootFile, "file-within-the-chroot.txt"} {
+			_, err = clone.Stat(path)
+			RequireNoError(t, err, "Stat %s", path)
+		}
+
+		// Absolute symbolic links are resolved from the new root directory.
+		escapePath := clone.Join("/escape", "chroot", chrootFile)
+
+		_, err = clone.Stat(escapePath)
+		AssertPathError(t, err).OpStat().Path(escapePath).Err(avfs.ErrNoSuchFileOrDir).Test()
+
+		_, err = clone.Stat(chrootFilePath)
+		AssertPathError(t, err).OpStat().Path(chrootFilePath).Err(avfs.ErrNoSuchFileOrDir).Test()
+
+		_, err = vfs.Stat(chrootFilePath)
+		RequireNoError(t, err, "Stat %s", chrootFilePath)
+	})
+}
+
 // TestChtimes tests Chtimes function.
 func (ts *Suite) TestChtimes(t *testing.T, testDir string) {
 	vfs := ts.vfsTest
@@ -463,6 +804,41 @@ func (ts *Suite) TestChtimes(t *testing.T, testDir string) {
 		}
 	})
 
+	t.Run("ChtimesSymlink", func(t *testing.T) {
+		if !vfs.HasFeature(avfs.FeatSymlink) {
+			return
+		}
+
+		existingFile := ts.existingFile(t, testDir, nil)
+		symlink := vfs.Join(testDir, "chtimesSymlink")
+
+		err := ts.vfsSetup.Symlink(existingFile, symlink)
+		RequireNoError(t, err, "Symlink %s %s", existingFile, symlink)
+
+		lInfo, err := vfs.Lstat(symlink)
+		RequireNoError(t, err, "Lstat %s", symlink)
+
+		mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		err = vfs.Chtimes(symlink, mtime, mtime)
+		RequireNoError(t, err, "Chtimes %s", symlink)
+
+		info, err := vfs.Stat(existingFile)
+		RequireNoError(t, err, "Stat %s", existingFile)
+
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Chtimes %s : want modtime of the target to be %s, got %s", symlink, mtime, info.ModTime())
+		}
+
+		info, err = vfs.Lstat(symlink)
+		RequireNoError(t, err, "Lstat %s", symlink)
+
+		if !info.ModTime().Equal(lInfo.ModTime()) {
+			t.Errorf("Chtimes %s : want modtime of the symlink to be unchanged %s, got %s",
+				symlink, lInfo.ModTime(), info.ModTime())
+		}
+	})
+
 	t.Run("ChtimesNonExistingFile", func(t *testing.T) {
 		nonExistingFile := ts.nonExistingFile(t, testDir)
 
@@ -556,6 +932,55 @@ func (ts *Suite) TestCreateTemp(t *testing.T, testDir string) {
 		RequireNoError(t, err, "Remove")
 	})
 
+	t.Run("CreateTempSeeded", func(t *testing.T) {
+		tr, ok := vfs.(avfs.TempRander)
+		if !ok {
+			return
+		}
+
+		defer tr.SetTempRand(nil)
+
+		var names [2]string
+
+		for i := range names {
+			tr.SetTempRand(rand.NewSource(42))
+
+			f, err := vfs.CreateTemp(testDir, "CreateTempSeeded*.txt")
+			RequireNoError(t, err, "CreateTemp")
+
+			names[i] = f.Name()
+
+			_ = f.Close()
+
+			err = vfs.Remove(f.Name())
+			RequireNoError(t, err, "Remove %s", f.Name())
+		}
+
+		if names[0] != names[1] {
+			t.Errorf("CreateTemp : want names with the same seed to be equal, got %s and %s", names[0], names[1])
+		}
+	})
+
+	t.Run("CreateTempExhausted", func(t *testing.T) {
+		tr, ok := vfs.(avfs.TempRander)
+		if !ok {
+			return
+		}
+
+		defer tr.SetTempRand(nil)
+
+		tr.SetTempRand(constSource(1))
+
+		f, err := vfs.CreateTemp(testDir, "CreateTempExhausted*.tmp")
+		RequireNoError(t, err, "CreateTemp")
+
+		_ = f.Close()
+
+		_, err = vfs.CreateTemp(testDir, "CreateTempExhausted*.tmp")
+		AssertPathError(t, err).Op("createtemp").Path(vfs.Join(testDir, "CreateTempExhausted*.tmp")).
+			Err(fs.ErrExist).Test()
+	})
+
 	t.Run("CreateTempBadPattern", func(t *testing.T) {
 		badPattern := vfs.TempDir()
 
@@ -628,88 +1053,431 @@ func (ts *Suite) TestEvalSymlink(t *testing.T, testDir string) {
 	})
 }
 
-// TestTempDir tests TempDir function.
-func (ts *Suite) TestTempDir(t *testing.T, _ string) {
+// TestFileLock tests Lock, RLock, TryLock and Unlock functions.
+func (ts *Suite) TestFileLock(t *testing.T, testDir string) {
 	vfs := ts.vfsTest
-	tmpDir := vfs.TempDir()
-
-	if vfs.OSType() == avfs.OsWindows {
-		const wantRe = `(?i)^(c:\\windows\\temp|c:\\Users\\[^\\]+\\AppData\\Local\\Temp)$`
 
-		re := regexp.MustCompile(wantRe)
-		if !re.MatchString(tmpDir) {
-			t.Errorf("TempDir : want temp dir to match '%s', got %s", wantRe, tmpDir)
-		}
-	} else {
-		wantTmpDir := "/tmp"
-		if tmpDir != wantTmpDir {
-			t.Errorf("TempDir : want temp dir to be %s, got %s", wantTmpDir, tmpDir)
-		}
+	fl, ok := vfs.(avfs.FileLocker)
+	if !ok {
+		return
 	}
-}
 
-// TestLchown tests Lchown function.
-func (ts *Suite) TestLchown(t *testing.T, testDir string) {
-	vfs := ts.vfsTest
-	idm := vfs.Idm()
+	path := ts.emptyFile(t, testDir)
 
-	if !ts.canTestPerm && vfs.HasFeature(avfs.FeatRealFS) ||
-		vfs.HasFeature(avfs.FeatReadOnly) || avfs.CurrentOSType() == avfs.OsWindows {
-		err := vfs.Lchown(testDir, 0, 0)
+	openFile := func(t *testing.T) avfs.File {
+		t.Helper()
 
-		AssertPathError(t, err).Op("lchown").Path(testDir).
-			OSType(avfs.OsLinux).Err(avfs.ErrOpNotPermitted).Test().
-			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+		f, err := vfs.OpenFile(path, os.O_RDONLY, 0)
+		RequireNoError(t, err, "OpenFile %s", path)
 
-		return
+		t.Cleanup(func() { _ = f.Close() })
+
+		return f
 	}
 
-	t.Run("LChownNonExistingFile", func(t *testing.T) {
-		nonExistingFile := ts.nonExistingFile(t, testDir)
+	t.Run("FileLockExclusive", func(t *testing.T) {
+		f1, f2 := openFile(t), openFile(t)
 
-		err := vfs.Lchown(nonExistingFile, 0, 0)
-		AssertPathError(t, err).Op("lchown").Path(nonExistingFile).
-			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
-			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
-	})
+		err := fl.Lock(f1)
+		RequireNoError(t, err, "Lock %s", path)
 
-	if !vfs.HasFeature(avfs.FeatIdentityMgr) {
-		wantUid, wantGid := 42, 42
+		locked, err := fl.TryLock(f2)
+		RequireNoError(t, err, "TryLock %s", path)
 
-		err := vfs.Lchown(testDir, wantUid, wantGid)
-		RequireNoError(t, err, "Lchown %s", testDir)
+		if locked {
+			t.Errorf("TryLock %s : want file to be locked by another file", path)
+		}
 
-		fst, err := vfs.Stat(testDir)
-		RequireNoError(t, err, "Stat %s", testDir)
+		err = fl.Unlock(f1)
+		RequireNoError(t, err, "Unlock %s", path)
 
-		sst := vfs.ToSysStat(fst)
+		locked, err = fl.TryLock(f2)
+		RequireNoError(t, err, "TryLock %s", path)
 
-		uid, gid := sst.Uid(), sst.Gid()
-		if uid != wantUid || gid != wantGid {
-			t.Errorf("Stat %s : want Uid=%d, Gid=%d, got Uid=%d, Gid=%d",
-				testDir, wantUid, wantGid, uid, gid)
+		if !locked {
+			t.Errorf("TryLock %s : want file to be locked after Unlock", path)
 		}
 
-		return
-	}
+		err = fl.Unlock(f2)
+		RequireNoError(t, err, "Unlock %s", path)
+	})
 
-	dirs := ts.createSampleDirs(t, testDir)
-	files := ts.createSampleFiles(t, testDir)
-	symlinks := ts.createSampleSymlinks(t, testDir)
-	uis := UserInfos()
+	t.Run("FileLockShared", func(t *testing.T) {
+		f1, f2, f3 := openFile(t), openFile(t), openFile(t)
 
-	t.Run("LchownDir", func(t *testing.T) {
-		for _, ui := range uis {
-			wantName := ui.Name
+		err := fl.RLock(f1)
+		RequireNoError(t, err, "RLock %s", path)
 
-			u, err := idm.LookupUser(wantName)
-			RequireNoError(t, err, "LookupUser %s", wantName)
+		err = fl.RLock(f2)
+		RequireNoError(t, err, "RLock %s", path)
 
-			for _, dir := range dirs {
-				err := vfs.Lchown(dir.Path, u.Uid(), u.Gid())
-				RequireNoError(t, err, "Lchown %s", dir.Path)
+		locked, err := fl.TryLock(f3)
+		RequireNoError(t, err, "TryLock %s", path)
 
-				fst, err := vfs.Lstat(dir.Path)
+		if locked {
+			t.Errorf("TryLock %s : want shared locks to prevent an exclusive lock", path)
+		}
+
+		for _, f := range []avfs.File{f1, f2} {
+			err = fl.Unlock(f)
+			RequireNoError(t, err, "Unlock %s", path)
+		}
+	})
+
+	t.Run("FileLockWait", func(t *testing.T) {
+		f1, f2 := openFile(t), openFile(t)
+
+		err := fl.Lock(f1)
+		RequireNoError(t, err, "Lock %s", path)
+
+		done := make(chan error)
+
+		go func() {
+			done <- fl.Lock(f2)
+		}()
+
+		select {
+		case <-done:
+			t.Fatalf("Lock %s : want Lock to wait until Unlock", path)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		err = fl.Unlock(f1)
+		RequireNoError(t, err, "Unlock %s", path)
+
+		err = <-done
+		RequireNoError(t, err, "Lock %s", path)
+
+		err = fl.Unlock(f2)
+		RequireNoError(t, err, "Unlock %s", path)
+	})
+
+	t.Run("FileLockClose", func(t *testing.T) {
+		f1, f2 := openFile(t), openFile(t)
+
+		err := fl.Lock(f1)
+		RequireNoError(t, err, "Lock %s", path)
+
+		err = f1.Close()
+		RequireNoError(t, err, "Close %s", path)
+
+		locked, err := fl.TryLock(f2)
+		RequireNoError(t, err, "TryLock %s", path)
+
+		if !locked {
+			t.Errorf("TryLock %s : want Close to release the lock", path)
+		}
+
+		err = fl.Unlock(f2)
+		RequireNoError(t, err, "Unlock %s", path)
+
+		err = fl.Lock(f1)
+		AssertPathError(t, err).OSType(avfs.OsLinux).Op("flock").Path(path).Err(avfs.ErrBadFileDesc).Test()
+	})
+}
+
+// TestFreeze tests Freeze and Thaw functions.
+func (ts *Suite) TestFreeze(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	fz, ok := vfs.(avfs.Freezer)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	t.Run("FreezeBlocksMutations", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, []byte("frozen"))
+		wantData := []byte("thawed")
+
+		fz.Freeze()
+
+		done := make(chan error)
+
+		go func() {
+			done <- vfs.WriteFile(path, wantData, avfs.DefaultFilePerm)
+		}()
+
+		data, err := vfs.ReadFile(path)
+		if err != nil || string(data) != "frozen" {
+			t.Errorf("ReadFile %s : want data to be frozen, got %s (error %v)", path, data, err)
+		}
+
+		select {
+		case err = <-done:
+			fz.Thaw()
+			t.Fatalf("WriteFile %s : want mutation to be blocked while frozen, got %v", path, err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		fz.Thaw()
+
+		err = <-done
+		RequireNoError(t, err, "WriteFile %s", path)
+
+		data, err = vfs.ReadFile(path)
+		RequireNoError(t, err, "ReadFile %s", path)
+
+		if !bytes.Equal(data, wantData) {
+			t.Errorf("ReadFile %s : want data to be %s, got %s", path, wantData, data)
+		}
+	})
+}
+
+// TestGroupMember tests the permissions granted to the members of supplementary groups.
+func (ts *Suite) TestGroupMember(t *testing.T, testDir string) {
+	vfs, ok := ts.vfsTest.(avfs.VFS)
+	if !ok || !ts.canTestPerm || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	idm := vfs.Idm()
+
+	mbr, ok := idm.(avfs.IdmMembership)
+	if !ok {
+		return
+	}
+
+	const userName, groupName, sharedName = "usrMember", "grpMember", "grpShared"
+
+	shared, err := idm.AddGroup(sharedName)
+	RequireNoError(t, err, "AddGroup %s", sharedName)
+
+	defer idm.DelGroup(sharedName) //nolint:errcheck // Ignore errors.
+
+	dir := vfs.Join(testDir, "shared")
+
+	err = vfs.Mkdir(dir, 0o770)
+	RequireNoError(t, err, "Mkdir %s", dir)
+
+	err = vfs.Chown(dir, 0, shared.Gid())
+	RequireNoError(t, err, "Chown %s", dir)
+
+	err = vfs.Chmod(dir, 0o770)
+	RequireNoError(t, err, "Chmod %s", dir)
+
+	// The current user of vfs is the new user if vfs can't be cloned.
+	usrFS, _ := CreateUserWithHome(t, vfs, userName, groupName)
+
+	t.Run("GroupMemberWrite", func(t *testing.T) {
+		path := vfs.Join(dir, "member")
+
+		err = usrFS.WriteFile(path, nil, avfs.DefaultFilePerm)
+		AssertPathError(t, err).Op("open").Path(path).ErrPermDenied().Test()
+
+		u, err := mbr.AddGroupMember(userName, sharedName)
+		RequireNoError(t, err, "AddGroupMember %s %s", userName, sharedName)
+
+		if !avfs.InGroup(u, shared.Gid()) || u.Gid() == shared.Gid() {
+			t.Errorf("AddGroupMember : want %s to be a supplementary group of %s", sharedName, userName)
+		}
+
+		err = usrFS.SetUser(u)
+		RequireNoError(t, err, "SetUser %s", userName)
+
+		err = usrFS.WriteFile(path, nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+
+		u, err = mbr.DelGroupMember(userName, sharedName)
+		RequireNoError(t, err, "DelGroupMember %s %s", userName, sharedName)
+
+		err = usrFS.SetUser(u)
+		RequireNoError(t, err, "SetUser %s", userName)
+
+		err = usrFS.Remove(path)
+		AssertPathError(t, err).Op("remove").Path(path).ErrPermDenied().Test()
+	})
+
+	t.Run("GroupMemberUnknown", func(t *testing.T) {
+		_, err = mbr.AddGroupMember("unknownUser", sharedName)
+		if want := avfs.UnknownUserError("unknownUser"); err != want {
+			t.Errorf("AddGroupMember : want error to be %v, got %v", want, err)
+		}
+
+		_, err = mbr.DelGroupMember(userName, "unknownGroup")
+		if want := avfs.UnknownGroupError("unknownGroup"); err != want {
+			t.Errorf("DelGroupMember : want error to be %v, got %v", want, err)
+		}
+	})
+}
+
+// TestTempDir tests TempDir function.
+func (ts *Suite) TestTempDir(t *testing.T, _ string) {
+	vfs := ts.vfsTest
+	tmpDir := vfs.TempDir()
+
+	if vfs.OSType() == avfs.OsWindows {
+		const wantRe = `(?i)^(c:\\windows\\temp|c:\\Users\\[^\\]+\\AppData\\Local\\Temp)$`
+
+		re := regexp.MustCompile(wantRe)
+		if !re.MatchString(tmpDir) {
+			t.Errorf("TempDir : want temp dir to match '%s', got %s", wantRe, tmpDir)
+		}
+	} else {
+		wantTmpDir := "/tmp"
+		if tmpDir != wantTmpDir {
+			t.Errorf("TempDir : want temp dir to be %s, got %s", wantTmpDir, tmpDir)
+		}
+	}
+}
+
+// TestLchtimes tests Lchtimes function.
+func (ts *Suite) TestLchtimes(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	lc, ok := vfs.(avfs.Lchtimer)
+	if !ok {
+		return
+	}
+
+	mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		err := lc.Lchtimes(testDir, mtime, mtime)
+		AssertPathError(t, err).Op("lchtimes").Path(testDir).ErrPermDenied().Test()
+
+		return
+	}
+
+	t.Run("LchtimesFile", func(t *testing.T) {
+		existingFile := ts.existingFile(t, testDir, nil)
+
+		err := lc.Lchtimes(existingFile, mtime, mtime)
+		RequireNoError(t, err, "Lchtimes %s", existingFile)
+
+		info, err := vfs.Lstat(existingFile)
+		RequireNoError(t, err, "Lstat %s", existingFile)
+
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Lchtimes %s : want modtime to be %s, got %s", existingFile, mtime, info.ModTime())
+		}
+
+		err = lc.Lchtimes(existingFile, time.Time{}, time.Time{})
+		RequireNoError(t, err, "Lchtimes %s", existingFile)
+
+		info, err = vfs.Lstat(existingFile)
+		RequireNoError(t, err, "Lstat %s", existingFile)
+
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Lchtimes %s : want zero times to leave modtime unchanged %s, got %s",
+				existingFile, mtime, info.ModTime())
+		}
+	})
+
+	t.Run("LchtimesSymlink", func(t *testing.T) {
+		if !vfs.HasFeature(avfs.FeatSymlink) {
+			return
+		}
+
+		existingFile := ts.existingFile(t, testDir, nil)
+		symlink := vfs.Join(testDir, "lchtimesSymlink")
+
+		err := ts.vfsSetup.Symlink(existingFile, symlink)
+		RequireNoError(t, err, "Symlink %s %s", existingFile, symlink)
+
+		wantInfo, err := vfs.Stat(existingFile)
+		RequireNoError(t, err, "Stat %s", existingFile)
+
+		err = lc.Lchtimes(symlink, mtime, mtime)
+		RequireNoError(t, err, "Lchtimes %s", symlink)
+
+		info, err := vfs.Lstat(symlink)
+		RequireNoError(t, err, "Lstat %s", symlink)
+
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Lchtimes %s : want modtime of the symlink to be %s, got %s", symlink, mtime, info.ModTime())
+		}
+
+		info, err = vfs.Stat(existingFile)
+		RequireNoError(t, err, "Stat %s", existingFile)
+
+		if !info.ModTime().Equal(wantInfo.ModTime()) {
+			t.Errorf("Lchtimes %s : want modtime of the target to be unchanged %s, got %s",
+				symlink, wantInfo.ModTime(), info.ModTime())
+		}
+	})
+
+	t.Run("LchtimesNonExistingFile", func(t *testing.T) {
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+
+		err := lc.Lchtimes(nonExistingFile, mtime, mtime)
+		AssertPathError(t, err).Op("lchtimes").Path(nonExistingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
+// TestLchown tests Lchown function.
+func (ts *Suite) TestLchown(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	idm := vfs.Idm()
+
+	if !ts.canTestPerm && vfs.HasFeature(avfs.FeatRealFS) ||
+		vfs.HasFeature(avfs.FeatReadOnly) || avfs.CurrentOSType() == avfs.OsWindows {
+		err := vfs.Lchown(testDir, 0, 0)
+
+		AssertPathError(t, err).Op("lchown").Path(testDir).
+			OSType(avfs.OsLinux).Err(avfs.ErrOpNotPermitted).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+
+		return
+	}
+
+	if vfs.HasFeature(avfs.FeatStrict) && !vfs.HasFeature(avfs.FeatIdentityMgr) {
+		err := vfs.Lchown(testDir, 0, 0)
+
+		AssertPathError(t, err).Op("lchown").Path(testDir).
+			OSType(avfs.OsLinux).Err(avfs.ErrNotSupported).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+
+		return
+	}
+
+	t.Run("LChownNonExistingFile", func(t *testing.T) {
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+
+		err := vfs.Lchown(nonExistingFile, 0, 0)
+		AssertPathError(t, err).Op("lchown").Path(nonExistingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+	})
+
+	if !vfs.HasFeature(avfs.FeatIdentityMgr) {
+		wantUid, wantGid := 42, 42
+
+		err := vfs.Lchown(testDir, wantUid, wantGid)
+		RequireNoError(t, err, "Lchown %s", testDir)
+
+		fst, err := vfs.Stat(testDir)
+		RequireNoError(t, err, "Stat %s", testDir)
+
+		sst := vfs.ToSysStat(fst)
+
+		uid, gid := sst.Uid(), sst.Gid()
+		if uid != wantUid || gid != wantGid {
+			t.Errorf("Stat %s : want Uid=%d, Gid=%d, got Uid=%d, Gid=%d",
+				testDir, wantUid, wantGid, uid, gid)
+		}
+
+		return
+	}
+
+	dirs := ts.createSampleDirs(t, testDir)
+	files := ts.createSampleFiles(t, testDir)
+	symlinks := ts.createSampleSymlinks(t, testDir)
+	uis := UserInfos()
+
+	t.Run("LchownDir", func(t *testing.T) {
+		for _, ui := range uis {
+			wantName := ui.Name
+
+			u, err := idm.LookupUser(wantName)
+			RequireNoError(t, err, "LookupUser %s", wantName)
+
+			for _, dir := range dirs {
+				err := vfs.Lchown(dir.Path, u.Uid(), u.Gid())
+				RequireNoError(t, err, "Lchown %s", dir.Path)
+
+				fst, err := vfs.Lstat(dir.Path)
 				if !AssertNoError(t, err, "Lstat %s", dir.Path) {
 					continue
 				}
@@ -819,6 +1587,9 @@ func (ts *Suite) TestLink(t *testing.T, testDir string) {
 			if !bytes.Equal(file.Content, newContent) {
 				t.Errorf("ReadFile %s : want content to be %s, got %s", newPath, file.Content, newContent)
 			}
+
+			ts.nlink(t, file.Path, 2)
+			ts.nlink(t, newPath, 2)
 		}
 	})
 
@@ -846,7 +1617,38 @@ func (ts *Suite) TestLink(t *testing.T, testDir string) {
 			if !bytes.Equal(file.Content, newContent) {
 				t.Errorf("ReadFile %s : want content to be %s, got %s", newPath, file.Content, newContent)
 			}
+
+			ts.nlink(t, newPath, 1)
+		}
+	})
+
+	t.Run("LinkRename", func(t *testing.T) {
+		file := ts.existingFile(t, testDir, nil)
+		links := []string{vfs.Join(pathLinks, "link1"), vfs.Join(pathLinks, "link2")}
+
+		for _, link := range links {
+			err := vfs.Link(file, link)
+			RequireNoError(t, err, "Link %s %s", file, link)
+		}
+
+		ts.nlink(t, file, 3)
+
+		// Renaming a hard link to another hard link of the same file does nothing.
+		err := vfs.Rename(links[0], links[1])
+		RequireNoError(t, err, "Rename %s %s", links[0], links[1])
+
+		for _, path := range []string{file, links[0], links[1]} {
+			ts.nlink(t, path, 3)
 		}
+
+		// Replacing a hard link by another file decrements the link count.
+		otherFile := ts.emptyFile(t, testDir)
+
+		err = vfs.Rename(otherFile, links[1])
+		RequireNoError(t, err, "Rename %s %s", otherFile, links[1])
+
+		ts.nlink(t, file, 2)
+		ts.nlink(t, links[1], 1)
 	})
 
 	t.Run("LinkErrorDir", func(t *testing.T) {
@@ -1261,31 +2063,270 @@ func (ts *Suite) TestMkdirTemp(t *testing.T, testDir string) {
 		RequireNoError(t, err, "Remove %s", tmpDir)
 	})
 
-	t.Run("MkdirTempBadPattern", func(t *testing.T) {
-		badPattern := vfs.TempDir()
+	t.Run("MkdirTempSeeded", func(t *testing.T) {
+		tr, ok := vfs.(avfs.TempRander)
+		if !ok {
+			return
+		}
 
-		_, err := vfs.MkdirTemp("", badPattern)
-		AssertPathError(t, err).Op("mkdirtemp").Path(badPattern).Err(avfs.ErrPatternHasSeparator).Test()
-	})
+		defer tr.SetTempRand(nil)
 
-	t.Run("MkdirTempOnFile", func(t *testing.T) {
-		existingFile := ts.emptyFile(t, testDir)
+		tr.SetTempRand(rand.NewSource(42))
 
-		_, err := vfs.MkdirTemp(existingFile, "MkdirTempOnFile")
-		AssertPathError(t, err).Op("mkdir").
-			OSType(avfs.OsLinux).Err(avfs.ErrNotADirectory).Test().
-			OSType(avfs.OsWindows).Err(avfs.ErrWinPathNotFound).Test()
-	})
-}
+		dir1, err := vfs.MkdirTemp(testDir, "MkdirTempSeeded")
+		RequireNoError(t, err, "MkdirTemp")
 
-// TestName tests Name function.
-func (ts *Suite) TestName(t *testing.T, _ string) {
-	vfs := ts.vfsSetup
+		tr.SetTempRand(rand.NewSource(42))
 
-	if vfs.Name() != "" {
-		t.Errorf("want name to be empty, got %s", vfs.Name())
-	}
-}
+		// The name generated first already exists, the next random name is used.
+		dir2, err := vfs.MkdirTemp(testDir, "MkdirTempSeeded")
+		RequireNoError(t, err, "MkdirTemp")
+
+		tr.SetTempRand(rand.NewSource(42))
+
+		err = vfs.Remove(dir1)
+		RequireNoError(t, err, "Remove %s", dir1)
+
+		dir3, err := vfs.MkdirTemp(testDir, "MkdirTempSeeded")
+		RequireNoError(t, err, "MkdirTemp")
+
+		if dir1 == dir2 || dir1 != dir3 {
+			t.Errorf("MkdirTemp : want names %s, %s, %s to be different, different, equal", dir1, dir2, dir3)
+		}
+	})
+
+	t.Run("MkdirTempExhausted", func(t *testing.T) {
+		tr, ok := vfs.(avfs.TempRander)
+		if !ok {
+			return
+		}
+
+		defer tr.SetTempRand(nil)
+
+		tr.SetTempRand(constSource(1))
+
+		_, err := vfs.MkdirTemp(testDir, "MkdirTempExhausted")
+		RequireNoError(t, err, "MkdirTemp")
+
+		_, err = vfs.MkdirTemp(testDir, "MkdirTempExhausted")
+		AssertPathError(t, err).Op("mkdirtemp").Path(vfs.Join(testDir, "MkdirTempExhausted*")).
+			Err(fs.ErrExist).Test()
+	})
+
+	t.Run("MkdirTempBadPattern", func(t *testing.T) {
+		badPattern := vfs.TempDir()
+
+		_, err := vfs.MkdirTemp("", badPattern)
+		AssertPathError(t, err).Op("mkdirtemp").Path(badPattern).Err(avfs.ErrPatternHasSeparator).Test()
+	})
+
+	t.Run("MkdirTempOnFile", func(t *testing.T) {
+		existingFile := ts.emptyFile(t, testDir)
+
+		_, err := vfs.MkdirTemp(existingFile, "MkdirTempOnFile")
+		AssertPathError(t, err).Op("mkdir").
+			OSType(avfs.OsLinux).Err(avfs.ErrNotADirectory).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinPathNotFound).Test()
+	})
+}
+
+// TestMknod tests Mknod function.
+func (ts *Suite) TestMknod(t *testing.T, testDir string) {
+	m, ok := ts.vfsTest.(avfs.Mknoder)
+	if !ok {
+		return
+	}
+
+	vfs := ts.vfsTest
+
+	if vfs.OSType() == avfs.OsWindows {
+		path := vfs.Join(testDir, "fifo")
+
+		err := m.Mknod(path, fs.ModeNamedPipe|avfs.DefaultFilePerm, 0)
+		AssertPathError(t, err).Op("mknod").Path(path).Err(avfs.ErrWinNotSupported).Test()
+
+		return
+	}
+
+	const dev = 0x103
+
+	t.Run("MknodTypes", func(t *testing.T) {
+		dir := ts.existingDir(t, testDir)
+
+		types := []fs.FileMode{0, fs.ModeNamedPipe, fs.ModeSocket}
+		if vfs.User().IsAdmin() {
+			types = append(types, fs.ModeDevice, fs.ModeDevice|fs.ModeCharDevice)
+		}
+
+		for i, typ := range types {
+			path := vfs.Join(dir, "special"+strconv.Itoa(i))
+
+			err := m.Mknod(path, typ|avfs.DefaultFilePerm, dev)
+			RequireNoError(t, err, "Mknod %s", path)
+
+			info, err := vfs.Lstat(path)
+			RequireNoError(t, err, "Lstat %s", path)
+
+			wantMode := typ | avfs.DefaultFilePerm&^vfs.UMask()
+			if info.Mode() != wantMode {
+				t.Errorf("Lstat %s : want mode to be %s, got %s", path, wantMode, info.Mode())
+			}
+
+			ds, ok := vfs.ToSysStat(info).(avfs.DeviceStater)
+			if !ok {
+				continue
+			}
+
+			wantDev := uint64(0)
+			if typ&fs.ModeDevice != 0 {
+				wantDev = dev
+			}
+
+			if ds.Rdev() != wantDev {
+				t.Errorf("Lstat %s : want device number to be %d, got %d", path, wantDev, ds.Rdev())
+			}
+		}
+
+		entries, err := vfs.ReadDir(dir)
+		RequireNoError(t, err, "ReadDir %s", dir)
+
+		for i, entry := range entries {
+			if entry.Type() != types[i] {
+				t.Errorf("ReadDir %s : want type of %s to be %s, got %s", dir, entry.Name(), types[i], entry.Type())
+			}
+		}
+	})
+
+	t.Run("MknodDeviceNotAdmin", func(t *testing.T) {
+		if vfs.User().IsAdmin() {
+			return
+		}
+
+		path := vfs.Join(testDir, "device")
+
+		err := m.Mknod(path, fs.ModeDevice|fs.ModeCharDevice|avfs.DefaultFilePerm, dev)
+		AssertPathError(t, err).Op("mknod").Path(path).Err(avfs.ErrOpNotPermitted).Test()
+	})
+
+	t.Run("MknodExisting", func(t *testing.T) {
+		existingFile := ts.emptyFile(t, testDir)
+
+		err := m.Mknod(existingFile, fs.ModeNamedPipe|avfs.DefaultFilePerm, 0)
+		AssertPathError(t, err).Op("mknod").Path(existingFile).Err(avfs.ErrFileExists).Test()
+	})
+
+	t.Run("MknodInvalidType", func(t *testing.T) {
+		path := vfs.Join(testDir, "dir")
+
+		err := m.Mknod(path, fs.ModeDir|avfs.DefaultDirPerm, 0)
+		AssertPathError(t, err).Op("mknod").Path(path).Err(avfs.ErrInvalidArgument).Test()
+	})
+
+	t.Run("MknodNonExistingDir", func(t *testing.T) {
+		path := vfs.Join(ts.nonExistingFile(t, testDir), "fifo")
+
+		err := m.Mknod(path, fs.ModeNamedPipe|avfs.DefaultFilePerm, 0)
+		AssertPathError(t, err).Op("mknod").Path(path).Err(avfs.ErrNoSuchFileOrDir).Test()
+	})
+}
+
+// TestName tests Name function.
+func (ts *Suite) TestName(t *testing.T, _ string) {
+	vfs := ts.vfsSetup
+
+	if vfs.Name() != "" {
+		t.Errorf("want name to be empty, got %s", vfs.Name())
+	}
+}
+
+// TestNetworkDrive tests MapNetworkDrive, NetworkDrives, UniversalName and UnmapNetworkDrive functions.
+func (ts *Suite) TestNetworkDrive(t *testing.T, _ string) {
+	const (
+		drive = "X:"
+		share = `\\server\share`
+	)
+
+	vfs := ts.vfsTest
+
+	nm, ok := vfs.(avfs.NetworkDriveMapper)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	if vfs.OSType() != avfs.OsWindows {
+		err := nm.MapNetworkDrive(drive, share)
+		AssertPathError(t, err).Op("MapNetworkDrive").Path(drive).Err(avfs.ErrVolumeWindows).Test()
+
+		err = nm.UnmapNetworkDrive(drive)
+		AssertPathError(t, err).Op("UnmapNetworkDrive").Path(drive).Err(avfs.ErrVolumeWindows).Test()
+
+		_, err = nm.UniversalName(drive)
+		AssertPathError(t, err).Op("UniversalName").Path(drive).Err(avfs.ErrVolumeWindows).Test()
+
+		if nd := nm.NetworkDrives(); len(nd) != 0 {
+			t.Errorf("NetworkDrives : want no network drive, got %v", nd)
+		}
+
+		return
+	}
+
+	vm, ok := vfs.(avfs.VolumeManager)
+	if !ok {
+		return
+	}
+
+	t.Run("NetworkDriveMap", func(t *testing.T) {
+		err := nm.MapNetworkDrive(drive, share)
+		RequireNoError(t, err, "MapNetworkDrive %s %s", drive, share)
+
+		defer vm.VolumeDelete(share) //nolint:errcheck // Ignore errors.
+
+		if nd := nm.NetworkDrives(); len(nd) != 1 || nd[drive] != share {
+			t.Errorf("NetworkDrives : want %s mapped to %s, got %v", drive, share, nd)
+		}
+
+		drivePath := vfs.Join(drive, defaultFile)
+		sharePath := vfs.Join(share, defaultFile)
+
+		err = vfs.WriteFile(drivePath, nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", drivePath)
+
+		driveInfo, err := vfs.Stat(drivePath)
+		RequireNoError(t, err, "Stat %s", drivePath)
+
+		shareInfo, err := vfs.Stat(sharePath)
+		RequireNoError(t, err, "Stat %s", sharePath)
+
+		if !vfs.SameFile(driveInfo, shareInfo) {
+			t.Errorf("SameFile : want %s and %s to be the same file", drivePath, sharePath)
+		}
+
+		name, err := nm.UniversalName(drivePath)
+		RequireNoError(t, err, "UniversalName %s", drivePath)
+
+		if name != sharePath {
+			t.Errorf("UniversalName : want %s, got %s", sharePath, name)
+		}
+
+		err = nm.UnmapNetworkDrive(drive)
+		RequireNoError(t, err, "UnmapNetworkDrive %s", drive)
+
+		_, err = vfs.Stat(sharePath)
+		RequireNoError(t, err, "Stat %s", sharePath)
+
+		_, err = vfs.Stat(drivePath)
+		AssertPathError(t, err).Op("CreateFile").Path(drivePath).
+			OSType(avfs.OsWindows).Err(avfs.ErrWinPathNotFound).Test()
+	})
+
+	t.Run("NetworkDriveInvalid", func(t *testing.T) {
+		err := nm.MapNetworkDrive(drive, "Y:")
+		AssertPathError(t, err).Op("MapNetworkDrive").Path("Y:").Err(avfs.ErrVolumeNameInvalid).Test()
+
+		err = nm.UnmapNetworkDrive(avfs.DefaultVolume)
+		AssertPathError(t, err).Op("UnmapNetworkDrive").Path(avfs.DefaultVolume).Err(avfs.ErrVolumeNameInvalid).Test()
+	})
+}
 
 // TestOpen tests Open function.
 func (ts *Suite) TestOpen(t *testing.T, testDir string) {
@@ -2076,6 +3117,22 @@ func (ts *Suite) TestRename(t *testing.T, testDir string) {
 			OSType(avfs.OsWindows).Err(avfs.ErrWinAccessDenied).Test()
 	})
 
+	t.Run("RenameDirToDescendant", func(t *testing.T) {
+		srcDir := ts.existingDir(t, testDir)
+		subDir := vfs.Join(srcDir, "sub")
+		dstDir := vfs.Join(subDir, "dst")
+
+		ts.createDir(t, subDir, avfs.DefaultDirPerm)
+
+		err := vfs.Rename(srcDir, dstDir)
+		AssertLinkError(t, err).Op("rename").Old(srcDir).New(dstDir).
+			OSType(avfs.OsLinux).Err(avfs.ErrInvalidArgument).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinSharingViolation).Test()
+
+		_, err = vfs.Stat(subDir)
+		RequireNoError(t, err, "Stat %s", subDir)
+	})
+
 	t.Run("RenamePerm", func(t *testing.T) {
 		if !ts.canTestPerm {
 			return
@@ -2093,6 +3150,108 @@ func (ts *Suite) TestRename(t *testing.T, testDir string) {
 	})
 }
 
+// TestRename2 tests Rename2 function.
+func (ts *Suite) TestRename2(t *testing.T, testDir string) {
+	r, ok := ts.vfsTest.(avfs.Renamer2)
+	if !ok {
+		return
+	}
+
+	vfs := ts.vfsTest
+	data := []byte("data")
+
+	t.Run("Rename2NoReplace", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, data)
+		dstFile := vfs.Join(testDir, "Rename2NoReplace")
+
+		err := r.Rename2(srcFile, dstFile, avfs.RenameNoReplace)
+		RequireNoError(t, err, "Rename2 %s %s", srcFile, dstFile)
+
+		gotData, err := vfs.ReadFile(dstFile)
+		RequireNoError(t, err, "ReadFile %s", dstFile)
+
+		if !bytes.Equal(gotData, data) {
+			t.Errorf("ReadFile %s : want data to be %s, got %s", dstFile, data, gotData)
+		}
+	})
+
+	t.Run("Rename2NoReplaceExisting", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, data)
+		dstFile := ts.emptyFile(t, testDir)
+
+		err := r.Rename2(srcFile, dstFile, avfs.RenameNoReplace)
+		AssertLinkError(t, err).Op("rename").Old(srcFile).New(dstFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrFileExists).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinAlreadyExists).Test()
+
+		_, err = vfs.Stat(srcFile)
+		RequireNoError(t, err, "Stat %s", srcFile)
+	})
+
+	t.Run("Rename2Exchange", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, data)
+		dstDir := ts.existingDir(t, testDir)
+
+		err := r.Rename2(srcFile, dstDir, avfs.RenameExchange)
+		if vfs.OSType() == avfs.OsWindows && errors.Is(err, avfs.ErrWinNotSupported) {
+			t.Skipf("Rename2 %s %s : exchange is not supported", srcFile, dstDir)
+		}
+
+		RequireNoError(t, err, "Rename2 %s %s", srcFile, dstDir)
+
+		ok, err := avfs.DirExists(vfs, srcFile)
+		RequireNoError(t, err, "DirExists %s", srcFile)
+
+		if !ok {
+			t.Errorf("DirExists %s : want directory to exist", srcFile)
+		}
+
+		gotData, err := vfs.ReadFile(dstDir)
+		RequireNoError(t, err, "ReadFile %s", dstDir)
+
+		if !bytes.Equal(gotData, data) {
+			t.Errorf("ReadFile %s : want data to be %s, got %s", dstDir, data, gotData)
+		}
+	})
+
+	t.Run("Rename2ExchangeNonExisting", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, data)
+		dstFile := vfs.Join(testDir, "Rename2ExchangeNonExisting")
+
+		err := r.Rename2(srcFile, dstFile, avfs.RenameExchange)
+		AssertLinkError(t, err).Op("rename").Old(srcFile).New(dstFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound, avfs.ErrWinNotSupported).Test()
+	})
+
+	t.Run("Rename2ExchangeDescendant", func(t *testing.T) {
+		srcDir := ts.existingDir(t, testDir)
+		subDir := vfs.Join(srcDir, "sub")
+
+		ts.createDir(t, subDir, avfs.DefaultDirPerm)
+
+		for _, paths := range [][2]string{{srcDir, subDir}, {subDir, srcDir}} {
+			err := r.Rename2(paths[0], paths[1], avfs.RenameExchange)
+			AssertLinkError(t, err).Op("rename").Old(paths[0]).New(paths[1]).
+				OSType(avfs.OsLinux).Err(avfs.ErrInvalidArgument).Test().
+				OSType(avfs.OsWindows).Err(avfs.ErrWinSharingViolation, avfs.ErrWinNotSupported).Test()
+		}
+
+		_, err := vfs.Stat(subDir)
+		RequireNoError(t, err, "Stat %s", subDir)
+	})
+
+	t.Run("Rename2InvalidFlags", func(t *testing.T) {
+		srcFile := ts.existingFile(t, testDir, data)
+		dstFile := ts.emptyFile(t, testDir)
+
+		err := r.Rename2(srcFile, dstFile, avfs.RenameNoReplace|avfs.RenameExchange)
+		AssertLinkError(t, err).Op("rename").Old(srcFile).New(dstFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrInvalidArgument).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+	})
+}
+
 // TestSameFile tests SameFile function.
 func (ts *Suite) TestSameFile(t *testing.T, testDir string) {
 	vfs := ts.vfsSetup
@@ -2177,80 +3336,439 @@ func (ts *Suite) TestSameFile(t *testing.T, testDir string) {
 			RequireNoError(t, err, "Remove %s", path2)
 		}
 	})
-}
 
-func (ts *Suite) TestSetUserByName(t *testing.T, testDir string) {
-	vfs := ts.vfsTest
-	idm := vfs.Idm()
+	t.Run("SameFileDir", func(t *testing.T) {
+		info1, err := vfs.Stat(testDir1)
+		RequireNoError(t, err, "Stat %s", testDir1)
 
-	if !vfs.HasFeature(avfs.FeatIdentityMgr) || vfs.HasFeature(avfs.FeatReadOnlyIdm) || vfs.HasFeature(avfs.FeatReadOnly) {
-		userName := vfs.User().Name()
+		info2, err := vfs.Stat(testDir2)
+		RequireNoError(t, err, "Stat %s", testDir2)
 
-		var wantErr error
-		if !vfs.HasFeature(avfs.FeatReadOnlyIdm) {
-			wantErr = avfs.ErrPermDenied
+		if vfs.SameFile(info1, info2) {
+			t.Errorf("SameFile %s, %s : want different directories", testDir1, testDir2)
 		}
 
-		err := vfs.SetUserByName(userName)
-		if err != wantErr {
-			t.Errorf("setUser : want error to be %v, got %v", wantErr, err)
+		info3, err := vfs.Stat(testDir1)
+		RequireNoError(t, err, "Stat %s", testDir1)
+
+		if !vfs.SameFile(info1, info3) {
+			t.Errorf("SameFile %s, %s : want same directories", testDir1, testDir1)
 		}
+	})
+}
 
-		return
+// TestSampleFunc tests SetSampleFunc function.
+func (ts *Suite) TestSampleFunc(t *testing.T, testDir string) {
+	type sample struct {
+		fn  avfs.FnVFS
+		err error
 	}
 
-	t.Run("UserNotExists", func(t *testing.T) {
-		const userName = "notExistingUser"
+	vfs := ts.vfsTest
 
-		wantErr := avfs.UnknownUserError(userName)
+	sp, ok := vfs.(avfs.Sampler)
+	if !ok {
+		return
+	}
 
-		_, err := idm.LookupUser(userName)
-		if err != wantErr {
-			t.Fatalf("LookupUser %s : want error to be %v, got %v", userName, wantErr, err)
-		}
+	var samples []sample
 
-		err = vfs.SetUserByName(userName)
-		if err != wantErr {
-			t.Errorf("setUser %s : want error to be %v, got %v", userName, wantErr, err)
+	sp.SetSampleFunc(func(fn avfs.FnVFS, d time.Duration, err error) {
+		if d < 0 {
+			t.Errorf("SampleFunc %s : want duration >= 0, got %v", fn, d)
 		}
+
+		samples = append(samples, sample{fn: fn, err: err})
 	})
 
-	t.Run("UserExists", func(t *testing.T) {
-		for _, ui := range UserInfos() {
-			userName := ui.Name
+	defer sp.SetSampleFunc(nil)
 
-			lu, err := idm.LookupUser(userName)
-			if !AssertNoError(t, err, "LookupUser %s", userName) {
-				continue
-			}
+	existingFile := ts.emptyFile(t, testDir)
+	nonExistingFile := ts.nonExistingFile(t, testDir)
 
-			uid := lu.Uid()
-			gid := lu.Gid()
+	samples = nil
 
-			// loop to test change with the same user
-			for i := range 2 {
-				err = vfs.SetUserByName(userName)
-				if !AssertNoError(t, err, "SetUserByName %s %d", userName, i) {
-					continue
-				}
+	_, err := vfs.Stat(existingFile)
+	RequireNoError(t, err, "Stat %s", existingFile)
 
-				u := vfs.User()
+	_, wantErr := vfs.Lstat(nonExistingFile)
 
-				if u.Name() != userName {
-					t.Errorf("setUser %s : want name to be %s, got %s", userName, userName, u.Name())
-				}
+	want := []sample{{fn: avfs.FnStat}, {fn: avfs.FnLstat, err: wantErr}}
+	if !reflect.DeepEqual(samples, want) {
+		t.Errorf("SampleFunc : want samples %v, got %v", want, samples)
+	}
 
-				if u.Uid() != uid {
-					t.Errorf("setUser %s : want uid to be %d, got %d", userName, uid, u.Uid())
-				}
+	sp.SetSampleFunc(nil)
+	samples = nil
 
-				if u.Gid() != gid {
-					t.Errorf("setUser %s : want gid to be %d, got %d", userName, gid, u.Gid())
-				}
+	_, _ = vfs.Stat(existingFile)
 
-				cu := vfs.User()
-				if cu.Name() != userName {
-					t.Errorf("setUser %s : want name to be %s, got %s", userName, userName, cu.Name())
+	if len(samples) != 0 {
+		t.Errorf("SampleFunc : want no sample after SetSampleFunc(nil), got %v", samples)
+	}
+}
+
+// TestSecurityInfo tests GetSecurityInfo and SetSecurityInfo functions.
+func (ts *Suite) TestSecurityInfo(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	sm, ok := vfs.(avfs.SecurityManager)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	if vfs.OSType() != avfs.OsWindows {
+		path := ts.emptyFile(t, testDir)
+
+		_, err := sm.GetSecurityInfo(path)
+		AssertPathError(t, err).Op("getsecurityinfo").Path(path).Err(avfs.ErrSecurityWindows).Test()
+
+		err = sm.SetSecurityInfo(path, &avfs.SecurityInfo{})
+		AssertPathError(t, err).Op("setsecurityinfo").Path(path).Err(avfs.ErrSecurityWindows).Test()
+
+		return
+	}
+
+	t.Run("SecurityInfoDerived", func(t *testing.T) {
+		path := ts.emptyFile(t, testDir)
+
+		err := vfs.Chmod(path, 0o640)
+		RequireNoError(t, err, "Chmod %s", path)
+
+		si, err := sm.GetSecurityInfo(path)
+		RequireNoError(t, err, "GetSecurityInfo %s", path)
+
+		wantMasks := []avfs.OpenMode{avfs.OpenRead | avfs.OpenWrite, avfs.OpenRead, 0}
+		if len(si.Dacl) != len(wantMasks) {
+			t.Fatalf("GetSecurityInfo : want %d entries, got %v", len(wantMasks), si.Dacl)
+		}
+
+		for i, ace := range si.Dacl {
+			if ace.Type != avfs.AceAllow || ace.Mask != wantMasks[i] {
+				t.Errorf("GetSecurityInfo : want entry %d to allow %o, got %v", i, wantMasks[i], ace)
+			}
+		}
+	})
+
+	t.Run("SecurityInfoDeny", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, nil)
+
+		si, err := sm.GetSecurityInfo(path)
+		RequireNoError(t, err, "GetSecurityInfo %s", path)
+
+		deny := avfs.ACE{Type: avfs.AceDeny, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenWrite}
+		si.Dacl = append([]avfs.ACE{deny}, si.Dacl...)
+
+		err = sm.SetSecurityInfo(path, si)
+		RequireNoError(t, err, "SetSecurityInfo %s", path)
+
+		_, err = vfs.ReadFile(path)
+		AssertNoError(t, err, "ReadFile %s", path)
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		AssertPathError(t, err).Op("open").Path(path).ErrPermDenied().Test()
+
+		err = vfs.Chmod(path, 0o600)
+		RequireNoError(t, err, "Chmod %s", path)
+
+		si, err = sm.GetSecurityInfo(path)
+		RequireNoError(t, err, "GetSecurityInfo %s", path)
+
+		if len(si.Dacl) != 4 || si.Dacl[0] != deny || si.Dacl[1].Mask != avfs.OpenRead|avfs.OpenWrite || si.Dacl[3].Mask != 0 {
+			t.Errorf("GetSecurityInfo : want Chmod to update allow entries only, got %v", si.Dacl)
+		}
+
+		err = sm.SetSecurityInfo(path, &avfs.SecurityInfo{Owner: si.Owner})
+		RequireNoError(t, err, "SetSecurityInfo %s", path)
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		AssertNoError(t, err, "WriteFile %s", path)
+	})
+
+	t.Run("SecurityInfoAllowBeforeDeny", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, nil)
+
+		si, err := sm.GetSecurityInfo(path)
+		RequireNoError(t, err, "GetSecurityInfo %s", path)
+
+		si.Dacl = []avfs.ACE{
+			{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenRead | avfs.OpenWrite},
+			{Type: avfs.AceDeny, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenWrite},
+		}
+
+		err = sm.SetSecurityInfo(path, si)
+		RequireNoError(t, err, "SetSecurityInfo %s", path)
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		AssertNoError(t, err, "WriteFile %s", path)
+
+		si.Dacl = []avfs.ACE{
+			{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenRead},
+			{Type: avfs.AceDeny, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenWrite},
+			{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenWrite},
+		}
+
+		err = sm.SetSecurityInfo(path, si)
+		RequireNoError(t, err, "SetSecurityInfo %s", path)
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		AssertPathError(t, err).Op("open").Path(path).ErrPermDenied().Test()
+
+		err = sm.SetSecurityInfo(path, &avfs.SecurityInfo{Owner: si.Owner})
+		RequireNoError(t, err, "SetSecurityInfo %s", path)
+	})
+
+	t.Run("SecurityInfoNonExisting", func(t *testing.T) {
+		path := ts.nonExistingFile(t, testDir)
+
+		_, err := sm.GetSecurityInfo(path)
+		AssertPathError(t, err).Op("getsecurityinfo").Path(path).
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+
+		err = sm.SetSecurityInfo(path, &avfs.SecurityInfo{})
+		AssertPathError(t, err).Op("setsecurityinfo").Path(path).
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
+// TestSetgid tests the group inherited from the directories having the setgid bit set
+// and the clearing of the setuid and setgid bits of the files.
+func (ts *Suite) TestSetgid(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	if !ts.canTestPerm || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	defer ts.setInitUser(t)
+
+	oth, err := vfs.Idm().LookupUser(UsrOth)
+	RequireNoError(t, err, "LookupUser %s", UsrOth)
+
+	dir := vfs.Join(testDir, "setgid")
+
+	err = vfs.Mkdir(dir, 0o777)
+	RequireNoError(t, err, "Mkdir %s", dir)
+
+	err = vfs.Chown(dir, 0, oth.Gid())
+	RequireNoError(t, err, "Chown %s", dir)
+
+	err = vfs.Chmod(dir, fs.ModeSetgid|0o777)
+	RequireNoError(t, err, "Chmod %s", dir)
+
+	file := vfs.Join(dir, "file")
+	subDir := vfs.Join(dir, "subDir")
+
+	t.Run("SetgidInherit", func(t *testing.T) {
+		ts.setUser(t, UsrTest)
+		defer ts.setInitUser(t)
+
+		err = vfs.WriteFile(file, nil, 0o644)
+		RequireNoError(t, err, "WriteFile %s", file)
+
+		err = vfs.Mkdir(subDir, 0o755)
+		RequireNoError(t, err, "Mkdir %s", subDir)
+
+		for _, path := range []string{file, subDir} {
+			info, err := vfs.Stat(path)
+			RequireNoError(t, err, "Stat %s", path)
+
+			if gid := vfs.ToSysStat(info).Gid(); gid != oth.Gid() {
+				t.Errorf("Stat %s : want gid to be %d, got %d", path, oth.Gid(), gid)
+			}
+		}
+
+		info, err := vfs.Stat(subDir)
+		RequireNoError(t, err, "Stat %s", subDir)
+
+		if info.Mode()&fs.ModeSetgid == 0 {
+			t.Errorf("Stat %s : want the setgid bit to be set, got mode %s", subDir, info.Mode())
+		}
+	})
+
+	t.Run("SetgidChmodNonMember", func(t *testing.T) {
+		ts.setUser(t, UsrTest)
+		defer ts.setInitUser(t)
+
+		err = vfs.Chmod(file, fs.ModeSetgid|0o755)
+		RequireNoError(t, err, "Chmod %s", file)
+
+		info, err := vfs.Stat(file)
+		RequireNoError(t, err, "Stat %s", file)
+
+		if wantMode := fs.FileMode(0o755); info.Mode() != wantMode {
+			t.Errorf("Stat %s : want mode to be %s, got %s", file, wantMode, info.Mode())
+		}
+	})
+
+	t.Run("SetuidWrite", func(t *testing.T) {
+		wantMode := fs.ModeSetuid | 0o755
+
+		err = vfs.Chmod(file, wantMode)
+		RequireNoError(t, err, "Chmod %s", file)
+
+		err = vfs.WriteFile(file, []byte("admin"), 0o644)
+		RequireNoError(t, err, "WriteFile %s", file)
+
+		// In a user namespace, the kernel checks CAP_FSETID in the initial namespace
+		// and clears the setuid bit even when root of the namespace writes the file.
+		if vfs.HasFeature(avfs.FeatRealFS) && os.Getenv(RunInNamespaceEnv) != "" {
+			wantMode = 0o755
+		}
+
+		ts.assertMode(t, file, wantMode)
+
+		ts.setUser(t, UsrTest)
+
+		err = vfs.WriteFile(file, []byte("user"), 0o644)
+		RequireNoError(t, err, "WriteFile %s", file)
+
+		ts.setInitUser(t)
+		ts.assertMode(t, file, 0o755)
+	})
+
+	t.Run("SetuidChown", func(t *testing.T) {
+		err = vfs.Chmod(file, fs.ModeSetuid|fs.ModeSetgid|0o755)
+		RequireNoError(t, err, "Chmod %s", file)
+
+		err = vfs.Chown(file, oth.Uid(), oth.Gid())
+		RequireNoError(t, err, "Chown %s", file)
+
+		ts.assertMode(t, file, 0o755)
+	})
+}
+
+// assertMode checks that the mode of the file path is wantMode.
+func (ts *Suite) assertMode(tb testing.TB, path string, wantMode fs.FileMode) {
+	tb.Helper()
+
+	info, err := ts.vfsTest.Stat(path)
+	RequireNoError(tb, err, "Stat %s", path)
+
+	if info.Mode() != wantMode {
+		tb.Errorf("Stat %s : want mode to be %s, got %s", path, wantMode, info.Mode())
+	}
+}
+
+// TestShortPathName tests ShortPathName function and the resolution of short names.
+func (ts *Suite) TestShortPathName(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	sp, ok := vfs.(avfs.ShortPathNamer)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	if vfs.OSType() != avfs.OsWindows {
+		_, err := sp.ShortPathName(testDir)
+		AssertPathError(t, err).Op("shortpathname").Path(testDir).Err(avfs.ErrShortNamesWindows).Test()
+
+		return
+	}
+
+	t.Run("ShortPathName", func(t *testing.T) {
+		dir := vfs.Join(testDir, "Long directory name")
+		path := vfs.Join(dir, "long file name.text")
+
+		err := vfs.MkdirAll(dir, avfs.DefaultDirPerm)
+		RequireNoError(t, err, "MkdirAll %s", dir)
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+
+		shortPath, err := sp.ShortPathName(path)
+		RequireNoError(t, err, "ShortPathName %s", path)
+
+		info, err := vfs.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+
+		shortInfo, err := vfs.Stat(shortPath)
+		RequireNoError(t, err, "Stat %s", shortPath)
+
+		if !vfs.SameFile(info, shortInfo) {
+			t.Errorf("Stat %s : want %s to be the same file", shortPath, path)
+		}
+	})
+
+	t.Run("ShortPathNameNonExisting", func(t *testing.T) {
+		path := ts.nonExistingFile(t, testDir)
+
+		_, err := sp.ShortPathName(path)
+		AssertPathError(t, err).Op("shortpathname").Path(path).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
+func (ts *Suite) TestSetUserByName(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	idm := vfs.Idm()
+
+	if !vfs.HasFeature(avfs.FeatIdentityMgr) || vfs.HasFeature(avfs.FeatReadOnlyIdm) || vfs.HasFeature(avfs.FeatReadOnly) {
+		userName := vfs.User().Name()
+
+		var wantErr error
+		if !vfs.HasFeature(avfs.FeatReadOnlyIdm) {
+			wantErr = avfs.ErrPermDenied
+		}
+
+		err := vfs.SetUserByName(userName)
+		if err != wantErr {
+			t.Errorf("setUser : want error to be %v, got %v", wantErr, err)
+		}
+
+		return
+	}
+
+	t.Run("UserNotExists", func(t *testing.T) {
+		const userName = "notExistingUser"
+
+		wantErr := avfs.UnknownUserError(userName)
+
+		_, err := idm.LookupUser(userName)
+		if err != wantErr {
+			t.Fatalf("LookupUser %s : want error to be %v, got %v", userName, wantErr, err)
+		}
+
+		err = vfs.SetUserByName(userName)
+		if err != wantErr {
+			t.Errorf("setUser %s : want error to be %v, got %v", userName, wantErr, err)
+		}
+	})
+
+	t.Run("UserExists", func(t *testing.T) {
+		for _, ui := range UserInfos() {
+			userName := ui.Name
+
+			lu, err := idm.LookupUser(userName)
+			if !AssertNoError(t, err, "LookupUser %s", userName) {
+				continue
+			}
+
+			uid := lu.Uid()
+			gid := lu.Gid()
+
+			// loop to test change with the same user
+			for i := range 2 {
+				err = vfs.SetUserByName(userName)
+				if !AssertNoError(t, err, "SetUserByName %s %d", userName, i) {
+					continue
+				}
+
+				u := vfs.User()
+
+				if u.Name() != userName {
+					t.Errorf("setUser %s : want name to be %s, got %s", userName, userName, u.Name())
+				}
+
+				if u.Uid() != uid {
+					t.Errorf("setUser %s : want uid to be %d, got %d", userName, uid, u.Uid())
+				}
+
+				if u.Gid() != gid {
+					t.Errorf("setUser %s : want gid to be %d, got %d", userName, gid, u.Gid())
+				}
+
+				cu := vfs.User()
+				if cu.Name() != userName {
+					t.Errorf("setUser %s : want name to be %s, got %s", userName, userName, cu.Name())
 				}
 
 				if cu.Uid() != uid {
@@ -2265,6 +3783,84 @@ func (ts *Suite) TestSetUserByName(t *testing.T, testDir string) {
 	})
 }
 
+// TestSetUserByUid tests SetUserByUid function.
+func (ts *Suite) TestSetUserByUid(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	idm := vfs.Idm()
+
+	if !vfs.HasFeature(avfs.FeatIdentityMgr) || vfs.HasFeature(avfs.FeatReadOnlyIdm) || vfs.HasFeature(avfs.FeatReadOnly) {
+		u := vfs.User()
+
+		var wantErr error
+		if !vfs.HasFeature(avfs.FeatReadOnlyIdm) {
+			wantErr = avfs.ErrPermDenied
+		}
+
+		err := vfs.SetUserByUid(u.Uid(), u.Gid())
+		if err != wantErr {
+			t.Errorf("SetUserByUid : want error to be %v, got %v", wantErr, err)
+		}
+
+		return
+	}
+
+	defer ts.setInitUser(t)
+
+	t.Run("UserExists", func(t *testing.T) {
+		for _, ui := range UserInfos() {
+			userName := ui.Name
+
+			lu, err := idm.LookupUser(userName)
+			if !AssertNoError(t, err, "LookupUser %s", userName) {
+				continue
+			}
+
+			err = vfs.SetUserByUid(lu.Uid(), lu.Gid())
+			if !AssertNoError(t, err, "SetUserByUid %d %d", lu.Uid(), lu.Gid()) {
+				continue
+			}
+
+			u := vfs.User()
+			if u.Name() != userName {
+				t.Errorf("SetUserByUid %d : want name to be %s, got %s", lu.Uid(), userName, u.Name())
+			}
+		}
+	})
+
+	t.Run("EphemeralUser", func(t *testing.T) {
+		const uid, gid = 43210, 43211
+
+		ts.setInitUser(t)
+
+		if ts.canTestPerm {
+			path := ts.emptyFile(t, testDir)
+
+			err := vfs.Chown(path, uid, gid)
+			RequireNoError(t, err, "Chown %s", path)
+
+			info, err := vfs.Stat(path)
+			RequireNoError(t, err, "Stat %s", path)
+
+			sst := vfs.ToSysStat(info)
+			if sst.Uid() != uid || sst.Gid() != gid {
+				t.Errorf("Stat %s : want uid, gid to be %d, %d, got %d, %d", path, uid, gid, sst.Uid(), sst.Gid())
+			}
+		}
+
+		err := vfs.SetUserByUid(uid, gid)
+		RequireNoError(t, err, "SetUserByUid %d %d", uid, gid)
+
+		u := vfs.User()
+		if u.Uid() != uid || u.Gid() != gid {
+			t.Errorf("SetUserByUid : want uid, gid to be %d, %d, got %d, %d", uid, gid, u.Uid(), u.Gid())
+		}
+
+		if u.Name() != strconv.Itoa(uid) {
+			t.Errorf("SetUserByUid : want name to be %d, got %s", uid, u.Name())
+		}
+	})
+}
+
 // TestStat tests Stat function.
 func (ts *Suite) TestStat(t *testing.T, testDir string) {
 	vfs := ts.vfsTest
@@ -2302,70 +3898,240 @@ func (ts *Suite) TestStat(t *testing.T, testDir string) {
 				continue
 			}
 
-			if info.Name() != vfs.Base(file.Path) {
-				t.Errorf("Stat %s : want name to be %s, got %s", file.Path, vfs.Base(file.Path), info.Name())
-			}
+			if info.Name() != vfs.Base(file.Path) {
+				t.Errorf("Stat %s : want name to be %s, got %s", file.Path, vfs.Base(file.Path), info.Name())
+			}
+
+			wantMode := file.Mode &^ vfs.UMask()
+			if vfs.OSType() == avfs.OsWindows {
+				wantMode = avfs.DefaultFilePerm
+			}
+
+			if wantMode != info.Mode() {
+				t.Errorf("Stat %s : want mode to be %s, got %s", file.Path, wantMode, info.Mode())
+			}
+
+			wantSize := int64(len(file.Content))
+			if wantSize != info.Size() {
+				t.Errorf("Lstat %s : want size to be %d, got %d", file.Path, wantSize, info.Size())
+			}
+		}
+	})
+
+	t.Run("StatSymlink", func(t *testing.T) {
+		for _, sl := range ts.sampleSymlinksEval(testDir) {
+			info, err := vfs.Stat(sl.NewPath)
+			if !AssertNoError(t, err, "Stat %s ", sl.NewPath) {
+				continue
+			}
+
+			wantName := vfs.Base(sl.OldPath)
+			if sl.IsSymlink {
+				wantName = vfs.Base(sl.NewPath)
+			}
+
+			wantMode := sl.Mode
+
+			if wantName != info.Name() {
+				t.Errorf("Stat %s : want name to be %s, got %s", sl.NewPath, wantName, info.Name())
+			}
+
+			if ts.canTestPerm && wantMode != info.Mode() {
+				t.Errorf("Stat %s : want mode to be %s, got %s", sl.NewPath, wantMode, info.Mode())
+			}
+		}
+	})
+
+	t.Run("StatNonExistingFile", func(t *testing.T) {
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+
+		_, err := vfs.Stat(nonExistingFile)
+		AssertPathError(t, err).OpStat().Path(nonExistingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat %s : want errors.Is(err, fs.ErrNotExist) to be true, got false ", nonExistingFile)
+		}
+	})
+
+	t.Run("StatSubDirOnFile", func(t *testing.T) {
+		subDirOnFile := vfs.Join(files[0].Path, "subDirOnFile")
+
+		_, err := vfs.Stat(subDirOnFile)
+		AssertPathError(t, err).OpStat().Path(subDirOnFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNotADirectory).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinPathNotFound).Test()
+	})
+}
+
+// TestStreams tests alternate data streams and the Streams function.
+func (ts *Suite) TestStreams(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	sl, ok := vfs.(avfs.StreamLister)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	if vfs.OSType() != avfs.OsWindows {
+		path := ts.emptyFile(t, testDir)
+
+		_, err := sl.Streams(path)
+		AssertPathError(t, err).Op("streams").Path(path).Err(avfs.ErrStreamsWindows).Test()
+
+		return
+	}
+
+	t.Run("StreamsReadWrite", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, []byte("main"))
+		stream := path + ":stream"
+		data := []byte("stream data")
+
+		err := vfs.WriteFile(stream, data, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", stream)
+
+		gotData, err := vfs.ReadFile(stream)
+		RequireNoError(t, err, "ReadFile %s", stream)
+
+		if !bytes.Equal(gotData, data) {
+			t.Errorf("ReadFile %s : want data to be %s, got %s", stream, data, gotData)
+		}
+
+		gotData, err = vfs.ReadFile(path + "::$DATA")
+		RequireNoError(t, err, "ReadFile %s", path+"::$DATA")
+
+		if string(gotData) != "main" {
+			t.Errorf("ReadFile %s : want data to be main, got %s", path, gotData)
+		}
+
+		info, err := vfs.Stat(stream + ":$DATA")
+		RequireNoError(t, err, "Stat %s", stream)
+
+		if info.Size() != int64(len(data)) {
+			t.Errorf("Stat %s : want size to be %d, got %d", stream, len(data), info.Size())
+		}
+
+		streams, err := sl.Streams(path)
+		RequireNoError(t, err, "Streams %s", path)
+
+		if len(streams) != 1 || streams[0] != "stream" {
+			t.Errorf("Streams %s : want streams to be [stream], got %v", path, streams)
+		}
+
+		err = vfs.Remove(stream)
+		RequireNoError(t, err, "Remove %s", stream)
+
+		_, err = vfs.Stat(stream)
+		AssertPathError(t, err).Op("CreateFile").Path(stream).Err(avfs.ErrWinFileNotFound).Test()
+
+		_, err = vfs.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+	})
+
+	t.Run("StreamsCreateFile", func(t *testing.T) {
+		path := ts.nonExistingFile(t, testDir)
+		stream := path + ":stream"
+
+		err := vfs.WriteFile(stream, nil, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", stream)
+
+		info, err := vfs.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+
+		if info.Size() != 0 {
+			t.Errorf("Stat %s : want size to be 0, got %d", path, info.Size())
+		}
+	})
+
+	t.Run("StreamsInvalidType", func(t *testing.T) {
+		path := ts.emptyFile(t, testDir)
+		stream := path + ":stream:$INDEX_ALLOCATION"
+
+		_, err := vfs.OpenFile(stream, os.O_RDONLY, 0)
+		AssertPathError(t, err).Op("open").Path(stream).Err(avfs.ErrWinInvalidName).Test()
+	})
+}
+
+// TestSticky tests that only the owners can remove or rename the entries of the directories having the sticky bit set.
+func (ts *Suite) TestSticky(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+	if !ts.canTestPerm || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	defer ts.setInitUser(t)
+
+	dir := vfs.Join(testDir, "sticky")
+
+	err := vfs.Mkdir(dir, 0o777)
+	RequireNoError(t, err, "Mkdir %s", dir)
+
+	err = vfs.Chmod(dir, fs.ModeSticky|0o777)
+	RequireNoError(t, err, "Chmod %s", dir)
+
+	testFile := vfs.Join(dir, "fileTest")
+	othFile := vfs.Join(dir, "fileOth")
+
+	ts.setUser(t, UsrTest)
+
+	err = vfs.WriteFile(testFile, nil, 0o666)
+	RequireNoError(t, err, "WriteFile %s", testFile)
+
+	ts.setUser(t, UsrOth)
+
+	err = vfs.WriteFile(othFile, nil, 0o666)
+	RequireNoError(t, err, "WriteFile %s", othFile)
 
-			wantMode := file.Mode &^ vfs.UMask()
-			if vfs.OSType() == avfs.OsWindows {
-				wantMode = avfs.DefaultFilePerm
-			}
+	t.Run("StickyNotOwner", func(t *testing.T) {
+		err = vfs.Remove(testFile)
+		AssertPathError(t, err).Op("remove").Path(testFile).Err(avfs.ErrOpNotPermitted).Test()
 
-			if wantMode != info.Mode() {
-				t.Errorf("Stat %s : want mode to be %s, got %s", file.Path, wantMode, info.Mode())
-			}
+		newPath := vfs.Join(dir, "renamed")
 
-			wantSize := int64(len(file.Content))
-			if wantSize != info.Size() {
-				t.Errorf("Lstat %s : want size to be %d, got %d", file.Path, wantSize, info.Size())
-			}
-		}
+		err = vfs.Rename(testFile, newPath)
+		AssertLinkError(t, err).Op("rename").Old(testFile).New(newPath).Err(avfs.ErrOpNotPermitted).Test()
+
+		err = vfs.Rename(othFile, testFile)
+		AssertLinkError(t, err).Op("rename").Old(othFile).New(testFile).Err(avfs.ErrOpNotPermitted).Test()
 	})
 
-	t.Run("StatSymlink", func(t *testing.T) {
-		for _, sl := range ts.sampleSymlinksEval(testDir) {
-			info, err := vfs.Stat(sl.NewPath)
-			if !AssertNoError(t, err, "Stat %s ", sl.NewPath) {
-				continue
-			}
+	t.Run("StickyOwner", func(t *testing.T) {
+		newPath := vfs.Join(dir, "othRenamed")
 
-			wantName := vfs.Base(sl.OldPath)
-			if sl.IsSymlink {
-				wantName = vfs.Base(sl.NewPath)
-			}
+		err = vfs.Rename(othFile, newPath)
+		RequireNoError(t, err, "Rename %s %s", othFile, newPath)
 
-			wantMode := sl.Mode
+		err = vfs.Remove(newPath)
+		RequireNoError(t, err, "Remove %s", newPath)
+	})
 
-			if wantName != info.Name() {
-				t.Errorf("Stat %s : want name to be %s, got %s", sl.NewPath, wantName, info.Name())
-			}
+	t.Run("StickyRemoveAll", func(t *testing.T) {
+		for i := range 5 {
+			path := vfs.Join(dir, "removeAll"+strconv.Itoa(i))
 
-			if ts.canTestPerm && wantMode != info.Mode() {
-				t.Errorf("Stat %s : want mode to be %s, got %s", sl.NewPath, wantMode, info.Mode())
-			}
+			err = vfs.WriteFile(path, nil, 0o666)
+			RequireNoError(t, err, "WriteFile %s", path)
 		}
-	})
 
-	t.Run("StatNonExistingFile", func(t *testing.T) {
-		nonExistingFile := ts.nonExistingFile(t, testDir)
+		err = vfs.RemoveAll(dir)
+		if err == nil {
+			t.Errorf("RemoveAll %s : want an error, got nil", dir)
+		}
 
-		_, err := vfs.Stat(nonExistingFile)
-		AssertPathError(t, err).OpStat().Path(nonExistingFile).
-			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
-			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+		entries, err := vfs.ReadDir(dir)
+		RequireNoError(t, err, "ReadDir %s", dir)
 
-		if !errors.Is(err, fs.ErrNotExist) {
-			t.Errorf("Stat %s : want errors.Is(err, fs.ErrNotExist) to be true, got false ", nonExistingFile)
+		if len(entries) != 1 || entries[0].Name() != vfs.Base(testFile) {
+			t.Errorf("ReadDir %s : want only %s to remain, got %d entries", dir, testFile, len(entries))
 		}
 	})
 
-	t.Run("StatSubDirOnFile", func(t *testing.T) {
-		subDirOnFile := vfs.Join(files[0].Path, "subDirOnFile")
+	t.Run("StickyAdmin", func(t *testing.T) {
+		ts.setInitUser(t)
 
-		_, err := vfs.Stat(subDirOnFile)
-		AssertPathError(t, err).OpStat().Path(subDirOnFile).
-			OSType(avfs.OsLinux).Err(avfs.ErrNotADirectory).Test().
-			OSType(avfs.OsWindows).Err(avfs.ErrWinPathNotFound).Test()
+		err = vfs.Remove(testFile)
+		RequireNoError(t, err, "Remove %s", testFile)
 	})
 }
 
@@ -2414,6 +4180,81 @@ func (ts *Suite) TestSymlink(t *testing.T, testDir string) {
 	})
 }
 
+// TestSymlinkLoop tests the detection of symbolic link loops.
+func (ts *Suite) TestSymlinkLoop(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	if !vfs.HasFeature(avfs.FeatSymlink) || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	loop1 := vfs.Join(testDir, "loop1")
+	loop2 := vfs.Join(testDir, "loop2")
+
+	err := ts.vfsSetup.Symlink(loop2, loop1)
+	RequireNoError(t, err, "Symlink %s %s", loop2, loop1)
+
+	err = ts.vfsSetup.Symlink(loop1, loop2)
+	RequireNoError(t, err, "Symlink %s %s", loop1, loop2)
+
+	t.Run("SymlinkLoopStat", func(t *testing.T) {
+		_, err := vfs.Stat(loop1)
+		AssertPathError(t, err).OpStat().Path(loop1).
+			OSType(avfs.OsLinux).Err(avfs.ErrTooManySymlinks).Test()
+
+		path := vfs.Join(loop1, "file")
+
+		_, err = vfs.Stat(path)
+		AssertPathError(t, err).OpStat().Path(path).
+			OSType(avfs.OsLinux).Err(avfs.ErrTooManySymlinks).Test()
+
+		_, err = vfs.Lstat(loop1)
+		RequireNoError(t, err, "Lstat %s", loop1)
+	})
+
+	t.Run("SymlinkLoopOpen", func(t *testing.T) {
+		_, err := vfs.OpenFile(loop1, os.O_RDONLY, 0)
+		AssertPathError(t, err).Op("open").Path(loop1).
+			OSType(avfs.OsLinux).Err(avfs.ErrTooManySymlinks).Test()
+	})
+
+	t.Run("SymlinkLoopEvalSymlinks", func(t *testing.T) {
+		_, err := vfs.EvalSymlinks(loop1)
+		if err == nil {
+			t.Errorf("EvalSymlinks %s : want error, got nil", loop1)
+		}
+	})
+
+	t.Run("SymlinkLoopLimit", func(t *testing.T) {
+		if vfs.OSType() != avfs.OsLinux {
+			return
+		}
+
+		// Linux follows at most 40 symbolic links (MAXSYMLINKS) to resolve a path.
+		const maxSymlinks = 40
+
+		link := ts.emptyFile(t, testDir)
+
+		for i := 1; i <= maxSymlinks+1; i++ {
+			newLink := vfs.Join(testDir, "chain"+strconv.Itoa(i))
+
+			err := ts.vfsSetup.Symlink(link, newLink)
+			RequireNoError(t, err, "Symlink %s %s", link, newLink)
+
+			link = newLink
+
+			_, err = vfs.Stat(link)
+			if i <= maxSymlinks {
+				RequireNoError(t, err, "Stat %s", link)
+
+				continue
+			}
+
+			AssertPathError(t, err).OpStat().Path(link).Err(avfs.ErrTooManySymlinks).Test()
+		}
+	})
+}
+
 // TestSub tests Sub function.
 func (ts *Suite) TestSub(t *testing.T, testDir string) {
 	vfs, ok := ts.vfsTest.(avfs.VFS)
@@ -2428,9 +4269,77 @@ func (ts *Suite) TestSub(t *testing.T, testDir string) {
 		return
 	}
 
-	t.Run("Sub", func(t *testing.T) {
-		//		vfsSub, err := vfs.Sub(testDir)
-		//		RequireNoError(t, "Sub "+testDir, err)
+	existingFile := ts.emptyFile(t, testDir)
+
+	t.Run("SubNonExisting", func(t *testing.T) {
+		nonExistingDir := vfs.Join(testDir, "nonExisting")
+
+		_, err := vfs.Sub(nonExistingDir)
+		AssertPathError(t, err).Op("sub").Path(nonExistingDir).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+
+	t.Run("SubOnFile", func(t *testing.T) {
+		_, err := vfs.Sub(existingFile)
+		AssertPathError(t, err).Op("sub").Path(existingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNotADirectory).Test()
+	})
+
+	subDir := vfs.Join(testDir, "sub")
+	ts.createDir(t, subDir, avfs.DefaultDirPerm)
+
+	vfsSub, err := vfs.Sub(subDir)
+	RequireNoError(t, err, "Sub %s", subDir)
+
+	root := avfs.VolumeName(vfs, subDir) + string(vfs.PathSeparator())
+
+	t.Run("SubReadFile", func(t *testing.T) {
+		data := []byte("sub")
+		fileName := vfs.Join(subDir, "file")
+
+		err = ts.vfsSetup.WriteFile(fileName, data, avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", fileName)
+
+		subFile := vfsSub.Join(root, "file")
+
+		got, err := vfsSub.ReadFile(subFile)
+		RequireNoError(t, err, "ReadFile %s", subFile)
+
+		if !bytes.Equal(got, data) {
+			t.Errorf("ReadFile : want content to be %s, got %s", data, got)
+		}
+	})
+
+	t.Run("SubNoEscape", func(t *testing.T) {
+		path := vfsSub.Join(root, "..", "..", "file")
+
+		_, err = vfsSub.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
+
+		entries, err := vfsSub.ReadDir(root + "..")
+		RequireNoError(t, err, "ReadDir %s", root+"..")
+
+		if len(entries) != 1 || entries[0].Name() != "file" {
+			t.Errorf("ReadDir : want only file in the root of the sub file system, got %v", entries)
+		}
+	})
+
+	t.Run("SubSymlinkNoEscape", func(t *testing.T) {
+		if !ts.vfsSetup.HasFeature(avfs.FeatSymlink) || !vfsSub.HasFeature(avfs.FeatSymlink) {
+			return
+		}
+
+		// The absolute symbolic link points to the root of the sub file system, not to the root of vfs.
+		link := vfs.Join(subDir, "link")
+
+		err = ts.vfsSetup.Symlink(root, link)
+		RequireNoError(t, err, "Symlink %s %s", root, link)
+
+		path := vfsSub.Join(root, "link", "file")
+
+		_, err = vfsSub.Stat(path)
+		RequireNoError(t, err, "Stat %s", path)
 	})
 }
 
@@ -2462,6 +4371,26 @@ func (ts *Suite) TestToSysStat(t *testing.T, testDir string) {
 	if sst.Nlink() != wantLink {
 		t.Errorf("ToSysStat : want Nlink to be %d, got %d", wantLink, sst.Nlink())
 	}
+
+	if vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	// The link count of a directory is 2 plus the number of its subdirectories.
+	dir := ts.existingDir(t, testDir)
+	ts.nlink(t, dir, 2)
+
+	for i := range 2 {
+		ts.createDir(t, vfs.Join(dir, "dir"+strconv.Itoa(i)), avfs.DefaultDirPerm)
+		ts.createFile(t, vfs.Join(dir, "file"+strconv.Itoa(i)), avfs.DefaultFilePerm)
+	}
+
+	ts.nlink(t, dir, 4)
+
+	err = vfs.Remove(vfs.Join(dir, "dir0"))
+	RequireNoError(t, err, "Remove %s", vfs.Join(dir, "dir0"))
+
+	ts.nlink(t, dir, 3)
 }
 
 // TestTruncate tests Truncate function.
@@ -2584,7 +4513,8 @@ func (ts *Suite) TestUMask(t *testing.T, _ string) {
 	}
 }
 
-// TestVolume tests VolumeAdd, VolumeDelete and VolumeList functions.
+// TestVolume tests VolumeAdd, VolumeDelete and VolumeList functions,
+// and VolumeInfo and VolumeSetInfo functions if the file system implements avfs.VolumeInfoManager.
 func (ts *Suite) TestVolume(t *testing.T, _ string) {
 	const testVolume = "Z:"
 
@@ -2595,6 +4525,8 @@ func (ts *Suite) TestVolume(t *testing.T, _ string) {
 		return
 	}
 
+	vim, hasInfo := vfs.(avfs.VolumeInfoManager)
+
 	if vfs.OSType() != avfs.OsWindows {
 		vl := vm.VolumeList()
 		if vl != nil {
@@ -2607,6 +4539,16 @@ func (ts *Suite) TestVolume(t *testing.T, _ string) {
 		err = vm.VolumeDelete(testVolume)
 		AssertPathError(t, err).Op("VolumeDelete").Path(testVolume).Err(avfs.ErrVolumeWindows).Test()
 
+		if !hasInfo {
+			return
+		}
+
+		_, err = vim.VolumeInfo(testVolume)
+		AssertPathError(t, err).Op("VolumeInfo").Path(testVolume).Err(avfs.ErrVolumeWindows).Test()
+
+		err = vim.VolumeSetInfo(testVolume, &avfs.VolumeInfo{Label: "Test"})
+		AssertPathError(t, err).Op("VolumeSetInfo").Path(testVolume).Err(avfs.ErrVolumeWindows).Test()
+
 		return
 	}
 
@@ -2632,6 +4574,261 @@ func (ts *Suite) TestVolume(t *testing.T, _ string) {
 			t.Errorf("VolumeList : want 1 volume, got %d = %v", len(vl), vl)
 		}
 	})
+
+	if !hasInfo {
+		return
+	}
+
+	t.Run("VolumeInfo", func(t *testing.T) {
+		err := vm.VolumeAdd(testVolume)
+		RequireNoError(t, err, "VolumeAdd %s", testVolume)
+
+		defer vm.VolumeDelete(testVolume) //nolint:errcheck // Ignore errors.
+
+		vi, err := vim.VolumeInfo(testVolume)
+		RequireNoError(t, err, "VolumeInfo %s", testVolume)
+
+		if vi.Name != testVolume || vi.FSType == "" {
+			t.Errorf("VolumeInfo : want name %s and a file system type, got %+v", testVolume, vi)
+		}
+
+		want := avfs.VolumeInfo{Name: testVolume, Label: "Test", FSType: "FAT32", Serial: 0x1234abcd}
+
+		err = vim.VolumeSetInfo(testVolume, &avfs.VolumeInfo{Name: "ignored", Label: want.Label, FSType: want.FSType, Serial: want.Serial})
+		RequireNoError(t, err, "VolumeSetInfo %s", testVolume)
+
+		vi, err = vim.VolumeInfo(testVolume)
+		RequireNoError(t, err, "VolumeInfo %s", testVolume)
+
+		if *vi != want {
+			t.Errorf("VolumeInfo : want %+v, got %+v", want, *vi)
+		}
+	})
+
+	t.Run("VolumeInfoNonExisting", func(t *testing.T) {
+		const nonExistingVolume = "Q:"
+
+		_, err := vim.VolumeInfo(nonExistingVolume)
+		AssertPathError(t, err).Op("VolumeInfo").Path(nonExistingVolume).Err(avfs.ErrVolumeNameInvalid).Test()
+
+		err = vim.VolumeSetInfo(nonExistingVolume, &avfs.VolumeInfo{})
+		AssertPathError(t, err).Op("VolumeSetInfo").Path(nonExistingVolume).Err(avfs.ErrVolumeNameInvalid).Test()
+	})
+}
+
+// TestWatchSize tests WatchSize function.
+func (ts *Suite) TestWatchSize(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	sw, ok := vfs.(avfs.SizeWatcher)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	nextEvent := func(t *testing.T, events <-chan avfs.SizeEvent, wantOld, wantNew int64) {
+		t.Helper()
+
+		select {
+		case e := <-events:
+			if e.OldSize != wantOld || e.NewSize != wantNew {
+				t.Errorf("WatchSize : want size change %d -> %d, got %d -> %d", wantOld, wantNew, e.OldSize, e.NewSize)
+			}
+		default:
+			t.Errorf("WatchSize : want size change %d -> %d, got no event", wantOld, wantNew)
+		}
+	}
+
+	noEvent := func(t *testing.T, events <-chan avfs.SizeEvent) {
+		t.Helper()
+
+		select {
+		case e, ok := <-events:
+			if ok {
+				t.Errorf("WatchSize : want no event, got %v", e)
+			}
+		default:
+		}
+	}
+
+	t.Run("WatchSize", func(t *testing.T) {
+		path := ts.emptyFile(t, testDir)
+
+		events, cancel, err := sw.WatchSize(path)
+		RequireNoError(t, err, "WatchSize %s", path)
+
+		defer cancel()
+
+		f, err := vfs.OpenFile(path, os.O_RDWR, 0)
+		RequireNoError(t, err, "OpenFile %s", path)
+
+		defer f.Close()
+
+		_, err = f.Write([]byte("AAA"))
+		RequireNoError(t, err, "Write %s", path)
+		nextEvent(t, events, 0, 3)
+
+		_, err = f.WriteAt([]byte("A"), 0)
+		RequireNoError(t, err, "WriteAt %s", path)
+		noEvent(t, events)
+
+		_, err = f.WriteAt([]byte("BBB"), 10)
+		RequireNoError(t, err, "WriteAt %s", path)
+		nextEvent(t, events, 3, 13)
+
+		err = f.Truncate(5)
+		RequireNoError(t, err, "Truncate %s", path)
+		nextEvent(t, events, 13, 5)
+
+		err = vfs.Truncate(path, 20)
+		RequireNoError(t, err, "Truncate %s", path)
+		nextEvent(t, events, 5, 20)
+
+		err = vfs.Truncate(path, 20)
+		RequireNoError(t, err, "Truncate %s", path)
+		noEvent(t, events)
+
+		cancel()
+
+		_, ok := <-events
+		if ok {
+			t.Errorf("WatchSize : want events channel to be closed after cancel")
+		}
+
+		cancel()
+	})
+
+	t.Run("WatchSizeRenamed", func(t *testing.T) {
+		path := ts.existingFile(t, testDir, nil)
+		newPath := vfs.Join(testDir, "WatchSizeRenamed.txt")
+
+		events, cancel, err := sw.WatchSize(path)
+		RequireNoError(t, err, "WatchSize %s", path)
+
+		defer cancel()
+
+		err = vfs.Rename(path, newPath)
+		RequireNoError(t, err, "Rename %s %s", path, newPath)
+
+		err = vfs.WriteFile(path, []byte("AAA"), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+		noEvent(t, events)
+
+		err = vfs.Truncate(newPath, 1)
+		RequireNoError(t, err, "Truncate %s", newPath)
+		nextEvent(t, events, 0, 1)
+	})
+
+	t.Run("WatchSizeOnDir", func(t *testing.T) {
+		_, _, err := sw.WatchSize(testDir)
+		AssertPathError(t, err).Op("watchsize").Path(testDir).
+			OSType(avfs.OsLinux).Err(avfs.ErrIsADirectory).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinIsADirectory).Test()
+	})
+
+	t.Run("WatchSizeNonExisting", func(t *testing.T) {
+		nonExistingFile := ts.nonExistingFile(t, testDir)
+
+		_, _, err := sw.WatchSize(nonExistingFile)
+		AssertPathError(t, err).Op("watchsize").Path(nonExistingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
+
+// TestWatcher tests NewWatcher function and the Watcher interface.
+func (ts *Suite) TestWatcher(t *testing.T, testDir string) {
+	vfs := ts.vfsTest
+
+	wm, ok := vfs.(avfs.WatcherMaker)
+	if !ok || vfs.HasFeature(avfs.FeatReadOnly) {
+		return
+	}
+
+	w, err := wm.NewWatcher()
+	RequireNoError(t, err, "NewWatcher")
+
+	defer w.Close()
+
+	// nextEvents waits for the events want, repeated events are ignored.
+	nextEvents := func(t *testing.T, want ...avfs.WatchEvent) {
+		t.Helper()
+
+		var got []avfs.WatchEvent
+
+		timeout := time.After(time.Second)
+
+		for len(got) < len(want) {
+			select {
+			case e := <-w.Events():
+				if len(got) == 0 || got[len(got)-1] != e {
+					got = append(got, e)
+				}
+			case <-timeout:
+				t.Fatalf("Events : want events %v, got %v", want, got)
+			}
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Events : want events %v, got %v", want, got)
+		}
+	}
+
+	dir := ts.existingDir(t, testDir)
+
+	t.Run("WatcherEvents", func(t *testing.T) {
+		err = w.Add(dir)
+		RequireNoError(t, err, "Add %s", dir)
+
+		path := vfs.Join(dir, "file")
+
+		err = vfs.WriteFile(path, []byte("data"), avfs.DefaultFilePerm)
+		RequireNoError(t, err, "WriteFile %s", path)
+
+		nextEvents(t, avfs.WatchEvent{Name: path, Op: avfs.WatchCreate}, avfs.WatchEvent{Name: path, Op: avfs.WatchWrite})
+
+		err = vfs.Chmod(path, 0o600)
+		RequireNoError(t, err, "Chmod %s", path)
+
+		nextEvents(t, avfs.WatchEvent{Name: path, Op: avfs.WatchChmod})
+
+		newPath := vfs.Join(dir, "newFile")
+
+		err = vfs.Rename(path, newPath)
+		RequireNoError(t, err, "Rename %s %s", path, newPath)
+
+		nextEvents(t, avfs.WatchEvent{Name: path, Op: avfs.WatchRename}, avfs.WatchEvent{Name: newPath, Op: avfs.WatchCreate})
+
+		err = vfs.Remove(newPath)
+		RequireNoError(t, err, "Remove %s", newPath)
+
+		nextEvents(t, avfs.WatchEvent{Name: newPath, Op: avfs.WatchRemove})
+	})
+
+	t.Run("WatcherRemove", func(t *testing.T) {
+		err = w.Remove(dir)
+		RequireNoError(t, err, "Remove %s", dir)
+
+		err = w.Remove(dir)
+		AssertPathError(t, err).Op("inotify_rm_watch").Path(dir).
+			OSType(avfs.OsLinux).Err(avfs.ErrInvalidArgument).Test()
+	})
+
+	t.Run("WatcherNonExisting", func(t *testing.T) {
+		path := ts.nonExistingFile(t, testDir)
+
+		err = w.Add(path)
+		AssertPathError(t, err).Op("inotify_add_watch").Path(path).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test()
+	})
+
+	t.Run("WatcherClose", func(t *testing.T) {
+		err = w.Close()
+		RequireNoError(t, err, "Close")
+
+		if _, ok := <-w.Events(); ok {
+			t.Errorf("Events : want channel to be closed")
+		}
+	})
 }
 
 // TestWriteFile tests WriteFile function.
@@ -2729,3 +4926,11 @@ func (ts *Suite) TestWriteString(t *testing.T, testDir string) {
 		}
 	})
 }
+
+// Int63 returns the constant number of the source.
+func (cs constSource) Int63() int64 {
+	return int64(cs)
+}
+
+// Seed does nothing, the source is constant.
+func (constSource) Seed(int64) {}
@@ -84,6 +84,7 @@ func main() {
 	_ = vfs.SetUMask(0)
 	_ = vfs.SetUser(u)
 	_ = vfs.SetUserByName("")
+	_ = vfs.SetUserByUid(0, 0)
 	_, _ = vfs.Sub("")
 	_ = vfs.TempDir()
 	_ = vfs.ToSlash(tmpDir)
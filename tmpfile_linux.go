@@ -0,0 +1,26 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+//go:build linux
+
+package avfs
+
+import "golang.org/x/sys/unix"
+
+// O_TMPFILE is the Linux open flag (O_DIRECTORY included) creating an unnamed temporary file
+// in the directory given to OpenFile, the file is removed when it is closed.
+// Its value depends on the architecture (0x404000 on arm and ppc64 for example).
+// It is supported by OsFS on Linux and by MemFS with a Linux OS type (see CreateAnonymousTemp).
+const O_TMPFILE = unix.O_TMPFILE //nolint:revive,stylecheck // Same name as the Linux open flag.
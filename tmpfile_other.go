@@ -0,0 +1,24 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+//go:build !linux
+
+package avfs
+
+// O_TMPFILE is the Linux open flag (O_DIRECTORY included) creating an unnamed temporary file
+// in the directory given to OpenFile, the file is removed when it is closed.
+// Outside Linux, it takes the value used on amd64.
+// It is supported by MemFS with a Linux OS type (see CreateAnonymousTemp).
+const O_TMPFILE = 0x410000 //nolint:revive,stylecheck // Same name as the Linux open flag.
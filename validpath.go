@@ -0,0 +1,196 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PathReason is the reason why a path is not valid.
+type PathReason uint8
+
+//go:generate stringer -type PathReason -linecomment -output pathreason_string.go
+
+const (
+	PathEmpty          PathReason = iota + 1 // empty path
+	PathTooLong                              // path too long
+	PathNameTooLong                          // file name too long
+	PathInvalidChar                          // invalid character
+	PathReservedName                         // reserved name
+	PathEmptyComponent                       // empty path component
+)
+
+const (
+	linuxMaxPath   = 4095 // linuxMaxPath is the maximum length of a Linux path (PATH_MAX without the trailing NUL).
+	windowsMaxPath = 259  // windowsMaxPath is the maximum length of a Windows path (MAX_PATH without the trailing NUL).
+	maxName        = 255  // maxName is the maximum length of a file name.
+)
+
+// InvalidPathError is the error returned by ValidatePath.
+type InvalidPathError struct {
+	Path   string     // Path is the validated path.
+	Name   string     // Name is the path component responsible for the error, it is empty if the whole path is.
+	Reason PathReason // Reason is the reason why the path is not valid.
+}
+
+// Error returns the error message.
+func (e *InvalidPathError) Error() string {
+	msg := "invalid path " + strconv.Quote(e.Path) + ": " + e.Reason.String()
+	if e.Name != "" {
+		msg += " " + strconv.Quote(e.Name)
+	}
+
+	return msg
+}
+
+// ValidatePath checks that path is a valid path for the operating system osType.
+// The path is rejected if it is empty, too long, if one of its components is too long,
+// contains an invalid character, is a reserved name (Windows only) or is empty (consecutive separators).
+// A trailing separator is allowed.
+// If the path is not valid, the error will be of type *InvalidPathError.
+func ValidatePath(osType OSType, path string) error {
+	if path == "" {
+		return &InvalidPathError{Path: path, Reason: PathEmpty}
+	}
+
+	maxPath, seps := linuxMaxPath, "/"
+	if osType == OsWindows {
+		maxPath, seps = windowsMaxPath, `\/`
+	}
+
+	if len(path) > maxPath {
+		return &InvalidPathError{Path: path, Reason: PathTooLong}
+	}
+
+	start := 0
+
+	if osType == OsWindows {
+		switch {
+		case len(path) >= 2 && path[1] == ':' && isASCIILetter(path[0]):
+			start = 2
+		case len(path) >= 2 && strings.IndexByte(seps, path[0]) >= 0 && strings.IndexByte(seps, path[1]) >= 0:
+			start = 2 // UNC path.
+		}
+	}
+
+	if start < len(path) && strings.IndexByte(seps, path[start]) >= 0 {
+		start++
+	}
+
+	rest := path[start:]
+	for rest != "" {
+		name := rest
+
+		i := strings.IndexAny(rest, seps)
+		if i >= 0 {
+			name, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = ""
+		}
+
+		if name == "" {
+			return &InvalidPathError{Path: path, Reason: PathEmptyComponent}
+		}
+
+		if reason := validateName(osType, name); reason != 0 {
+			return &InvalidPathError{Path: path, Name: name, Reason: reason}
+		}
+	}
+
+	return nil
+}
+
+// CheckPathLen checks the length of path and of its components for the operating system osType,
+// the maximum length of a path is PATH_MAX on Linux and MAX_PATH on Windows, the one of a name is 255.
+// It returns ErrNameTooLong (ErrWinFilenameExcedRange on Windows) if one of the limits is exceeded, nil otherwise.
+func CheckPathLen(osType OSType, path string) error {
+	maxPath, seps, err := linuxMaxPath, "/", error(ErrNameTooLong)
+	if osType == OsWindows {
+		maxPath, seps, err = windowsMaxPath, `\/`, ErrWinFilenameExcedRange
+	}
+
+	if len(path) > maxPath {
+		return err
+	}
+
+	for len(path) > maxName {
+		i := strings.IndexAny(path, seps)
+		if i < 0 || i > maxName {
+			return err
+		}
+
+		path = path[i+1:]
+	}
+
+	return nil
+}
+
+// validateName checks a single path component and returns the reason why it is not valid or 0.
+func validateName(osType OSType, name string) PathReason {
+	if len(name) > maxName {
+		return PathNameTooLong
+	}
+
+	if osType != OsWindows {
+		if strings.IndexByte(name, 0) >= 0 {
+			return PathInvalidChar
+		}
+
+		return 0
+	}
+
+	for i := range len(name) {
+		if c := name[i]; c < ' ' || strings.IndexByte(`<>:"|?*`, c) >= 0 {
+			return PathInvalidChar
+		}
+	}
+
+	if isWindowsReservedName(name) {
+		return PathReservedName
+	}
+
+	return 0
+}
+
+// isWindowsReservedName returns true if name is a reserved device name on Windows,
+// with or without extension ("NUL", "com1.txt").
+func isWindowsReservedName(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	base = strings.TrimRight(base, " ")
+
+	switch strings.ToUpper(base) {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+
+	if len(base) != 4 {
+		return false
+	}
+
+	switch strings.ToUpper(base[:3]) {
+	case "COM", "LPT":
+		return base[3] >= '1' && base[3] <= '9'
+	default:
+		return false
+	}
+}
+
+// isASCIILetter returns true if c is an ASCII letter.
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,109 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/avfs/avfs"
+)
+
+// TestValidatePath tests ValidatePath function.
+func TestValidatePath(t *testing.T) {
+	longName := strings.Repeat("a", 256)
+
+	tests := []struct {
+		osType avfs.OSType
+		path   string
+		name   string
+		reason avfs.PathReason
+	}{
+		{osType: avfs.OsLinux, path: "/home/user/file.txt"},
+		{osType: avfs.OsLinux, path: "relative/dir/"},
+		{osType: avfs.OsLinux, path: "/a/CON/b:c"},
+		{osType: avfs.OsLinux, path: "", reason: avfs.PathEmpty},
+		{osType: avfs.OsLinux, path: "/" + strings.Repeat("a/", 2048), reason: avfs.PathTooLong},
+		{osType: avfs.OsLinux, path: "/tmp/" + longName, name: longName, reason: avfs.PathNameTooLong},
+		{osType: avfs.OsLinux, path: "/tmp/a\x00b", name: "a\x00b", reason: avfs.PathInvalidChar},
+		{osType: avfs.OsLinux, path: "/tmp//file", reason: avfs.PathEmptyComponent},
+		{osType: avfs.OsWindows, path: `C:\Users\user\file.txt`},
+		{osType: avfs.OsWindows, path: `\\server\share\dir\`},
+		{osType: avfs.OsWindows, path: `C:file/dir`},
+		{osType: avfs.OsWindows, path: `C:\CONSOLE\com0`},
+		{osType: avfs.OsWindows, path: `C:\` + strings.Repeat("a", 258), reason: avfs.PathTooLong},
+		{osType: avfs.OsWindows, path: `C:\dir\a?b`, name: "a?b", reason: avfs.PathInvalidChar},
+		{osType: avfs.OsWindows, path: `C:\dir\a:b`, name: "a:b", reason: avfs.PathInvalidChar},
+		{osType: avfs.OsWindows, path: `C:\dir\a` + "\x1f", name: "a\x1f", reason: avfs.PathInvalidChar},
+		{osType: avfs.OsWindows, path: `C:\dir\nul`, name: "nul", reason: avfs.PathReservedName},
+		{osType: avfs.OsWindows, path: `C:\dir\Com1.txt\file`, name: "Com1.txt", reason: avfs.PathReservedName},
+		{osType: avfs.OsWindows, path: `C:\dir\LPT9 .log`, name: "LPT9 .log", reason: avfs.PathReservedName},
+		{osType: avfs.OsWindows, path: `C:\dir\/file`, reason: avfs.PathEmptyComponent},
+	}
+
+	for _, test := range tests {
+		err := avfs.ValidatePath(test.osType, test.path)
+		if test.reason == 0 {
+			if err != nil {
+				t.Errorf("ValidatePath (%s) %q : want error to be nil, got %v", test.osType, test.path, err)
+			}
+
+			continue
+		}
+
+		var ipe *avfs.InvalidPathError
+		if !errors.As(err, &ipe) {
+			t.Errorf("ValidatePath (%s) %q : want error to be *InvalidPathError, got %v", test.osType, test.path, err)
+
+			continue
+		}
+
+		if ipe.Path != test.path || ipe.Name != test.name || ipe.Reason != test.reason {
+			t.Errorf("ValidatePath (%s) %q : want (%q, %s), got (%q, %s)",
+				test.osType, test.path, test.name, test.reason, ipe.Name, ipe.Reason)
+		}
+	}
+}
+
+// TestCheckPathLen tests CheckPathLen function.
+func TestCheckPathLen(t *testing.T) {
+	maxName := strings.Repeat("a", 255)
+	longName := maxName + "a"
+
+	tests := []struct {
+		osType avfs.OSType
+		path   string
+		err    error
+	}{
+		{osType: avfs.OsLinux, path: "/tmp/" + maxName + "/" + maxName},
+		{osType: avfs.OsLinux, path: maxName},
+		{osType: avfs.OsLinux, path: "/tmp/" + longName, err: avfs.ErrNameTooLong},
+		{osType: avfs.OsLinux, path: longName + "/file", err: avfs.ErrNameTooLong},
+		{osType: avfs.OsLinux, path: strings.Repeat("a/", 2048), err: avfs.ErrNameTooLong},
+		{osType: avfs.OsWindows, path: `C:\` + maxName},
+		{osType: avfs.OsWindows, path: `C:/tmp/` + longName, err: avfs.ErrWinFilenameExcedRange},
+		{osType: avfs.OsWindows, path: `C:\` + strings.Repeat(`a\`, 129), err: avfs.ErrWinFilenameExcedRange},
+	}
+
+	for _, test := range tests {
+		err := avfs.CheckPathLen(test.osType, test.path)
+		if err != test.err {
+			t.Errorf("CheckPathLen (%s) %.20q : want error to be %v, got %v", test.osType, test.path, test.err, err)
+		}
+	}
+}
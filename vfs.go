@@ -22,8 +22,12 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"unicode/utf8"
 )
 
 // Abs returns an absolute representation of path.
@@ -36,6 +40,30 @@ func Abs[T VFSBase](vfs T, path, curDir string) (string, error) {
 		return vfs.Clean(path), nil
 	}
 
+	if vfs.OSType() == OsWindows {
+		vol := VolumeName(vfs, path)
+
+		switch {
+		case vol != "":
+			// Drive relative path like "D:file.txt".
+			dir := vol + string(vfs.PathSeparator())
+
+			switch {
+			case strings.EqualFold(VolumeName(vfs, curDir), vol):
+				dir = curDir
+			default:
+				if dr, ok := any(vfs).(DriveCurDirReader); ok {
+					dir = dr.DriveCurDir(vol)
+				}
+			}
+
+			return vfs.Join(dir, path[len(vol):]), nil
+		case path != "" && IsPathSeparator(vfs, path[0]):
+			// Path relative to the root of the current drive like "\dir".
+			return vfs.Join(VolumeName(vfs, curDir), path), nil
+		}
+	}
+
 	return vfs.Join(curDir, path), nil
 }
 
@@ -90,6 +118,8 @@ func Create[T VFSBase](vfs T, name string) (File, error) {
 // If pattern includes a "*", the random string replaces the last "*".
 // If dir is the empty string, CreateTemp uses the default directory for temporary files, as returned by TempDir.
 // Multiple programs or goroutines calling CreateTemp simultaneously will not choose the same file.
+// A new random name is tried when the file already exists, after TempMaxTries names
+// CreateTemp returns a *PathError wrapping fs.ErrExist with the path dir/prefix*suffix.
 // The caller can use the file's Name method to find the pathname of the file.
 // It is the caller's responsibility to remove the file when it is no longer needed.
 func CreateTemp[T VFSBase](vfs T, dir, pattern string) (File, error) {
@@ -99,7 +129,7 @@ func CreateTemp[T VFSBase](vfs T, dir, pattern string) (File, error) {
 		dir = TempDir(vfs)
 	}
 
-	prefix, suffix, err := prefixAndSuffix(vfs, pattern)
+	prefix, suffix, err := SplitPattern(vfs, pattern)
 	if err != nil {
 		return nil, &fs.PathError{Op: op, Path: pattern, Err: err}
 	}
@@ -109,22 +139,112 @@ func CreateTemp[T VFSBase](vfs T, dir, pattern string) (File, error) {
 	try := 0
 
 	for {
-		name := prefix + nextRandom() + suffix
+		name := prefix + nextTempRandom(vfs) + suffix
 
 		f, err := vfs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
 		if IsExist(err) {
 			try++
-			if try < 10000 {
+			if try < TempMaxTries {
 				continue
 			}
 
-			return nil, &fs.PathError{Op: op, Path: dir + string(vfs.PathSeparator()) + prefix + "*" + suffix, Err: fs.ErrExist}
+			return nil, &fs.PathError{Op: op, Path: prefix + "*" + suffix, Err: fs.ErrExist}
 		}
 
 		return f, err
 	}
 }
 
+// CreateAnonymousTemp creates a new unnamed temporary file in the directory dir,
+// opens the file for reading and writing, and returns the resulting file.
+// If dir is the empty string, CreateAnonymousTemp uses the default directory for temporary files, as returned by TempDir.
+// The file is opened with the O_TMPFILE flag if the file system supports it,
+// otherwise it is created by CreateTemp and removed immediately, or when it is closed
+// if it can't be removed while open (Windows).
+func CreateAnonymousTemp[T VFSBase](vfs T, dir string) (File, error) {
+	if dir == "" {
+		dir = TempDir(vfs)
+	}
+
+	if vfs.OSType() == OsLinux {
+		f, err := vfs.OpenFile(dir, os.O_RDWR|O_TMPFILE, 0o600)
+
+		// Like open(2) suggests, fall back to a named file if O_TMPFILE is not supported.
+		if err == nil || !isTmpFileNotSupported(err) {
+			return f, err
+		}
+	}
+
+	f, err := CreateTemp(vfs, dir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = vfs.Remove(f.Name()); err != nil {
+		return &anonymousFile{File: f, remove: vfs.Remove}, nil
+	}
+
+	return f, nil
+}
+
+// isTmpFileNotSupported returns true if err is an error of open(2) when the O_TMPFILE flag is not supported:
+// EISDIR by kernels ignoring the flag, EOPNOTSUPP or EINVAL by file systems not supporting it.
+func isTmpFileNotSupported(err error) bool {
+	err = unwrapPathError(err)
+	if err == ErrIsADirectory || err == ErrNotSupported || err == ErrInvalidArgument {
+		return true
+	}
+
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return false
+	}
+
+	switch uint64(errno) {
+	case uint64(ErrIsADirectory), uint64(ErrNotSupported), uint64(ErrInvalidArgument):
+		return true
+	default:
+		return false
+	}
+}
+
+// anonymousFile is a temporary file created by CreateAnonymousTemp which is removed when closed.
+type anonymousFile struct {
+	File
+	remove func(name string) error // remove is the Remove function of the file system of the file.
+}
+
+// Close closes the file and removes it.
+func (f *anonymousFile) Close() error {
+	err := f.File.Close()
+	if err != nil {
+		return err
+	}
+
+	return f.remove(f.Name())
+}
+
+// DotElem returns the last element of path if it is "." or "..", an empty string otherwise.
+// Trailing path separators are ignored.
+func DotElem[T VFSBase](vfs T, path string) string {
+	end := len(path)
+	for end > 0 && IsPathSeparator(vfs, path[end-1]) {
+		end--
+	}
+
+	start := end
+	for start > 0 && !IsPathSeparator(vfs, path[start-1]) {
+		start--
+	}
+
+	switch elem := path[start:end]; elem {
+	case ".", "..":
+		return elem
+	default:
+		return ""
+	}
+}
+
 // FromUnixPath returns valid path for Unix or Windows from a unix path.
 // For Windows systems, absolute paths are prefixed with the default volume
 // and relative paths are preserved.
@@ -148,12 +268,49 @@ func FromUnixPath[T VFSBase](vfs T, path string) string {
 // Glob ignores file system errors such as I/O errors reading directories.
 // The only possible returned error is ErrBadPattern, when pattern
 // is malformed.
+//
+// The matches are sorted in lexical order of their path elements :
+// by directory first, then by name, like filepath.Glob.
+// All file systems return the matches of Glob in this order.
+// GlobWithOptions supports also the recursive patterns "**" and the brace expansion.
 func Glob[T VFSBase](vfs T, pattern string) (matches []string, err error) {
+	return GlobWithOptions(vfs, pattern, nil)
+}
+
+// GlobOptions defines the options of GlobWithOptions.
+type GlobOptions struct {
+	Unsorted bool // Unsorted skips sorting the names of directories, the order of the matches is unspecified.
+
+	// Recursive matches zero or more directories with a "**" path element, "dir/**" matches dir and all
+	// the files and directories under it. The symbolic links to directories are not followed.
+	Recursive bool
+
+	// Braces expands the alternatives of the pattern separated by commas between braces before matching,
+	// "*.{go,md}" matches the same files as "*.go" and "*.md".
+	Braces bool
+}
+
+// GlobWithOptions returns the names of all files matching pattern like Glob with the selected GlobOptions.
+// If opts is nil, the default options of Glob are used.
+// The recursive patterns and the brace expansion are implemented in the same way for all file systems.
+func GlobWithOptions[T VFSBase](vfs T, pattern string, opts *GlobOptions) (matches []string, err error) {
+	if opts == nil {
+		opts = &GlobOptions{}
+	}
+
+	if opts.Braces {
+		return globBraces(vfs, pattern, opts)
+	}
+
 	// Check pattern is well-formed.
 	if _, err = Match(vfs, pattern, ""); err != nil {
 		return nil, err
 	}
 
+	if opts.Recursive && hasDoubleStar(vfs, pattern) {
+		return globRecursive(vfs, pattern, opts)
+	}
+
 	if !hasMeta(vfs, pattern) {
 		if _, err = vfs.Lstat(pattern); err != nil {
 			return nil, nil
@@ -172,7 +329,7 @@ func Glob[T VFSBase](vfs T, pattern string) (matches []string, err error) {
 	}
 
 	if !hasMeta(vfs, dir[volumeLen:]) {
-		return glob(vfs, dir, file, nil)
+		return glob(vfs, dir, file, nil, opts)
 	}
 
 	// Prevent infinite recursion. See issue 15879.
@@ -182,13 +339,13 @@ func Glob[T VFSBase](vfs T, pattern string) (matches []string, err error) {
 
 	var m []string
 
-	m, err = Glob(vfs, dir)
+	m, err = GlobWithOptions(vfs, dir, opts)
 	if err != nil {
 		return
 	}
 
 	for _, d := range m {
-		matches, err = glob(vfs, d, file, matches)
+		matches, err = glob(vfs, d, file, matches, opts)
 		if err != nil {
 			return
 		}
@@ -200,8 +357,8 @@ func Glob[T VFSBase](vfs T, pattern string) (matches []string, err error) {
 // glob searches for files matching pattern in the directory dir
 // and appends them to matches. If the directory cannot be
 // opened, it returns the existing matches. New matches are
-// added in lexicographical order.
-func glob[T VFSBase](vfs T, dir, pattern string, matches []string) (m []string, e error) {
+// added in lexicographical order, unless opts.Unsorted is set.
+func glob[T VFSBase](vfs T, dir, pattern string, matches []string, opts *GlobOptions) (m []string, e error) {
 	m = matches
 
 	fi, err := vfs.Stat(dir)
@@ -221,7 +378,9 @@ func glob[T VFSBase](vfs T, dir, pattern string, matches []string) (m []string,
 	defer d.Close()
 
 	names, _ := d.Readdirnames(-1)
-	sort.Strings(names)
+	if !opts.Unsorted {
+		sort.Strings(names)
+	}
 
 	for _, n := range names {
 		matched, err := Match(vfs, pattern, n)
@@ -249,6 +408,239 @@ func hasMeta[T VFSBase](vfs T, path string) bool {
 	return strings.ContainsAny(path, magicChars)
 }
 
+// globBraces returns the names of all files matching one of the patterns of the brace expansion of pattern.
+// The matches are sorted and without duplicates, unless opts.Unsorted is set.
+func globBraces[T VFSBase](vfs T, pattern string, opts *GlobOptions) (matches []string, err error) {
+	patternOpts := *opts
+	patternOpts.Braces = false
+
+	for _, p := range expandBraces(vfs, pattern) {
+		m, err := GlobWithOptions(vfs, p, &patternOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		matches = append(matches, m...)
+	}
+
+	return sortMatches(vfs, matches, opts), nil
+}
+
+// expandBraces returns the patterns resulting from the expansion of the first group of alternatives
+// between braces of pattern, recursively. The braces without a comma or without a closing brace
+// are not expanded. On Linux, a brace escaped by a backslash is not expanded either.
+func expandBraces[T VFSBase](vfs T, pattern string) []string {
+	escape := vfs.OSType() != OsWindows
+	start, depth, inClass := -1, 0, false
+
+	var commas []int
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch {
+		case c == '\\' && escape:
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+		case c == '{':
+			if depth == 0 {
+				start = i
+				commas = commas[:0]
+			}
+
+			depth++
+		case c == ',' && depth == 1:
+			commas = append(commas, i)
+		case c == '}' && depth > 0:
+			depth--
+			if depth != 0 {
+				continue
+			}
+
+			if len(commas) == 0 {
+				start = -1
+
+				continue
+			}
+
+			prefix, suffix := pattern[:start], pattern[i+1:]
+			bounds := append(append([]int{start}, commas...), i)
+
+			var patterns []string
+
+			for j := 1; j < len(bounds); j++ {
+				alt := pattern[bounds[j-1]+1 : bounds[j]]
+				patterns = append(patterns, expandBraces(vfs, prefix+alt+suffix)...)
+			}
+
+			return patterns
+		}
+	}
+
+	return []string{pattern}
+}
+
+// hasDoubleStar reports whether one of the path elements of pattern is "**".
+func hasDoubleStar[T VFSBase](vfs T, pattern string) bool {
+	return slices.Contains(splitElems(vfs, pattern), "**")
+}
+
+// splitElems returns the non-empty path elements of path.
+func splitElems[T VFSBase](vfs T, path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r < utf8.RuneSelf && IsPathSeparator(vfs, uint8(r))
+	})
+}
+
+// globRecursive returns the names of all files matching a pattern containing "**" path elements.
+func globRecursive[T VFSBase](vfs T, pattern string, opts *GlobOptions) (matches []string, err error) {
+	dir := pattern[:VolumeNameLen(vfs, pattern)]
+	rest := pattern[len(dir):]
+
+	if rest != "" && IsPathSeparator(vfs, rest[0]) {
+		dir += string(vfs.PathSeparator())
+	}
+
+	// The leading path elements without meta characters are not matched.
+	elems := splitElems(vfs, rest)
+	for len(elems) > 0 && !hasMeta(vfs, elems[0]) {
+		dir = Join(vfs, dir, elems[0])
+		elems = elems[1:]
+	}
+
+	if dir != "" {
+		if _, err = vfs.Lstat(dir); err != nil {
+			return nil, nil //nolint:nilerr // Glob ignores file system errors.
+		}
+	}
+
+	matches, err = globElems(vfs, dir, elems, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortMatches(vfs, matches, opts), nil
+}
+
+// globElems appends to matches the names of the files under dir matching the path elements of a pattern.
+// An empty dir is the current directory.
+func globElems[T VFSBase](vfs T, dir string, elems, matches []string) ([]string, error) {
+	if len(elems) == 0 {
+		return append(matches, dir), nil
+	}
+
+	join := func(name string) string {
+		if dir == "" {
+			return name
+		}
+
+		return Join(vfs, dir, name)
+	}
+
+	readDir := func() []fs.DirEntry {
+		name := dir
+		if name == "" {
+			name = "."
+		}
+
+		entries, _ := vfs.ReadDir(name)
+
+		return entries
+	}
+
+	elem := elems[0]
+
+	switch {
+	case elem == "**":
+		if len(elems) == 1 {
+			if dir != "" {
+				matches = append(matches, dir)
+			}
+
+			for _, entry := range readDir() {
+				if entry.IsDir() {
+					var err error
+
+					matches, err = globElems(vfs, join(entry.Name()), elems, matches)
+					if err != nil {
+						return nil, err
+					}
+
+					continue
+				}
+
+				matches = append(matches, join(entry.Name()))
+			}
+
+			return matches, nil
+		}
+
+		matches, err := globElems(vfs, dir, elems[1:], matches)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range readDir() {
+			if entry.IsDir() {
+				matches, err = globElems(vfs, join(entry.Name()), elems, matches)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return matches, nil
+	case !hasMeta(vfs, elem):
+		path := join(elem)
+
+		if _, err := vfs.Lstat(path); err != nil {
+			return matches, nil //nolint:nilerr // Glob ignores file system errors.
+		}
+
+		return globElems(vfs, path, elems[1:], matches)
+	default:
+		for _, entry := range readDir() {
+			matched, err := Match(vfs, elem, entry.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			if matched {
+				matches, err = globElems(vfs, join(entry.Name()), elems[1:], matches)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return matches, nil
+	}
+}
+
+// sortMatches sorts the matches of Glob in lexical order of their path elements and removes the duplicates,
+// unless opts.Unsorted is set.
+func sortMatches[T VFSBase](vfs T, matches []string, opts *GlobOptions) []string {
+	if opts.Unsorted || len(matches) < 2 {
+		return matches
+	}
+
+	cmp := func(a, b string) int {
+		return cmpSlashPath(ToSlash(vfs, a), ToSlash(vfs, b))
+	}
+
+	slices.SortFunc(matches, cmp)
+
+	return slices.CompactFunc(matches, func(a, b string) bool { return cmp(a, b) == 0 })
+}
+
+// HasTrailingSeparator returns true if path ends with a path separator.
+func HasTrailingSeparator[T VFSBase](vfs T, path string) bool {
+	return path != "" && IsPathSeparator(vfs, path[len(path)-1])
+}
+
 // HomeDir returns the home directory of the file system.
 func HomeDir[T VFSBase](vfs T, basePath string) string {
 	switch vfs.OSType() {
@@ -279,6 +671,17 @@ func HomeDirPerm() fs.FileMode {
 	return 0o700
 }
 
+// IsDirPath returns true if path ends with a path separator or if its last element is "." or "..".
+// Such a path can only name a directory.
+// On Windows, "." and ".." are resolved lexically, only a trailing path separator is considered.
+func IsDirPath[T VFSBase](vfs T, path string) bool {
+	if vfs.OSType() == OsWindows {
+		return HasTrailingSeparator(vfs, path)
+	}
+
+	return HasTrailingSeparator(vfs, path) || DotElem(vfs, path) != ""
+}
+
 // IsExist returns a boolean indicating whether the error is known to report
 // that a file or directory already exists. It is satisfied by ErrExist as
 // well as some syscall errors.
@@ -313,6 +716,8 @@ func joinPath[T VFSBase](vfs T, dir, name string) string {
 // If pattern includes a "*", the random string replaces the last "*" instead.
 // If dir is the empty string, MkdirTemp uses the default directory for temporary files, as returned by TempDir.
 // Multiple programs or goroutines calling MkdirTemp simultaneously will not choose the same directory.
+// A new random name is tried when the directory already exists, after TempMaxTries names
+// MkdirTemp returns a *PathError wrapping fs.ErrExist with the path dir/prefix*suffix.
 // It is the caller's responsibility to remove the directory when it is no longer needed.
 func MkdirTemp[T VFSBase](vfs T, dir, pattern string) (string, error) {
 	const op = "mkdirtemp"
@@ -321,7 +726,7 @@ func MkdirTemp[T VFSBase](vfs T, dir, pattern string) (string, error) {
 		dir = vfs.TempDir()
 	}
 
-	prefix, suffix, err := prefixAndSuffix(vfs, pattern)
+	prefix, suffix, err := SplitPattern(vfs, pattern)
 	if err != nil {
 		return "", &fs.PathError{Op: op, Path: pattern, Err: err}
 	}
@@ -330,7 +735,7 @@ func MkdirTemp[T VFSBase](vfs T, dir, pattern string) (string, error) {
 	try := 0
 
 	for {
-		name := prefix + nextRandom() + suffix
+		name := prefix + nextTempRandom(vfs) + suffix
 
 		err := vfs.Mkdir(name, 0o700)
 		if err == nil {
@@ -339,11 +744,11 @@ func MkdirTemp[T VFSBase](vfs T, dir, pattern string) (string, error) {
 
 		if IsExist(err) {
 			try++
-			if try < 10000 {
+			if try < TempMaxTries {
 				continue
 			}
 
-			return "", &fs.PathError{Op: op, Path: dir + string(vfs.PathSeparator()) + prefix + "*" + suffix, Err: fs.ErrExist}
+			return "", &fs.PathError{Op: op, Path: prefix + "*" + suffix, Err: fs.ErrExist}
 		}
 
 		if IsNotExist(err) {
@@ -400,22 +805,25 @@ func MkSystemDirs[T VFSBase](vfs T, dirs []DirInfo) error {
 	return nil
 }
 
-// prefixAndSuffix splits pattern by the last wildcard "*", if applicable,
-// returning prefix as the part before "*" and suffix as the part after "*".
-func prefixAndSuffix[T VFSBase](vfs T, pattern string) (prefix, suffix string, err error) {
-	for i := range len(pattern) {
-		if IsPathSeparator(vfs, pattern[i]) {
-			return "", "", ErrPatternHasSeparator
-		}
+// OwnerOf returns the names of the user and the group owning a file, like "ls -l".
+// The uid and the gid of info are resolved by the identity manager of the file system,
+// if a name can't be resolved, the numeric id is returned instead.
+func OwnerOf[T VFSBase](vfs T, info fs.FileInfo) (userName, groupName string) {
+	sst := vfs.ToSysStat(info)
+	uid, gid := sst.Uid(), sst.Gid()
+	idm := vfs.Idm()
+
+	userName = strconv.Itoa(uid)
+	if u, err := idm.LookupUserId(uid); err == nil {
+		userName = u.Name()
 	}
 
-	if pos := strings.LastIndexByte(pattern, '*'); pos != -1 {
-		prefix, suffix = pattern[:pos], pattern[pos+1:]
-	} else {
-		prefix = pattern
+	groupName = strconv.Itoa(gid)
+	if g, err := idm.LookupGroupId(gid); err == nil {
+		groupName = g.Name()
 	}
 
-	return prefix, suffix, nil
+	return userName, groupName
 }
 
 // ReadDir reads the named directory,
@@ -511,6 +919,32 @@ func SetUserByName[T VFSBase](vfs T, name string) error {
 	return err
 }
 
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user
+// (see NewEphemeralUser) not registered in the identity manager is used.
+func SetUserByUid[T VFSBase](vfs T, uid, gid int) error {
+	if !vfs.HasFeature(FeatIdentityMgr) {
+		return ErrPermDenied
+	}
+
+	if u := vfs.User(); u.Uid() == uid && u.Gid() == gid {
+		return nil
+	}
+
+	u, err := vfs.Idm().LookupUserId(uid)
+	switch err.(type) {
+	case nil:
+		if u.Gid() == gid {
+			return vfs.SetUser(u)
+		}
+	case UnknownUserIdError:
+	default:
+		return err
+	}
+
+	return vfs.SetUser(NewEphemeralUser(uid, gid))
+}
+
 // SplitAbs splits an absolute path immediately preceding the final Separator,
 // separating it into a directory and file name component.
 // If there is no Separator in path, splitPath returns an empty dir
@@ -527,6 +961,25 @@ func SplitAbs[T VFSBase](vfs T, path string) (dir, file string) {
 	return path[:i], path[i+1:]
 }
 
+// SplitPattern splits the pattern of CreateTemp or MkdirTemp by the last wildcard "*", if applicable,
+// returning prefix as the part before "*" and suffix as the part after "*".
+// If pattern contains a path separator, it returns ErrPatternHasSeparator.
+func SplitPattern[T VFSBase](vfs T, pattern string) (prefix, suffix string, err error) {
+	for i := range len(pattern) {
+		if IsPathSeparator(vfs, pattern[i]) {
+			return "", "", ErrPatternHasSeparator
+		}
+	}
+
+	if pos := strings.LastIndexByte(pattern, '*'); pos != -1 {
+		prefix, suffix = pattern[:pos], pattern[pos+1:]
+	} else {
+		prefix = pattern
+	}
+
+	return prefix, suffix, nil
+}
+
 // SystemDirs returns an array of system directories always present in the file system.
 func SystemDirs[T VFSBase](vfs T, basePath string) []DirInfo {
 	switch vfs.OSType() {
@@ -582,13 +1035,17 @@ func TempDirUser[T VFSBase](vfs T, basePath, username string) string {
 func ToOpenMode(flag int) OpenMode {
 	var om OpenMode
 
+	if flag&O_TMPFILE == O_TMPFILE {
+		om |= OpenTmpFile
+	}
+
 	// Mask flags that can be used in read only mode (syscall.O_DIRECT for example)
 	if flag&0xFFF == os.O_RDONLY {
-		return OpenRead
+		return om | OpenRead
 	}
 
 	if flag&os.O_RDWR != 0 {
-		om = OpenRead | OpenWrite
+		om |= OpenRead | OpenWrite
 	}
 
 	if flag&(os.O_EXCL|os.O_CREATE) == (os.O_EXCL | os.O_CREATE) {
@@ -614,6 +1071,18 @@ func ToOpenMode(flag int) OpenMode {
 	return om
 }
 
+// TrimSeparators returns path without its trailing path separators, except for a root directory.
+func TrimSeparators[T VFSBase](vfs T, path string) string {
+	start := VolumeNameLen(vfs, path) + 1
+
+	end := len(path)
+	for end > start && IsPathSeparator(vfs, path[end-1]) {
+		end--
+	}
+
+	return path[:end]
+}
+
 // VolumeName returns leading volume name.
 // Given "C:\foo\bar" it returns "C:" on Windows.
 // Given "\\host\share\foo" it returns "\\host\share".
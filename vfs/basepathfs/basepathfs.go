@@ -31,6 +31,11 @@ import (
 // path name for a given file is not guaranteed to be unique.
 // Abs calls [Clean] on the result.
 func (vfs *BasePathFS) Abs(path string) (string, error) {
+	if path == "" {
+		// The empty path is the current directory, ToBasePath would keep it empty.
+		path = "."
+	}
+
 	abs, err := vfs.baseFS.Abs(vfs.ToBasePath(path))
 
 	return vfs.FromBasePath(abs), vfs.FromPathError(err)
@@ -405,6 +410,11 @@ func (vfs *BasePathFS) Rel(basepath, targpath string) (string, error) {
 // Remove removes the named file or (empty) directory.
 // If there is an error, it will be of type *PathError.
 func (vfs *BasePathFS) Remove(name string) error {
+	// ToBasePath resolves ".." lexically, like rmdir the parent of a directory is never removed.
+	if vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, name) == ".." {
+		return &fs.PathError{Op: "remove", Path: name, Err: avfs.ErrDirNotEmpty}
+	}
+
 	err := vfs.baseFS.Remove(vfs.ToBasePath(name))
 
 	return vfs.FromPathError(err)
@@ -421,6 +431,11 @@ func (vfs *BasePathFS) RemoveAll(path string) error {
 		return nil
 	}
 
+	// ToBasePath resolves ".." lexically, the parent of a directory is never removed.
+	if vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, path) == ".." {
+		return &fs.PathError{Op: "unlinkat", Path: path, Err: avfs.ErrDirNotEmpty}
+	}
+
 	err := vfs.baseFS.RemoveAll(vfs.ToBasePath(path))
 
 	return vfs.FromPathError(err)
@@ -431,6 +446,11 @@ func (vfs *BasePathFS) RemoveAll(path string) error {
 // OS-specific restrictions may apply when oldpath and newpath are in different directories.
 // If there is an error, it will be of type *LinkError.
 func (vfs *BasePathFS) Rename(oldname, newname string) error {
+	// ToBasePath resolves ".." lexically, like rename(2) the parent of a directory is never renamed.
+	if vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, oldname) == ".." {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: avfs.ErrDeviceBusy}
+	}
+
 	err := vfs.baseFS.Rename(vfs.ToBasePath(oldname), vfs.ToBasePath(newname))
 
 	return vfs.FromLinkError(err)
@@ -469,6 +489,12 @@ func (vfs *BasePathFS) SetUserByName(name string) error {
 	return vfs.baseFS.SetUserByName(name)
 }
 
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *BasePathFS) SetUserByUid(uid, gid int) error {
+	return vfs.baseFS.SetUserByUid(uid, gid)
+}
+
 // Split splits path immediately following the final Separator,
 // separating it into a directory and file name component.
 // If there is no Separator in path, Split returns an empty dir
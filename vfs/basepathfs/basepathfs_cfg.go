@@ -69,14 +69,24 @@ func NewWithErr(baseFS avfs.VFS, basePath string) (*BasePathFS, error) {
 
 // FromBasePath returns a BasePathFS path from an internal path.
 // When the base path is "/base/path", FromBasePath("/base/path/tmp") returns "/tmp".
+// Relative and empty paths, which can only come from errors, are returned unchanged.
 func (vfs *BasePathFS) FromBasePath(path string) string {
+	if !vfs.IsAbs(path) {
+		return path
+	}
+
 	if !strings.HasPrefix(path, vfs.basePath) {
 		panic("path must start with " + vfs.basePath + " : " + path)
 	}
 
 	vl := avfs.VolumeNameLen(vfs, path)
 
-	return vfs.Join(path[:vl], path[len(vfs.basePath):], string(vfs.PathSeparator()))
+	rel := path[len(vfs.basePath):]
+	if rel == "" || !vfs.IsPathSeparator(rel[0]) {
+		rel = string(vfs.PathSeparator()) + rel
+	}
+
+	return path[:vl] + rel
 }
 
 // FromPathError restore paths in fs.PathError if necessary.
@@ -101,18 +111,68 @@ func (vfs *BasePathFS) FromLinkError(err error) error {
 
 // ToBasePath transforms a BasePathFS path to an internal path.
 // When the base path is "/base/path", ToBasePath("/tmp") returns "/base/path/tmp".
+// The internal path never escapes the base path : ".." elements can't go above the root,
+// and relative paths are resolved from the root when the current directory is outside the base path.
+// The empty path is never found, it is returned unchanged.
 func (vfs *BasePathFS) ToBasePath(path string) string {
-	if path == "" || path == "/" {
+	if path == "" {
+		return ""
+	}
+
+	if path == "/" {
 		return vfs.basePath
 	}
 
+	sep := string(vfs.PathSeparator())
+
 	if vfs.IsAbs(path) {
 		vl := avfs.VolumeNameLen(vfs, path)
 
-		return vfs.basePath + path[vl:]
+		return vfs.confine(path[vl:])
+	}
+
+	wd, err := vfs.baseFS.Getwd()
+	if err != nil || !vfs.inBasePath(wd) {
+		return vfs.confine(sep + path)
+	}
+
+	if cleaned := vfs.Clean(path); cleaned != ".." && !strings.HasPrefix(cleaned, ".."+sep) {
+		return path
+	}
+
+	return vfs.confine(sep + wd[len(vfs.basePath):] + sep + path)
+}
+
+// confine returns the internal path of a path rooted at the base path.
+// A final "." element or a trailing path separator is kept, so the base file system
+// checks that the path names a directory, ".." elements are always resolved lexically.
+func (vfs *BasePathFS) confine(path string) string {
+	sep := string(vfs.PathSeparator())
+	suffix := ""
+
+	switch {
+	case avfs.DotElem(vfs, path) == ".":
+		suffix = sep + "."
+	case avfs.HasTrailingSeparator(vfs, path):
+		suffix = sep
+	}
+
+	path = vfs.Clean(path)
+	if path == sep {
+		return vfs.basePath + suffix
+	}
+
+	return vfs.basePath + path + suffix
+}
+
+// inBasePath returns true if the internal path is the base path or one of its descendants.
+func (vfs *BasePathFS) inBasePath(path string) bool {
+	if !strings.HasPrefix(path, vfs.basePath) {
+		return false
 	}
 
-	return path
+	return len(path) == len(vfs.basePath) || path[len(vfs.basePath)] == vfs.PathSeparator() ||
+		strings.HasSuffix(vfs.basePath, string(vfs.PathSeparator()))
 }
 
 // Name returns the name of the fileSystem.
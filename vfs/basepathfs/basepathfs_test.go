@@ -125,10 +125,17 @@ func TestBasePathFSToBasePath(t *testing.T) {
 	vfs, basePath := initFS(t)
 
 	toTests := []struct{ Path, ToPath string }{
-		{Path: "", ToPath: basePath},
+		{Path: "", ToPath: ""},
 		{Path: "/", ToPath: basePath},
+		{Path: "/.", ToPath: basePath + "/."},
+		{Path: "/tmp/", ToPath: basePath + "/tmp/"},
+		{Path: "/tmp/.", ToPath: basePath + "/tmp/."},
+		{Path: "/tmp/..", ToPath: basePath},
 		{Path: "/tmp", ToPath: basePath + "/tmp"},
 		{Path: "/tmp/avfs", ToPath: basePath + "/tmp/avfs"},
+		{Path: "/..", ToPath: basePath},
+		{Path: "/tmp/../../etc", ToPath: basePath + "/etc"},
+		{Path: "../../etc", ToPath: basePath + "/etc"},
 	}
 
 	for _, tt := range toTests {
@@ -149,13 +156,17 @@ func TestBasePathFSFromBasePath(t *testing.T) {
 		{FromPath: basePath, Path: "/"},
 		{FromPath: basePath + "/tmp", Path: "/tmp"},
 		{FromPath: basePath + "/tmp/avfs", Path: "/tmp/avfs"},
+		{FromPath: basePath + "/tmp/", Path: "/tmp/"},
+		{FromPath: basePath + "/tmp/.", Path: "/tmp/."},
+		{FromPath: "tmp", Path: "tmp"},
+		{FromPath: "", Path: ""},
 	}
 
 	for _, ft := range fromTests {
 		fromPath := avfs.FromUnixPath(vfs, ft.FromPath)
 		path := ""
 
-		if !strings.HasPrefix(fromPath, basePath) {
+		if vfs.IsAbs(fromPath) && !strings.HasPrefix(fromPath, basePath) {
 			test.AssertPanic(t, "", func() {
 				path = vfs.FromBasePath(fromPath)
 			})
@@ -18,11 +18,15 @@
 //
 // Failures are generated by returning an error in a custom function of type FailFunc.
 // This functions should be set using FailFS.SetFailFunc.
+// NthCallFunc, EveryNthCallFunc and ProbabilityFunc fail calls by their count or randomly,
+// LatencyFunc delays calls to simulate slow disks, the number of calls and failures of each function
+// is returned by FailFS.Stats.
 package failfs
 
 import (
 	"io/fs"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/avfs/avfs"
@@ -286,6 +290,20 @@ func (vfs *FailFS) Lchown(name string, uid, gid int) error {
 	return vfs.baseFS.Lchown(name, uid, gid)
 }
 
+// Lchtimes changes the access and modification times of the named file like Chtimes,
+// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+// If there is an error, it will be of type *PathError.
+func (vfs *FailFS) Lchtimes(name string, atime, mtime time.Time) error {
+	fp := FailParam{Op: "lchtimes", Path: name, ATime: atime, MTime: mtime}
+
+	err := vfs.fail(avfs.FnLchtimes, &fp)
+	if err != nil {
+		return err
+	}
+
+	return avfs.Lchtimes(vfs.baseFS, name, atime, mtime)
+}
+
 // Link creates newname as a hard link to the oldname file.
 // If there is an error, it will be of type *LinkError.
 func (vfs *FailFS) Link(oldname, newname string) error {
@@ -581,6 +599,19 @@ func (vfs *FailFS) SetUserByName(name string) error {
 	return vfs.baseFS.SetUserByName(name)
 }
 
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *FailFS) SetUserByUid(uid, gid int) error {
+	fp := FailParam{Path: strconv.Itoa(uid)}
+
+	err := vfs.fail(avfs.FnSetUserByUid, &fp)
+	if err != nil {
+		return err
+	}
+
+	return vfs.baseFS.SetUserByUid(uid, gid)
+}
+
 // Split splits path immediately following the final Separator,
 // separating it into a directory and file name component.
 // If there is no Separator in path, Split returns an empty dir
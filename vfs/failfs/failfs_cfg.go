@@ -37,9 +37,42 @@ func New(baseFS avfs.VFS) *FailFS {
 func (vfs *FailFS) fail(fn avfs.FnVFS, fp *FailParam) error {
 	err := vfs.failFunc(vfs, fn, fp)
 
+	st := &vfs.stats[fn]
+	st.calls.Add(1)
+
+	if err != nil {
+		st.fails.Add(1)
+	}
+
 	return err
 }
 
+// Stats returns the counters of calls and failures of the functions called at least once.
+func (vfs *FailFS) Stats() map[avfs.FnVFS]FnStats {
+	stats := make(map[avfs.FnVFS]FnStats)
+
+	for fn := range vfs.stats {
+		st := &vfs.stats[fn]
+
+		calls := st.calls.Load()
+		if calls == 0 {
+			continue
+		}
+
+		stats[avfs.FnVFS(fn)] = FnStats{Calls: calls, Fails: st.fails.Load()}
+	}
+
+	return stats
+}
+
+// ResetStats resets the counters of calls and failures of all the functions.
+func (vfs *FailFS) ResetStats() {
+	for fn := range vfs.stats {
+		vfs.stats[fn].calls.Store(0)
+		vfs.stats[fn].fails.Store(0)
+	}
+}
+
 // Name returns the name of the fileSystem.
 func (vfs *FailFS) Name() string {
 	return vfs.baseFS.Name()
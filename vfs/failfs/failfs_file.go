@@ -144,7 +144,7 @@ func (f *FailFile) Read(b []byte) (n int, err error) {
 	}
 
 	name := f.name()
-	fp := FailParam{Op: "read", Path: name}
+	fp := FailParam{Op: "read", Path: name, Size: int64(len(b))}
 	vfs := f.vfs
 
 	err = vfs.fail(avfs.FnFileRead, &fp)
@@ -152,7 +152,7 @@ func (f *FailFile) Read(b []byte) (n int, err error) {
 		return 0, err
 	}
 
-	return f.baseFile.Read(b)
+	return f.baseFile.Read(fp.shorten(b))
 }
 
 // ReadAt reads len(b) bytes from the File starting at byte offset off.
@@ -321,7 +321,7 @@ func (f *FailFile) Write(b []byte) (n int, err error) {
 	}
 
 	name := f.name()
-	fp := FailParam{Op: "write", Path: name}
+	fp := FailParam{Op: "write", Path: name, Size: int64(len(b))}
 	vfs := f.vfs
 
 	err = vfs.fail(avfs.FnFileWrite, &fp)
@@ -329,7 +329,7 @@ func (f *FailFile) Write(b []byte) (n int, err error) {
 		return 0, err
 	}
 
-	return f.baseFile.Write(b)
+	return f.baseFile.Write(fp.shorten(b))
 }
 
 // WriteAt writes len(b) bytes to the File starting at byte offset off.
@@ -2,7 +2,12 @@ package failfs
 
 import (
 	"io/fs"
+	"math/rand"
 	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/avfs/avfs"
 )
@@ -22,7 +27,7 @@ func ReadOnlyFunc(_ avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
 
 		return nil
 	case avfs.FnChmod, avfs.FnFileChmod, avfs.FnFileChown, avfs.FnChtimes, avfs.FnCreateTemp,
-		avfs.FnFileSync, avfs.FnFileTruncate, avfs.FnFileWrite, avfs.FnFileWriteAt,
+		avfs.FnFileSync, avfs.FnFileTruncate, avfs.FnFileWrite, avfs.FnFileWriteAt, avfs.FnLchtimes,
 		avfs.FnMkdir, avfs.FnMkdirAll, avfs.FnMkdirTemp, avfs.FnRemove, avfs.FnRemoveAll, avfs.FnTruncate:
 		return &fs.PathError{Op: fp.Op, Path: fp.Path, Err: avfs.ErrPermDenied}
 	case avfs.FnChown, avfs.FnLchown:
@@ -33,3 +38,184 @@ func ReadOnlyFunc(_ avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
 		return nil
 	}
 }
+
+// ShortIOFunc returns a FailFunc that makes every n-th File.Read or File.Write a short read or write :
+// at most size bytes are transferred and no error is returned.
+// Short reads are legal, short writes without error break the io.Writer contract,
+// both are rarely tested in loops around Read and Write.
+func ShortIOFunc(n, size int) FailFunc {
+	var calls atomic.Int64
+
+	return func(_ avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
+		if fn != avfs.FnFileRead && fn != avfs.FnFileWrite {
+			return nil
+		}
+
+		if n > 0 && calls.Add(1)%int64(n) == 0 {
+			fp.Short = size
+		}
+
+		return nil
+	}
+}
+
+// EINTRFunc returns a FailFunc that makes every n-th OpenFile, File.Read or File.Write fail with
+// avfs.ErrInterrupted (EINTR) on file systems emulating Linux, so that retry loops can be tested.
+// Interrupted calls have no effect and can be retried.
+func EINTRFunc(n int) FailFunc {
+	var calls atomic.Int64
+
+	return func(vfs avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
+		if vfs.OSType() != avfs.OsLinux {
+			return nil
+		}
+
+		switch fn {
+		case avfs.FnOpenFile, avfs.FnFileRead, avfs.FnFileWrite:
+			if n > 0 && calls.Add(1)%int64(n) == 0 {
+				return &fs.PathError{Op: fp.Op, Path: fp.Path, Err: avfs.ErrInterrupted}
+			}
+		}
+
+		return nil
+	}
+}
+
+// CombineFuncs returns a FailFunc calling the functions fns in order until one of them returns an error.
+// Each function can modify the FailParam (see FailParam.Short), for example
+// CombineFuncs(EINTRFunc(5), ShortIOFunc(3, 1)).
+func CombineFuncs(fns ...FailFunc) FailFunc {
+	return func(vfs avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
+		for _, f := range fns {
+			if err := f(vfs, fn, fp); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// NthCallFunc returns a FailFunc that makes the n-th call to one of the functions fns fail with the error err,
+// all the functions are counted if fns is empty. The other calls succeed.
+func NthCallFunc(n int, err error, fns ...avfs.FnVFS) FailFunc {
+	var calls atomic.Int64
+
+	return func(_ avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
+		if !matchFn(fn, fns) {
+			return nil
+		}
+
+		if calls.Add(1) == int64(n) {
+			return fp.newError(fn, err)
+		}
+
+		return nil
+	}
+}
+
+// EveryNthCallFunc returns a FailFunc that makes every n-th call to one of the functions fns fail with the error err,
+// all the functions are counted if fns is empty.
+func EveryNthCallFunc(n int, err error, fns ...avfs.FnVFS) FailFunc {
+	var calls atomic.Int64
+
+	return func(_ avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
+		if !matchFn(fn, fns) {
+			return nil
+		}
+
+		if n > 0 && calls.Add(1)%int64(n) == 0 {
+			return fp.newError(fn, err)
+		}
+
+		return nil
+	}
+}
+
+// ProbabilityFunc returns a FailFunc that makes the calls to one of the functions fns fail with the error err
+// with the probability p (between 0 and 1), all the functions can fail if fns is empty.
+// The random numbers are drawn from src, a seeded source produces reproducible failures,
+// a nil source uses the default source.
+func ProbabilityFunc(p float64, src rand.Source, err error, fns ...avfs.FnVFS) FailFunc {
+	draw := randFunc(src)
+
+	return func(_ avfs.VFSBase, fn avfs.FnVFS, fp *FailParam) error {
+		if !matchFn(fn, fns) {
+			return nil
+		}
+
+		if draw() < p {
+			return fp.newError(fn, err)
+		}
+
+		return nil
+	}
+}
+
+// LatencyFunc returns a FailFunc that delays the calls to one of the functions fns,
+// all the functions are delayed if fns is empty, to simulate slow disks or network file systems.
+// The delay is minDelay if maxDelay is lower or equal to minDelay, otherwise it is drawn uniformly in [minDelay, maxDelay) from src
+// (a nil source uses the default source). The calls are delayed but never fail,
+// LatencyFunc can be combined with other functions by CombineFuncs to inject both delays and errors.
+func LatencyFunc(minDelay, maxDelay time.Duration, src rand.Source, fns ...avfs.FnVFS) FailFunc {
+	draw := randFunc(src)
+
+	return func(_ avfs.VFSBase, fn avfs.FnVFS, _ *FailParam) error {
+		if !matchFn(fn, fns) {
+			return nil
+		}
+
+		d := minDelay
+		if maxDelay > minDelay {
+			d += time.Duration(draw() * float64(maxDelay-minDelay))
+		}
+
+		time.Sleep(d)
+
+		return nil
+	}
+}
+
+// randFunc returns a function returning random numbers in [0.0, 1.0) drawn from src, safe for concurrent use.
+// A nil source uses the default source.
+func randFunc(src rand.Source) func() float64 {
+	if src == nil {
+		return rand.Float64
+	}
+
+	var mu sync.Mutex
+
+	rnd := rand.New(src) //nolint:gosec // No need for a cryptographic source to inject failures.
+
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return rnd.Float64()
+	}
+}
+
+// matchFn returns true if fn is one of the functions fns or if fns is empty.
+func matchFn(fn avfs.FnVFS, fns []avfs.FnVFS) bool {
+	return len(fns) == 0 || slices.Contains(fns, fn)
+}
+
+// newError returns the error err of the function fn wrapped in an *os.LinkError for link functions
+// and in a *fs.PathError for the others.
+func (fp *FailParam) newError(fn avfs.FnVFS, err error) error {
+	switch fn {
+	case avfs.FnLink, avfs.FnRename, avfs.FnSymlink:
+		return &os.LinkError{Op: fp.Op, Old: fp.Path, New: fp.NewPath, Err: err}
+	default:
+		return &fs.PathError{Op: fp.Op, Path: fp.Path, Err: err}
+	}
+}
+
+// shorten returns b limited to Short bytes if Short is set.
+func (fp *FailParam) shorten(b []byte) []byte {
+	if fp.Short > 0 && fp.Short < len(b) {
+		return b[:fp.Short]
+	}
+
+	return b
+}
@@ -19,7 +19,13 @@
 package failfs_test
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/test"
@@ -58,3 +64,258 @@ func TestFailFSReadOnly(t *testing.T) {
 	ts := test.NewSuiteFS(t, baseFS, vfs)
 	ts.TestVFSAll(t)
 }
+
+func TestFailFSShortIO(t *testing.T) {
+	baseFS := orefafs.New()
+	vfs := failfs.New(baseFS)
+	_ = vfs.SetFailFunc(failfs.ShortIOFunc(2, 3))
+
+	path := vfs.Join(test.TempRoot(t, baseFS), "file")
+	data := []byte("0123456789")
+
+	f, err := vfs.Create(path)
+	test.RequireNoError(t, err, "Create %s", path)
+
+	defer f.Close()
+
+	n, err := f.Write(data)
+	test.RequireNoError(t, err, "Write %s", path)
+
+	if n != len(data) {
+		t.Errorf("Write : want first write to be complete, got %d bytes", n)
+	}
+
+	n, err = f.Write(data)
+	test.RequireNoError(t, err, "Write %s", path)
+
+	if n != 3 {
+		t.Errorf("Write : want second write to be short (3 bytes), got %d bytes", n)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	test.RequireNoError(t, err, "Seek %s", path)
+
+	got := make([]byte, len(data)+3)
+
+	_, err = io.ReadFull(f, got)
+	test.RequireNoError(t, err, "ReadFull %s", path)
+
+	want := append(bytes.Clone(data), data[:3]...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("ReadFull : want %s, got %s", want, got)
+	}
+}
+
+func TestFailFSEINTR(t *testing.T) {
+	baseFS := orefafs.New()
+	if baseFS.OSType() != avfs.OsLinux {
+		t.Skip("EINTR is only simulated on Linux")
+	}
+
+	vfs := failfs.New(baseFS)
+	_ = vfs.SetFailFunc(failfs.CombineFuncs(failfs.EINTRFunc(2), failfs.ShortIOFunc(3, 1)))
+
+	path := vfs.Join(test.TempRoot(t, baseFS), "file")
+	data := []byte("0123456789")
+
+	// writeAll writes data retrying on EINTR and short writes.
+	writeAll := func(f avfs.File, b []byte) (interrupted int) {
+		for len(b) > 0 {
+			n, err := f.Write(b)
+			if errors.Is(err, avfs.ErrInterrupted) {
+				interrupted++
+
+				continue
+			}
+
+			test.RequireNoError(t, err, "Write %s", path)
+
+			b = b[n:]
+		}
+
+		return interrupted
+	}
+
+	f, err := vfs.Create(path)
+	test.RequireNoError(t, err, "Create %s", path)
+
+	defer f.Close()
+
+	if interrupted := writeAll(f, data); interrupted == 0 {
+		t.Errorf("Write : want some writes to be interrupted")
+	}
+
+	_, err = vfs.OpenFile(path, 0, 0)
+	test.AssertPathError(t, err).Op("open").Path(path).Err(avfs.ErrInterrupted).Test()
+
+	got, err := baseFS.ReadFile(path)
+	test.RequireNoError(t, err, "ReadFile %s", path)
+
+	if !bytes.Equal(got, data) {
+		t.Errorf("ReadFile : want %s, got %s", data, got)
+	}
+}
+
+func TestFailFSCallCount(t *testing.T) {
+	baseFS := orefafs.New()
+	vfs := failfs.New(baseFS)
+	dir := test.TempRoot(t, baseFS)
+
+	_ = vfs.SetFailFunc(failfs.CombineFuncs(
+		failfs.NthCallFunc(2, avfs.ErrPermDenied, avfs.FnMkdir),
+		failfs.EveryNthCallFunc(3, avfs.ErrDeviceBusy, avfs.FnStat),
+	))
+
+	for i := 1; i <= 4; i++ {
+		path := vfs.Join(dir, "dir"+strconv.Itoa(i))
+
+		err := vfs.Mkdir(path, avfs.DefaultDirPerm)
+		if i == 2 {
+			test.AssertPathError(t, err).Op("mkdir").Path(path).Err(avfs.ErrPermDenied).Test()
+
+			continue
+		}
+
+		test.RequireNoError(t, err, "Mkdir %s", path)
+	}
+
+	fails := 0
+
+	for i := 1; i <= 6; i++ {
+		_, err := vfs.Stat(dir)
+		if err != nil {
+			test.AssertPathError(t, err).Op("stat").Path(dir).Err(avfs.ErrDeviceBusy).Test()
+
+			fails++
+		}
+	}
+
+	if fails != 2 {
+		t.Errorf("Stat : want 2 failures, got %d", fails)
+	}
+
+	stats := vfs.Stats()
+
+	if st := stats[avfs.FnMkdir]; st != (failfs.FnStats{Calls: 4, Fails: 1}) {
+		t.Errorf("Stats Mkdir : want 4 calls and 1 failure, got %+v", st)
+	}
+
+	if st := stats[avfs.FnStat]; st != (failfs.FnStats{Calls: 6, Fails: 2}) {
+		t.Errorf("Stats Stat : want 6 calls and 2 failures, got %+v", st)
+	}
+
+	if _, ok := stats[avfs.FnRemove]; ok {
+		t.Errorf("Stats Remove : want no stats for a function never called")
+	}
+
+	vfs.ResetStats()
+
+	if stats = vfs.Stats(); len(stats) != 0 {
+		t.Errorf("ResetStats : want no stats, got %v", stats)
+	}
+}
+
+func TestFailFSLchtimes(t *testing.T) {
+	baseFS := orefafs.New()
+	vfs := failfs.New(baseFS)
+	dir := test.TempRoot(t, baseFS)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	_ = vfs.SetFailFunc(failfs.NthCallFunc(1, avfs.ErrPermDenied, avfs.FnLchtimes))
+
+	err := vfs.Chtimes(dir, mtime, mtime)
+	test.RequireNoError(t, err, "Chtimes %s", dir)
+
+	err = vfs.Lchtimes(dir, mtime, mtime)
+	test.AssertPathError(t, err).Op("lchtimes").Path(dir).Err(avfs.ErrPermDenied).Test()
+
+	stats := vfs.Stats()
+
+	if st := stats[avfs.FnChtimes]; st != (failfs.FnStats{Calls: 1}) {
+		t.Errorf("Stats Chtimes : want 1 call and no failure, got %+v", st)
+	}
+
+	if st := stats[avfs.FnLchtimes]; st != (failfs.FnStats{Calls: 1, Fails: 1}) {
+		t.Errorf("Stats Lchtimes : want 1 call and 1 failure, got %+v", st)
+	}
+}
+
+func TestFailFSProbability(t *testing.T) {
+	baseFS := orefafs.New()
+	vfs := failfs.New(baseFS)
+	dir := test.TempRoot(t, baseFS)
+
+	const calls = 1000
+
+	for _, p := range []float64{0, 0.3, 1} {
+		_ = vfs.SetFailFunc(failfs.ProbabilityFunc(p, rand.NewSource(42), avfs.ErrPermDenied))
+		vfs.ResetStats()
+
+		for i := 0; i < calls; i++ {
+			_, _ = vfs.Stat(dir)
+		}
+
+		st := vfs.Stats()[avfs.FnStat]
+		if st.Calls != calls {
+			t.Errorf("Stats Stat : want %d calls, got %d", calls, st.Calls)
+		}
+
+		want, delta := p*calls, 0.1*calls
+		if got := float64(st.Fails); got < want-delta || got > want+delta {
+			t.Errorf("Stats Stat : want about %.0f failures with probability %.1f, got %d", want, p, st.Fails)
+		}
+	}
+}
+
+func TestFailFSLatency(t *testing.T) {
+	baseFS := orefafs.New()
+	vfs := failfs.New(baseFS)
+	dir := test.TempRoot(t, baseFS)
+
+	const (
+		minDelay = 20 * time.Millisecond
+		maxDelay = 40 * time.Millisecond
+	)
+
+	_ = vfs.SetFailFunc(failfs.CombineFuncs(
+		failfs.LatencyFunc(minDelay, minDelay, nil, avfs.FnMkdir),
+		failfs.LatencyFunc(minDelay, maxDelay, rand.NewSource(42), avfs.FnStat),
+		failfs.EveryNthCallFunc(2, avfs.ErrDeviceBusy, avfs.FnStat),
+	))
+
+	path := vfs.Join(dir, "slow")
+	start := time.Now()
+
+	err := vfs.Mkdir(path, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "Mkdir %s", path)
+
+	if d := time.Since(start); d < minDelay {
+		t.Errorf("Mkdir : want a delay of at least %s, got %s", minDelay, d)
+	}
+
+	for i := 1; i <= 2; i++ {
+		start = time.Now()
+
+		_, err = vfs.Stat(path)
+		if d := time.Since(start); d < minDelay {
+			t.Errorf("Stat : want a delay of at least %s, got %s", minDelay, d)
+		}
+
+		if i == 2 {
+			test.AssertPathError(t, err).Op("stat").Path(path).Err(avfs.ErrDeviceBusy).Test()
+
+			continue
+		}
+
+		test.RequireNoError(t, err, "Stat %s", path)
+	}
+
+	start = time.Now()
+
+	_, err = vfs.Lstat(path)
+	test.RequireNoError(t, err, "Lstat %s", path)
+
+	if d := time.Since(start); d >= minDelay {
+		t.Errorf("Lstat : want no delay, got %s", d)
+	}
+}
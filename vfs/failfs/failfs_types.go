@@ -18,6 +18,7 @@ package failfs
 
 import (
 	"io/fs"
+	"sync/atomic"
 	"time"
 
 	"github.com/avfs/avfs"
@@ -28,9 +29,22 @@ import (
 type FailFS struct {
 	baseFS          avfs.VFS // baseFS is the base file system.
 	failFunc        FailFunc // failFunc is the function
+	stats           fnStats  // stats are the counters of calls and failures of each function.
 	avfs.FeaturesFn          // FeaturesFn provides features functions to a file system or an identity manager.
 }
 
+// FnStats are the counters of a function of a FailFS file system.
+type FnStats struct {
+	Calls uint64 // Calls is the number of calls to the function.
+	Fails uint64 // Fails is the number of calls failed by the FailFunc function.
+}
+
+// fnStats are the counters of calls and failures of each function, indexed by avfs.FnVFS.
+type fnStats [avfs.FnWriteFile + 1]struct {
+	calls atomic.Uint64
+	fails atomic.Uint64
+}
+
 // FailFile represents an open file descriptor.
 type FailFile struct {
 	baseFile avfs.File // baseFile represents an open file descriptor from the base file system.
@@ -51,7 +65,8 @@ type FailParam struct {
 	Flag    int         // Flag is the opening flag for Open function.
 	Uid     int         // Uid is used in the Chown and Lchown functions.
 	Gid     int         // Gid is used in the Chown and Lchown functions.
-	Size    int64       // Size is used in Truncate functions.
+	Size    int64       // Size is used in Truncate functions, it is the length of the buffer for reads and writes.
+	Short   int         // Short can be set by a FailFunc to limit the number of bytes of File.Read and File.Write (short I/O).
 	ATime   time.Time   // ATime is used in the Chtimes functions.
 	MTime   time.Time   // MTime is used in the Chtimes functions.
 }
@@ -0,0 +1,41 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package freezefs
+
+import "github.com/avfs/avfs"
+
+// New returns a new FreezeFS file system from a baseFS file system.
+func New(baseFS avfs.VFS) *FreezeFS {
+	vfs := &FreezeFS{
+		baseFS:   baseFS,
+		FreezeFn: &avfs.FreezeFn{},
+	}
+
+	_ = vfs.SetFeatures(baseFS.Features())
+
+	return vfs
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *FreezeFS) Name() string {
+	return vfs.baseFS.Name()
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*FreezeFS) Type() string {
+	return "FreezeFS"
+}
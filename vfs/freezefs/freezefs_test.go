@@ -0,0 +1,63 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package freezefs_test
+
+import (
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/freezefs"
+	"github.com/avfs/avfs/vfs/memfs"
+)
+
+var (
+	// Tests that freezefs.FreezeFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &freezefs.FreezeFS{}
+
+	// Tests that freezefs.FreezeFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &freezefs.FreezeFS{}
+
+	// Tests that freezefs.FreezeFS struct implements avfs.Freezer interface.
+	_ avfs.Freezer = &freezefs.FreezeFS{}
+
+	// Tests that freezefs.FreezeFile struct implements avfs.File interface.
+	_ avfs.File = &freezefs.FreezeFile{}
+)
+
+func TestFreezeFS(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := freezefs.New(baseFS)
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
+func TestFreezeFSConfig(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := freezefs.New(baseFS)
+
+	if vfs.Features() != baseFS.Features() {
+		t.Errorf("Features : want Features to be %s, got %s", baseFS.Features(), vfs.Features())
+	}
+
+	if vfs.Type() != "FreezeFS" {
+		t.Errorf("Type : want type to be FreezeFS, got %s", vfs.Type())
+	}
+}
@@ -0,0 +1,33 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package freezefs
+
+import "github.com/avfs/avfs"
+
+// FreezeFS implements a file system which can be frozen to block the mutations of a base file system
+// using the avfs.VFS interface.
+type FreezeFS struct {
+	baseFS          avfs.VFS // baseFS is the base file system.
+	*avfs.FreezeFn           // FreezeFn provides freeze functions to a file system, shared with the sub file systems.
+	avfs.FeaturesFn          // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// FreezeFile represents an open file descriptor.
+type FreezeFile struct {
+	baseFile avfs.File // baseFile represents an open file descriptor from the base file system.
+	vfs      *FreezeFS // vfs is the file system of the file.
+}
@@ -0,0 +1,205 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package guardfs
+
+import (
+	"runtime/debug"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// New returns a new GuardFS file system from a baseFS file system with the default Options,
+// any mutation panics.
+func New(baseFS avfs.VFS) *GuardFS {
+	return NewWithOptions(baseFS, nil)
+}
+
+// NewWithOptions returns a new GuardFS file system from a baseFS file system with the selected Options.
+func NewWithOptions(baseFS avfs.VFS, opts *Options) *GuardFS {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	vfs := &GuardFS{
+		baseFS: baseFS,
+		guard:  &guard{noPanic: opts.NoPanic},
+	}
+
+	for _, dir := range opts.Scratch {
+		vfs.scratch = append(vfs.scratch, vfs.resolve(dir, true))
+	}
+
+	_ = vfs.SetFeatures(baseFS.Features())
+
+	return vfs
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *GuardFS) Name() string {
+	return vfs.baseFS.Name()
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*GuardFS) Type() string {
+	return "GuardFS"
+}
+
+// Violations returns the violations recorded, in call order.
+func (vfs *GuardFS) Violations() []*Violation {
+	g := vfs.guard
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]*Violation(nil), g.violations...)
+}
+
+// Error returns the error string of the violation, including the stack trace.
+func (v *Violation) Error() string {
+	return "guardfs: mutating operation " + v.Op + " on " + v.Path +
+		" outside of the scratch directories\n" + string(v.Stack)
+}
+
+// check returns nil if the operation op on all the paths is allowed,
+// otherwise it records the violation and panics, or returns it if the option NoPanic is set.
+// The symbolic links of the paths are evaluated, the last one only if follow is true.
+func (vfs *GuardFS) check(op string, follow bool, paths ...string) error {
+	for _, path := range paths {
+		if vfs.inScratch(vfs.resolve(path, follow)) {
+			continue
+		}
+
+		abs, err := vfs.baseFS.Abs(path)
+		if err != nil {
+			abs = path
+		}
+
+		v := &Violation{Op: op, Path: abs, Stack: debug.Stack()}
+		g := vfs.guard
+
+		g.mu.Lock()
+		g.violations = append(g.violations, v)
+		g.mu.Unlock()
+
+		if !g.noPanic {
+			panic(v)
+		}
+
+		return v
+	}
+
+	return nil
+}
+
+// maxSymlinks is the maximum number of dangling symbolic links followed by resolve.
+const maxSymlinks = 40
+
+// resolve returns the absolute path of path with its symbolic links evaluated,
+// the last element of the path is only evaluated if follow is true.
+// The elements of the path which don't exist yet are kept as is.
+func (vfs *GuardFS) resolve(path string, follow bool) string {
+	bfs := vfs.baseFS
+
+	abs, err := bfs.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	dir, rest := abs, ""
+	if !follow {
+		dir, rest = bfs.Dir(abs), bfs.Base(abs)
+	}
+
+	for links := 0; links < maxSymlinks; {
+		realDir, err := bfs.EvalSymlinks(dir)
+		if err == nil {
+			return bfs.Join(realDir, rest)
+		}
+
+		// A dangling symbolic link is replaced by its target, which could be created through it.
+		if target, err := bfs.Readlink(dir); err == nil {
+			if !bfs.IsAbs(target) {
+				target = bfs.Join(bfs.Dir(dir), target)
+			}
+
+			dir = target
+			links++
+
+			continue
+		}
+
+		parent := bfs.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		rest = bfs.Join(bfs.Base(dir), rest)
+		dir = parent
+	}
+
+	return bfs.Join(dir, rest)
+}
+
+// inScratch returns true if the resolved absolute path is a scratch directory or one of its descendants.
+func (vfs *GuardFS) inScratch(path string) bool {
+	for _, dir := range vfs.scratch {
+		if vfs.isUnder(path, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnder returns true if path is the directory dir or one of its descendants.
+func (vfs *GuardFS) isUnder(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+
+	sep := string(vfs.baseFS.PathSeparator())
+	if !strings.HasSuffix(dir, sep) {
+		dir += sep
+	}
+
+	return strings.HasPrefix(path, dir)
+}
+
+// subScratch returns the scratch directories of the sub file system subFS of the directory dir,
+// in the namespace of subFS.
+func (vfs *GuardFS) subScratch(dir string, subFS avfs.VFS) []string {
+	subDir := vfs.resolve(dir, true)
+
+	root, err := subFS.Abs(string(subFS.PathSeparator()))
+	if err != nil {
+		return nil
+	}
+
+	var scratch []string
+
+	for _, s := range vfs.scratch {
+		switch {
+		case vfs.isUnder(subDir, s):
+			scratch = append(scratch, root)
+		case vfs.isUnder(s, subDir):
+			scratch = append(scratch, subFS.Join(root, s[len(subDir):]))
+		}
+	}
+
+	return scratch
+}
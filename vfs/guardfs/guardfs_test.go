@@ -0,0 +1,254 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package guardfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/guardfs"
+	"github.com/avfs/avfs/vfs/memfs"
+)
+
+var (
+	// Tests that guardfs.GuardFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &guardfs.GuardFS{}
+
+	// Tests that guardfs.GuardFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &guardfs.GuardFS{}
+
+	// Tests that guardfs.GuardFile struct implements avfs.File interface.
+	_ avfs.File = &guardfs.GuardFile{}
+)
+
+func TestGuardFS(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := guardfs.NewWithOptions(baseFS, &guardfs.Options{Scratch: []string{"/"}})
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+
+	if v := vfs.Violations(); len(v) != 0 {
+		t.Errorf("Violations : want no violations, got %d", len(v))
+	}
+}
+
+func TestGuardFSConfig(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := guardfs.New(baseFS)
+
+	if vfs.Features() != baseFS.Features() {
+		t.Errorf("Features : want Features to be %s, got %s", baseFS.Features(), vfs.Features())
+	}
+
+	if vfs.Type() != "GuardFS" {
+		t.Errorf("Type : want type to be GuardFS, got %s", vfs.Type())
+	}
+}
+
+func TestGuardFSPanic(t *testing.T) {
+	baseFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+	file := baseFS.Join(baseFS.TempDir(), "file")
+
+	test.RequireNoError(t, baseFS.WriteFile(file, []byte("data"), avfs.DefaultFilePerm), "WriteFile %s", file)
+
+	vfs := guardfs.New(baseFS)
+
+	_, err := vfs.ReadFile(file)
+	test.RequireNoError(t, err, "ReadFile %s", file)
+
+	defer func() {
+		v, ok := recover().(*guardfs.Violation)
+		if !ok {
+			t.Fatalf("Remove : want a panic with a *Violation")
+		}
+
+		if v.Op != "remove" || v.Path != file || len(v.Stack) == 0 {
+			t.Errorf("Remove : want violation of remove on %s with a stack, got %s %s", file, v.Op, v.Path)
+		}
+	}()
+
+	_ = vfs.Remove(file)
+}
+
+func TestGuardFSNoPanic(t *testing.T) {
+	baseFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+	scratch := baseFS.Join(baseFS.TempDir(), "scratch")
+	file := baseFS.Join(baseFS.TempDir(), "file")
+
+	test.RequireNoError(t, baseFS.Mkdir(scratch, avfs.DefaultDirPerm), "Mkdir %s", scratch)
+	test.RequireNoError(t, baseFS.WriteFile(file, []byte("data"), avfs.DefaultFilePerm), "WriteFile %s", file)
+
+	vfs := guardfs.NewWithOptions(baseFS, &guardfs.Options{Scratch: []string{scratch}, NoPanic: true})
+
+	t.Run("Scratch", func(t *testing.T) {
+		tmpFile := vfs.Join(scratch, "tmp")
+
+		test.RequireNoError(t, vfs.WriteFile(tmpFile, []byte("tmp"), avfs.DefaultFilePerm), "WriteFile %s", tmpFile)
+		test.RequireNoError(t, vfs.Chmod(tmpFile, 0o600), "Chmod %s", tmpFile)
+		test.RequireNoError(t, vfs.Remove(tmpFile), "Remove %s", tmpFile)
+
+		if v := vfs.Violations(); len(v) != 0 {
+			t.Errorf("Violations : want no violations, got %d", len(v))
+		}
+	})
+
+	t.Run("Violations", func(t *testing.T) {
+		err := vfs.Chmod(file, 0o600)
+		assertViolation(t, err, "chmod", file)
+
+		_, err = vfs.OpenFile(file, os.O_WRONLY, 0)
+		assertViolation(t, err, "open", file)
+
+		err = vfs.Rename(file, vfs.Join(scratch, "file"))
+		if _, ok := err.(*os.LinkError); !ok {
+			t.Errorf("Rename : want error to be a *LinkError, got %v", err)
+		}
+
+		assertViolation(t, err, "rename", file)
+
+		f, err := vfs.Open(file)
+		test.RequireNoError(t, err, "Open %s", file)
+
+		defer f.Close()
+
+		err = f.Chmod(0o600)
+		assertViolation(t, err, "chmod", file)
+
+		if v := vfs.Violations(); len(v) != 4 {
+			t.Errorf("Violations : want 4 violations, got %d", len(v))
+		}
+
+		info, err := baseFS.Stat(file)
+		test.RequireNoError(t, err, "Stat %s", file)
+
+		if info.Mode().Perm() != avfs.DefaultFilePerm&^baseFS.UMask() {
+			t.Errorf("Stat : want mode to be unchanged, got %s", info.Mode())
+		}
+
+		if _, err = baseFS.Stat(file); errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Rename : want %s to be unchanged", file)
+		}
+	})
+}
+
+// assertViolation checks that err is a violation of the operation op on path.
+func assertViolation(tb testing.TB, err error, op, path string) {
+	tb.Helper()
+
+	var v *guardfs.Violation
+	if !errors.As(err, &v) {
+		tb.Errorf("want error to be a *Violation, got %v", err)
+
+		return
+	}
+
+	if v.Op != op || v.Path != path || len(v.Stack) == 0 {
+		tb.Errorf("want a violation of %s on %s with a stack trace, got %s on %s", op, path, v.Op, v.Path)
+	}
+}
+
+func TestGuardFSSymlink(t *testing.T) {
+	baseFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+	scratch := baseFS.Join(baseFS.TempDir(), "scratch")
+	etcDir := baseFS.Join(baseFS.TempDir(), "etc")
+	file := baseFS.Join(etcDir, "passwd")
+	link := baseFS.Join(scratch, "link")
+	dangling := baseFS.Join(scratch, "dangling")
+
+	test.RequireNoError(t, baseFS.Mkdir(scratch, avfs.DefaultDirPerm), "Mkdir %s", scratch)
+	test.RequireNoError(t, baseFS.Mkdir(etcDir, avfs.DefaultDirPerm), "Mkdir %s", etcDir)
+	test.RequireNoError(t, baseFS.WriteFile(file, []byte("data"), avfs.DefaultFilePerm), "WriteFile %s", file)
+	test.RequireNoError(t, baseFS.Symlink(etcDir, link), "Symlink %s %s", etcDir, link)
+	test.RequireNoError(t, baseFS.Symlink(baseFS.Join(etcDir, "new"), dangling), "Symlink %s", dangling)
+
+	vfs := guardfs.NewWithOptions(baseFS, &guardfs.Options{Scratch: []string{scratch}, NoPanic: true})
+
+	t.Run("SymlinkExisting", func(t *testing.T) {
+		linkFile := vfs.Join(link, "passwd")
+
+		err := vfs.WriteFile(linkFile, []byte("guard"), avfs.DefaultFilePerm)
+		assertViolation(t, err, "open", linkFile)
+
+		err = vfs.WriteFile(dangling, []byte("guard"), avfs.DefaultFilePerm)
+		assertViolation(t, err, "open", dangling)
+
+		data, err := baseFS.ReadFile(file)
+		test.RequireNoError(t, err, "ReadFile %s", file)
+
+		if string(data) != "data" {
+			t.Errorf("ReadFile %s : want data to be unchanged, got %s", file, data)
+		}
+
+		test.RequireNoError(t, vfs.Remove(link), "Remove %s", link)
+		test.RequireNoError(t, baseFS.Symlink(etcDir, link), "Symlink %s %s", etcDir, link)
+	})
+
+	t.Run("SymlinkCreate", func(t *testing.T) {
+		newLink := vfs.Join(scratch, "newLink")
+
+		err := vfs.Symlink(etcDir, newLink)
+		assertViolation(t, err, "symlink", etcDir)
+
+		err = vfs.Symlink("../etc", newLink)
+		assertViolation(t, err, "symlink", etcDir)
+
+		err = vfs.Symlink("inScratch", newLink)
+		test.RequireNoError(t, err, "Symlink %s", newLink)
+
+		if v := vfs.Violations(); len(v) != 4 {
+			t.Errorf("Violations : want 4 violations, got %d", len(v))
+		}
+	})
+}
+
+func TestGuardFSSub(t *testing.T) {
+	baseFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+	scratch := baseFS.Join(baseFS.TempDir(), "scratch")
+	subDir := baseFS.Join(baseFS.TempDir(), "sub")
+	subScratch := baseFS.Join(subDir, "work")
+
+	// The path of the scratch directory in the sub file system exists, but it is not a scratch directory.
+	test.RequireNoError(t, baseFS.MkdirAll(baseFS.Join(subDir, scratch), avfs.DefaultDirPerm), "MkdirAll %s", scratch)
+	test.RequireNoError(t, baseFS.Mkdir(scratch, avfs.DefaultDirPerm), "Mkdir %s", scratch)
+	test.RequireNoError(t, baseFS.Mkdir(subScratch, avfs.DefaultDirPerm), "Mkdir %s", subScratch)
+
+	vfs := guardfs.NewWithOptions(baseFS, &guardfs.Options{Scratch: []string{scratch, subScratch}, NoPanic: true})
+
+	sub, err := vfs.Sub(subDir)
+	test.RequireNoError(t, err, "Sub %s", subDir)
+
+	outside := sub.Join(scratch, "file")
+
+	err = sub.WriteFile(outside, nil, avfs.DefaultFilePerm)
+	assertViolation(t, err, "open", outside)
+
+	inside := sub.Join("/work", "file")
+
+	err = sub.WriteFile(inside, nil, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", inside)
+
+	if v := vfs.Violations(); len(v) != 1 {
+		t.Errorf("Violations : want 1 violation, got %d", len(v))
+	}
+}
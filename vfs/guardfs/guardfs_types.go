@@ -0,0 +1,58 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package guardfs
+
+import (
+	"sync"
+
+	"github.com/avfs/avfs"
+)
+
+// GuardFS implements a file system failing loudly on the mutations of a base file system
+// using the avfs.VFS interface.
+type GuardFS struct {
+	baseFS          avfs.VFS // baseFS is the base file system.
+	guard           *guard   // guard records the violations, shared with the sub file systems.
+	scratch         []string // scratch are the resolved absolute paths of the directories where mutations are allowed.
+	avfs.FeaturesFn          // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// GuardFile represents an open file descriptor.
+type GuardFile struct {
+	baseFile avfs.File // baseFile represents an open file descriptor from the base file system.
+	vfs      *GuardFS  // vfs is the file system of the file.
+}
+
+// Options defines the initialization options of GuardFS.
+type Options struct {
+	Scratch []string // Scratch are the directories where mutations are allowed.
+	NoPanic bool     // NoPanic returns the violations as errors instead of panicking.
+}
+
+// Violation is a mutating operation invoked outside the scratch directories.
+type Violation struct {
+	Op    string // Op is the operation.
+	Path  string // Path is the absolute path of the file mutated by the operation.
+	Stack []byte // Stack is the stack trace of the goroutine which invoked the operation.
+}
+
+// guard records the violations of a file system and of its sub file systems.
+type guard struct {
+	violations []*Violation // violations are the violations recorded.
+	mu         sync.Mutex   // mu is the mutex used to access violations.
+	noPanic    bool         // noPanic returns the violations as errors instead of panicking.
+}
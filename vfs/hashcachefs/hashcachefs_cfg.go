@@ -0,0 +1,179 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package hashcachefs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/fs"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// New returns a new HashCacheFS file system from a baseFS file system with the default Options.
+func New(baseFS avfs.VFS) *HashCacheFS {
+	return NewWithOptions(baseFS, nil)
+}
+
+// NewWithOptions returns a new HashCacheFS file system from a baseFS file system with the selected Options.
+func NewWithOptions(baseFS avfs.VFS, opts *Options) *HashCacheFS {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	vfs := &HashCacheFS{
+		baseFS: baseFS,
+		cache:  &hashCache{entries: make(map[string]hashEntry)},
+		opts:   *opts,
+	}
+
+	if vfs.opts.NewHash == nil {
+		vfs.opts.NewHash = sha256.New
+	}
+
+	_ = vfs.SetFeatures(baseFS.Features())
+
+	return vfs
+}
+
+// Hash returns the hash sum of the named file.
+// The hash sum is returned from the cache if the size, the modification time
+// and the inode of the file are unchanged since it was computed.
+func (vfs *HashCacheFS) Hash(name string) ([]byte, error) {
+	absPath, err := vfs.baseFS.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := vfs.baseFS.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	key := vfs.newEntry(info)
+	c := vfs.cache
+
+	c.mu.Lock()
+
+	entry, ok := c.entries[absPath]
+	if ok && entry.matches(key) {
+		c.stats.Hits++
+		c.mu.Unlock()
+
+		return bytes.Clone(entry.sum), nil
+	}
+
+	if ok {
+		c.stats.Invalidations++
+		delete(c.entries, absPath)
+	}
+
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	sum, err := avfs.HashFile(vfs.baseFS, name, vfs.opts.NewHash())
+	if err != nil {
+		return nil, err
+	}
+
+	// The hash sum is only cached if the file was not modified while it was hashed.
+	info, err = vfs.baseFS.Stat(name)
+	if err != nil || !vfs.newEntry(info).matches(key) {
+		return sum, nil
+	}
+
+	key.sum = bytes.Clone(sum)
+
+	c.mu.Lock()
+	c.entries[absPath] = key
+	c.mu.Unlock()
+
+	return sum, nil
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *HashCacheFS) Name() string {
+	return vfs.baseFS.Name()
+}
+
+// Purge removes all the hash sums from the cache.
+func (vfs *HashCacheFS) Purge() {
+	c := vfs.cache
+
+	c.mu.Lock()
+	clear(c.entries)
+	c.mu.Unlock()
+}
+
+// Stats returns the cache statistics of the file system.
+func (vfs *HashCacheFS) Stats() Stats {
+	c := vfs.cache
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+
+	return stats
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*HashCacheFS) Type() string {
+	return "HashCacheFS"
+}
+
+// forget removes the hash sums of name and of its descendants from the cache.
+func (vfs *HashCacheFS) forget(name string) {
+	absPath, err := vfs.baseFS.Abs(name)
+	if err != nil {
+		return
+	}
+
+	prefix := absPath
+	if !strings.HasSuffix(prefix, string(vfs.PathSeparator())) {
+		prefix += string(vfs.PathSeparator())
+	}
+
+	c := vfs.cache
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.entries {
+		if path == absPath || strings.HasPrefix(path, prefix) {
+			delete(c.entries, path)
+		}
+	}
+}
+
+// newEntry returns a cache entry without hash sum from the metadata of a file.
+func (vfs *HashCacheFS) newEntry(info fs.FileInfo) hashEntry {
+	entry := hashEntry{modTime: info.ModTime(), size: info.Size()}
+
+	if is, ok := vfs.baseFS.ToSysStat(info).(avfs.InodeStater); ok {
+		entry.ino = is.Ino()
+	}
+
+	return entry
+}
+
+// matches returns true if the metadata of both entries are identical.
+func (e hashEntry) matches(other hashEntry) bool {
+	return e.size == other.size && e.ino == other.ino && e.modTime.Equal(other.modTime)
+}
@@ -0,0 +1,121 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package hashcachefs_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/hashcachefs"
+	"github.com/avfs/avfs/vfs/memfs"
+)
+
+var (
+	// Tests that hashcachefs.HashCacheFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &hashcachefs.HashCacheFS{}
+
+	// Tests that hashcachefs.HashCacheFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &hashcachefs.HashCacheFS{}
+)
+
+func TestHashCacheFS(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := hashcachefs.New(baseFS)
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
+func TestHashCacheFSHash(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := hashcachefs.New(baseFS)
+	path := vfs.Join(vfs.TempDir(), "file")
+
+	// assertHash checks the hash sum of path against content and the statistics of the cache.
+	assertHash := func(t *testing.T, content string, wantStats hashcachefs.Stats) {
+		t.Helper()
+
+		sum, err := vfs.Hash(path)
+		test.RequireNoError(t, err, "Hash %s", path)
+
+		wantSum := sha256.Sum256([]byte(content))
+		if !bytes.Equal(sum, wantSum[:]) {
+			t.Errorf("Hash %s : want hash sum to be %x, got %x", path, wantSum, sum)
+		}
+
+		if stats := vfs.Stats(); stats != wantStats {
+			t.Errorf("Stats : want %+v, got %+v", wantStats, stats)
+		}
+	}
+
+	err := baseFS.WriteFile(path, []byte("content"), avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", path)
+
+	t.Run("Miss", func(t *testing.T) {
+		assertHash(t, "content", hashcachefs.Stats{Misses: 1, Entries: 1})
+	})
+
+	t.Run("Hit", func(t *testing.T) {
+		assertHash(t, "content", hashcachefs.Stats{Hits: 1, Misses: 1, Entries: 1})
+	})
+
+	t.Run("ModTimeChanged", func(t *testing.T) {
+		mtime := time.Now().Add(time.Hour)
+
+		err = baseFS.Chtimes(path, mtime, mtime)
+		test.RequireNoError(t, err, "Chtimes %s", path)
+
+		assertHash(t, "content", hashcachefs.Stats{Hits: 1, Misses: 2, Invalidations: 1, Entries: 1})
+	})
+
+	t.Run("ContentChangedOnBaseFS", func(t *testing.T) {
+		err = baseFS.WriteFile(path, []byte("changed"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		assertHash(t, "changed", hashcachefs.Stats{Hits: 1, Misses: 3, Invalidations: 2, Entries: 1})
+	})
+
+	t.Run("ContentChanged", func(t *testing.T) {
+		err = vfs.WriteFile(path, []byte("again"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		if stats := vfs.Stats(); stats.Entries != 0 {
+			t.Errorf("Stats : want the entry to be dropped by WriteFile, got %+v", stats)
+		}
+
+		assertHash(t, "again", hashcachefs.Stats{Hits: 1, Misses: 4, Invalidations: 2, Entries: 1})
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		err = vfs.RemoveAll(vfs.TempDir())
+		test.RequireNoError(t, err, "RemoveAll %s", vfs.TempDir())
+
+		if stats := vfs.Stats(); stats.Entries != 0 {
+			t.Errorf("Stats : want no entry after RemoveAll, got %+v", stats)
+		}
+
+		_, err = vfs.Hash(path)
+		test.AssertPathError(t, err).OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+	})
+}
@@ -0,0 +1,61 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package hashcachefs
+
+import (
+	"hash"
+	"sync"
+	"time"
+
+	"github.com/avfs/avfs"
+)
+
+// HashCacheFS implements a file system caching the hash sums of files using the avfs.VFS interface.
+type HashCacheFS struct {
+	baseFS          avfs.VFS   // baseFS is the base file system.
+	cache           *hashCache // cache is the cache of the hash sums.
+	opts            Options    // opts are the options of the file system.
+	avfs.FeaturesFn            // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// Options defines the initialization options of HashCacheFS.
+type Options struct {
+	NewHash func() hash.Hash // NewHash returns the hash function of the cache (default sha256.New).
+}
+
+// Stats are the cache statistics of a HashCacheFS file system.
+type Stats struct {
+	Hits          int64 // Hits is the number of hash sums returned from the cache.
+	Misses        int64 // Misses is the number of hash sums computed from the content of the files.
+	Invalidations int64 // Invalidations is the number of cached hash sums discarded after a change of size or modification time.
+	Entries       int   // Entries is the number of hash sums in the cache.
+}
+
+// hashCache is the cache of the hash sums, indexed by absolute path.
+type hashCache struct {
+	mu      sync.Mutex
+	entries map[string]hashEntry
+	stats   Stats
+}
+
+// hashEntry is the hash sum of a file with the metadata it was computed from.
+type hashEntry struct {
+	modTime time.Time // modTime is the modification time of the file.
+	sum     []byte    // sum is the hash sum of the file.
+	size    int64     // size is the size of the file.
+	ino     uint64    // ino is the inode number of the file if the base file system provides it.
+}
@@ -0,0 +1,75 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package iofsfs
+
+import (
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// New returns a new read only file system (IofsFS) over the io/fs file system fsys with the default Options.
+func New(fsys fs.FS) *IofsFS {
+	return NewWithOptions(fsys, nil)
+}
+
+// NewWithOptions returns a new read only file system (IofsFS) over the io/fs file system fsys with the selected Options.
+// The root directory of the file system is the directory "." of fsys (on Windows, the root directory of the default volume).
+func NewWithOptions(fsys fs.FS, opts *Options) *IofsFS {
+	if opts == nil {
+		opts = &Options{OSType: avfs.OsUnknown}
+	}
+
+	idm := avfs.NotImplementedIdm
+
+	vfs := &IofsFS{
+		fsys:              fsys,
+		errOpNotPermitted: avfs.ErrOpNotPermitted,
+		errPermDenied:     avfs.ErrPermDenied,
+		name:              opts.Name,
+	}
+
+	_ = vfs.SetFeatures(avfs.FeatReadOnly | avfs.FeatSubFS | avfs.BuildFeatures())
+	_ = vfs.SetOSType(opts.OSType)
+	_ = vfs.SetIdm(idm)
+	_ = vfs.SetUser(idm.AdminUser())
+
+	vfs.err.SetOSType(vfs.OSType())
+
+	curDir := "/"
+
+	if vfs.OSType() == avfs.OsWindows {
+		vfs.errOpNotPermitted = avfs.ErrWinNotSupported
+		vfs.errPermDenied = avfs.ErrWinAccessDenied
+		curDir = avfs.DefaultVolume + string(vfs.PathSeparator())
+	}
+
+	_ = vfs.SetCurDir(curDir)
+	_ = vfs.SetUMask(avfs.UMask())
+
+	return vfs
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *IofsFS) Name() string {
+	return vfs.name
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*IofsFS) Type() string {
+	return "IofsFS"
+}
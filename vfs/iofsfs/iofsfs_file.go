@@ -0,0 +1,576 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package iofsfs
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// Chdir changes the current working directory to the file,
+// which must be a directory.
+// If there is an error, it will be of type *PathError.
+func (f *IofsFile) Chdir() error {
+	const op = "chdir"
+
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.name == "" {
+		return fs.ErrInvalid
+	}
+
+	if f.file == nil {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	if !f.info.IsDir() {
+		err := error(avfs.ErrNotADirectory)
+		if f.vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinDirNameInvalid
+		}
+
+		return &fs.PathError{Op: op, Path: f.name, Err: err}
+	}
+
+	_ = f.vfs.SetCurDir(f.absPath)
+
+	return nil
+}
+
+// Chmod changes the mode of the file to mode.
+// If there is an error, it will be of type *PathError.
+func (f *IofsFile) Chmod(mode fs.FileMode) error {
+	const op = "chmod"
+
+	return f.readOnlyError(op)
+}
+
+// Chown changes the numeric uid and gid of the named file.
+// If there is an error, it will be of type *PathError.
+//
+// On Windows, it always returns the syscall.EWINDOWS error, wrapped
+// in *PathError.
+func (f *IofsFile) Chown(uid, gid int) error {
+	const op = "chown"
+
+	return f.readOnlyError(op)
+}
+
+// Close closes the File, rendering it unusable for I/O.
+// On files that support SetDeadline, any pending I/O operations will
+// be canceled and return immediately with an error.
+func (f *IofsFile) Close() error {
+	const op = "close"
+
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if f.name == "" {
+			return fs.ErrInvalid
+		}
+
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	err := f.file.Close()
+
+	f.data = nil
+	f.dirEntries = nil
+	f.dirNames = nil
+	f.file = nil
+
+	if err != nil {
+		return f.vfs.pathError(op, f.name, err)
+	}
+
+	return nil
+}
+
+// Fd returns the integer Unix file descriptor referencing the open file.
+// The file descriptor is valid only until f.Close is called or f is garbage collected.
+// On Unix systems this will cause the SetDeadline methods to stop working.
+func (f *IofsFile) Fd() uintptr {
+	return ^(uintptr(0))
+}
+
+// Name returns the name of the file as presented to Open.
+func (f *IofsFile) Name() string {
+	return f.name
+}
+
+// Read reads up to len(b) bytes from the IofsFile.
+// It returns the number of bytes read and any error encountered.
+// At end of file, Read returns 0, io.EOF.
+func (f *IofsFile) Read(b []byte) (n int, err error) {
+	const op = "read"
+
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err = f.readAt(b, f.at, op)
+	if err != nil && err != io.EOF {
+		return n, err
+	}
+
+	f.at += int64(n)
+
+	if n == 0 {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+// ReadAt reads len(b) bytes from the File starting at byte offset off.
+// It returns the number of bytes read and the error, if any.
+// ReadAt always returns a non-nil error when n < len(b).
+// At end of file, that error is io.EOF.
+func (f *IofsFile) ReadAt(b []byte, off int64) (n int, err error) {
+	const op = "read"
+
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if off < 0 {
+		if err = f.checkFile(op); err != nil {
+			return 0, err
+		}
+
+		return 0, &fs.PathError{Op: "readat", Path: f.name, Err: avfs.ErrNegativeOffset}
+	}
+
+	return f.readAt(b, off, op)
+}
+
+// ReadDir reads the contents of the directory associated with the file f
+// and returns a slice of DirEntry values in directory order.
+// Subsequent calls on the same file will yield later DirEntry records in the directory.
+//
+// If n > 0, ReadDir returns at most n DirEntry records.
+// In this case, if ReadDir returns an empty slice, it will return an error explaining why.
+// At the end of a directory, the error is io.EOF.
+//
+// If n <= 0, ReadDir returns all the DirEntry records remaining in the directory.
+// When it succeeds, it returns a nil error (not io.EOF).
+func (f *IofsFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.checkDir(); err != nil {
+		return nil, err
+	}
+
+	if n <= 0 || f.dirEntries == nil {
+		de, err := f.readDir()
+		if err != nil {
+			return nil, err
+		}
+
+		f.dirIndex = 0
+
+		if n <= 0 {
+			f.dirEntries = nil
+
+			return de, nil
+		}
+
+		f.dirEntries = de
+	}
+
+	start := f.dirIndex
+	if start >= len(f.dirEntries) {
+		f.dirIndex = 0
+		f.dirEntries = nil
+
+		return nil, io.EOF
+	}
+
+	end := min(start+n, len(f.dirEntries))
+	f.dirIndex = end
+
+	return f.dirEntries[start:end], nil
+}
+
+// Readdirnames reads and returns a slice of names from the directory f.
+//
+// If n > 0, Readdirnames returns at most n names. In this case, if
+// Readdirnames returns an empty slice, it will return a non-nil error
+// explaining why. At the end of a directory, the error is io.EOF.
+//
+// If n <= 0, Readdirnames returns all the names from the directory in
+// a single slice. In this case, if Readdirnames succeeds (reads all
+// the way to the end of the directory), it returns the slice and a
+// nil error. If it encounters an error before the end of the
+// directory, Readdirnames returns the names read until that point and
+// a non-nil error.
+func (f *IofsFile) Readdirnames(n int) (names []string, err error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err = f.checkDir(); err != nil {
+		return nil, err
+	}
+
+	if n <= 0 || f.dirNames == nil {
+		de, err := f.readDir()
+		if err != nil {
+			return nil, err
+		}
+
+		names = make([]string, len(de))
+		for i, e := range de {
+			names[i] = e.Name()
+		}
+
+		f.dirIndex = 0
+
+		if n <= 0 {
+			f.dirNames = nil
+
+			return names, nil
+		}
+
+		f.dirNames = names
+	}
+
+	start := f.dirIndex
+	if start >= len(f.dirNames) {
+		f.dirIndex = 0
+		f.dirNames = nil
+
+		return nil, io.EOF
+	}
+
+	end := min(start+n, len(f.dirNames))
+	f.dirIndex = end
+
+	return f.dirNames[start:end], nil
+}
+
+// Seek sets the offset for the next Read or Write on file to offset, interpreted
+// according to whence: 0 means relative to the origin of the file, 1 means
+// relative to the current offset, and 2 means relative to the end.
+// It returns the new offset and an error, if any.
+// The behavior of Seek on a file opened with O_APPEND is not specified.
+func (f *IofsFile) Seek(offset int64, whence int) (ret int64, err error) {
+	const op = "seek"
+
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.name == "" {
+		return 0, fs.ErrInvalid
+	}
+
+	if f.file == nil {
+		return 0, &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	if f.info.IsDir() {
+		return 0, nil
+	}
+
+	size := f.info.Size()
+
+	switch whence {
+	case io.SeekStart:
+		if offset < 0 {
+			return 0, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.InvalidArgument}
+		}
+
+		f.at = offset
+	case io.SeekCurrent:
+		if f.at+offset < 0 {
+			return 0, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.InvalidArgument}
+		}
+
+		f.at += offset
+	case io.SeekEnd:
+		if size+offset < 0 {
+			return 0, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.InvalidArgument}
+		}
+
+		f.at = size + offset
+	default:
+		if f.vfs.OSType() != avfs.OsWindows {
+			return 0, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.InvalidArgument}
+		}
+
+		return 0, nil
+	}
+
+	return f.at, nil
+}
+
+// Stat returns the FileInfo structure describing file.
+// If there is an error, it will be of type *PathError.
+func (f *IofsFile) Stat() (info fs.FileInfo, err error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.name == "" {
+		return nil, fs.ErrInvalid
+	}
+
+	op := "stat"
+	if f.vfs.OSType() == avfs.OsWindows {
+		op = "GetFileType"
+	}
+
+	if f.file == nil {
+		err = avfs.ErrFileClosing
+		if f.vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinInvalidHandle
+		}
+
+		return nil, &fs.PathError{Op: op, Path: f.name, Err: err}
+	}
+
+	return &IofsInfo{FileInfo: f.info, name: f.vfs.Base(f.absPath), absPath: f.absPath}, nil
+}
+
+// Sync commits the current contents of the file to stable storage.
+// Typically, this means flushing the file system's in-memory copy
+// of recently written data to disk.
+func (f *IofsFile) Sync() error {
+	const op = "sync"
+
+	return f.readOnlyError(op)
+}
+
+// Truncate changes the size of the file.
+// It does not change the I/O offset.
+// If there is an error, it will be of type *PathError.
+func (f *IofsFile) Truncate(size int64) error {
+	const op = "truncate"
+
+	return f.readOnlyError(op)
+}
+
+// Write writes len(b) bytes to the File.
+// It returns the number of bytes written and an error, if any.
+// Write returns a non-nil error when n != len(b).
+func (f *IofsFile) Write(b []byte) (n int, err error) {
+	const op = "write"
+
+	return 0, f.readOnlyError(op)
+}
+
+// WriteAt writes len(b) bytes to the File starting at byte offset off.
+// It returns the number of bytes written and an error, if any.
+// WriteAt returns a non-nil error when n != len(b).
+func (f *IofsFile) WriteAt(b []byte, off int64) (n int, err error) {
+	const op = "write"
+
+	return 0, f.readOnlyError(op)
+}
+
+// WriteString is like Write, but writes the contents of string s rather than
+// a slice of bytes.
+func (f *IofsFile) WriteString(s string) (n int, err error) {
+	return f.Write([]byte(s))
+}
+
+// checkDir returns an error if the file is closed or is not a directory.
+func (f *IofsFile) checkDir() error {
+	if f.name == "" {
+		return fs.ErrInvalid
+	}
+
+	op := "readdirent"
+	if f.vfs.OSType() == avfs.OsWindows {
+		op = "readdir"
+	}
+
+	if f.file == nil {
+		err := error(avfs.ErrFileClosing)
+		if f.vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinInvalidHandle
+		}
+
+		return &fs.PathError{Op: op, Path: f.name, Err: err}
+	}
+
+	if !f.info.IsDir() {
+		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.NotADirectory}
+	}
+
+	return nil
+}
+
+// checkFile returns an error if the file is closed or is a directory.
+func (f *IofsFile) checkFile(op string) error {
+	if f.name == "" {
+		return fs.ErrInvalid
+	}
+
+	if f.file == nil {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	if f.info.IsDir() {
+		err := error(avfs.ErrIsADirectory)
+		if f.vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinIncorrectFunc
+		}
+
+		return &fs.PathError{Op: op, Path: f.name, Err: err}
+	}
+
+	return nil
+}
+
+// readAt reads len(b) bytes of the file from the offset off for the operation op.
+// The files of the io/fs file system which don't implement io.ReaderAt are read entirely by the first read.
+func (f *IofsFile) readAt(b []byte, off int64, op string) (int, error) {
+	if err := f.checkFile(op); err != nil {
+		return 0, err
+	}
+
+	if ra, ok := f.file.(io.ReaderAt); ok {
+		n, err := ra.ReadAt(b, off)
+		if err != nil && err != io.EOF {
+			return n, f.vfs.pathError(op, f.name, err)
+		}
+
+		return n, err
+	}
+
+	if !f.loaded {
+		data, err := io.ReadAll(f.file)
+		if err != nil {
+			return 0, f.vfs.pathError(op, f.name, err)
+		}
+
+		f.data = data
+		f.loaded = true
+	}
+
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(b, f.data[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// readDir returns the entries of the directory sorted by name.
+func (f *IofsFile) readDir() ([]fs.DirEntry, error) {
+	op := "readdirent"
+	if f.vfs.OSType() == avfs.OsWindows {
+		op = "readdir"
+	}
+
+	entries, err := fs.ReadDir(f.vfs.fsys, f.fsName)
+	if err != nil {
+		return nil, f.vfs.pathError(op, f.name, err)
+	}
+
+	return entries, nil
+}
+
+// readOnlyError returns the error of the operation op modifying a read only file,
+// or the error of a closed file.
+func (f *IofsFile) readOnlyError(op string) error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.name == "" {
+		return fs.ErrInvalid
+	}
+
+	if f.file == nil {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+
+	return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.errPermDenied}
+}
+
+// IofsInfo is the implementation of fs.FileInfo returned by Stat and Lstat.
+
+// Name returns the base name of the file.
+func (info *IofsInfo) Name() string {
+	if info.name == "" {
+		return info.FileInfo.Name()
+	}
+
+	return info.name
+}
+
+// Sys returns the underlying data source (can return nil).
+func (info *IofsInfo) Sys() any {
+	return info
+}
+
+// Gid returns the group id, the files of an io/fs file system are owned by the administrator.
+func (info *IofsInfo) Gid() int {
+	return 0
+}
+
+// Uid returns the user id, the files of an io/fs file system are owned by the administrator.
+func (info *IofsInfo) Uid() int {
+	return 0
+}
+
+// Nlink returns the number of hard links, always 1.
+func (info *IofsInfo) Nlink() uint64 {
+	return 1
+}
@@ -0,0 +1,102 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package iofsfs
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// lookup returns the file information, the absolute path and the name in the io/fs file system
+// of the file or directory name, or the error of the operation op.
+// Like the system calls, an empty path is never found and a path ending with a path separator,
+// "." or ".." can only name a directory.
+func (vfs *IofsFS) lookup(name, op string) (fs.FileInfo, string, string, error) {
+	absPath, _ := vfs.Abs(name)
+
+	if name == "" {
+		return nil, absPath, "", &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+	}
+
+	fsName, ok := vfs.toName(absPath)
+	if !ok {
+		return nil, absPath, "", &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+	}
+
+	info, err := fs.Stat(vfs.fsys, fsName)
+	if err == nil {
+		if !info.IsDir() && avfs.IsDirPath(vfs, name) {
+			return nil, absPath, fsName, &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+		}
+
+		return info, absPath, fsName, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, absPath, fsName, vfs.pathError(op, name, err)
+	}
+
+	parent, err := fs.Stat(vfs.fsys, path.Dir(fsName))
+
+	switch {
+	case err != nil:
+		return nil, absPath, fsName, &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+	case parent.IsDir():
+		return nil, absPath, fsName, &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	default:
+		return nil, absPath, fsName, &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+}
+
+// pathError returns the *PathError of the operation op on path from an error of the io/fs file system.
+func (vfs *IofsFS) pathError(op, path string, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		err = vfs.err.NoSuchFile
+	case errors.Is(err, fs.ErrPermission):
+		err = vfs.errPermDenied
+	default:
+		var e *fs.PathError
+		if errors.As(err, &e) {
+			err = e.Err
+		}
+	}
+
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+// toName returns the name in the io/fs file system of the absolute path absPath.
+// On Windows, only the paths of the default volume are in the io/fs file system.
+func (vfs *IofsFS) toName(absPath string) (string, bool) {
+	vl := avfs.VolumeNameLen(vfs, absPath)
+	if vfs.OSType() == avfs.OsWindows && !strings.EqualFold(absPath[:vl], avfs.DefaultVolume) {
+		return "", false
+	}
+
+	name := vfs.ToSlash(absPath[vl:])
+	name = strings.TrimPrefix(name, "/")
+
+	if name == "" {
+		return ".", true
+	}
+
+	return name, fs.ValidPath(name)
+}
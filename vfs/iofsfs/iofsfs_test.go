@@ -0,0 +1,330 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package iofsfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/vfs/iofsfs"
+	"github.com/avfs/avfs/vfs/memfs"
+)
+
+var (
+	// Tests that iofsfs.IofsFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &iofsfs.IofsFS{}
+
+	// Tests that iofsfs.IofsFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &iofsfs.IofsFS{}
+
+	// Tests that iofsfs.IofsFile struct implements avfs.File interface.
+	_ avfs.File = &iofsfs.IofsFile{}
+)
+
+var testModTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) //nolint:gochecknoglobals // Test data.
+
+// newMapFS returns an io/fs file system used by the tests.
+func newMapFS() fstest.MapFS {
+	return fstest.MapFS{
+		"dir/file.txt":          {Data: []byte("hello iofs"), Mode: 0o644, ModTime: testModTime},
+		"implicit/sub/deep.txt": {Data: []byte("deep"), Mode: 0o644},
+		"root.txt":              {Data: []byte("root"), Mode: 0o644},
+	}
+}
+
+// newIofsFS returns an IofsFS over the io/fs file system fsys emulating Linux.
+func newIofsFS(fsys fs.FS) *iofsfs.IofsFS {
+	return iofsfs.NewWithOptions(fsys, &iofsfs.Options{Name: "iofs", OSType: avfs.OsLinux})
+}
+
+// noReaderAtFS is an io/fs file system whose regular files only implement fs.File.
+type noReaderAtFS struct {
+	fsys fs.FS
+}
+
+func (nfs noReaderAtFS) Open(name string) (fs.File, error) {
+	f, err := nfs.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := f.(fs.ReadDirFile); ok {
+		return f, nil
+	}
+
+	return struct{ fs.File }{f}, nil
+}
+
+func TestIofsFSConfig(t *testing.T) {
+	vfs := newIofsFS(newMapFS())
+
+	if !vfs.HasFeature(avfs.FeatReadOnly) {
+		t.Errorf("Features : want FeatReadOnly to be set, got %s", vfs.Features())
+	}
+
+	if vfs.Name() != "iofs" {
+		t.Errorf("Name : want name to be %s, got %s", "iofs", vfs.Name())
+	}
+
+	if vfs.Type() != "IofsFS" {
+		t.Errorf("Type : want type to be %s, got %s", "IofsFS", vfs.Type())
+	}
+
+	if vfs.OSType() != avfs.OsLinux {
+		t.Errorf("OSType : want os type to be %v, got %v", avfs.OsLinux, vfs.OSType())
+	}
+
+	info, err := vfs.Stat("/")
+	if err != nil || !info.IsDir() {
+		t.Errorf("Stat : want root to be a directory, got %v, %v", info, err)
+	}
+}
+
+func TestIofsFSRead(t *testing.T) {
+	for _, fsys := range []fs.FS{newMapFS(), noReaderAtFS{fsys: newMapFS()}} {
+		vfs := newIofsFS(fsys)
+
+		t.Run("ReadFile", func(t *testing.T) {
+			for name, content := range map[string]string{
+				"/dir/file.txt":          "hello iofs",
+				"/implicit/sub/deep.txt": "deep",
+				"root.txt":               "root",
+			} {
+				data, err := vfs.ReadFile(name)
+				if err != nil {
+					t.Errorf("ReadFile %s : want error to be nil, got %v", name, err)
+				}
+
+				if string(data) != content {
+					t.Errorf("ReadFile %s : want content to be %q, got %q", name, content, data)
+				}
+			}
+		})
+
+		t.Run("ReadDir", func(t *testing.T) {
+			entries, err := vfs.ReadDir("/")
+			if err != nil {
+				t.Fatalf("ReadDir : want error to be nil, got %v", err)
+			}
+
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+
+			wantNames := []string{"dir", "implicit", "root.txt"}
+			if !slices.Equal(names, wantNames) {
+				t.Errorf("ReadDir : want names to be %v, got %v", wantNames, names)
+			}
+
+			_, err = vfs.ReadDir("/root.txt")
+			if !errors.Is(err, avfs.ErrNotADirectory) {
+				t.Errorf("ReadDir : want error to be %v, got %v", avfs.ErrNotADirectory, err)
+			}
+		})
+
+		t.Run("Stat", func(t *testing.T) {
+			info, err := vfs.Stat("/dir/file.txt")
+			if err != nil {
+				t.Fatalf("Stat : want error to be nil, got %v", err)
+			}
+
+			if info.Name() != "file.txt" || info.Size() != int64(len("hello iofs")) || info.Mode() != 0o644 {
+				t.Errorf("Stat : want file.txt, %d, %s, got %s, %d, %s",
+					len("hello iofs"), fs.FileMode(0o644), info.Name(), info.Size(), info.Mode())
+			}
+
+			if !info.ModTime().Equal(testModTime) {
+				t.Errorf("Stat : want modification time to be %v, got %v", testModTime, info.ModTime())
+			}
+
+			if sst := vfs.ToSysStat(info); sst.Uid() != 0 || sst.Nlink() != 1 {
+				t.Errorf("ToSysStat : want uid 0 and 1 link, got %d, %d", sst.Uid(), sst.Nlink())
+			}
+
+			info, err = vfs.Stat("/implicit/sub")
+			if err != nil || !info.IsDir() {
+				t.Errorf("Stat : want implicit directory, got %v, %v", info, err)
+			}
+
+			_, err = vfs.Stat("/nonExisting")
+			if !errors.Is(err, avfs.ErrNoSuchFileOrDir) {
+				t.Errorf("Stat : want error to be %v, got %v", avfs.ErrNoSuchFileOrDir, err)
+			}
+
+			_, err = vfs.Stat("/root.txt/")
+			if !errors.Is(err, avfs.ErrNotADirectory) {
+				t.Errorf("Stat : want error to be %v, got %v", avfs.ErrNotADirectory, err)
+			}
+
+			_, err = vfs.Stat("/root.txt/file")
+			if !errors.Is(err, avfs.ErrNotADirectory) {
+				t.Errorf("Stat : want error to be %v, got %v", avfs.ErrNotADirectory, err)
+			}
+
+			fi1, _ := vfs.Stat("/dir")
+			fi2, _ := vfs.Lstat("dir/.")
+
+			if !vfs.SameFile(fi1, fi2) {
+				t.Errorf("SameFile : want same file to be true, got false")
+			}
+		})
+
+		t.Run("File", func(t *testing.T) {
+			err := vfs.Chdir("/dir")
+			if err != nil {
+				t.Fatalf("Chdir : want error to be nil, got %v", err)
+			}
+
+			defer vfs.Chdir("/") //nolint:errcheck // Restore the current directory.
+
+			f, err := vfs.Open("file.txt")
+			if err != nil {
+				t.Fatalf("Open : want error to be nil, got %v", err)
+			}
+
+			defer f.Close()
+
+			pos, err := f.Seek(-4, io.SeekEnd)
+			if err != nil || pos != 6 {
+				t.Errorf("Seek : want position to be 6, got %d, %v", pos, err)
+			}
+
+			b := make([]byte, 10)
+
+			n, err := f.Read(b)
+			if err != nil || string(b[:n]) != "iofs" {
+				t.Errorf("Read : want %q, got %q, %v", "iofs", b[:n], err)
+			}
+
+			n, err = f.ReadAt(b[:5], 0)
+			if err != nil || string(b[:n]) != "hello" {
+				t.Errorf("ReadAt : want %q, got %q, %v", "hello", b[:n], err)
+			}
+
+			_, err = f.Write(b)
+			if !errors.Is(err, avfs.ErrPermDenied) {
+				t.Errorf("Write : want error to be %v, got %v", avfs.ErrPermDenied, err)
+			}
+
+			err = f.Close()
+			if err != nil {
+				t.Errorf("Close : want error to be nil, got %v", err)
+			}
+
+			_, err = f.Read(b)
+			if !errors.Is(err, fs.ErrClosed) {
+				t.Errorf("Read : want error to be %v, got %v", fs.ErrClosed, err)
+			}
+		})
+	}
+}
+
+func TestIofsFSReadOnly(t *testing.T) {
+	vfs := newIofsFS(newMapFS())
+
+	const name = "/root.txt"
+
+	for _, tc := range []struct {
+		op string
+		fn func() error
+	}{
+		{op: "Chmod", fn: func() error { return vfs.Chmod(name, 0o777) }},
+		{op: "Chtimes", fn: func() error { return vfs.Chtimes(name, time.Now(), time.Now()) }},
+		{op: "Mkdir", fn: func() error { return vfs.Mkdir("/new", 0o755) }},
+		{op: "Remove", fn: func() error { return vfs.Remove(name) }},
+		{op: "RemoveAll", fn: func() error { return vfs.RemoveAll("/dir") }},
+		{op: "Rename", fn: func() error { return vfs.Rename(name, "/new") }},
+		{op: "Truncate", fn: func() error { return vfs.Truncate(name, 0) }},
+		{op: "WriteFile", fn: func() error { return vfs.WriteFile("/new", nil, 0o644) }},
+		{op: "OpenFile", fn: func() error {
+			_, err := vfs.OpenFile(name, os.O_RDWR, 0)
+
+			return err
+		}},
+	} {
+		err := tc.fn()
+
+		var e *fs.PathError
+		if !errors.As(err, &e) && !errors.As(err, new(*os.LinkError)) {
+			t.Errorf("%s : want error to be a *PathError or a *LinkError, got %T", tc.op, err)
+		}
+
+		if !errors.Is(err, avfs.ErrPermDenied) {
+			t.Errorf("%s : want error to be %v, got %v", tc.op, avfs.ErrPermDenied, err)
+		}
+	}
+
+	err := vfs.Chown(name, 0, 0)
+	if !errors.Is(err, avfs.ErrOpNotPermitted) {
+		t.Errorf("Chown : want error to be %v, got %v", avfs.ErrOpNotPermitted, err)
+	}
+}
+
+func TestIofsFSSub(t *testing.T) {
+	vfs := newIofsFS(newMapFS())
+
+	sub, err := vfs.Sub("/implicit")
+	if err != nil {
+		t.Fatalf("Sub : want error to be nil, got %v", err)
+	}
+
+	data, err := sub.ReadFile("/sub/deep.txt")
+	if err != nil || string(data) != "deep" {
+		t.Errorf("ReadFile : want content to be %q, got %q, %v", "deep", data, err)
+	}
+
+	_, err = sub.Stat("/../root.txt")
+	if !errors.Is(err, avfs.ErrNoSuchFileOrDir) {
+		t.Errorf("Stat : want error to be %v, got %v", avfs.ErrNoSuchFileOrDir, err)
+	}
+
+	_, err = vfs.Sub("/root.txt")
+	if !errors.Is(err, avfs.ErrNotADirectory) {
+		t.Errorf("Sub : want error to be %v, got %v", avfs.ErrNotADirectory, err)
+	}
+}
+
+func TestIofsFSToIOFS(t *testing.T) {
+	memFS := memfs.New()
+
+	err := memFS.WriteFile("/file.txt", []byte("memfs"), avfs.DefaultFilePerm)
+	if err != nil {
+		t.Fatalf("WriteFile : want error to be nil, got %v", err)
+	}
+
+	vfs := iofsfs.New(avfs.ToIOFS(memFS))
+
+	data, err := vfs.ReadFile(avfs.FromUnixPath(vfs, "/file.txt"))
+	if err != nil || string(data) != "memfs" {
+		t.Errorf("ReadFile : want content to be %q, got %q, %v", "memfs", data, err)
+	}
+
+	matches, err := vfs.Glob(avfs.FromUnixPath(vfs, "/*.txt"))
+	if err != nil || len(matches) != 1 {
+		t.Errorf("Glob : want 1 match, got %v, %v", matches, err)
+	}
+}
@@ -0,0 +1,69 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package iofsfs
+
+import (
+	"io/fs"
+	"sync"
+
+	"github.com/avfs/avfs"
+)
+
+// IofsFS implements a read only file system over an io/fs file system using the avfs.VFS interface.
+type IofsFS struct {
+	fsys              fs.FS       // fsys is the io/fs file system.
+	err               avfs.Errors // err regroups errors depending on the OS emulated.
+	errOpNotPermitted error       // errOpNotPermitted is the error operation not permitted depending on the OS emulated.
+	errPermDenied     error       // errPermDenied is the error permission denied depending on the OS emulated.
+	name              string      // name is the name of the file system.
+	avfs.CurDirFn                 // CurDirFn provides current directory functions to a file system.
+	avfs.CurUserFn                // CurUserFn provides current user functions to a file system.
+	avfs.IdmFn                    // IdmFn provides identity manager functions to a file system.
+	avfs.UMaskFn                  // UMaskFn provides UMask functions to file systems.
+	avfs.FeaturesFn               // FeaturesFn provides features functions to a file system or an identity manager.
+	avfs.OSTypeFn                 // OSTypeFn provides OS type functions to a file system or an identity manager.
+}
+
+// IofsFile represents an open file descriptor.
+type IofsFile struct {
+	vfs        *IofsFS       // vfs is the io/fs file system of the file.
+	file       fs.File       // file is the file opened from the io/fs file system, nil once closed.
+	info       fs.FileInfo   // info is the file information from the io/fs file system.
+	name       string        // name is the name of the file.
+	absPath    string        // absPath is the absolute path of the file when it was opened (used by Chdir).
+	fsName     string        // fsName is the name of the file in the io/fs file system.
+	data       []byte        // data is the content of a file not implementing io.ReaderAt, read by the first read.
+	dirEntries []fs.DirEntry // dirEntries stores the file information returned by ReadDir function.
+	dirNames   []string      // dirNames stores the names of the file returned by Readdirnames function.
+	at         int64         // at is current position in the file used by Read function.
+	dirIndex   int           // dirIndex is the position of the current index for dirEntries ou dirNames slices.
+	loaded     bool          // loaded is true if data has been read from the io/fs file system.
+	mu         sync.Mutex    // mu is the Mutex used to access content of IofsFile.
+}
+
+// Options defines the initialization options of IofsFS.
+type Options struct {
+	Name   string      // Name is the name of the file system.
+	OSType avfs.OSType // OSType defines the operating system type.
+}
+
+// IofsInfo is the implementation of fs.FileInfo returned by Stat and Lstat.
+type IofsInfo struct {
+	fs.FileInfo        // FileInfo is the file information from the io/fs file system.
+	name        string // name is the base name of the file.
+	absPath     string // absPath is the absolute path of the file (used by SameFile function).
+}
@@ -18,16 +18,45 @@
 //
 // it supports several features :
 //   - can emulate Linux or Windows systems regardless of the host system
-//   - checks files permissions
+//   - checks files permissions, including for the supplementary groups of the users (see avfs.GroupMember)
 //   - supports different Identity managers
 //   - supports multiple concurrent users
 //   - supports Hard links
 //   - supports symbolic links
+//   - notifies size changes of files (see WatchSize)
+//   - watches the changes of files and directories (see NewWatcher)
+//   - samples the duration of operations (see SetSampleFunc)
+//   - models Windows security descriptors (see SetSecurityInfo)
+//   - models POSIX access and default ACLs (see SetACL)
+//   - supports Windows alternate data streams (see Streams)
+//   - generates and resolves DOS 8.3 short names (see Options.ShortNames)
+//   - normalizes Windows names (trailing dots and spaces) and rejects reserved device names (CON, NUL, ...)
+//   - emulates case-insensitive and case preserving volumes (see Options.CaseInsensitive)
+//   - limits the length of paths and names like the operating systems (see Options.PathLimits)
+//   - opens unnamed temporary files on Linux (see avfs.O_TMPFILE)
+//   - simulates crashes losing the writes not synced (see Options.UnsyncedBuffers and Crash)
+//   - uses an injectable clock for deterministic timestamps (see SetClock)
+//   - tracks the access and change times of files, the reads update the access time like relatime (see Options.Atime)
+//   - mounts Windows volumes from other memory file systems (see VolumeMount)
+//   - maps Windows network shares to drive letters (see MapNetworkDrive)
+//   - reads files without copying their content (see ReadFileShared)
+//   - composes read only files from extents of other readers (see CreateChunked)
+//   - tracks the paths changed since a mark (see Options.TrackChanges)
+//   - audits the flags used to open files (see Options.OpenAudit)
+//   - limits the number of open files (see SetMaxOpenFiles)
+//   - generates /etc/passwd and /etc/group from the identity manager (see Options.EtcFiles)
+//   - saves and restores its whole content (see Snapshot)
+//   - stores large files in blocks and sparse files, only the blocks of data written use memory
 package memfs
 
 import (
+	"errors"
+	"io"
 	"io/fs"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/avfs/avfs"
@@ -52,7 +81,11 @@ func (vfs *MemFS) Base(path string) string {
 
 // Chdir changes the current working directory to the named directory.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Chdir(dir string) error {
+func (vfs *MemFS) Chdir(dir string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChdir, time.Now(), &err)
+	}
+
 	const op = "chdir"
 
 	_, child, pi, err := vfs.searchNode(dir, slmLstat)
@@ -100,7 +133,22 @@ func (vfs *MemFS) Chdir(dir string) error {
 //
 // On Plan 9, the mode's permission bits, ModeAppend, ModeExclusive,
 // and ModeTemporary are used.
-func (vfs *MemFS) Chmod(name string, mode fs.FileMode) error {
+func (vfs *MemFS) Chmod(name string, mode fs.FileMode) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChmod, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalChmod, Path: name, Mode: mode})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchChmod, name)
+	}
+
 	const op = "chmod"
 
 	_, child, _, err := vfs.searchNode(name, slmEval)
@@ -115,6 +163,8 @@ func (vfs *MemFS) Chmod(name string, mode fs.FileMode) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
 	}
 
+	child.setChangeTime(vfs.Now())
+
 	return nil
 }
 
@@ -125,11 +175,27 @@ func (vfs *MemFS) Chmod(name string, mode fs.FileMode) error {
 //
 // On Windows or Plan 9, Chown always returns the syscall.EWINDOWS or
 // EPLAN9 error, wrapped in *PathError.
-func (vfs *MemFS) Chown(name string, uid, gid int) error {
+func (vfs *MemFS) Chown(name string, uid, gid int) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChown, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalChown, Path: name, Uid: uid, Gid: gid})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchChmod, name)
+	}
+
 	const op = "chown"
 
-	if (vfs.HasFeature(avfs.FeatIdentityMgr) && !vfs.User().IsAdmin()) || vfs.OSType() == avfs.OsWindows {
-		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+	err = vfs.chownError()
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
 	}
 
 	_, child, _, err := vfs.searchNode(name, slmEval)
@@ -138,8 +204,56 @@ func (vfs *MemFS) Chown(name string, uid, gid int) error {
 	}
 
 	child.Lock()
-	child.setOwner(uid, gid)
-	child.Unlock()
+	defer child.Unlock()
+
+	if !child.setOwner(uid, gid) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+	}
+
+	child.setChangeTime(vfs.Now())
+
+	return nil
+}
+
+// Chroot changes the root directory of the file system to the directory path (Linux only),
+// the paths and the symbolic links can't escape from it.
+// Like chroot(2), only an administrator can change the root directory.
+// The current directory is kept if it is inside the new root directory, otherwise it is the new root directory.
+// The clones of the file system (see Clone) keep their own root directory.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Chroot(path string) error {
+	const op = "chroot"
+
+	if vfs.OSType() != avfs.OsLinux {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.NotSupported}
+	}
+
+	if !vfs.User().IsAdmin() {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.OpNotPermitted}
+	}
+
+	_, child, pi, err := vfs.searchNode(path, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	c, ok := child.(*dirNode)
+	if !ok {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.NotADirectory}
+	}
+
+	root, curDir := pi.Path(), vfs.CurDir()
+
+	switch {
+	case root == "/":
+	case strings.HasPrefix(curDir, root+"/"):
+		curDir = curDir[len(root):]
+	default:
+		curDir = "/"
+	}
+
+	vfs.rootNode = c
+	_ = vfs.SetCurDir(curDir)
 
 	return nil
 }
@@ -150,10 +264,25 @@ func (vfs *MemFS) Chown(name string, uid, gid int) error {
 // The underlying file system may truncate or round the values to a
 // less precise time unit.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Chtimes(name string, _, mtime time.Time) error {
+func (vfs *MemFS) Chtimes(name string, atime, mtime time.Time) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChtimes, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalChtimes, Path: name, Atime: atime, Mtime: mtime})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchChmod, name)
+	}
+
 	const op = "chtimes"
 
-	_, child, _, err := vfs.searchNode(name, slmLstat)
+	_, child, _, err := vfs.searchNode(name, slmEval)
 	if err != vfs.err.FileExists || child == nil {
 		return &fs.PathError{Op: op, Path: name, Err: err}
 	}
@@ -161,10 +290,12 @@ func (vfs *MemFS) Chtimes(name string, _, mtime time.Time) error {
 	child.Lock()
 	defer child.Unlock()
 
-	if !child.setModTime(mtime, vfs.User()) {
+	if !child.setTimes(atime, mtime, vfs.User()) {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
 	}
 
+	child.setChangeTime(vfs.Now())
+
 	return nil
 }
 
@@ -195,6 +326,14 @@ func (vfs *MemFS) Clean(path string) string {
 	return avfs.Clean(vfs, path)
 }
 
+// Clone returns a shallow copy of the current file system sharing the same files.
+// The current user and the current directory of the copy can be changed independently.
+func (vfs *MemFS) Clone() avfs.VFS {
+	clone := *vfs
+
+	return &clone
+}
+
 // Create creates or truncates the named file. If the file already exists,
 // it is truncated. If the file does not exist, it is created with mode 0666
 // (before umask). If successful, methods on the returned DummyFile can
@@ -234,6 +373,10 @@ func (vfs *MemFS) Dir(path string) string {
 func (vfs *MemFS) EvalSymlinks(path string) (string, error) {
 	const op = "lstat"
 
+	if path == "" {
+		return ".", nil
+	}
+
 	_, _, pi, err := vfs.searchNode(path, slmEval)
 	if err != vfs.err.FileExists {
 		return "", &fs.PathError{Op: op, Path: pi.LeftPart(), Err: err}
@@ -257,6 +400,27 @@ func (vfs *MemFS) Getwd() (dir string, err error) {
 	return vfs.CurDir(), nil
 }
 
+// GetSecurityInfo returns the security descriptor of the named file (Windows only).
+// If the file has no explicit DACL, the returned DACL is derived from the permission bits of the file.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) GetSecurityInfo(name string) (*avfs.SecurityInfo, error) {
+	const op = "getsecurityinfo"
+
+	if vfs.OSType() != avfs.OsWindows {
+		return nil, &fs.PathError{Op: op, Path: name, Err: avfs.ErrSecurityWindows}
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	child.RLock()
+	defer child.RUnlock()
+
+	return child.securityInfo(), nil
+}
+
 // Glob returns the names of all files matching pattern or nil
 // if there is no matching file. The syntax of patterns is the same
 // as in Match. The pattern may describe hierarchical names such as
@@ -296,28 +460,103 @@ func (vfs *MemFS) Join(elem ...string) string {
 //
 // On Windows, it always returns the syscall.EWINDOWS error, wrapped
 // in *PathError.
-func (vfs *MemFS) Lchown(name string, uid, gid int) error {
+func (vfs *MemFS) Lchown(name string, uid, gid int) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLchown, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalLchown, Path: name, Uid: uid, Gid: gid})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchChmod, name)
+	}
+
 	const op = "lchown"
 
-	if (vfs.HasFeature(avfs.FeatIdentityMgr) && !vfs.User().IsAdmin()) || vfs.OSType() == avfs.OsWindows {
+	err = vfs.chownError()
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmLstat)
+	if err != vfs.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	child.Lock()
+	defer child.Unlock()
+
+	if !child.setOwner(uid, gid) {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
 	}
 
+	child.setChangeTime(vfs.Now())
+
+	return nil
+}
+
+// Lchtimes changes the access and modification times of the named file like Chtimes,
+// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+// A zero time.Time value leaves the corresponding file time unchanged.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Lchtimes(name string, atime, mtime time.Time) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLchtimes, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalLchtimes, Path: name, Atime: atime, Mtime: mtime})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchChmod, name)
+	}
+
+	const op = "lchtimes"
+
 	_, child, _, err := vfs.searchNode(name, slmLstat)
 	if err != vfs.err.FileExists || child == nil {
 		return &fs.PathError{Op: op, Path: name, Err: err}
 	}
 
 	child.Lock()
-	child.setOwner(uid, gid)
-	child.Unlock()
+	defer child.Unlock()
+
+	if !child.setTimes(atime, mtime, vfs.User()) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+	}
+
+	child.setChangeTime(vfs.Now())
 
 	return nil
 }
 
 // Link creates newname as a hard link to the oldname file.
 // If there is an error, it will be of type *LinkError.
-func (vfs *MemFS) Link(oldname, newname string) error {
+func (vfs *MemFS) Link(oldname, newname string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLink, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalLink, Path: oldname, NewPath: newname})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchCreate, newname)
+	}
+
 	const op = "link"
 
 	_, oChild, _, oerr := vfs.searchNode(oldname, slmLstat)
@@ -325,9 +564,13 @@ func (vfs *MemFS) Link(oldname, newname string) error {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: oerr}
 	}
 
-	nParent, _, pi, nerr := vfs.searchNode(newname, slmLstat)
-	if !vfs.isNotExist(nerr) {
-		if vfs.OSType() == avfs.OsWindows {
+	nParent, _, pi, nerr := vfs.searchNode(avfs.TrimSeparators(vfs, newname), slmLstat)
+	if vfs.isNotExist(nerr) && avfs.HasTrailingSeparator(vfs, newname) {
+		nerr = vfs.err.NoSuchFile
+	}
+
+	if !vfs.isNotExist(nerr) || !pi.IsLast() || avfs.HasTrailingSeparator(vfs, newname) {
+		if vfs.OSType() == avfs.OsWindows && nerr == vfs.err.FileExists {
 			nerr = avfs.ErrWinAlreadyExists
 		}
 
@@ -351,10 +594,14 @@ func (vfs *MemFS) Link(oldname, newname string) error {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
 	}
 
+	now := vfs.Now()
+
 	c.mu.Lock()
 	nParent.addChild(pi.Part(), c)
+	nParent.setModified(now)
 
 	c.nlink++
+	c.setChangeTime(now)
 	c.mu.Unlock()
 
 	return nil
@@ -364,18 +611,31 @@ func (vfs *MemFS) Link(oldname, newname string) error {
 // If the file is a symbolic link, the returned FileInfo
 // describes the symbolic link. Lstat makes no attempt to follow the link.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Lstat(path string) (fs.FileInfo, error) {
+func (vfs *MemFS) Lstat(path string) (info fs.FileInfo, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLstat, time.Now(), &err)
+	}
+
 	op := "lstat"
 	if vfs.OSType() == avfs.OsWindows {
 		op = "CreateFile"
 	}
 
-	_, child, pi, err := vfs.searchNode(path, slmLstat)
+	fileName, stream, err := vfs.splitStream(path)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	if stream != "" {
+		return vfs.statStream(op, path, fileName, stream)
+	}
+
+	_, child, pi, err := vfs.searchNode(fileName, slmLstat)
 	if err != vfs.err.FileExists || child == nil {
 		return nil, &fs.PathError{Op: op, Path: path, Err: err}
 	}
 
-	fst := child.fillStatFrom(pi.Part())
+	fst := fillStatFrom(child, pi.Part())
 
 	return fst, nil
 }
@@ -411,14 +671,30 @@ func (vfs *MemFS) Match(pattern, name string) (matched bool, err error) {
 // Mkdir creates a new directory with the specified name and permission
 // bits (before umask).
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Mkdir(name string, perm fs.FileMode) error {
+func (vfs *MemFS) Mkdir(name string, perm fs.FileMode) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnMkdir, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalMkdir, Path: name, Mode: perm})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchCreate, name)
+	}
+
 	const op = "mkdir"
 
 	if name == "" {
 		return &fs.PathError{Op: op, Path: "", Err: vfs.err.NoSuchDir}
 	}
 
-	parent, _, pi, err := vfs.searchNode(name, slmEval)
+	// Like mkdir(2), an existing file followed by a path separator exists.
+	parent, _, pi, err := vfs.searchNode(avfs.TrimSeparators(vfs, name), slmEval)
 	if !vfs.isNotExist(err) || !pi.IsLast() {
 		return &fs.PathError{Op: op, Path: name, Err: err}
 	}
@@ -447,9 +723,24 @@ func (vfs *MemFS) Mkdir(name string, perm fs.FileMode) error {
 // directories that MkdirAll creates.
 // If name is already a directory, MkdirAll does nothing
 // and returns nil.
-func (vfs *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+func (vfs *MemFS) MkdirAll(path string, perm fs.FileMode) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnMkdirAll, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalMkdirAll, Path: path, Mode: perm})()
+	}
+
 	const op = "mkdir"
 
+	if path == "" {
+		return &fs.PathError{Op: op, Path: "", Err: vfs.err.NoSuchDir}
+	}
+
 	parent, child, pi, err := vfs.searchNode(path, slmEval)
 	switch child.(type) {
 	case *dirNode:
@@ -462,6 +753,10 @@ func (vfs *MemFS) MkdirAll(path string, perm fs.FileMode) error {
 		return &fs.PathError{Op: op, Path: pi.LeftPart(), Err: vfs.err.NotADirectory}
 	}
 
+	if err == vfs.err.NameTooLong || err == avfs.ErrWinInvalidName {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
 	parent.mu.Lock()
 	defer parent.mu.Unlock()
 
@@ -478,6 +773,7 @@ func (vfs *MemFS) MkdirAll(path string, perm fs.FileMode) error {
 		}
 
 		dn = vfs.createDir(dn, part, perm)
+		vfs.sendWatch(avfs.WatchCreate, pi.LeftPart())
 
 		if !pi.Next() {
 			break
@@ -512,17 +808,59 @@ func (vfs *MemFS) Open(name string) (avfs.File, error) {
 // is passed, it is created with mode perm (before umask). If successful,
 // methods on the returned File can be used for I/O.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File, error) {
+func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (f avfs.File, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnOpenFile, time.Now(), &err)
+	}
+
+	if vfs.openAudit != nil {
+		defer vfs.auditOpen(name, flag, perm, &err)
+	}
+
 	const op = "open"
 
+	if !vfs.openFiles.acquire() {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.TooManyOpenFiles}
+	}
+
+	defer vfs.openFiles.releaseOnError(&err)
+
+	path, stream, err := vfs.splitStream(name)
+	if err != nil {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	if stream != "" {
+		return vfs.openStream(name, path, stream, flag, perm)
+	}
+
+	if flag&(os.O_CREATE|os.O_TRUNC) != 0 {
+		vfs.StartMutation()
+		defer vfs.EndMutation()
+
+		if vfs.journal != nil {
+			defer vfs.journaled(&err, &JournalEntry{Op: JournalOpen, Path: name, Flag: flag, Mode: perm})()
+		}
+	}
+
 	at := int64(0)
 	om := avfs.ToOpenMode(flag)
+	dirPath := ""
+
+	// Like open(2), a path ending with a path separator can't be created.
+	if om&avfs.OpenCreate != 0 && avfs.HasTrailingSeparator(vfs, path) {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+	}
 
-	parent, child, pi, err := vfs.searchNode(name, slmEval)
+	parent, child, pi, err := vfs.searchNode(path, slmEval)
 	if err != vfs.err.FileExists && !vfs.isNotExist(err) || !pi.IsLast() {
 		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
 	}
 
+	if om&avfs.OpenTmpFile != 0 {
+		return vfs.openTmpFile(name, child, err, om, perm)
+	}
+
 	if vfs.isNotExist(err) {
 		if om&avfs.OpenCreate == 0 {
 			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
@@ -540,6 +878,7 @@ func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File,
 		child = parent.children[part]
 		if child == nil {
 			child = vfs.createFile(parent, part, perm)
+			vfs.sendWatch(avfs.WatchCreate, name)
 			f := &MemFile{
 				nd:       child,
 				vfs:      vfs,
@@ -554,8 +893,15 @@ func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File,
 
 	switch c := child.(type) {
 	case *fileNode:
-		c.mu.Lock()
-		defer c.mu.Unlock()
+		// The concurrent opens of a file without truncation share its node,
+		// the content of a grafted file can be loaded by the open.
+		if om&avfs.OpenTruncate != 0 || c.graft != nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+		} else {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+		}
 
 		if !c.checkPermission(om, vfs.User()) {
 			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
@@ -565,8 +911,21 @@ func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File,
 			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.FileExists}
 		}
 
+		// Special files created by Mknod only hold metadata.
+		if c.mode.Type() != 0 {
+			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: avfs.ErrNoSuchDevice}
+		}
+
+		err = c.loadGraft()
+		if err != nil {
+			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: errors.Unwrap(err)}
+		}
+
 		if om&avfs.OpenTruncate != 0 {
+			vfs.markUnsynced(c)
 			c.truncate(0)
+			c.setWritten(vfs.Now(), vfs.fsetid())
+			vfs.sendWatch(avfs.WatchWrite, name)
 		}
 
 		if om&avfs.OpenAppend != 0 {
@@ -574,8 +933,10 @@ func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File,
 		}
 
 	case *dirNode:
-		c.mu.Lock()
-		defer c.mu.Unlock()
+		vfs.loadGraft(c)
+
+		c.mu.RLock()
+		defer c.mu.RUnlock()
 
 		if om&avfs.OpenWrite != 0 {
 			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
@@ -584,12 +945,15 @@ func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File,
 		if !c.checkPermission(om, vfs.User()) {
 			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
 		}
+
+		dirPath = pi.Path()
 	}
 
-	f := &MemFile{
+	f = &MemFile{
 		nd:       child,
 		vfs:      vfs,
 		name:     name,
+		dirPath:  dirPath,
 		at:       at,
 		openMode: om,
 	}
@@ -602,10 +966,74 @@ func (vfs *MemFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File,
 // If an error occurs reading the directory,
 // ReadDir returns the entries it was able to read before the error,
 // along with the error.
-func (vfs *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+func (vfs *MemFS) ReadDir(name string) (entries []fs.DirEntry, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnReadDir, time.Now(), &err)
+	}
+
 	return avfs.ReadDir(vfs, name)
 }
 
+// ReadDirInto reads the named directory into entries without allocating new entries,
+// entries of type *MemInfo already present in entries are reused.
+// The entries are sorted by filename and n is the number of entries of the directory.
+// If entries is too small to hold all the entries of the directory,
+// no entry is read and the error io.ErrShortBuffer is returned with the number of entries needed.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) ReadDirInto(name string, entries []fs.DirEntry) (n int, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnReadDir, time.Now(), &err)
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return 0, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	op := "readdirent"
+	if vfs.OSType() == avfs.OsWindows {
+		op = "readdir"
+	}
+
+	dn, ok := child.(*dirNode)
+	if !ok {
+		return 0, &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+
+	vfs.loadGraft(dn)
+
+	dn.mu.RLock()
+	defer dn.mu.RUnlock()
+
+	if !dn.checkPermission(avfs.OpenRead, vfs.User()) {
+		return 0, &fs.PathError{Op: "open", Path: name, Err: vfs.err.PermDenied}
+	}
+
+	n = len(dn.children)
+	if n > len(entries) {
+		return n, &fs.PathError{Op: op, Path: name, Err: io.ErrShortBuffer}
+	}
+
+	i := 0
+
+	for childName, nd := range dn.children {
+		fst, ok := entries[i].(*MemInfo)
+		if !ok || fst == nil {
+			fst = &MemInfo{}
+			entries[i] = fst
+		}
+
+		nd.fillStat(fst, childName)
+		i++
+	}
+
+	vfs.accessed(&dn.baseNode)
+
+	slices.SortFunc(entries[:n], func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return n, nil
+}
+
 // ReadFile reads the named file and returns the contents.
 // A successful call returns err == nil, not err == EOF.
 // Because ReadFile reads the whole file, it does not treat an EOF from Read
@@ -614,9 +1042,103 @@ func (vfs *MemFS) ReadFile(name string) ([]byte, error) {
 	return avfs.ReadFile(vfs, name)
 }
 
+// ReadFileShared reads the named file like ReadFile but returns the internal buffer of the file without copying it.
+// The returned data must not be modified. It is never modified by the file system either :
+// while data is shared, the first write to the file copies its content before modifying it.
+// release must be called once data is no longer used so that the next writes to the file
+// modify its content in place again, calling it more than once has no effect.
+// Directories, alternate data streams and files that can't be read are handled by ReadFile,
+// the content of chunked files (see CreateChunked) is copied.
+// Files larger than 1 MiB and sparse files are stored in blocks and can't be shared without a copy of their
+// content, ReadFileShared returns an error wrapping the NotSupported error of the OS type for them (use ReadFile).
+// If there is an error, release is a no-op function and the error will be of type *PathError.
+func (vfs *MemFS) ReadFileShared(name string) (data []byte, release func(), err error) {
+	const op = "open"
+
+	release = func() {}
+
+	if _, stream, _ := vfs.splitStream(name); stream != "" {
+		data, err = vfs.ReadFile(name)
+
+		return data, release, err
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return nil, release, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	c, ok := child.(*fileNode)
+	if !ok {
+		data, err = vfs.ReadFile(name)
+
+		return data, release, err
+	}
+
+	if vfs.openAudit != nil {
+		defer vfs.auditOpen(name, os.O_RDONLY, 0, &err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkPermission(avfs.OpenRead, vfs.User()) {
+		return nil, release, &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	err = c.loadGraft()
+	if err != nil {
+		return nil, release, &fs.PathError{Op: op, Path: name, Err: errors.Unwrap(err)}
+	}
+
+	if c.blocks != nil {
+		return nil, release, &fs.PathError{Op: op, Path: name, Err: vfs.err.NotSupported}
+	}
+
+	vfs.accessed(&c.baseNode)
+
+	if c.chunks != nil {
+		data = make([]byte, c.size())
+
+		_, err = c.readAt(data, 0)
+		if err != nil && err != io.EOF {
+			return nil, release, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+
+		return data, release, nil
+	}
+
+	if len(c.data) == 0 {
+		return []byte{}, release, nil
+	}
+
+	if c.shares == nil {
+		c.shares = new(int)
+	}
+
+	shares := c.shares
+	*shares++
+
+	release = sync.OnceFunc(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		*shares--
+		if *shares == 0 && c.shares == shares {
+			c.shares = nil
+		}
+	})
+
+	return c.data[:len(c.data):len(c.data)], release, nil
+}
+
 // Readlink returns the destination of the named symbolic link.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Readlink(name string) (string, error) {
+func (vfs *MemFS) Readlink(name string) (link string, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnReadlink, time.Now(), &err)
+	}
+
 	const op = "readlink"
 
 	_, child, _, err := vfs.searchNode(name, slmLstat)
@@ -651,14 +1173,53 @@ func (vfs *MemFS) Rel(basepath, targpath string) (string, error) {
 
 // Remove removes the named file or (empty) directory.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Remove(name string) error {
+func (vfs *MemFS) Remove(name string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRemove, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalRemove, Path: name})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchRemove, name)
+	}
+
 	const op = "remove"
 
-	parent, child, pi, err := vfs.searchNode(name, slmLstat)
+	path, stream, err := vfs.splitStream(name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	if stream != "" {
+		return vfs.removeStream(name, path, stream)
+	}
+
+	parent, child, pi, err := vfs.searchNode(avfs.TrimSeparators(vfs, path), slmLstat)
 	if err != vfs.err.FileExists || child == nil {
 		return &fs.PathError{Op: op, Path: name, Err: err}
 	}
 
+	// Like rmdir, a file or a symbolic link followed by a path separator is not removed.
+	if _, ok := child.(*dirNode); !ok && avfs.HasTrailingSeparator(vfs, path) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+
+	// Like rmdir, a directory can't be removed by a path ending with "." or "..".
+	if vfs.OSType() != avfs.OsWindows {
+		switch avfs.DotElem(vfs, path) {
+		case ".":
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+		case "..":
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
 	parent.mu.Lock()
 	defer parent.mu.Unlock()
 
@@ -666,6 +1227,10 @@ func (vfs *MemFS) Remove(name string) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
 	}
 
+	if parent.stickyDenied(child, vfs.User()) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+	}
+
 	child.Lock()
 	defer child.Unlock()
 
@@ -680,8 +1245,12 @@ func (vfs *MemFS) Remove(name string) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
 	}
 
+	now := vfs.Now()
+
 	parent.removeChild(part)
+	parent.setModified(now)
 	child.delete()
+	child.setChangeTime(now)
 
 	return nil
 }
@@ -691,7 +1260,18 @@ func (vfs *MemFS) Remove(name string) error {
 // it encounters. If the path does not exist, RemoveAll
 // returns nil (no error).
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) RemoveAll(path string) error {
+func (vfs *MemFS) RemoveAll(path string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRemoveAll, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalRemoveAll, Path: path})()
+	}
+
 	const op = "unlinkat"
 
 	if path == "" {
@@ -699,7 +1279,17 @@ func (vfs *MemFS) RemoveAll(path string) error {
 		return nil
 	}
 
-	parent, child, pi, err := vfs.searchNode(path, slmLstat)
+	// The current directory or the parent of a directory is never removed.
+	switch avfs.DotElem(vfs, path) {
+	case ".":
+		return &fs.PathError{Op: "RemoveAll", Path: path, Err: vfs.err.InvalidArgument}
+	case "..":
+		if vfs.OSType() != avfs.OsWindows {
+			return &fs.PathError{Op: op, Path: path, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
+	parent, child, pi, err := vfs.searchNode(avfs.TrimSeparators(vfs, path), slmLstat)
 	if vfs.isNotExist(err) {
 		return nil
 	}
@@ -711,7 +1301,7 @@ func (vfs *MemFS) RemoveAll(path string) error {
 	parent.mu.Lock()
 	defer parent.mu.Unlock()
 
-	if c, ok := child.(*dirNode); ok && len(c.children) != 0 {
+	if c, ok := child.(*dirNode); ok && len(c.children) != 0 && c.graft == nil {
 		err = vfs.removeAll(c)
 		if err != nil {
 			return &fs.PathError{Op: op, Path: path, Err: err}
@@ -722,51 +1312,150 @@ func (vfs *MemFS) RemoveAll(path string) error {
 		return &fs.PathError{Op: op, Path: path, Err: vfs.err.PermDenied}
 	}
 
+	if parent.stickyDenied(child, vfs.User()) {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.OpNotPermitted}
+	}
+
 	parent.removeChild(pi.Part())
+	parent.setModified(vfs.Now())
 	child.delete()
+	vfs.sendWatch(avfs.WatchRemove, path)
 
 	return nil
 }
 
+// removeAll removes the descendants of the directory parent,
+// like RemoveAll it removes everything it can and returns the first error encountered.
 func (vfs *MemFS) removeAll(parent *dirNode) error {
 	parent.mu.Lock()
 	defer parent.mu.Unlock()
 
-	if ok := parent.checkPermission(avfs.OpenWrite, vfs.User()); !ok {
+	u := vfs.User()
+	if ok := parent.checkPermission(avfs.OpenWrite, u); !ok {
 		return vfs.err.PermDenied
 	}
 
-	for _, child := range parent.children {
-		if c, ok := child.(*dirNode); ok {
+	var firstErr error
+
+	for name, child := range parent.children {
+		if parent.stickyDenied(child, u) {
+			if firstErr == nil {
+				firstErr = vfs.err.OpNotPermitted
+			}
+
+			continue
+		}
+
+		if c, ok := child.(*dirNode); ok && len(c.children) != 0 && c.graft == nil {
 			err := vfs.removeAll(c)
 			if err != nil {
-				return err
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				continue
 			}
 		}
 
+		parent.removeChild(name)
+		parent.setModified(vfs.Now())
 		child.delete()
 	}
 
-	return nil
+	return firstErr
 }
 
 // Rename renames (moves) oldpath to newpath.
 // If newpath already exists and is not a directory, Rename replaces it.
 // OS-specific restrictions may apply when oldpath and newpath are in different directories.
 // If there is an error, it will be of type *LinkError.
-func (vfs *MemFS) Rename(oldpath, newpath string) error {
+func (vfs *MemFS) Rename(oldpath, newpath string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRename, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalRename, Path: oldpath, NewPath: newpath})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchCreate, newpath)
+		defer vfs.notify(&err, avfs.WatchRename, oldpath)
+	}
+
+	return vfs.rename(oldpath, newpath, 0)
+}
+
+// Rename2 renames (moves) oldpath to newpath like Rename, flags modifies its behavior :
+// with RenameNoReplace it fails if newpath already exists,
+// with RenameExchange it atomically exchanges oldpath and newpath, both of them must exist.
+// RenameNoReplace and RenameExchange can't be combined.
+// If there is an error, it will be of type *LinkError.
+func (vfs *MemFS) Rename2(oldpath, newpath string, flags avfs.RenameFlag) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRename, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalRename, Path: oldpath, NewPath: newpath, Flag: int(flags)})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchCreate, newpath)
+		defer vfs.notify(&err, avfs.WatchRename, oldpath)
+	}
+
+	return vfs.rename(oldpath, newpath, flags)
+}
+
+// rename renames (moves) oldpath to newpath, the caller is responsible for sampling and journaling.
+func (vfs *MemFS) rename(oldpath, newpath string, flags avfs.RenameFlag) error {
 	const op = "rename"
 
-	oParent, oChild, oPI, oErr := vfs.searchNode(oldpath, slmLstat)
+	if flags&^(avfs.RenameNoReplace|avfs.RenameExchange) != 0 ||
+		flags == avfs.RenameNoReplace|avfs.RenameExchange {
+		err := error(avfs.ErrInvalidArgument)
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinNotSupported
+		}
+
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	oParent, oChild, oPI, oErr := vfs.searchNode(avfs.TrimSeparators(vfs, oldpath), slmLstat)
 	if oErr != vfs.err.FileExists {
 		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: oErr}
 	}
 
-	nParent, nChild, nPI, nErr := vfs.searchNode(newpath, slmLstat)
-	if nErr != vfs.err.FileExists && !vfs.isNotExist(nErr) {
+	if vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, oldpath) != "" {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: avfs.ErrDeviceBusy}
+	}
+
+	_, isDir := oChild.(*dirNode)
+	if !isDir && (avfs.HasTrailingSeparator(vfs, oldpath) || avfs.HasTrailingSeparator(vfs, newpath)) {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: vfs.err.NotADirectory}
+	}
+
+	nParent, nChild, nPI, nErr := vfs.searchNode(avfs.TrimSeparators(vfs, newpath), slmLstat)
+	if nErr != vfs.err.FileExists && (!vfs.isNotExist(nErr) || !nPI.IsLast()) {
 		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: nErr}
 	}
 
+	if vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, newpath) != "" && oChild == nChild {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: avfs.ErrDeviceBusy}
+	}
+
+	// Like MoveFile, a file can't be renamed to another volume.
+	if vfs.OSType() == avfs.OsWindows && oPI.VolumeName() != nPI.VolumeName() {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: avfs.ErrWinNotSameDevice}
+	}
+
 	oParent.mu.Lock()
 	defer oParent.mu.Unlock()
 
@@ -783,7 +1472,61 @@ func (vfs *MemFS) Rename(oldpath, newpath string) error {
 		}
 	}
 
+	if oChild != nChild {
+		u := vfs.User()
+
+		if oParent.stickyDenied(oChild, u, nParent) || (nChild != nil && nParent.stickyDenied(nChild, u, oParent)) {
+			return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: vfs.err.OpNotPermitted}
+		}
+	}
+
+	if flags == avfs.RenameExchange && nChild == nil {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: nErr}
+	}
+
+	// Like rename(2), a directory can't become a descendant of itself.
+	if vfs.isInside(nPI.Path(), oPI.Path()) || (flags == avfs.RenameExchange && vfs.isInside(oPI.Path(), nPI.Path())) {
+		err := error(avfs.ErrInvalidArgument)
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinSharingViolation
+		}
+
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	now := vfs.Now()
+
+	switch {
+	case flags == avfs.RenameExchange:
+		if oPI.Path() != nPI.Path() {
+			nParent.addChild(nPI.Part(), oChild)
+			oParent.addChild(oPI.Part(), nChild)
+			vfs.renamed(now, oParent, nParent, oChild, nChild)
+		}
+
+		return nil
+	case flags == avfs.RenameNoReplace && nChild != nil:
+		err := error(avfs.ErrFileExists)
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinAlreadyExists
+		}
+
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	// On a case-insensitive file system, the new name keeps the case given by newpath.
+	newName := nPI.Part()
+	if nChild != nil && nParent.folded != nil {
+		newName = foldedNewName(nPI.Part(), vfs.Base(avfs.TrimSeparators(vfs, newpath)))
+	}
+
 	if oPI.Path() == nPI.Path() {
+		if newName != nPI.Part() {
+			oParent.removeChild(oPI.Part())
+			oParent.addChild(newName, oChild)
+			vfs.renamed(now, oParent, oParent, oChild)
+		}
+
 		return nil
 	}
 
@@ -804,7 +1547,15 @@ func (vfs *MemFS) Rename(oldpath, newpath string) error {
 
 		switch nc := nChild.(type) {
 		case *fileNode:
+			// Like rename(2), nothing is done if oldpath and newpath are hard links to the same file.
+			if nc == oChild {
+				return nil
+			}
+
+			nc.Lock()
 			nc.delete()
+			nc.setChangeTime(now)
+			nc.Unlock()
 		default:
 			err := error(avfs.ErrFileExists)
 			if vfs.OSType() == avfs.OsWindows {
@@ -815,12 +1566,30 @@ func (vfs *MemFS) Rename(oldpath, newpath string) error {
 		}
 	}
 
-	nParent.addChild(nPI.Part(), oChild)
+	if newName != nPI.Part() {
+		nParent.removeChild(nPI.Part())
+	}
+
+	nParent.addChild(newName, oChild)
 	oParent.removeChild(oPI.Part())
+	vfs.renamed(now, oParent, nParent, oChild)
 
 	return nil
 }
 
+// renamed sets the modification and change times of the parent directories oParent and nParent
+// and the change time of the renamed nodes, the parent directories must be locked.
+func (vfs *MemFS) renamed(now time.Time, oParent, nParent *dirNode, nodes ...node) {
+	oParent.setModified(now)
+	nParent.setModified(now)
+
+	for _, nd := range nodes {
+		nd.Lock()
+		nd.setChangeTime(now)
+		nd.Unlock()
+	}
+}
+
 // SameFile reports whether fi1 and fi2 describe the same file.
 // For example, on Unix this means that the device and inode fields
 // of the two underlying structures are identical; on other systems
@@ -841,12 +1610,54 @@ func (*MemFS) SameFile(fi1, fi2 fs.FileInfo) bool {
 	return fs1.id == fs2.id
 }
 
+// SetSecurityInfo sets the owner and the DACL of the named file (Windows only).
+// Access checks then evaluate the entries of the DACL in order :
+// a deny entry matching the user and one of the requested rights denies the access, even to an administrator,
+// otherwise the access is granted if the allow entries matching the user grant all the requested rights.
+// Chmod updates the rights of the allow entries of the owner, the group and everyone.
+// A nil DACL removes the explicit DACL, access checks then use the permission bits of the file.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) SetSecurityInfo(name string, si *avfs.SecurityInfo) error {
+	const op = "setsecurityinfo"
+
+	if vfs.OSType() != avfs.OsWindows {
+		return &fs.PathError{Op: op, Path: name, Err: avfs.ErrSecurityWindows}
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	child.Lock()
+	defer child.Unlock()
+
+	if !child.setSecurityInfo(si, vfs.User()) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	child.setChangeTime(vfs.Now())
+
+	vfs.sendWatch(avfs.WatchChmod, name)
+
+	return nil
+}
+
 // SetUserByName sets the current user by name.
 // If the user is not found, the returned error is of type UnknownUserError.
 func (vfs *MemFS) SetUserByName(name string) error {
 	return avfs.SetUserByName(vfs, name)
 }
 
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *MemFS) SetUserByUid(uid, gid int) error {
+	return avfs.SetUserByUid(vfs, uid, gid)
+}
+
 // Split splits path immediately following the final Separator,
 // separating it into a directory and file name component.
 // If there is no Separator in path, Split returns an empty dir
@@ -858,27 +1669,43 @@ func (vfs *MemFS) Split(path string) (dir, file string) {
 
 // Stat returns a FileInfo describing the named file.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Stat(path string) (fs.FileInfo, error) {
+func (vfs *MemFS) Stat(path string) (info fs.FileInfo, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnStat, time.Now(), &err)
+	}
+
 	op := "stat"
 	if vfs.OSType() == avfs.OsWindows {
 		op = "CreateFile"
 	}
 
-	_, child, pi, err := vfs.searchNode(path, slmStat)
+	fileName, stream, err := vfs.splitStream(path)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	if stream != "" {
+		return vfs.statStream(op, path, fileName, stream)
+	}
+
+	_, child, pi, err := vfs.searchNode(fileName, slmStat)
 	if err != vfs.err.FileExists || child == nil {
 		return nil, &fs.PathError{Op: op, Path: path, Err: err}
 	}
 
-	fst := child.fillStatFrom(pi.Part())
+	fst := fillStatFrom(child, pi.Part())
 
 	return fst, nil
 }
 
 // Sub returns an FS corresponding to the subtree rooted at dir.
+// The root directory of the sub file system is dir, the paths and the symbolic links can't escape from it,
+// its current directory is its root directory.
+// On Windows, the sub file system only has the volume of dir.
 func (vfs *MemFS) Sub(dir string) (avfs.VFS, error) {
 	const op = "sub"
 
-	_, child, _, err := vfs.searchNode(dir, slmEval)
+	_, child, pi, err := vfs.searchNode(dir, slmEval)
 	if err != vfs.err.FileExists || child == nil {
 		return nil, &fs.PathError{Op: op, Path: dir, Err: err}
 	}
@@ -888,19 +1715,59 @@ func (vfs *MemFS) Sub(dir string) (avfs.VFS, error) {
 		return nil, &fs.PathError{Op: op, Path: dir, Err: vfs.err.NotADirectory}
 	}
 
+	// The journal, the tracked changes and the audit are shared with the root file system,
+	// the paths of the sub file system are recorded in the namespace of the root file system.
 	subFS := *vfs
 	subFS.rootNode = c
+	subFS.subDir = vfs.rootPath(pi.Path())
+	subFS.CurDirFn = avfs.CurDirFn{}
+
+	root := string(vfs.PathSeparator())
+
+	if vfs.OSType() == avfs.OsWindows {
+		absDir, _ := vfs.Abs(dir)
+		vol := avfs.VolumeName(vfs, absDir)
+
+		subFS.volumes = volumes{vol: newVolume(vol, c)}
+		root = vol + root
+	}
+
+	_ = subFS.SetCurDir(root)
 
 	return &subFS, nil
 }
 
 // Symlink creates newname as a symbolic link to oldname.
+// On Windows, only administrators can create symbolic links unless the option DevMode is set.
 // If there is an error, it will be of type *LinkError.
-func (vfs *MemFS) Symlink(oldname, newname string) error {
+func (vfs *MemFS) Symlink(oldname, newname string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnSymlink, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalSymlink, Path: oldname, NewPath: newname})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchCreate, newname)
+	}
+
 	const op = "symlink"
 
-	parent, _, pi, nerr := vfs.searchNode(newname, slmLstat)
-	if !vfs.isNotExist(nerr) {
+	if vfs.OSType() == avfs.OsWindows && !vfs.devMode && !vfs.User().IsAdmin() {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: avfs.ErrWinPrivilegeNotHeld}
+	}
+
+	parent, _, pi, nerr := vfs.searchNode(avfs.TrimSeparators(vfs, newname), slmLstat)
+	if vfs.isNotExist(nerr) && avfs.HasTrailingSeparator(vfs, newname) {
+		nerr = vfs.err.NoSuchFile
+	}
+
+	if !vfs.isNotExist(nerr) || !pi.IsLast() || avfs.HasTrailingSeparator(vfs, newname) {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: nerr}
 	}
 
@@ -946,7 +1813,22 @@ func (*MemFS) ToSysStat(info fs.FileInfo) avfs.SysStater {
 // Truncate changes the size of the named file.
 // If the file is a symbolic link, it changes the size of the link's target.
 // If there is an error, it will be of type *PathError.
-func (vfs *MemFS) Truncate(name string, size int64) error {
+func (vfs *MemFS) Truncate(name string, size int64) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnTruncate, time.Now(), &err)
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalTruncate, Path: name, Size: size})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchWrite, name)
+	}
+
 	op := "truncate"
 
 	_, child, _, err := vfs.searchNode(name, slmEval)
@@ -971,9 +1853,20 @@ func (vfs *MemFS) Truncate(name string, size int64) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
 	}
 
+	if c.readOnly {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
 	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.mode.Type() != 0 {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+	}
+
+	vfs.markUnsynced(c)
 	c.truncate(size)
-	c.mu.Unlock()
+	c.setWritten(vfs.Now(), vfs.fsetid())
 
 	return nil
 }
@@ -993,6 +1886,51 @@ func (vfs *MemFS) WalkDir(root string, fn fs.WalkDirFunc) error {
 	return avfs.WalkDir(vfs, root, fn)
 }
 
+// WatchSize subscribes to the size changes of the named file.
+// Each Write, WriteAt or Truncate modifying the size of the file sends a SizeEvent
+// on the returned channel until cancel is called.
+// Like inotify, the subscription follows the file and not its name :
+// a renamed file still sends events, a new file created with the same name does not.
+// If the receiver is too slow, the oldest pending events are dropped.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) WatchSize(name string) (events <-chan avfs.SizeEvent, cancel func(), err error) {
+	const op = "watchsize"
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	c, ok := child.(*fileNode)
+	if !ok {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkPermission(avfs.OpenRead, vfs.User()) {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	sw := &sizeWatcher{events: make(chan avfs.SizeEvent, sizeEventsMax), name: name}
+	c.sizeWatchers = append(c.sizeWatchers, sw)
+
+	var once sync.Once
+
+	cancel = func() {
+		once.Do(func() {
+			c.mu.Lock()
+			c.removeSizeWatcher(sw)
+			c.mu.Unlock()
+
+			close(sw.events)
+		})
+	}
+
+	return sw.events, cancel, nil
+}
+
 // WriteFile writes data to the named file, creating it if necessary.
 // If the file does not exist, WriteFile creates it with permissions perm (before umask);
 // otherwise WriteFile truncates it before writing, without changing permissions.
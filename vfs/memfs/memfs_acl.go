@@ -0,0 +1,309 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+	"slices"
+
+	"github.com/avfs/avfs"
+)
+
+// GetACL returns the POSIX ACL of type typ of the named file (Linux only).
+// The access ACL of a file without extended entries is the minimal ACL derived from its permission bits,
+// the default ACL of a directory without default ACL is nil.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) GetACL(name string, typ avfs.ACLType) ([]avfs.ACLEntry, error) {
+	const op = "getacl"
+
+	if vfs.OSType() != avfs.OsLinux {
+		return nil, &fs.PathError{Op: op, Path: name, Err: avfs.ErrACLLinux}
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	child.Lock()
+	defer child.Unlock()
+
+	switch typ {
+	case avfs.ACLAccess:
+		return child.accessACL(), nil
+	case avfs.ACLDefault:
+		dn, ok := child.(*dirNode)
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+		}
+
+		return slices.Clone(dn.defaultACL), nil
+	default:
+		return nil, &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+	}
+}
+
+// SetACL sets the POSIX ACL of type typ of the named file (Linux only).
+// A valid ACL contains exactly one ACLUserObj, ACLGroupObj and ACLOther entry,
+// an ACLMask entry is required if the ACL contains ACLUser or ACLGroup entries.
+// The permission bits of the file reflect the access ACL : the owner bits are the ACLUserObj entry,
+// the group bits are the ACLMask entry (or the ACLGroupObj entry without mask) and the other bits are the ACLOther entry.
+// The files and directories created in a directory with a default ACL inherit it as their access ACL,
+// restricted by the permissions given at creation instead of the umask, the directories also inherit it as their default ACL.
+// A nil default ACL removes the default ACL of the directory.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) SetACL(name string, typ avfs.ACLType, acl []avfs.ACLEntry) (err error) {
+	const op = "setacl"
+
+	if vfs.OSType() != avfs.OsLinux {
+		return &fs.PathError{Op: op, Path: name, Err: avfs.ErrACLLinux}
+	}
+
+	if (typ != avfs.ACLAccess && typ != avfs.ACLDefault) ||
+		((typ == avfs.ACLAccess || acl != nil) && !validACL(acl)) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalSetACL, Path: name, Flag: int(typ), ACL: slices.Clone(acl)})()
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	child.Lock()
+	defer child.Unlock()
+
+	if typ == avfs.ACLAccess {
+		if !child.setACL(acl, vfs.User()) {
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+		}
+
+		child.setChangeTime(vfs.Now())
+
+		vfs.sendWatch(avfs.WatchChmod, name)
+
+		return nil
+	}
+
+	dn, ok := child.(*dirNode)
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	if !dn.setDefaultACL(acl, vfs.User()) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+	}
+
+	dn.setChangeTime(vfs.Now())
+
+	vfs.sendWatch(avfs.WatchChmod, name)
+
+	return nil
+}
+
+// accessACL returns the access ACL of the node.
+// If the node has no extended ACL, the minimal ACL is derived from the permission bits of the node.
+func (bn *baseNode) accessACL() []avfs.ACLEntry {
+	if bn.acl == nil {
+		return []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenMode(bn.mode>>6) & 0o7},
+			{Tag: avfs.ACLGroupObj, Perm: avfs.OpenMode(bn.mode>>3) & 0o7},
+			{Tag: avfs.ACLOther, Perm: avfs.OpenMode(bn.mode) & 0o7},
+		}
+	}
+
+	// The permission bits of the node are the permissions of the owner, the mask and the other users.
+	acl := slices.Clone(bn.acl)
+	for i, e := range acl {
+		switch e.Tag {
+		case avfs.ACLUserObj:
+			acl[i].Perm = avfs.OpenMode(bn.mode>>6) & 0o7
+		case avfs.ACLMask:
+			acl[i].Perm = avfs.OpenMode(bn.mode>>3) & 0o7
+		case avfs.ACLOther:
+			acl[i].Perm = avfs.OpenMode(bn.mode) & 0o7
+		}
+	}
+
+	return acl
+}
+
+// checkACL checks if the extended ACL of the node grants the desired permissions (perm) to the user.
+// Like POSIX, the entries are checked in order : owner, named users, groups and other users,
+// the permissions of the named users and the groups are limited by the mask (the group bits of the mode).
+func (bn *baseNode) checkACL(perm avfs.OpenMode, u avfs.UserReader) bool {
+	mode := avfs.OpenMode(bn.mode)
+	mask := (mode >> 3) & 0o7
+
+	if bn.uid == u.Uid() {
+		return (mode>>6)&perm == perm
+	}
+
+	for _, e := range bn.acl {
+		if e.Tag == avfs.ACLUser && e.Id == u.Uid() {
+			return e.Perm&mask&perm == perm
+		}
+	}
+
+	groupMatch := false
+
+	for _, e := range bn.acl {
+		if (e.Tag == avfs.ACLGroupObj && avfs.InGroup(u, bn.gid)) || (e.Tag == avfs.ACLGroup && avfs.InGroup(u, e.Id)) {
+			if e.Perm&mask&perm == perm {
+				return true
+			}
+
+			groupMatch = true
+		}
+	}
+
+	if groupMatch {
+		return false
+	}
+
+	return mode&perm == perm
+}
+
+// setACL sets the access ACL of the node and its permission bits.
+func (bn *baseNode) setACL(acl []avfs.ACLEntry, u avfs.UserReader) bool {
+	if bn.readOnly || (bn.uid != u.Uid() && !u.IsAdmin()) {
+		return false
+	}
+
+	bn.acl, bn.mode = aclMode(acl, bn.mode)
+
+	return true
+}
+
+// setDefaultACL sets the default ACL of the directory.
+func (dn *dirNode) setDefaultACL(acl []avfs.ACLEntry, u avfs.UserReader) bool {
+	if dn.readOnly || (dn.uid != u.Uid() && !u.IsAdmin()) {
+		return false
+	}
+
+	dn.defaultACL = sortACL(acl)
+
+	return true
+}
+
+// inheritACL returns the access ACL and the mode of a node created with the permissions perm
+// in the directory dn having a default ACL. Like Linux, the umask is not applied.
+// The directory must be locked.
+func (dn *dirNode) inheritACL(mode, perm fs.FileMode) ([]avfs.ACLEntry, fs.FileMode) {
+	acl := slices.Clone(dn.defaultACL)
+	hasMask := slices.ContainsFunc(acl, func(e avfs.ACLEntry) bool { return e.Tag == avfs.ACLMask })
+
+	for i, e := range acl {
+		switch {
+		case e.Tag == avfs.ACLUserObj:
+			acl[i].Perm &= avfs.OpenMode(perm>>6) & 0o7
+		case e.Tag == avfs.ACLMask, e.Tag == avfs.ACLGroupObj && !hasMask:
+			acl[i].Perm &= avfs.OpenMode(perm>>3) & 0o7
+		case e.Tag == avfs.ACLOther:
+			acl[i].Perm &= avfs.OpenMode(perm) & 0o7
+		}
+	}
+
+	return aclMode(acl, mode|(perm&avfs.FileModeMask&^fs.ModePerm))
+}
+
+// aclMode returns the extended ACL to store in a node and the mode with the permission bits set from acl.
+// A minimal ACL is not stored.
+func aclMode(acl []avfs.ACLEntry, mode fs.FileMode) ([]avfs.ACLEntry, fs.FileMode) {
+	var user, group, mask, other avfs.OpenMode
+
+	hasMask := false
+
+	for _, e := range acl {
+		switch e.Tag {
+		case avfs.ACLUserObj:
+			user = e.Perm
+		case avfs.ACLGroupObj:
+			group = e.Perm
+		case avfs.ACLMask:
+			mask = e.Perm
+			hasMask = true
+		case avfs.ACLOther:
+			other = e.Perm
+		}
+	}
+
+	if hasMask {
+		group = mask
+	}
+
+	mode = mode&^fs.ModePerm | fs.FileMode(user<<6|group<<3|other)
+
+	if len(acl) == 3 {
+		return nil, mode
+	}
+
+	return sortACL(acl), mode
+}
+
+// sortACL returns a copy of acl sorted in the canonical order : by tag, then by id.
+func sortACL(acl []avfs.ACLEntry) []avfs.ACLEntry {
+	if acl == nil {
+		return nil
+	}
+
+	acl = slices.Clone(acl)
+	slices.SortFunc(acl, func(a, b avfs.ACLEntry) int {
+		if a.Tag != b.Tag {
+			return int(a.Tag) - int(b.Tag)
+		}
+
+		return a.Id - b.Id
+	})
+
+	return acl
+}
+
+// validACL returns true if acl is a valid POSIX ACL.
+func validACL(acl []avfs.ACLEntry) bool {
+	var count [avfs.ACLOther + 1]int
+
+	ids := make(map[avfs.ACLEntry]bool, len(acl))
+
+	for _, e := range acl {
+		if e.Tag < avfs.ACLUserObj || e.Tag > avfs.ACLOther || e.Perm&^0o7 != 0 {
+			return false
+		}
+
+		count[e.Tag]++
+
+		if e.Tag == avfs.ACLUser || e.Tag == avfs.ACLGroup {
+			key := avfs.ACLEntry{Tag: e.Tag, Id: e.Id}
+			if ids[key] {
+				return false
+			}
+
+			ids[key] = true
+		}
+	}
+
+	named := count[avfs.ACLUser] + count[avfs.ACLGroup]
+
+	return count[avfs.ACLUserObj] == 1 && count[avfs.ACLGroupObj] == 1 && count[avfs.ACLOther] == 1 &&
+		count[avfs.ACLMask] <= 1 && (named == 0 || count[avfs.ACLMask] == 1)
+}
@@ -0,0 +1,68 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+)
+
+// Records returns the calls to OpenFile recorded by the audit, in call order.
+func (a *OpenAudit) Records() []OpenRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]OpenRecord(nil), a.records...)
+}
+
+// Opened returns the records of the files opened under the directory dir (or dir itself)
+// with all the flags of flag, failed calls included.
+// For example, Opened("/data", os.O_TRUNC) returns the files truncated under /data.
+// dir must be an absolute and clean path of the root file system, flag 0 matches all the records.
+func (a *OpenAudit) Opened(dir string, flag int) []OpenRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var records []OpenRecord
+
+	for _, r := range a.records {
+		if r.Flag&flag == flag && a.isUnder(r.Path, dir) {
+			records = append(records, r)
+		}
+	}
+
+	return records
+}
+
+// Reset removes all the records of the audit.
+func (a *OpenAudit) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.records = nil
+}
+
+// auditOpen records a call to OpenFile in the audit of the file system, the path is recorded
+// with its symbolic links evaluated, as an absolute path of the root file system for a sub file system.
+func (vfs *MemFS) auditOpen(name string, flag int, perm fs.FileMode, err *error) {
+	path := vfs.rootPath(vfs.resolveChange(name, true))
+	a := vfs.openAudit
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.records = append(a.records, OpenRecord{Path: path, Flag: flag, Perm: perm, Err: *err})
+}
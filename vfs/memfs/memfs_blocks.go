@@ -0,0 +1,142 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io"
+	"maps"
+	"slices"
+)
+
+const (
+	// blockSize is the size of the blocks of a file stored in blocks.
+	blockSize = 4096
+
+	// blockMinSize is the size from which a file is stored in blocks.
+	// Smaller files are stored in a single slice, which is returned without copy by ReadFileShared.
+	blockMinSize = 1 << 20
+)
+
+// needBlocks returns true if the file must be stored in blocks to grow to size bytes
+// with a hole between its end and holeEnd.
+// The node must be locked.
+func (fn *fileNode) needBlocks(size, holeEnd int64) bool {
+	return fn.blocks != nil || fn.isLargeHole(holeEnd) || size > blockMinSize
+}
+
+// toBlocks converts the content of the file to blocks.
+// The node must be locked.
+func (fn *fileNode) toBlocks() {
+	if fn.blocks != nil {
+		return
+	}
+
+	bs := &blockStore{data: make(map[int64][]byte)}
+	bs.writeAt(fn.data, 0)
+
+	fn.data = nil
+	fn.shares = nil
+	fn.blocks = bs
+}
+
+// copy returns a deep copy of the blocks.
+func (bs *blockStore) copy() *blockStore {
+	data := maps.Clone(bs.data)
+	for i, b := range data {
+		data[i] = slices.Clone(b)
+	}
+
+	return &blockStore{data: data, size: bs.size}
+}
+
+// snapshot returns a copy of the blocks sharing their data, the shared blocks are copied
+// by the next modifications of bs.
+func (bs *blockStore) snapshot() *blockStore {
+	bs.shared = make(map[int64]struct{}, len(bs.data))
+	for i := range bs.data {
+		bs.shared[i] = struct{}{}
+	}
+
+	return &blockStore{data: maps.Clone(bs.data), size: bs.size}
+}
+
+// block returns the block i to be modified, a shared block is copied first.
+func (bs *blockStore) block(i int64) (block []byte, ok bool) {
+	block, ok = bs.data[i]
+	if !ok {
+		return nil, false
+	}
+
+	if _, shared := bs.shared[i]; shared {
+		block = slices.Clone(block)
+		bs.data[i] = block
+
+		delete(bs.shared, i)
+	}
+
+	return block, true
+}
+
+// readAt reads len(b) bytes of a file stored in blocks starting at offset off, the holes are read as zeros.
+// It returns io.EOF when the end of the file is reached before b is full.
+func (bs *blockStore) readAt(b []byte, off int64) (n int, err error) {
+	if off > bs.size {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(b)), bs.size)
+
+	for pos := off; pos < end; {
+		i, start := pos/blockSize, pos%blockSize
+		m := min(blockSize-start, end-pos)
+
+		if block, ok := bs.data[i]; ok {
+			copy(b[n:n+int(m)], block[start:])
+		} else {
+			clear(b[n : n+int(m)])
+		}
+
+		n += int(m)
+		pos += m
+	}
+
+	if n < len(b) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// writeAt writes b at the offset off of a file stored in blocks, only the blocks of data written are allocated.
+func (bs *blockStore) writeAt(b []byte, off int64) int {
+	for n := 0; n < len(b); {
+		pos := off + int64(n)
+		i, start := pos/blockSize, pos%blockSize
+
+		block, ok := bs.block(i)
+		if !ok {
+			block = make([]byte, blockSize)
+			bs.data[i] = block
+		}
+
+		n += copy(block[start:], b[n:])
+	}
+
+	bs.size = max(bs.size, off+int64(len(b)))
+
+	return len(b)
+}
@@ -17,7 +17,9 @@
 package memfs
 
 import (
+	"hash/fnv"
 	"io/fs"
+	"slices"
 
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/idm/memidm"
@@ -28,6 +30,10 @@ func New() *MemFS {
 	return NewWithOptions(nil)
 }
 
+// nodeIds is the last unique id used to identify the nodes of all the file systems,
+// so that the nodes of a volume mounted from another file system keep unique ids (see VolumeMount).
+var nodeIds uint64 //nolint:gochecknoglobals // Shared by all the file systems.
+
 // NewWithOptions returns a new memory file system (MemFS) with the selected Options.
 func NewWithOptions(opts *Options) *MemFS {
 	if opts == nil {
@@ -40,6 +46,9 @@ func NewWithOptions(opts *Options) *MemFS {
 	}
 
 	features := avfs.FeatHardlink | avfs.FeatSubFS | avfs.FeatSymlink | idm.Features() | avfs.BuildFeatures()
+	if opts.Strict {
+		features |= avfs.FeatStrict
+	}
 
 	user := opts.User
 	if opts.User == nil {
@@ -47,10 +56,14 @@ func NewWithOptions(opts *Options) *MemFS {
 	}
 
 	vfs := &MemFS{
-		dirMode:  fs.ModeDir,
-		fileMode: 0,
-		lastId:   new(uint64),
-		name:     opts.Name,
+		dirMode:   fs.ModeDir,
+		fileMode:  0,
+		lastId:    &nodeIds,
+		name:      opts.Name,
+		openFiles: &openFiles{},
+		locks:     newFileLocks(),
+		watchers:  &watchers{},
+		FreezeFn:  &avfs.FreezeFn{},
 	}
 
 	_ = vfs.SetFeatures(features)
@@ -59,6 +72,21 @@ func NewWithOptions(opts *Options) *MemFS {
 	_ = vfs.SetUser(user)
 
 	vfs.err.SetOSType(vfs.OSType())
+	vfs.shortNames = opts.ShortNames && vfs.OSType() == avfs.OsWindows
+	vfs.caseInsensitive = opts.CaseInsensitive
+	vfs.pathLimits = opts.PathLimits
+	vfs.atimeMode = opts.Atime
+	vfs.devMode = opts.DevMode && vfs.OSType() == avfs.OsWindows
+	vfs.SetMaxOpenFiles(opts.MaxOpenFiles)
+	vfs.SetClock(opts.Clock)
+
+	if vfs.atimeMode == AtimeDefault {
+		vfs.atimeMode = AtimeRelative
+		if vfs.OSType() == avfs.OsWindows {
+			vfs.atimeMode = AtimeNever
+		}
+	}
+
 	vfs.rootNode = vfs.createRootNode()
 
 	var volumeName string
@@ -69,7 +97,7 @@ func NewWithOptions(opts *Options) *MemFS {
 
 		volumeName = avfs.DefaultVolume
 		vfs.volumes = make(volumes)
-		vfs.volumes[volumeName] = vfs.rootNode
+		vfs.volumes[volumeName] = newVolume(volumeName, vfs.rootNode)
 	}
 
 	if len(opts.SystemDirs) == 0 {
@@ -77,8 +105,29 @@ func NewWithOptions(opts *Options) *MemFS {
 	}
 
 	_ = avfs.MkSystemDirs(vfs, opts.SystemDirs)
+
+	if opts.EtcFiles {
+		_ = vfs.SetUser(idm.AdminUser())
+		vfs.createEtcFiles()
+		_ = vfs.SetUser(user)
+	}
+
 	_ = vfs.SetUMask(avfs.UMask())
 
+	// The creation of the system directories is not recorded.
+	vfs.openAudit = opts.OpenAudit
+	if vfs.openAudit != nil {
+		vfs.openAudit.isUnder = vfs.isUnder
+	}
+
+	if opts.UnsyncedBuffers {
+		vfs.unsynced = &unsynced{durable: make(map[*fileNode]*durableContent)}
+	}
+
+	if opts.Journal != nil || opts.TrackChanges {
+		vfs.journal = &journal{j: opts.Journal, vfs: vfs, now: vfs.Now, track: opts.TrackChanges}
+	}
+
 	return vfs
 }
 
@@ -97,52 +146,59 @@ func (*MemFS) Type() string {
 func (vfs *MemFS) VolumeAdd(path string) error {
 	const op = "VolumeAdd"
 
-	if vfs.OSType() != avfs.OsWindows {
-		return &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeWindows}
-	}
-
-	vol := avfs.VolumeName(vfs, path)
-	if vol == "" {
-		return &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeNameInvalid}
-	}
-
-	_, ok := vfs.volumes[vol]
-	if ok {
-		return &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeAlreadyExists}
+	vol, err := vfs.newVolumeName(op, path)
+	if err != nil {
+		return err
 	}
 
-	vfs.volumes[vol] = vfs.createRootNode()
+	vfs.volumes[vol] = newVolume(vol, vfs.createRootNode())
 
 	return nil
 }
 
 // VolumeDelete deletes an existing volume and all its files from a Windows file system.
+// The files of a volume mounted by VolumeMount or of a mapped network drive are not deleted.
+// Deleting a network share also removes the network drives mapped to it.
 // If there is an error, it will be of type *PathError.
 func (vfs *MemFS) VolumeDelete(path string) error {
 	const op = "VolumeDelete"
 
-	if vfs.OSType() != avfs.OsWindows {
-		return &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeWindows}
+	vol, v, err := vfs.volume(op, path)
+	if err != nil {
+		return err
 	}
 
-	vol := avfs.VolumeName(vfs, path)
-	if vol == "" {
-		return &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeNameInvalid}
+	if !v.mounted {
+		err = vfs.removeAll(v.rootNode)
+		if err != nil {
+			return &fs.PathError{Op: op, Path: path, Err: err}
+		}
 	}
 
-	_, ok := vfs.volumes[vol]
-	if !ok {
-		return &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeNameInvalid}
+	delete(vfs.volumes, vol)
+
+	for name, v := range vfs.volumes {
+		if v.unc == vol {
+			delete(vfs.volumes, name)
+		}
 	}
 
-	err := vfs.RemoveAll(vol)
+	return nil
+}
+
+// VolumeInfo returns the metadata of a volume of a Windows file system.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) VolumeInfo(path string) (*avfs.VolumeInfo, error) {
+	const op = "VolumeInfo"
+
+	_, v, err := vfs.volume(op, path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	delete(vfs.volumes, vol)
+	info := v.info
 
-	return nil
+	return &info, nil
 }
 
 // VolumeList returns the volumes of the file system.
@@ -157,5 +213,112 @@ func (vfs *MemFS) VolumeList() []string {
 		l = append(l, v)
 	}
 
+	slices.Sort(l)
+
 	return l
 }
+
+// VolumeMount adds a new volume to a Windows file system backed by the directory dir of the file system src.
+// Files created in the volume are visible in src and conversely.
+// VolumeDelete only removes the volume, not the files of dir.
+// The volume shares the nodes of src, so src must have the same OS type, identity manager,
+// short names and case sensitivity options as vfs, otherwise the error avfs.ErrVolumeMountOptions is returned.
+// The owners and the permissions of the files are checked against the current user of vfs.
+// The mutations made through the volume are only reported to the journal and the watchers of vfs.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) VolumeMount(path string, src *MemFS, dir string) error {
+	const op = "VolumeMount"
+
+	vol, err := vfs.newVolumeName(op, path)
+	if err != nil {
+		return err
+	}
+
+	if src.OSType() != vfs.OSType() || src.Idm() != vfs.Idm() ||
+		src.shortNames != vfs.shortNames || src.caseInsensitive != vfs.caseInsensitive {
+		return &fs.PathError{Op: op, Path: dir, Err: avfs.ErrVolumeMountOptions}
+	}
+
+	_, child, _, err := src.searchNode(dir, slmEval)
+	if err != src.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: dir, Err: err}
+	}
+
+	dn, ok := child.(*dirNode)
+	if !ok {
+		return &fs.PathError{Op: op, Path: dir, Err: src.err.NotADirectory}
+	}
+
+	v := newVolume(vol, dn)
+	v.mounted = true
+	vfs.volumes[vol] = v
+
+	return nil
+}
+
+// VolumeSetInfo sets the label, the file system type and the serial number of a volume of a Windows file system.
+// The name of the volume in info is ignored.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) VolumeSetInfo(path string, info *avfs.VolumeInfo) error {
+	const op = "VolumeSetInfo"
+
+	vol, v, err := vfs.volume(op, path)
+	if err != nil {
+		return err
+	}
+
+	v.info = *info
+	v.info.Name = vol
+
+	return nil
+}
+
+// newVolume returns a new volume named name with rootNode as root directory.
+// The volume has a NTFS file system type and a serial number derived from its name.
+func newVolume(name string, rootNode *dirNode) *volume {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	return &volume{
+		rootNode: rootNode,
+		info:     avfs.VolumeInfo{Name: name, FSType: "NTFS", Serial: h.Sum32()},
+	}
+}
+
+// newVolumeName returns the name of a volume to be added to the file system.
+func (vfs *MemFS) newVolumeName(op, path string) (string, error) {
+	if vfs.OSType() != avfs.OsWindows {
+		return "", &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeWindows}
+	}
+
+	vol := avfs.VolumeName(vfs, path)
+	if vol == "" {
+		return "", &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	_, ok := vfs.volumes[vol]
+	if ok {
+		return "", &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeAlreadyExists}
+	}
+
+	return vol, nil
+}
+
+// volume returns the name and the existing volume of path.
+func (vfs *MemFS) volume(op, path string) (string, *volume, error) {
+	if vfs.OSType() != avfs.OsWindows {
+		return "", nil, &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeWindows}
+	}
+
+	vol := avfs.VolumeName(vfs, path)
+	if vol == "" {
+		return "", nil, &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	v, ok := vfs.volumes[vol]
+	if !ok {
+		return "", nil, &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	return vol, v, nil
+}
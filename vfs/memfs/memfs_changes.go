@@ -0,0 +1,367 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// Mark returns the current version of the file system, incremented by each mutation.
+// The version can be passed later to HasChangedSince and ChangedSince.
+// It returns 0 if neither the changes are tracked (see Options.TrackChanges) nor the mutations journaled.
+func (vfs *MemFS) Mark() uint64 {
+	if vfs.journal == nil {
+		return 0
+	}
+
+	vfs.journal.mu.Lock()
+	defer vfs.journal.mu.Unlock()
+
+	return vfs.journal.seq
+}
+
+// HasChangedSince reports whether the file or directory path or one of its descendants changed
+// after the version mark returned by Mark.
+// Removing or renaming a parent directory of path or restoring a snapshot is considered as a change of path.
+// It always returns true if the changes are not tracked (see Options.TrackChanges).
+func (vfs *MemFS) HasChangedSince(mark uint64, path string) bool {
+	if vfs.journal == nil || !vfs.journal.track {
+		return true
+	}
+
+	return vfs.SubtreeVersion(path) > mark
+}
+
+// SubtreeVersion returns the version of the last mutation of the file or directory path or of one of its descendants,
+// 0 if path didn't change since the creation of the file system or if the changes are not tracked.
+// The symbolic links of path are followed, so the changes made through an alias are included.
+// If path does not exist, it returns the version of the last mutation of its nearest existing parent directory.
+func (vfs *MemFS) SubtreeVersion(path string) uint64 {
+	jnl := vfs.journal
+	if jnl == nil || !jnl.track {
+		return 0
+	}
+
+	jnl.mu.Lock()
+	restored, linked := jnl.restored, jnl.linked
+	jnl.mu.Unlock()
+
+	nodes, ok := jnl.vfs.nodePath(vfs.rootPath(vfs.resolveChange(path, false)))
+	last := nodes[len(nodes)-1]
+	version := max(restored, last.base().version.Load())
+
+	// A file or a directory placed at its path after the mark changes all the paths under it.
+	for _, nd := range nodes[1:] {
+		version = max(version, nd.base().placed.Load())
+	}
+
+	if !ok {
+		return version
+	}
+
+	if _, isLink := last.(*symlinkNode); isLink {
+		if target, ok := jnl.vfs.nodePath(vfs.rootPath(vfs.resolveChange(path, true))); ok {
+			last = target[len(target)-1]
+		}
+	}
+
+	dn, ok := last.(*dirNode)
+	if !ok {
+		return max(version, last.base().version.Load())
+	}
+
+	version = max(version, dn.treeVersion.Load())
+
+	// The changes of the files having several hard links are only propagated to the parents of the changed path.
+	if linked > version {
+		version = max(version, dn.linkedVersion(version))
+	}
+
+	return version
+}
+
+// ChangedSince returns the sorted absolute paths changed after the version mark returned by Mark,
+// nil if the changes are not tracked (see Options.TrackChanges).
+// A removed file is reported by its parent directory.
+func (vfs *MemFS) ChangedSince(mark uint64) []string {
+	jnl := vfs.journal
+	if jnl == nil || !jnl.track {
+		return nil
+	}
+
+	jnl.mu.Lock()
+	linked := jnl.linked
+	jnl.mu.Unlock()
+
+	var paths []string
+
+	root := string(vfs.PathSeparator())
+	if vfs.OSType() != avfs.OsWindows {
+		vfs.rootNode.changedSince(vfs, mark, linked > mark, root, &paths)
+	}
+
+	for vol, v := range vfs.volumes {
+		v.rootNode.changedSince(vfs, mark, linked > mark, vol+root, &paths)
+	}
+
+	slices.Sort(paths)
+
+	return paths
+}
+
+// changedSince appends to paths the path of the directory dn and the paths of its descendants
+// changed after the version mark.
+// If linked is false, the subtrees which didn't change after mark are skipped.
+func (dn *dirNode) changedSince(vfs *MemFS, mark uint64, linked bool, path string, paths *[]string) {
+	if dn.treeVersion.Load() <= mark && !linked {
+		return
+	}
+
+	if dn.version.Load() > mark {
+		*paths = append(*paths, path)
+	}
+
+	dn.mu.RLock()
+	names := make([]string, 0, len(dn.children))
+	for name := range dn.children {
+		names = append(names, name)
+	}
+	dn.mu.RUnlock()
+
+	for _, name := range names {
+		dn.mu.RLock()
+		child := dn.children[name]
+		dn.mu.RUnlock()
+
+		if child == nil {
+			continue
+		}
+
+		childPath := vfs.Join(path, name)
+
+		if c, ok := child.(*dirNode); ok {
+			c.changedSince(vfs, mark, linked, childPath, paths)
+
+			continue
+		}
+
+		if child.base().version.Load() > mark {
+			*paths = append(*paths, childPath)
+		}
+	}
+}
+
+// linkedVersion returns the version of the last mutation of the files of the directory dn or of its descendants
+// if it is greater than version, version otherwise.
+func (dn *dirNode) linkedVersion(version uint64) uint64 {
+	dn.mu.RLock()
+	defer dn.mu.RUnlock()
+
+	for _, child := range dn.children {
+		switch c := child.(type) {
+		case *dirNode:
+			version = c.linkedVersion(version)
+		default:
+			version = max(version, c.base().version.Load())
+		}
+	}
+
+	return version
+}
+
+// trackChanges sets the versions of the nodes changed by the mutation of entry to its sequence number.
+// The paths of the changes are resolved in the root file system by changes.
+// The journal must be locked.
+func (jnl *journal) trackChanges(entry *JournalEntry, changes []change) {
+	seq := entry.Seq
+
+	switch entry.Op {
+	case JournalLink:
+		jnl.hardlinks = true
+	case JournalRestore:
+		// The restored snapshot may contain hard links.
+		jnl.hardlinks = true
+		jnl.restored = seq
+	}
+
+	for _, c := range changes {
+		nodes, ok := jnl.vfs.nodePath(c.path)
+
+		for _, nd := range nodes[:len(nodes)-1] {
+			nd.(*dirNode).treeVersion.Store(seq)
+		}
+
+		last := nodes[len(nodes)-1]
+		if dn, isDir := last.(*dirNode); isDir {
+			dn.treeVersion.Store(seq)
+		}
+
+		if !ok {
+			continue
+		}
+
+		last.base().version.Store(seq)
+
+		if c.placed {
+			last.base().placed.Store(seq)
+		}
+
+		if _, isFile := last.(*fileNode); isFile && jnl.hardlinks {
+			jnl.linked = seq
+		}
+	}
+}
+
+// changes returns the paths changed by the mutation of entry, resolved in the root file system.
+// The path of a removed file is the path of its parent directory.
+func (vfs *MemFS) changes(entry *JournalEntry) []change {
+	switch entry.Op {
+	case JournalRestore:
+		return []change{{path: vfs.rootPath(entry.Path)}}
+	case JournalRemove, JournalRemoveAll:
+		return []change{vfs.removedChange(entry.Path)}
+	case JournalRename:
+		if entry.Flag&int(avfs.RenameExchange) != 0 {
+			return []change{vfs.placedChange(entry.Path), vfs.placedChange(entry.NewPath)}
+		}
+
+		return []change{vfs.removedChange(entry.Path), vfs.placedChange(entry.NewPath)}
+	case JournalLink:
+		return []change{{path: vfs.rootPath(vfs.resolveChange(entry.Path, false))}, vfs.placedChange(entry.NewPath)}
+	case JournalSymlink:
+		// The path of a symbolic link is its target, which is not changed.
+		return []change{vfs.placedChange(entry.NewPath)}
+	case JournalGraft, JournalMkdir, JournalMkdirAll, JournalMknod:
+		return []change{vfs.placedChange(entry.Path)}
+	case JournalLchown, JournalLchtimes:
+		return []change{{path: vfs.rootPath(vfs.resolveChange(entry.Path, false))}}
+	case JournalOpen:
+		return []change{{path: vfs.rootPath(vfs.resolveChange(entry.Path, true)), placed: entry.Flag&os.O_CREATE != 0}}
+	default:
+		return []change{{path: vfs.rootPath(vfs.resolveChange(entry.Path, true))}}
+	}
+}
+
+// placedChange returns the change of a file created, linked or renamed to path.
+func (vfs *MemFS) placedChange(path string) change {
+	return change{path: vfs.rootPath(vfs.resolveChange(path, false)), placed: true}
+}
+
+// removedChange returns the change of the parent directory of the file removed from path.
+func (vfs *MemFS) removedChange(path string) change {
+	return change{path: vfs.Dir(vfs.rootPath(vfs.resolveChange(path, false)))}
+}
+
+// resolveChange returns the absolute path of path with its symbolic links evaluated.
+// The last symbolic link is only evaluated if follow is true.
+func (vfs *MemFS) resolveChange(path string, follow bool) string {
+	if follow {
+		_, child, pi, err := vfs.searchNode(path, slmEval)
+		if err == vfs.err.FileExists && child != nil {
+			return pi.Path()
+		}
+	}
+
+	absPath, _ := vfs.Abs(path)
+
+	dir := vfs.Dir(absPath)
+	if dir == absPath {
+		return absPath
+	}
+
+	_, child, pi, err := vfs.searchNode(dir, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return absPath
+	}
+
+	return vfs.Join(pi.Path(), vfs.Base(absPath))
+}
+
+// nodePath returns the nodes of the absolute path from the root directory of its volume,
+// the symbolic links of path being already evaluated.
+// If the path does not exist, ok is false and the last node is its nearest existing parent directory.
+func (vfs *MemFS) nodePath(absPath string) (nodes []node, ok bool) {
+	parent := vfs.rootNode
+
+	pi := avfs.NewPathIterator[*MemFS](vfs, absPath)
+	if pi.VolumeNameLen() > 0 {
+		vol, found := vfs.volumes[pi.VolumeName()]
+		if !found {
+			return []node{parent}, false
+		}
+
+		parent = vol.rootNode
+	}
+
+	nodes = append(nodes, parent)
+
+	for pi.Next() {
+		parent.mu.RLock()
+		child := parent.children[parent.longName(pi.Part())]
+		parent.mu.RUnlock()
+
+		if child == nil {
+			return nodes, false
+		}
+
+		nodes = append(nodes, child)
+
+		dn, isDir := child.(*dirNode)
+		if !isDir {
+			return nodes, pi.IsLast()
+		}
+
+		parent = dn
+	}
+
+	return nodes, true
+}
+
+// rootPath returns the absolute path of path in the namespace of the root file system,
+// which differs from its absolute path for a sub file system (see Sub).
+func (vfs *MemFS) rootPath(path string) string {
+	absPath, _ := vfs.Abs(path)
+	if vfs.subDir == "" {
+		return absPath
+	}
+
+	return vfs.Join(vfs.subDir, absPath[len(avfs.VolumeName(vfs, absPath)):])
+}
+
+// isUnder reports whether path is the directory dir or one of its descendants,
+// both paths being absolute and clean.
+func (vfs *MemFS) isUnder(path, dir string) bool {
+	if !strings.HasPrefix(path, dir) {
+		return false
+	}
+
+	return len(path) == len(dir) || vfs.IsPathSeparator(path[len(dir)]) || vfs.IsPathSeparator(dir[len(dir)-1])
+}
+
+// isInside reports whether path is a descendant of the directory dir, but not dir itself,
+// both paths being absolute and clean.
+func (vfs *MemFS) isInside(path, dir string) bool {
+	if vfs.caseInsensitive {
+		path, dir = foldName(path), foldName(dir)
+	}
+
+	return len(path) != len(dir) && vfs.isUnder(path, dir)
+}
@@ -0,0 +1,288 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// Check verifies the internal invariants of the file system and returns a detailed report:
+// each directory is referenced only once, the link counts of the files match the number of
+// directory entries referencing them, the names are valid and unique, the short names and
+// case folded indexes match the children and the types of the nodes match their modes.
+// MemFS nodes have no back-pointers to their parents, the directory tree is the only source of truth.
+// The file system should not be modified while it is checked.
+// Files of a sub file system (see Sub) linked outside of it are reported with an inconsistent link count.
+func (vfs *MemFS) Check() *CheckReport {
+	c := &checker{
+		vfs:    vfs,
+		report: &CheckReport{},
+		dirs:   make(map[*dirNode]string),
+		files:  make(map[*fileNode]*fileRefs),
+	}
+
+	if vfs.OSType() != avfs.OsWindows {
+		c.checkDir(string(vfs.PathSeparator()), vfs.rootNode)
+	} else {
+		vols := make([]string, 0, len(vfs.volumes))
+		for vol, v := range vfs.volumes {
+			if !v.mounted {
+				vols = append(vols, vol)
+			}
+		}
+
+		slices.Sort(vols)
+
+		for _, vol := range vols {
+			c.checkDir(vol+string(vfs.PathSeparator()), vfs.volumes[vol].rootNode)
+		}
+	}
+
+	var mismatches []*fileRefs
+
+	for fn, refs := range c.files {
+		fn.mu.RLock()
+		refs.nlink = fn.nlink
+		fn.mu.RUnlock()
+
+		if refs.count != refs.nlink {
+			mismatches = append(mismatches, refs)
+		}
+	}
+
+	slices.SortFunc(mismatches, func(a, b *fileRefs) int { return strings.Compare(a.path, b.path) })
+
+	for _, refs := range mismatches {
+		c.addProblem(refs.path, "link count is %d, referenced %d times", refs.nlink, refs.count)
+	}
+
+	c.report.Files = len(c.files)
+
+	return c.report
+}
+
+// OK returns true if no problem was found.
+func (r *CheckReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// String returns the problems found, one per line.
+func (r *CheckReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("no problem found (%d directories, %d files, %d symbolic links)", r.Dirs, r.Files, r.Symlinks)
+	}
+
+	var sb strings.Builder
+
+	for i, p := range r.Problems {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+
+		sb.WriteString(p.String())
+	}
+
+	return sb.String()
+}
+
+// String returns the path and the description of the problem.
+func (p CheckProblem) String() string {
+	return p.Path + ": " + p.Msg
+}
+
+// addProblem adds a problem to the report.
+func (c *checker) addProblem(path, format string, a ...any) {
+	c.report.Problems = append(c.report.Problems, CheckProblem{Path: path, Msg: fmt.Sprintf(format, a...)})
+}
+
+// checkDir checks the directory dn and its descendants.
+func (c *checker) checkDir(path string, dn *dirNode) {
+	if prevPath, ok := c.dirs[dn]; ok {
+		c.addProblem(path, "directory is also referenced by %s", prevPath)
+
+		return
+	}
+
+	c.dirs[dn] = path
+	c.report.Dirs++
+
+	dn.mu.RLock()
+
+	if dn.mode.Type() != fs.ModeDir {
+		c.addProblem(path, "directory has mode %s", dn.mode)
+	}
+
+	names := make([]string, 0, len(dn.children))
+	for name := range dn.children {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	nodes := make([]node, len(names))
+	for i, name := range names {
+		nodes[i] = dn.children[name]
+	}
+
+	c.checkShortNames(path, dn)
+	c.checkFoldedNames(path, dn, names)
+
+	dn.mu.RUnlock()
+
+	for i, name := range names {
+		childPath := c.vfs.Join(path, name)
+
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/"+string(c.vfs.PathSeparator())) {
+			c.addProblem(childPath, "invalid name %q", name)
+		}
+
+		switch nd := nodes[i].(type) {
+		case *dirNode:
+			c.checkDir(childPath, nd)
+		case *fileNode:
+			c.checkFile(childPath, nd)
+		case *symlinkNode:
+			c.checkSymlink(childPath, nd)
+		default:
+			c.addProblem(childPath, "unknown node type %T", nd)
+		}
+	}
+}
+
+// checkShortNames checks that the short names of the directory dn reference distinct children
+// and do not hide other children.
+// The directory must be locked.
+func (c *checker) checkShortNames(path string, dn *dirNode) {
+	longNames := make(map[string]string, len(dn.shortNames))
+
+	for shortName, longName := range dn.shortNames {
+		switch {
+		case dn.children[longName] == nil:
+			c.addProblem(path, "short name %s references a missing child %s", shortName, longName)
+		case dn.children[shortName] != nil:
+			c.addProblem(path, "short name %s of %s is also the name of a child", shortName, longName)
+		case longNames[longName] != "":
+			c.addProblem(path, "child %s has the short names %s and %s", longName, longNames[longName], shortName)
+		}
+
+		longNames[longName] = shortName
+	}
+}
+
+// checkFoldedNames checks that the case folded index of the directory dn references all its children
+// and that no two children differ only by case.
+// The directory must be locked.
+func (c *checker) checkFoldedNames(path string, dn *dirNode, names []string) {
+	if dn.folded == nil {
+		return
+	}
+
+	for key, name := range dn.folded {
+		if dn.children[name] == nil || foldName(name) != key {
+			c.addProblem(path, "case folded name %s references a missing child %s", key, name)
+		}
+	}
+
+	for _, name := range names {
+		indexed, ok := dn.folded[foldName(name)]
+
+		switch {
+		case !ok:
+			c.addProblem(path, "child %s is missing from the case folded index", name)
+		case indexed != name:
+			c.addProblem(path, "children %s and %s differ only by case", indexed, name)
+		}
+	}
+}
+
+// checkFile checks the file fn and its alternate data streams.
+func (c *checker) checkFile(path string, fn *fileNode) {
+	if refs, ok := c.files[fn]; ok {
+		refs.count++
+
+		return
+	}
+
+	c.files[fn] = &fileRefs{path: path, count: 1}
+
+	fn.mu.RLock()
+	defer fn.mu.RUnlock()
+
+	if !isFileType(fn.mode.Type()) {
+		c.addProblem(path, "file has mode %s", fn.mode)
+	}
+
+	if fn.nlink <= 0 {
+		c.addProblem(path, "referenced file has a link count of %d", fn.nlink)
+	}
+
+	if fn.graft != nil && !fn.graft.loaded && len(fn.data) != 0 {
+		c.addProblem(path, "grafted file is not loaded but has %d bytes of content", len(fn.data))
+	}
+
+	if fn.chunks != nil && len(fn.data) != 0 {
+		c.addProblem(path, "chunked file has %d bytes of content", len(fn.data))
+	}
+
+	if fn.blocks != nil && len(fn.data) != 0 {
+		c.addProblem(path, "file stored in blocks has %d bytes of content", len(fn.data))
+	}
+
+	streamNames := make([]string, 0, len(fn.streams))
+	for stream := range fn.streams {
+		streamNames = append(streamNames, stream)
+	}
+
+	slices.Sort(streamNames)
+
+	for _, stream := range streamNames {
+		sn := fn.streams[stream]
+		streamPath := path + ":" + stream
+
+		switch {
+		case sn == nil:
+			c.addProblem(streamPath, "stream is nil")
+		case sn.id != fn.id:
+			c.addProblem(streamPath, "stream id is %d, file id is %d", sn.id, fn.id)
+		case sn.nlink != 1:
+			c.addProblem(streamPath, "stream has a link count of %d", sn.nlink)
+		case len(sn.streams) != 0:
+			c.addProblem(streamPath, "stream has %d streams", len(sn.streams))
+		}
+	}
+}
+
+// checkSymlink checks the symbolic link sn.
+func (c *checker) checkSymlink(path string, sn *symlinkNode) {
+	c.report.Symlinks++
+
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	if sn.mode.Type() != fs.ModeSymlink {
+		c.addProblem(path, "symbolic link has mode %s", sn.mode)
+	}
+
+	if sn.link == "" {
+		c.addProblem(path, "symbolic link has an empty target")
+	}
+}
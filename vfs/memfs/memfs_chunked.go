@@ -0,0 +1,126 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/avfs/avfs"
+)
+
+// CreateChunked creates the named read only file whose content is the concatenation of extents,
+// like the members of a tar archive or the pieces of a file downloaded from a CDN.
+// The content is read from the readers of the extents each time the file is read,
+// it is never loaded in memory. A reader returning less bytes than the size of its extent
+// fails the read with io.ErrUnexpectedEOF.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) CreateChunked(name string, perm fs.FileMode, extents []Extent) (err error) {
+	const op = "open"
+
+	c := &chunks{extents: slices.Clone(extents), ends: make([]int64, len(extents))}
+	end := int64(0)
+
+	for i, e := range extents {
+		if e.Reader == nil || e.Offset < 0 || e.Size < 0 {
+			err := error(avfs.ErrInvalidArgument)
+			if vfs.OSType() == avfs.OsWindows {
+				err = avfs.ErrWinNotSupported
+			}
+
+			return &fs.PathError{Op: op, Path: name, Err: err}
+		}
+
+		end += e.Size
+		c.ends[i] = end
+	}
+
+	f, err := vfs.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	fn, ok := f.(*MemFile).nd.(*fileNode) //nolint:forcetypeassert // OpenFile returns a *MemFile.
+	_ = f.Close()
+
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+	}
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	// The file is journaled as created by OpenFile, then as chunked.
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalChunked, Path: name})()
+	}
+
+	fn.mu.Lock()
+	defer fn.mu.Unlock()
+
+	oldSize := fn.size()
+	fn.chunks = c
+	fn.readOnly = true
+	fn.notifySize(oldSize)
+
+	return nil
+}
+
+// size returns the size of a chunked file.
+func (c *chunks) size() int64 {
+	if len(c.ends) == 0 {
+		return 0
+	}
+
+	return c.ends[len(c.ends)-1]
+}
+
+// readAt reads len(b) bytes of a chunked file starting at offset off from the readers of its extents.
+// It returns io.EOF when the end of the file is reached before b is full.
+func (c *chunks) readAt(b []byte, off int64) (n int, err error) {
+	if off > c.size() {
+		return 0, io.EOF
+	}
+
+	// i is the first extent ending after off.
+	i, _ := slices.BinarySearch(c.ends, off+1)
+
+	for ; n < len(b) && i < len(c.extents); i++ {
+		e := c.extents[i]
+		pos := off + int64(n) - (c.ends[i] - e.Size)
+		m := int(min(int64(len(b)-n), e.Size-pos))
+
+		k, err := e.Reader.ReadAt(b[n:n+m], e.Offset+pos)
+		n += k
+
+		if k < m {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return n, err
+		}
+	}
+
+	if n < len(b) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
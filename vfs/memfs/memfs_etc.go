@@ -0,0 +1,140 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+const (
+	// etcGroup is the path of the group file.
+	etcGroup = "/etc/group"
+
+	// etcPasswd is the path of the password file.
+	etcPasswd = "/etc/passwd"
+
+	// etcShell is the login shell of the users of the password file.
+	etcShell = "/bin/sh"
+)
+
+// createEtcFiles creates the read only files /etc/passwd and /etc/group generated from
+// the users and the groups of the identity manager and updated after each of their changes.
+// The files are not created on Windows or if the identity manager does not list its users and groups.
+func (vfs *MemFS) createEtcFiles() {
+	idm := vfs.Idm()
+
+	lister, ok := idm.(avfs.IdmLister)
+	if !ok || vfs.OSType() == avfs.OsWindows {
+		return
+	}
+
+	passwd := vfs.createEtcFile(etcPasswd)
+	group := vfs.createEtcFile(etcGroup)
+
+	if passwd == nil || group == nil {
+		return
+	}
+
+	update := func() {
+		passwd.setEtcData(func() []byte { return vfs.passwdData(lister.Users()) })
+		group.setEtcData(func() []byte { return groupData(lister.Groups(), lister.Users()) })
+	}
+
+	update()
+
+	if sub, ok := idm.(avfs.IdmSubscriber); ok {
+		sub.Subscribe(func(avfs.IdmEvent) { update() })
+	}
+}
+
+// createEtcFile creates an empty read only file, the current user should be the administrator.
+func (vfs *MemFS) createEtcFile(name string) *fileNode {
+	err := vfs.MkdirAll(vfs.Dir(name), 0o755)
+	if err != nil {
+		return nil
+	}
+
+	f, err := vfs.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil
+	}
+
+	fn, ok := f.(*MemFile).nd.(*fileNode) //nolint:forcetypeassert // OpenFile returns a *MemFile.
+	_ = f.Close()
+
+	if !ok {
+		return nil
+	}
+
+	fn.mu.Lock()
+	defer fn.mu.Unlock()
+
+	fn.readOnly = true
+	fn.generated = true
+
+	return fn
+}
+
+// setEtcData replaces the content of a file by the data generated from the identity manager.
+// The data is generated once the file is locked, so that concurrent updates
+// can't replace the content of the file by outdated data.
+func (fn *fileNode) setEtcData(gen func() []byte) {
+	fn.mu.Lock()
+	defer fn.mu.Unlock()
+
+	oldSize := fn.size()
+	fn.data = gen()
+	fn.notifySize(oldSize)
+}
+
+// passwdData returns the content of the password file of users.
+func (vfs *MemFS) passwdData(users []avfs.UserReader) []byte {
+	var sb strings.Builder
+
+	for _, u := range users {
+		home := avfs.HomeDirUser(vfs, "", u)
+		_, _ = fmt.Fprintf(&sb, "%s:x:%d:%d:%s:%s:%s\n", u.Name(), u.Uid(), u.Gid(), u.Name(), home, etcShell)
+	}
+
+	return []byte(sb.String())
+}
+
+// groupData returns the content of the group file of groups, listing the users members of supplementary groups.
+// Like on Linux, the users are members of their primary group without being listed.
+func groupData(groups []avfs.GroupReader, users []avfs.UserReader) []byte {
+	members := make(map[int][]string)
+
+	for _, u := range users {
+		if gm, ok := u.(avfs.GroupMember); ok {
+			for _, gid := range gm.Groups() {
+				members[gid] = append(members[gid], u.Name())
+			}
+		}
+	}
+
+	var sb strings.Builder
+
+	for _, g := range groups {
+		_, _ = fmt.Fprintf(&sb, "%s:x:%d:%s\n", g.Name(), g.Gid(), strings.Join(members[g.Gid()], ","))
+	}
+
+	return []byte(sb.String())
+}
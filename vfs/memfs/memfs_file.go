@@ -17,6 +17,7 @@
 package memfs
 
 import (
+	"bytes"
 	"io"
 	"io/fs"
 	"time"
@@ -45,7 +46,7 @@ func (f *MemFile) Chdir() error {
 		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
 	}
 
-	_, ok := f.nd.(*dirNode)
+	c, ok := f.nd.(*dirNode)
 	if !ok {
 		err := error(avfs.ErrNotADirectory)
 		if f.vfs.OSType() == avfs.OsWindows {
@@ -55,7 +56,16 @@ func (f *MemFile) Chdir() error {
 		return &fs.PathError{Op: op, Path: f.name, Err: err}
 	}
 
-	_ = f.vfs.SetCurDir(f.name)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.checkPermission(avfs.OpenLookup, f.vfs.User()) {
+		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.PermDenied}
+	}
+
+	// The current directory is the directory as it was resolved when the file was opened,
+	// even if the file was opened with a relative path and the current directory changed since.
+	_ = f.vfs.SetCurDir(f.dirPath)
 
 	return nil
 }
@@ -69,6 +79,14 @@ func (f *MemFile) Chmod(mode fs.FileMode) error {
 		return fs.ErrInvalid
 	}
 
+	f.vfs.StartMutation()
+	defer f.vfs.EndMutation()
+
+	if f.vfs.journal != nil {
+		f.vfs.journal.apply.Lock()
+		defer f.vfs.journal.apply.Unlock()
+	}
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -80,6 +98,12 @@ func (f *MemFile) Chmod(mode fs.FileMode) error {
 		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
 	}
 
+	// The path is resolved before locking the node, which can be a directory of the path.
+	var path string
+	if f.vfs.journal != nil {
+		path = f.journalPath()
+	}
+
 	nd := f.nd
 
 	nd.Lock()
@@ -89,6 +113,14 @@ func (f *MemFile) Chmod(mode fs.FileMode) error {
 		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.PermDenied}
 	}
 
+	nd.setChangeTime(f.vfs.Now())
+
+	if path != "" {
+		f.vfs.appendEntry(&JournalEntry{Op: JournalChmod, Path: path, Mode: mode})
+	}
+
+	f.vfs.sendWatch(avfs.WatchChmod, f.name)
+
 	return nil
 }
 
@@ -104,6 +136,14 @@ func (f *MemFile) Chown(uid, gid int) error {
 		return fs.ErrInvalid
 	}
 
+	f.vfs.StartMutation()
+	defer f.vfs.EndMutation()
+
+	if f.vfs.journal != nil {
+		f.vfs.journal.apply.Lock()
+		defer f.vfs.journal.apply.Unlock()
+	}
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -119,6 +159,16 @@ func (f *MemFile) Chown(uid, gid int) error {
 		return &fs.PathError{Op: op, Path: f.name, Err: avfs.ErrWinNotSupported}
 	}
 
+	if f.vfs.HasFeature(avfs.FeatStrict) && !f.vfs.HasFeature(avfs.FeatIdentityMgr) {
+		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.NotSupported}
+	}
+
+	// The path is resolved before locking the node, which can be a directory of the path.
+	var path string
+	if f.vfs.journal != nil {
+		path = f.journalPath()
+	}
+
 	nd := f.nd
 
 	nd.Lock()
@@ -128,7 +178,17 @@ func (f *MemFile) Chown(uid, gid int) error {
 		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.OpNotPermitted}
 	}
 
-	nd.setOwner(uid, gid)
+	if !nd.setOwner(uid, gid) {
+		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.OpNotPermitted}
+	}
+
+	nd.setChangeTime(f.vfs.Now())
+
+	if path != "" {
+		f.vfs.appendEntry(&JournalEntry{Op: JournalChown, Path: path, Uid: uid, Gid: gid})
+	}
+
+	f.vfs.sendWatch(avfs.WatchChmod, f.name)
 
 	return nil
 }
@@ -154,9 +214,14 @@ func (f *MemFile) Close() error {
 		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
 	}
 
+	if f.locked.Load() {
+		f.vfs.locks.unlock(f, f.nd)
+	}
+
 	f.dirEntries = nil
 	f.dirNames = nil
 	f.nd = nil
+	f.vfs.openFiles.release()
 
 	return nil
 }
@@ -216,12 +281,22 @@ func (f *MemFile) Read(b []byte) (n int, err error) {
 		return 0, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.BadFileDesc}
 	}
 
+	// Like os.File, reading an empty buffer returns 0, nil even at the end of the file.
+	if len(b) == 0 {
+		return 0, nil
+	}
+
 	nd.mu.RLock()
-	n = copy(b, nd.data[f.at:])
+	n, err = nd.readAt(b, f.at)
+	f.vfs.accessed(&nd.baseNode)
 	nd.mu.RUnlock()
 
 	f.at += int64(n)
 
+	if err != nil && err != io.EOF {
+		return n, &fs.PathError{Op: op, Path: f.name, Err: err}
+	}
+
 	if n == 0 {
 		return 0, io.EOF
 	}
@@ -272,16 +347,14 @@ func (f *MemFile) ReadAt(b []byte, off int64) (n int, err error) {
 	nd.mu.RLock()
 	defer nd.mu.RUnlock()
 
-	if int(off) > len(nd.data) {
-		return 0, io.EOF
-	}
+	n, err = nd.readAt(b, off)
+	f.vfs.accessed(&nd.baseNode)
 
-	n = copy(b, nd.data[off:])
-	if n < len(b) {
-		return n, io.EOF
+	if err != nil && err != io.EOF {
+		return n, &fs.PathError{Op: op, Path: f.name, Err: err}
 	}
 
-	return n, nil
+	return n, err
 }
 
 // ReadDir reads the contents of the directory associated with the file f
@@ -325,27 +398,24 @@ func (f *MemFile) ReadDir(n int) (entries []fs.DirEntry, err error) {
 		return nil, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.NotADirectory}
 	}
 
-	if n <= 0 || f.dirEntries == nil {
+	if f.dirEntries == nil {
 		nd.mu.RLock()
 		entries = nd.dirEntries()
+		f.vfs.accessed(&nd.baseNode)
 		nd.mu.RUnlock()
 
 		f.dirIndex = 0
+		f.dirEntries = append(make([]fs.DirEntry, 0, len(entries)), entries...)
+	}
 
-		if n <= 0 {
-			f.dirEntries = nil
-
-			return entries, nil
-		}
+	start := f.dirIndex
+	if n <= 0 {
+		f.dirIndex = len(f.dirEntries)
 
-		f.dirEntries = entries
+		return f.dirEntries[start:], nil
 	}
 
-	start := f.dirIndex
 	if start >= len(f.dirEntries) {
-		f.dirIndex = 0
-		f.dirEntries = nil
-
 		return nil, io.EOF
 	}
 
@@ -402,27 +472,24 @@ func (f *MemFile) Readdirnames(n int) (names []string, err error) {
 		return nil, &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.NotADirectory}
 	}
 
-	if n <= 0 || f.dirNames == nil {
+	if f.dirNames == nil {
 		nd.mu.RLock()
 		names = nd.dirNames()
+		f.vfs.accessed(&nd.baseNode)
 		nd.mu.RUnlock()
 
 		f.dirIndex = 0
+		f.dirNames = append(make([]string, 0, len(names)), names...)
+	}
 
-		if n <= 0 {
-			f.dirNames = nil
-
-			return names, nil
-		}
+	start := f.dirIndex
+	if n <= 0 {
+		f.dirIndex = len(f.dirNames)
 
-		f.dirNames = names
+		return f.dirNames[start:], nil
 	}
 
-	start := f.dirIndex
 	if start >= len(f.dirNames) {
-		f.dirIndex = 0
-		f.dirNames = nil
-
 		return nil, io.EOF
 	}
 
@@ -461,11 +528,18 @@ func (f *MemFile) Seek(offset int64, whence int) (ret int64, err error) {
 
 	nd, ok := f.nd.(*fileNode)
 	if !ok {
+		// Like os.File, seeking to the start of a directory restarts the reading of its entries.
+		if offset == 0 && whence == io.SeekStart {
+			f.dirEntries = nil
+			f.dirNames = nil
+			f.dirIndex = 0
+		}
+
 		return 0, nil
 	}
 
 	nd.mu.RLock()
-	size := int64(len(nd.data))
+	size := nd.size()
 	nd.mu.RUnlock()
 
 	switch whence {
@@ -527,7 +601,7 @@ func (f *MemFile) Stat() (info fs.FileInfo, err error) {
 	}
 
 	name := f.vfs.Base(f.name)
-	fst := f.nd.fillStatFrom(name)
+	fst := fillStatFrom(f.nd, name)
 
 	return fst, nil
 }
@@ -553,6 +627,10 @@ func (f *MemFile) Sync() error {
 		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
 	}
 
+	if nd, ok := f.nd.(*fileNode); ok {
+		f.vfs.sync(nd)
+	}
+
 	return nil
 }
 
@@ -566,6 +644,14 @@ func (f *MemFile) Truncate(size int64) error {
 		return fs.ErrInvalid
 	}
 
+	f.vfs.StartMutation()
+	defer f.vfs.EndMutation()
+
+	if f.vfs.journal != nil {
+		f.vfs.journal.apply.Lock()
+		defer f.vfs.journal.apply.Unlock()
+	}
+
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
@@ -602,11 +688,20 @@ func (f *MemFile) Truncate(size int64) error {
 
 	nd.mu.Lock()
 
+	f.vfs.markUnsynced(nd)
 	nd.truncate(size)
-	nd.mtime = time.Now().UnixNano()
+	nd.setWritten(f.vfs.Now(), f.vfs.fsetid())
 
 	nd.mu.Unlock()
 
+	if f.vfs.journal != nil {
+		if path := f.journalPath(); path != "" {
+			f.vfs.appendEntry(&JournalEntry{Op: JournalTruncate, Path: path, Size: size})
+		}
+	}
+
+	f.vfs.sendWatch(avfs.WatchWrite, f.name)
+
 	return nil
 }
 
@@ -620,6 +715,14 @@ func (f *MemFile) Write(b []byte) (n int, err error) {
 		return 0, fs.ErrInvalid
 	}
 
+	f.vfs.StartMutation()
+	defer f.vfs.EndMutation()
+
+	if f.vfs.journal != nil {
+		f.vfs.journal.apply.Lock()
+		defer f.vfs.journal.apply.Unlock()
+	}
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -652,16 +755,24 @@ func (f *MemFile) Write(b []byte) (n int, err error) {
 
 	nd.mu.Lock()
 
-	n = copy(nd.data[f.at:], b)
-	if n < len(b) {
-		nd.data = append(nd.data, b[n:]...)
-		n = len(b)
-	}
+	oldSize := nd.size()
+
+	f.vfs.markUnsynced(nd)
+	n = nd.writeAt(b, f.at)
 
-	nd.mtime = time.Now().UnixNano()
+	nd.setWritten(f.vfs.Now(), f.vfs.fsetid())
+	nd.notifySize(oldSize)
 
 	nd.mu.Unlock()
 
+	if f.vfs.journal != nil {
+		if path := f.journalPath(); path != "" {
+			f.vfs.appendEntry(&JournalEntry{Op: JournalWrite, Path: path, Size: f.at, Data: bytes.Clone(b)})
+		}
+	}
+
+	f.vfs.sendWatch(avfs.WatchWrite, f.name)
+
 	f.at += int64(n)
 
 	return n, nil
@@ -681,6 +792,14 @@ func (f *MemFile) WriteAt(b []byte, off int64) (n int, err error) {
 		return 0, &fs.PathError{Op: "writeat", Path: f.name, Err: avfs.ErrNegativeOffset}
 	}
 
+	f.vfs.StartMutation()
+	defer f.vfs.EndMutation()
+
+	if f.vfs.journal != nil {
+		f.vfs.journal.apply.Lock()
+		defer f.vfs.journal.apply.Unlock()
+	}
+
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
@@ -713,17 +832,24 @@ func (f *MemFile) WriteAt(b []byte, off int64) (n int, err error) {
 
 	nd.mu.Lock()
 
-	diff := off + int64(len(b)) - nd.size()
-	if diff > 0 {
-		nd.data = append(nd.data, make([]byte, diff)...)
-	}
+	oldSize := nd.size()
 
-	n = copy(nd.data[off:], b)
+	f.vfs.markUnsynced(nd)
+	n = nd.writeAt(b, off)
 
-	nd.mtime = time.Now().UnixNano()
+	nd.setWritten(f.vfs.Now(), f.vfs.fsetid())
+	nd.notifySize(oldSize)
 
 	nd.mu.Unlock()
 
+	if f.vfs.journal != nil {
+		if path := f.journalPath(); path != "" {
+			f.vfs.appendEntry(&JournalEntry{Op: JournalWrite, Path: path, Size: off, Data: bytes.Clone(b)})
+		}
+	}
+
+	f.vfs.sendWatch(avfs.WatchWrite, f.name)
+
 	return n, nil
 }
 
@@ -781,6 +907,26 @@ func (info *MemInfo) Type() fs.FileMode {
 	return info.mode & fs.ModeType
 }
 
+// Atime returns the last access time of the file.
+func (info *MemInfo) Atime() time.Time {
+	return time.Unix(0, info.atime)
+}
+
+// Ctime returns the last change time of the file.
+func (info *MemInfo) Ctime() time.Time {
+	return time.Unix(0, info.ctime)
+}
+
+// BlockSize returns the preferred block size for file system I/O, the size of the blocks of large files.
+func (info *MemInfo) BlockSize() int64 {
+	return blockSize
+}
+
+// Dev returns the device id of the file system containing the file, always 0.
+func (info *MemInfo) Dev() uint64 {
+	return 0
+}
+
 // Gid returns the group id.
 func (info *MemInfo) Gid() int {
 	return info.gid
@@ -791,7 +937,17 @@ func (info *MemInfo) Uid() int {
 	return info.uid
 }
 
+// Ino returns the unique id of the file.
+func (info *MemInfo) Ino() uint64 {
+	return info.id
+}
+
 // Nlink returns the number of hard links.
 func (info *MemInfo) Nlink() uint64 {
 	return uint64(info.nlink)
 }
+
+// Rdev returns the device number of a device file, 0 for other files.
+func (info *MemInfo) Rdev() uint64 {
+	return info.rdev
+}
@@ -0,0 +1,118 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// addFoldedName indexes the child name of the directory by its case folded name if the index is enabled.
+// The directory must be locked.
+func (dn *dirNode) addFoldedName(name string) {
+	if dn.folded == nil {
+		return
+	}
+
+	dn.folded[foldName(name)] = name
+}
+
+// foldedName returns the name of the child of the directory matching name regardless of case,
+// or name if there is none or if the index is disabled.
+// The directory must be locked.
+func (dn *dirNode) foldedName(name string) string {
+	if dn.folded == nil {
+		return name
+	}
+
+	if childName, ok := dn.folded[foldName(name)]; ok {
+		return childName
+	}
+
+	return name
+}
+
+// removeFoldedName removes the child name of the directory from the case folded index.
+// The directory must be locked.
+func (dn *dirNode) removeFoldedName(name string) {
+	if dn.folded == nil {
+		return
+	}
+
+	key := foldName(name)
+	if dn.folded[key] == name {
+		delete(dn.folded, key)
+	}
+}
+
+// foldedNewName returns the name given by the caller if it only differs by case from the name of the existing child,
+// or the name of the existing child otherwise (short names, dot elements).
+func foldedNewName(childName, name string) string {
+	if name != childName && foldName(name) == foldName(childName) {
+		return name
+	}
+
+	return childName
+}
+
+// foldName returns the case folded form of name using Unicode simple case folding,
+// names differing only by case have the same folded form.
+// Names without upper case ASCII letters nor multibyte characters are returned without allocation.
+func foldName(name string) string {
+	i := 0
+	for ; i < len(name); i++ {
+		c := name[i]
+		if c >= utf8.RuneSelf || 'A' <= c && c <= 'Z' {
+			break
+		}
+	}
+
+	if i == len(name) {
+		return name
+	}
+
+	var sb strings.Builder
+
+	sb.Grow(len(name))
+	sb.WriteString(name[:i])
+
+	for _, r := range name[i:] {
+		sb.WriteRune(foldRune(r))
+	}
+
+	return sb.String()
+}
+
+// foldRune returns the representative of the Unicode simple folding orbit of r,
+// the lower case of the smallest rune of the orbit ('k' for 'K', 'k' and the Kelvin sign).
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+
+		return r
+	}
+
+	minRune := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		minRune = min(minRune, f)
+	}
+
+	return unicode.ToLower(minRune)
+}
@@ -0,0 +1,185 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"sync/atomic"
+
+	"github.com/avfs/avfs"
+)
+
+// Graft grafts the directory srcDir of the file system src at path, which must not exist.
+// The grafted files and directories are read only, they can be read but not modified.
+// Their content is loaded lazily from src : directories when they are traversed or read,
+// files when they are opened.
+// Symbolic links are grafted as is, they are resolved in the memory file system.
+// Removing path with RemoveAll ungrafts srcDir, src is never modified.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Graft(path string, src avfs.VFSBase, srcDir string) (err error) {
+	const op = "graft"
+
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalGraft, Path: path})()
+	}
+
+	info, err := src.Stat(srcDir)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: srcDir, Err: errors.Unwrap(err)}
+	}
+
+	if !info.IsDir() {
+		return &fs.PathError{Op: op, Path: srcDir, Err: vfs.err.NotADirectory}
+	}
+
+	parent, _, pi, err := vfs.searchNode(path, slmEval)
+	if !vfs.isNotExist(err) || !pi.IsLast() {
+		return &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	if !parent.checkPermission(avfs.OpenWrite|avfs.OpenLookup, vfs.User()) {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.PermDenied}
+	}
+
+	part := pi.Part()
+	if parent.children[part] != nil {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.FileExists}
+	}
+
+	parent.addChild(part, vfs.graftNode(parent, src, srcDir, info))
+	parent.setModified(vfs.Now())
+
+	return nil
+}
+
+// graftNode returns a read only node grafted from the file path of the file system src described by info.
+// It returns nil if the type of the file is not supported.
+func (vfs *MemFS) graftNode(parent *dirNode, src avfs.VFSBase, path string, info fs.FileInfo) node {
+	switch {
+	case info.IsDir():
+		dn := &dirNode{graft: &graft{src: src, path: path}, id: atomic.AddUint64(vfs.lastId, 1)}
+		if parent.shortNames != nil {
+			dn.shortNames = make(shortNames)
+		}
+
+		if parent.folded != nil {
+			dn.folded = make(foldedNames)
+		}
+
+		dn.setGraftInfo(src, info)
+
+		return dn
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := src.Readlink(path)
+		if err != nil {
+			return nil
+		}
+
+		sn := &symlinkNode{link: link}
+		sn.setGraftInfo(src, info)
+
+		return sn
+	case info.Mode().IsRegular():
+		fn := &fileNode{
+			graft: &graft{src: src, path: path, size: info.Size()},
+			id:    atomic.AddUint64(vfs.lastId, 1),
+			nlink: 1,
+		}
+
+		fn.setGraftInfo(src, info)
+
+		return fn
+	default:
+		return nil
+	}
+}
+
+// setGraftInfo sets the information of a read only node grafted from the file system src described by info.
+func (bn *baseNode) setGraftInfo(src avfs.VFSBase, info fs.FileInfo) {
+	sst := src.ToSysStat(info)
+
+	bn.initTimes(info.ModTime())
+	bn.mode = info.Mode()
+	bn.uid = sst.Uid()
+	bn.gid = sst.Gid()
+	bn.readOnly = true
+
+	if at, ok := sst.(avfs.AccessTimer); ok {
+		bn.atime.Store(at.Atime().UnixNano())
+	}
+
+	if ct, ok := sst.(avfs.ChangeTimer); ok {
+		bn.ctime = ct.Ctime().UnixNano()
+	}
+}
+
+// loadGraft loads the children of a directory grafted from another file system if needed.
+// The entries of the source directory which can't be read are ignored.
+func (vfs *MemFS) loadGraft(dn *dirNode) {
+	g := dn.graft
+	if g == nil {
+		return
+	}
+
+	dn.mu.Lock()
+	defer dn.mu.Unlock()
+
+	if g.loaded {
+		return
+	}
+
+	g.loaded = true
+
+	entries, _ := g.src.ReadDir(g.path)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		child := vfs.graftNode(dn, g.src, g.src.Join(g.path, entry.Name()), info)
+		if child != nil {
+			dn.addChild(entry.Name(), child)
+		}
+	}
+}
+
+// loadGraft loads the content of a file grafted from another file system if needed.
+// The node must be locked.
+func (fn *fileNode) loadGraft() error {
+	g := fn.graft
+	if g == nil || g.loaded {
+		return nil
+	}
+
+	data, err := g.src.ReadFile(g.path)
+	if err != nil {
+		return err
+	}
+
+	fn.data = data
+	g.loaded = true
+
+	return nil
+}
@@ -18,7 +18,9 @@ package memfs
 
 import (
 	"bytes"
+	"io"
 	"io/fs"
+	"slices"
 	"sort"
 	"sync/atomic"
 	"time"
@@ -36,31 +38,54 @@ import (
 // start and end, the beginning and ending position of the last found segment of absPath
 // err, one of the following errors :
 //
-//	ErrNoSuchFileOrDir when the node is not found
+//	ErrNoSuchFileOrDir when the node is not found or the path is empty
 //	ErrFileExists when the node is a file or directory
 //	ErrPermDenied when the current user doesn't have permissions on one of the nodes on the path
 //	ErrNotADirectory when a file node is found while the path segmentation is not finished
-//	ErrTooManySymlinks when more than slCountMax symbolic link resolutions have been performed.
+//	  or when the path of a file ends with a path separator, "." or ".."
+//	ErrTooManySymlinks when more than slCountMax (slCountMaxWin on Windows) symbolic link resolutions have been performed.
+//	ErrNameTooLong when the path or one of its names is too long (see Options.PathLimits).
 func (vfs *MemFS) searchNode(path string, slMode slMode) (
 	parent *dirNode, child node, pi *avfs.PathIterator[*MemFS], err error,
 ) {
 	slCount := 0
 	slResolved := false
 
+	slMax := slCountMax
+	if vfs.OSType() == avfs.OsWindows {
+		slMax = slCountMaxWin
+	}
+
 	absPath, _ := vfs.Abs(path)
 	pi = avfs.NewPathIterator[*MemFS](vfs, absPath)
 
+	if path == "" {
+		err = vfs.err.NoSuchDir
+
+		return
+	}
+
+	if vfs.pathLimits && avfs.CheckPathLen(vfs.OSType(), path) != nil {
+		err = vfs.err.NameTooLong
+
+		return
+	}
+
+	// A path ending with a path separator, "." or ".." can only be a directory,
+	// its last symbolic link is followed even by Lstat.
+	dirOnly := avfs.IsDirPath(vfs, path)
+
 	volNode := vfs.rootNode
 
 	if pi.VolumeNameLen() > 0 {
-		nd, ok := vfs.volumes[pi.VolumeName()]
+		vol, ok := vfs.volumes[pi.VolumeName()]
 		if !ok {
 			err = vfs.err.NoSuchDir
 
 			return
 		}
 
-		volNode = nd
+		volNode = vol.rootNode
 	}
 
 	parent = volNode
@@ -68,10 +93,35 @@ func (vfs *MemFS) searchNode(path string, slMode slMode) (
 	for pi.Next() {
 		name := pi.Part()
 
+		if vfs.OSType() == avfs.OsWindows {
+			winName, winErr := windowsName(name, pi.IsLast() && !dirOnly)
+			if winErr != nil {
+				err = winErr
+
+				return
+			}
+
+			if winName != name {
+				pi.ReplacePart(winName)
+
+				continue
+			}
+		}
+
+		vfs.loadGraft(parent)
+
 		parent.mu.RLock()
-		child = parent.children[name]
+		longName := parent.longName(name)
+		child = parent.children[longName]
 		parent.mu.RUnlock()
 
+		if longName != name {
+			// Resolve the short name and restart from the long name.
+			pi.ReplacePart(longName)
+
+			continue
+		}
+
 		if child == nil {
 			err = vfs.err.NoSuchDir
 			if pi.IsLast() {
@@ -103,7 +153,7 @@ func (vfs *MemFS) searchNode(path string, slMode slMode) (
 
 		case *fileNode:
 			// File permissions are checked by the calling function.
-			if pi.IsLast() {
+			if pi.IsLast() && !dirOnly {
 				err = vfs.err.FileExists
 
 				return
@@ -116,14 +166,14 @@ func (vfs *MemFS) searchNode(path string, slMode slMode) (
 		case *symlinkNode:
 			// Symlinks mode is always 0o777, no need to check permissions.
 			slCount++
-			if slCount > slCountMax {
+			if slCount > slMax {
 				err = vfs.err.TooManySymlinks
 
 				return
 			}
 
 			if pi.IsLast() {
-				if slMode == slmLstat {
+				if slMode == slmLstat && !dirOnly {
 					err = vfs.err.FileExists
 
 					return
@@ -150,52 +200,104 @@ func (vfs *MemFS) searchNode(path string, slMode slMode) (
 	return parent, parent, pi, vfs.err.FileExists
 }
 
+// fsetid returns true if the current user keeps the setuid and setgid bits of the files it modifies,
+// like the users having the CAP_FSETID capability on Linux : the administrators
+// or all the users if the file system has no identity manager.
+func (vfs *MemFS) fsetid() bool {
+	return !vfs.HasFeature(avfs.FeatIdentityMgr) || vfs.User().IsAdmin()
+}
+
 // createRootNode creates a root node for a file system.
 func (vfs *MemFS) createRootNode() *dirNode {
 	u := vfs.User()
 	dn := &dirNode{
 		baseNode: baseNode{
-			mtime: time.Now().UnixNano(),
-			mode:  fs.ModeDir | 0o755,
-			uid:   u.Uid(),
-			gid:   u.Gid(),
+			mode: fs.ModeDir | 0o755,
+			uid:  u.Uid(),
+			gid:  u.Gid(),
 		},
+		id: atomic.AddUint64(vfs.lastId, 1),
+	}
+
+	dn.initTimes(vfs.Now())
+
+	if vfs.shortNames {
+		dn.shortNames = make(shortNames)
+	}
+
+	if vfs.caseInsensitive {
+		dn.folded = make(foldedNames)
 	}
 
 	return dn
 }
 
 // createDir creates a new directory.
+// Like Linux, a directory created in a directory having the setgid bit set inherits its group and its setgid bit.
 func (vfs *MemFS) createDir(parent *dirNode, name string, perm fs.FileMode) *dirNode {
 	child := &dirNode{
 		baseNode: baseNode{
-			mtime: time.Now().UnixNano(),
-			mode:  vfs.dirMode | (perm & avfs.FileModeMask &^ vfs.UMask()),
-			uid:   vfs.User().Uid(),
-			gid:   vfs.User().Gid(),
+			mode: vfs.dirMode | (perm & avfs.FileModeMask &^ vfs.UMask()),
+			uid:  vfs.User().Uid(),
+			gid:  parent.newGid(vfs.User()),
 		},
 		children: nil,
+		id:       atomic.AddUint64(vfs.lastId, 1),
+	}
+
+	if parent.mode&fs.ModeSetgid != 0 {
+		child.mode |= fs.ModeSetgid
+	}
+
+	now := vfs.Now()
+	child.initTimes(now)
+
+	if parent.shortNames != nil {
+		child.shortNames = make(shortNames)
+	}
+
+	if parent.folded != nil {
+		child.folded = make(foldedNames)
+	}
+
+	if parent.defaultACL != nil {
+		child.acl, child.mode = parent.inheritACL(vfs.dirMode, perm)
+		child.defaultACL = slices.Clone(parent.defaultACL)
 	}
 
 	parent.addChild(name, child)
+	parent.setModified(now)
 
 	return child
 }
 
 // createFile creates a new file.
 func (vfs *MemFS) createFile(parent *dirNode, name string, perm fs.FileMode) *fileNode {
+	child := vfs.newFile(parent, perm)
+	child.nlink = 1
+
+	parent.addChild(name, child)
+	parent.setModified(time.Unix(0, child.mtime))
+
+	return child
+}
+
+// newFile returns a new file without link, with the default ACL of its parent directory.
+func (vfs *MemFS) newFile(parent *dirNode, perm fs.FileMode) *fileNode {
 	child := &fileNode{
 		baseNode: baseNode{
-			mtime: time.Now().UnixNano(),
-			mode:  vfs.fileMode | (perm & avfs.FileModeMask &^ vfs.UMask()),
-			uid:   vfs.User().Uid(),
-			gid:   vfs.User().Gid(),
+			mode: vfs.fileMode | (perm & avfs.FileModeMask &^ vfs.UMask()),
+			uid:  vfs.User().Uid(),
+			gid:  parent.newGid(vfs.User()),
 		},
-		id:    atomic.AddUint64(vfs.lastId, 1),
-		nlink: 1,
+		id: atomic.AddUint64(vfs.lastId, 1),
 	}
 
-	parent.addChild(name, child)
+	child.initTimes(vfs.Now())
+
+	if parent.defaultACL != nil {
+		child.acl, child.mode = parent.inheritACL(vfs.fileMode, perm)
+	}
 
 	return child
 }
@@ -204,66 +306,245 @@ func (vfs *MemFS) createFile(parent *dirNode, name string, perm fs.FileMode) *fi
 func (vfs *MemFS) createSymlink(parent *dirNode, name, link string) *symlinkNode {
 	child := &symlinkNode{
 		baseNode: baseNode{
-			mtime: time.Now().UnixNano(),
-			mode:  fs.ModeSymlink | fs.ModePerm,
-			uid:   vfs.User().Uid(),
-			gid:   vfs.User().Gid(),
+			mode: fs.ModeSymlink | fs.ModePerm,
+			uid:  vfs.User().Uid(),
+			gid:  parent.newGid(vfs.User()),
 		},
 		link: link,
 	}
 
+	now := vfs.Now()
+	child.initTimes(now)
+
 	parent.addChild(name, child)
+	parent.setModified(now)
 
 	return child
 }
 
+// accessed updates the access time of a node read according to the atime mode of the file system.
+// With AtimeRelative, the access time is only updated if it is older than the modification
+// or the change time of the node, or if it is older than relatimeMax.
+// The node must be locked, for reading at least.
+func (vfs *MemFS) accessed(bn *baseNode) {
+	switch vfs.atimeMode {
+	case AtimeNever:
+		return
+	case AtimeStrict:
+		bn.atime.Store(vfs.Now().UnixNano())
+	default:
+		now := vfs.Now().UnixNano()
+		atime := bn.atime.Load()
+
+		if atime <= bn.mtime || atime <= bn.ctime || now-atime >= int64(relatimeMax) {
+			bn.atime.Store(now)
+		}
+	}
+}
+
 // isNotExist is IsNotExist without unwrapping.
 func (vfs *MemFS) isNotExist(err error) bool {
 	return err == vfs.err.NoSuchDir || err == vfs.err.NoSuchFile
 }
 
+// base returns the common structure of the node.
+func (bn *baseNode) base() *baseNode {
+	return bn
+}
+
 // checkPermission checks if the current user has the desired permissions (perm) on the node.
+// The group permissions apply to the users having the group of the node as primary or supplementary group.
 func (bn *baseNode) checkPermission(perm avfs.OpenMode, u avfs.UserReader) bool {
 	const PermRWX = 0o007 // filter all permissions bits.
 
+	perm &= PermRWX
+
+	if bn.readOnly && perm&avfs.OpenWrite != 0 {
+		return false
+	}
+
+	if bn.dacl != nil {
+		return bn.checkDacl(perm, u)
+	}
+
 	if u.IsAdmin() {
 		return true
 	}
 
+	if bn.acl != nil {
+		return bn.checkACL(perm, u)
+	}
+
 	mode := avfs.OpenMode(bn.mode)
 
 	switch {
 	case bn.uid == u.Uid():
 		mode >>= 6
-	case bn.gid == u.Gid():
+	case avfs.InGroup(u, bn.gid):
 		mode >>= 3
 	}
 
-	perm &= PermRWX
-
 	return mode&perm == perm
 }
 
+// checkDacl checks if the explicit DACL of the node grants the desired permissions (perm) to the user.
+// Like Windows, the entries are evaluated in order : the access is denied by the first deny entry
+// matching one of the permissions not granted yet, and granted as soon as the allow entries grant all of them,
+// so an allow entry listed before a deny entry takes precedence.
+// Administrators are granted the permissions not denied, allow entries are not required for them.
+func (bn *baseNode) checkDacl(perm avfs.OpenMode, u avfs.UserReader) bool {
+	remaining := perm
+
+	for _, ace := range bn.dacl {
+		if !aceMatch(ace, u) {
+			continue
+		}
+
+		switch ace.Type {
+		case avfs.AceDeny:
+			if ace.Mask&remaining != 0 {
+				return false
+			}
+		case avfs.AceAllow:
+			remaining &^= ace.Mask
+			if remaining == 0 {
+				return true
+			}
+		}
+	}
+
+	return u.IsAdmin() || remaining == 0
+}
+
+// aceMatch returns true if the user is the trustee of the access control entry.
+func aceMatch(ace avfs.ACE, u avfs.UserReader) bool {
+	switch ace.TrusteeType {
+	case avfs.TrusteeEveryone:
+		return true
+	case avfs.TrusteeUser:
+		return ace.Id == u.Uid()
+	case avfs.TrusteeGroup:
+		return avfs.InGroup(u, ace.Id)
+	default:
+		return false
+	}
+}
+
 // Lock locks the node.
 func (bn *baseNode) Lock() {
 	bn.mu.Lock()
 }
 
-// setModTime sets the modification time of the node.
-func (bn *baseNode) setModTime(mtime time.Time, u avfs.UserReader) bool {
-	if bn.uid != u.Uid() && !u.IsAdmin() {
+// initTimes sets the access, modification and change times of a new node.
+func (bn *baseNode) initTimes(now time.Time) {
+	bn.atime.Store(now.UnixNano())
+	bn.mtime = now.UnixNano()
+	bn.ctime = now.UnixNano()
+}
+
+// setChangeTime sets the change time of the node.
+func (bn *baseNode) setChangeTime(ctime time.Time) {
+	bn.ctime = ctime.UnixNano()
+}
+
+// setModified sets the modification and change times of the node after a change of its content,
+// the entries of a directory being its content.
+func (bn *baseNode) setModified(now time.Time) {
+	bn.mtime = now.UnixNano()
+	bn.ctime = now.UnixNano()
+}
+
+// setTimes sets the access and modification times of the node, a zero time leaves the time unchanged.
+func (bn *baseNode) setTimes(atime, mtime time.Time, u avfs.UserReader) bool {
+	if bn.readOnly || (bn.uid != u.Uid() && !u.IsAdmin()) {
 		return false
 	}
 
-	bn.mtime = mtime.UnixNano()
+	if !atime.IsZero() {
+		bn.atime.Store(atime.UnixNano())
+	}
+
+	if !mtime.IsZero() {
+		bn.mtime = mtime.UnixNano()
+	}
 
 	return true
 }
 
+// allowedMode returns the permission bits of mode which can be set on the node by the user u :
+// like Linux, the setgid bit is silently cleared if u is neither an administrator nor a member of the group of the node.
+func (bn *baseNode) allowedMode(mode fs.FileMode, u avfs.UserReader) fs.FileMode {
+	mode &= avfs.FileModeMask
+
+	if !u.IsAdmin() && !avfs.InGroup(u, bn.gid) {
+		mode &^= fs.ModeSetgid
+	}
+
+	return mode
+}
+
+// owner returns the user id of the owner of the node.
+func (bn *baseNode) owner() int {
+	return bn.uid
+}
+
 // setOwner sets the owner of the node.
-func (bn *baseNode) setOwner(uid, gid int) {
+func (bn *baseNode) setOwner(uid, gid int) bool {
+	if bn.readOnly {
+		return false
+	}
+
 	bn.uid = uid
 	bn.gid = gid
+
+	return true
+}
+
+// securityInfo returns the security descriptor of the node.
+// If the node has no explicit DACL, the DACL is derived from the permission bits of the node.
+func (bn *baseNode) securityInfo() *avfs.SecurityInfo {
+	dacl := bn.dacl
+	if dacl == nil {
+		dacl = []avfs.ACE{
+			{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeUser, Id: bn.uid, Mask: avfs.OpenMode(bn.mode>>6) & 0o7},
+			{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeGroup, Id: bn.gid, Mask: avfs.OpenMode(bn.mode>>3) & 0o7},
+			{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeEveryone, Mask: avfs.OpenMode(bn.mode) & 0o7},
+		}
+	}
+
+	return &avfs.SecurityInfo{Dacl: slices.Clone(dacl), Owner: bn.uid}
+}
+
+// setDaclMode maps the permission bits of the node onto the allow entries of its explicit DACL
+// for the owner, the group and everyone.
+func (bn *baseNode) setDaclMode() {
+	for i, ace := range bn.dacl {
+		if ace.Type != avfs.AceAllow {
+			continue
+		}
+
+		switch {
+		case ace.TrusteeType == avfs.TrusteeUser && ace.Id == bn.uid:
+			bn.dacl[i].Mask = avfs.OpenMode(bn.mode>>6) & 0o7
+		case ace.TrusteeType == avfs.TrusteeGroup && ace.Id == bn.gid:
+			bn.dacl[i].Mask = avfs.OpenMode(bn.mode>>3) & 0o7
+		case ace.TrusteeType == avfs.TrusteeEveryone:
+			bn.dacl[i].Mask = avfs.OpenMode(bn.mode) & 0o7
+		}
+	}
+}
+
+// setSecurityInfo sets the owner and the explicit DACL of the node.
+// A nil DACL removes the explicit DACL of the node.
+func (bn *baseNode) setSecurityInfo(si *avfs.SecurityInfo, u avfs.UserReader) bool {
+	if bn.readOnly || (!u.IsAdmin() && (bn.uid != u.Uid() || si.Owner != bn.uid)) {
+		return false
+	}
+
+	bn.uid = si.Owner
+	bn.dacl = slices.Clone(si.Dacl)
+
+	return true
 }
 
 // Unlock unlocks the node.
@@ -271,6 +552,16 @@ func (bn *baseNode) Unlock() {
 	bn.mu.Unlock()
 }
 
+// RLock locks the node for reading.
+func (bn *baseNode) RLock() {
+	bn.mu.RLock()
+}
+
+// RUnlock unlocks the node for reading.
+func (bn *baseNode) RUnlock() {
+	bn.mu.RUnlock()
+}
+
 // dirNode
 
 // addChild adds a child to a dirNode.
@@ -280,11 +571,15 @@ func (dn *dirNode) addChild(name string, child node) {
 	}
 
 	dn.children[name] = child
+	dn.addShortName(name)
+	dn.addFoldedName(name)
 }
 
 // removeChild removes the child from the parent dirNode.
 func (dn *dirNode) removeChild(name string) {
 	delete(dn.children, name)
+	dn.removeShortName(name)
+	dn.removeFoldedName(name)
 }
 
 // delete removes all information from the node.
@@ -292,21 +587,30 @@ func (dn *dirNode) delete() {
 	dn.children = nil
 }
 
-// fillStatFrom returns a MemInfo (implementation of fs.FileInfo) from a dirNode dn named name.
-func (dn *dirNode) fillStatFrom(name string) *MemInfo {
+// fillStat fills fst (implementation of fs.FileInfo) from a dirNode dn named name.
+func (dn *dirNode) fillStat(fst *MemInfo, name string) {
 	dn.mu.RLock()
 
-	fst := &MemInfo{
+	*fst = MemInfo{
+		id:    dn.id,
 		name:  name,
 		size:  dn.size(),
 		mode:  dn.mode,
+		atime: dn.atime.Load(),
 		mtime: dn.mtime,
+		ctime: dn.ctime,
 		uid:   dn.uid,
 		gid:   dn.gid,
-		nlink: 0,
+		nlink: dn.nlink(),
 	}
 
 	dn.mu.RUnlock()
+}
+
+// fillStatFrom returns a MemInfo (implementation of fs.FileInfo) from a node nd named name.
+func fillStatFrom(nd node, name string) *MemInfo {
+	fst := &MemInfo{}
+	nd.fillStat(fst, name)
 
 	return fst
 }
@@ -322,7 +626,7 @@ func (dn *dirNode) dirEntries() []fs.DirEntry {
 	i := 0
 
 	for name, nd := range dn.children {
-		entries[i] = nd.fillStatFrom(name)
+		entries[i] = fillStatFrom(nd, name)
 		i++
 	}
 
@@ -353,21 +657,63 @@ func (dn *dirNode) dirNames() []string {
 
 // setMode sets the permissions of the directory node.
 func (dn *dirNode) setMode(mode fs.FileMode, u avfs.UserReader) bool {
-	if dn.uid != u.Uid() && !u.IsAdmin() {
+	if dn.readOnly || (dn.uid != u.Uid() && !u.IsAdmin()) {
 		return false
 	}
 
 	dn.mode &^= avfs.FileModeMask
-	dn.mode |= mode & avfs.FileModeMask
+	dn.mode |= dn.allowedMode(mode, u)
+	dn.setDaclMode()
 
 	return true
 }
 
+// newGid returns the group id of a node created in the directory by the user u :
+// the group of the directory if its setgid bit is set, the primary group of u otherwise.
+func (dn *dirNode) newGid(u avfs.UserReader) int {
+	if dn.mode&fs.ModeSetgid != 0 {
+		return dn.gid
+	}
+
+	return u.Gid()
+}
+
+// stickyDenied returns true if the sticky bit of the directory prevents the user u from removing,
+// renaming or replacing its entry child : only the owners of the entry and of the directory
+// and the administrators can. The lock of the directory must be held,
+// the lock of child is taken unless it is one of the directories already locked by the caller.
+func (dn *dirNode) stickyDenied(child node, u avfs.UserReader, locked ...*dirNode) bool {
+	if dn.mode&fs.ModeSticky == 0 || u.IsAdmin() || dn.uid == u.Uid() {
+		return false
+	}
+
+	if d, ok := child.(*dirNode); !ok || !slices.Contains(locked, d) {
+		child.Lock()
+		defer child.Unlock()
+	}
+
+	return child.owner() != u.Uid()
+}
+
 // size returns the size of the dirNode : number of children.
 func (dn *dirNode) size() int64 {
 	return int64(len(dn.children))
 }
 
+// nlink returns the link count of the directory : its entry in the parent directory,
+// its own '.' entry and the '..' entries of its subdirectories.
+func (dn *dirNode) nlink() int {
+	nlink := 2
+
+	for _, child := range dn.children {
+		if _, ok := child.(*dirNode); ok {
+			nlink++
+		}
+	}
+
+	return nlink
+}
+
 // fileNode
 
 // delete removes all information from the node, decrements the reference counter of the fileNode.
@@ -376,62 +722,234 @@ func (fn *fileNode) delete() {
 	fn.nlink--
 	if fn.nlink == 0 {
 		fn.data = nil
+		fn.chunks = nil
+		fn.blocks = nil
+		fn.streams = nil
 	}
 }
 
-// fillStatFrom returns a MemInfo (implementation of fs.FileInfo) from a fileNode fn named name.
-func (fn *fileNode) fillStatFrom(name string) *MemInfo {
+// fillStat fills fst (implementation of fs.FileInfo) from a fileNode fn named name.
+func (fn *fileNode) fillStat(fst *MemInfo, name string) {
 	fn.mu.RLock()
 
-	fst := &MemInfo{
+	*fst = MemInfo{
 		id:    fn.id,
 		name:  name,
 		size:  fn.size(),
 		mode:  fn.mode,
+		atime: fn.atime.Load(),
 		mtime: fn.mtime,
+		ctime: fn.ctime,
 		uid:   fn.uid,
 		gid:   fn.gid,
 		nlink: fn.nlink,
+		rdev:  fn.rdev,
 	}
 
 	fn.mu.RUnlock()
-
-	return fst
 }
 
 // setMode sets the permissions of the file node.
 func (fn *fileNode) setMode(mode fs.FileMode, u avfs.UserReader) bool {
-	if fn.uid != u.Uid() && !u.IsAdmin() {
+	if fn.readOnly || (fn.uid != u.Uid() && !u.IsAdmin()) {
 		return false
 	}
 
 	fn.mode &^= avfs.FileModeMask
-	fn.mode |= mode & avfs.FileModeMask
+	fn.mode |= fn.allowedMode(mode, u)
+	fn.setDaclMode()
 
 	return true
 }
 
+// setOwner sets the owner of the file, like chown(2) its setuid and setgid bits are cleared.
+func (fn *fileNode) setOwner(uid, gid int) bool {
+	if !fn.baseNode.setOwner(uid, gid) {
+		return false
+	}
+
+	fn.clearSetId()
+
+	return true
+}
+
+// setWritten sets the modification and change times of the file after a change of its content.
+// Like Linux for the users without the CAP_FSETID capability, the setuid and setgid bits are cleared
+// unless fsetid is true.
+func (fn *fileNode) setWritten(now time.Time, fsetid bool) {
+	fn.setModified(now)
+
+	if !fsetid {
+		fn.clearSetId()
+	}
+}
+
+// clearSetId clears the setuid bit of the file and its setgid bit if the group can execute it,
+// otherwise the setgid bit marks the file for mandatory locking and is kept.
+func (fn *fileNode) clearSetId() {
+	fn.mode &^= fs.ModeSetuid
+
+	if fn.mode&0o010 != 0 {
+		fn.mode &^= fs.ModeSetgid
+	}
+}
+
 // size returns the size of the file.
 func (fn *fileNode) size() int64 {
+	if fn.graft != nil && !fn.graft.loaded {
+		return fn.graft.size
+	}
+
+	if fn.chunks != nil {
+		return fn.chunks.size()
+	}
+
+	if fn.blocks != nil {
+		return fn.blocks.size
+	}
+
 	return int64(len(fn.data))
 }
 
+// readAt reads len(b) bytes of the content of the file starting at offset off.
+// It returns io.EOF when the end of the file is reached before b is full.
+// The node must be locked.
+func (fn *fileNode) readAt(b []byte, off int64) (int, error) {
+	if fn.chunks != nil {
+		return fn.chunks.readAt(b, off)
+	}
+
+	if fn.blocks != nil {
+		return fn.blocks.readAt(b, off)
+	}
+
+	if off > int64(len(fn.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(b, fn.data[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// notifySize sends a SizeEvent to the watchers of the file if its size is different from oldSize.
+// The node must be locked.
+func (fn *fileNode) notifySize(oldSize int64) {
+	newSize := fn.size()
+	if newSize == oldSize {
+		return
+	}
+
+	for _, sw := range fn.sizeWatchers {
+		sw.send(avfs.SizeEvent{Name: sw.name, OldSize: oldSize, NewSize: newSize})
+	}
+}
+
+// removeSizeWatcher removes a size watcher from the file.
+// The node must be locked.
+func (fn *fileNode) removeSizeWatcher(sw *sizeWatcher) {
+	for i, w := range fn.sizeWatchers {
+		if w == sw {
+			fn.sizeWatchers = append(fn.sizeWatchers[:i], fn.sizeWatchers[i+1:]...)
+
+			return
+		}
+	}
+}
+
 // truncate truncates the file.
+// The node must be locked.
 func (fn *fileNode) truncate(size int64) {
-	if size == 0 {
+	oldSize := fn.size()
+
+	switch {
+	case size == 0:
 		fn.data = nil
+		fn.blocks = nil
+	case fn.needBlocks(size, size):
+		fn.toBlocks()
+		fn.blocks.truncate(size)
+	case size > int64(len(fn.data)):
+		fn.unshare()
+		fn.grow(size, size)
+	default:
+		fn.data = fn.data[:size]
+	}
 
-		return
+	fn.notifySize(oldSize)
+}
+
+// writeAt writes b at the offset off of the file and returns the number of bytes written.
+// A small hole between the end of the file and off is filled with zeros, a large one turns the file
+// into a sparse file. Large files are stored in blocks (see blockStore), so that the cost of a write
+// only depends on the length of b, not on the size of the file.
+// The node must be locked.
+func (fn *fileNode) writeAt(b []byte, off int64) int {
+	if fn.needBlocks(off+int64(len(b)), off) {
+		fn.toBlocks()
+
+		return fn.blocks.writeAt(b, off)
+	}
+
+	fn.unshare()
+
+	if end := off + int64(len(b)); end > int64(len(fn.data)) {
+		fn.grow(end, off)
 	}
 
-	diff := int(size) - len(fn.data)
-	if diff > 0 {
-		fn.data = append(fn.data, bytes.Repeat([]byte{0}, diff)...)
+	return copy(fn.data[off:], b)
+}
+
+// grow extends the content of the file to size bytes, the bytes between the current end of the file
+// and zeroEnd are set to zero, the following bytes are left to be overwritten by the caller.
+// The capacity of the content grows geometrically so that successive appends are amortized.
+// The node must be locked.
+func (fn *fileNode) grow(size, zeroEnd int64) {
+	oldLen := len(fn.data)
+
+	if size > int64(cap(fn.data)) {
+		// The bytes beyond the previous length are zeroed by append.
+		fn.data = append(fn.data, make([]byte, int(size)-oldLen)...)
 
 		return
 	}
 
+	// The bytes beyond the length may contain data of a previous truncation.
 	fn.data = fn.data[:size]
+	clear(fn.data[oldLen:min(zeroEnd, size)])
+}
+
+// unshare copies the content of the file if it is shared by ReadFileShared, before it is modified in place.
+// The buffers already returned by ReadFileShared keep the previous content.
+// The node must be locked.
+func (fn *fileNode) unshare() {
+	if fn.shares == nil {
+		return
+	}
+
+	fn.data = bytes.Clone(fn.data)
+	fn.shares = nil
+}
+
+// sizeWatcher
+
+// send sends an event without blocking, the oldest pending event is dropped if the channel is full.
+func (sw *sizeWatcher) send(event avfs.SizeEvent) {
+	for {
+		select {
+		case sw.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-sw.events:
+		default:
+		}
+	}
 }
 
 // symlinkNode
@@ -441,23 +959,23 @@ func (sn *symlinkNode) delete() {
 	sn.link = ""
 }
 
-// fillStatFrom returns a MemInfo (implementation of fs.FileInfo) from a symlinkNode named name.
-func (sn *symlinkNode) fillStatFrom(name string) *MemInfo {
+// fillStat fills fst (implementation of fs.FileInfo) from a symlinkNode named name.
+func (sn *symlinkNode) fillStat(fst *MemInfo, name string) {
 	sn.mu.RLock()
 
-	fst := &MemInfo{
+	*fst = MemInfo{
 		name:  name,
 		size:  sn.size(),
 		mode:  sn.mode,
+		atime: sn.atime.Load(),
 		mtime: sn.mtime,
+		ctime: sn.ctime,
 		uid:   sn.uid,
 		gid:   sn.gid,
-		nlink: 0,
+		nlink: 1,
 	}
 
 	sn.mu.RUnlock()
-
-	return fst
 }
 
 // setMode sets the permissions of the symlink node.
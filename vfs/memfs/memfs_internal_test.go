@@ -19,7 +19,11 @@
 package memfs
 
 import (
+	"bytes"
+	"errors"
 	"io/fs"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/avfs/avfs"
@@ -137,3 +141,363 @@ func TestSearchNode(t *testing.T) {
 		}
 	}
 }
+
+func TestFoldName(t *testing.T) {
+	cases := []struct {
+		name, want string
+	}{
+		{name: "file.txt", want: "file.txt"},
+		{name: "File.TXT", want: "file.txt"},
+		{name: "\u212Aelvin", want: "kelvin"},
+		{name: "STRA\u00DFE", want: "stra\u00dfe"},
+		{name: "\u017Fs", want: "ss"},
+		{name: "\u03A3\u03C3\u03C2", want: "\u03c3\u03c3\u03c3"},
+	}
+
+	for _, c := range cases {
+		if got := foldName(c.name); got != c.want {
+			t.Errorf("foldName %q : want %q, got %q", c.name, c.want, got)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() { _ = foldName("already_folded.txt") })
+	if allocs != 0 {
+		t.Errorf("foldName : want no allocation for a folded name, got %v", allocs)
+	}
+}
+
+func TestFoldedNames(t *testing.T) {
+	dn := &dirNode{folded: make(foldedNames)}
+	child := &fileNode{}
+
+	dn.addChild("ReadMe.md", child)
+
+	for _, name := range []string{"ReadMe.md", "README.MD", "readme.md"} {
+		if got := dn.longName(name); got != "ReadMe.md" {
+			t.Errorf("longName %s : want %s, got %s", name, "ReadMe.md", got)
+		}
+	}
+
+	if got := dn.longName("other"); got != "other" {
+		t.Errorf("longName other : want other, got %s", got)
+	}
+
+	dn.removeChild("ReadMe.md")
+
+	if len(dn.folded) != 0 {
+		t.Errorf("removeChild : want folded index to be empty, got %v", dn.folded)
+	}
+}
+
+// similarNames returns n names differing only by their numeric suffix.
+func similarNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "Similar_File_Name_" + strconv.Itoa(i) + ".TXT"
+	}
+
+	return names
+}
+
+func TestCheck(t *testing.T) {
+	vfs := New()
+	rn := vfs.rootNode
+
+	da := vfs.createDir(rn, "a", avfs.DefaultDirPerm)
+	f := vfs.createFile(da, "f", avfs.DefaultFilePerm)
+	vfs.createSymlink(da, "sl", "f")
+
+	if report := vfs.Check(); !report.OK() {
+		t.Fatalf("Check : want no problem, got\n%s", report)
+	}
+
+	t.Run("HardLink", func(t *testing.T) {
+		rn.addChild("hl", f)
+		defer rn.removeChild("hl")
+
+		report := vfs.Check()
+		if len(report.Problems) != 1 || report.Problems[0].Path != "/a/f" {
+			t.Errorf("Check : want a link count problem on /a/f, got\n%s", report)
+		}
+
+		f.nlink++
+		defer func() { f.nlink-- }()
+
+		if report = vfs.Check(); !report.OK() {
+			t.Errorf("Check : want no problem, got\n%s", report)
+		}
+	})
+
+	t.Run("DirLoop", func(t *testing.T) {
+		da.addChild("loop", rn)
+		defer da.removeChild("loop")
+
+		report := vfs.Check()
+		if len(report.Problems) != 1 || report.Problems[0].Path != "/a/loop" {
+			t.Errorf("Check : want a problem on /a/loop, got\n%s", report)
+		}
+	})
+
+	t.Run("FoldedNames", func(t *testing.T) {
+		dn := vfs.createDir(rn, "folded", avfs.DefaultDirPerm)
+		defer rn.removeChild("folded")
+
+		dn.folded = make(foldedNames)
+		vfs.createFile(dn, "Name", avfs.DefaultFilePerm)
+		dn.children["NAME"] = &fileNode{nlink: 1}
+
+		report := vfs.Check()
+		if len(report.Problems) != 1 || report.Problems[0].Path != "/folded" {
+			t.Errorf("Check : want a duplicate name problem on /folded, got\n%s", report)
+		}
+	})
+
+	t.Run("InvalidNodes", func(t *testing.T) {
+		rn.addChild("bad/name", &symlinkNode{baseNode: baseNode{mode: fs.ModeSymlink}})
+		defer rn.removeChild("bad/name")
+
+		report := vfs.Check()
+		if len(report.Problems) != 2 {
+			t.Errorf("Check : want 2 problems, got\n%s", report)
+		}
+	})
+}
+
+// BenchmarkFoldedLookup benchmarks case-insensitive lookups in directories with many similar names,
+// using the case folded index or a linear scan of the children.
+func BenchmarkFoldedLookup(b *testing.B) {
+	for _, n := range []int{10, 100, 1000, 10000} {
+		names := similarNames(n)
+		dn := &dirNode{folded: make(foldedNames)}
+
+		for _, name := range names {
+			dn.addChild(name, &fileNode{})
+		}
+
+		lookups := make([]string, len(names))
+		for i, name := range names {
+			lookups[i] = strings.ToLower(name)
+		}
+
+		b.Run("Index"+strconv.Itoa(n), func(b *testing.B) {
+			for i := range b.N {
+				_ = dn.longName(lookups[i%n])
+			}
+		})
+
+		b.Run("Linear"+strconv.Itoa(n), func(b *testing.B) {
+			for i := range b.N {
+				lookup := lookups[i%n]
+
+				for name := range dn.children {
+					if strings.EqualFold(name, lookup) {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSparse(t *testing.T) {
+	vfs := New()
+	fn := vfs.createFile(vfs.rootNode, "sparse", avfs.DefaultFilePerm)
+
+	const size int64 = 8 << 30
+
+	fn.writeAt([]byte("head"), 0)
+	fn.truncate(size)
+	fn.writeAt([]byte("tail"), size-4)
+
+	if fn.blocks == nil || fn.data != nil {
+		t.Fatalf("truncate : want the file to be sparse")
+	}
+
+	if n := len(fn.blocks.data); n != 2 {
+		t.Errorf("writeAt : want 2 blocks of data, got %d", n)
+	}
+
+	if fn.size() != size {
+		t.Errorf("size : want size to be %d, got %d", size, fn.size())
+	}
+
+	b := make([]byte, 8)
+
+	for _, off := range []int64{0, blockSize - 4, size / 2, size - 8} {
+		want := make([]byte, len(b))
+
+		switch off {
+		case 0:
+			copy(want, "head")
+		case size - 8:
+			copy(want[4:], "tail")
+		}
+
+		n, err := fn.readAt(b, off)
+		if err != nil || n != len(b) || string(b) != string(want) {
+			t.Errorf("readAt %d : want %q, got %q (%d bytes, %v)", off, want, b, n, err)
+		}
+	}
+
+	fn.truncate(2)
+	fn.truncate(sparseMinHole)
+
+	n, _ := fn.readAt(b, 0)
+	if string(b[:n]) != "he\x00\x00\x00\x00\x00\x00" {
+		t.Errorf("readAt : want the truncated bytes to be read as zeros, got %q", b[:n])
+	}
+
+	if report := vfs.Check(); !report.OK() {
+		t.Errorf("Check : want no problem, got\n%s", report)
+	}
+
+	fn.truncate(0)
+
+	if fn.blocks != nil || fn.size() != 0 {
+		t.Errorf("truncate : want an empty dense file, got size %d", fn.size())
+	}
+
+	fn.writeAt([]byte("x"), sparseMinHole-2)
+
+	if fn.blocks != nil {
+		t.Errorf("writeAt : want a file with a small hole to be dense")
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	vfs := New()
+	fn := vfs.createFile(vfs.rootNode, "log", avfs.DefaultFilePerm)
+
+	line := []byte(strings.Repeat("x", 99) + "\n")
+	size := int64(0)
+
+	for size <= 3*blockMinSize {
+		size += int64(fn.writeAt(line, size))
+	}
+
+	if fn.blocks == nil || fn.data != nil {
+		t.Fatalf("writeAt : want a large file to be stored in blocks")
+	}
+
+	if want := int((size + blockSize - 1) / blockSize); len(fn.blocks.data) != want {
+		t.Errorf("writeAt : want %d blocks, got %d", want, len(fn.blocks.data))
+	}
+
+	b := make([]byte, len(line))
+
+	for _, off := range []int64{0, 100 * blockSize, size - int64(len(line))} {
+		n, err := fn.readAt(b, off)
+		if err != nil || n != len(b) || string(b) != string(line) {
+			t.Errorf("readAt %d : want %q, got %q (%d bytes, %v)", off, line, b, n, err)
+		}
+	}
+
+	fn.truncate(blockSize + 1)
+
+	if len(fn.blocks.data) != 2 || fn.size() != blockSize+1 {
+		t.Errorf("truncate : want 2 blocks and a size of %d, got %d blocks and a size of %d",
+			blockSize+1, len(fn.blocks.data), fn.size())
+	}
+}
+
+func TestBlocksReadFileShared(t *testing.T) {
+	vfs := New()
+	fn := vfs.createFile(vfs.rootNode, "large", avfs.DefaultFilePerm)
+
+	fn.writeAt(bytes.Repeat([]byte("0123456789abcdef"), 2*blockMinSize/16), 0)
+
+	if fn.blocks == nil {
+		t.Fatalf("writeAt : want a large file to be stored in blocks")
+	}
+
+	_, release, err := vfs.ReadFileShared("/large")
+	release()
+
+	if !errors.Is(err, vfs.err.NotSupported) {
+		t.Errorf("ReadFileShared : want error to be %v, got %v", vfs.err.NotSupported, err)
+	}
+
+	if fn.blocks == nil {
+		t.Errorf("ReadFileShared : want a large file to stay in blocks")
+	}
+}
+
+func TestBlocksUnsynced(t *testing.T) {
+	vfs := NewWithOptions(&Options{UnsyncedBuffers: true})
+	fn := vfs.createFile(vfs.rootNode, "sparse", avfs.DefaultFilePerm)
+
+	const off = 1 << 32
+
+	fn.writeAt([]byte("durable"), off)
+	vfs.markUnsynced(fn)
+
+	dc := vfs.unsynced.durable[fn]
+	if dc == nil || dc.data != nil || dc.blocks == nil {
+		t.Fatalf("markUnsynced : want the blocks of a sparse file to be saved, got %+v", dc)
+	}
+
+	if n := len(dc.blocks.data); n != 1 {
+		t.Errorf("markUnsynced : want 1 saved block, got %d", n)
+	}
+
+	fn.writeAt([]byte("written"), off)
+
+	b := make([]byte, 7)
+
+	_, err := dc.blocks.readAt(b, off)
+	if err != nil || string(b) != "durable" {
+		t.Errorf("writeAt : want the saved block to be unchanged, got %q, %v", b, err)
+	}
+
+	vfs.Crash()
+
+	_, err = fn.readAt(b, off)
+	if err != nil || string(b) != "durable" {
+		t.Errorf("Crash : want the saved content to be restored, got %q, %v", b, err)
+	}
+}
+
+func TestCheckDacl(t *testing.T) {
+	vfs := New()
+
+	grp, err := vfs.Idm().AddGroup("dacl")
+	if err != nil {
+		t.Fatalf("AddGroup : want error to be nil, got %v", err)
+	}
+
+	usr, err := vfs.Idm().AddUser("dacl", grp.Name())
+	if err != nil {
+		t.Fatalf("AddUser : want error to be nil, got %v", err)
+	}
+
+	allow := func(mask avfs.OpenMode) avfs.ACE {
+		return avfs.ACE{Type: avfs.AceAllow, TrusteeType: avfs.TrusteeUser, Id: usr.Uid(), Mask: mask}
+	}
+
+	deny := func(mask avfs.OpenMode) avfs.ACE {
+		return avfs.ACE{Type: avfs.AceDeny, TrusteeType: avfs.TrusteeEveryone, Mask: mask}
+	}
+
+	rw := avfs.OpenRead | avfs.OpenWrite
+
+	tests := []struct {
+		name string
+		dacl []avfs.ACE
+		perm avfs.OpenMode
+		want bool
+	}{
+		{name: "AllowBeforeDeny", dacl: []avfs.ACE{allow(rw), deny(avfs.OpenWrite)}, perm: avfs.OpenWrite, want: true},
+		{name: "DenyBeforeAllow", dacl: []avfs.ACE{deny(avfs.OpenWrite), allow(rw)}, perm: avfs.OpenWrite, want: false},
+		{name: "PartialAllowBeforeDeny", dacl: []avfs.ACE{allow(avfs.OpenRead), deny(avfs.OpenWrite), allow(avfs.OpenWrite)}, perm: rw, want: false},
+		{name: "DenyOtherRight", dacl: []avfs.ACE{deny(avfs.OpenWrite), allow(avfs.OpenRead)}, perm: avfs.OpenRead, want: true},
+		{name: "NotAllowed", dacl: []avfs.ACE{allow(avfs.OpenRead)}, perm: rw, want: false},
+	}
+
+	for _, tt := range tests {
+		bn := &baseNode{dacl: tt.dacl}
+
+		if got := bn.checkDacl(tt.perm, usr); got != tt.want {
+			t.Errorf("checkDacl %s : want %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
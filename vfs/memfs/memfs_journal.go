@@ -0,0 +1,426 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/avfs/avfs"
+)
+
+// Operations recorded in a Journal.
+const (
+	JournalChmod     = "chmod"     // JournalChmod is Chmod or File.Chmod.
+	JournalChown     = "chown"     // JournalChown is Chown or File.Chown.
+	JournalChtimes   = "chtimes"   // JournalChtimes is Chtimes.
+	JournalChunked   = "chunked"   // JournalChunked is CreateChunked, it can't be replayed.
+	JournalGraft     = "graft"     // JournalGraft is Graft, it can't be replayed.
+	JournalLchown    = "lchown"    // JournalLchown is Lchown.
+	JournalLchtimes  = "lchtimes"  // JournalLchtimes is Lchtimes.
+	JournalLink      = "link"      // JournalLink is Link.
+	JournalMkdir     = "mkdir"     // JournalMkdir is Mkdir.
+	JournalMkdirAll  = "mkdirall"  // JournalMkdirAll is MkdirAll.
+	JournalMknod     = "mknod"     // JournalMknod is Mknod.
+	JournalOpen      = "open"      // JournalOpen is OpenFile with the O_CREATE or O_TRUNC flags.
+	JournalRemove    = "remove"    // JournalRemove is Remove.
+	JournalRemoveAll = "removeall" // JournalRemoveAll is RemoveAll.
+	JournalRename    = "rename"    // JournalRename is Rename.
+	JournalRestore   = "restore"   // JournalRestore is Restore, it can't be replayed.
+	JournalSetACL    = "setacl"    // JournalSetACL is SetACL.
+	JournalSymlink   = "symlink"   // JournalSymlink is Symlink.
+	JournalTruncate  = "truncate"  // JournalTruncate is Truncate or File.Truncate.
+	JournalWrite     = "write"     // JournalWrite is File.Write or File.WriteAt.
+)
+
+// NewRingJournal returns an in memory journal keeping the size most recent entries.
+func NewRingJournal(size int) *RingJournal {
+	return &RingJournal{entries: make([]JournalEntry, size)}
+}
+
+// Append appends an entry to the journal, overwriting the oldest entry if the journal is full.
+func (j *RingJournal) Append(entry *JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.entries) == 0 {
+		return nil
+	}
+
+	j.entries[j.next] = *entry
+
+	j.next++
+	if j.next == len(j.entries) {
+		j.next = 0
+		j.full = true
+	}
+
+	return nil
+}
+
+// Entries returns the entries of the journal from the oldest to the most recent.
+// A file system can only be reconstructed with Replay if the journal has not wrapped
+// (the sequence number of the first entry is 1).
+func (j *RingJournal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !j.full {
+		return append([]JournalEntry(nil), j.entries[:j.next]...)
+	}
+
+	entries := make([]JournalEntry, 0, len(j.entries))
+	entries = append(entries, j.entries[j.next:]...)
+	entries = append(entries, j.entries[:j.next]...)
+
+	return entries
+}
+
+// NewFileJournal returns a journal appending entries to the file name of the file system vfs,
+// the file is created if needed. vfs must not be the journaled file system.
+func NewFileJournal(vfs avfs.VFSBase, name string) (*FileJournal, error) {
+	f, err := vfs.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, avfs.DefaultFilePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileJournal{f: f}, nil
+}
+
+// Append appends an entry to the journal file.
+// After a first error, entries are discarded and the error is returned by Close.
+func (j *FileJournal) Append(entry *JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil {
+		return j.err
+	}
+
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		j.err = err
+
+		return err
+	}
+
+	_, j.err = j.f.Write(append(buf, '\n'))
+
+	return j.err
+}
+
+// Close closes the journal file and returns the first error encountered writing the journal.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.f.Close()
+	if j.err != nil {
+		return j.err
+	}
+
+	return err
+}
+
+// ReadJournal reads the entries of the journal file name of the file system vfs written by a FileJournal.
+func ReadJournal(vfs avfs.VFSBase, name string) ([]JournalEntry, error) {
+	f, err := vfs.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var entries []JournalEntry
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var entry JournalEntry
+
+		err = dec.Decode(&entry)
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Replay applies the mutations of the journal entries to the file system vfs.
+// Replaying the entries up to a given sequence number on a new file system created with the same options
+// reconstructs the journaled file system at this point in time, except for the modification times
+// which are only restored by Chtimes.
+func Replay(vfs avfs.VFSBase, entries []JournalEntry) error {
+	for i := range entries {
+		err := replayEntry(vfs, &entries[i])
+		if err != nil {
+			return fmt.Errorf("replay entry %d : %w", entries[i].Seq, err)
+		}
+	}
+
+	return nil
+}
+
+// replayEntry applies the mutation of a single journal entry to the file system vfs.
+func replayEntry(vfs avfs.VFSBase, e *JournalEntry) error {
+	switch e.Op {
+	case JournalChmod:
+		return vfs.Chmod(e.Path, e.Mode)
+	case JournalChown:
+		return vfs.Chown(e.Path, e.Uid, e.Gid)
+	case JournalChtimes:
+		return vfs.Chtimes(e.Path, e.Atime, e.Mtime)
+	case JournalLchown:
+		return vfs.Lchown(e.Path, e.Uid, e.Gid)
+	case JournalLchtimes:
+		return avfs.Lchtimes(vfs, e.Path, e.Atime, e.Mtime)
+	case JournalLink:
+		return vfs.Link(e.Path, e.NewPath)
+	case JournalMkdir:
+		return vfs.Mkdir(e.Path, e.Mode)
+	case JournalMkdirAll:
+		return vfs.MkdirAll(e.Path, e.Mode)
+	case JournalMknod:
+		m, ok := vfs.(avfs.Mknoder)
+		if !ok {
+			return &fs.PathError{Op: "mknod", Path: e.Path, Err: avfs.ErrNotSupported}
+		}
+
+		return m.Mknod(e.Path, e.Mode, uint64(e.Size))
+	case JournalOpen:
+		f, err := vfs.OpenFile(e.Path, e.Flag, e.Mode)
+		if err != nil {
+			return err
+		}
+
+		return f.Close()
+	case JournalRemove:
+		return vfs.Remove(e.Path)
+	case JournalRemoveAll:
+		return vfs.RemoveAll(e.Path)
+	case JournalRename:
+		if e.Flag == 0 {
+			return vfs.Rename(e.Path, e.NewPath)
+		}
+
+		r, ok := vfs.(avfs.Renamer2)
+		if !ok {
+			return &os.LinkError{Op: "rename", Old: e.Path, New: e.NewPath, Err: avfs.ErrNotSupported}
+		}
+
+		return r.Rename2(e.Path, e.NewPath, avfs.RenameFlag(e.Flag))
+	case JournalSetACL:
+		m, ok := vfs.(avfs.ACLManager)
+		if !ok {
+			return &fs.PathError{Op: "setacl", Path: e.Path, Err: avfs.ErrNotSupported}
+		}
+
+		return m.SetACL(e.Path, avfs.ACLType(e.Flag), e.ACL)
+	case JournalChunked, JournalGraft, JournalRestore:
+		return fmt.Errorf("operation %q can't be replayed", e.Op)
+	case JournalSymlink:
+		return vfs.Symlink(e.Path, e.NewPath)
+	case JournalTruncate:
+		return vfs.Truncate(e.Path, e.Size)
+	case JournalWrite:
+		f, err := vfs.OpenFile(e.Path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+
+		_, err = f.WriteAt(e.Data, e.Size)
+		if err != nil {
+			_ = f.Close()
+
+			return err
+		}
+
+		return f.Close()
+	default:
+		return fmt.Errorf("unknown operation %q", e.Op)
+	}
+}
+
+// append numbers the entry, updates the versions of the nodes changed by the entry and appends it to the journal.
+func (jnl *journal) append(entry *JournalEntry, changes []change) {
+	jnl.mu.Lock()
+	defer jnl.mu.Unlock()
+
+	jnl.seq++
+	entry.Seq = jnl.seq
+	entry.Time = jnl.now()
+
+	if jnl.track {
+		jnl.trackChanges(entry, changes)
+	}
+
+	if jnl.j != nil {
+		_ = jnl.j.Append(entry)
+	}
+}
+
+// journaled starts a mutation of a journaled file system, the returned function appends the entry
+// to the journal if the mutation succeeded (*err == nil) and ends the mutation :
+//
+//	defer vfs.journaled(&err, &JournalEntry{...})()
+//
+// The journaled mutations are serialized until their entry is appended,
+// so that the entries are recorded in the order the mutations are applied.
+// A journaled mutation must not call another one.
+func (vfs *MemFS) journaled(err *error, entry *JournalEntry) func() {
+	vfs.journal.apply.Lock()
+
+	return func() {
+		defer vfs.journal.apply.Unlock()
+
+		if *err == nil {
+			vfs.appendEntry(entry)
+		}
+	}
+}
+
+// appendEntry appends the entry to the journal of the file system,
+// the paths of a sub file system are recorded as absolute paths of the root file system.
+func (vfs *MemFS) appendEntry(entry *JournalEntry) {
+	var changes []change
+	if vfs.journal.track {
+		changes = vfs.changes(entry)
+	}
+
+	if vfs.subDir != "" {
+		// The path of a symbolic link is its target, which is kept as is.
+		if entry.Op != JournalSymlink {
+			entry.Path = vfs.rootPath(entry.Path)
+		}
+
+		if entry.NewPath != "" {
+			entry.NewPath = vfs.rootPath(entry.NewPath)
+		}
+	}
+
+	vfs.journal.append(entry, changes)
+}
+
+// journalPath returns the path of the node of the open file f to be recorded in the journal :
+// the name used to open the file if it still leads to the node, otherwise the first path of the node
+// in the file system, after a rename of the file or of one of its parent directories.
+// It returns an empty path if the node has no link anymore (removed file or unnamed temporary file),
+// its mutations are not visible in the file system and are not journaled.
+// The journal must be locked, so that the path stays valid until the entry is appended,
+// and the node must not be locked, since it can be a directory of the path.
+func (f *MemFile) journalPath() string {
+	vfs := f.vfs
+	name, stream, _ := vfs.splitStream(f.name)
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err == vfs.err.FileExists && isNodeOf(child, stream, f.nd) {
+		return f.name
+	}
+
+	sep := string(vfs.PathSeparator())
+	roots := map[string]*dirNode{sep: vfs.rootNode}
+
+	if vfs.OSType() == avfs.OsWindows {
+		roots = make(map[string]*dirNode, len(vfs.volumes))
+		for vol, v := range vfs.volumes {
+			roots[vol+sep] = v.rootNode
+		}
+	}
+
+	rootPaths := make([]string, 0, len(roots))
+	for root := range roots {
+		rootPaths = append(rootPaths, root)
+	}
+
+	slices.Sort(rootPaths)
+
+	for _, root := range rootPaths {
+		path := vfs.findNode(root, roots[root], stream, f.nd)
+		if path == "" {
+			continue
+		}
+
+		if stream != "" {
+			path += ":" + stream
+		}
+
+		return path
+	}
+
+	return ""
+}
+
+// findNode returns the first path of the node nd, or of the file having nd as alternate data stream
+// named stream, in the directory dn named path. It returns an empty path if the node is not found.
+func (vfs *MemFS) findNode(path string, dn *dirNode, stream string, nd node) string {
+	dn.mu.RLock()
+
+	names := make([]string, 0, len(dn.children))
+	for name := range dn.children {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	children := make([]node, len(names))
+	for i, name := range names {
+		children[i] = dn.children[name]
+	}
+
+	dn.mu.RUnlock()
+
+	for i, child := range children {
+		childPath := vfs.Join(path, names[i])
+
+		if isNodeOf(child, stream, nd) {
+			return childPath
+		}
+
+		if c, ok := child.(*dirNode); ok {
+			if p := vfs.findNode(childPath, c, stream, nd); p != "" {
+				return p
+			}
+		}
+	}
+
+	return ""
+}
+
+// isNodeOf returns true if nd is the node child, or its alternate data stream named stream.
+func isNodeOf(child node, stream string, nd node) bool {
+	if stream == "" {
+		return child == nd
+	}
+
+	fn, ok := child.(*fileNode)
+	if !ok {
+		return false
+	}
+
+	fn.mu.RLock()
+	defer fn.mu.RUnlock()
+
+	sn, ok := fn.streams[stream]
+
+	return ok && node(sn) == nd
+}
@@ -0,0 +1,191 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+	"sync"
+
+	"github.com/avfs/avfs"
+)
+
+// Lock places an exclusive advisory lock on the open file f, waiting until no other file holds a lock.
+// Like flock on Linux, the lock belongs to the open file : another file opened on the same path conflicts with it,
+// locking an already locked file converts its lock and closing the file releases it.
+// The locks are shared with the sub file systems and clones.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Lock(f avfs.File) error {
+	_, err := vfs.lock(f, true, true)
+
+	return err
+}
+
+// RLock places a shared advisory lock on the open file f, waiting until no other file holds an exclusive lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) RLock(f avfs.File) error {
+	_, err := vfs.lock(f, false, true)
+
+	return err
+}
+
+// TryLock tries to place an exclusive advisory lock on the open file f without waiting,
+// it returns false if another file holds a lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) TryLock(f avfs.File) (bool, error) {
+	return vfs.lock(f, true, false)
+}
+
+// Unlock removes the advisory lock held by the open file f.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Unlock(f avfs.File) error {
+	op := "flock"
+	if vfs.OSType() == avfs.OsWindows {
+		op = "UnlockFileEx"
+	}
+
+	mf, nd, err := vfs.lockedFile(op, f)
+	if err != nil {
+		return err
+	}
+
+	vfs.locks.unlock(mf, nd)
+
+	return nil
+}
+
+// lock places an exclusive or a shared lock on the open file f, waiting for the lock if wait is true.
+func (vfs *MemFS) lock(f avfs.File, exclusive, wait bool) (bool, error) {
+	op := "flock"
+	if vfs.OSType() == avfs.OsWindows {
+		op = "LockFileEx"
+	}
+
+	mf, nd, err := vfs.lockedFile(op, f)
+	if err != nil {
+		return false, err
+	}
+
+	if !vfs.locks.lock(mf, nd, exclusive, wait) {
+		return false, nil
+	}
+
+	// The file may have been closed while waiting for the lock.
+	mf.mu.RLock()
+	closed := mf.nd != nd
+	mf.mu.RUnlock()
+
+	if closed {
+		vfs.locks.unlock(mf, nd)
+
+		return false, &fs.PathError{Op: op, Path: mf.name, Err: vfs.err.BadFileDesc}
+	}
+
+	return true, nil
+}
+
+// lockedFile returns the MemFile and the node of the open file f.
+func (vfs *MemFS) lockedFile(op string, f avfs.File) (*MemFile, node, error) {
+	mf, ok := f.(*MemFile)
+	if !ok || mf == nil {
+		return nil, nil, &fs.PathError{Op: op, Err: fs.ErrInvalid}
+	}
+
+	mf.mu.RLock()
+	defer mf.mu.RUnlock()
+
+	if mf.nd == nil || mf.vfs.locks != vfs.locks {
+		return nil, nil, &fs.PathError{Op: op, Path: mf.name, Err: vfs.err.BadFileDesc}
+	}
+
+	return mf, mf.nd, nil
+}
+
+// newFileLocks returns a new table of advisory locks.
+func newFileLocks() *fileLocks {
+	fl := &fileLocks{locks: make(map[node]*fileLock)}
+	fl.cond = sync.NewCond(&fl.mu)
+
+	return fl
+}
+
+// lock places a lock on the node nd for the file f, it returns false if wait is false and the lock is held by another file.
+// Like flock, the conversion of a lock is not atomic : the existing lock of f is removed first.
+func (fl *fileLocks) lock(f *MemFile, nd node, exclusive, wait bool) bool {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	if f.locked.Load() {
+		fl.remove(f, nd)
+	}
+
+	for {
+		l := fl.locks[nd]
+		if l == nil {
+			l = &fileLock{shared: make(map[*MemFile]struct{})}
+			fl.locks[nd] = l
+		}
+
+		if l.exclusive == nil && (!exclusive || len(l.shared) == 0) {
+			if exclusive {
+				l.exclusive = f
+			} else {
+				l.shared[f] = struct{}{}
+			}
+
+			f.locked.Store(true)
+
+			return true
+		}
+
+		if !wait {
+			return false
+		}
+
+		fl.cond.Wait()
+	}
+}
+
+// unlock removes the lock held by the file f on the node nd.
+func (fl *fileLocks) unlock(f *MemFile, nd node) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	fl.remove(f, nd)
+}
+
+// remove removes the lock held by the file f on the node nd and wakes up the waiting files.
+// fl.mu must be locked.
+func (fl *fileLocks) remove(f *MemFile, nd node) {
+	f.locked.Store(false)
+
+	l := fl.locks[nd]
+	if l == nil {
+		return
+	}
+
+	if l.exclusive == f {
+		l.exclusive = nil
+	}
+
+	delete(l.shared, f)
+
+	if l.exclusive == nil && len(l.shared) == 0 {
+		delete(fl.locks, nd)
+	}
+
+	fl.cond.Broadcast()
+}
@@ -0,0 +1,174 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// ApplyMetadata applies the metadata changes of ops in a batch (see avfs.ApplyMetadata).
+// The file system is marked as mutating once for the whole batch,
+// each file is looked up once and locked once for all its changes.
+func (vfs *MemFS) ApplyMetadata(ops []avfs.MetadataOp) error {
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		vfs.journal.apply.Lock()
+		defer vfs.journal.apply.Unlock()
+	}
+
+	u := vfs.User()
+	chownErr := vfs.chownError()
+
+	var errs []error
+
+	for i := range ops {
+		err := vfs.applyMetadata(&ops[i], u, chownErr)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// applyMetadata applies the metadata changes of a single operation.
+func (vfs *MemFS) applyMetadata(mo *avfs.MetadataOp, u avfs.UserReader, chownErr error) error {
+	owner := mo.Set&avfs.MetadataOwner != 0
+	mode := mo.Set&avfs.MetadataMode != 0
+	times := mo.Set&avfs.MetadataTimes != 0
+	noFollow := mo.Set&avfs.MetadataNoFollow != 0
+
+	chownOp, op := "chown", "chtimes"
+	if noFollow {
+		chownOp, op = "lchown", "lchtimes"
+	}
+
+	switch {
+	case owner:
+		op = chownOp
+
+		if chownErr != nil {
+			return &fs.PathError{Op: op, Path: mo.Path, Err: chownErr}
+		}
+	case mode:
+		op = "chmod"
+	case !times:
+		return nil
+	}
+
+	// Like Lchown and Lchtimes, the final symbolic link is not followed with MetadataNoFollow,
+	// it is always followed for Chmod.
+	_, nd, _, err := vfs.searchNode(mo.Path, slmLstat)
+	if err != vfs.err.FileExists || nd == nil {
+		return &fs.PathError{Op: op, Path: mo.Path, Err: err}
+	}
+
+	target := nd
+
+	if _, ok := nd.(*symlinkNode); ok && (mode || (owner || times) && !noFollow) {
+		_, target, _, err = vfs.searchNode(mo.Path, slmEval)
+		if err != vfs.err.FileExists || target == nil {
+			return &fs.PathError{Op: op, Path: mo.Path, Err: err}
+		}
+	}
+
+	if target == nd {
+		return vfs.applyNodeMetadata(nd, mo, u, owner, mode, times, chownOp)
+	}
+
+	err = vfs.applyNodeMetadata(target, mo, u, owner && !noFollow, mode, times && !noFollow, chownOp)
+	if err != nil {
+		return err
+	}
+
+	return vfs.applyNodeMetadata(nd, mo, u, owner && noFollow, false, times && noFollow, chownOp)
+}
+
+// applyNodeMetadata applies the selected metadata changes of an operation to a node locked once.
+func (vfs *MemFS) applyNodeMetadata(nd node, mo *avfs.MetadataOp, u avfs.UserReader,
+	owner, mode, times bool, chownOp string,
+) error {
+	if !owner && !mode && !times {
+		return nil
+	}
+
+	nd.Lock()
+	defer nd.Unlock()
+
+	if owner {
+		if !nd.setOwner(mo.Uid, mo.Gid) {
+			return &fs.PathError{Op: chownOp, Path: mo.Path, Err: vfs.err.OpNotPermitted}
+		}
+
+		if vfs.journal != nil {
+			jOp := JournalChown
+			if chownOp == "lchown" {
+				jOp = JournalLchown
+			}
+
+			vfs.appendEntry(&JournalEntry{Op: jOp, Path: mo.Path, Uid: mo.Uid, Gid: mo.Gid})
+		}
+	}
+
+	if mode {
+		if !nd.setMode(mo.Mode, u) {
+			return &fs.PathError{Op: "chmod", Path: mo.Path, Err: vfs.err.OpNotPermitted}
+		}
+
+		if vfs.journal != nil {
+			vfs.appendEntry(&JournalEntry{Op: JournalChmod, Path: mo.Path, Mode: mo.Mode})
+		}
+	}
+
+	if times {
+		chtimesOp, jOp := "chtimes", JournalChtimes
+		if chownOp == "lchown" {
+			chtimesOp, jOp = "lchtimes", JournalLchtimes
+		}
+
+		if !nd.setTimes(mo.Atime, mo.Mtime, u) {
+			return &fs.PathError{Op: chtimesOp, Path: mo.Path, Err: vfs.err.OpNotPermitted}
+		}
+
+		if vfs.journal != nil {
+			vfs.appendEntry(&JournalEntry{Op: jOp, Path: mo.Path, Atime: mo.Atime, Mtime: mo.Mtime})
+		}
+	}
+
+	nd.setChangeTime(vfs.Now())
+	vfs.sendWatch(avfs.WatchChmod, mo.Path)
+
+	return nil
+}
+
+// chownError returns the error of Chown and Lchown for the current user, nil if they are permitted.
+func (vfs *MemFS) chownError() error {
+	if (vfs.HasFeature(avfs.FeatIdentityMgr) && !vfs.User().IsAdmin()) || vfs.OSType() == avfs.OsWindows {
+		return vfs.err.OpNotPermitted
+	}
+
+	if vfs.HasFeature(avfs.FeatStrict) && !vfs.HasFeature(avfs.FeatIdentityMgr) {
+		return vfs.err.NotSupported
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// Mknod creates the special file name with the type and the permissions of mode (Linux only) :
+// a block device (fs.ModeDevice), a character device (fs.ModeDevice | fs.ModeCharDevice),
+// a named pipe (fs.ModeNamedPipe), a socket (fs.ModeSocket) or an empty regular file if mode has no type.
+// dev is the device number of a device file returned by MemInfo.Rdev, it is ignored for the other types.
+// Special files only hold metadata : they can be listed, linked, renamed and removed
+// but opening them fails with the error avfs.ErrNoSuchDevice.
+// Like mknod(2), only an administrator can create a device file.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Mknod(name string, mode fs.FileMode, dev uint64) (err error) {
+	vfs.StartMutation()
+	defer vfs.EndMutation()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalMknod, Path: name, Mode: mode, Size: int64(dev)})()
+	}
+
+	if vfs.watchers.active() {
+		defer vfs.notify(&err, avfs.WatchCreate, name)
+	}
+
+	const op = "mknod"
+
+	if vfs.OSType() != avfs.OsLinux {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotSupported}
+	}
+
+	typ := mode.Type()
+	if !isFileType(typ) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+	}
+
+	if typ&fs.ModeDevice != 0 && !vfs.User().IsAdmin() {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
+	}
+
+	parent, _, pi, nerr := vfs.searchNode(avfs.TrimSeparators(vfs, name), slmLstat)
+	if !vfs.isNotExist(nerr) || !pi.IsLast() || avfs.HasTrailingSeparator(vfs, name) {
+		return &fs.PathError{Op: op, Path: name, Err: nerr}
+	}
+
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	if !parent.checkPermission(avfs.OpenWrite, vfs.User()) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	child := vfs.createFile(parent, pi.Part(), mode)
+	child.mode |= typ
+
+	if typ&fs.ModeDevice != 0 {
+		child.rdev = dev
+	}
+
+	return nil
+}
+
+// isFileType returns true if typ is the type of a regular file or of a special file created by Mknod.
+func isFileType(typ fs.FileMode) bool {
+	switch typ {
+	case 0, fs.ModeDevice, fs.ModeDevice | fs.ModeCharDevice, fs.ModeNamedPipe, fs.ModeSocket:
+		return true
+	default:
+		return false
+	}
+}
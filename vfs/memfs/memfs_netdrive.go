@@ -0,0 +1,120 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// MapNetworkDrive maps the network share unc (like `\\server\share`) to the drive drive (like "Z:"),
+// like the "net use" command. The share is created if it doesn't exist.
+// The drive and the share give access to the same files.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) MapNetworkDrive(drive, unc string) error {
+	const op = "MapNetworkDrive"
+
+	drv, err := vfs.newVolumeName(op, drive)
+	if err != nil {
+		return err
+	}
+
+	if len(drv) != len(drive) || !isDrive(drv) {
+		return &fs.PathError{Op: op, Path: drive, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	share := avfs.VolumeName(vfs, unc)
+	if share == "" || isDrive(share) || len(unc) > len(share)+1 {
+		return &fs.PathError{Op: op, Path: unc, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	sv, ok := vfs.volumes[share]
+	if !ok {
+		sv = newVolume(share, vfs.createRootNode())
+		vfs.volumes[share] = sv
+	}
+
+	if sv.unc != "" {
+		return &fs.PathError{Op: op, Path: unc, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	v := newVolume(drv, sv.rootNode)
+	v.unc = share
+	v.mounted = true
+	vfs.volumes[drv] = v
+
+	return nil
+}
+
+// NetworkDrives returns the mapped network drives and their network shares.
+func (vfs *MemFS) NetworkDrives() map[string]string {
+	drives := make(map[string]string)
+
+	for name, v := range vfs.volumes {
+		if v.unc != "" {
+			drives[name] = v.unc
+		}
+	}
+
+	return drives
+}
+
+// UniversalName returns the UNC form of the absolute path of path if path lies on a mapped network drive,
+// or the absolute path of path otherwise.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) UniversalName(path string) (string, error) {
+	const op = "UniversalName"
+
+	if vfs.OSType() != avfs.OsWindows {
+		return "", &fs.PathError{Op: op, Path: path, Err: avfs.ErrVolumeWindows}
+	}
+
+	absPath, _ := vfs.Abs(path)
+	vol := avfs.VolumeName(vfs, absPath)
+
+	v, ok := vfs.volumes[vol]
+	if !ok || v.unc == "" {
+		return absPath, nil
+	}
+
+	return v.unc + absPath[len(vol):], nil
+}
+
+// UnmapNetworkDrive removes the mapping of a network drive. The files of the network share are not deleted.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) UnmapNetworkDrive(drive string) error {
+	const op = "UnmapNetworkDrive"
+
+	vol, v, err := vfs.volume(op, drive)
+	if err != nil {
+		return err
+	}
+
+	if v.unc == "" {
+		return &fs.PathError{Op: op, Path: drive, Err: avfs.ErrVolumeNameInvalid}
+	}
+
+	delete(vfs.volumes, vol)
+
+	return nil
+}
+
+// isDrive returns true if vol is a drive letter volume name (like "C:").
+func isDrive(vol string) bool {
+	return len(vol) == 2 && vol[1] == ':'
+}
@@ -0,0 +1,63 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+// MaxOpenFiles returns the maximum number of files that can be open at the same time, 0 if there is no limit.
+func (vfs *MemFS) MaxOpenFiles() int {
+	return int(vfs.openFiles.max.Load())
+}
+
+// OpenFiles returns the number of files currently open (opened and not yet closed).
+func (vfs *MemFS) OpenFiles() int {
+	return int(vfs.openFiles.count.Load())
+}
+
+// SetMaxOpenFiles sets the maximum number of files that can be open at the same time,
+// like the RLIMIT_NOFILE resource limit of a process. A value of 0 or less removes the limit.
+// Once the limit is reached, OpenFile and the functions using it fail with EMFILE on Linux
+// (ERROR_TOO_MANY_OPEN_FILES on Windows) until some files are closed.
+// Files already open are not affected by a new limit.
+// The limit and the count of open files are shared with the sub file systems and clones.
+func (vfs *MemFS) SetMaxOpenFiles(n int) {
+	vfs.openFiles.max.Store(int64(max(n, 0)))
+}
+
+// acquire reserves a slot for a new open file, it returns false if the limit of open files is reached.
+func (of *openFiles) acquire() bool {
+	for {
+		count, maxCount := of.count.Load(), of.max.Load()
+		if maxCount > 0 && count >= maxCount {
+			return false
+		}
+
+		if of.count.CompareAndSwap(count, count+1) {
+			return true
+		}
+	}
+}
+
+// release frees the slot of a closed file.
+func (of *openFiles) release() {
+	of.count.Add(-1)
+}
+
+// releaseOnError frees the slot reserved by acquire if the file could not be opened.
+func (of *openFiles) releaseOnError(err *error) {
+	if *err != nil {
+		of.release()
+	}
+}
@@ -0,0 +1,204 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// ShortPathName returns the DOS 8.3 short form of the named path (Windows only),
+// like the GetShortPathName Windows function.
+// Elements of the path without short name are returned unchanged.
+// Short names are only generated if the file system was created with the ShortNames option.
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) ShortPathName(path string) (string, error) {
+	const op = "shortpathname"
+
+	if vfs.OSType() != avfs.OsWindows {
+		return "", &fs.PathError{Op: op, Path: path, Err: avfs.ErrShortNamesWindows}
+	}
+
+	_, _, _, err := vfs.searchNode(path, slmLstat)
+	if err != vfs.err.FileExists {
+		return "", &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	absPath, _ := vfs.Abs(path)
+	pi := avfs.NewPathIterator[*MemFS](vfs, absPath)
+	parent := vfs.volumes[pi.VolumeName()].rootNode
+
+	var sb strings.Builder
+
+	sb.WriteString(pi.VolumeName())
+
+	for pi.Next() {
+		parent.mu.RLock()
+		longName := parent.longName(pi.Part())
+		shortName := parent.shortName(longName)
+		child := parent.children[longName]
+		parent.mu.RUnlock()
+
+		sb.WriteByte(vfs.PathSeparator())
+		sb.WriteString(shortName)
+
+		dn, ok := child.(*dirNode)
+		if !ok {
+			sb.WriteString(pi.Right())
+
+			break
+		}
+
+		parent = dn
+	}
+
+	if sb.Len() == len(pi.VolumeName()) {
+		sb.WriteByte(vfs.PathSeparator())
+	}
+
+	return sb.String(), nil
+}
+
+// addShortName generates a unique short name for the child name of the directory if needed.
+// The directory must be locked.
+func (dn *dirNode) addShortName(name string) {
+	if dn.shortNames == nil || isShortName(name) || dn.shortName(name) != name {
+		return
+	}
+
+	base, ext := shortNameParts(name)
+
+	for i := 1; ; i++ {
+		tail := "~" + strconv.Itoa(i)
+		shortName := base[:min(len(base), 8-len(tail))] + tail + ext
+
+		_, exists := dn.shortNames[shortName]
+		if !exists && dn.children[shortName] == nil {
+			dn.shortNames[shortName] = name
+
+			return
+		}
+	}
+}
+
+// longName returns the long name of the child of the directory named name,
+// name being either a long name, a short name or a name differing only by case if names are case-insensitive.
+// The directory must be locked.
+func (dn *dirNode) longName(name string) string {
+	if dn.children[name] != nil {
+		return name
+	}
+
+	if dn.shortNames != nil {
+		if longName, ok := dn.shortNames[strings.ToUpper(name)]; ok {
+			return longName
+		}
+	}
+
+	return dn.foldedName(name)
+}
+
+// removeShortName removes the short name of the child name of the directory.
+// The directory must be locked.
+func (dn *dirNode) removeShortName(name string) {
+	for shortName, longName := range dn.shortNames {
+		if longName == name {
+			delete(dn.shortNames, shortName)
+
+			return
+		}
+	}
+}
+
+// shortName returns the short name of the child name of the directory or name if it has no short name.
+// The directory must be locked.
+func (dn *dirNode) shortName(name string) string {
+	for shortName, longName := range dn.shortNames {
+		if longName == name {
+			return shortName
+		}
+	}
+
+	return name
+}
+
+// isShortName returns true if name is a valid DOS 8.3 name,
+// a name with a base of at most 8 characters and an optional extension of at most 3 characters.
+func isShortName(name string) bool {
+	base, ext, _ := strings.Cut(name, ".")
+	if base == "" || len(base) > 8 || len(ext) > 3 || strings.Contains(ext, ".") {
+		return false
+	}
+
+	return strings.IndexFunc(name, func(r rune) bool {
+		return r != '.' && !isShortNameChar(r)
+	}) < 0
+}
+
+// isShortNameChar returns true if r is a valid character of a DOS 8.3 name.
+func isShortNameChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	default:
+		return strings.ContainsRune("!#$%&'()-@^_`{}~", r)
+	}
+}
+
+// shortNameParts returns the upper case base (at most 6 characters) and extension (at most 3 characters
+// including the dot) of the short name of name, invalid characters being removed or replaced.
+func shortNameParts(name string) (base, ext string) {
+	clean := func(s string, maxLen int) string {
+		var sb strings.Builder
+
+		for _, r := range strings.ToUpper(s) {
+			if sb.Len() == maxLen {
+				break
+			}
+
+			switch {
+			case r == ' ' || r == '.':
+			case isShortNameChar(r):
+				sb.WriteRune(r)
+			default:
+				sb.WriteByte('_')
+			}
+		}
+
+		return sb.String()
+	}
+
+	baseName := name
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		baseName = name[:i]
+
+		ext = clean(name[i+1:], 3)
+		if ext != "" {
+			ext = "." + ext
+		}
+	}
+
+	base = clean(baseName, 6)
+	if base == "" {
+		base = "_"
+	}
+
+	return base, ext
+}
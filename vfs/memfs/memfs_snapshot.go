@@ -0,0 +1,248 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+	"maps"
+	"slices"
+	"sync/atomic"
+
+	"github.com/avfs/avfs"
+)
+
+// Snapshot returns a copy of the content of the file system : files, directories, symbolic links,
+// their metadata and owners, the hard links between files and on Windows the volumes.
+// The snapshot is never modified, it can be restored any number of times by Restore,
+// on this file system or on another MemFS of the same OS type.
+// Mutations are blocked while the snapshot is taken, so it must not be called by a goroutine
+// which has frozen the file system (see Freeze).
+func (vfs *MemFS) Snapshot() *Snapshot {
+	vfs.Freeze()
+	defer vfs.Thaw()
+
+	nc := make(nodeCopier)
+	s := &Snapshot{
+		rootNode:   nc.copyDir(&dirNode{}, vfs.rootNode),
+		osType:     vfs.OSType(),
+		shortNames: vfs.shortNames,
+		folded:     vfs.caseInsensitive,
+		lastId:     atomic.LoadUint64(vfs.lastId),
+	}
+
+	if !vfs.isVolumeRoot() {
+		return s
+	}
+
+	s.volumes = make(volumes)
+
+	for vol, v := range vfs.volumes {
+		if v.mounted || v.rootNode == vfs.rootNode {
+			continue
+		}
+
+		s.volumes[vol] = &volume{rootNode: nc.copyDir(&dirNode{}, v.rootNode), info: v.info}
+	}
+
+	return s
+}
+
+// Restore restores the content of the file system saved by Snapshot.
+// The root directory is restored in place, so clones and sub file systems sharing it see the restored content,
+// whereas open files and sub file systems rooted in a subdirectory keep referencing the previous files.
+// On Windows, the volumes which were not mounted are restored too.
+// Restore waits for the mutations in progress to complete and is recorded as a single JournalRestore entry.
+func (vfs *MemFS) Restore(s *Snapshot) (err error) {
+	const op = "restore"
+
+	root := string(vfs.PathSeparator())
+
+	if s == nil || s.osType != vfs.OSType() || s.shortNames != vfs.shortNames ||
+		s.folded != vfs.caseInsensitive {
+		return &fs.PathError{Op: op, Path: root, Err: avfs.ErrInvalidArgument}
+	}
+
+	vfs.Freeze()
+	defer vfs.Thaw()
+
+	if vfs.journal != nil {
+		defer vfs.journaled(&err, &JournalEntry{Op: JournalRestore, Path: root})()
+	}
+
+	// The restored files keep their ids, the next ids must not be used by one of them.
+	advanceId(vfs.lastId, s.lastId)
+
+	nc := make(nodeCopier)
+
+	vfs.rootNode.mu.Lock()
+	nc.copyDir(vfs.rootNode, s.rootNode)
+	vfs.rootNode.mu.Unlock()
+
+	if s.volumes == nil || !vfs.isVolumeRoot() {
+		return nil
+	}
+
+	for vol, v := range vfs.volumes {
+		if !v.mounted && v.rootNode != vfs.rootNode {
+			delete(vfs.volumes, vol)
+		}
+	}
+
+	for vol, v := range s.volumes {
+		if _, ok := vfs.volumes[vol]; ok {
+			// A volume mounted since the snapshot is kept.
+			continue
+		}
+
+		vfs.volumes[vol] = &volume{rootNode: nc.copyDir(&dirNode{}, v.rootNode), info: v.info}
+	}
+
+	return nil
+}
+
+// advanceId sets the last unique id lastId to id if it is lower.
+func advanceId(lastId *uint64, id uint64) {
+	for {
+		last := atomic.LoadUint64(lastId)
+		if last >= id || atomic.CompareAndSwapUint64(lastId, last, id) {
+			return
+		}
+	}
+}
+
+// isVolumeRoot returns true if the root directory of the file system is the root directory of a volume (Windows only).
+func (vfs *MemFS) isVolumeRoot() bool {
+	for _, v := range vfs.volumes {
+		if v.rootNode == vfs.rootNode && !v.mounted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyNode returns a deep copy of the node nd.
+func (nc nodeCopier) copyNode(nd node) node {
+	switch c := nd.(type) {
+	case *dirNode:
+		return nc.copyDir(&dirNode{}, c)
+	case *fileNode:
+		return nc.copyFile(c)
+	case *symlinkNode:
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		sn := &symlinkNode{link: c.link}
+		copyBase(&sn.baseNode, &c.baseNode)
+
+		return sn
+	default:
+		return nd
+	}
+}
+
+// copyDir copies the directory src and its descendants into dst and returns dst.
+// The caller is responsible for locking dst if it is reachable by other goroutines.
+func (nc nodeCopier) copyDir(dst, src *dirNode) *dirNode {
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+
+	copyBase(&dst.baseNode, &src.baseNode)
+	dst.id = src.id
+	dst.shortNames = maps.Clone(src.shortNames)
+	dst.folded = maps.Clone(src.folded)
+	dst.graft = copyGraft(src.graft)
+	dst.defaultACL = slices.Clone(src.defaultACL)
+	dst.children = nil
+
+	if src.children != nil {
+		dst.children = make(children, len(src.children))
+		for name, child := range src.children {
+			dst.children[name] = nc.copyNode(child)
+		}
+	}
+
+	return dst
+}
+
+// copyFile returns a deep copy of the file src, the same copy is returned for all the hard links to src.
+// A file generated from the identity manager is not copied, so that it is still updated once restored.
+func (nc nodeCopier) copyFile(src *fileNode) *fileNode {
+	if fn, ok := nc[src]; ok {
+		return fn
+	}
+
+	if src.generated {
+		return src
+	}
+
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+
+	fn := &fileNode{
+		data:  slices.Clone(src.data),
+		graft: copyGraft(src.graft),
+		id:    src.id,
+		nlink: src.nlink,
+		rdev:  src.rdev,
+	}
+
+	copyBase(&fn.baseNode, &src.baseNode)
+
+	if src.chunks != nil {
+		fn.chunks = &chunks{extents: slices.Clone(src.chunks.extents), ends: slices.Clone(src.chunks.ends)}
+	}
+
+	if src.blocks != nil {
+		fn.blocks = src.blocks.copy()
+	}
+
+	nc[src] = fn
+
+	if src.streams != nil {
+		fn.streams = make(streams, len(src.streams))
+		for name, st := range src.streams {
+			fn.streams[name] = nc.copyFile(st)
+		}
+	}
+
+	return fn
+}
+
+// copyBase copies the metadata of the node src to dst.
+func copyBase(dst, src *baseNode) {
+	dst.acl = slices.Clone(src.acl)
+	dst.dacl = slices.Clone(src.dacl)
+	dst.atime.Store(src.atime.Load())
+	dst.mtime = src.mtime
+	dst.ctime = src.ctime
+	dst.mode = src.mode
+	dst.uid = src.uid
+	dst.gid = src.gid
+	dst.readOnly = src.readOnly
+}
+
+// copyGraft returns a copy of the graft g, nil if g is nil.
+func copyGraft(g *graft) *graft {
+	if g == nil {
+		return nil
+	}
+
+	cg := *g
+
+	return &cg
+}
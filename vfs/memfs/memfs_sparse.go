@@ -0,0 +1,54 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+// sparseMinHole is the minimum size of a hole which turns a file into a sparse file stored in blocks.
+// Smaller holes are filled with zeros.
+const sparseMinHole = 16 * blockSize
+
+// isLargeHole returns true if the hole between the end of a file stored in a single slice and holeEnd
+// is large enough to turn the file into a sparse file.
+// The node must be locked.
+func (fn *fileNode) isLargeHole(holeEnd int64) bool {
+	return fn.blocks == nil && holeEnd-int64(len(fn.data)) >= sparseMinHole
+}
+
+// isSparse returns true if some blocks of the file are holes.
+func (bs *blockStore) isSparse() bool {
+	return int64(len(bs.data)) < (bs.size+blockSize-1)/blockSize
+}
+
+// truncate changes the size of a file stored in blocks, the blocks beyond the end of the file are released
+// and the end of the last block is cleared, so that the file can grow again with a hole.
+func (bs *blockStore) truncate(size int64) {
+	if size < bs.size {
+		last := (size + blockSize - 1) / blockSize
+
+		for i := range bs.data {
+			if i >= last {
+				delete(bs.data, i)
+				delete(bs.shared, i)
+			}
+		}
+
+		if block, ok := bs.block(size / blockSize); ok {
+			clear(block[size%blockSize:])
+		}
+	}
+
+	bs.size = size
+}
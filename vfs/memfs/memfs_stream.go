@@ -0,0 +1,234 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// Streams returns the sorted names of the alternate data streams of the named file (Windows only).
+// A stream is opened, read, written and removed with the name "file:stream".
+// If there is an error, it will be of type *PathError.
+func (vfs *MemFS) Streams(name string) ([]string, error) {
+	const op = "streams"
+
+	if vfs.OSType() != avfs.OsWindows {
+		return nil, &fs.PathError{Op: op, Path: name, Err: avfs.ErrStreamsWindows}
+	}
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	c, ok := child.(*fileNode)
+	if !ok {
+		return nil, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	streams := make([]string, 0, len(c.streams))
+	for stream := range c.streams {
+		streams = append(streams, stream)
+	}
+
+	slices.Sort(streams)
+
+	return streams, nil
+}
+
+// splitStream splits the name of an alternate data stream "file:stream[:$DATA]" (Windows only)
+// into the name of the file and the name of the stream.
+// The name of the stream is empty for the unnamed stream ("file::$DATA") or if name is not a stream.
+func (vfs *MemFS) splitStream(name string) (fileName, stream string, err error) {
+	if vfs.OSType() != avfs.OsWindows {
+		return name, "", nil
+	}
+
+	start := len(avfs.VolumeName(vfs, name))
+	if i := strings.LastIndexAny(name[start:], `\/`); i >= 0 {
+		start += i + 1
+	}
+
+	i := strings.IndexByte(name[start:], ':')
+	if i < 0 {
+		return name, "", nil
+	}
+
+	fileName = name[:start+i]
+
+	stream, streamType, found := strings.Cut(name[start+i+1:], ":")
+	if found && !strings.EqualFold(streamType, "$DATA") {
+		return "", "", avfs.ErrWinInvalidName
+	}
+
+	return fileName, stream, nil
+}
+
+// openStream opens the stream of the file fileName, the file is created with the stream if needed.
+// The creation of the file and the opening of the stream are journaled as two entries.
+func (vfs *MemFS) openStream(name, fileName, stream string, flag int, perm fs.FileMode) (f avfs.File, err error) {
+	const op = "open"
+
+	at := int64(0)
+	om := avfs.ToOpenMode(flag)
+
+	_, child, _, err := vfs.searchNode(fileName, slmEval)
+	if vfs.isNotExist(err) && om&avfs.OpenCreate != 0 {
+		f, err := vfs.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, perm)
+		if err != nil {
+			return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: errors.Unwrap(err)}
+		}
+
+		child = f.(*MemFile).nd //nolint:forcetypeassert // OpenFile returns a *MemFile.
+		_ = f.Close()
+	} else if err != vfs.err.FileExists || child == nil {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	c, ok := child.(*fileNode)
+	if !ok {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+	}
+
+	if flag&(os.O_CREATE|os.O_TRUNC) != 0 {
+		vfs.StartMutation()
+		defer vfs.EndMutation()
+
+		if vfs.journal != nil {
+			defer vfs.journaled(&err, &JournalEntry{Op: JournalOpen, Path: name, Flag: flag, Mode: perm})()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkPermission(om, vfs.User()) {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	sn := c.streams[stream]
+
+	switch {
+	case sn == nil && om&avfs.OpenCreate == 0:
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	case sn == nil:
+		sn = &fileNode{
+			baseNode: baseNode{
+				mode: c.mode,
+				uid:  c.uid,
+				gid:  c.gid,
+			},
+			id:    c.id,
+			nlink: 1,
+		}
+
+		sn.initTimes(vfs.Now())
+
+		if c.streams == nil {
+			c.streams = make(streams)
+		}
+
+		c.streams[stream] = sn
+	case om&avfs.OpenCreateExcl != 0:
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.FileExists}
+	default:
+		sn.mu.Lock()
+		defer sn.mu.Unlock()
+
+		if om&avfs.OpenTruncate != 0 {
+			vfs.markUnsynced(sn)
+			sn.truncate(0)
+			sn.setModified(vfs.Now())
+		}
+
+		if om&avfs.OpenAppend != 0 {
+			at = sn.size()
+		}
+	}
+
+	f = &MemFile{
+		nd:       sn,
+		vfs:      vfs,
+		name:     name,
+		at:       at,
+		openMode: om,
+	}
+
+	return f, nil
+}
+
+// removeStream removes the stream of the file fileName.
+func (vfs *MemFS) removeStream(name, fileName, stream string) error {
+	const op = "remove"
+
+	_, child, _, err := vfs.searchNode(fileName, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	c, ok := child.(*fileNode)
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkPermission(avfs.OpenWrite, vfs.User()) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	if c.streams[stream] == nil {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	}
+
+	delete(c.streams, stream)
+
+	return nil
+}
+
+// statStream returns the file information of the stream of the file fileName.
+func (vfs *MemFS) statStream(op, name, fileName, stream string) (fs.FileInfo, error) {
+	_, child, _, err := vfs.searchNode(fileName, slmStat)
+	if err != vfs.err.FileExists || child == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	c, ok := child.(*fileNode)
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	}
+
+	c.mu.RLock()
+	sn := c.streams[stream]
+	c.mu.RUnlock()
+
+	if sn == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	}
+
+	return fillStatFrom(sn, vfs.Base(name)), nil
+}
@@ -0,0 +1,109 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import "bytes"
+
+// Crash simulates a system crash of a file system created with the UnsyncedBuffers option :
+// the content of the files written since their last Sync is replaced by the content they had at that time
+// (a file never synced becomes empty).
+// Only the content of the files is lost, the operations on the directories (creations, renames, removals)
+// and the metadata are durable immediately.
+// The open files are not closed, they should not be used after Crash.
+// Crash waits for the mutations in progress to complete, it does nothing without the UnsyncedBuffers option.
+func (vfs *MemFS) Crash() {
+	if vfs.unsynced == nil {
+		return
+	}
+
+	vfs.Freeze()
+	defer vfs.Thaw()
+
+	u := vfs.unsynced
+
+	u.mu.Lock()
+	durable := u.durable
+	u.durable = make(map[*fileNode]*durableContent)
+	u.mu.Unlock()
+
+	for fn, dc := range durable {
+		fn.mu.Lock()
+
+		oldSize := fn.size()
+
+		fn.data = dc.data
+		fn.blocks = dc.blocks
+		fn.shares = nil
+		fn.notifySize(oldSize)
+
+		fn.mu.Unlock()
+	}
+}
+
+// Unsynced returns the number of files whose content would be lost by Crash.
+func (vfs *MemFS) Unsynced() int {
+	if vfs.unsynced == nil {
+		return 0
+	}
+
+	u := vfs.unsynced
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return len(u.durable)
+}
+
+// markUnsynced saves the durable content of the file before its first modification since its last Sync.
+// The blocks of a file stored in blocks are shared with the saved content and only copied when they are
+// modified, so that the cost does not depend on the size of the file.
+// The file must be locked.
+func (vfs *MemFS) markUnsynced(fn *fileNode) {
+	if vfs.unsynced == nil {
+		return
+	}
+
+	u := vfs.unsynced
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.durable[fn]; ok {
+		return
+	}
+
+	if fn.blocks != nil {
+		u.durable[fn] = &durableContent{blocks: fn.blocks.snapshot()}
+
+		return
+	}
+
+	u.durable[fn] = &durableContent{data: bytes.Clone(fn.data)}
+}
+
+// sync makes the content of the file durable.
+func (vfs *MemFS) sync(fn *fileNode) {
+	if vfs.unsynced == nil {
+		return
+	}
+
+	u := vfs.unsynced
+
+	u.mu.Lock()
+	delete(u.durable, fn)
+	u.mu.Unlock()
+}
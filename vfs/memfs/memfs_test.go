@@ -19,8 +19,17 @@
 package memfs_test
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"io/fs"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/idm/memidm"
@@ -35,6 +44,51 @@ var (
 	// Tests that memfs.MemFS struct implements avfs.VFSBase interface.
 	_ avfs.VFSBase = &memfs.MemFS{}
 
+	// Tests that memfs.MemFS struct implements avfs.ChRooter interface.
+	_ avfs.ChRooter = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.Cloner interface.
+	_ avfs.Cloner = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.Freezer interface.
+	_ avfs.Freezer = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.NetworkDriveMapper interface.
+	_ avfs.NetworkDriveMapper = &memfs.MemFS{}
+
+	// Tests that memfs.RingJournal struct implements memfs.Journal interface.
+	_ memfs.Journal = &memfs.RingJournal{}
+
+	// Tests that memfs.FileJournal struct implements memfs.Journal interface.
+	_ memfs.Journal = &memfs.FileJournal{}
+
+	// Tests that memfs.MemFS struct implements avfs.Mknoder interface.
+	_ avfs.Mknoder = &memfs.MemFS{}
+
+	// Tests that memfs.MemInfo struct implements avfs.DeviceStater interface.
+	_ avfs.DeviceStater = &memfs.MemInfo{}
+
+	// Tests that memfs.MemFS struct implements avfs.Renamer2 interface.
+	_ avfs.Renamer2 = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.Sampler interface.
+	_ avfs.Sampler = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.TempRander interface.
+	_ avfs.TempRander = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.SecurityManager interface.
+	_ avfs.SecurityManager = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.StreamLister interface.
+	_ avfs.StreamLister = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.ShortPathNamer interface.
+	_ avfs.ShortPathNamer = &memfs.MemFS{}
+
+	// Tests that memfs.MemFS struct implements avfs.SizeWatcher interface.
+	_ avfs.SizeWatcher = &memfs.MemFS{}
+
 	// Tests that memfs.MemFile struct implements avfs.File interface.
 	_ avfs.File = &memfs.MemFile{}
 
@@ -47,6 +101,9 @@ var (
 	// Tests that memfs.MemInfo struct implements avfs.SysStater interface.
 	_ avfs.SysStater = &memfs.MemInfo{}
 
+	// Tests that memfs.MemInfo struct implements avfs.BlockSizer interface.
+	_ avfs.BlockSizer = &memfs.MemInfo{}
+
 	// Tests that memfs.MemIOFS struct implements avfs.IOFS interface.
 	_ avfs.IOFS = &memfs.MemIOFS{}
 )
@@ -56,6 +113,8 @@ func TestMemFS(t *testing.T) {
 
 	ts := test.NewSuiteFS(t, vfs, vfs)
 	ts.TestVFSAll(t)
+
+	checkMemFS(t, vfs)
 }
 
 func TestMemFSWithNoIdm(t *testing.T) {
@@ -63,6 +122,26 @@ func TestMemFSWithNoIdm(t *testing.T) {
 
 	ts := test.NewSuiteFS(t, vfs, vfs)
 	ts.TestVFSAll(t)
+
+	checkMemFS(t, vfs)
+}
+
+func TestMemFSStrict(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{Idm: avfs.NotImplementedIdm, Strict: true})
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+
+	checkMemFS(t, vfs)
+}
+
+func TestMemFSCaseInsensitive(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{CaseInsensitive: true})
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+
+	checkMemFS(t, vfs)
 }
 
 func TestMemFSOptionUser(t *testing.T) {
@@ -132,9 +211,1977 @@ func TestMemFSConfig(t *testing.T) {
 	}
 }
 
-func BenchmarkMemFSAll(b *testing.B) {
+func TestMemFSOptionDevMode(t *testing.T) {
+	idm := memidm.NewWithOptions(&memidm.Options{OSType: avfs.OsWindows})
+
+	groupName := "aGroup"
+	_, err := idm.AddGroup(groupName)
+	test.RequireNoError(t, err, "AddGroup %s", groupName)
+
+	userName := "aUser"
+	u, err := idm.AddUser(userName, groupName)
+	test.RequireNoError(t, err, "AddUser %s", userName)
+
+	for _, devMode := range []bool{false, true} {
+		vfs := memfs.NewWithOptions(&memfs.Options{Idm: idm, User: u, OSType: avfs.OsWindows, DevMode: devMode})
+		if !test.CanEmulate(vfs, avfs.OsWindows) {
+			t.Skip("MemFS can't emulate Windows, use build tag 'avfs_setostype'")
+		}
+
+		err = vfs.MkdirAll(vfs.TempDir(), avfs.DefaultDirPerm)
+		test.RequireNoError(t, err, "MkdirAll %s", vfs.TempDir())
+
+		oldName := vfs.Join(vfs.TempDir(), "file")
+		newName := vfs.Join(vfs.TempDir(), "symlink")
+
+		err = vfs.Symlink(oldName, newName)
+		if devMode {
+			test.RequireNoError(t, err, "Symlink %s %s", oldName, newName)
+
+			continue
+		}
+
+		test.AssertLinkError(t, err).Op("symlink").Old(oldName).New(newName).
+			Err(avfs.ErrWinPrivilegeNotHeld).Test()
+	}
+}
+
+func TestMemFSOptionCaseInsensitive(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{CaseInsensitive: true})
+
+	dir := vfs.Join(vfs.TempDir(), "Dir")
+	err := vfs.Mkdir(dir, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "Mkdir %s", dir)
+
+	file := vfs.Join(dir, "File.txt")
+	err = vfs.WriteFile(file, []byte("data"), avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", file)
+
+	t.Run("CaseInsensitiveLookup", func(t *testing.T) {
+		path := vfs.Join(vfs.TempDir(), "DIR", "file.TXT")
+
+		info, err := vfs.Stat(path)
+		test.RequireNoError(t, err, "Stat %s", path)
+
+		if info.Name() != "File.txt" {
+			t.Errorf("Stat %s : want name to be File.txt, got %s", path, info.Name())
+		}
+
+		path = vfs.Join(vfs.TempDir(), "dir")
+
+		err = vfs.Mkdir(path, avfs.DefaultDirPerm)
+		test.AssertPathError(t, err).Op("mkdir").Path(path).Err(avfs.ErrFileExists).Test()
+	})
+
+	t.Run("CaseInsensitiveRename", func(t *testing.T) {
+		newFile := vfs.Join(dir, "FILE.TXT")
+
+		err := vfs.Rename(file, newFile)
+		test.RequireNoError(t, err, "Rename %s %s", file, newFile)
+
+		other := vfs.Join(dir, "other")
+		err = vfs.WriteFile(other, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", other)
+
+		err = vfs.Rename(other, file)
+		test.RequireNoError(t, err, "Rename %s %s", other, file)
+
+		entries, err := vfs.ReadDir(dir)
+		test.RequireNoError(t, err, "ReadDir %s", dir)
+
+		if len(entries) != 1 || entries[0].Name() != "File.txt" {
+			t.Errorf("ReadDir %s : want one entry named File.txt, got %v", dir, entries)
+		}
+	})
+}
+
+func TestMemFSOptionPathLimits(t *testing.T) {
+	for _, pathLimits := range []bool{false, true} {
+		vfs := memfs.NewWithOptions(&memfs.Options{PathLimits: pathLimits})
+
+		wantErr := error(avfs.ErrNameTooLong)
+		if vfs.OSType() == avfs.OsWindows {
+			wantErr = avfs.ErrWinFilenameExcedRange
+		}
+
+		maxName := strings.Repeat("a", 255)
+
+		path := vfs.Join(vfs.TempDir(), maxName)
+		err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		path = vfs.Join(vfs.TempDir(), maxName+"a")
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		if !pathLimits {
+			test.RequireNoError(t, err, "WriteFile %s", path)
+
+			continue
+		}
+
+		test.AssertPathError(t, err).Op("open").Path(path).Err(wantErr).Test()
+
+		_, err = vfs.Stat(path)
+		test.AssertPathError(t, err).OpStat().Path(path).Err(wantErr).Test()
+
+		err = vfs.Mkdir(path, avfs.DefaultDirPerm)
+		test.AssertPathError(t, err).Op("mkdir").Path(path).Err(wantErr).Test()
+
+		longPath := strings.Repeat(vfs.Join(maxName, ""), 20)
+
+		err = vfs.MkdirAll(longPath, avfs.DefaultDirPerm)
+		test.AssertPathError(t, err).Op("mkdir").Err(wantErr).Test()
+	}
+}
+
+func TestMemFSOptionClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var names [2]string
+
+	for i := range names {
+		clock := avfs.NewFakeClock(start, time.Second)
+		vfs := memfs.NewWithOptions(&memfs.Options{Clock: clock})
+
+		clock.Set(start.Add(time.Hour))
+
+		f, err := vfs.CreateTemp("", "clock")
+		test.RequireNoError(t, err, "CreateTemp")
+
+		names[i] = f.Name()
+
+		_, err = f.WriteString("data")
+		test.RequireNoError(t, err, "WriteString %s", f.Name())
+
+		info, err := f.Stat()
+		test.RequireNoError(t, err, "Stat %s", f.Name())
+
+		if want := start.Add(time.Hour + time.Second); !info.ModTime().Equal(want) {
+			t.Errorf("Stat %s : want modification time to be %v, got %v", f.Name(), want, info.ModTime())
+		}
+
+		_ = f.Close()
+	}
+
+	if names[0] != names[1] {
+		t.Errorf("CreateTemp : want names with the same clock to be equal, got %s and %s", names[0], names[1])
+	}
+
+	vfs := memfs.New()
+	vfs.SetClock(nil)
+
+	if now := vfs.Now(); time.Since(now) > time.Minute {
+		t.Errorf("Now : want the system time, got %v", now)
+	}
+}
+
+func TestMemFSOptionUnsyncedBuffers(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{UnsyncedBuffers: true})
+
+	file := vfs.Join(vfs.TempDir(), "file")
+	tmpFile := file + ".tmp"
+
+	writeFile := func(t *testing.T, name, data string, sync bool) {
+		t.Helper()
+
+		f, err := vfs.Create(name)
+		test.RequireNoError(t, err, "Create %s", name)
+
+		_, err = f.WriteString(data)
+		test.RequireNoError(t, err, "WriteString %s", name)
+
+		if sync {
+			err = f.Sync()
+			test.RequireNoError(t, err, "Sync %s", name)
+		}
+
+		err = f.Close()
+		test.RequireNoError(t, err, "Close %s", name)
+	}
+
+	checkFile := func(t *testing.T, name, want string) {
+		t.Helper()
+
+		data, err := vfs.ReadFile(name)
+		test.RequireNoError(t, err, "ReadFile %s", name)
+
+		if string(data) != want {
+			t.Errorf("ReadFile %s : want data to be %q, got %q", name, want, data)
+		}
+	}
+
+	writeFile(t, file, "v1", true)
+
+	t.Run("CrashUnsynced", func(t *testing.T) {
+		writeFile(t, file, "v2", false)
+
+		if n := vfs.Unsynced(); n != 1 {
+			t.Errorf("Unsynced : want 1 unsynced file, got %d", n)
+		}
+
+		vfs.Crash()
+		checkFile(t, file, "v1")
+
+		if n := vfs.Unsynced(); n != 0 {
+			t.Errorf("Unsynced : want no unsynced file, got %d", n)
+		}
+	})
+
+	t.Run("CrashRenameWithoutSync", func(t *testing.T) {
+		writeFile(t, tmpFile, "v2", false)
+
+		err := vfs.Rename(tmpFile, file)
+		test.RequireNoError(t, err, "Rename %s %s", tmpFile, file)
+
+		vfs.Crash()
+		checkFile(t, file, "")
+	})
+
+	t.Run("CrashRenameWithSync", func(t *testing.T) {
+		writeFile(t, tmpFile, "v3", true)
+
+		err := vfs.Rename(tmpFile, file)
+		test.RequireNoError(t, err, "Rename %s %s", tmpFile, file)
+
+		vfs.Crash()
+		checkFile(t, file, "v3")
+	})
+
+	t.Run("CrashSizeEvent", func(t *testing.T) {
+		writeFile(t, file, "v4", true)
+
+		events, cancel, err := vfs.WatchSize(file)
+		test.RequireNoError(t, err, "WatchSize %s", file)
+
+		defer cancel()
+
+		err = vfs.WriteFile(file, []byte("v5-unsynced"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", file)
+
+		for len(events) > 0 {
+			<-events
+		}
+
+		vfs.Crash()
+		checkFile(t, file, "v4")
+
+		want := avfs.SizeEvent{Name: file, OldSize: int64(len("v5-unsynced")), NewSize: int64(len("v4"))}
+		if n := len(events); n != 1 {
+			t.Fatalf("Crash : want 1 size event, got %d", n)
+		}
+
+		if event := <-events; event != want {
+			t.Errorf("Crash : want size event to be %+v, got %+v", want, event)
+		}
+	})
+
+	t.Run("CrashDisabled", func(t *testing.T) {
+		vfs := memfs.New()
+
+		err := vfs.WriteFile(file, []byte("data"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", file)
+
+		vfs.Crash()
+
+		data, err := vfs.ReadFile(file)
+		if err != nil || string(data) != "data" {
+			t.Errorf("ReadFile %s : want data to be kept, got %q, %v", file, data, err)
+		}
+	})
+}
+
+func TestMemFSOptionAtime(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	times := func(t *testing.T, vfs *memfs.MemFS, name string) (atime, ctime time.Time) {
+		t.Helper()
+
+		info, err := vfs.Stat(name)
+		test.RequireNoError(t, err, "Stat %s", name)
+
+		sst := vfs.ToSysStat(info)
+
+		return sst.(avfs.AccessTimer).Atime(), sst.(avfs.ChangeTimer).Ctime()
+	}
+
+	// readAfter advances the clock by d, reads the file and returns its access time.
+	readAfter := func(t *testing.T, vfs *memfs.MemFS, clock *avfs.FakeClock, name string, d time.Duration) time.Time {
+		t.Helper()
+
+		clock.Advance(d)
+
+		_, err := vfs.ReadFile(name)
+		test.RequireNoError(t, err, "ReadFile %s", name)
+
+		atime, _ := times(t, vfs, name)
+
+		return atime
+	}
+
+	tests := []struct {
+		mode  memfs.AtimeMode
+		want1 time.Duration // want1 is the access time after a first read 1 hour after the creation.
+		want2 time.Duration // want2 is the access time after a second read 1 hour later.
+		want3 time.Duration // want3 is the access time after a third read 1 day later.
+	}{
+		{mode: memfs.AtimeRelative, want1: time.Hour, want2: time.Hour, want3: 26 * time.Hour},
+		{mode: memfs.AtimeStrict, want1: time.Hour, want2: 2 * time.Hour, want3: 26 * time.Hour},
+		{mode: memfs.AtimeNever, want1: 0, want2: 0, want3: 0},
+	}
+
+	for _, tt := range tests {
+		clock := avfs.NewFakeClock(start, 0)
+		vfs := memfs.NewWithOptions(&memfs.Options{Clock: clock, Atime: tt.mode})
+		file := vfs.Join(vfs.TempDir(), "file")
+
+		err := vfs.WriteFile(file, []byte("data"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", file)
+
+		for i, want := range []time.Duration{tt.want1, tt.want2, tt.want3} {
+			d := time.Hour
+			if i == 2 {
+				d = 24 * time.Hour
+			}
+
+			if atime := readAfter(t, vfs, clock, file, d); !atime.Equal(start.Add(want)) {
+				t.Errorf("ReadFile %s : atime mode %d, read %d, want access time to be %v, got %v",
+					file, tt.mode, i+1, start.Add(want), atime)
+			}
+		}
+	}
+
+	t.Run("AtimeDefault", func(t *testing.T) {
+		clock := avfs.NewFakeClock(start, 0)
+		vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows, Clock: clock})
+		if !test.CanEmulate(vfs, avfs.OsWindows) {
+			t.Skip("MemFS can't emulate Windows, use build tag 'avfs_setostype'")
+		}
+
+		tmpDir := vfs.TempDir()
+		err := vfs.MkdirAll(tmpDir, avfs.DefaultDirPerm)
+		test.RequireNoError(t, err, "MkdirAll %s", tmpDir)
+
+		file := vfs.Join(tmpDir, "file")
+
+		err = vfs.WriteFile(file, []byte("data"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", file)
+
+		if atime := readAfter(t, vfs, clock, file, time.Hour); !atime.Equal(start) {
+			t.Errorf("ReadFile %s : want access time not to be updated on Windows, got %v", file, atime)
+		}
+	})
+
+	t.Run("Ctime", func(t *testing.T) {
+		clock := avfs.NewFakeClock(start, 0)
+		vfs := memfs.NewWithOptions(&memfs.Options{Clock: clock})
+		file := vfs.Join(vfs.TempDir(), "file")
+
+		err := vfs.WriteFile(file, []byte("data"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", file)
+
+		clock.Advance(time.Hour)
+
+		err = vfs.Chmod(file, 0o600)
+		test.RequireNoError(t, err, "Chmod %s", file)
+
+		info, err := vfs.Stat(file)
+		test.RequireNoError(t, err, "Stat %s", file)
+
+		if _, ctime := times(t, vfs, file); !ctime.Equal(start.Add(time.Hour)) || !info.ModTime().Equal(start) {
+			t.Errorf("Chmod %s : want change time to be %v and modification time %v, got %v and %v",
+				file, start.Add(time.Hour), start, ctime, info.ModTime())
+		}
+
+		clock.Advance(time.Hour)
+
+		atime := start.Add(-time.Hour)
+
+		err = vfs.Chtimes(file, atime, time.Time{})
+		test.RequireNoError(t, err, "Chtimes %s", file)
+
+		info, err = vfs.Stat(file)
+		test.RequireNoError(t, err, "Stat %s", file)
+
+		gotAtime, ctime := times(t, vfs, file)
+		if !gotAtime.Equal(atime) || !ctime.Equal(start.Add(2*time.Hour)) || !info.ModTime().Equal(start) {
+			t.Errorf("Chtimes %s : want times (atime, ctime, mtime) to be (%v, %v, %v), got (%v, %v, %v)",
+				file, atime, start.Add(2*time.Hour), start, gotAtime, ctime, info.ModTime())
+		}
+	})
+
+	t.Run("DirTimes", func(t *testing.T) {
+		clock := avfs.NewFakeClock(start, 0)
+		vfs := memfs.NewWithOptions(&memfs.Options{Clock: clock})
+		dir := vfs.Join(vfs.TempDir(), "dir")
+		otherDir := vfs.Join(vfs.TempDir(), "other")
+		file := vfs.Join(dir, "file")
+		renamed := vfs.Join(otherDir, "file")
+
+		test.RequireNoError(t, vfs.Mkdir(dir, avfs.DefaultDirPerm), "Mkdir %s", dir)
+		test.RequireNoError(t, vfs.Mkdir(otherDir, avfs.DefaultDirPerm), "Mkdir %s", otherDir)
+
+		// checkDirTimes checks that the modification and change times of the directories are now.
+		checkDirTimes := func(t *testing.T, op string, dirs ...string) {
+			t.Helper()
+
+			now := clock.Now()
+
+			for _, dir := range dirs {
+				info, err := vfs.Stat(dir)
+				test.RequireNoError(t, err, "Stat %s", dir)
+
+				if _, ctime := times(t, vfs, dir); !ctime.Equal(now) || !info.ModTime().Equal(now) {
+					t.Errorf("%s : want times (ctime, mtime) of %s to be %v, got (%v, %v)",
+						op, dir, now, ctime, info.ModTime())
+				}
+			}
+		}
+
+		clock.Advance(time.Hour)
+
+		err := vfs.WriteFile(file, []byte("data"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", file)
+		checkDirTimes(t, "Create", dir)
+
+		clock.Advance(time.Hour)
+
+		err = vfs.Rename(file, renamed)
+		test.RequireNoError(t, err, "Rename %s %s", file, renamed)
+		checkDirTimes(t, "Rename", dir, otherDir)
+
+		if _, ctime := times(t, vfs, renamed); !ctime.Equal(clock.Now()) {
+			t.Errorf("Rename %s : want change time to be %v, got %v", renamed, clock.Now(), ctime)
+		}
+
+		clock.Advance(time.Hour)
+
+		err = vfs.Remove(renamed)
+		test.RequireNoError(t, err, "Remove %s", renamed)
+		checkDirTimes(t, "Remove", otherDir)
+	})
+}
+
+func TestMemFSWindowsNames(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows})
+	if !test.CanEmulate(vfs, avfs.OsWindows) {
+		t.Skip("MemFS can't emulate Windows, use build tag 'avfs_setostype'")
+	}
+
+	tmpDir := vfs.TempDir()
+	err := vfs.MkdirAll(tmpDir, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", tmpDir)
+
+	t.Run("WindowsTrailingDotsSpaces", func(t *testing.T) {
+		path := vfs.Join(tmpDir, "file.txt. .")
+
+		err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		info, err := vfs.Stat(vfs.Join(tmpDir, "file.txt"))
+		test.RequireNoError(t, err, "Stat %s", path)
+
+		if info.Name() != "file.txt" {
+			t.Errorf("Stat %s : want name to be file.txt, got %s", path, info.Name())
+		}
+	})
+
+	t.Run("WindowsReservedNames", func(t *testing.T) {
+		for _, name := range []string{"CON", "nul.txt", "Com1", "LPT9.log", "aux. ", "a?b", "a|b"} {
+			path := vfs.Join(tmpDir, name)
+
+			err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+			test.AssertPathError(t, err).Op("open").Path(path).Err(avfs.ErrWinInvalidName).Test()
+
+			err = vfs.Mkdir(path, avfs.DefaultDirPerm)
+			test.AssertPathError(t, err).Op("mkdir").Path(path).Err(avfs.ErrWinInvalidName).Test()
+
+			subDir := vfs.Join(path, "subDir")
+
+			err = vfs.MkdirAll(subDir, avfs.DefaultDirPerm)
+			test.AssertPathError(t, err).Op("mkdir").Path(subDir).Err(avfs.ErrWinInvalidName).Test()
+		}
+
+		for _, name := range []string{"CONSOLE", "COM0", "nul_", "LPT"} {
+			path := vfs.Join(tmpDir, name)
+
+			err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+			test.RequireNoError(t, err, "WriteFile %s", path)
+		}
+	})
+
+	t.Run("WindowsRenameOtherVolume", func(t *testing.T) {
+		err := vfs.VolumeAdd(`D:`)
+		test.RequireNoError(t, err, "VolumeAdd D:")
+
+		oldPath := vfs.Join(tmpDir, "move.txt")
+		newPath := `D:\move.txt`
+
+		err = vfs.WriteFile(oldPath, []byte("move"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", oldPath)
+
+		err = vfs.Rename(oldPath, newPath)
+		test.AssertLinkError(t, err).Op("rename").Old(oldPath).New(newPath).
+			Err(avfs.ErrWinNotSameDevice).Test()
+
+		err = avfs.Move(vfs, vfs, newPath, oldPath)
+		test.RequireNoError(t, err, "Move %s %s", oldPath, newPath)
+
+		_, err = vfs.Stat(oldPath)
+		test.AssertPathError(t, err).Op("CreateFile").Path(oldPath).Err(avfs.ErrWinFileNotFound).Test()
+
+		content, err := vfs.ReadFile(newPath)
+		test.RequireNoError(t, err, "ReadFile %s", newPath)
+
+		if string(content) != "move" {
+			t.Errorf("ReadFile %s : want content to be move, got %s", newPath, content)
+		}
+	})
+}
+
+func TestMemFSVolumeMount(t *testing.T) {
+	idm := memidm.NewWithOptions(&memidm.Options{OSType: avfs.OsWindows})
+
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows, Idm: idm})
+	if !test.CanEmulate(vfs, avfs.OsWindows) {
+		t.Skip("MemFS can't emulate Windows, use build tag 'avfs_setostype'")
+	}
+
+	srcFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows, Idm: idm})
+	srcDir := srcFS.TempDir()
+
+	err := srcFS.MkdirAll(srcDir, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", srcDir)
+
+	t.Run("VolumeMountMemFS", func(t *testing.T) {
+		err := vfs.VolumeMount(`D:`, srcFS, srcDir)
+		test.RequireNoError(t, err, "VolumeMount D: %s", srcDir)
+
+		path := `D:\file.txt`
+
+		err = vfs.WriteFile(path, []byte("mounted"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		srcPath := srcFS.Join(srcDir, "file.txt")
+
+		data, err := srcFS.ReadFile(srcPath)
+		test.RequireNoError(t, err, "ReadFile %s", srcPath)
+
+		if string(data) != "mounted" {
+			t.Errorf("ReadFile %s : want content to be mounted, got %s", srcPath, data)
+		}
+
+		err = vfs.VolumeDelete(`D:`)
+		test.RequireNoError(t, err, "VolumeDelete D:")
+
+		_, err = srcFS.Stat(srcPath)
+		test.RequireNoError(t, err, "Stat %s", srcPath)
+	})
+
+	t.Run("VolumeMountSameFile", func(t *testing.T) {
+		err := vfs.VolumeMount(`D:`, srcFS, srcDir)
+		test.RequireNoError(t, err, "VolumeMount D: %s", srcDir)
+
+		defer vfs.VolumeDelete(`D:`) //nolint:errcheck // Ignore errors.
+
+		info, err := vfs.Stat(`D:\`)
+		test.RequireNoError(t, err, "Stat %s", `D:\`)
+
+		for _, path := range []string{`C:\`, vfs.TempDir()} {
+			other, err := vfs.Stat(path)
+			test.RequireNoError(t, err, "Stat %s", path)
+
+			if vfs.SameFile(info, other) {
+				t.Errorf("SameFile : want the root of the volume and %s to be different files", path)
+			}
+		}
+	})
+
+	t.Run("VolumeMountOptions", func(t *testing.T) {
+		for _, opts := range []*memfs.Options{
+			{OSType: avfs.OsWindows},
+			{OSType: avfs.OsWindows, Idm: idm, CaseInsensitive: true},
+			{OSType: avfs.OsWindows, Idm: idm, ShortNames: true},
+		} {
+			otherFS := memfs.NewWithOptions(opts)
+
+			err := otherFS.MkdirAll(srcDir, avfs.DefaultDirPerm)
+			test.RequireNoError(t, err, "MkdirAll %s", srcDir)
+
+			err = vfs.VolumeMount(`E:`, otherFS, srcDir)
+			test.AssertPathError(t, err).Op("VolumeMount").Path(srcDir).Err(avfs.ErrVolumeMountOptions).Test()
+		}
+	})
+}
+
+func TestMemFSGraft(t *testing.T) {
+	src := memfs.New()
+	srcDir := src.Join(src.TempDir(), "data")
+	srcFile := src.Join(srcDir, "file")
+	wantData := []byte("reference data")
+
+	err := src.MkdirAll(src.Join(srcDir, "subDir"), avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", srcDir)
+
+	err = src.WriteFile(srcFile, wantData, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", srcFile)
+
+	vfs := memfs.New()
+	dir := vfs.Join(vfs.TempDir(), "graft")
+	file := vfs.Join(dir, "file")
+
+	err = vfs.Graft(dir, src, srcDir)
+	test.RequireNoError(t, err, "Graft %s", dir)
+
+	t.Run("GraftRead", func(t *testing.T) {
+		entries, err := vfs.ReadDir(dir)
+		test.RequireNoError(t, err, "ReadDir %s", dir)
+
+		if len(entries) != 2 || entries[0].Name() != "file" || !entries[1].IsDir() {
+			t.Errorf("ReadDir %s : want entries to be file and subDir, got %v", dir, entries)
+		}
+
+		info, err := vfs.Stat(file)
+		test.RequireNoError(t, err, "Stat %s", file)
+
+		if info.Size() != int64(len(wantData)) {
+			t.Errorf("Stat %s : want size to be %d, got %d", file, len(wantData), info.Size())
+		}
+
+		data, err := vfs.ReadFile(file)
+		test.RequireNoError(t, err, "ReadFile %s", file)
+
+		if string(data) != string(wantData) {
+			t.Errorf("ReadFile %s : want data to be %s, got %s", file, wantData, data)
+		}
+	})
+
+	t.Run("GraftReadOnly", func(t *testing.T) {
+		err := vfs.WriteFile(file, nil, avfs.DefaultFilePerm)
+		test.AssertPathError(t, err).Op("open").Path(file).ErrPermDenied().Test()
+
+		newFile := vfs.Join(dir, "newFile")
+
+		err = vfs.WriteFile(newFile, nil, avfs.DefaultFilePerm)
+		test.AssertPathError(t, err).Op("open").Path(newFile).ErrPermDenied().Test()
+
+		err = vfs.Chmod(file, avfs.DefaultFilePerm)
+		test.AssertPathError(t, err).Op("chmod").Path(file).Err(avfs.ErrOpNotPermitted).Test()
+
+		err = vfs.Remove(file)
+		test.AssertPathError(t, err).Op("remove").Path(file).ErrPermDenied().Test()
+	})
+
+	t.Run("GraftExisting", func(t *testing.T) {
+		err := vfs.Graft(dir, src, srcDir)
+		test.AssertPathError(t, err).Op("graft").Path(dir).Err(avfs.ErrFileExists).Test()
+
+		err = vfs.Graft(vfs.Join(vfs.TempDir(), "other"), src, srcFile)
+		test.AssertPathError(t, err).Op("graft").Path(srcFile).Err(avfs.ErrNotADirectory).Test()
+	})
+
+	t.Run("Ungraft", func(t *testing.T) {
+		err := vfs.RemoveAll(dir)
+		test.RequireNoError(t, err, "RemoveAll %s", dir)
+
+		_, err = vfs.Stat(dir)
+		test.AssertPathError(t, err).Op("stat").Path(dir).Err(avfs.ErrNoSuchFileOrDir).Test()
+
+		_, err = src.Stat(srcFile)
+		test.RequireNoError(t, err, "Stat %s", srcFile)
+	})
+}
+
+func TestMemFSReadDirInto(t *testing.T) {
 	vfs := memfs.New()
+	dir := vfs.TempDir()
+
+	for _, name := range []string{"c", "a", "b"} {
+		path := vfs.Join(dir, name)
+
+		err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+	}
+
+	subDir := vfs.Join(dir, "d")
+
+	err := vfs.Mkdir(subDir, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "Mkdir %s", subDir)
+
+	wantEntries, err := vfs.ReadDir(dir)
+	test.RequireNoError(t, err, "ReadDir %s", dir)
+
+	t.Run("ReadDirIntoShortBuffer", func(t *testing.T) {
+		entries := make([]fs.DirEntry, 2)
+
+		n, err := vfs.ReadDirInto(dir, entries)
+		test.AssertPathError(t, err).Op("readdirent").Path(dir).Err(io.ErrShortBuffer).Test()
+
+		if n != len(wantEntries) {
+			t.Errorf("ReadDirInto : want n to be %d, got %d", len(wantEntries), n)
+		}
+	})
+
+	t.Run("ReadDirInto", func(t *testing.T) {
+		entries := make([]fs.DirEntry, 10)
+
+		n, err := vfs.ReadDirInto(dir, entries)
+		test.RequireNoError(t, err, "ReadDirInto %s", dir)
+
+		if n != len(wantEntries) {
+			t.Fatalf("ReadDirInto : want n to be %d, got %d", len(wantEntries), n)
+		}
+
+		for i, want := range wantEntries {
+			got := entries[i]
+			if got.Name() != want.Name() || got.IsDir() != want.IsDir() {
+				t.Errorf("ReadDirInto : want entry %d to be %s (dir %t), got %s (dir %t)",
+					i, want.Name(), want.IsDir(), got.Name(), got.IsDir())
+			}
+		}
+
+		allocs := testing.AllocsPerRun(10, func() {
+			_, _ = vfs.ReadDirInto(dir, entries)
+		})
+
+		for _, name := range []string{"e", "f", "g"} {
+			path := vfs.Join(dir, name)
+
+			err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+			test.RequireNoError(t, err, "WriteFile %s", path)
+		}
+
+		moreAllocs := testing.AllocsPerRun(10, func() {
+			_, _ = vfs.ReadDirInto(dir, entries)
+		})
+		if moreAllocs != allocs {
+			t.Errorf("ReadDirInto : want allocations independent of the number of entries (%v), got %v",
+				allocs, moreAllocs)
+		}
+	})
+
+	t.Run("ReadDirIntoFile", func(t *testing.T) {
+		path := vfs.Join(dir, "a")
+
+		_, err := vfs.ReadDirInto(path, make([]fs.DirEntry, 1))
+		test.AssertPathError(t, err).Op("readdirent").Path(path).Err(avfs.ErrNotADirectory).Test()
+	})
+}
+
+func TestMemFSReadFileShared(t *testing.T) {
+	vfs := memfs.New()
+	path := vfs.Join(vfs.TempDir(), "shared")
+	content := []byte("shared content")
+
+	err := vfs.WriteFile(path, content, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", path)
+
+	data, release, err := vfs.ReadFileShared(path)
+	test.RequireNoError(t, err, "ReadFileShared %s", path)
+
+	if !bytes.Equal(data, content) {
+		t.Errorf("ReadFileShared : want data to be %s, got %s", content, data)
+	}
+
+	t.Run("CopyOnWrite", func(t *testing.T) {
+		f, err := vfs.OpenFile(path, os.O_RDWR, 0)
+		test.RequireNoError(t, err, "OpenFile %s", path)
+
+		defer f.Close()
+
+		_, err = f.WriteAt([]byte("SHARED"), 0)
+		test.RequireNoError(t, err, "WriteAt %s", path)
+
+		err = f.Truncate(3)
+		test.RequireNoError(t, err, "Truncate %s", path)
+
+		err = f.Truncate(int64(len(content)))
+		test.RequireNoError(t, err, "Truncate %s", path)
+
+		if !bytes.Equal(data, content) {
+			t.Errorf("ReadFileShared : want shared data to be unchanged, got %s", data)
+		}
+
+		release()
+		release()
+
+		got, err := vfs.ReadFile(path)
+		test.RequireNoError(t, err, "ReadFile %s", path)
+
+		if want := []byte("SHA\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"); !bytes.Equal(got, want) {
+			t.Errorf("ReadFile : want data to be %q, got %q", want, got)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		nonExisting := vfs.Join(vfs.TempDir(), "nonExisting")
+
+		_, release, err := vfs.ReadFileShared(nonExisting)
+		test.AssertPathError(t, err).Op("open").Path(nonExisting).
+			OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+
+		release()
+
+		dir := vfs.TempDir()
+
+		_, _, err = vfs.ReadFileShared(dir)
+		test.AssertPathError(t, err).Op("read").Path(dir).
+			OSType(avfs.OsLinux).Err(avfs.ErrIsADirectory).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinIncorrectFunc).Test()
+	})
+}
+
+func BenchmarkMemFSAll(b *testing.B) {
+	test.BenchAll(b, memfs.New())
+}
+
+func TestMemFSJournal(t *testing.T) {
+	journalFS := memfs.New()
+	journalFile := journalFS.Join(journalFS.TempDir(), "journal")
+
+	fileJournal, err := memfs.NewFileJournal(journalFS, journalFile)
+	test.RequireNoError(t, err, "NewFileJournal %s", journalFile)
+
+	ringJournal := memfs.NewRingJournal(100)
+
+	for _, journal := range []memfs.Journal{ringJournal, fileJournal} {
+		vfs := memfs.NewWithOptions(&memfs.Options{Journal: journal})
+		dir := vfs.Join(vfs.TempDir(), "dir")
+		file := vfs.Join(dir, "file")
+		mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+
+		test.RequireNoError(t, vfs.MkdirAll(vfs.Join(dir, "a", "b"), avfs.DefaultDirPerm), "MkdirAll")
+		test.RequireNoError(t, vfs.WriteFile(file, []byte("first"), avfs.DefaultFilePerm), "WriteFile")
+
+		// Failed mutations are not recorded.
+		_ = vfs.Mkdir(dir, avfs.DefaultDirPerm)
+
+		f, err := vfs.OpenFile(file, os.O_RDWR, 0)
+		test.RequireNoError(t, err, "OpenFile %s", file)
+
+		_, _ = f.WriteAt([]byte("FIRST"), 0)
+		_, _ = f.Write([]byte("!"))
+		_ = f.Truncate(3)
+		_ = f.Close()
+
+		test.RequireNoError(t, vfs.Chmod(file, 0o600), "Chmod")
+		test.RequireNoError(t, vfs.Chtimes(file, mtime, mtime), "Chtimes")
+		test.RequireNoError(t, vfs.Link(file, vfs.Join(dir, "hardlink")), "Link")
+		test.RequireNoError(t, vfs.Symlink(file, vfs.Join(dir, "symlink")), "Symlink")
+		test.RequireNoError(t, vfs.Rename(vfs.Join(dir, "a"), vfs.Join(dir, "c")), "Rename")
+		test.RequireNoError(t, vfs.RemoveAll(vfs.Join(dir, "c")), "RemoveAll")
+
+		var entries []memfs.JournalEntry
+
+		switch j := journal.(type) {
+		case *memfs.RingJournal:
+			entries = j.Entries()
+		case *memfs.FileJournal:
+			test.RequireNoError(t, j.Close(), "Close")
+
+			entries, err = memfs.ReadJournal(journalFS, journalFile)
+			test.RequireNoError(t, err, "ReadJournal %s", journalFile)
+		}
+
+		wantOps := []string{
+			memfs.JournalMkdirAll, memfs.JournalOpen, memfs.JournalWrite, memfs.JournalWrite, memfs.JournalWrite,
+			memfs.JournalTruncate, memfs.JournalChmod, memfs.JournalChtimes, memfs.JournalLink, memfs.JournalSymlink,
+			memfs.JournalRename, memfs.JournalRemoveAll,
+		}
+
+		if len(entries) != len(wantOps) {
+			t.Fatalf("Entries : want %d entries, got %d", len(wantOps), len(entries))
+		}
+
+		for i, e := range entries {
+			if e.Op != wantOps[i] || e.Seq != uint64(i+1) {
+				t.Errorf("Entries %d : want op %s seq %d, got %s seq %d", i, wantOps[i], i+1, e.Op, e.Seq)
+			}
+		}
+
+		// Reconstruct the file system just after the truncation of the file.
+		replayFS := memfs.New()
+
+		err = memfs.Replay(replayFS, entries[:6])
+		test.RequireNoError(t, err, "Replay")
+
+		data, err := replayFS.ReadFile(file)
+		test.RequireNoError(t, err, "ReadFile %s", file)
+
+		if string(data) != "!IR" {
+			t.Errorf("ReadFile : want data to be %s, got %s", "!IR", data)
+		}
+
+		_, err = replayFS.Stat(vfs.Join(dir, "a", "b"))
+		test.RequireNoError(t, err, "Stat")
+
+		// Reconstruct the final file system.
+		err = memfs.Replay(replayFS, entries[6:])
+		test.RequireNoError(t, err, "Replay")
+
+		for _, name := range []string{file, vfs.Join(dir, "hardlink"), vfs.Join(dir, "symlink")} {
+			want, _ := vfs.Lstat(name)
+
+			got, err := replayFS.Lstat(name)
+			test.RequireNoError(t, err, "Lstat %s", name)
+
+			if got.Mode() != want.Mode() || got.Size() != want.Size() || !got.ModTime().Equal(want.ModTime()) &&
+				got.Mode()&fs.ModeSymlink == 0 {
+				t.Errorf("Lstat %s : want (%s, %d, %s), got (%s, %d, %s)", name,
+					want.Mode(), want.Size(), want.ModTime(), got.Mode(), got.Size(), got.ModTime())
+			}
+		}
+
+		_, err = replayFS.Stat(vfs.Join(dir, "c"))
+		test.AssertPathError(t, err).OpStat().Err(avfs.ErrNoSuchFileOrDir).Test()
+	}
+}
+
+// checkJournal is a journal checking that the mode of a chmod entry is the current mode of the file,
+// no other mutation can be applied before the entry is appended.
+type checkJournal struct {
+	vfs        *memfs.MemFS
+	mismatches int
+}
+
+func (j *checkJournal) Append(entry *memfs.JournalEntry) error {
+	if entry.Op != memfs.JournalChmod {
+		return nil
+	}
+
+	// Let the other goroutines mutate the file if they can.
+	runtime.Gosched()
+
+	info, err := j.vfs.Stat(entry.Path)
+	if err != nil || info.Mode().Perm() != entry.Mode {
+		j.mismatches++
+	}
+
+	return nil
+}
+
+func TestMemFSJournalConcurrent(t *testing.T) {
+	const (
+		goroutines = 8
+		chmods     = 200
+	)
+
+	journal := &checkJournal{}
+	vfs := memfs.NewWithOptions(&memfs.Options{Journal: journal})
+	journal.vfs = vfs
+	file := vfs.Join(vfs.TempDir(), "file")
+
+	test.RequireNoError(t, vfs.WriteFile(file, nil, avfs.DefaultFilePerm), "WriteFile")
+
+	var wg sync.WaitGroup
+
+	for i := range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range chmods {
+				_ = vfs.Chmod(file, fs.FileMode(0o600+i))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if journal.mismatches != 0 {
+		t.Errorf("Append : want the entries to be appended in the order the mutations are applied, "+
+			"got %d entries appended after another mutation", journal.mismatches)
+	}
+}
+
+func TestMemFSJournalOps(t *testing.T) {
+	t.Run("JournalSetACL", func(t *testing.T) {
+		ringJournal := memfs.NewRingJournal(10)
+		vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, Journal: ringJournal})
+		file := vfs.Join(vfs.TempDir(), "file")
+		acl := []avfs.ACLEntry{
+			{Tag: avfs.ACLUserObj, Perm: avfs.OpenRead | avfs.OpenWrite},
+			{Tag: avfs.ACLUser, Id: 1000, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLGroupObj, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLMask, Perm: avfs.OpenRead},
+			{Tag: avfs.ACLOther},
+		}
+
+		test.RequireNoError(t, vfs.WriteFile(file, nil, avfs.DefaultFilePerm), "WriteFile")
+		test.RequireNoError(t, vfs.SetACL(file, avfs.ACLAccess, acl), "SetACL")
+
+		replayFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+
+		err := memfs.Replay(replayFS, ringJournal.Entries())
+		test.RequireNoError(t, err, "Replay")
+
+		want, _ := vfs.GetACL(file, avfs.ACLAccess)
+
+		got, err := replayFS.GetACL(file, avfs.ACLAccess)
+		test.RequireNoError(t, err, "GetACL %s", file)
+
+		if !slices.Equal(got, want) {
+			t.Errorf("GetACL : want ACL to be %v, got %v", want, got)
+		}
+	})
+
+	t.Run("JournalStream", func(t *testing.T) {
+		ringJournal := memfs.NewRingJournal(10)
+
+		vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows, Journal: ringJournal})
+		if !test.CanEmulate(vfs, avfs.OsWindows) {
+			t.Skip("MemFS can't emulate Windows, use build tag 'avfs_setostype'")
+		}
+
+		test.RequireNoError(t, vfs.MkdirAll(vfs.TempDir(), avfs.DefaultDirPerm), "MkdirAll")
+
+		file := vfs.Join(vfs.TempDir(), "file")
+		stream := file + ":stream"
+
+		test.RequireNoError(t, vfs.WriteFile(stream, []byte("data"), avfs.DefaultFilePerm), "WriteFile")
+
+		wantOps := []string{memfs.JournalMkdirAll, memfs.JournalOpen, memfs.JournalOpen, memfs.JournalWrite}
+		entries := ringJournal.Entries()
+
+		if len(entries) != len(wantOps) {
+			t.Fatalf("Entries : want %d entries, got %d", len(wantOps), len(entries))
+		}
+
+		for i, e := range entries {
+			if e.Op != wantOps[i] {
+				t.Errorf("Entries %d : want op %s, got %s", i, wantOps[i], e.Op)
+			}
+		}
+
+		replayFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows})
+
+		err := memfs.Replay(replayFS, entries)
+		test.RequireNoError(t, err, "Replay")
+
+		data, err := replayFS.ReadFile(stream)
+		test.RequireNoError(t, err, "ReadFile %s", stream)
+
+		if string(data) != "data" {
+			t.Errorf("ReadFile : want data to be %s, got %s", "data", data)
+		}
+	})
+
+	t.Run("JournalRenameOpenFile", func(t *testing.T) {
+		ringJournal := memfs.NewRingJournal(20)
+		vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, Journal: ringJournal})
+		dir := vfs.Join(vfs.TempDir(), "dir")
+		file := vfs.Join(dir, "file")
+		newDir := vfs.Join(vfs.TempDir(), "newDir")
+		newFile := vfs.Join(newDir, "newFile")
+		removed := vfs.Join(vfs.TempDir(), "removed")
+
+		test.RequireNoError(t, vfs.Mkdir(dir, avfs.DefaultDirPerm), "Mkdir %s", dir)
+
+		f, err := vfs.Create(file)
+		test.RequireNoError(t, err, "Create %s", file)
+
+		defer f.Close()
+
+		_, err = f.WriteString("before")
+		test.RequireNoError(t, err, "WriteString %s", file)
+
+		test.RequireNoError(t, vfs.Rename(file, vfs.Join(dir, "newFile")), "Rename %s", file)
+		test.RequireNoError(t, vfs.Rename(dir, newDir), "Rename %s", dir)
+
+		_, err = f.WriteString(" after")
+		test.RequireNoError(t, err, "WriteString %s", file)
+
+		test.RequireNoError(t, f.Chmod(0o600), "Chmod %s", file)
+
+		rf, err := vfs.Create(removed)
+		test.RequireNoError(t, err, "Create %s", removed)
+
+		defer rf.Close()
+
+		test.RequireNoError(t, vfs.Remove(removed), "Remove %s", removed)
+
+		_, err = rf.WriteString("unlinked")
+		test.RequireNoError(t, err, "WriteString %s", removed)
+
+		replayFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+
+		err = memfs.Replay(replayFS, ringJournal.Entries())
+		test.RequireNoError(t, err, "Replay")
+
+		data, err := replayFS.ReadFile(newFile)
+		test.RequireNoError(t, err, "ReadFile %s", newFile)
+
+		if string(data) != "before after" {
+			t.Errorf("ReadFile %s : want data to be %q, got %q", newFile, "before after", data)
+		}
+
+		info, err := replayFS.Stat(newFile)
+		test.RequireNoError(t, err, "Stat %s", newFile)
+
+		if info.Mode() != 0o600 {
+			t.Errorf("Stat %s : want mode to be %s, got %s", newFile, fs.FileMode(0o600), info.Mode())
+		}
+
+		for _, path := range []string{dir, file, removed} {
+			_, err = replayFS.Stat(path)
+			test.AssertPathError(t, err).Op("stat").Path(path).Err(avfs.ErrNoSuchFileOrDir).Test()
+		}
+	})
+
+	t.Run("JournalGraftChunked", func(t *testing.T) {
+		ringJournal := memfs.NewRingJournal(10)
+		vfs := memfs.NewWithOptions(&memfs.Options{Journal: ringJournal})
+		src := memfs.New()
+		dir := vfs.Join(vfs.TempDir(), "graft")
+		chunked := vfs.Join(vfs.TempDir(), "chunked")
+
+		test.RequireNoError(t, vfs.Graft(dir, src, src.TempDir()), "Graft")
+
+		err := vfs.CreateChunked(chunked, avfs.DefaultFilePerm,
+			[]memfs.Extent{{Reader: strings.NewReader("data"), Size: 4}})
+		test.RequireNoError(t, err, "CreateChunked")
+
+		wantOps := []string{memfs.JournalGraft, memfs.JournalOpen, memfs.JournalChunked}
+		entries := ringJournal.Entries()
+
+		if len(entries) != len(wantOps) {
+			t.Fatalf("Entries : want %d entries, got %d", len(wantOps), len(entries))
+		}
+
+		for i, e := range entries {
+			if e.Op != wantOps[i] {
+				t.Errorf("Entries %d : want op %s, got %s", i, wantOps[i], e.Op)
+			}
+		}
+
+		for _, e := range []memfs.JournalEntry{entries[0], entries[2]} {
+			err = memfs.Replay(memfs.New(), []memfs.JournalEntry{e})
+			if err == nil {
+				t.Errorf("Replay %s : want an error, got nil", e.Op)
+			}
+		}
+	})
+}
+
+func TestMemFSMknod(t *testing.T) {
+	ringJournal := memfs.NewRingJournal(10)
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, Journal: ringJournal})
+	fifo := vfs.Join(vfs.TempDir(), "fifo")
+	device := vfs.Join(vfs.TempDir(), "null")
+
+	err := vfs.Mknod(fifo, fs.ModeNamedPipe|0o600, 0)
+	test.RequireNoError(t, err, "Mknod %s", fifo)
+
+	err = vfs.Mknod(device, fs.ModeDevice|fs.ModeCharDevice|0o666, 0x103)
+	test.RequireNoError(t, err, "Mknod %s", device)
+
+	t.Run("MknodOpen", func(t *testing.T) {
+		_, err = vfs.Open(fifo)
+		test.AssertPathError(t, err).Op("open").Path(fifo).Err(avfs.ErrNoSuchDevice).Test()
+
+		err = vfs.Truncate(device, 0)
+		test.AssertPathError(t, err).Op("truncate").Path(device).Err(avfs.ErrInvalidArgument).Test()
+	})
+
+	t.Run("MknodLinkRename", func(t *testing.T) {
+		link := vfs.Join(vfs.TempDir(), "link")
+
+		err = vfs.Link(device, link)
+		test.RequireNoError(t, err, "Link %s %s", device, link)
+
+		newFifo := vfs.Join(vfs.TempDir(), "newFifo")
+
+		err = vfs.Rename(fifo, newFifo)
+		test.RequireNoError(t, err, "Rename %s %s", fifo, newFifo)
+
+		info, err := vfs.Lstat(link)
+		test.RequireNoError(t, err, "Lstat %s", link)
+
+		if info.Mode().Type() != fs.ModeDevice|fs.ModeCharDevice || vfs.ToSysStat(info).Nlink() != 2 {
+			t.Errorf("Lstat %s : want a character device with 2 links, got %s with %d links",
+				link, info.Mode(), vfs.ToSysStat(info).Nlink())
+		}
+
+		if r := vfs.Check(); !r.OK() {
+			t.Errorf("Check : want no problem, got\n%s", r)
+		}
+	})
+
+	t.Run("MknodReplay", func(t *testing.T) {
+		replayFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+
+		err = memfs.Replay(replayFS, ringJournal.Entries())
+		test.RequireNoError(t, err, "Replay")
+
+		info, err := replayFS.Lstat(device)
+		test.RequireNoError(t, err, "Lstat %s", device)
+
+		wantMode := fs.ModeDevice | fs.ModeCharDevice | 0o644
+		rdev := info.(*memfs.MemInfo).Rdev()
+
+		if info.Mode() != wantMode || rdev != 0x103 {
+			t.Errorf("Lstat %s : want mode %s and device number %d, got %s and %d",
+				device, wantMode, 0x103, info.Mode(), rdev)
+		}
+	})
+}
+
+func TestMemFSOpenAudit(t *testing.T) {
+	audit := &memfs.OpenAudit{}
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, OpenAudit: audit})
+	data := vfs.Join(vfs.TempDir(), "data")
+	file := vfs.Join(data, "file")
+	other := vfs.Join(vfs.TempDir(), "other")
+
+	test.RequireNoError(t, vfs.MkdirAll(data, avfs.DefaultDirPerm), "MkdirAll %s", data)
+	test.RequireNoError(t, vfs.WriteFile(file, []byte("data"), 0o600), "WriteFile %s", file)
+	test.RequireNoError(t, vfs.WriteFile(other, nil, avfs.DefaultFilePerm), "WriteFile %s", other)
+
+	_, err := vfs.ReadFile(file)
+	test.RequireNoError(t, err, "ReadFile %s", file)
+
+	_, err = vfs.Open(vfs.Join(data, "nonExisting"))
+	if err == nil {
+		t.Fatalf("Open : want error, got nil")
+	}
+
+	records := audit.Records()
+	if len(records) != 4 {
+		t.Fatalf("Records : want 4 records, got %d : %v", len(records), records)
+	}
+
+	want := memfs.OpenRecord{Path: file, Flag: os.O_WRONLY | os.O_CREATE | os.O_TRUNC, Perm: 0o600}
+	if records[0] != want {
+		t.Errorf("Records : want first record to be %v, got %v", want, records[0])
+	}
+
+	if records[3].Err == nil {
+		t.Errorf("Records : want an error for the last record")
+	}
+
+	truncated := audit.Opened(data, os.O_TRUNC)
+	if len(truncated) != 1 || truncated[0].Path != file {
+		t.Errorf("Opened %s : want only %s opened with O_TRUNC, got %v", data, file, truncated)
+	}
+
+	if got := audit.Opened(data, 0); len(got) != 3 {
+		t.Errorf("Opened %s : want 3 records, got %v", data, got)
+	}
+
+	link := vfs.Join(vfs.TempDir(), "link")
+	test.RequireNoError(t, vfs.Symlink(data, link), "Symlink %s %s", data, link)
+
+	_, err = vfs.ReadFile(vfs.Join(link, "file"))
+	test.RequireNoError(t, err, "ReadFile %s", link)
+
+	if got := audit.Opened(data, 0); len(got) != 4 || got[3].Path != file {
+		t.Errorf("Opened %s : want the file read through %s to be recorded as %s, got %v", data, link, file, got)
+	}
+
+	audit.Reset()
+
+	if got := audit.Records(); len(got) != 0 {
+		t.Errorf("Reset : want no records, got %v", got)
+	}
+}
+
+func TestMemFSMaxOpenFiles(t *testing.T) {
+	for _, osType := range []avfs.OSType{avfs.OsLinux, avfs.OsWindows} {
+		t.Run(osType.String(), func(t *testing.T) {
+			vfs := memfs.NewWithOptions(&memfs.Options{OSType: osType, MaxOpenFiles: 2})
+			if !test.CanEmulate(vfs, osType) {
+				t.Skipf("MemFS can't emulate %s, use build tag 'avfs_setostype'", osType)
+			}
+
+			tmpDir := vfs.TempDir()
+			file := vfs.Join(tmpDir, "file")
+
+			test.RequireNoError(t, vfs.MkdirAll(tmpDir, avfs.DefaultDirPerm), "MkdirAll %s", tmpDir)
+			test.RequireNoError(t, vfs.WriteFile(file, nil, avfs.DefaultFilePerm), "WriteFile %s", file)
+
+			wantErr := error(avfs.ErrTooManyOpenFiles)
+			if vfs.OSType() == avfs.OsWindows {
+				wantErr = avfs.ErrWinTooManyOpenFiles
+			}
+
+			if vfs.MaxOpenFiles() != 2 || vfs.OpenFiles() != 0 {
+				t.Fatalf("MaxOpenFiles : want 0/2 open files, got %d/%d", vfs.OpenFiles(), vfs.MaxOpenFiles())
+			}
+
+			f1, err := vfs.Open(file)
+			test.RequireNoError(t, err, "Open %s", file)
+
+			_, err = vfs.Open(vfs.Join(vfs.TempDir(), "nonExisting"))
+			if err == nil {
+				t.Fatalf("Open : want error, got nil")
+			}
+
+			f2, err := vfs.Create(file)
+			test.RequireNoError(t, err, "Create %s", file)
+
+			if vfs.OpenFiles() != 2 {
+				t.Errorf("OpenFiles : want 2 open files, got %d", vfs.OpenFiles())
+			}
+
+			_, err = vfs.Open(file)
+			test.AssertPathError(t, err).Op("open").Path(file).Err(wantErr).Test()
+
+			_, err = vfs.ReadFile(file)
+			test.AssertPathError(t, err).Op("open").Path(file).Err(wantErr).Test()
+
+			test.RequireNoError(t, f1.Close(), "Close %s", file)
+
+			if f1.Close() == nil {
+				t.Errorf("Close : want error for a closed file, got nil")
+			}
+
+			if vfs.OpenFiles() != 1 {
+				t.Errorf("OpenFiles : want 1 open file, got %d", vfs.OpenFiles())
+			}
+
+			f3, err := vfs.Open(file)
+			test.RequireNoError(t, err, "Open %s", file)
+
+			vfs.SetMaxOpenFiles(0)
+
+			f4, err := vfs.Open(file)
+			test.RequireNoError(t, err, "Open %s", file)
+
+			for _, f := range []avfs.File{f2, f3, f4} {
+				test.RequireNoError(t, f.Close(), "Close %s", file)
+			}
+
+			if vfs.OpenFiles() != 0 || vfs.MaxOpenFiles() != 0 {
+				t.Errorf("OpenFiles : want 0/0 open files, got %d/%d", vfs.OpenFiles(), vfs.MaxOpenFiles())
+			}
+		})
+	}
+}
+
+func TestMemFSFileChdir(t *testing.T) {
+	test.RunOSMatrix(t, func(t *testing.T, vfs avfs.VFS) {
+		tmpDir := vfs.TempDir()
+		dir := vfs.Join(tmpDir, "dir")
+
+		test.RequireNoError(t, vfs.MkdirAll(dir, avfs.DefaultDirPerm), "MkdirAll %s", dir)
+		test.RequireNoError(t, vfs.Chdir(tmpDir), "Chdir %s", tmpDir)
+
+		f, err := vfs.Open("dir")
+		test.RequireNoError(t, err, "Open %s", "dir")
+
+		defer f.Close()
+
+		test.RequireNoError(t, vfs.Chdir(dir), "Chdir %s", dir)
+		test.RequireNoError(t, f.Chdir(), "Chdir %s", "dir")
+
+		curDir, err := vfs.Getwd()
+		test.RequireNoError(t, err, "Getwd")
+
+		if curDir != dir {
+			t.Errorf("Getwd : want current directory to be %s, got %s", dir, curDir)
+		}
+	})
+}
+
+func TestMemFSTrackChanges(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, TrackChanges: true})
+	etc := vfs.Join(vfs.TempDir(), "etc")
+	passwd := vfs.Join(etc, "passwd")
+	varDir := vfs.Join(vfs.TempDir(), "var")
+	log := vfs.Join(varDir, "log")
+
+	test.RequireNoError(t, vfs.MkdirAll(etc, avfs.DefaultDirPerm), "MkdirAll %s", etc)
+	test.RequireNoError(t, vfs.MkdirAll(varDir, avfs.DefaultDirPerm), "MkdirAll %s", varDir)
+	test.RequireNoError(t, vfs.WriteFile(passwd, []byte("root"), avfs.DefaultFilePerm), "WriteFile %s", passwd)
+
+	mark := vfs.Mark()
+
+	test.RequireNoError(t, vfs.WriteFile(log, []byte("log"), avfs.DefaultFilePerm), "WriteFile %s", log)
+
+	// A failed operation is not a change.
+	_ = vfs.Remove(vfs.Join(etc, "nonExisting"))
+
+	if vfs.HasChangedSince(mark, etc) {
+		t.Errorf("HasChangedSince %s : want no change", etc)
+	}
+
+	if !vfs.HasChangedSince(mark, varDir) {
+		t.Errorf("HasChangedSince %s : want a change", varDir)
+	}
+
+	if v := vfs.SubtreeVersion(varDir); v != vfs.Mark() {
+		t.Errorf("SubtreeVersion %s : want version to be %d, got %d", varDir, vfs.Mark(), v)
+	}
+
+	if got, want := vfs.ChangedSince(mark), []string{log}; !slices.Equal(got, want) {
+		t.Errorf("ChangedSince : want paths to be %v, got %v", want, got)
+	}
+
+	t.Run("ParentRemoved", func(t *testing.T) {
+		mark := vfs.Mark()
+
+		test.RequireNoError(t, vfs.RemoveAll(etc), "RemoveAll %s", etc)
+
+		if !vfs.HasChangedSince(mark, passwd) {
+			t.Errorf("HasChangedSince %s : want a change", passwd)
+		}
+
+		if vfs.HasChangedSince(mark, varDir) {
+			t.Errorf("HasChangedSince %s : want no change", varDir)
+		}
+	})
+
+	t.Run("Aliases", func(t *testing.T) {
+		data := vfs.Join(vfs.TempDir(), "data")
+		file := vfs.Join(data, "file")
+		link := vfs.Join(vfs.TempDir(), "link")
+		hardLink := vfs.Join(varDir, "hardLink")
+
+		test.RequireNoError(t, vfs.MkdirAll(data, avfs.DefaultDirPerm), "MkdirAll %s", data)
+		test.RequireNoError(t, vfs.WriteFile(file, nil, avfs.DefaultFilePerm), "WriteFile %s", file)
+		test.RequireNoError(t, vfs.Symlink(data, link), "Symlink %s", link)
+		test.RequireNoError(t, vfs.Link(file, hardLink), "Link %s", hardLink)
+
+		mark := vfs.Mark()
+		aliasFile := vfs.Join(link, "file")
+
+		test.RequireNoError(t, vfs.Chmod(aliasFile, 0o600), "Chmod %s", aliasFile)
+
+		if !vfs.HasChangedSince(mark, data) || !vfs.HasChangedSince(mark, link) {
+			t.Errorf("HasChangedSince %s : want a change made through the symbolic link %s", data, link)
+		}
+
+		mark = vfs.Mark()
+
+		test.RequireNoError(t, vfs.WriteFile(hardLink, []byte("data"), avfs.DefaultFilePerm), "WriteFile %s", hardLink)
+
+		if !vfs.HasChangedSince(mark, data) {
+			t.Errorf("HasChangedSince %s : want a change made through the hard link %s", data, hardLink)
+		}
+
+		if got, want := vfs.ChangedSince(mark), []string{file, hardLink}; !slices.Equal(got, want) {
+			t.Errorf("ChangedSince : want paths to be %v, got %v", want, got)
+		}
+
+		mark = vfs.Mark()
+		moved := vfs.Join(vfs.TempDir(), "moved")
+
+		test.RequireNoError(t, vfs.Rename(data, moved), "Rename %s %s", data, moved)
+
+		if movedFile := vfs.Join(moved, "file"); !vfs.HasChangedSince(mark, movedFile) {
+			t.Errorf("HasChangedSince %s : want the renaming of its parent to be a change", movedFile)
+		}
+
+		if vfs.HasChangedSince(mark, varDir) {
+			t.Errorf("HasChangedSince %s : want no change", varDir)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		vfs := memfs.New()
+
+		if vfs.Mark() != 0 || !vfs.HasChangedSince(0, vfs.TempDir()) || vfs.ChangedSince(0) != nil {
+			t.Errorf("Mark : want changes not to be tracked")
+		}
+	})
+}
+
+func TestMemFSSubJournal(t *testing.T) {
+	ringJournal := memfs.NewRingJournal(10)
+	audit := &memfs.OpenAudit{}
+	vfs := memfs.NewWithOptions(&memfs.Options{
+		OSType: avfs.OsLinux, Journal: ringJournal, TrackChanges: true, OpenAudit: audit,
+	})
+
+	dir := vfs.Join(vfs.TempDir(), "sub")
+	other := vfs.Join(vfs.TempDir(), "other")
+
+	test.RequireNoError(t, vfs.MkdirAll(dir, avfs.DefaultDirPerm), "MkdirAll %s", dir)
+
+	sub, err := vfs.Sub(dir)
+	test.RequireNoError(t, err, "Sub %s", dir)
+
+	subFS := sub.(*memfs.MemFS)
+	mark := vfs.Mark()
+
+	f, err := subFS.Create("/file")
+	test.RequireNoError(t, err, "Create %s", "/file")
+
+	_, err = f.Write([]byte("data"))
+	test.RequireNoError(t, err, "Write")
+	test.RequireNoError(t, f.Close(), "Close")
+
+	test.RequireNoError(t, vfs.WriteFile(other, nil, avfs.DefaultFilePerm), "WriteFile %s", other)
+
+	file := vfs.Join(dir, "file")
+
+	entries := ringJournal.Entries()
+	if len(entries) < 3 || entries[1].Path != file || entries[2].Path != file {
+		t.Errorf("Entries : want the paths of the sub file system to be %s, got %v", file, entries)
+	}
+
+	if records := audit.Records(); len(records) != 2 || records[0].Path != file {
+		t.Errorf("Records : want the path of the sub file system to be %s, got %v", file, records)
+	}
+
+	if !vfs.HasChangedSince(mark, file) {
+		t.Errorf("HasChangedSince %s : want a change", file)
+	}
+
+	if !subFS.HasChangedSince(mark, "/file") {
+		t.Errorf("HasChangedSince %s : want a change", "/file")
+	}
+
+	if got, want := subFS.ChangedSince(mark), []string{"/file"}; !slices.Equal(got, want) {
+		t.Errorf("ChangedSince : want paths to be %v, got %v", want, got)
+	}
+
+	if got, want := vfs.ChangedSince(mark), []string{other, file}; !slices.Equal(got, want) {
+		t.Errorf("ChangedSince : want paths to be %v, got %v", want, got)
+	}
+}
+
+// checkMemFS reports the violated invariants of a memory file system.
+func checkMemFS(t *testing.T, vfs *memfs.MemFS) {
+	t.Helper()
+
+	if report := vfs.Check(); !report.OK() {
+		t.Errorf("Check : want no problem, got\n%s", report)
+	}
+}
+
+func TestRunOSMatrix(t *testing.T) {
+	var osTypes []avfs.OSType
+
+	test.RunOSMatrixWithOsFS(t, func(t *testing.T, vfs avfs.VFS) {
+		osTypes = append(osTypes, vfs.OSType())
+
+		wantSep := uint8('/')
+		if vfs.OSType() == avfs.OsWindows {
+			wantSep = '\\'
+		}
+
+		if sep := vfs.PathSeparator(); sep != wantSep {
+			t.Errorf("PathSeparator : want %c, got %c", wantSep, sep)
+		}
+
+		path := vfs.Join(test.TempRoot(t, vfs), "file")
+
+		err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+	})
+
+	if len(osTypes) == 0 || osTypes[0] != avfs.OsLinux && osTypes[0] != avfs.OsWindows {
+		t.Errorf("RunOSMatrix : want the test to run at least on MemFS, got %v", osTypes)
+	}
+}
+
+func TestMemFSCreateChunked(t *testing.T) {
+	vfs := memfs.New()
+	path := vfs.Join(vfs.TempDir(), "chunked")
+	archive := strings.NewReader("header|first|middle|last|trailer")
+
+	extents := []memfs.Extent{
+		{Reader: archive, Offset: 7, Size: 6},
+		{Reader: strings.NewReader(""), Size: 0},
+		{Reader: archive, Offset: 13, Size: 7},
+		{Reader: strings.NewReader("last"), Size: 4},
+	}
+
+	err := vfs.CreateChunked(path, avfs.DefaultFilePerm, extents)
+	test.RequireNoError(t, err, "CreateChunked %s", path)
+
+	content := "first|middle|last"
+
+	t.Run("Read", func(t *testing.T) {
+		data, err := vfs.ReadFile(path)
+		test.RequireNoError(t, err, "ReadFile %s", path)
+
+		if string(data) != content {
+			t.Errorf("ReadFile : want data to be %q, got %q", content, data)
+		}
+
+		data, release, err := vfs.ReadFileShared(path)
+		test.RequireNoError(t, err, "ReadFileShared %s", path)
+
+		release()
+
+		if string(data) != content {
+			t.Errorf("ReadFileShared : want data to be %q, got %q", content, data)
+		}
+
+		info, err := vfs.Stat(path)
+		test.RequireNoError(t, err, "Stat %s", path)
+
+		if info.Size() != int64(len(content)) {
+			t.Errorf("Stat : want size to be %d, got %d", len(content), info.Size())
+		}
+	})
+
+	t.Run("ReadAt", func(t *testing.T) {
+		f, err := vfs.Open(path)
+		test.RequireNoError(t, err, "Open %s", path)
+
+		defer f.Close()
+
+		for off := 0; off <= len(content); off++ {
+			b := make([]byte, 8)
+
+			n, err := f.ReadAt(b, int64(off))
+			want := content[off:min(off+len(b), len(content))]
+
+			if string(b[:n]) != want {
+				t.Errorf("ReadAt %d : want data to be %q, got %q", off, want, b[:n])
+			}
+
+			if wantEOF := len(want) < len(b); (err == io.EOF) != wantEOF {
+				t.Errorf("ReadAt %d : want io.EOF to be %t, got %v", off, wantEOF, err)
+			}
+		}
+
+		size, err := f.Seek(0, io.SeekEnd)
+		test.RequireNoError(t, err, "Seek %s", path)
+
+		if size != int64(len(content)) {
+			t.Errorf("Seek : want offset to be %d, got %d", len(content), size)
+		}
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		_, err = vfs.OpenFile(path, os.O_WRONLY, 0)
+		test.AssertPathError(t, err).Op("open").Path(path).
+			OSType(avfs.OsLinux).Err(avfs.ErrPermDenied).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinAccessDenied).Test()
+	})
+
+	t.Run("ShortReader", func(t *testing.T) {
+		shortPath := vfs.Join(vfs.TempDir(), "short")
+
+		err = vfs.CreateChunked(shortPath, avfs.DefaultFilePerm, []memfs.Extent{{Reader: strings.NewReader("short"), Size: 10}})
+		test.RequireNoError(t, err, "CreateChunked %s", shortPath)
+
+		_, err = vfs.ReadFile(shortPath)
+		test.AssertPathError(t, err).Op("read").Path(shortPath).Err(io.ErrUnexpectedEOF).Test()
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		invalidPath := vfs.Join(vfs.TempDir(), "invalid")
+
+		err = vfs.CreateChunked(invalidPath, avfs.DefaultFilePerm, []memfs.Extent{{Size: 1}})
+		test.AssertPathError(t, err).Op("open").Path(invalidPath).
+			OSType(avfs.OsLinux).Err(avfs.ErrInvalidArgument).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinNotSupported).Test()
+
+		existingFile := vfs.Join(vfs.TempDir(), "existing")
+
+		err = vfs.WriteFile(existingFile, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", existingFile)
+
+		err = vfs.CreateChunked(existingFile, avfs.DefaultFilePerm, nil)
+		test.AssertPathError(t, err).Op("open").Path(existingFile).
+			OSType(avfs.OsLinux).Err(avfs.ErrFileExists).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileExists).Test()
+	})
+}
+
+func TestCorpus(t *testing.T) {
+	wantCounts := map[string][3]int{
+		test.CorpusSmall:  {16, 128, 8},
+		test.CorpusMedium: {128, 2048, 64},
+		test.CorpusLarge:  {512, 8192, 256},
+	}
+
+	for _, name := range test.CorpusNames() {
+		c, err := test.LoadCorpus(name)
+		test.RequireNoError(t, err, "LoadCorpus %s", name)
+
+		want := wantCounts[name]
+		if got := [3]int{len(c.Dirs), len(c.Files), len(c.SymLinks)}; got != want {
+			t.Errorf("LoadCorpus %s : want %v directories, files and symbolic links, got %v", name, want, got)
+		}
+	}
+
+	c, err := test.LoadCorpus(test.CorpusSmall)
+	test.RequireNoError(t, err, "LoadCorpus %s", test.CorpusSmall)
+
+	vfs := memfs.New()
+	baseDir := vfs.Join(vfs.TempDir(), "corpus")
+
+	err = c.Create(vfs, baseDir)
+	test.RequireNoError(t, err, "Create %s", baseDir)
+
+	var size int64
+
+	for _, file := range c.Files {
+		path := vfs.Join(baseDir, file.Name)
+
+		info, err := vfs.Stat(path)
+		test.RequireNoError(t, err, "Stat %s", path)
+
+		size += info.Size()
+	}
+
+	if size != c.Size() {
+		t.Errorf("Create : want total size to be %d, got %d", c.Size(), size)
+	}
+
+	_, err = test.ParseCorpus("invalid", strings.NewReader("d /dir\nf /dir/file -1\n"))
+	if err == nil {
+		t.Errorf("ParseCorpus : want error for a negative size, got nil")
+	}
+}
+
+func TestCreateUserWithHome(t *testing.T) {
+	vfs := memfs.New()
+	adminUser := vfs.User()
+
+	const userName, groupName = "UsrHome", "GrpHome"
+
+	var homeDir string
+
+	t.Run("CreateUserWithHome", func(t *testing.T) {
+		view, u := test.CreateUserWithHome(t, vfs, userName, groupName)
+
+		if view.User().Name() != userName {
+			t.Errorf("CreateUserWithHome : want view user to be %s, got %s", userName, view.User().Name())
+		}
+
+		if vfs.User() != adminUser {
+			t.Errorf("CreateUserWithHome : want vfs user to be %s, got %s", adminUser.Name(), vfs.User().Name())
+		}
+
+		homeDir = avfs.HomeDirUser(vfs, "", u)
+
+		info, err := vfs.Stat(homeDir)
+		test.RequireNoError(t, err, "Stat %s", homeDir)
+
+		if sst := vfs.ToSysStat(info); sst.Uid() != u.Uid() || sst.Gid() != u.Gid() {
+			t.Errorf("Stat %s : want owner to be %d:%d, got %d:%d", homeDir, u.Uid(), u.Gid(), sst.Uid(), sst.Gid())
+		}
+
+		path := view.Join(homeDir, "file")
+
+		err = view.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		if vfs.OSType() == avfs.OsWindows {
+			return
+		}
+
+		rootHome := avfs.HomeDirUser(vfs, "", adminUser)
+		path = view.Join(rootHome, "file")
+
+		err = view.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.AssertPathError(t, err).Op("open").Path(path).Err(avfs.ErrPermDenied).Test()
+	})
+
+	if _, err := vfs.Idm().LookupUser(userName); err != avfs.UnknownUserError(userName) {
+		t.Errorf("LookupUser %s : want error to be %v, got %v", userName, avfs.UnknownUserError(userName), err)
+	}
+
+	if _, err := vfs.Idm().LookupGroup(groupName); err != avfs.UnknownGroupError(groupName) {
+		t.Errorf("LookupGroup %s : want error to be %v, got %v", groupName, avfs.UnknownGroupError(groupName), err)
+	}
+
+	if _, err := vfs.Stat(homeDir); err == nil {
+		t.Errorf("Stat %s : want home directory to be removed, got nil", homeDir)
+	}
+}
+
+func TestMemFSEtcFiles(t *testing.T) {
+	idm := memidm.NewWithOptions(&memidm.Options{OSType: avfs.OsLinux})
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, Idm: idm, EtcFiles: true})
+
+	const userName, groupName = "user", "group"
+
+	_, err := idm.AddGroup(groupName)
+	test.RequireNoError(t, err, "AddGroup %s", groupName)
+
+	u, err := idm.AddUser(userName, groupName)
+	test.RequireNoError(t, err, "AddUser %s", userName)
+
+	passwd, err := vfs.ReadFile("/etc/passwd")
+	test.RequireNoError(t, err, "ReadFile %s", "/etc/passwd")
+
+	wantPasswd := fmt.Sprintf("root:x:0:0:root:/root:/bin/sh\nuser:x:%d:%d:user:/home/user:/bin/sh\n", u.Uid(), u.Gid())
+	if string(passwd) != wantPasswd {
+		t.Errorf("ReadFile : want passwd to be\n%s, got\n%s", wantPasswd, passwd)
+	}
+
+	group, err := vfs.ReadFile("/etc/group")
+	test.RequireNoError(t, err, "ReadFile %s", "/etc/group")
+
+	wantGroup := fmt.Sprintf("root:x:0:\ngroup:x:%d:\n", u.Gid())
+	if string(group) != wantGroup {
+		t.Errorf("ReadFile : want group to be\n%s, got\n%s", wantGroup, group)
+	}
+
+	_, err = idm.AddGroupMember(userName, "root")
+	test.RequireNoError(t, err, "AddGroupMember %s", userName)
+
+	group, err = vfs.ReadFile("/etc/group")
+	test.RequireNoError(t, err, "ReadFile %s", "/etc/group")
+
+	wantGroup = fmt.Sprintf("root:x:0:user\ngroup:x:%d:\n", u.Gid())
+	if string(group) != wantGroup {
+		t.Errorf("ReadFile : want group to be\n%s, got\n%s", wantGroup, group)
+	}
+
+	err = vfs.WriteFile("/etc/passwd", nil, 0o644)
+	test.AssertPathError(t, err).Op("open").Path("/etc/passwd").ErrPermDenied().Test()
+
+	err = idm.DelUser(userName)
+	test.RequireNoError(t, err, "DelUser %s", userName)
+
+	passwd, err = vfs.ReadFile("/etc/passwd")
+	test.RequireNoError(t, err, "ReadFile %s", "/etc/passwd")
+
+	if want := "root:x:0:0:root:/root:/bin/sh\n"; string(passwd) != want {
+		t.Errorf("ReadFile : want passwd to be\n%s, got\n%s", want, passwd)
+	}
+
+	vfs = memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, Idm: idm})
+
+	_, err = vfs.Stat("/etc/passwd")
+	test.AssertPathError(t, err).OpStat().Err(avfs.ErrNoSuchFileOrDir).Test()
+}
+
+func TestMemFSSnapshot(t *testing.T) {
+	vfs := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux, TrackChanges: true})
+
+	dir := "/fixture"
+	file := vfs.Join(dir, "file")
+	hardLink := vfs.Join(dir, "hardLink")
+	symlink := vfs.Join(dir, "symlink")
+	wantData := []byte("fixture data")
+
+	err := vfs.MkdirAll(dir, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", dir)
+
+	err = vfs.WriteFile(file, wantData, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", file)
+
+	err = vfs.Chown(file, 42, 43)
+	test.RequireNoError(t, err, "Chown %s", file)
+
+	err = vfs.Link(file, hardLink)
+	test.RequireNoError(t, err, "Link %s %s", file, hardLink)
+
+	err = vfs.Symlink(file, symlink)
+	test.RequireNoError(t, err, "Symlink %s %s", file, symlink)
+
+	wantInfo, err := vfs.Stat(file)
+	test.RequireNoError(t, err, "Stat %s", file)
+
+	s := vfs.Snapshot()
+
+	t.Run("SnapshotRestore", func(t *testing.T) {
+		for range 2 {
+			mark := vfs.Mark()
+
+			err = vfs.WriteFile(hardLink, []byte("modified"), avfs.DefaultFilePerm)
+			test.RequireNoError(t, err, "WriteFile %s", hardLink)
+
+			err = vfs.Remove(symlink)
+			test.RequireNoError(t, err, "Remove %s", symlink)
+
+			newFile := vfs.Join(dir, "newFile")
+
+			err = vfs.WriteFile(newFile, nil, avfs.DefaultFilePerm)
+			test.RequireNoError(t, err, "WriteFile %s", newFile)
+
+			err = vfs.Restore(s)
+			test.RequireNoError(t, err, "Restore")
+
+			if !vfs.HasChangedSince(mark, "/") {
+				t.Errorf("HasChangedSince : want the restored file system to be changed")
+			}
+
+			data, err := vfs.ReadFile(symlink)
+			test.RequireNoError(t, err, "ReadFile %s", symlink)
+
+			if !bytes.Equal(data, wantData) {
+				t.Errorf("ReadFile %s : want data to be %s, got %s", symlink, wantData, data)
+			}
+
+			info, err := vfs.Stat(file)
+			test.RequireNoError(t, err, "Stat %s", file)
+
+			if !vfs.SameFile(info, wantInfo) || info.Mode() != wantInfo.Mode() || !info.ModTime().Equal(wantInfo.ModTime()) {
+				t.Errorf("Stat %s : want info to be %v, got %v", file, wantInfo, info)
+			}
+
+			if sst := vfs.ToSysStat(info); sst.Uid() != 42 || sst.Gid() != 43 || sst.Nlink() != 2 {
+				t.Errorf("Stat %s : want uid, gid and nlink to be 42, 43, 2, got %d, %d, %d",
+					file, sst.Uid(), sst.Gid(), sst.Nlink())
+			}
+
+			_, err = vfs.Stat(newFile)
+			test.AssertPathError(t, err).OpStat().Err(avfs.ErrNoSuchFileOrDir).Test()
+
+			if r := vfs.Check(); !r.OK() {
+				t.Errorf("Check : want no problem, got\n%s", r)
+			}
+		}
+	})
+
+	t.Run("SnapshotHardLink", func(t *testing.T) {
+		err = vfs.WriteFile(hardLink, []byte("modified"), avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", hardLink)
+
+		data, err := vfs.ReadFile(file)
+		test.RequireNoError(t, err, "ReadFile %s", file)
+
+		if string(data) != "modified" {
+			t.Errorf("ReadFile %s : want the restored hard links to share their content, got %s", file, data)
+		}
+	})
+
+	t.Run("SnapshotOtherFS", func(t *testing.T) {
+		otherFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsLinux})
+
+		err = otherFS.Restore(s)
+		test.RequireNoError(t, err, "Restore")
+
+		data, err := otherFS.ReadFile(file)
+		test.RequireNoError(t, err, "ReadFile %s", file)
+
+		if !bytes.Equal(data, wantData) {
+			t.Errorf("ReadFile %s : want data to be %s, got %s", file, wantData, data)
+		}
+
+		// The files created after the restoration must not have the id of a restored file.
+		for _, name := range []string{dir, file, symlink} {
+			restoredInfo, err := otherFS.Lstat(name)
+			test.RequireNoError(t, err, "Lstat %s", name)
+
+			for i := range 20 {
+				newFile := otherFS.Join("/", fmt.Sprintf("new%s%d", otherFS.Base(name), i))
+
+				err = otherFS.WriteFile(newFile, nil, avfs.DefaultFilePerm)
+				test.RequireNoError(t, err, "WriteFile %s", newFile)
+
+				info, err := otherFS.Stat(newFile)
+				test.RequireNoError(t, err, "Stat %s", newFile)
+
+				if otherFS.SameFile(info, restoredInfo) {
+					t.Fatalf("SameFile %s %s : want new file to have a new id", newFile, name)
+				}
+			}
+		}
+
+		foldedFS := memfs.NewWithOptions(&memfs.Options{CaseInsensitive: true})
+
+		err = foldedFS.Restore(s)
+		test.AssertPathError(t, err).Op("restore").Err(avfs.ErrInvalidArgument).Test()
+
+		winFS := memfs.NewWithOptions(&memfs.Options{OSType: avfs.OsWindows})
+		if !test.CanEmulate(winFS, avfs.OsWindows) {
+			return
+		}
 
-	ts := test.NewSuiteFS(b, vfs, vfs)
-	ts.BenchAll(b)
+		err = winFS.Restore(s)
+		test.AssertPathError(t, err).Op("restore").Err(avfs.ErrInvalidArgument).Test()
+	})
 }
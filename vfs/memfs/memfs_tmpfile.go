@@ -0,0 +1,63 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// openTmpFile opens an unnamed temporary file in the directory name, like open(2) with the O_TMPFILE flag (Linux only).
+// child and err are the node and the error returned by searchNode for name.
+// The file has no link, its content is released when it is closed.
+func (vfs *MemFS) openTmpFile(name string, child node, err error, om avfs.OpenMode, perm fs.FileMode) (avfs.File, error) {
+	const op = "open"
+
+	if vfs.OSType() != avfs.OsLinux {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.NotSupported}
+	}
+
+	if err != vfs.err.FileExists {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	dn, ok := child.(*dirNode)
+	if !ok {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+
+	if om&avfs.OpenWrite == 0 {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+	}
+
+	dn.mu.RLock()
+	defer dn.mu.RUnlock()
+
+	if !dn.checkPermission(avfs.OpenWrite|avfs.OpenLookup, vfs.User()) {
+		return (*MemFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	f := &MemFile{
+		nd:       vfs.newFile(dn, perm),
+		vfs:      vfs,
+		name:     name,
+		openMode: om,
+	}
+
+	return f, nil
+}
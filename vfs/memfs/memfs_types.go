@@ -17,16 +17,30 @@
 package memfs
 
 import (
+	"io"
 	"io/fs"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/avfs/avfs"
 )
 
 const (
-	// Maximum number of symlinks in a path.
-	slCountMax = 64
+	// Maximum number of symlinks followed to resolve a path (MAXSYMLINKS on Linux).
+	slCountMax = 40
+
+	// Maximum number of symlinks followed to resolve a path on Windows (maximum number of reparse points).
+	slCountMaxWin = 63
+
+	// Maximum age of the access time of a node before a read updates it with AtimeRelative.
+	relatimeMax = 24 * time.Hour
+
+	// Maximum number of pending events of a size watcher.
+	sizeEventsMax = 64
+
+	// Maximum number of pending events of a watcher.
+	watchEventsMax = 256
 )
 
 // MemIOFS implements a memory file system using the avfs.IOFS interface.
@@ -38,17 +52,33 @@ type MemIOFS struct {
 type MemFS struct {
 	rootNode        *dirNode    // rootNode represent the root directory of the file system.
 	err             avfs.Errors // err regroups errors depending on the OS emulated.
-	volumes         volumes     // volumes contains the volume names (for Windows only).
+	volumes         volumes     // volumes contains the volumes (for Windows only).
 	dirMode         fs.FileMode // dirMode is the default fs.FileMode for a directory.
 	fileMode        fs.FileMode // fileMode is de default fs.FileMode for a file.
 	lastId          *uint64     // lastId is the last unique id used to identify files uniquely.
 	name            string      // name is the name of the file system.
+	shortNames      bool        // shortNames generates and resolves DOS 8.3 short names (Windows only).
+	caseInsensitive bool        // caseInsensitive is true if the lookups of names are case-insensitive.
+	pathLimits      bool        // pathLimits is true if the lengths of the paths and of the names are limited.
+	atimeMode       AtimeMode   // atimeMode defines when the reads of the files update their access time.
+	devMode         bool        // devMode allows non administrators to create symbolic links (Windows only).
+	subDir          string      // subDir is the absolute path of the root directory in the root file system, empty if not a sub file system.
+	journal         *journal    // journal records the mutations of the file system, nil if disabled.
+	openAudit       *OpenAudit  // openAudit records the flags used to open files, nil if disabled.
+	openFiles       *openFiles  // openFiles counts the open files and limits their number, shared with the sub file systems.
+	locks           *fileLocks  // locks is the table of the advisory locks of the open files, shared with the sub file systems.
+	watchers        *watchers   // watchers are the watchers of the file system, shared with the clones and the sub file systems.
+	unsynced        *unsynced   // unsynced keeps the durable content of the files not synced, nil if disabled (see Crash).
 	avfs.CurDirFn               // CurDirFn provides current directory functions to a file system.
 	avfs.CurUserFn              // CurUserFn provides current user functions to a file system.
 	avfs.IdmFn                  // IdmFn provides identity manager functions to a file system.
 	avfs.UMaskFn                // UMaskFn provides UMask functions to file systems.
 	avfs.FeaturesFn             // FeaturesFn provides features functions to a file system or an identity manager.
 	avfs.OSTypeFn               // OSTypeFn provides OS type functions to a file system or an identity manager.
+	avfs.SampleFn               // SampleFn provides an operation sampling hook to a file system.
+	avfs.TempRandFn             // TempRandFn provides a source of random numbers for the names of temporary files.
+	avfs.ClockFn                // ClockFn provides a clock for the timestamps of the files.
+	*avfs.FreezeFn              // FreezeFn provides freeze functions to a file system, shared with the sub file systems.
 }
 
 // MemFile represents an open file descriptor.
@@ -56,69 +86,250 @@ type MemFile struct {
 	nd         node          // nd is node of the file.
 	vfs        *MemFS        // vfs is the memory file system of the file.
 	name       string        // name is the name of the file.
+	dirPath    string        // dirPath is the absolute path of a directory when it was opened (used by Chdir).
 	dirEntries []fs.DirEntry // dirEntries stores the file information returned by ReadDir function.
 	dirNames   []string      // dirNames stores the names of the file returned by Readdirnames function.
 	at         int64         // at is current position in the file used by Read and Write functions.
 	dirIndex   int           // dirIndex is the position of the current index for dirEntries ou dirNames slices.
 	mu         sync.RWMutex  // mu is the RWMutex used to access content of MemFile.
 	openMode   avfs.OpenMode // openMode defines the permissions to check for OpenFile and CheckPermission functions.
+	locked     atomic.Bool   // locked is true if the file holds an advisory lock.
 }
 
 // Options defines the initialization options of MemFS.
 type Options struct {
-	Idm        avfs.IdentityMgr // Idm is the identity manager of the file system.
-	User       avfs.UserReader  // User is the current user of the file system.
-	Name       string           // Name is the name of the file system.
-	OSType     avfs.OSType      // OSType defines the operating system type.
-	SystemDirs []avfs.DirInfo   // SystemDirs contains data to create system directories.
+	Idm             avfs.IdentityMgr // Idm is the identity manager of the file system.
+	User            avfs.UserReader  // User is the current user of the file system.
+	Name            string           // Name is the name of the file system.
+	OSType          avfs.OSType      // OSType defines the operating system type.
+	ShortNames      bool             // ShortNames generates and resolves DOS 8.3 short names of files (Windows only).
+	CaseInsensitive bool             // CaseInsensitive makes the lookups of names case-insensitive, the case of the names is preserved.
+	PathLimits      bool             // PathLimits limits the length of names to 255 bytes and of paths to PATH_MAX (MAX_PATH on Windows).
+	Strict          bool             // Strict returns an error for unsupported operations instead of silently succeeding.
+	SystemDirs      []avfs.DirInfo   // SystemDirs contains data to create system directories.
+	DevMode         bool             // DevMode allows non administrators to create symbolic links (Windows only).
+	Journal         Journal          // Journal records the mutations of the file system in the order they are applied, nil to disable journaling.
+	TrackChanges    bool             // TrackChanges records the changed paths (see MemFS.Mark and MemFS.HasChangedSince).
+	OpenAudit       *OpenAudit       // OpenAudit records the flags and permissions used to open files, nil to disable.
+	MaxOpenFiles    int              // MaxOpenFiles is the maximum number of open files (see MemFS.SetMaxOpenFiles), 0 for no limit.
+	EtcFiles        bool             // EtcFiles creates read only /etc/passwd and /etc/group files generated from the identity manager (not on Windows).
+	UnsyncedBuffers bool             // UnsyncedBuffers makes the writes to files durable only after File.Sync (see MemFS.Crash).
+	Clock           avfs.Clock       // Clock is the clock of the timestamps of the files, nil to use the system clock (see MemFS.SetClock).
+	Atime           AtimeMode        // Atime defines when the reads of the files update their access time, the default depends on the OS.
+}
+
+// AtimeMode defines when the reads of the files update their access time (see Options.Atime).
+type AtimeMode uint8
+
+const (
+	AtimeDefault  AtimeMode = iota // AtimeDefault is AtimeNever on Windows and AtimeRelative on the other OSes.
+	AtimeRelative                  // AtimeRelative updates the access time like the relatime mount option of Linux.
+	AtimeStrict                    // AtimeStrict updates the access time on every read like the strictatime mount option.
+	AtimeNever                     // AtimeNever never updates the access time like the noatime mount option.
+)
+
+// unsynced keeps the durable content of the files written since their last Sync (see MemFS.Crash).
+type unsynced struct {
+	mu      sync.Mutex                    // mu is the mutex protecting durable.
+	durable map[*fileNode]*durableContent // durable is the content of the files at their last Sync.
+}
+
+// durableContent is the content of a file at its last Sync.
+type durableContent struct {
+	data   []byte      // data is a copy of the content of a file stored in a single slice.
+	blocks *blockStore // blocks shares the unmodified blocks of a file stored in blocks, nil otherwise.
+}
+
+// openFiles counts the open files of a file system and limits their number.
+type openFiles struct {
+	count atomic.Int64 // count is the number of open files.
+	max   atomic.Int64 // max is the maximum number of open files, 0 for no limit.
+}
+
+// watchers are the watchers of a file system (see MemFS.NewWatcher).
+type watchers struct {
+	list  []*MemWatcher // list are the registered watchers.
+	count atomic.Int32  // count is the number of registered watchers.
+	mu    sync.RWMutex  // mu is the RWMutex used to access list.
+}
+
+// MemWatcher is the implementation of avfs.Watcher returned by MemFS.NewWatcher.
+type MemWatcher struct {
+	vfs     *MemFS               // vfs is the watched file system.
+	events  chan avfs.WatchEvent // events is the channel receiving the changes.
+	errors  chan error           // errors is the channel receiving the errors.
+	watches map[string]string    // watches maps the absolute paths of the watched files to their names as presented to Add.
+	mu      sync.Mutex           // mu is the mutex used to access watches.
+	closed  bool                 // closed is true if the watcher is closed.
+}
+
+// fileLocks is the table of the advisory locks of the open files (see MemFS.Lock).
+type fileLocks struct {
+	locks map[node]*fileLock // locks are the advisory locks indexed by the locked node.
+	cond  *sync.Cond         // cond signals the release of a lock.
+	mu    sync.Mutex         // mu is the mutex used to access locks.
+}
+
+// fileLock is the advisory lock of a node.
+type fileLock struct {
+	exclusive *MemFile              // exclusive is the file holding an exclusive lock, nil if none.
+	shared    map[*MemFile]struct{} // shared are the files holding a shared lock.
 }
 
 // node is the interface implemented by dirNode, fileNode and symlinkNode.
 type node interface {
 	sync.Locker
 
+	// RLock locks the node for reading.
+	RLock()
+
+	// RUnlock unlocks the node for reading.
+	RUnlock()
+
 	// checkPermission returns true if the current user has the desired permissions (perm) on the node.
 	checkPermission(perm avfs.OpenMode, u avfs.UserReader) bool
 
 	// delete removes all information from the node.
 	delete()
 
-	// fillStatFrom returns a *MemInfo (implementation of fs.FileInfo) from a node named name.
-	fillStatFrom(name string) *MemInfo
+	// fillStat fills fst (implementation of fs.FileInfo) from a node named name.
+	fillStat(fst *MemInfo, name string)
+
+	// owner returns the user id of the owner of the node.
+	owner() int
+
+	// accessACL returns the POSIX access ACL of the node.
+	accessACL() []avfs.ACLEntry
+
+	// securityInfo returns the security descriptor of the node.
+	securityInfo() *avfs.SecurityInfo
+
+	// setACL sets the POSIX access ACL of the node.
+	setACL(acl []avfs.ACLEntry, u avfs.UserReader) bool
 
 	// setMode sets the permissions of the node.
 	setMode(mode fs.FileMode, u avfs.UserReader) bool
 
-	// setModTime sets the modification time of the node.
-	setModTime(mtime time.Time, u avfs.UserReader) bool
+	// setTimes sets the access and modification times of the node, a zero time leaves the time unchanged.
+	setTimes(atime, mtime time.Time, u avfs.UserReader) bool
+
+	// setChangeTime sets the change time of the node.
+	setChangeTime(ctime time.Time)
 
 	// setOwner sets the owner of the node.
-	setOwner(uid, gid int)
+	setOwner(uid, gid int) bool
+
+	// setSecurityInfo sets the security descriptor of the node.
+	setSecurityInfo(si *avfs.SecurityInfo, u avfs.UserReader) bool
 
 	// size returns the size of the node.
 	size() int64
+
+	// base returns the common structure of the node.
+	base() *baseNode
 }
 
-// volumes are the volumes names for Windows.
-type volumes map[string]*dirNode
+// volumes are the volumes of a Windows file system.
+type volumes map[string]*volume
+
+// volume is a volume of a Windows file system.
+type volume struct {
+	rootNode *dirNode        // rootNode is the root directory of the volume.
+	info     avfs.VolumeInfo // info contains the metadata of the volume.
+	unc      string          // unc is the network share of a mapped network drive (see MapNetworkDrive).
+	mounted  bool            // mounted is true if rootNode belongs to another volume or file system (see VolumeMount).
+}
 
 // dirNode is the structure for a directory.
 type dirNode struct {
-	children children // children are the nodes present in the directory.
-	baseNode          // baseNode is the common structure of directories, files and symbolic links.
+	children    children        // children are the nodes present in the directory.
+	shortNames  shortNames      // shortNames are the short names of the children (Windows only), nil if disabled.
+	folded      foldedNames     // folded indexes the children by their case folded names, nil if names are case sensitive.
+	graft       *graft          // graft is the source of a directory grafted from another file system, nil otherwise.
+	defaultACL  []avfs.ACLEntry // defaultACL is the POSIX default ACL inherited by the files created in the directory (Linux only), nil if none.
+	baseNode                    // baseNode is the common structure of directories, files and symbolic links.
+	id          uint64          // id is a unique id to identify a directory (used by SameFile function).
+	treeVersion atomic.Uint64   // treeVersion is the version of the last mutation of the directory or of one of its descendants.
 }
 
 // children are the children of a directory.
 type children = map[string]node
 
+// shortNames maps the upper case DOS 8.3 short names of the children of a directory to their long names.
+type shortNames = map[string]string
+
+// foldedNames maps the case folded names of the children of a directory to their names.
+type foldedNames = map[string]string
+
 // fileNode is the structure for a file.
 type fileNode struct {
-	data     []byte // data is the file content.
-	baseNode        // baseNode is the common structure of directories, files and symbolic links.
-	id       uint64 // id is a unique id to identify a file (used by SameFile function).
-	nlink    int    // nlink is the number of hardlinks to this fileNode.
+	data         []byte         // data is the file content.
+	chunks       *chunks        // chunks are the extents of the content of a chunked file, nil otherwise.
+	blocks       *blockStore    // blocks is the content of a large or sparse file stored in blocks, nil otherwise.
+	graft        *graft         // graft is the source of a file grafted from another file system, nil otherwise.
+	baseNode                    // baseNode is the common structure of directories, files and symbolic links.
+	id           uint64         // id is a unique id to identify a file (used by SameFile function).
+	nlink        int            // nlink is the number of hardlinks to this fileNode.
+	rdev         uint64         // rdev is the device number of a device file (see MemFS.Mknod).
+	shares       *int           // shares counts the unreleased buffers returned by ReadFileShared, nil if data is not shared.
+	sizeWatchers []*sizeWatcher // sizeWatchers are the subscriptions to the size changes of the file.
+	streams      streams        // streams are the alternate data streams of the file (Windows only).
+	generated    bool           // generated is true if the content is generated from the identity manager (see Options.EtcFiles).
+}
+
+// streams are the alternate data streams of a file.
+type streams = map[string]*fileNode
+
+// sizeWatcher is a subscription to the size changes of a fileNode (see MemFS.WatchSize).
+type sizeWatcher struct {
+	events chan avfs.SizeEvent // events is the channel receiving the size changes.
+	name   string              // name is the name of the file as presented to WatchSize.
+}
+
+// graft is the source of a node grafted from another file system (see MemFS.Graft).
+type graft struct {
+	src    avfs.VFSBase // src is the source file system.
+	path   string       // path is the path of the node in the source file system.
+	size   int64        // size is the size of a file in the source file system.
+	loaded bool         // loaded is true if the content of the node has been loaded from the source file system.
+}
+
+// Extent is a piece of the content of a chunked file (see MemFS.CreateChunked).
+type Extent struct {
+	Reader io.ReaderAt // Reader provides the content of the extent.
+	Offset int64       // Offset is the offset of the extent in Reader.
+	Size   int64       // Size is the size of the extent.
+}
+
+// chunks are the extents of the content of a chunked file.
+type chunks struct {
+	extents []Extent // extents are the extents of the file in order.
+	ends    []int64  // ends are the offsets of the ends of the extents in the file.
+}
+
+// blockStore is the content of a file stored in blocks of blockSize bytes, so that writes only touch
+// the blocks written and never copy the whole content. Only the blocks of data written are stored,
+// the holes are read as zeros.
+type blockStore struct {
+	data   map[int64][]byte   // data are the blocks of data indexed by their number.
+	shared map[int64]struct{} // shared are the blocks shared with a snapshot, copied before being modified.
+	size   int64              // size is the size of the file.
 }
 
+// Snapshot is a copy of the content of a MemFS (see MemFS.Snapshot and MemFS.Restore).
+type Snapshot struct {
+	rootNode   *dirNode    // rootNode is a copy of the root directory of the file system.
+	volumes    volumes     // volumes are copies of the other volumes which were not mounted (Windows only).
+	osType     avfs.OSType // osType is the operating system type of the file system.
+	shortNames bool        // shortNames is true if the file system generates DOS 8.3 short names.
+	folded     bool        // folded is true if the lookups of names of the file system are case-insensitive.
+	lastId     uint64      // lastId is the last unique id used by the file system when the snapshot was taken.
+}
+
+// nodeCopier makes deep copies of nodes, the copies of the files are indexed by their source
+// to preserve the hard links between files.
+type nodeCopier map[*fileNode]*fileNode
+
 // symlinkNode is the structure for a symbolic link.
 type symlinkNode struct {
 	link     string // link is the symbolic link value.
@@ -127,11 +338,18 @@ type symlinkNode struct {
 
 // baseNode is the common structure of directories, files and symbolic links.
 type baseNode struct {
-	mu    sync.RWMutex // mu is the RWMutex used to access the content of the node.
-	mtime int64        // mtime is the modification time.
-	mode  fs.FileMode  // mode represents a file's mode and permission bits.
-	uid   int          // uid is the user id.
-	gid   int          // gid is the group id.
+	acl      []avfs.ACLEntry // acl is the extended POSIX access ACL of the node (Linux only), nil if derived from mode.
+	dacl     []avfs.ACE      // dacl is the explicit DACL of the node (Windows only), nil if derived from mode.
+	mu       sync.RWMutex    // mu is the RWMutex used to access the content of the node.
+	atime    atomic.Int64    // atime is the access time, updated by the reads holding only a read lock.
+	mtime    int64           // mtime is the modification time.
+	ctime    int64           // ctime is the change time of the content or of the metadata.
+	mode     fs.FileMode     // mode represents a file's mode and permission bits.
+	uid      int             // uid is the user id.
+	gid      int             // gid is the group id.
+	readOnly bool            // readOnly is true if the node is grafted from another file system (see MemFS.Graft).
+	version  atomic.Uint64   // version is the version of the last mutation of the node (see MemFS.Mark).
+	placed   atomic.Uint64   // placed is the version of the mutation which created, linked or renamed the node to its path.
 }
 
 // slMode defines the behavior of searchNode function relatively to symlinks.
@@ -148,9 +366,120 @@ type MemInfo struct {
 	name  string      // name is the name of the file.
 	id    uint64      // id is a unique id to identify a file (used by SameFile function).
 	size  int64       // size is the size of the file.
+	atime int64       // atime is the access time.
 	mtime int64       // mtime is the modification time.
+	ctime int64       // ctime is the change time.
 	uid   int         // uid is the user id.
 	gid   int         // gid is the group id.
 	nlink int         // nlink is the number of hardlinks to this fileNode.
+	rdev  uint64      // rdev is the device number of a device file.
 	mode  fs.FileMode // mode represents a file's mode and permission bits.
 }
+
+// Journal records the mutations of a MemFS (see Options.Journal).
+type Journal interface {
+	// Append appends an entry to the journal.
+	Append(entry *JournalEntry) error
+}
+
+// JournalEntry is a successful mutation of a MemFS recorded in a Journal.
+// Paths are recorded as they were passed to the file system or used to open the file,
+// as absolute paths of the root file system for a sub file system (see MemFS.Sub).
+// The mutations of an open file renamed since it was opened are recorded with its current path,
+// those of a removed file are not recorded.
+type JournalEntry struct {
+	Seq     uint64          `json:"seq"`               // Seq is the sequence number of the entry, starting at 1.
+	Time    time.Time       `json:"time"`              // Time is the time of the mutation.
+	Op      string          `json:"op"`                // Op is the operation (see the Journal* constants).
+	Path    string          `json:"path"`              // Path is the path of the file or the old path (link, rename, symlink).
+	NewPath string          `json:"newPath,omitempty"` // NewPath is the new path (link, rename, symlink).
+	Mode    fs.FileMode     `json:"mode,omitempty"`    // Mode is the mode (chmod) or the permissions (mkdir, open).
+	Flag    int             `json:"flag,omitempty"`    // Flag are the flags of OpenFile or Rename2, the type of ACL (setacl).
+	Uid     int             `json:"uid,omitempty"`     // Uid is the user id (chown).
+	Gid     int             `json:"gid,omitempty"`     // Gid is the group id (chown).
+	Size    int64           `json:"size,omitempty"`    // Size is the size (truncate), the offset (write) or the device number (mknod).
+	Atime   time.Time       `json:"atime,omitzero"`    // Atime is the access time (chtimes).
+	Mtime   time.Time       `json:"mtime,omitzero"`    // Mtime is the modification time (chtimes).
+	Data    []byte          `json:"data,omitempty"`    // Data are the written bytes (write).
+	ACL     []avfs.ACLEntry `json:"acl,omitempty"`     // ACL are the entries of the ACL (setacl), nil removes a default ACL.
+}
+
+// RingJournal is an in memory Journal keeping the most recent entries.
+type RingJournal struct {
+	entries []JournalEntry // entries are the entries of the ring buffer.
+	next    int            // next is the index of the next entry to write.
+	full    bool           // full is true once the ring buffer has wrapped.
+	mu      sync.Mutex     // mu is the mutex used to access the journal.
+}
+
+// FileJournal is a Journal appending entries as JSON lines to a file of another file system.
+type FileJournal struct {
+	f   avfs.File  // f is the journal file.
+	err error      // err is the first error encountered writing the journal.
+	mu  sync.Mutex // mu is the mutex used to access the journal.
+}
+
+// journal numbers the mutations of a MemFS and appends them to a Journal.
+type journal struct {
+	j         Journal          // j is the journal, nil if only the changes are tracked.
+	vfs       *MemFS           // vfs is the root file system, the versions of its nodes are updated by the mutations.
+	now       func() time.Time // now returns the time of the entries from the clock of the file system.
+	seq       uint64           // seq is the sequence number of the last entry.
+	linked    uint64           // linked is the version of the last mutation of a file once hard links may exist.
+	restored  uint64           // restored is the version of the last restoration of a snapshot.
+	hardlinks bool             // hardlinks is true if the file system may have files with several hard links.
+	track     bool             // track updates the versions of the changed nodes (see Options.TrackChanges).
+	mu        sync.Mutex       // mu serializes the entries.
+	apply     sync.Mutex       // apply serializes the journaled mutations with the recording of their entries.
+}
+
+// OpenAudit records the flags and permissions used to open files (see Options.OpenAudit).
+// The zero value is ready to use, an audit is used by a single file system and its sub file systems.
+type OpenAudit struct {
+	isUnder func(path, dir string) bool // isUnder is the isUnder method of the file system using the audit.
+	records []OpenRecord                // records are the calls to OpenFile, in call order.
+	mu      sync.Mutex                  // mu is the mutex used to access the records.
+}
+
+// OpenRecord is a call to OpenFile recorded by an OpenAudit.
+type OpenRecord struct {
+	Path string      // Path is the absolute path of the file in the root file system, its symbolic links evaluated.
+	Flag int         // Flag are the flags of OpenFile.
+	Perm fs.FileMode // Perm are the permissions of OpenFile.
+	Err  error       // Err is the error returned by OpenFile, nil if the file was opened.
+}
+
+// change is a path changed by a mutation.
+type change struct {
+	path   string // path is the absolute path of the changed file in the root file system, its symbolic links evaluated.
+	placed bool   // placed is true if the file was created, linked or renamed to path.
+}
+
+// CheckReport is the result of the verification of the internal invariants of a MemFS (see MemFS.Check).
+type CheckReport struct {
+	Problems []CheckProblem // Problems are the violated invariants, empty if the file system is consistent.
+	Dirs     int            // Dirs is the number of directories checked.
+	Files    int            // Files is the number of distinct files checked.
+	Symlinks int            // Symlinks is the number of symbolic links checked.
+}
+
+// CheckProblem is a violated invariant of a MemFS.
+type CheckProblem struct {
+	Path string // Path is the path of the faulty node.
+	Msg  string // Msg describes the violated invariant.
+}
+
+// checker walks the nodes of a MemFS to verify their invariants.
+type checker struct {
+	vfs    *MemFS                  // vfs is the checked file system.
+	report *CheckReport            // report collects the problems found.
+	dirs   map[*dirNode]string     // dirs are the paths of the directories already visited.
+	files  map[*fileNode]*fileRefs // files are the references to the files found.
+}
+
+// fileRefs are the references to a fileNode found in the directory tree.
+type fileRefs struct {
+	path  string // path is the first path of the file.
+	count int    // count is the number of directory entries referencing the file.
+	nlink int    // nlink is the link count of the file.
+}
@@ -0,0 +1,217 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"io/fs"
+	"slices"
+
+	"github.com/avfs/avfs"
+)
+
+// NewWatcher returns a new Watcher of the changes of files and directories made through the file system,
+// its clones and the sub file systems of its clones share the watchers only if they share the same root.
+// Unlike inotify, a watch follows the name and not the file : the watch of a removed or renamed file
+// or directory is removed, the events of a file are reported with its name at the time of the change.
+// If the receiver is too slow, the oldest pending events are dropped and avfs.ErrWatchOverflow is sent on the Errors channel.
+func (vfs *MemFS) NewWatcher() (avfs.Watcher, error) {
+	w := &MemWatcher{
+		vfs:     vfs,
+		events:  make(chan avfs.WatchEvent, watchEventsMax),
+		errors:  make(chan error, 1),
+		watches: make(map[string]string),
+	}
+
+	ws := vfs.watchers
+
+	ws.mu.Lock()
+	ws.list = append(ws.list, w)
+	ws.count.Store(int32(len(ws.list)))
+	ws.mu.Unlock()
+
+	return w, nil
+}
+
+// Add starts watching the named file or directory.
+// The changes of a directory and of its direct children are reported, the subdirectories are not watched.
+// If there is an error, it will be of type *PathError.
+func (w *MemWatcher) Add(name string) error {
+	const op = "inotify_add_watch"
+
+	vfs := w.vfs
+
+	_, child, _, err := vfs.searchNode(name, slmEval)
+	if err != vfs.err.FileExists || child == nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	child.Lock()
+	ok := child.checkPermission(avfs.OpenRead, vfs.User())
+	child.Unlock()
+
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.PermDenied}
+	}
+
+	absPath, _ := vfs.Abs(name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrClosed}
+	}
+
+	w.watches[absPath] = name
+
+	return nil
+}
+
+// Close removes all the watches and closes the Events and Errors channels.
+func (w *MemWatcher) Close() error {
+	ws := w.vfs.watchers
+
+	ws.mu.Lock()
+	ws.list = slices.DeleteFunc(ws.list, func(lw *MemWatcher) bool { return lw == w })
+	ws.count.Store(int32(len(ws.list)))
+	ws.mu.Unlock()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	w.watches = nil
+
+	close(w.events)
+	close(w.errors)
+
+	return nil
+}
+
+// Errors returns the channel receiving the errors of the watcher, like a lost event.
+func (w *MemWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Events returns the channel receiving the changes of the watched files and directories.
+func (w *MemWatcher) Events() <-chan avfs.WatchEvent {
+	return w.events
+}
+
+// Remove stops watching the named file or directory.
+// If there is an error, it will be of type *PathError.
+func (w *MemWatcher) Remove(name string) error {
+	const op = "inotify_rm_watch"
+
+	absPath, _ := w.vfs.Abs(name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.watches[absPath]; !ok {
+		return &fs.PathError{Op: op, Path: name, Err: w.vfs.err.InvalidArgument}
+	}
+
+	delete(w.watches, absPath)
+
+	return nil
+}
+
+// send sends the event op on the file absPath if it is watched or if its parent directory is watched.
+func (w *MemWatcher) send(op avfs.WatchOp, absPath string) {
+	vfs := w.vfs
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	if name, ok := w.watches[absPath]; ok {
+		w.sendEvent(avfs.WatchEvent{Name: name, Op: op})
+
+		if op == avfs.WatchRemove || op == avfs.WatchRename {
+			delete(w.watches, absPath)
+		}
+	}
+
+	dir, file := vfs.Split(absPath)
+	if file == "" {
+		return
+	}
+
+	if name, ok := w.watches[avfs.TrimSeparators(vfs, dir)]; ok {
+		w.sendEvent(avfs.WatchEvent{Name: vfs.Join(name, file), Op: op})
+	}
+}
+
+// sendEvent sends an event without blocking, the oldest pending event is dropped if the channel is full.
+// The watcher must be locked.
+func (w *MemWatcher) sendEvent(event avfs.WatchEvent) {
+	for {
+		select {
+		case w.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-w.events:
+			select {
+			case w.errors <- avfs.ErrWatchOverflow:
+			default:
+			}
+		default:
+		}
+	}
+}
+
+// active returns true if at least one watcher is registered.
+func (ws *watchers) active() bool {
+	return ws.count.Load() != 0
+}
+
+// notify sends the event op on the named file to the watchers if the operation succeeded (*err == nil).
+func (vfs *MemFS) notify(err *error, op avfs.WatchOp, name string) {
+	if *err == nil {
+		vfs.sendWatch(op, name)
+	}
+}
+
+// sendWatch sends the event op on the named file to the watchers.
+func (vfs *MemFS) sendWatch(op avfs.WatchOp, name string) {
+	ws := vfs.watchers
+	if !ws.active() {
+		return
+	}
+
+	absPath, _ := vfs.Abs(name)
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	for _, w := range ws.list {
+		if w.vfs.rootNode == vfs.rootNode {
+			w.send(op, absPath)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package memfs
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// windowsName returns the element name of a Windows path as normalized by the Win32 API:
+// the trailing dots and spaces of the last element are removed.
+// The error is ErrWinInvalidName if name contains an invalid character or is a reserved device name
+// (CON, NUL, COM1, ...), MemFS doesn't emulate devices.
+func windowsName(name string, trim bool) (string, error) {
+	if trim {
+		if s := strings.TrimRight(name, ". "); s != "" {
+			name = s
+		}
+	}
+
+	var e *avfs.InvalidPathError
+	if err := avfs.ValidatePath(avfs.OsWindows, name); errors.As(err, &e) &&
+		(e.Reason == avfs.PathInvalidChar || e.Reason == avfs.PathReservedName) {
+		return name, avfs.ErrWinInvalidName
+	}
+
+	return name, nil
+}
@@ -58,6 +58,10 @@ func (vfs *OrefaFS) Chdir(dir string) error {
 	nd, ok := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(dir, nd, ok); err != nil {
+		return &fs.PathError{Op: op, Path: dir, Err: err}
+	}
+
 	if !ok {
 		return &fs.PathError{Op: op, Path: dir, Err: vfs.err.NoSuchFile}
 	}
@@ -103,6 +107,10 @@ func (vfs *OrefaFS) Chmod(name string, mode fs.FileMode) error {
 	nd, ok := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(name, nd, ok); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !ok {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
 	}
@@ -128,12 +136,20 @@ func (vfs *OrefaFS) Chown(name string, uid, gid int) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
 	}
 
+	if vfs.HasFeature(avfs.FeatStrict) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotSupported}
+	}
+
 	absPath, _ := vfs.Abs(name)
 
 	vfs.mu.RLock()
 	nd, ok := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(name, nd, ok); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !ok {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
 	}
@@ -160,6 +176,10 @@ func (vfs *OrefaFS) Chtimes(name string, atime, mtime time.Time) error {
 	nd, ok := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(name, nd, ok); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !ok {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
 	}
@@ -304,12 +324,20 @@ func (vfs *OrefaFS) Lchown(name string, uid, gid int) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.OpNotPermitted}
 	}
 
+	if vfs.HasFeature(avfs.FeatStrict) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotSupported}
+	}
+
 	absPath, _ := vfs.Abs(name)
 
 	vfs.mu.RLock()
 	nd, ok := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(name, nd, ok); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !ok {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
 	}
@@ -355,7 +383,12 @@ func (vfs *OrefaFS) Link(oldname, newname string) error {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
 	}
 
-	if !nParentOk {
+	if err := vfs.checkPath(oldname, oChild, oChildOk); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	// Like link(2), a path ending with a path separator can't be created.
+	if !nParentOk || newname == "" || (!nChildOk && avfs.HasTrailingSeparator(vfs, newname)) {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NoSuchFile}
 	}
 
@@ -451,10 +484,15 @@ func (vfs *OrefaFS) Mkdir(name string, perm fs.FileMode) error {
 	vfs.mu.Lock()
 	defer vfs.mu.Unlock()
 
-	_, childOk := vfs.nodes[absPath]
+	child, childOk := vfs.nodes[absPath]
 	parent, parentOk := vfs.nodes[dirName]
 
 	if childOk {
+		// Like mkdir(2), a file followed by "." or ".." is not a directory.
+		if !child.mode.IsDir() && vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, name) != "" {
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+		}
+
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.FileExists}
 	}
 
@@ -490,6 +528,10 @@ func (vfs *OrefaFS) Mkdir(name string, perm fs.FileMode) error {
 func (vfs *OrefaFS) MkdirAll(path string, perm fs.FileMode) error {
 	const op = "mkdir"
 
+	if path == "" {
+		return &fs.PathError{Op: op, Path: "", Err: vfs.err.NoSuchDir}
+	}
+
 	absPath, _ := vfs.Abs(path)
 
 	vfs.mu.Lock()
@@ -527,7 +569,8 @@ func (vfs *OrefaFS) MkdirAll(path string, perm fs.FileMode) error {
 		dirName, _ = avfs.SplitAbs(vfs, dirName)
 	}
 
-	for _, absPath = range ds {
+	for i := len(ds) - 1; i >= 0; i-- {
+		absPath = ds[i]
 		_, fileName := avfs.SplitAbs(vfs, absPath)
 
 		parent = vfs.createDir(parent, absPath, fileName, perm)
@@ -567,6 +610,11 @@ func (vfs *OrefaFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File
 	at := int64(0)
 	om := avfs.ToOpenMode(flag)
 
+	// Like open(2), a path ending with a path separator can't be created.
+	if om&avfs.OpenCreate != 0 && avfs.HasTrailingSeparator(vfs, name) {
+		return (*OrefaFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+	}
+
 	absPath, _ := vfs.Abs(name)
 	dirName, fileName := avfs.SplitAbs(vfs, absPath)
 
@@ -575,6 +623,10 @@ func (vfs *OrefaFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File
 	child, childOk := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(name, child, childOk); err != nil {
+		return (*OrefaFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !childOk {
 		if !parentOk {
 			return (*OrefaFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
@@ -629,6 +681,7 @@ func (vfs *OrefaFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File
 		nd:       child,
 		openMode: om,
 		name:     name,
+		absPath:  absPath,
 		at:       at,
 	}
 
@@ -691,10 +744,24 @@ func (vfs *OrefaFS) Remove(name string) error {
 	child, childOk := vfs.nodes[absPath]
 	parent, parentOk := vfs.nodes[dirName]
 
+	if err := vfs.checkPath(name, child, childOk); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !childOk || !parentOk {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
 	}
 
+	// Like rmdir, a directory can't be removed by a path ending with "." or "..".
+	if vfs.OSType() != avfs.OsWindows {
+		switch avfs.DotElem(vfs, name) {
+		case ".":
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+		case "..":
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
 	parent.mu.Lock()
 	defer parent.mu.Unlock()
 
@@ -705,6 +772,10 @@ func (vfs *OrefaFS) Remove(name string) error {
 		return &fs.PathError{Op: op, Path: name, Err: vfs.err.DirNotEmpty}
 	}
 
+	if child.mode.IsDir() {
+		parent.nlink--
+	}
+
 	child.remove()
 
 	delete(parent.children, fileName)
@@ -719,11 +790,23 @@ func (vfs *OrefaFS) Remove(name string) error {
 // returns nil (no error).
 // If there is an error, it will be of type *PathError.
 func (vfs *OrefaFS) RemoveAll(path string) error {
+	const op = "unlinkat"
+
 	if path == "" {
 		// fail silently to retain compatibility with previous behavior of RemoveAll.
 		return nil
 	}
 
+	// The current directory or the parent of a directory is never removed.
+	switch avfs.DotElem(vfs, path) {
+	case ".":
+		return &fs.PathError{Op: "RemoveAll", Path: path, Err: vfs.err.InvalidArgument}
+	case "..":
+		if vfs.OSType() != avfs.OsWindows {
+			return &fs.PathError{Op: op, Path: path, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
 	absPath, _ := vfs.Abs(path)
 	dirName, fileName := avfs.SplitAbs(vfs, absPath)
 
@@ -743,7 +826,15 @@ func (vfs *OrefaFS) RemoveAll(path string) error {
 
 	child.remove()
 
+	parent.mu.Lock()
+
+	if child.mode.IsDir() {
+		parent.nlink--
+	}
+
 	delete(parent.children, fileName)
+	parent.mu.Unlock()
+
 	delete(vfs.nodes, absPath)
 
 	return nil
@@ -772,10 +863,6 @@ func (vfs *OrefaFS) Rename(oldname, newname string) error {
 	oAbsPath, _ := vfs.Abs(oldname)
 	nAbsPath, _ := vfs.Abs(newname)
 
-	if oAbsPath == nAbsPath {
-		return nil
-	}
-
 	oDirName, oFileName := avfs.SplitAbs(vfs, oAbsPath)
 	nDirName, nFileName := avfs.SplitAbs(vfs, nAbsPath)
 
@@ -786,10 +873,29 @@ func (vfs *OrefaFS) Rename(oldname, newname string) error {
 	nParent, nParentOk := vfs.nodes[nDirName]
 	vfs.mu.RUnlock()
 
-	if !oChildOk || !oParentOk || !nParentOk {
+	if err := vfs.checkPath(oldname, oChild, oChildOk); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	if !oChildOk || !oParentOk || !nParentOk || newname == "" {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NoSuchFile}
 	}
 
+	// Like rename(2), a directory can't be renamed by or to itself through a path ending with "." or "..".
+	if vfs.OSType() != avfs.OsWindows &&
+		(avfs.DotElem(vfs, oldname) != "" || (avfs.DotElem(vfs, newname) != "" && oChild == nChild)) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: avfs.ErrDeviceBusy}
+	}
+
+	if !oChild.mode.IsDir() && avfs.HasTrailingSeparator(vfs, newname) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NotADirectory}
+	}
+
+	// Like rename(2), nothing is done if oldpath and newpath are hard links to the same file.
+	if oAbsPath == nAbsPath || oChild == nChild {
+		return nil
+	}
+
 	if (oChild.mode.IsDir() && nChildOk) || (!oChild.mode.IsDir() && nChildOk && nChild.mode.IsDir()) {
 		err := vfs.err.FileExists
 		if vfs.OSType() == avfs.OsWindows {
@@ -799,6 +905,16 @@ func (vfs *OrefaFS) Rename(oldname, newname string) error {
 		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
 	}
 
+	// Like rename(2), a directory can't become a descendant of itself.
+	if oChild.mode.IsDir() && strings.HasPrefix(nAbsPath, oAbsPath+string(vfs.PathSeparator())) {
+		err := error(avfs.ErrInvalidArgument)
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinSharingViolation
+		}
+
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
 	nParent.mu.Lock()
 	defer nParent.mu.Unlock()
 
@@ -807,10 +923,21 @@ func (vfs *OrefaFS) Rename(oldname, newname string) error {
 		defer oParent.mu.Unlock()
 	}
 
-	nParent.children[nFileName] = oChild
+	if nChildOk {
+		nChild.mu.Lock()
+		nChild.remove()
+		nChild.mu.Unlock()
+	}
+
+	nParent.addChild(nFileName, oChild)
 
 	delete(oParent.children, oFileName)
 
+	if oChild.mode.IsDir() && nParent != oParent {
+		nParent.nlink++
+		oParent.nlink--
+	}
+
 	vfs.mu.Lock()
 	defer vfs.mu.Unlock()
 
@@ -859,6 +986,12 @@ func (vfs *OrefaFS) SetUserByName(name string) error {
 	return avfs.SetUserByName(vfs, name)
 }
 
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *OrefaFS) SetUserByUid(uid, gid int) error {
+	return avfs.SetUserByUid(vfs, uid, gid)
+}
+
 // Split splits path immediately following the final Separator,
 // separating it into a directory and file name component.
 // If there is no Separator in path, Split returns an empty dir
@@ -888,6 +1021,10 @@ func (vfs *OrefaFS) stat(path, op string) (fs.FileInfo, error) {
 	child, childOk := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(path, child, childOk); err != nil {
+		return nil, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
 	if !childOk {
 		vfs.mu.RLock()
 		parent, parentOk := vfs.nodes[dirName]
@@ -966,6 +1103,10 @@ func (vfs *OrefaFS) Truncate(name string, size int64) error {
 	child, childOk := vfs.nodes[absPath]
 	vfs.mu.RUnlock()
 
+	if err := vfs.checkPath(name, child, childOk); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
 	if !childOk {
 		if vfs.OSType() == avfs.OsWindows {
 			op = "open"
@@ -35,6 +35,9 @@ func NewWithOptions(opts *Options) *OrefaFS {
 	}
 
 	features := avfs.FeatHardlink | avfs.BuildFeatures()
+	if opts.Strict {
+		features |= avfs.FeatStrict
+	}
 	idm := avfs.NotImplementedIdm
 
 	user := opts.User
@@ -72,6 +75,7 @@ func NewWithOptions(opts *Options) *OrefaFS {
 		mtime: time.Now().UnixNano(),
 		uid:   0,
 		gid:   0,
+		nlink: 2,
 	}
 
 	_ = vfs.SetCurDir(curDir)
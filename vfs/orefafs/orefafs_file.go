@@ -54,7 +54,7 @@ func (f *OrefaFile) Chdir() error {
 		return &fs.PathError{Op: op, Path: f.name, Err: err}
 	}
 
-	_ = f.vfs.SetCurDir(f.name)
+	_ = f.vfs.SetCurDir(f.absPath)
 
 	return nil
 }
@@ -111,6 +111,10 @@ func (f *OrefaFile) Chown(uid, gid int) error {
 		return &fs.PathError{Op: op, Path: f.name, Err: avfs.ErrWinNotSupported}
 	}
 
+	if f.vfs.HasFeature(avfs.FeatStrict) {
+		return &fs.PathError{Op: op, Path: f.name, Err: f.vfs.err.NotSupported}
+	}
+
 	f.nd.setOwner(uid, gid)
 
 	return nil
@@ -192,7 +196,9 @@ func (f *OrefaFile) Read(b []byte) (n int, err error) {
 	}
 
 	nd.mu.RLock()
-	n = copy(b, nd.data[f.at:])
+	if f.at < int64(len(nd.data)) {
+		n = copy(b, nd.data[f.at:])
+	}
 	nd.mu.RUnlock()
 
 	f.at += int64(n)
@@ -627,12 +633,13 @@ func (f *OrefaFile) Write(b []byte) (n int, err error) {
 
 	nd.mu.Lock()
 
-	n = copy(nd.data[f.at:], b)
-	if n < len(b) {
-		nd.data = append(nd.data, b[n:]...)
-		n = len(b)
+	diff := f.at + int64(len(b)) - nd.size()
+	if diff > 0 {
+		nd.data = append(nd.data, make([]byte, diff)...)
 	}
 
+	n = copy(nd.data[f.at:], b)
+
 	nd.mtime = time.Now().UnixNano()
 
 	nd.mu.Unlock()
@@ -756,6 +763,11 @@ func (info *OrefaInfo) Type() fs.FileMode {
 	return info.mode & fs.ModeType
 }
 
+// Dev returns the device id of the file system containing the file, always 0.
+func (info *OrefaInfo) Dev() uint64 {
+	return 0
+}
+
 // Gid returns the group id.
 func (info *OrefaInfo) Gid() int {
 	return info.gid
@@ -766,6 +778,11 @@ func (info *OrefaInfo) Uid() int {
 	return info.uid
 }
 
+// Ino returns the unique id of the file.
+func (info *OrefaInfo) Ino() uint64 {
+	return info.id
+}
+
 // Nlink returns the number of hard links.
 func (info *OrefaInfo) Nlink() uint64 {
 	return uint64(info.nlink)
@@ -35,6 +35,21 @@ func (nd *node) addChild(name string, child *node) {
 	nd.children[name] = child
 }
 
+// checkPath returns the error of a path whose node nd was found or not (ok) by its absolute path.
+// Like the system calls, an empty path is never found and a path ending with a path separator,
+// "." or ".." can only name a directory.
+func (vfs *OrefaFS) checkPath(path string, nd *node, ok bool) error {
+	if path == "" {
+		return vfs.err.NoSuchDir
+	}
+
+	if ok && !nd.mode.IsDir() && avfs.IsDirPath(vfs, path) {
+		return vfs.err.NotADirectory
+	}
+
+	return nil
+}
+
 // createDir creates a new directory.
 func (vfs *OrefaFS) createDir(parent *node, absPath, fileName string, perm fs.FileMode) *node {
 	mode := vfs.dirMode | (perm & avfs.FileModeMask &^ vfs.UMask())
@@ -63,6 +78,12 @@ func (vfs *OrefaFS) createNode(parent *node, absPath, fileName string, mode fs.F
 		nlink: 1,
 	}
 
+	// The link count of a directory includes its own '.' entry and the '..' entries of its subdirectories.
+	if mode.IsDir() {
+		nd.nlink = 2
+		parent.nlink++
+	}
+
 	parent.addChild(fileName, nd)
 
 	vfs.nodes[absPath] = nd
@@ -146,8 +167,12 @@ func (nd *node) setMode(mode fs.FileMode) {
 	nd.mode |= mode & avfs.FileModeMask
 }
 
-// setModTime sets the modification time of the node.
+// setModTime sets the modification time of the node, a zero time leaves it unchanged.
 func (nd *node) setModTime(mtime time.Time) {
+	if mtime.IsZero() {
+		return
+	}
+
 	nd.mtime = mtime.UnixNano()
 }
 
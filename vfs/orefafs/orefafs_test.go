@@ -43,6 +43,9 @@ var (
 	// Tests that orefafs.OrefaInfo struct implements fs.FileInfo interface.
 	_ fs.FileInfo = &orefafs.OrefaInfo{}
 
+	// Tests that orefafs.OrefaFS struct implements avfs.TempRander interface.
+	_ avfs.TempRander = &orefafs.OrefaFS{}
+
 	// Tests that orefafs.OrefaInfo struct implements avfs.SysStater interface.
 	_ avfs.SysStater = &orefafs.OrefaInfo{}
 )
@@ -54,6 +57,13 @@ func TestOrefaFS(t *testing.T) {
 	ts.TestVFSAll(t)
 }
 
+func TestOrefaFSStrict(t *testing.T) {
+	vfs := orefafs.NewWithOptions(&orefafs.Options{Strict: true})
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
 func TestOrefaFSConfig(t *testing.T) {
 	vfs := orefafs.New()
 
@@ -73,9 +83,29 @@ func TestOrefaFSConfig(t *testing.T) {
 	}
 }
 
-func BenchmarkOrefaFSAll(b *testing.B) {
+func TestOrefaFSMkdirAll(t *testing.T) {
 	vfs := orefafs.New()
+	root := vfs.TempDir()
+	path := vfs.Join(root, "a", "b", "c")
+
+	err := vfs.MkdirAll(path, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", path)
+
+	// Each directory created by MkdirAll must be a child of the previous one.
+	for dir, name := range map[string]string{
+		root:                     "a",
+		vfs.Join(root, "a"):      "b",
+		vfs.Join(root, "a", "b"): "c",
+	} {
+		entries, err := vfs.ReadDir(dir)
+		test.RequireNoError(t, err, "ReadDir %s", dir)
+
+		if len(entries) != 1 || entries[0].Name() != name {
+			t.Errorf("ReadDir %s : want a single entry %s, got %v", dir, name, entries)
+		}
+	}
+}
 
-	ts := test.NewSuiteFS(b, vfs, vfs)
-	ts.BenchAll(b)
+func BenchmarkOrefaFSAll(b *testing.B) {
+	test.BenchAll(b, orefafs.New())
 }
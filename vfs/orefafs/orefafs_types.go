@@ -38,6 +38,7 @@ type OrefaFS struct {
 	avfs.UMaskFn                 // UMaskFn provides UMask functions to file systems.
 	avfs.FeaturesFn              // FeaturesFn provides features functions to a file system or an identity manager.
 	avfs.OSTypeFn                // OSTypeFn provides OS type functions to a file system or an identity manager.
+	avfs.TempRandFn              // TempRandFn provides a source of random numbers for the names of temporary files.
 }
 
 // OrefaFile represents an open file descriptor.
@@ -45,6 +46,7 @@ type OrefaFile struct {
 	vfs        *OrefaFS      // vfs is the memory file system of the file.
 	nd         *node         // nd is node of the file.
 	name       string        // name is the name of the file.
+	absPath    string        // absPath is the absolute path of the file when it was opened (used by Chdir).
 	dirEntries []fs.DirEntry // dirEntries stores the file information returned by ReadDir function.
 	dirNames   []string      // dirNames stores the names of the file returned by Readdirnames function.
 	at         int64         // at is current position in the file used by Read and Write functions.
@@ -59,6 +61,7 @@ type Options struct {
 	User       avfs.UserReader // User is the current user of the file system.
 	Name       string          // Name is the name of the file system.
 	OSType     avfs.OSType     // OSType defines the operating system type.
+	Strict     bool            // Strict returns an error for unsupported operations instead of silently succeeding.
 }
 
 // nodes is the map of nodes (files or directories) where the key is the absolute path.
@@ -27,6 +27,7 @@ import (
 
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/idm/osidm"
+	"github.com/avfs/avfs/vfs/basepathfs"
 )
 
 // file system functions.
@@ -50,8 +51,12 @@ func (vfs *OsFS) Base(path string) string {
 
 // Chdir changes the current working directory to the named directory.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Chdir(dir string) error {
-	return os.Chdir(dir)
+func (vfs *OsFS) Chdir(dir string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChdir, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Chdir(dir) })
 }
 
 // Chmod changes the mode of the named file to mode.
@@ -72,8 +77,12 @@ func (vfs *OsFS) Chdir(dir string) error {
 //
 // On Plan 9, the mode's permission bits, ModeAppend, ModeExclusive,
 // and ModeTemporary are used.
-func (vfs *OsFS) Chmod(name string, mode fs.FileMode) error {
-	return os.Chmod(name, mode)
+func (vfs *OsFS) Chmod(name string, mode fs.FileMode) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChmod, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Chmod(name, mode) })
 }
 
 // Chown changes the numeric uid and gid of the named file.
@@ -83,14 +92,18 @@ func (vfs *OsFS) Chmod(name string, mode fs.FileMode) error {
 //
 // On Windows or Plan 9, Chown always returns the syscall.EWINDOWS or
 // EPLAN9 error, wrapped in *PathError.
-func (vfs *OsFS) Chown(name string, uid, gid int) error {
+func (vfs *OsFS) Chown(name string, uid, gid int) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChown, time.Now(), &err)
+	}
+
 	const op = "chown"
 
 	if !vfs.HasFeature(avfs.FeatIdentityMgr) && vfs.OSType() != avfs.OsWindows {
 		return &fs.PathError{Op: op, Path: name, Err: avfs.ErrOpNotPermitted}
 	}
 
-	return os.Chown(name, uid, gid)
+	return vfs.asUserErr(func() error { return os.Chown(name, uid, gid) })
 }
 
 // Chtimes changes the access and modification times of the named
@@ -99,8 +112,12 @@ func (vfs *OsFS) Chown(name string, uid, gid int) error {
 // The underlying filesystem may truncate or round the values to a
 // less precise time unit.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Chtimes(name string, atime, mtime time.Time) error {
-	return os.Chtimes(name, atime, mtime)
+func (vfs *OsFS) Chtimes(name string, atime, mtime time.Time) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnChtimes, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Chtimes(name, atime, mtime) })
 }
 
 // Clean returns the shortest path name equivalent to path
@@ -136,7 +153,7 @@ func (vfs *OsFS) Clean(path string) string {
 // be used for I/O; the associated file descriptor has mode O_RDWR.
 // If there is an error, it will be of type *PathError.
 func (vfs *OsFS) Create(name string) (avfs.File, error) {
-	return os.Create(name)
+	return vfs.openAsUser(func() (*os.File, error) { return os.Create(name) })
 }
 
 // CreateTemp creates a new temporary file in the directory dir,
@@ -148,7 +165,7 @@ func (vfs *OsFS) Create(name string) (avfs.File, error) {
 // The caller can use the file's Name method to find the pathname of the file.
 // It is the caller's responsibility to remove the file when it is no longer needed.
 func (vfs *OsFS) CreateTemp(dir, pattern string) (avfs.File, error) {
-	return os.CreateTemp(dir, pattern)
+	return vfs.openAsUser(func() (*os.File, error) { return os.CreateTemp(dir, pattern) })
 }
 
 // Dir returns all but the last element of path, typically the path's directory.
@@ -167,7 +184,7 @@ func (vfs *OsFS) Dir(path string) string {
 // unless one of the components is an absolute symbolic link.
 // EvalSymlinks calls Clean on the result.
 func (vfs *OsFS) EvalSymlinks(path string) (string, error) {
-	return filepath.EvalSymlinks(path)
+	return asUser(vfs, func() (string, error) { return filepath.EvalSymlinks(path) })
 }
 
 // FromSlash returns the result of replacing each slash ('/') character
@@ -194,7 +211,7 @@ func (vfs *OsFS) Getwd() (dir string, err error) {
 // The only possible returned error is ErrBadPattern, when pattern
 // is malformed.
 func (vfs *OsFS) Glob(pattern string) (matches []string, err error) {
-	return filepath.Glob(pattern)
+	return asUser(vfs, func() ([]string, error) { return filepath.Glob(pattern) })
 }
 
 // IsAbs reports whether the path is absolute.
@@ -224,28 +241,40 @@ func (vfs *OsFS) Join(elem ...string) string {
 //
 // On Windows, it always returns the syscall.EWINDOWS error, wrapped
 // in *PathError.
-func (vfs *OsFS) Lchown(name string, uid, gid int) error {
+func (vfs *OsFS) Lchown(name string, uid, gid int) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLchown, time.Now(), &err)
+	}
+
 	const op = "lchown"
 
 	if !vfs.HasFeature(avfs.FeatIdentityMgr) && vfs.OSType() != avfs.OsWindows {
 		return &os.PathError{Op: op, Path: name, Err: avfs.ErrOpNotPermitted}
 	}
 
-	return os.Lchown(name, uid, gid)
+	return vfs.asUserErr(func() error { return os.Lchown(name, uid, gid) })
 }
 
 // Link creates newname as a hard link to the oldname file.
 // If there is an error, it will be of type *LinkError.
-func (vfs *OsFS) Link(oldname, newname string) error {
-	return os.Link(oldname, newname)
+func (vfs *OsFS) Link(oldname, newname string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLink, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Link(oldname, newname) })
 }
 
 // Lstat returns a FileInfo describing the named file.
 // If the file is a symbolic link, the returned FileInfo
 // describes the symbolic link. Lstat makes no attempt to follow the link.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Lstat(name string) (fs.FileInfo, error) {
-	return os.Lstat(name)
+func (vfs *OsFS) Lstat(name string) (info fs.FileInfo, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLstat, time.Now(), &err)
+	}
+
+	return asUser(vfs, func() (fs.FileInfo, error) { return os.Lstat(name) })
 }
 
 // Match reports whether name matches the shell file name pattern.
@@ -279,8 +308,12 @@ func (vfs *OsFS) Match(pattern, name string) (matched bool, err error) {
 // Mkdir creates a new directory with the specified name and permission
 // bits (before umask).
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Mkdir(name string, perm fs.FileMode) error {
-	return os.Mkdir(name, perm)
+func (vfs *OsFS) Mkdir(name string, perm fs.FileMode) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnMkdir, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Mkdir(name, perm) })
 }
 
 // MkdirAll creates a directory named path,
@@ -290,8 +323,12 @@ func (vfs *OsFS) Mkdir(name string, perm fs.FileMode) error {
 // directories that MkdirAll creates.
 // If path is already a directory, MkdirAll does nothing
 // and returns nil.
-func (vfs *OsFS) MkdirAll(path string, perm fs.FileMode) error {
-	return os.MkdirAll(path, perm)
+func (vfs *OsFS) MkdirAll(path string, perm fs.FileMode) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnMkdirAll, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.MkdirAll(path, perm) })
 }
 
 // MkdirTemp creates a new temporary directory in the directory dir
@@ -302,7 +339,7 @@ func (vfs *OsFS) MkdirAll(path string, perm fs.FileMode) error {
 // Multiple programs or goroutines calling MkdirTemp simultaneously will not choose the same directory.
 // It is the caller's responsibility to remove the directory when it is no longer needed.
 func (vfs *OsFS) MkdirTemp(dir, prefix string) (name string, err error) {
-	return os.MkdirTemp(dir, prefix)
+	return asUser(vfs, func() (string, error) { return os.MkdirTemp(dir, prefix) })
 }
 
 // Open opens the named file for reading. If successful, methods on
@@ -310,7 +347,7 @@ func (vfs *OsFS) MkdirTemp(dir, prefix string) (name string, err error) {
 // descriptor has mode O_RDONLY.
 // If there is an error, it will be of type *PathError.
 func (vfs *OsFS) Open(name string) (avfs.File, error) {
-	return os.Open(name)
+	return vfs.openAsUser(func() (*os.File, error) { return os.Open(name) })
 }
 
 // OpenFile is the generalized open call; most users will use Open
@@ -319,8 +356,16 @@ func (vfs *OsFS) Open(name string) (avfs.File, error) {
 // is passed, it is created with mode perm (before umask). If successful,
 // methods on the returned File can be used for I/O.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File, error) {
-	return os.OpenFile(name, flag, perm)
+func (vfs *OsFS) OpenFile(name string, flag int, perm fs.FileMode) (f avfs.File, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnOpenFile, time.Now(), &err)
+	}
+
+	if flag&avfs.O_TMPFILE == avfs.O_TMPFILE {
+		return vfs.openAsUser(func() (*os.File, error) { return openTmpFile(name, flag, perm) })
+	}
+
+	return vfs.openAsUser(func() (*os.File, error) { return os.OpenFile(name, flag, perm) })
 }
 
 // OSType returns the operating system type of the file system.
@@ -338,8 +383,12 @@ func (vfs *OsFS) PathSeparator() uint8 {
 // If an error occurs reading the directory,
 // ReadDir returns the entries it was able to read before the error,
 // along with the error.
-func (vfs *OsFS) ReadDir(name string) ([]fs.DirEntry, error) {
-	return os.ReadDir(name)
+func (vfs *OsFS) ReadDir(name string) (entries []fs.DirEntry, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnReadDir, time.Now(), &err)
+	}
+
+	return asUser(vfs, func() ([]fs.DirEntry, error) { return os.ReadDir(name) })
 }
 
 // ReadFile reads the named file and returns the contents.
@@ -347,13 +396,17 @@ func (vfs *OsFS) ReadDir(name string) ([]fs.DirEntry, error) {
 // Because ReadFile reads the whole file, it does not treat an EOF from Read
 // as an error to be reported.
 func (vfs *OsFS) ReadFile(filename string) ([]byte, error) {
-	return os.ReadFile(filename)
+	return asUser(vfs, func() ([]byte, error) { return os.ReadFile(filename) })
 }
 
 // Readlink returns the destination of the named symbolic link.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Readlink(name string) (string, error) {
-	return os.Readlink(name)
+func (vfs *OsFS) Readlink(name string) (link string, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnReadlink, time.Now(), &err)
+	}
+
+	return asUser(vfs, func() (string, error) { return os.Readlink(name) })
 }
 
 // Rel returns a relative path that is lexically equivalent to targpath when
@@ -370,8 +423,12 @@ func (vfs *OsFS) Rel(basepath, targpath string) (string, error) {
 
 // Remove removes the named file or directory.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Remove(name string) error {
-	return os.Remove(name)
+func (vfs *OsFS) Remove(name string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRemove, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Remove(name) })
 }
 
 // RemoveAll removes path and any children it contains.
@@ -379,16 +436,24 @@ func (vfs *OsFS) Remove(name string) error {
 // it encounters. If the path does not exist, RemoveAll
 // returns nil (no error).
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) RemoveAll(path string) error {
-	return os.RemoveAll(path)
+func (vfs *OsFS) RemoveAll(path string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRemoveAll, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.RemoveAll(path) })
 }
 
 // Rename renames (moves) oldpath to newpath.
 // If newpath already exists and is not a directory, Rename replaces it.
 // OS-specific restrictions may apply when oldpath and newpath are in different directories.
 // If there is an error, it will be of type *LinkError.
-func (vfs *OsFS) Rename(oldpath, newpath string) error {
-	return os.Rename(oldpath, newpath)
+func (vfs *OsFS) Rename(oldpath, newpath string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRename, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Rename(oldpath, newpath) })
 }
 
 // SameFile reports whether fi1 and fi2 describe the same file.
@@ -408,12 +473,30 @@ func (vfs *OsFS) SetUMask(mask fs.FileMode) error {
 
 // SetUser sets the current user.
 // If the user can't be changed an error is returned.
+// If the file system impersonates users (see Options.Impersonate), the user of the process
+// is not changed and the process must be run as root, otherwise avfs.ErrPermDenied is returned.
 func (vfs *OsFS) SetUser(user avfs.UserReader) error {
 	if !vfs.HasFeature(avfs.FeatIdentityMgr) {
 		return avfs.ErrPermDenied
 	}
 
-	return osidm.SetUser(user)
+	if !vfs.impersonate {
+		return osidm.SetUser(user)
+	}
+
+	if os.Geteuid() != 0 {
+		return avfs.ErrPermDenied
+	}
+
+	if user.Uid() == os.Geteuid() && user.Gid() == os.Getegid() {
+		vfs.user = nil
+
+		return nil
+	}
+
+	vfs.user = user
+
+	return nil
 }
 
 // SetUserByName sets and returns the current user.
@@ -423,13 +506,32 @@ func (vfs *OsFS) SetUserByName(name string) error {
 		return avfs.ErrPermDenied
 	}
 
-	return osidm.SetUserByName(name)
+	if !vfs.impersonate {
+		return osidm.SetUserByName(name)
+	}
+
+	u, err := vfs.Idm().LookupUser(name)
+	if err != nil {
+		return err
+	}
+
+	return vfs.SetUser(u)
+}
+
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *OsFS) SetUserByUid(uid, gid int) error {
+	return avfs.SetUserByUid(vfs, uid, gid)
 }
 
 // Stat returns a FileInfo describing the named file.
 // If there is an error, it will be of type *PathError.
-func (vfs *OsFS) Stat(name string) (fs.FileInfo, error) {
-	return os.Stat(name)
+func (vfs *OsFS) Stat(name string) (info fs.FileInfo, err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnStat, time.Now(), &err)
+	}
+
+	return asUser(vfs, func() (fs.FileInfo, error) { return os.Stat(name) })
 }
 
 // Split splits path immediately following the final Separator,
@@ -442,18 +544,33 @@ func (vfs *OsFS) Split(path string) (dir, file string) {
 }
 
 // Sub returns an FS corresponding to the subtree rooted at dir.
+// The sub file system is a BasePathFS rooted at dir, the paths can't escape from it
+// and symbolic links are not supported.
 func (vfs *OsFS) Sub(dir string) (avfs.VFS, error) {
 	const op = "sub"
 
-	return nil, &fs.PathError{Op: op, Path: dir, Err: vfs.permDeniedError}
+	subFS, err := basepathfs.NewWithErr(vfs, dir)
+	if err != nil {
+		if e, ok := err.(*fs.PathError); ok {
+			err = e.Err
+		}
+
+		return nil, &fs.PathError{Op: op, Path: dir, Err: err}
+	}
+
+	return subFS, nil
 }
 
 // Symlink creates newname as a symbolic link to oldname.
 // On Windows, a symlink to a non-existent oldname creates a file symlink;
 // if oldname is later created as a directory the symlink will not work.
 // If there is an error, it will be of type *LinkError.
-func (vfs *OsFS) Symlink(oldname, newname string) error {
-	return os.Symlink(oldname, newname)
+func (vfs *OsFS) Symlink(oldname, newname string) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnSymlink, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Symlink(oldname, newname) })
 }
 
 // TempDir returns the default directory to use for temporary files.
@@ -478,8 +595,12 @@ func (vfs *OsFS) ToSlash(path string) string {
 
 // Truncate changes the size of the named file.
 // If the file is a symbolic link, it changes the size of the link's target.
-func (vfs *OsFS) Truncate(name string, size int64) error {
-	return os.Truncate(name, size)
+func (vfs *OsFS) Truncate(name string, size int64) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnTruncate, time.Now(), &err)
+	}
+
+	return vfs.asUserErr(func() error { return os.Truncate(name, size) })
 }
 
 // UMask returns the file mode creation mask.
@@ -489,6 +610,10 @@ func (vfs *OsFS) UMask() fs.FileMode {
 
 // User returns the current user.
 func (vfs *OsFS) User() avfs.UserReader {
+	if u := vfs.user; u != nil {
+		return u
+	}
+
 	return osidm.User()
 }
 
@@ -504,6 +629,11 @@ func (vfs *OsFS) User() avfs.UserReader {
 //
 // WalkDir does not follow symbolic links.
 func (vfs *OsFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	// fn is called from the goroutine of the caller, only the file system operations are impersonated.
+	if vfs.user != nil {
+		return avfs.WalkDir(vfs, root, fn)
+	}
+
 	return filepath.WalkDir(root, fn)
 }
 
@@ -511,5 +641,5 @@ func (vfs *OsFS) WalkDir(root string, fn fs.WalkDirFunc) error {
 // If the file does not exist, WriteFile creates it with permissions perm (before umask);
 // otherwise WriteFile truncates it before writing, without changing permissions.
 func (vfs *OsFS) WriteFile(filename string, data []byte, perm fs.FileMode) error {
-	return os.WriteFile(filename, data, perm)
+	return vfs.asUserErr(func() error { return os.WriteFile(filename, data, perm) })
 }
@@ -17,6 +17,8 @@
 package osfs
 
 import (
+	"os"
+
 	"github.com/avfs/avfs"
 	"github.com/avfs/avfs/idm/osidm"
 )
@@ -44,8 +46,8 @@ func NewWithOptions(opts *Options) *OsFS {
 		idm = avfs.NotImplementedIdm
 	}
 
-	features := avfs.FeatRealFS | avfs.FeatSymlink | avfs.FeatHardlink | idm.Features()
-	vfs := &OsFS{}
+	features := avfs.FeatRealFS | avfs.FeatSubFS | avfs.FeatSymlink | avfs.FeatHardlink | idm.Features()
+	vfs := &OsFS{impersonate: opts.Impersonate}
 
 	_ = vfs.SetFeatures(features)
 	_ = vfs.SetIdm(idm)
@@ -73,3 +75,40 @@ func (*OsFS) Name() string {
 func (*OsFS) Type() string {
 	return "OsFS"
 }
+
+// asUser returns the results of fn called with the file system credentials of the impersonated user
+// (see Options.Impersonate), fn is called directly if no user is impersonated.
+func asUser[T any](vfs *OsFS, fn func() (T, error)) (res T, err error) {
+	u := vfs.user
+	if u == nil {
+		return fn()
+	}
+
+	if rerr := runAs(u, func() { res, err = fn() }); rerr != nil {
+		return res, rerr
+	}
+
+	return res, err
+}
+
+// openAsUser returns the file opened by fn with the file system credentials of the impersonated user,
+// the file is then wrapped in an OsFile to change its metadata with the same credentials.
+func (vfs *OsFS) openAsUser(fn func() (*os.File, error)) (avfs.File, error) {
+	if vfs.user == nil {
+		return fn()
+	}
+
+	f, err := asUser(vfs, fn)
+	if err != nil {
+		return f, err
+	}
+
+	return &OsFile{File: f, vfs: vfs}, nil
+}
+
+// asUserErr returns the error of fn called with the file system credentials of the impersonated user.
+func (vfs *OsFS) asUserErr(fn func() error) error {
+	_, err := asUser(vfs, func() (struct{}, error) { return struct{}{}, fn() })
+
+	return err
+}
@@ -0,0 +1,40 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package osfs
+
+import (
+	"io/fs"
+)
+
+// Chmod changes the mode of the file to mode with the file system credentials of the impersonated user.
+// If there is an error, it will be of type *PathError.
+func (f *OsFile) Chmod(mode fs.FileMode) error {
+	return f.vfs.asUserErr(func() error { return f.File.Chmod(mode) })
+}
+
+// Chown changes the numeric uid and gid of the file with the file system credentials of the impersonated user.
+// If there is an error, it will be of type *PathError.
+func (f *OsFile) Chown(uid, gid int) error {
+	return f.vfs.asUserErr(func() error { return f.File.Chown(uid, gid) })
+}
+
+// Truncate changes the size of the file with the file system credentials of the impersonated user.
+// It does not change the I/O offset.
+// If there is an error, it will be of type *PathError.
+func (f *OsFile) Truncate(size int64) error {
+	return f.vfs.asUserErr(func() error { return f.File.Truncate(size) })
+}
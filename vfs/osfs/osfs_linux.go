@@ -20,9 +20,13 @@ package osfs
 
 import (
 	"io/fs"
+	"os"
+	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/avfs/avfs"
+	"golang.org/x/sys/unix"
 )
 
 // Chroot changes the root to that specified in path.
@@ -42,6 +46,122 @@ func (vfs *OsFS) Chroot(path string) error {
 	return nil
 }
 
+// runAs calls fn on a dedicated thread using the file system credentials of user u (see setfsuid(2)),
+// the supplementary groups of the thread are the groups of u if it implements avfs.GroupMember, none otherwise.
+// The thread is never unlocked from its goroutine, so it is terminated when fn returns
+// and its credentials are never used by other goroutines.
+func runAs(u avfs.UserReader, fn func()) (err error) {
+	var groups []int
+	if gm, ok := u.(avfs.GroupMember); ok {
+		groups = gm.Groups()
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		runtime.LockOSThread()
+
+		// syscall.Setgroups changes the groups of all the threads, unix.Setgroups only changes the current one.
+		if err = unix.Setgroups(groups); err != nil {
+			return
+		}
+
+		if err = unix.Setfsgid(u.Gid()); err != nil {
+			return
+		}
+
+		if err = unix.Setfsuid(u.Uid()); err != nil {
+			return
+		}
+
+		fn()
+	}()
+
+	<-done
+
+	return err
+}
+
+// Lchtimes changes the access and modification times of the named file like Chtimes,
+// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+// A zero time.Time value leaves the corresponding file time unchanged.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Lchtimes(name string, atime, mtime time.Time) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLchtimes, time.Now(), &err)
+	}
+
+	const op = "lchtimes"
+
+	var ts [2]unix.Timespec
+
+	for i, t := range [2]time.Time{atime, mtime} {
+		if t.IsZero() {
+			ts[i] = unix.Timespec{Nsec: unix.UTIME_OMIT}
+
+			continue
+		}
+
+		ts[i] = unix.NsecToTimespec(t.UnixNano())
+	}
+
+	err = vfs.asUserErr(func() error {
+		return unix.UtimesNanoAt(unix.AT_FDCWD, name, ts[:], unix.AT_SYMLINK_NOFOLLOW)
+	})
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return nil
+}
+
+// Rename2 renames (moves) oldpath to newpath like Rename, flags modifies its behavior :
+// with RenameNoReplace it fails if newpath already exists,
+// with RenameExchange it atomically exchanges oldpath and newpath, both of them must exist.
+// RenameNoReplace and RenameExchange can't be combined.
+// If there is an error, it will be of type *LinkError.
+func (vfs *OsFS) Rename2(oldpath, newpath string, flags avfs.RenameFlag) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRename, time.Now(), &err)
+	}
+
+	if flags == 0 {
+		return vfs.asUserErr(func() error { return os.Rename(oldpath, newpath) })
+	}
+
+	const op = "rename"
+
+	if flags&^(avfs.RenameNoReplace|avfs.RenameExchange) != 0 {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: avfs.ErrInvalidArgument}
+	}
+
+	err = vfs.asUserErr(func() error {
+		return unix.Renameat2(unix.AT_FDCWD, oldpath, unix.AT_FDCWD, newpath, renameFlags(flags))
+	})
+	if err != nil {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	return nil
+}
+
+// renameFlags returns the flags of renameat2(2) corresponding to flags.
+func renameFlags(flags avfs.RenameFlag) uint {
+	var rf uint
+
+	if flags&avfs.RenameNoReplace != 0 {
+		rf |= unix.RENAME_NOREPLACE
+	}
+
+	if flags&avfs.RenameExchange != 0 {
+		rf |= unix.RENAME_EXCHANGE
+	}
+
+	return rf
+}
+
 // ToSysStat takes a value from fs.FileInfo.Sys() and returns a value that implements interface avfs.SysStater.
 func (vfs *OsFS) ToSysStat(info fs.FileInfo) avfs.SysStater {
 	return &LinuxSysStat{Sys: info.Sys().(*syscall.Stat_t)} //nolint:forcetypeassert // type assertion must be checked
@@ -52,6 +172,21 @@ type LinuxSysStat struct {
 	Sys *syscall.Stat_t
 }
 
+// Atime returns the last access time of the file.
+func (lst *LinuxSysStat) Atime() time.Time {
+	return time.Unix(lst.Sys.Atim.Unix())
+}
+
+// Ctime returns the last change time of the file.
+func (lst *LinuxSysStat) Ctime() time.Time {
+	return time.Unix(lst.Sys.Ctim.Unix())
+}
+
+// Dev returns the device id of the file system containing the file.
+func (lst *LinuxSysStat) Dev() uint64 {
+	return uint64(lst.Sys.Dev) //nolint:unconvert // required for 32 bits systems.
+}
+
 // Gid returns the group id.
 func (lst *LinuxSysStat) Gid() int {
 	return int(lst.Sys.Gid)
@@ -62,7 +197,76 @@ func (lst *LinuxSysStat) Uid() int {
 	return int(lst.Sys.Uid)
 }
 
+// Ino returns the inode number of the file.
+func (lst *LinuxSysStat) Ino() uint64 {
+	return uint64(lst.Sys.Ino) //nolint:unconvert // required for 32 bits systems.
+}
+
 // Nlink returns the number of hard links.
 func (lst *LinuxSysStat) Nlink() uint64 {
 	return uint64(lst.Sys.Nlink) //nolint:unconvert // required for 32 bits systems.
 }
+
+// Rdev returns the device number of a device file, 0 for other files.
+func (lst *LinuxSysStat) Rdev() uint64 {
+	return uint64(lst.Sys.Rdev) //nolint:unconvert // required for 32 bits systems.
+}
+
+// Lock places an exclusive advisory lock on the open file f with flock, waiting until no other file holds a lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Lock(f avfs.File) error {
+	_, err := flock(f, syscall.LOCK_EX)
+
+	return err
+}
+
+// RLock places a shared advisory lock on the open file f with flock, waiting until no other file holds an exclusive lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) RLock(f avfs.File) error {
+	_, err := flock(f, syscall.LOCK_SH)
+
+	return err
+}
+
+// TryLock tries to place an exclusive advisory lock on the open file f with flock without waiting,
+// it returns false if another file holds a lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) TryLock(f avfs.File) (bool, error) {
+	return flock(f, syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// Unlock removes the advisory lock held by the open file f with flock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Unlock(f avfs.File) error {
+	_, err := flock(f, syscall.LOCK_UN)
+
+	return err
+}
+
+// flock applies the operation how to the open file f,
+// it returns false if the file is already locked and how contains LOCK_NB.
+func flock(f avfs.File, how int) (bool, error) {
+	const op = "flock"
+
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+
+		switch err {
+		case nil:
+			return true, nil
+		case syscall.EINTR:
+			continue
+		case syscall.EWOULDBLOCK:
+			if how&syscall.LOCK_NB != 0 {
+				return false, nil
+			}
+		}
+
+		return false, &fs.PathError{Op: op, Path: f.Name(), Err: err}
+	}
+}
+
+// openTmpFile opens an unnamed temporary file in the directory name (O_TMPFILE flag).
+func openTmpFile(name string, flag int, perm fs.FileMode) (*os.File, error) {
+	return os.OpenFile(name, flag, perm)
+}
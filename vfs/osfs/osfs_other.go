@@ -21,6 +21,8 @@ package osfs
 import (
 	"io/fs"
 	"math"
+	"os"
+	"time"
 
 	"github.com/avfs/avfs"
 )
@@ -33,6 +35,32 @@ func (vfs *OsFS) Chroot(path string) error {
 	return &fs.PathError{Op: op, Path: path, Err: avfs.ErrOpNotPermitted}
 }
 
+// runAs calls fn, users can't be impersonated on this OS.
+func runAs(_ avfs.UserReader, fn func()) error {
+	fn()
+
+	return nil
+}
+
+// Rename2 renames (moves) oldpath to newpath like Rename, flags modifies its behavior :
+// with RenameNoReplace it fails if newpath already exists,
+// with RenameExchange it atomically exchanges oldpath and newpath, both of them must exist.
+// RenameNoReplace and RenameExchange can't be combined.
+// If there is an error, it will be of type *LinkError.
+func (vfs *OsFS) Rename2(oldpath, newpath string, flags avfs.RenameFlag) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRename, time.Now(), &err)
+	}
+
+	const op = "rename"
+
+	if flags != 0 {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: avfs.ErrNotSupported}
+	}
+
+	return os.Rename(oldpath, newpath)
+}
+
 // ToSysStat takes a value from fs.FileInfo.Sys() and returns a value that implements interface avfs.SysStater.
 func (vfs *OsFS) ToSysStat(info fs.FileInfo) avfs.SysStater {
 	return &OtherSysStat{gid: math.MaxInt, uid: math.MaxInt}
@@ -58,3 +86,44 @@ func (oss *OtherSysStat) Uid() int {
 func (oss *OtherSysStat) Nlink() uint64 {
 	return 1
 }
+
+// Lock places an exclusive advisory lock on the open file f, waiting until no other file holds a lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Lock(f avfs.File) error {
+	const op = "flock"
+
+	return &fs.PathError{Op: op, Path: f.Name(), Err: avfs.ErrNotSupported}
+}
+
+// RLock places a shared advisory lock on the open file f, waiting until no other file holds an exclusive lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) RLock(f avfs.File) error {
+	const op = "flock"
+
+	return &fs.PathError{Op: op, Path: f.Name(), Err: avfs.ErrNotSupported}
+}
+
+// TryLock tries to place an exclusive advisory lock on the open file f without waiting,
+// it returns false if another file holds a lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) TryLock(f avfs.File) (bool, error) {
+	const op = "flock"
+
+	return false, &fs.PathError{Op: op, Path: f.Name(), Err: avfs.ErrNotSupported}
+}
+
+// Unlock removes the advisory lock held by the open file f.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Unlock(f avfs.File) error {
+	const op = "flock"
+
+	return &fs.PathError{Op: op, Path: f.Name(), Err: avfs.ErrNotSupported}
+}
+
+// openTmpFile opens an unnamed temporary file in the directory name (O_TMPFILE flag).
+// Unnamed temporary files are only supported on Linux.
+func openTmpFile(name string, _ int, _ fs.FileMode) (*os.File, error) {
+	const op = "open"
+
+	return nil, &fs.PathError{Op: op, Path: name, Err: avfs.ErrNotSupported}
+}
@@ -20,9 +20,12 @@ package osfs_test
 
 import (
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/idm/osidm"
 	"github.com/avfs/avfs/test"
 	"github.com/avfs/avfs/vfs/osfs"
 )
@@ -34,8 +37,23 @@ var (
 	// Tests that osfs.OsFS struct implements avfs.VFSBase interface.
 	_ avfs.VFSBase = &osfs.OsFS{}
 
+	// Tests that osfs.OsFS struct implements avfs.Renamer2 interface.
+	_ avfs.Renamer2 = &osfs.OsFS{}
+
+	// Tests that osfs.OsFS struct implements avfs.Sampler interface.
+	_ avfs.Sampler = &osfs.OsFS{}
+
+	// Tests that osfs.OsFS struct implements avfs.WatcherMaker interface.
+	_ avfs.WatcherMaker = &osfs.OsFS{}
+
+	// Tests that osfs.OsWatcher struct implements avfs.Watcher interface.
+	_ avfs.Watcher = &osfs.OsWatcher{}
+
 	// Tests that os.File struct implements avfs.File interface.
 	_ avfs.File = &os.File{}
+
+	// Tests that osfs.OsFile struct implements avfs.File interface.
+	_ avfs.File = &osfs.OsFile{}
 )
 
 func TestOsFS(t *testing.T) {
@@ -52,10 +70,108 @@ func TestOsFSWithNoIdm(t *testing.T) {
 	ts.TestVFSAll(t)
 }
 
+func TestOsFSImpersonate(t *testing.T) {
+	vfs := osfs.NewWithOptions(&osfs.Options{Idm: osidm.New(), Impersonate: true})
+	if !vfs.HasFeature(avfs.FeatIdentityMgr) || !vfs.User().IsAdmin() {
+		t.Skip("Impersonate : the process must be run as root on Linux, skipping")
+	}
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+
+	t.Run("ImpersonateIsolation", func(t *testing.T) {
+		rootDir := t.TempDir()
+
+		dir := vfs.Join(rootDir, "private")
+
+		err := vfs.Mkdir(dir, 0o700)
+		test.RequireNoError(t, err, "Mkdir %s", dir)
+
+		usrFS := osfs.NewWithOptions(&osfs.Options{Idm: vfs.Idm(), Impersonate: true})
+
+		err = usrFS.SetUserByName(test.UsrTest)
+		test.RequireNoError(t, err, "SetUserByName %s", test.UsrTest)
+
+		if name := usrFS.User().Name(); name != test.UsrTest {
+			t.Errorf("User : want user to be %s, got %s", test.UsrTest, name)
+		}
+
+		if !osidm.User().IsAdmin() {
+			t.Errorf("User : want the user of the process to be an admin, got %s", osidm.User().Name())
+		}
+
+		_, err = usrFS.ReadDir(dir)
+		test.AssertPathError(t, err).Op("open").Path(dir).Err(avfs.ErrPermDenied).Test()
+
+		_, err = vfs.ReadDir(dir)
+		test.RequireNoError(t, err, "ReadDir %s", dir)
+
+		err = usrFS.SetUser(vfs.User())
+		test.RequireNoError(t, err, "SetUser %s", vfs.User().Name())
+
+		_, err = usrFS.ReadDir(dir)
+		test.RequireNoError(t, err, "ReadDir %s", dir)
+	})
+
+	t.Run("ImpersonateFile", func(t *testing.T) {
+		dir, err := vfs.MkdirTemp("", "impersonate")
+		test.RequireNoError(t, err, "MkdirTemp")
+
+		defer vfs.RemoveAll(dir) //nolint:errcheck // Ignore errors.
+
+		err = vfs.Chmod(dir, 0o777)
+		test.RequireNoError(t, err, "Chmod %s", dir)
+
+		rootFile := vfs.Join(dir, "root")
+
+		err = vfs.WriteFile(rootFile, nil, 0o666)
+		test.RequireNoError(t, err, "WriteFile %s", rootFile)
+
+		err = vfs.Chmod(rootFile, 0o666)
+		test.RequireNoError(t, err, "Chmod %s", rootFile)
+
+		usrFS := osfs.NewWithOptions(&osfs.Options{Idm: vfs.Idm(), Impersonate: true})
+
+		err = usrFS.SetUserByName(test.UsrTest)
+		test.RequireNoError(t, err, "SetUserByName %s", test.UsrTest)
+
+		usrFile := vfs.Join(dir, "user")
+
+		f, err := usrFS.Create(usrFile)
+		test.RequireNoError(t, err, "Create %s", usrFile)
+
+		defer f.Close()
+
+		err = f.Chown(0, 0)
+		test.AssertPathError(t, err).Op("chown").Path(usrFile).Err(avfs.ErrOpNotPermitted).Test()
+
+		f, err = usrFS.OpenFile(rootFile, os.O_RDWR, 0)
+		test.RequireNoError(t, err, "OpenFile %s", rootFile)
+
+		defer f.Close()
+
+		err = f.Chmod(0o777)
+		test.AssertPathError(t, err).Op("chmod").Path(rootFile).Err(avfs.ErrOpNotPermitted).Test()
+
+		for _, path := range []string{usrFile, rootFile} {
+			info, err := vfs.Stat(path)
+			test.RequireNoError(t, err, "Stat %s", path)
+
+			if info.Mode() != 0o666 && info.Mode() != 0o644 {
+				t.Errorf("Stat %s : want mode to be unchanged, got %s", path, info.Mode())
+			}
+
+			if path == usrFile && vfs.ToSysStat(info).Uid() == 0 {
+				t.Errorf("Stat %s : want the owner to be unchanged, got root", path)
+			}
+		}
+	})
+}
+
 func TestOsFSConfig(t *testing.T) {
 	vfs := osfs.New()
 
-	wantFeatures := avfs.FeatHardlink | avfs.FeatRealFS | avfs.FeatSymlink
+	wantFeatures := avfs.FeatHardlink | avfs.FeatRealFS | avfs.FeatSubFS | avfs.FeatSymlink
 	if vfs.OSType() == avfs.OsLinux {
 		wantFeatures |= avfs.FeatIdentityMgr
 	}
@@ -80,8 +196,122 @@ func TestOsFSConfig(t *testing.T) {
 }
 
 func BenchmarkOsFSAll(b *testing.B) {
+	test.BenchAll(b, osfs.New())
+}
+
+func TestOsFSInNamespace(t *testing.T) {
+	if !test.RunInNamespace(t) {
+		return
+	}
+
+	vfs := osfs.New()
+	if !vfs.User().IsAdmin() {
+		t.Fatalf("User : want user to be an admin, got %s", vfs.User().Name())
+	}
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
+func TestIsolatedOsFS(t *testing.T) {
+	vfs := test.IsolatedOsFS(t)
+	baseFS := osfs.New()
+
+	for _, dir := range []string{vfs.TempDir(), avfs.HomeDirUser(vfs, "", vfs.User())} {
+		info, err := baseFS.Stat(vfs.ToBasePath(dir))
+		test.RequireNoError(t, err, "Stat %s", dir)
+
+		if !info.IsDir() {
+			t.Errorf("Stat %s : want a directory, got %s", dir, info.Mode())
+		}
+	}
+
+	rootDir := vfs.ToBasePath("/")
+	escapes := []string{
+		vfs.Join(string(vfs.PathSeparator()), "..", "escaped"),
+		vfs.Join(vfs.TempDir(), "..", "..", "..", "escaped"),
+		vfs.Join("..", "..", "..", "escaped"),
+	}
+
+	for _, path := range escapes {
+		err := vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+
+		_, err = baseFS.Stat(baseFS.Join(rootDir, "escaped"))
+		test.RequireNoError(t, err, "Stat %s", path)
+
+		_, err = baseFS.Stat(baseFS.Join(baseFS.Dir(rootDir), "escaped"))
+		test.AssertPathError(t, err).OSType(avfs.OsLinux).Err(avfs.ErrNoSuchFileOrDir).Test().
+			OSType(avfs.OsWindows).Err(avfs.ErrWinFileNotFound).Test()
+
+		err = vfs.Remove(path)
+		test.RequireNoError(t, err, "Remove %s", path)
+	}
+}
+
+func TestOsFSMove(t *testing.T) {
+	srcFS, dstFS := osfs.New(), osfs.New()
+	rootDir := t.TempDir()
+	srcFile := srcFS.Join(rootDir, "src")
+	data := []byte("data")
+
+	err := srcFS.WriteFile(srcFile, data, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", srcFile)
+
+	err = avfs.Move(dstFS, srcFS, srcFile, srcFile)
+	test.AssertLinkError(t, err).Op("move").Old(srcFile).New(srcFile).Err(avfs.ErrSameFile).Test()
+
+	dstFile := dstFS.Join(rootDir, "dst")
+
+	err = avfs.Move(dstFS, srcFS, dstFile, srcFile)
+	test.RequireNoError(t, err, "Move %s %s", srcFile, dstFile)
+
+	gotData, err := dstFS.ReadFile(dstFile)
+	test.RequireNoError(t, err, "ReadFile %s", dstFile)
+
+	if string(gotData) != string(data) {
+		t.Errorf("ReadFile %s : want data to be %s, got %s", dstFile, data, gotData)
+	}
+}
+
+func TestOsFSWatcherOverflow(t *testing.T) {
 	vfs := osfs.New()
+	rootDir := t.TempDir()
+
+	w, err := vfs.NewWatcher()
+	test.RequireNoError(t, err, "NewWatcher")
+
+	defer w.Close()
 
-	ts := test.NewSuiteFS(b, vfs, vfs)
-	ts.BenchAll(b)
+	err = w.Add(rootDir)
+	test.RequireNoError(t, err, "Add %s", rootDir)
+
+	// The events are not received while the files are created, the oldest ones are dropped.
+	for i := range 1000 {
+		path := vfs.Join(rootDir, "file"+strconv.Itoa(i))
+
+		err = vfs.WriteFile(path, nil, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", path)
+	}
+
+	select {
+	case err = <-w.Errors():
+		if err != avfs.ErrWatchOverflow {
+			t.Errorf("Errors : want error to be %v, got %v", avfs.ErrWatchOverflow, err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Errors : want error to be %v, got no error", avfs.ErrWatchOverflow)
+	}
+
+	err = w.Close()
+	test.RequireNoError(t, err, "Close")
+
+	n := 0
+	for range w.Events() {
+		n++
+	}
+
+	if n == 0 || n > 256 {
+		t.Errorf("Events : want at most 256 pending events, got %d", n)
+	}
 }
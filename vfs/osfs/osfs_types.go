@@ -17,17 +17,43 @@
 package osfs
 
 import (
+	"os"
+
 	"github.com/avfs/avfs"
+	"github.com/fsnotify/fsnotify"
 )
 
+// Maximum number of pending events of a watcher.
+const watchEventsMax = 256
+
 // OsFS represents the current file system.
 type OsFS struct {
-	permDeniedError error // Permission denied error.
-	avfs.IdmFn            // IdmFn provides identity manager functions to a file system.
-	avfs.FeaturesFn       // FeaturesFn provides features functions to a file system or an identity manager.
+	user            avfs.UserReader // user is the impersonated user, nil if the operations run as the user of the process.
+	permDeniedError error           // Permission denied error.
+	impersonate     bool            // impersonate is true if SetUser impersonates users instead of changing the user of the process.
+	avfs.IdmFn                      // IdmFn provides identity manager functions to a file system.
+	avfs.FeaturesFn                 // FeaturesFn provides features functions to a file system or an identity manager.
+	avfs.SampleFn                   // SampleFn provides an operation sampling hook to a file system.
+}
+
+// OsFile is an open file of an OsFS impersonating a user (see Options.Impersonate).
+// The permissions to read or write the file are checked when it is opened,
+// the methods changing its metadata use the file system credentials of the impersonated user.
+type OsFile struct {
+	*os.File       // File is the open file.
+	vfs      *OsFS // vfs is the file system of the file.
 }
 
 // Options defines the initialization options of OsFS.
 type Options struct {
-	Idm avfs.IdentityMgr // Idm is the identity manager of the file system.
+	Idm         avfs.IdentityMgr // Idm is the identity manager of the file system.
+	Impersonate bool             // Impersonate makes SetUser change the user of the file system and not of the process (Linux only, root required).
+}
+
+// OsWatcher is the implementation of avfs.Watcher using fsnotify returned by OsFS.NewWatcher.
+type OsWatcher struct {
+	fw      *fsnotify.Watcher    // fw is the fsnotify watcher.
+	events  chan avfs.WatchEvent // events is the channel receiving the changes.
+	errors  chan error           // errors is the channel receiving the errors.
+	stopped chan struct{}        // stopped is closed once the events are not read anymore.
 }
@@ -0,0 +1,68 @@
+//
+//  Copyright 2022 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build aix || darwin || dragonfly || freebsd || netbsd || openbsd || solaris
+
+package osfs
+
+import (
+	"io/fs"
+	"time"
+
+	"github.com/avfs/avfs"
+	"golang.org/x/sys/unix"
+)
+
+// Lchtimes changes the access and modification times of the named file like Chtimes,
+// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+// A zero time.Time value leaves the corresponding file time unchanged.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Lchtimes(name string, atime, mtime time.Time) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLchtimes, time.Now(), &err)
+	}
+
+	const op = "lchtimes"
+
+	err = vfs.asUserErr(func() error {
+		var ts [2]unix.Timespec
+
+		// UTIME_OMIT is not defined on all these OSes, the times left unchanged are set to their current value.
+		if atime.IsZero() || mtime.IsZero() {
+			var st unix.Stat_t
+
+			err := unix.Lstat(name, &st)
+			if err != nil {
+				return err
+			}
+
+			ts = [2]unix.Timespec{st.Atim, st.Mtim}
+		}
+
+		for i, t := range [2]time.Time{atime, mtime} {
+			if !t.IsZero() {
+				ts[i] = unix.NsecToTimespec(t.UnixNano())
+			}
+		}
+
+		return unix.UtimesNanoAt(unix.AT_FDCWD, name, ts[:], unix.AT_SYMLINK_NOFOLLOW)
+	})
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return nil
+}
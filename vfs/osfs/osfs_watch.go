@@ -0,0 +1,188 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package osfs
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+
+	"github.com/avfs/avfs"
+	"github.com/fsnotify/fsnotify"
+)
+
+// NewWatcher returns a new Watcher of the changes of files and directories using fsnotify
+// (inotify on Linux, kqueue on BSD and macOS, ReadDirectoryChangesW on Windows).
+// If the receiver is too slow, the oldest pending events are dropped and avfs.ErrWatchOverflow is sent on the Errors channel.
+func (vfs *OsFS) NewWatcher() (avfs.Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &OsWatcher{
+		fw:      fw,
+		events:  make(chan avfs.WatchEvent, watchEventsMax),
+		errors:  make(chan error, 1),
+		stopped: make(chan struct{}),
+	}
+
+	go w.readEvents()
+
+	return w, nil
+}
+
+// Add starts watching the named file or directory.
+// The changes of a directory and of its direct children are reported, the subdirectories are not watched.
+// If there is an error, it will be of type *PathError.
+func (w *OsWatcher) Add(name string) error {
+	const op = "inotify_add_watch"
+
+	err := w.fw.Add(name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return nil
+}
+
+// Close removes all the watches and closes the Events and Errors channels.
+func (w *OsWatcher) Close() error {
+	err := w.fw.Close()
+
+	<-w.stopped
+
+	return err
+}
+
+// Errors returns the channel receiving the errors of the watcher, like a lost event.
+func (w *OsWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Events returns the channel receiving the changes of the watched files and directories.
+func (w *OsWatcher) Events() <-chan avfs.WatchEvent {
+	return w.events
+}
+
+// Remove stops watching the named file or directory.
+// If there is an error, it will be of type *PathError.
+func (w *OsWatcher) Remove(name string) error {
+	const op = "inotify_rm_watch"
+
+	err := w.fw.Remove(name)
+	if err != nil {
+		if errors.Is(err, fsnotify.ErrNonExistentWatch) {
+			err = syscall.EINVAL
+		}
+
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return nil
+}
+
+// readEvents converts the fsnotify events and errors and sends them without blocking
+// until the fsnotify watcher is closed.
+func (w *OsWatcher) readEvents() {
+	defer func() {
+		close(w.events)
+		close(w.errors)
+		close(w.stopped)
+	}()
+
+	fwEvents, fwErrors := w.fw.Events, w.fw.Errors
+
+	for fwEvents != nil || fwErrors != nil {
+		select {
+		case fe, ok := <-fwEvents:
+			if !ok {
+				fwEvents = nil
+
+				continue
+			}
+
+			if op := watchOp(fe.Op); op != 0 {
+				w.sendEvent(avfs.WatchEvent{Name: fe.Name, Op: op})
+			}
+		case err, ok := <-fwErrors:
+			if !ok {
+				fwErrors = nil
+
+				continue
+			}
+
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				err = avfs.ErrWatchOverflow
+			}
+
+			w.sendError(err)
+		}
+	}
+}
+
+// sendEvent sends an event without blocking, the oldest pending event is dropped if the channel is full.
+func (w *OsWatcher) sendEvent(event avfs.WatchEvent) {
+	for {
+		select {
+		case w.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-w.events:
+			w.sendError(avfs.ErrWatchOverflow)
+		default:
+		}
+	}
+}
+
+// sendError sends an error without blocking, it is dropped if an error is already pending.
+func (w *OsWatcher) sendError(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// watchOp returns the operations of a fsnotify operation.
+func watchOp(fop fsnotify.Op) avfs.WatchOp {
+	var op avfs.WatchOp
+
+	if fop.Has(fsnotify.Create) {
+		op |= avfs.WatchCreate
+	}
+
+	if fop.Has(fsnotify.Write) {
+		op |= avfs.WatchWrite
+	}
+
+	if fop.Has(fsnotify.Remove) {
+		op |= avfs.WatchRemove
+	}
+
+	if fop.Has(fsnotify.Rename) {
+		op |= avfs.WatchRename
+	}
+
+	if fop.Has(fsnotify.Chmod) {
+		op |= avfs.WatchChmod
+	}
+
+	return op
+}
@@ -21,6 +21,10 @@ package osfs
 import (
 	"io/fs"
 	"math"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
 
 	"github.com/avfs/avfs"
 )
@@ -33,6 +37,98 @@ func (vfs *OsFS) Chroot(path string) error {
 	return &fs.PathError{Op: op, Path: path, Err: avfs.ErrWinNotSupported}
 }
 
+// runAs calls fn, users can't be impersonated on this OS.
+func runAs(_ avfs.UserReader, fn func()) error {
+	fn()
+
+	return nil
+}
+
+// Lchtimes changes the access and modification times of the named file like Chtimes,
+// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+// A zero time.Time value leaves the corresponding file time unchanged.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Lchtimes(name string, atime, mtime time.Time) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnLchtimes, time.Now(), &err)
+	}
+
+	const op = "lchtimes"
+
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	// FILE_FLAG_OPEN_REPARSE_POINT opens the symbolic link itself instead of its target.
+	h, err := syscall.CreateFile(p, syscall.FILE_WRITE_ATTRIBUTES,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE, nil, syscall.OPEN_EXISTING,
+		syscall.FILE_FLAG_BACKUP_SEMANTICS|syscall.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var a, w *syscall.Filetime
+
+	if !atime.IsZero() {
+		ft := syscall.NsecToFiletime(atime.UnixNano())
+		a = &ft
+	}
+
+	if !mtime.IsZero() {
+		ft := syscall.NsecToFiletime(mtime.UnixNano())
+		w = &ft
+	}
+
+	err = syscall.SetFileTime(h, nil, a, w)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return nil
+}
+
+// Rename2 renames (moves) oldpath to newpath like Rename, flags modifies its behavior :
+// with RenameNoReplace it fails if newpath already exists,
+// with RenameExchange it atomically exchanges oldpath and newpath, both of them must exist.
+// RenameNoReplace and RenameExchange can't be combined.
+// If there is an error, it will be of type *LinkError.
+func (vfs *OsFS) Rename2(oldpath, newpath string, flags avfs.RenameFlag) (err error) {
+	if vfs.Sampling() {
+		defer vfs.Sample(avfs.FnRename, time.Now(), &err)
+	}
+
+	const op = "rename"
+
+	switch flags {
+	case 0:
+		return os.Rename(oldpath, newpath)
+	case avfs.RenameNoReplace:
+	default:
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: avfs.ErrWinNotSupported}
+	}
+
+	from, err := syscall.UTF16PtrFromString(oldpath)
+	if err != nil {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	to, err := syscall.UTF16PtrFromString(newpath)
+	if err != nil {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	// Unlike os.Rename, MoveFile without MOVEFILE_REPLACE_EXISTING never replaces newpath.
+	err = syscall.MoveFile(from, to)
+	if err != nil {
+		return &os.LinkError{Op: op, Old: oldpath, New: newpath, Err: err}
+	}
+
+	return nil
+}
+
 // ToSysStat takes a value from fs.FileInfo.Sys() and returns a value that implements interface avfs.SysStater.
 func (vfs *OsFS) ToSysStat(info fs.FileInfo) avfs.SysStater {
 	return &WindowsSysStat{gid: math.MaxInt, uid: math.MaxInt}
@@ -58,3 +154,82 @@ func (wss *WindowsSysStat) Uid() int {
 func (wss *WindowsSysStat) Nlink() uint64 {
 	return 1
 }
+
+// Flags and error of LockFileEx.
+const (
+	lockfileFailImmediately = 0x1  // LOCKFILE_FAIL_IMMEDIATELY
+	lockfileExclusiveLock   = 0x2  // LOCKFILE_EXCLUSIVE_LOCK
+	errLockViolation        = 0x21 // ERROR_LOCK_VIOLATION
+)
+
+var (
+	modKernel32      = syscall.NewLazyDLL("kernel32.dll")  //nolint:gochecknoglobals // Lazy loaded DLL.
+	procLockFileEx   = modKernel32.NewProc("LockFileEx")   //nolint:gochecknoglobals // Lazy loaded procedure.
+	procUnlockFileEx = modKernel32.NewProc("UnlockFileEx") //nolint:gochecknoglobals // Lazy loaded procedure.
+)
+
+// Lock places an exclusive lock on the open file f with LockFileEx, waiting until no other file holds a lock.
+// Unlike flock, the locks of Windows are mandatory : the locked file can't be read or written by other files.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Lock(f avfs.File) error {
+	_, err := lockFileEx(f, lockfileExclusiveLock)
+
+	return err
+}
+
+// RLock places a shared lock on the open file f with LockFileEx, waiting until no other file holds an exclusive lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) RLock(f avfs.File) error {
+	_, err := lockFileEx(f, 0)
+
+	return err
+}
+
+// TryLock tries to place an exclusive lock on the open file f with LockFileEx without waiting,
+// it returns false if another file holds a lock.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) TryLock(f avfs.File) (bool, error) {
+	return lockFileEx(f, lockfileExclusiveLock|lockfileFailImmediately)
+}
+
+// Unlock removes the lock held by the open file f with UnlockFileEx.
+// If there is an error, it will be of type *PathError.
+func (vfs *OsFS) Unlock(f avfs.File) error {
+	const op = "UnlockFileEx"
+
+	var ol syscall.Overlapped
+
+	r1, _, err := procUnlockFileEx.Call(f.Fd(), 0, math.MaxUint32, math.MaxUint32, uintptr(unsafe.Pointer(&ol)))
+	if r1 == 0 {
+		return &fs.PathError{Op: op, Path: f.Name(), Err: err}
+	}
+
+	return nil
+}
+
+// lockFileEx locks the whole open file f with the flags of LockFileEx,
+// it returns false if the file is already locked and flags contains LOCKFILE_FAIL_IMMEDIATELY.
+func lockFileEx(f avfs.File, flags uintptr) (bool, error) {
+	const op = "LockFileEx"
+
+	var ol syscall.Overlapped
+
+	r1, _, err := procLockFileEx.Call(f.Fd(), flags, 0, math.MaxUint32, math.MaxUint32, uintptr(unsafe.Pointer(&ol)))
+	if r1 == 0 {
+		if err == syscall.Errno(errLockViolation) && flags&lockfileFailImmediately != 0 {
+			return false, nil
+		}
+
+		return false, &fs.PathError{Op: op, Path: f.Name(), Err: err}
+	}
+
+	return true, nil
+}
+
+// openTmpFile opens an unnamed temporary file in the directory name (O_TMPFILE flag).
+// Unnamed temporary files are not supported on Windows.
+func openTmpFile(name string, _ int, _ fs.FileMode) (*os.File, error) {
+	const op = "open"
+
+	return nil, &fs.PathError{Op: op, Path: name, Err: avfs.ErrWinNotSupported}
+}
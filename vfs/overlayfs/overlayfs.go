@@ -0,0 +1,1000 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+// Package overlayfs implements a copy on write file system merging a read only lower file system
+// and a writable upper file system, similar to the Linux overlay file system.
+//
+// The files of the upper layer hide the files of the same path in the lower layer
+// and the directories of both layers are merged.
+// The lower layer is never modified : a file of the lower layer is copied up to the upper layer
+// before its first modification, a removed file of the lower layer is hidden by a whiteout
+// (an empty file named WhiteoutPrefix followed by the name of the removed file) and the content of
+// the lower directory replaced by a new directory is hidden by an opaque marker (see OpaqueMarker).
+// The names starting with WhiteoutPrefix are reserved and can't be created in the merged view.
+//
+// This allows to test "base image + modifications" scenarios without duplicating the base tree.
+package overlayfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/avfs/avfs"
+)
+
+// Abs returns an absolute representation of path.
+// If the path is not absolute it will be joined with the current
+// working directory to turn it into an absolute path. The absolute
+// path name for a given file is not guaranteed to be unique.
+// Abs calls [Clean] on the result.
+func (vfs *OverlayFS) Abs(path string) (string, error) {
+	return avfs.Abs(vfs, path, vfs.CurDir())
+}
+
+// Base returns the last element of path.
+// Trailing path separators are removed before extracting the last element.
+// If the path is empty, Base returns ".".
+// If the path consists entirely of separators, Base returns a single separator.
+func (vfs *OverlayFS) Base(path string) string {
+	return avfs.Base(vfs, path)
+}
+
+// Chdir changes the current working directory to the named directory.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Chdir(dir string) error {
+	const op = "chdir"
+
+	absPath := vfs.abs(dir)
+
+	_, info, _, err := vfs.follow(absPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return vfs.notFound(op, dir, absPath)
+		}
+
+		return &fs.PathError{Op: op, Path: dir, Err: err}
+	}
+
+	if err = vfs.checkPath(op, dir, info, true); err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		err = vfs.err.NotADirectory
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinDirNameInvalid
+		}
+
+		return &fs.PathError{Op: op, Path: dir, Err: err}
+	}
+
+	_ = vfs.SetCurDir(absPath)
+
+	return nil
+}
+
+// Chmod changes the mode of the named file to mode.
+// If the file is a symbolic link, it changes the mode of the link's target.
+// If there is an error, it will be of type *PathError.
+//
+// A different subset of the mode bits are used, depending on the
+// operating system.
+//
+// On Unix, the mode's permission bits, ModeSetuid, ModeSetgid, and
+// ModeSticky are used.
+//
+// On Windows, only the 0200 bit (owner writable) of mode is used; it
+// controls whether the file's read-only attribute is set or cleared.
+// The other bits are currently unused. For compatibility with Go 1.12
+// and earlier, use a non-zero mode. Use mode 0400 for a read-only
+// file and 0600 for a readable+writable file.
+//
+// On Plan 9, the mode's permission bits, ModeAppend, ModeExclusive,
+// and ModeTemporary are used.
+func (vfs *OverlayFS) Chmod(name string, mode fs.FileMode) error {
+	const op = "chmod"
+
+	return vfs.mutate(op, name, true, func(absPath string) error {
+		return vfs.upper.Chmod(absPath, mode)
+	})
+}
+
+// Chown changes the numeric uid and gid of the named file.
+// If the file is a symbolic link, it changes the uid and gid of the link's target.
+// A uid or gid of -1 means to not change that value.
+// If there is an error, it will be of type *PathError.
+//
+// On Windows or Plan 9, Chown always returns the syscall.EWINDOWS or
+// EPLAN9 error, wrapped in *PathError.
+func (vfs *OverlayFS) Chown(name string, uid, gid int) error {
+	const op = "chown"
+
+	return vfs.mutate(op, name, true, func(absPath string) error {
+		return vfs.upper.Chown(absPath, uid, gid)
+	})
+}
+
+// Chtimes changes the access and modification times of the named
+// file, similar to the Unix utime() or utimes() functions.
+//
+// The underlying file system may truncate or round the values to a
+// less precise time unit.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Chtimes(name string, atime, mtime time.Time) error {
+	const op = "chtimes"
+
+	return vfs.mutate(op, name, true, func(absPath string) error {
+		return vfs.upper.Chtimes(absPath, atime, mtime)
+	})
+}
+
+// Clean returns the shortest path name equivalent to path
+// by purely lexical processing. It applies the following rules
+// iteratively until no further processing can be done:
+//
+//  1. Replace multiple Separator elements with a single one.
+//  2. Eliminate each . path name element (the current directory).
+//  3. Eliminate each inner .. path name element (the parent directory)
+//     along with the non-.. element that precedes it.
+//  4. Eliminate .. elements that begin a rooted path:
+//     that is, replace "/.." by "/" at the beginning of a path,
+//     assuming Separator is '/'.
+//
+// The returned path ends in a slash only if it represents a root directory,
+// such as "/" on Unix or `C:\` on Windows.
+//
+// Finally, any occurrences of slash are replaced by Separator.
+//
+// If the result of this process is an empty string, Clean
+// returns the string ".".
+//
+// See also Rob Pike, “Lexical File Names in Plan 9 or
+// Getting Dot-Dot Right,”
+// https://9p.io/sys/doc/lexnames.html
+func (vfs *OverlayFS) Clean(path string) string {
+	return avfs.Clean(vfs, path)
+}
+
+// Create creates or truncates the named file. If the file already exists,
+// it is truncated. If the file does not exist, it is created with mode 0666
+// (before umask). If successful, methods on the returned DummyFile can
+// be used for I/O; the associated file descriptor has mode O_RDWR.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Create(name string) (avfs.File, error) {
+	return avfs.Create(vfs, name)
+}
+
+// CreateTemp creates a new temporary file in the directory dir,
+// opens the file for reading and writing, and returns the resulting file.
+// The filename is generated by taking pattern and adding a random string to the end.
+// If pattern includes a "*", the random string replaces the last "*".
+// If dir is the empty string, CreateTemp uses the default directory for temporary files, as returned by TempDir.
+// Multiple programs or goroutines calling CreateTemp simultaneously will not choose the same file.
+// The caller can use the file's Name method to find the pathname of the file.
+// It is the caller's responsibility to remove the file when it is no longer needed.
+func (vfs *OverlayFS) CreateTemp(dir, pattern string) (avfs.File, error) {
+	return avfs.CreateTemp(vfs, dir, pattern)
+}
+
+// Dir returns all but the last element of path, typically the path's directory.
+// After dropping the final element, Dir calls Clean on the path and trailing
+// slashes are removed.
+// If the path is empty, Dir returns ".".
+// If the path consists entirely of separators, Dir returns a single separator.
+// The returned path does not end in a separator unless it is the root directory.
+func (vfs *OverlayFS) Dir(path string) string {
+	return avfs.Dir(vfs, path)
+}
+
+// EvalSymlinks returns the path name after the evaluation of any symbolic
+// links.
+// If path is relative the result will be relative to the current directory,
+// unless one of the components is an absolute symbolic link.
+// EvalSymlinks calls Clean on the result.
+func (vfs *OverlayFS) EvalSymlinks(path string) (string, error) {
+	op := "lstat"
+	if vfs.OSType() == avfs.OsWindows {
+		op = "CreateFile"
+	}
+
+	if path == "" {
+		return vfs.upper.EvalSymlinks(path)
+	}
+
+	absPath := vfs.abs(path)
+
+	_, _, target, err := vfs.follow(absPath)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	if vfs.IsAbs(path) || target != absPath {
+		return target, nil
+	}
+
+	return vfs.Clean(path), nil
+}
+
+// Export returns the patch of the changes made to the directory root in the upper layer (see avfs.Diff) :
+// the files added, modified or removed in the merged view since the lower layer.
+// Applied to another copy of the lower layer, the patch reproduces the merged view,
+// this allows to record what a tool would change without modifying the lower layer.
+// The files of the lower layer which were not copied up are not read.
+func (vfs *OverlayFS) Export(root string) (*avfs.Patch, error) {
+	return avfs.Diff(vfs.lower, root, vfs, root)
+}
+
+// FromSlash returns the result of replacing each slash ('/') character
+// in path with a separator character. Multiple slashes are replaced
+// by multiple separators.
+func (vfs *OverlayFS) FromSlash(path string) string {
+	return avfs.FromSlash(vfs, path)
+}
+
+// Getwd returns a rooted name link corresponding to the
+// current directory. If the current directory can be
+// reached via multiple paths (due to symbolic links),
+// Getwd may return any one of them.
+func (vfs *OverlayFS) Getwd() (dir string, err error) {
+	return vfs.CurDir(), nil
+}
+
+// Glob returns the names of all files matching pattern or nil
+// if there is no matching file. The syntax of patterns is the same
+// as in Match. The pattern may describe hierarchical names such as
+// /usr/*/bin/ed (assuming the Separator is '/').
+//
+// Glob ignores file system errors such as I/O errors reading directories.
+// The only possible returned error is ErrBadPattern, when pattern
+// is malformed.
+func (vfs *OverlayFS) Glob(pattern string) (matches []string, err error) {
+	return avfs.Glob(vfs, pattern)
+}
+
+func (vfs *OverlayFS) Idm() avfs.IdentityMgr {
+	return avfs.NotImplementedIdm
+}
+
+// IsAbs reports whether the path is absolute.
+func (vfs *OverlayFS) IsAbs(path string) bool {
+	return avfs.IsAbs(vfs, path)
+}
+
+// IsPathSeparator reports whether c is a directory separator character.
+func (vfs *OverlayFS) IsPathSeparator(c uint8) bool {
+	return avfs.IsPathSeparator(vfs, c)
+}
+
+// Join joins any number of path elements into a single path,
+// separating them with an OS specific Separator. Empty elements
+// are ignored. The result is Cleaned. However, if the argument
+// list is empty or all its elements are empty, Join returns
+// an empty string.
+// On Windows, the result will only be a UNC path if the first
+// non-empty element is a UNC path.
+func (vfs *OverlayFS) Join(elem ...string) string {
+	return avfs.Join(vfs, elem...)
+}
+
+// Lchown changes the numeric uid and gid of the named file.
+// If the file is a symbolic link, it changes the uid and gid of the link itself.
+// If there is an error, it will be of type *PathError.
+//
+// On Windows, it always returns the syscall.EWINDOWS error, wrapped
+// in *PathError.
+func (vfs *OverlayFS) Lchown(name string, uid, gid int) error {
+	const op = "lchown"
+
+	return vfs.mutate(op, name, false, func(absPath string) error {
+		return vfs.upper.Lchown(absPath, uid, gid)
+	})
+}
+
+// Link creates newname as a hard link to the oldname file.
+// If there is an error, it will be of type *LinkError.
+func (vfs *OverlayFS) Link(oldname, newname string) error {
+	const op = "link"
+
+	oAbsPath, nAbsPath := vfs.abs(oldname), vfs.abs(newname)
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	oLayer, oInfo, oOk := vfs.lookup(oAbsPath)
+	if !oOk {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NoSuchFile}
+	}
+
+	if !oInfo.IsDir() && avfs.IsDirPath(vfs, oldname) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NotADirectory}
+	}
+
+	if err := vfs.reservedName(nAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	_, _, nOk := vfs.lookup(nAbsPath)
+
+	// Like link(2), a path ending with a path separator can't be created.
+	if newname == "" || (!nOk && avfs.HasTrailingSeparator(vfs, newname)) || !vfs.parentExists(nAbsPath) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NoSuchFile}
+	}
+
+	if oInfo.IsDir() {
+		err := error(avfs.ErrOpNotPermitted)
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinAccessDenied
+		}
+
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	if nOk {
+		err := vfs.err.FileExists
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinAlreadyExists
+		}
+
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	if err := vfs.copyUpParent(nAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	if oLayer == vfs.lower {
+		if err := vfs.copyUp(oAbsPath, oInfo); err != nil {
+			return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+		}
+	}
+
+	vfs.clearWhiteout(nAbsPath)
+
+	return linkError(vfs.upper.Link(oAbsPath, nAbsPath), oldname, newname)
+}
+
+// Lstat returns a FileInfo describing the named file.
+// If the file is a symbolic link, the returned FileInfo
+// describes the symbolic link. Lstat makes no attempt to follow the link.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Lstat(name string) (fs.FileInfo, error) {
+	op := "lstat"
+	if vfs.OSType() == avfs.OsWindows {
+		op = "CreateFile"
+	}
+
+	absPath := vfs.abs(name)
+	layer, info, ok := vfs.lookup(absPath)
+
+	if err := vfs.checkPath(op, name, info, ok); err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, vfs.notFound(op, name, absPath)
+	}
+
+	if vfs.isRoot(absPath) {
+		return info, nil
+	}
+
+	info, err := layer.Lstat(absPath)
+
+	return info, pathError(err, name)
+}
+
+// Match reports whether name matches the shell file name pattern.
+// The pattern syntax is:
+//
+//	pattern:
+//		{ term }
+//	term:
+//		'*'         matches any sequence of non-Separator characters
+//		'?'         matches any single non-Separator character
+//		'[' [ '^' ] { character-range } ']'
+//		            character class (must be non-empty)
+//		c           matches character c (c != '*', '?', '\\', '[')
+//		'\\' c      matches character c
+//
+//	character-range:
+//		c           matches character c (c != '\\', '-', ']')
+//		'\\' c      matches character c
+//		lo '-' hi   matches character c for lo <= c <= hi
+//
+// Match requires pattern to match all of name, not just a substring.
+// The only possible returned error is ErrBadPattern, when pattern
+// is malformed.
+//
+// On Windows, escaping is disabled. Instead, '\\' is treated as
+// path separator.
+func (vfs *OverlayFS) Match(pattern, name string) (matched bool, err error) {
+	return avfs.Match(vfs, pattern, name)
+}
+
+// Mkdir creates a new directory with the specified name and permission
+// bits (before umask).
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Mkdir(name string, perm fs.FileMode) error {
+	const op = "mkdir"
+
+	absPath := vfs.abs(name)
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	return vfs.mkdir(op, name, absPath, perm)
+}
+
+// MkdirAll creates a directory named name,
+// along with any necessary parents, and returns nil,
+// or else returns an error.
+// The permission bits perm (before umask) are used for all
+// directories that MkdirAll creates.
+// If name is already a directory, MkdirAll does nothing
+// and returns nil.
+func (vfs *OverlayFS) MkdirAll(path string, perm fs.FileMode) error {
+	const op = "mkdir"
+
+	if path == "" {
+		return &fs.PathError{Op: op, Path: path, Err: vfs.err.NoSuchDir}
+	}
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	return vfs.mkdirAll(op, vfs.abs(path), perm)
+}
+
+// MkdirTemp creates a new temporary directory in the directory dir
+// and returns the pathname of the new directory.
+// The new directory's name is generated by adding a random string to the end of pattern.
+// If pattern includes a "*", the random string replaces the last "*" instead.
+// If dir is the empty string, MkdirTemp uses the default directory for temporary files, as returned by TempDir.
+// Multiple programs or goroutines calling MkdirTemp simultaneously will not choose the same directory.
+// It is the caller's responsibility to remove the directory when it is no longer needed.
+func (vfs *OverlayFS) MkdirTemp(dir, pattern string) (string, error) {
+	return avfs.MkdirTemp(vfs, dir, pattern)
+}
+
+// Open opens the named file for reading. If successful, methods on
+// the returned file can be used for reading; the associated file
+// descriptor has mode O_RDONLY.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Open(name string) (avfs.File, error) {
+	return vfs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile is the generalized open call; most users will use Open
+// or Create instead. It opens the named file with specified flag
+// (O_RDONLY etc.). If the file does not exist, and the O_CREATE flag
+// is passed, it is created with mode perm (before umask). If successful,
+// methods on the returned File can be used for I/O.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) OpenFile(name string, flag int, perm fs.FileMode) (avfs.File, error) {
+	const op = "open"
+
+	om := avfs.ToOpenMode(flag)
+	if om&avfs.OpenCreate != 0 && avfs.HasTrailingSeparator(vfs, name) {
+		return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+	}
+
+	absPath := vfs.abs(name)
+
+	if om&avfs.OpenWrite != 0 || om&avfs.OpenCreate != 0 {
+		vfs.mu.Lock()
+		defer vfs.mu.Unlock()
+	}
+
+	layer, info, ok := vfs.lookup(absPath)
+	if ok && info.Mode()&fs.ModeSymlink != 0 {
+		var err error
+
+		layer, info, absPath, err = vfs.follow(absPath)
+		if err == vfs.err.TooManySymlinks {
+			return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+		}
+
+		ok = err == nil
+	}
+
+	if err := vfs.checkPath(op, name, info, ok); err != nil {
+		return (*OverlayFile)(nil), err
+	}
+
+	switch {
+	case !ok:
+		if om&avfs.OpenCreate == 0 {
+			return (*OverlayFile)(nil), vfs.notFound(op, name, absPath)
+		}
+
+		if err := vfs.reservedName(absPath); err != nil {
+			return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+		}
+
+		if err := vfs.copyUpParent(absPath); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return (*OverlayFile)(nil), vfs.notFound(op, name, absPath)
+			}
+
+			return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+		}
+
+		vfs.clearWhiteout(absPath)
+
+		layer = vfs.upper
+	case om&avfs.OpenCreateExcl != 0:
+		return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.FileExists}
+	case om&avfs.OpenWrite != 0 && layer == vfs.lower:
+		if info.IsDir() {
+			return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: vfs.err.IsADirectory}
+		}
+
+		if err := vfs.copyUp(absPath, info); err != nil {
+			return (*OverlayFile)(nil), &fs.PathError{Op: op, Path: name, Err: err}
+		}
+
+		layer = vfs.upper
+	case layer == vfs.lower:
+		flag = os.O_RDONLY
+	}
+
+	bf, err := layer.OpenFile(absPath, flag, perm)
+	if err != nil {
+		return (*OverlayFile)(nil), pathError(err, name)
+	}
+
+	f := &OverlayFile{
+		baseFile: bf,
+		vfs:      vfs,
+		name:     name,
+		absPath:  absPath,
+	}
+
+	return f, nil
+}
+
+func (vfs *OverlayFS) PathSeparator() uint8 {
+	return vfs.upper.PathSeparator()
+}
+
+// ReadDir reads the named directory,
+// returning all its directory entries sorted by filename.
+// If an error occurs reading the directory,
+// ReadDir returns the entries it was able to read before the error,
+// along with the error.
+func (vfs *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return avfs.ReadDir(vfs, name)
+}
+
+// ReadFile reads the named file and returns the contents.
+// A successful call returns err == nil, not err == EOF.
+// Because ReadFile reads the whole file, it does not treat an EOF from Read
+// as an error to be reported.
+func (vfs *OverlayFS) ReadFile(name string) ([]byte, error) {
+	return avfs.ReadFile(vfs, name)
+}
+
+// Readlink returns the destination of the named symbolic link.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Readlink(name string) (string, error) {
+	const op = "readlink"
+
+	absPath := vfs.abs(name)
+
+	layer, info, ok := vfs.lookup(absPath)
+	if err := vfs.checkPath(op, name, info, ok); err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", vfs.notFound(op, name, absPath)
+	}
+
+	if info.Mode()&fs.ModeSymlink == 0 {
+		err := error(avfs.ErrInvalidArgument)
+		if vfs.OSType() == avfs.OsWindows {
+			err = avfs.ErrWinNotReparsePoint
+		}
+
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	link, err := layer.Readlink(absPath)
+
+	return link, pathError(err, name)
+}
+
+// Rel returns a relative path that is lexically equivalent to targpath when
+// joined to basepath with an intervening separator. That is,
+// Join(basepath, Rel(basepath, targpath)) is equivalent to targpath itself.
+// On success, the returned path will always be relative to basepath,
+// even if basepath and targpath share no elements.
+// An error is returned if targpath can't be made relative to basepath or if
+// knowing the current working directory would be necessary to compute it.
+// Rel calls Clean on the result.
+func (vfs *OverlayFS) Rel(basepath, targpath string) (string, error) {
+	return avfs.Rel(vfs, basepath, targpath)
+}
+
+// Remove removes the named file or (empty) directory.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Remove(name string) error {
+	const op = "remove"
+
+	absPath := vfs.abs(name)
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	layer, info, ok := vfs.lookup(absPath)
+	if err := vfs.checkPath(op, name, info, ok); err != nil {
+		return err
+	}
+
+	if !ok {
+		return vfs.notFound(op, name, absPath)
+	}
+
+	// Like rmdir, a symbolic link to a directory can't be removed by a path ending with a path separator.
+	if avfs.IsDirPath(vfs, name) && vfs.isSymlink(name) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+
+	// Like rmdir, a directory can't be removed by a path ending with "." or "..".
+	if vfs.OSType() != avfs.OsWindows {
+		switch avfs.DotElem(vfs, name) {
+		case ".":
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.InvalidArgument}
+		case "..":
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
+	if info.IsDir() {
+		entries, err := vfs.readDir(absPath)
+		if err != nil {
+			return pathError(err, name)
+		}
+
+		if len(entries) != 0 {
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
+	return vfs.removeFile(op, name, absPath, layer == vfs.upper)
+}
+
+// RemoveAll removes path and any children it contains.
+// It removes everything it can but returns the first error
+// it encounters. If the path does not exist, RemoveAll
+// returns nil (no error).
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) RemoveAll(path string) error {
+	const op = "unlinkat"
+
+	if path == "" {
+		// fail silently to retain compatibility with previous behavior of RemoveAll.
+		return nil
+	}
+
+	// The current directory or the parent of a directory is never removed.
+	switch avfs.DotElem(vfs, path) {
+	case ".":
+		return &fs.PathError{Op: "RemoveAll", Path: path, Err: vfs.err.InvalidArgument}
+	case "..":
+		if vfs.OSType() != avfs.OsWindows {
+			return &fs.PathError{Op: op, Path: path, Err: vfs.err.DirNotEmpty}
+		}
+	}
+
+	absPath := vfs.abs(avfs.TrimSeparators(vfs, path))
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	layer, _, ok := vfs.lookup(absPath)
+	if !ok || vfs.isRoot(absPath) {
+		return nil
+	}
+
+	return vfs.removeFile(op, path, absPath, layer == vfs.upper)
+}
+
+// Rename renames (moves) oldpath to newpath.
+// If newpath already exists and is not a directory, Rename replaces it.
+// OS-specific restrictions may apply when oldpath and newpath are in different directories.
+// If there is an error, it will be of type *LinkError.
+func (vfs *OverlayFS) Rename(oldname, newname string) error {
+	const op = "rename"
+
+	oAbsPath, nAbsPath := vfs.abs(oldname), vfs.abs(newname)
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	oLayer, oInfo, oOk := vfs.lookup(oAbsPath)
+	if err := vfs.checkPath(op, oldname, oInfo, oOk); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: errors.Unwrap(err)}
+	}
+
+	if !oOk || newname == "" || !vfs.parentExists(nAbsPath) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NoSuchFile}
+	}
+
+	if err := vfs.reservedName(nAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	nLayer, nInfo, nOk := vfs.lookup(nAbsPath)
+
+	// Like rename(2), a directory can't be renamed by or to itself through a path ending with "." or "..".
+	if vfs.OSType() != avfs.OsWindows &&
+		(avfs.DotElem(vfs, oldname) != "" || (avfs.DotElem(vfs, newname) != "" && oAbsPath == nAbsPath)) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: avfs.ErrDeviceBusy}
+	}
+
+	if !oInfo.IsDir() && avfs.HasTrailingSeparator(vfs, newname) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NotADirectory}
+	}
+
+	if oAbsPath == nAbsPath {
+		return nil
+	}
+
+	if nOk && nLayer == vfs.lower {
+		if nInfo.IsDir() {
+			entries, err := vfs.readDir(nAbsPath)
+			if err == nil && len(entries) != 0 {
+				return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.DirNotEmpty}
+			}
+		}
+
+		if err := vfs.copyUp(nAbsPath, nInfo); err != nil {
+			return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+		}
+	}
+
+	if err := vfs.copyUpParent(nAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	if err := vfs.copyUpTree(oAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	oLowerVisible := oLayer == vfs.lower || vfs.lowerVisible(oAbsPath)
+	whiteout := !nOk && vfs.clearWhiteout(nAbsPath)
+
+	err := vfs.upper.Rename(oAbsPath, nAbsPath)
+	if err != nil {
+		if whiteout {
+			_ = vfs.whiteout(nAbsPath)
+		}
+
+		return linkError(err, oldname, newname)
+	}
+
+	if oLowerVisible {
+		if err = vfs.whiteout(oAbsPath); err != nil {
+			return &os.LinkError{Op: op, Old: oldname, New: newname, Err: errors.Unwrap(err)}
+		}
+	}
+
+	if oInfo.IsDir() && (nOk || whiteout) {
+		if err = vfs.markOpaque(nAbsPath); err != nil {
+			return &os.LinkError{Op: op, Old: oldname, New: newname, Err: errors.Unwrap(err)}
+		}
+	}
+
+	return nil
+}
+
+// SameFile reports whether fi1 and fi2 describe the same file.
+// For example, on Unix this means that the device and inode fields
+// of the two underlying structures are identical; on other systems
+// the decision may be based on the path names.
+// SameFile only applies to results returned by this package's Stat.
+// It returns false in other cases.
+func (vfs *OverlayFS) SameFile(fi1, fi2 fs.FileInfo) bool {
+	return vfs.upper.SameFile(unwrapInfo(fi1), unwrapInfo(fi2)) ||
+		vfs.lower.SameFile(unwrapInfo(fi1), unwrapInfo(fi2))
+}
+
+func (vfs *OverlayFS) SetIdm(idm avfs.IdentityMgr) error {
+	return avfs.ErrPermDenied
+}
+
+func (vfs *OverlayFS) SetUMask(mask fs.FileMode) error {
+	return vfs.upper.SetUMask(mask)
+}
+
+func (vfs *OverlayFS) SetUser(user avfs.UserReader) error {
+	return avfs.ErrPermDenied
+}
+
+// SetUserByName sets the current user by name.
+// If the user is not found, the returned error is of type UnknownUserError.
+func (vfs *OverlayFS) SetUserByName(name string) error {
+	return avfs.ErrPermDenied
+}
+
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *OverlayFS) SetUserByUid(uid, gid int) error {
+	return avfs.ErrPermDenied
+}
+
+// Split splits path immediately following the final Separator,
+// separating it into a directory and file name component.
+// If there is no Separator in path, Split returns an empty dir
+// and file set to path.
+// The returned values have the property that path = dir+file.
+func (vfs *OverlayFS) Split(path string) (dir, file string) {
+	return avfs.Split(vfs, path)
+}
+
+// Stat returns a FileInfo describing the named file.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Stat(path string) (fs.FileInfo, error) {
+	op := "stat"
+	if vfs.OSType() == avfs.OsWindows {
+		op = "CreateFile"
+	}
+
+	absPath := vfs.abs(path)
+
+	layer, info, target, err := vfs.follow(absPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, vfs.notFound(op, path, target)
+		}
+
+		return nil, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	if err = vfs.checkPath(op, path, info, true); err != nil {
+		return nil, err
+	}
+
+	if vfs.isRoot(absPath) {
+		return info, nil
+	}
+
+	info, err = layer.Lstat(target)
+	if err != nil {
+		return nil, pathError(err, path)
+	}
+
+	if name := vfs.Base(absPath); info.Name() != name {
+		return &OverlayInfo{FileInfo: info, name: name}, nil
+	}
+
+	return info, nil
+}
+
+// Sub returns an FS corresponding to the subtree rooted at dir.
+func (vfs *OverlayFS) Sub(dir string) (avfs.VFS, error) {
+	const op = "sub"
+
+	return nil, &fs.PathError{Op: op, Path: dir, Err: vfs.err.PermDenied}
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+// If there is an error, it will be of type *LinkError.
+func (vfs *OverlayFS) Symlink(oldname, newname string) error {
+	const op = "symlink"
+
+	nAbsPath := vfs.abs(newname)
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	if err := vfs.reservedName(nAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	_, _, nOk := vfs.lookup(nAbsPath)
+
+	// Like symlink(2), a path ending with a path separator can't be created.
+	if newname == "" || (!nOk && avfs.HasTrailingSeparator(vfs, newname)) || !vfs.parentExists(nAbsPath) {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.NoSuchFile}
+	}
+
+	if nOk {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: vfs.err.FileExists}
+	}
+
+	if err := vfs.copyUpParent(nAbsPath); err != nil {
+		return &os.LinkError{Op: op, Old: oldname, New: newname, Err: err}
+	}
+
+	vfs.clearWhiteout(nAbsPath)
+
+	return linkError(vfs.upper.Symlink(oldname, nAbsPath), oldname, newname)
+}
+
+// TempDir returns the default directory to use for temporary files.
+//
+// On Unix systems, it returns $TMPDIR if non-empty, else /tmp.
+// On Windows, it uses GetTempPath, returning the first non-empty
+// value from %TMP%, %TEMP%, %USERPROFILE%, or the Windows directory.
+// On Plan 9, it returns /tmp.
+//
+// The directory is neither guaranteed to exist nor have accessible
+// permissions.
+func (vfs *OverlayFS) TempDir() string {
+	return vfs.upper.TempDir()
+}
+
+// ToSlash returns the result of replacing each separator character
+// in path with a slash ('/') character. Multiple separators are
+// replaced by multiple slashes.
+func (vfs *OverlayFS) ToSlash(path string) string {
+	return avfs.ToSlash(vfs, path)
+}
+
+// ToSysStat takes a value from fs.FileInfo.Sys() and returns a value that implements interface avfs.SysStater.
+func (vfs *OverlayFS) ToSysStat(info fs.FileInfo) avfs.SysStater {
+	info = unwrapInfo(info)
+	if sst, ok := info.Sys().(avfs.SysStater); ok {
+		return sst
+	}
+
+	return vfs.upper.ToSysStat(info)
+}
+
+// Truncate changes the size of the named file.
+// If the file is a symbolic link, it changes the size of the link's target.
+// If there is an error, it will be of type *PathError.
+func (vfs *OverlayFS) Truncate(name string, size int64) error {
+	const op = "truncate"
+
+	return vfs.mutate(op, name, true, func(absPath string) error {
+		return vfs.upper.Truncate(absPath, size)
+	})
+}
+
+func (vfs *OverlayFS) UMask() fs.FileMode {
+	return vfs.upper.UMask()
+}
+
+func (vfs *OverlayFS) User() avfs.UserReader {
+	return vfs.upper.User()
+}
+
+// WalkDir walks the file tree rooted at root, calling fn for each file or
+// directory in the tree, including root.
+//
+// All errors that arise visiting files and directories are filtered by fn:
+// see the fs.WalkDirFunc documentation for details.
+//
+// The files are walked in lexical order, which makes the output deterministic
+// but requires WalkDir to read an entire directory into memory before proceeding
+// to walk that directory.
+//
+// WalkDir does not follow symbolic links.
+func (vfs *OverlayFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return avfs.WalkDir(vfs, root, fn)
+}
+
+// WriteFile writes data to the named file, creating it if necessary.
+// If the file does not exist, WriteFile creates it with permissions perm (before umask);
+// otherwise WriteFile truncates it before writing, without changing permissions.
+func (vfs *OverlayFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return avfs.WriteFile(vfs, name, data, perm)
+}
@@ -0,0 +1,66 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package overlayfs
+
+import (
+	"github.com/avfs/avfs"
+)
+
+// New returns a new overlay file system (OverlayFS) merging the lower and upper file systems,
+// which must emulate the same OS type.
+// The lower layer is never modified, all the mutations are made in the upper layer.
+func New(lower, upper avfs.VFS) *OverlayFS {
+	vfs := &OverlayFS{
+		lower: lower,
+		upper: upper,
+	}
+
+	features := upper.Features() &^ (avfs.FeatIdentityMgr | avfs.FeatRealFS | avfs.FeatSubFS)
+	_ = vfs.SetFeatures(features)
+
+	vfs.err.SetOSType(upper.OSType())
+
+	curDir, _ := upper.Getwd()
+	_ = vfs.SetCurDir(curDir)
+
+	return vfs
+}
+
+// Lower returns the lower layer of the file system.
+func (vfs *OverlayFS) Lower() avfs.VFS {
+	return vfs.lower
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *OverlayFS) Name() string {
+	return vfs.upper.Name()
+}
+
+// OSType returns the operating system type of the file system.
+func (vfs *OverlayFS) OSType() avfs.OSType {
+	return vfs.upper.OSType()
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*OverlayFS) Type() string {
+	return "OverlayFS"
+}
+
+// Upper returns the upper layer of the file system.
+func (vfs *OverlayFS) Upper() avfs.VFS {
+	return vfs.upper
+}
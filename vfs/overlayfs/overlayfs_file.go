@@ -0,0 +1,344 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package overlayfs
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// Chdir changes the current working directory to the file,
+// which must be a directory.
+// If there is an error, it will be of type *PathError.
+func (f *OverlayFile) Chdir() error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	if err := f.baseFile.Chdir(); err != nil {
+		return pathError(err, f.name)
+	}
+
+	_ = f.vfs.SetCurDir(f.absPath)
+
+	return nil
+}
+
+// Chmod changes the mode of the file to mode.
+// If there is an error, it will be of type *PathError.
+func (f *OverlayFile) Chmod(mode fs.FileMode) error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	return pathError(f.baseFile.Chmod(mode), f.name)
+}
+
+// Chown changes the numeric uid and gid of the named file.
+// If there is an error, it will be of type *PathError.
+//
+// On Windows, it always returns the syscall.EWINDOWS error, wrapped
+// in *PathError.
+func (f *OverlayFile) Chown(uid, gid int) error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	return pathError(f.baseFile.Chown(uid, gid), f.name)
+}
+
+// Close closes the OverlayFile, rendering it unusable for I/O.
+// On files that support SetDeadline, any pending I/O operations will
+// be canceled and return immediately with an error.
+func (f *OverlayFile) Close() error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	f.dirEntries = nil
+	f.dirNames = nil
+	f.mu.Unlock()
+
+	return pathError(f.baseFile.Close(), f.name)
+}
+
+// Fd returns the integer Unix file descriptor referencing the open file.
+// The file descriptor is valid only until f.Close is called or f is garbage collected.
+// On Unix systems this will cause the SetDeadline methods to stop working.
+func (f *OverlayFile) Fd() uintptr {
+	if f == nil {
+		return ^(uintptr(0))
+	}
+
+	return f.baseFile.Fd()
+}
+
+// Name returns the name of the file as presented to Open.
+func (f *OverlayFile) Name() string {
+	if f == nil {
+		panic("")
+	}
+
+	return f.name
+}
+
+// Read reads up to len(b) bytes from the OverlayFile.
+// It returns the number of bytes read and any error encountered.
+// At end of file, Read returns 0, io.EOF.
+func (f *OverlayFile) Read(b []byte) (n int, err error) {
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	n, err = f.baseFile.Read(b)
+
+	return n, pathError(err, f.name)
+}
+
+// ReadAt reads len(b) bytes from the OverlayFile starting at byte offset off.
+// It returns the number of bytes read and the error, if any.
+// ReadAt always returns a non-nil error when n < len(b).
+// At end of file, that error is io.EOF.
+func (f *OverlayFile) ReadAt(b []byte, off int64) (n int, err error) {
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	n, err = f.baseFile.ReadAt(b, off)
+
+	return n, pathError(err, f.name)
+}
+
+// ReadDir reads the contents of the directory associated with the file f
+// and returns a slice of DirEntry values in directory order.
+// Subsequent calls on the same file will yield later DirEntry records in the directory.
+//
+// If n > 0, ReadDir returns at most n DirEntry records.
+// In this case, if ReadDir returns an empty slice, it will return an error explaining why.
+// At the end of a directory, the error is io.EOF.
+//
+// If n <= 0, ReadDir returns all the DirEntry records remaining in the directory.
+// When it succeeds, it returns a nil error (not io.EOF).
+func (f *OverlayFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n <= 0 || f.dirEntries == nil {
+		de, err := f.readDir()
+		if err != nil {
+			return nil, err
+		}
+
+		f.dirIndex = 0
+
+		if n <= 0 {
+			f.dirEntries = nil
+
+			return de, nil
+		}
+
+		f.dirEntries = de
+	}
+
+	start := f.dirIndex
+	if start >= len(f.dirEntries) {
+		f.dirIndex = 0
+		f.dirEntries = nil
+
+		return nil, io.EOF
+	}
+
+	end := min(start+n, len(f.dirEntries))
+	f.dirIndex = end
+
+	return f.dirEntries[start:end], nil
+}
+
+// Readdirnames reads and returns a slice of names from the directory f.
+//
+// If n > 0, Readdirnames returns at most n names. In this case, if
+// Readdirnames returns an empty slice, it will return a non-nil error
+// explaining why. At the end of a directory, the error is io.EOF.
+//
+// If n <= 0, Readdirnames returns all the names from the directory in
+// a single slice. In this case, if Readdirnames succeeds (reads all
+// the way to the end of the directory), it returns the slice and a
+// nil error. If it encounters an error before the end of the
+// directory, Readdirnames returns the names read until that point and
+// a non-nil error.
+func (f *OverlayFile) Readdirnames(n int) (names []string, err error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n <= 0 || f.dirNames == nil {
+		de, err := f.readDir()
+		if err != nil {
+			return nil, err
+		}
+
+		names = make([]string, len(de))
+		for i, e := range de {
+			names[i] = e.Name()
+		}
+
+		f.dirIndex = 0
+
+		if n <= 0 {
+			f.dirNames = nil
+
+			return names, nil
+		}
+
+		f.dirNames = names
+	}
+
+	start := f.dirIndex
+	if start >= len(f.dirNames) {
+		f.dirIndex = 0
+		f.dirNames = nil
+
+		return nil, io.EOF
+	}
+
+	end := min(start+n, len(f.dirNames))
+	f.dirIndex = end
+
+	return f.dirNames[start:end], nil
+}
+
+// Seek sets the offset for the next Read or Write on file to offset, interpreted
+// according to whence: 0 means relative to the origin of the file, 1 means
+// relative to the current offset, and 2 means relative to the end.
+// It returns the new offset and an error, if any.
+// The behavior of Seek on a file opened with O_APPEND is not specified.
+func (f *OverlayFile) Seek(offset int64, whence int) (ret int64, err error) {
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	ret, err = f.baseFile.Seek(offset, whence)
+
+	return ret, pathError(err, f.name)
+}
+
+// Stat returns the FileInfo structure describing file.
+// If there is an error, it will be of type *PathError.
+func (f *OverlayFile) Stat() (fs.FileInfo, error) {
+	if f == nil {
+		return nil, fs.ErrInvalid
+	}
+
+	info, err := f.baseFile.Stat()
+	if err != nil {
+		return nil, pathError(err, f.name)
+	}
+
+	// A file opened through a symbolic link is named by the link.
+	if name := avfs.Base(f.vfs, f.name); avfs.DotElem(f.vfs, f.name) == "" && info.Name() != name {
+		return &OverlayInfo{FileInfo: info, name: name}, nil
+	}
+
+	return info, nil
+}
+
+// Sync commits the current contents of the file to stable storage.
+// Typically, this means flushing the file system's in-memory copy
+// of recently written data to disk.
+func (f *OverlayFile) Sync() error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	return pathError(f.baseFile.Sync(), f.name)
+}
+
+// Truncate changes the size of the file.
+// It does not change the I/O offset.
+// If there is an error, it will be of type *PathError.
+func (f *OverlayFile) Truncate(size int64) error {
+	if f == nil {
+		return fs.ErrInvalid
+	}
+
+	return pathError(f.baseFile.Truncate(size), f.name)
+}
+
+// Write writes len(b) bytes to the OverlayFile.
+// It returns the number of bytes written and an error, if any.
+// Write returns a non-nil error when n != len(b).
+func (f *OverlayFile) Write(b []byte) (n int, err error) {
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	n, err = f.baseFile.Write(b)
+
+	return n, pathError(err, f.name)
+}
+
+// WriteAt writes len(b) bytes to the File starting at byte offset off.
+// It returns the number of bytes written and an error, if any.
+// WriteAt returns a non-nil error when n != len(b).
+func (f *OverlayFile) WriteAt(b []byte, off int64) (n int, err error) {
+	if f == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	n, err = f.baseFile.WriteAt(b, off)
+
+	return n, pathError(err, f.name)
+}
+
+// WriteString is like Write, but writes the contents of string s rather than
+// a slice of bytes.
+func (f *OverlayFile) WriteString(s string) (n int, err error) {
+	return f.Write([]byte(s))
+}
+
+// readDir returns the entries of the directory in the merged view.
+// The directory is read from its layer first, to return the errors of a closed file or of a file
+// which is not a directory.
+func (f *OverlayFile) readDir() ([]fs.DirEntry, error) {
+	if _, err := f.baseFile.Readdirnames(-1); err != nil {
+		return nil, pathError(err, f.name)
+	}
+
+	de, err := f.vfs.readDir(f.absPath)
+	if err != nil {
+		return nil, pathError(err, f.name)
+	}
+
+	return de, nil
+}
+
+// OverlayInfo is the implementation of fs.FileInfo returned by Stat for a symbolic link.
+
+// Name returns the base name of the symbolic link.
+func (info *OverlayInfo) Name() string {
+	return info.name
+}
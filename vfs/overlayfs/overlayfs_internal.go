@@ -0,0 +1,618 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package overlayfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// maxFollow is the maximum number of symbolic links followed to resolve a path.
+const maxFollow = 40
+
+// abs returns the absolute path of name after the evaluation of the symbolic links of its parents,
+// the last element is also evaluated if name can only name a directory.
+func (vfs *OverlayFS) abs(name string) string {
+	absPath, _ := avfs.Abs(vfs, name, vfs.CurDir())
+	absPath, _ = vfs.resolve(absPath, avfs.IsDirPath(vfs, name))
+
+	return absPath
+}
+
+// resolve returns the absolute path absPath after the evaluation of the symbolic links of the merged view,
+// the last element is only evaluated if followLast is true.
+// If an element does not exist, the remaining elements are returned unevaluated.
+func (vfs *OverlayFS) resolve(absPath string, followLast bool) (string, error) {
+	volLen := avfs.VolumeNameLen(vfs, absPath)
+	path := absPath[:volLen+1]
+	elems := strings.FieldsFunc(absPath[volLen:], func(r rune) bool { return r < 0x80 && vfs.IsPathSeparator(uint8(r)) })
+	links := 0
+
+	for len(elems) > 0 {
+		elem := elems[0]
+		elems = elems[1:]
+
+		switch elem {
+		case ".":
+			continue
+		case "..":
+			path = vfs.Dir(path)
+
+			continue
+		}
+
+		next := vfs.Join(path, elem)
+		if len(elems) == 0 && !followLast {
+			return next, nil
+		}
+
+		layer, info, ok := vfs.lookup(next)
+		if !ok {
+			return vfs.Join(append([]string{next}, elems...)...), vfs.err.NoSuchFile
+		}
+
+		if info.Mode()&fs.ModeSymlink == 0 {
+			path = next
+
+			continue
+		}
+
+		links++
+		if links > maxFollow {
+			return absPath, vfs.err.TooManySymlinks
+		}
+
+		target, err := layer.Readlink(next)
+		if err != nil {
+			return absPath, errors.Unwrap(err)
+		}
+
+		if vfs.IsAbs(target) {
+			volLen = avfs.VolumeNameLen(vfs, target)
+			path = target[:volLen+1]
+			target = target[volLen:]
+		}
+
+		tElems := strings.FieldsFunc(target, func(r rune) bool { return r < 0x80 && vfs.IsPathSeparator(uint8(r)) })
+		elems = append(tElems, elems...)
+	}
+
+	return path, nil
+}
+
+// isRoot returns true if the absolute path is a root directory.
+func (vfs *OverlayFS) isRoot(absPath string) bool {
+	return vfs.Dir(absPath) == absPath
+}
+
+// reservedName returns an error if the base name of absPath starts with WhiteoutPrefix,
+// such names are reserved for the whiteouts of the upper layer and can't be created in the merged view.
+func (vfs *OverlayFS) reservedName(absPath string) error {
+	if !strings.HasPrefix(vfs.Base(absPath), WhiteoutPrefix) {
+		return nil
+	}
+
+	if vfs.OSType() == avfs.OsWindows {
+		return avfs.ErrWinInvalidName
+	}
+
+	return avfs.ErrInvalidArgument
+}
+
+// lookup returns the layer and the information (from Lstat) of the file absPath in the merged view.
+// If the file does not exist, ok is false.
+func (vfs *OverlayFS) lookup(absPath string) (layer avfs.VFS, info fs.FileInfo, ok bool) {
+	if strings.HasPrefix(vfs.Base(absPath), WhiteoutPrefix) {
+		return nil, nil, false
+	}
+
+	if info, err := vfs.upper.Lstat(absPath); err == nil {
+		return vfs.upper, info, true
+	}
+
+	if vfs.lowerHidden(absPath) {
+		return nil, nil, false
+	}
+
+	if info, err := vfs.lower.Lstat(absPath); err == nil {
+		return vfs.lower, info, true
+	}
+
+	return nil, nil, false
+}
+
+// lowerHidden returns true if the file absPath of the lower layer is hidden by the upper layer :
+// the file or one of its parents is whited out, one of its parents is an opaque directory
+// or a file which is not a directory.
+func (vfs *OverlayFS) lowerHidden(absPath string) bool {
+	for path := absPath; !vfs.isRoot(path); path = vfs.Dir(path) {
+		parent, name := vfs.Dir(path), vfs.Base(path)
+
+		if vfs.upperExists(vfs.Join(parent, WhiteoutPrefix+name)) ||
+			vfs.upperExists(vfs.Join(parent, OpaqueMarker)) {
+			return true
+		}
+
+		if path == absPath {
+			continue
+		}
+
+		if info, err := vfs.upper.Lstat(path); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lowerVisible returns true if the file absPath exists in the lower layer and is not hidden by the upper layer.
+func (vfs *OverlayFS) lowerVisible(absPath string) bool {
+	if vfs.lowerHidden(absPath) {
+		return false
+	}
+
+	_, err := vfs.lower.Lstat(absPath)
+
+	return err == nil
+}
+
+// upperExists returns true if the file absPath exists in the upper layer.
+func (vfs *OverlayFS) upperExists(absPath string) bool {
+	_, err := vfs.upper.Lstat(absPath)
+
+	return err == nil
+}
+
+// parentExists returns true if the parent directory of absPath exists in the merged view.
+func (vfs *OverlayFS) parentExists(absPath string) bool {
+	_, info, ok := vfs.lookup(vfs.Dir(absPath))
+
+	return ok && info.IsDir()
+}
+
+// isSymlink returns true if the last element of name, unevaluated, is a symbolic link in the merged view.
+func (vfs *OverlayFS) isSymlink(name string) bool {
+	absPath, _ := avfs.Abs(vfs, avfs.TrimSeparators(vfs, name), vfs.CurDir())
+	absPath, _ = vfs.resolve(absPath, false)
+	_, info, ok := vfs.lookup(absPath)
+
+	return ok && info.Mode()&fs.ModeSymlink != 0
+}
+
+// follow returns the layer, the information and the absolute path of the file absPath
+// after the evaluation of the symbolic links of the merged view.
+func (vfs *OverlayFS) follow(absPath string) (layer avfs.VFS, info fs.FileInfo, path string, err error) {
+	path, err = vfs.resolve(absPath, true)
+	if err != nil {
+		return nil, nil, path, err
+	}
+
+	layer, info, ok := vfs.lookup(path)
+	if !ok {
+		return nil, nil, path, vfs.err.NoSuchFile
+	}
+
+	return layer, info, path, nil
+}
+
+// notFound returns the error of the operation op on the file name (absolute path absPath) which does not exist.
+func (vfs *OverlayFS) notFound(op, name, absPath string) error {
+	if name == "" {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+	}
+
+	parent := vfs.Dir(absPath)
+
+	_, info, ok := vfs.lookup(parent)
+	if ok && info.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchFile}
+	}
+
+	for !ok && !vfs.isRoot(parent) {
+		parent = vfs.Dir(parent)
+		_, info, ok = vfs.lookup(parent)
+	}
+
+	if ok && !info.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+
+	return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+}
+
+// checkPath returns the error of the operation op on a path whose file was found (ok) with the information info.
+// Like the system calls, an empty path is never found and a path ending with a path separator,
+// "." or ".." can only name a directory.
+func (vfs *OverlayFS) checkPath(op, name string, info fs.FileInfo, ok bool) error {
+	if name == "" {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+	}
+
+	if ok && !info.IsDir() && avfs.IsDirPath(vfs, name) {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+	}
+
+	return nil
+}
+
+// readDir returns the entries of the directory absPath in the merged view ordered by name :
+// the entries of the upper layer and the entries of the lower layer which are not hidden.
+func (vfs *OverlayFS) readDir(absPath string) ([]fs.DirEntry, error) {
+	entries := make(map[string]fs.DirEntry)
+	whiteouts := make(map[string]bool)
+	opaque := false
+
+	upperEntries, err := vfs.upper.ReadDir(absPath)
+	if err == nil {
+		for _, e := range upperEntries {
+			name := e.Name()
+
+			switch {
+			case name == OpaqueMarker:
+				opaque = true
+			case strings.HasPrefix(name, WhiteoutPrefix):
+				whiteouts[name[len(WhiteoutPrefix):]] = true
+			default:
+				entries[name] = e
+			}
+		}
+	}
+
+	if !opaque && !vfs.lowerHidden(absPath) {
+		lowerEntries, lerr := vfs.lower.ReadDir(absPath)
+		if lerr == nil {
+			for _, e := range lowerEntries {
+				name := e.Name()
+				if _, ok := entries[name]; ok || whiteouts[name] || strings.HasPrefix(name, WhiteoutPrefix) {
+					continue
+				}
+
+				entries[name] = e
+			}
+		} else if err != nil {
+			return nil, lerr
+		}
+	}
+
+	des := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		des = append(des, e)
+	}
+
+	sort.Slice(des, func(i, j int) bool { return des[i].Name() < des[j].Name() })
+
+	return des, nil
+}
+
+// clearWhiteout removes the whiteout of the file absPath from the upper layer
+// and returns true if there was one.
+func (vfs *OverlayFS) clearWhiteout(absPath string) bool {
+	wh := vfs.Join(vfs.Dir(absPath), WhiteoutPrefix+vfs.Base(absPath))
+
+	return vfs.upper.Remove(wh) == nil
+}
+
+// whiteout hides the file absPath of the lower layer.
+func (vfs *OverlayFS) whiteout(absPath string) error {
+	wh := vfs.Join(vfs.Dir(absPath), WhiteoutPrefix+vfs.Base(absPath))
+
+	return vfs.upper.WriteFile(wh, nil, avfs.DefaultFilePerm)
+}
+
+// markOpaque hides the content of the directory absPath of the lower layer.
+func (vfs *OverlayFS) markOpaque(absPath string) error {
+	return vfs.upper.WriteFile(vfs.Join(absPath, OpaqueMarker), nil, avfs.DefaultFilePerm)
+}
+
+// copyUpDir creates in the upper layer the directory absPath and its missing parents
+// with the permissions and the modification time of the lower layer.
+func (vfs *OverlayFS) copyUpDir(absPath string) error {
+	info, err := vfs.upper.Lstat(absPath)
+	if err == nil {
+		if !info.IsDir() {
+			return vfs.err.NotADirectory
+		}
+
+		return nil
+	}
+
+	if !vfs.isRoot(absPath) {
+		if err = vfs.copyUpDir(vfs.Dir(absPath)); err != nil {
+			return err
+		}
+	}
+
+	_, info, ok := vfs.lookup(absPath)
+
+	switch {
+	case !ok:
+		return vfs.err.NoSuchDir
+	case !info.IsDir():
+		return vfs.err.NotADirectory
+	}
+
+	if err = vfs.upper.Mkdir(absPath, info.Mode().Perm()); err != nil {
+		return errors.Unwrap(err)
+	}
+
+	return vfs.copyMeta(absPath, info)
+}
+
+// copyUp copies the file absPath of the lower layer to the upper layer.
+// The content of a directory is not copied.
+func (vfs *OverlayFS) copyUp(absPath string, info fs.FileInfo) error {
+	if err := vfs.copyUpDir(vfs.Dir(absPath)); err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		return vfs.copyUpDir(absPath)
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := vfs.lower.Readlink(absPath)
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+
+		if err = vfs.upper.Symlink(target, absPath); err != nil {
+			return errors.Unwrap(err)
+		}
+
+		return vfs.copyOwner(absPath, info)
+	}
+
+	if err := vfs.copyFile(absPath); err != nil {
+		return err
+	}
+
+	return vfs.copyMeta(absPath, info)
+}
+
+// copyFile copies the content of the regular file absPath from the lower layer to the upper layer.
+func (vfs *OverlayFS) copyFile(absPath string) error {
+	src, err := vfs.lower.OpenFile(absPath, os.O_RDONLY, 0)
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+
+	defer src.Close()
+
+	dst, err := vfs.upper.OpenFile(absPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, avfs.DefaultFilePerm)
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// copyMeta copies the owner, the permissions and the modification time of a file copied up.
+func (vfs *OverlayFS) copyMeta(absPath string, info fs.FileInfo) error {
+	if err := vfs.copyOwner(absPath, info); err != nil {
+		return err
+	}
+
+	if err := vfs.upper.Chmod(absPath, info.Mode()&avfs.FileModeMask); err != nil {
+		return errors.Unwrap(err)
+	}
+
+	return errors.Unwrap(vfs.upper.Chtimes(absPath, info.ModTime(), info.ModTime()))
+}
+
+// copyOwner copies the owner of a file copied up (described by info from the lower layer).
+// Like the copy up of overlayfs which uses the credentials of the mounter, the owner is only preserved
+// if the upper layer has an identity manager and its user is an administrator.
+func (vfs *OverlayFS) copyOwner(absPath string, info fs.FileInfo) error {
+	if !vfs.upper.HasFeature(avfs.FeatIdentityMgr) || !vfs.upper.User().IsAdmin() {
+		return nil
+	}
+
+	sst := vfs.lower.ToSysStat(info)
+
+	return errors.Unwrap(vfs.upper.Lchown(absPath, sst.Uid(), sst.Gid()))
+}
+
+// copyUpTree copies the file absPath and all its descendants from the lower layer to the upper layer.
+func (vfs *OverlayFS) copyUpTree(absPath string) error {
+	layer, info, ok := vfs.lookup(absPath)
+	if !ok {
+		return vfs.err.NoSuchFile
+	}
+
+	if layer == vfs.lower {
+		if err := vfs.copyUp(absPath, info); err != nil {
+			return err
+		}
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	entries, err := vfs.readDir(absPath)
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+
+	for _, e := range entries {
+		if err = vfs.copyUpTree(vfs.Join(absPath, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pathError returns the error err of a layer with the path of the file name as presented by the caller.
+func pathError(err error, name string) error {
+	if pe, ok := err.(*fs.PathError); ok { //nolint:errorlint // Only the errors of the layers are rewritten.
+		return &fs.PathError{Op: pe.Op, Path: name, Err: pe.Err}
+	}
+
+	return err
+}
+
+// linkError returns the error err of a layer with the paths oldname and newname as presented by the caller.
+func linkError(err error, oldname, newname string) error {
+	if le, ok := err.(*os.LinkError); ok { //nolint:errorlint // Only the errors of the layers are rewritten.
+		return &os.LinkError{Op: le.Op, Old: oldname, New: newname, Err: le.Err}
+	}
+
+	return pathError(err, oldname)
+}
+
+// copyUpParent creates in the upper layer the parent directory of the file absPath.
+func (vfs *OverlayFS) copyUpParent(absPath string) error {
+	return vfs.copyUpDir(vfs.Dir(absPath))
+}
+
+// mutate copies up the file name if it only exists in the lower layer and applies the mutation fn
+// of the operation op on the upper layer, following symbolic links if follow is true.
+func (vfs *OverlayFS) mutate(op, name string, follow bool, fn func(absPath string) error) error {
+	absPath := vfs.abs(name)
+
+	vfs.mu.Lock()
+	defer vfs.mu.Unlock()
+
+	layer, info, ok := vfs.lookup(absPath)
+	if err := vfs.checkPath(op, name, info, ok); err != nil {
+		return err
+	}
+
+	if !ok {
+		return vfs.notFound(op, name, absPath)
+	}
+
+	if follow && info.Mode()&fs.ModeSymlink != 0 {
+		var err error
+
+		layer, info, absPath, err = vfs.follow(absPath)
+		if err != nil {
+			return &fs.PathError{Op: op, Path: name, Err: err}
+		}
+	}
+
+	if layer == vfs.lower {
+		if err := vfs.copyUp(absPath, info); err != nil {
+			return &fs.PathError{Op: op, Path: name, Err: err}
+		}
+	}
+
+	return pathError(fn(absPath), name)
+}
+
+// mkdir creates the directory name (absolute path absPath) in the upper layer.
+func (vfs *OverlayFS) mkdir(op, name, absPath string, perm fs.FileMode) error {
+	if name == "" {
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSuchDir}
+	}
+
+	if err := vfs.reservedName(absPath); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	if _, info, ok := vfs.lookup(absPath); ok {
+		// Like mkdir(2), a file followed by "." or ".." is not a directory.
+		if !info.IsDir() && vfs.OSType() != avfs.OsWindows && avfs.DotElem(vfs, name) != "" {
+			return &fs.PathError{Op: op, Path: name, Err: vfs.err.NotADirectory}
+		}
+
+		return &fs.PathError{Op: op, Path: name, Err: vfs.err.FileExists}
+	}
+
+	if _, info, ok := vfs.lookup(vfs.Dir(absPath)); !ok || !info.IsDir() {
+		return vfs.notFound(op, name, absPath)
+	}
+
+	if err := vfs.copyUpParent(absPath); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	whiteout := vfs.clearWhiteout(absPath)
+
+	if err := vfs.upper.Mkdir(absPath, perm); err != nil {
+		return pathError(err, name)
+	}
+
+	if whiteout {
+		if err := vfs.markOpaque(absPath); err != nil {
+			return pathError(err, name)
+		}
+	}
+
+	return nil
+}
+
+// mkdirAll creates the directory absPath and its missing parents in the upper layer.
+func (vfs *OverlayFS) mkdirAll(op, absPath string, perm fs.FileMode) error {
+	if _, info, ok := vfs.lookup(absPath); ok {
+		if info.IsDir() {
+			return nil
+		}
+
+		return &fs.PathError{Op: op, Path: absPath, Err: vfs.err.NotADirectory}
+	}
+
+	if !vfs.isRoot(absPath) {
+		if err := vfs.mkdirAll(op, vfs.Dir(absPath), perm); err != nil {
+			return err
+		}
+	}
+
+	return vfs.mkdir(op, absPath, absPath, perm)
+}
+
+// removeFile removes the file name (absolute path absPath) and its descendants from the merged view :
+// the file is removed from the upper layer if inUpper is true and whited out if it exists in the lower layer.
+func (vfs *OverlayFS) removeFile(op, name, absPath string, inUpper bool) error {
+	lowerVisible := vfs.lowerVisible(absPath)
+
+	if inUpper {
+		if err := vfs.upper.RemoveAll(absPath); err != nil {
+			return pathError(err, name)
+		}
+	}
+
+	if !lowerVisible {
+		return nil
+	}
+
+	if err := vfs.copyUpParent(absPath); err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	return pathError(vfs.whiteout(absPath), name)
+}
+
+// unwrapInfo returns the file information of a layer.
+func unwrapInfo(info fs.FileInfo) fs.FileInfo {
+	if oi, ok := info.(*OverlayInfo); ok {
+		return oi.FileInfo
+	}
+
+	return info
+}
@@ -0,0 +1,295 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package overlayfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/overlayfs"
+)
+
+var (
+	// Tests that overlayfs.OverlayFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &overlayfs.OverlayFS{}
+
+	// Tests that overlayfs.OverlayFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &overlayfs.OverlayFS{}
+
+	// Tests that overlayfs.OverlayFile struct implements avfs.File interface.
+	_ avfs.File = &overlayfs.OverlayFile{}
+)
+
+func TestOverlayFS(t *testing.T) {
+	vfs := overlayfs.New(memfs.New(), memfs.New())
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
+func TestOverlayFSLayers(t *testing.T) {
+	lower, upper := memfs.New(), memfs.New()
+
+	dir := lower.Join(lower.TempDir(), "data")
+	aFile, bFile, cFile := lower.Join(dir, "a.txt"), lower.Join(dir, "sub", "b.txt"), lower.Join(dir, "c.txt")
+
+	err := lower.MkdirAll(lower.Dir(bFile), 0o755)
+	if err != nil {
+		t.Fatalf("MkdirAll %s : want error to be nil, got %v", lower.Dir(bFile), err)
+	}
+
+	for _, name := range []string{aFile, bFile, cFile} {
+		err = lower.WriteFile(name, []byte("lower"), 0o644)
+		if err != nil {
+			t.Fatalf("WriteFile %s : want error to be nil, got %v", name, err)
+		}
+	}
+
+	vfs := overlayfs.New(lower, upper)
+
+	t.Run("CopyUp", func(t *testing.T) {
+		err = vfs.WriteFile(aFile, []byte("upper"), 0o644)
+		if err != nil {
+			t.Fatalf("WriteFile %s : want error to be nil, got %v", aFile, err)
+		}
+
+		data, _ := vfs.ReadFile(aFile)
+		if string(data) != "upper" {
+			t.Errorf("ReadFile %s : want content to be upper, got %s", aFile, data)
+		}
+
+		data, _ = lower.ReadFile(aFile)
+		if string(data) != "lower" {
+			t.Errorf("ReadFile %s : want lower content to be unchanged, got %s", aFile, data)
+		}
+
+		err = vfs.Chmod(bFile, 0o600)
+		if err != nil {
+			t.Fatalf("Chmod %s : want error to be nil, got %v", bFile, err)
+		}
+
+		info, _ := vfs.Stat(bFile)
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Stat %s : want mode to be 0o600, got %o", bFile, info.Mode().Perm())
+		}
+
+		info, _ = lower.Stat(bFile)
+		if info.Mode().Perm() != 0o644 {
+			t.Errorf("Stat %s : want lower mode to be unchanged, got %o", bFile, info.Mode().Perm())
+		}
+	})
+
+	t.Run("Whiteout", func(t *testing.T) {
+		err = vfs.Remove(cFile)
+		if err != nil {
+			t.Fatalf("Remove %s : want error to be nil, got %v", cFile, err)
+		}
+
+		_, err = vfs.Stat(cFile)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat %s : want error to be ErrNotExist, got %v", cFile, err)
+		}
+
+		_, err = lower.Stat(cFile)
+		if err != nil {
+			t.Errorf("Stat %s : want lower file to be kept, got %v", cFile, err)
+		}
+
+		whiteout := upper.Join(dir, overlayfs.WhiteoutPrefix+"c.txt")
+
+		_, err = upper.Stat(whiteout)
+		if err != nil {
+			t.Errorf("Stat %s : want whiteout to exist, got %v", whiteout, err)
+		}
+
+		names := readDirNames(t, vfs, dir)
+		if want := []string{"a.txt", "sub"}; !slices.Equal(names, want) {
+			t.Errorf("ReadDir %s : want names to be %v, got %v", dir, want, names)
+		}
+	})
+
+	t.Run("Opaque", func(t *testing.T) {
+		subDir := vfs.Dir(bFile)
+
+		err = vfs.RemoveAll(subDir)
+		if err != nil {
+			t.Fatalf("RemoveAll %s : want error to be nil, got %v", subDir, err)
+		}
+
+		err = vfs.Mkdir(subDir, 0o755)
+		if err != nil {
+			t.Fatalf("Mkdir %s : want error to be nil, got %v", subDir, err)
+		}
+
+		names := readDirNames(t, vfs, subDir)
+		if len(names) != 0 {
+			t.Errorf("ReadDir %s : want the lower files to be hidden, got %v", subDir, names)
+		}
+
+		_, err = lower.Stat(bFile)
+		if err != nil {
+			t.Errorf("Stat %s : want lower file to be kept, got %v", bFile, err)
+		}
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		patch, err := vfs.Export(dir)
+		if err != nil {
+			t.Fatalf("Export %s : want error to be nil, got %v", dir, err)
+		}
+
+		var entries []string
+		for _, entry := range patch.Entries {
+			entries = append(entries, string(entry.Op)+" "+entry.Path)
+		}
+
+		want := []string{"delete c.txt", "delete sub/b.txt", "modify a.txt"}
+		if !slices.Equal(entries, want) {
+			t.Errorf("Export : want entries to be %v, got %v", want, entries)
+		}
+
+		dst := memfs.New()
+		dstDir := dst.Join(dst.TempDir(), "data")
+
+		err = avfs.CopyDir(dst, lower, dstDir, dir, nil)
+		if err != nil {
+			t.Fatalf("CopyDir %s : want error to be nil, got %v", dir, err)
+		}
+
+		err = patch.Apply(dst, dstDir)
+		if err != nil {
+			t.Fatalf("Apply : want error to be nil, got %v", err)
+		}
+
+		diffs, err := avfs.DiffFS(vfs, dir, dst, dstDir, nil)
+		if err != nil || len(diffs) != 0 {
+			t.Errorf("DiffFS : want no difference with the merged view, got %v, %v", diffs, err)
+		}
+	})
+}
+
+func TestOverlayFSReservedNames(t *testing.T) {
+	lower, upper := memfs.New(), memfs.New()
+
+	dir := lower.Join(lower.TempDir(), "data")
+	file := lower.Join(dir, "config")
+	whiteout := lower.Join(dir, overlayfs.WhiteoutPrefix+"config")
+
+	err := lower.MkdirAll(dir, 0o755)
+	if err != nil {
+		t.Fatalf("MkdirAll %s : want error to be nil, got %v", dir, err)
+	}
+
+	err = lower.WriteFile(file, []byte("lower"), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile %s : want error to be nil, got %v", file, err)
+	}
+
+	vfs := overlayfs.New(lower, upper)
+	var wantErr error = avfs.ErrInvalidArgument
+
+	if vfs.OSType() == avfs.OsWindows {
+		wantErr = avfs.ErrWinInvalidName
+	}
+
+	err = vfs.WriteFile(whiteout, nil, 0o644)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("WriteFile %s : want error to be %v, got %v", whiteout, wantErr, err)
+	}
+
+	err = vfs.Mkdir(whiteout, 0o755)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Mkdir %s : want error to be %v, got %v", whiteout, wantErr, err)
+	}
+
+	err = vfs.Symlink(file, whiteout)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Symlink %s : want error to be %v, got %v", whiteout, wantErr, err)
+	}
+
+	err = vfs.Rename(file, whiteout)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Rename %s : want error to be %v, got %v", whiteout, wantErr, err)
+	}
+
+	_, err = vfs.Stat(file)
+	if err != nil {
+		t.Errorf("Stat %s : want lower file to be visible, got %v", file, err)
+	}
+}
+
+func TestOverlayFSCopyUpOwner(t *testing.T) {
+	lower, upper := memfs.New(), memfs.New()
+	if !upper.HasFeature(avfs.FeatIdentityMgr) {
+		t.Skip("the upper layer has no identity manager")
+	}
+
+	const uid, gid = 1001, 1002
+
+	file := lower.Join(lower.TempDir(), "owned.txt")
+
+	err := lower.WriteFile(file, []byte("lower"), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile %s : want error to be nil, got %v", file, err)
+	}
+
+	err = lower.Chown(file, uid, gid)
+	if err != nil {
+		t.Fatalf("Chown %s : want error to be nil, got %v", file, err)
+	}
+
+	vfs := overlayfs.New(lower, upper)
+
+	err = vfs.WriteFile(file, []byte("upper"), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile %s : want error to be nil, got %v", file, err)
+	}
+
+	info, err := upper.Stat(file)
+	if err != nil {
+		t.Fatalf("Stat %s : want error to be nil, got %v", file, err)
+	}
+
+	sst := upper.ToSysStat(info)
+	if sst.Uid() != uid || sst.Gid() != gid {
+		t.Errorf("Stat %s : want owner to be %d:%d, got %d:%d", file, uid, gid, sst.Uid(), sst.Gid())
+	}
+}
+
+// readDirNames returns the names of the entries of the directory dir.
+func readDirNames(t *testing.T, vfs avfs.VFS, dir string) []string {
+	t.Helper()
+
+	entries, err := vfs.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir %s : want error to be nil, got %v", dir, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	return names
+}
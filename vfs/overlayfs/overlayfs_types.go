@@ -0,0 +1,64 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package overlayfs
+
+import (
+	"io/fs"
+	"sync"
+
+	"github.com/avfs/avfs"
+)
+
+const (
+	// WhiteoutPrefix is the prefix of the name of a whiteout, an empty file of the upper layer
+	// hiding the file of the same name without the prefix in the lower layer.
+	WhiteoutPrefix = ".wh."
+
+	// OpaqueMarker is the name of the empty file of an opaque directory of the upper layer,
+	// the content of the directory of the same path in the lower layer is hidden.
+	OpaqueMarker = WhiteoutPrefix + WhiteoutPrefix + ".opq"
+)
+
+// OverlayFS implements a copy on write file system merging a read only lower layer
+// and a writable upper layer using the avfs.VFS interface.
+type OverlayFS struct {
+	lower           avfs.VFS    // lower is the read only lower layer.
+	upper           avfs.VFS    // upper is the writable upper layer.
+	err             avfs.Errors // err regroups errors depending on the OS emulated.
+	mu              sync.Mutex  // mu is the mutex used to serialize the mutations of the upper layer.
+	avfs.CurDirFn               // CurDirFn provides current directory functions to a file system.
+	avfs.FeaturesFn             // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// OverlayFile represents an open file descriptor.
+type OverlayFile struct {
+	baseFile   avfs.File     // baseFile represents an open file descriptor from the lower or the upper layer.
+	vfs        *OverlayFS    // vfs is the overlay file system of the file.
+	name       string        // name is the name of the file as presented to OpenFile.
+	absPath    string        // absPath is the absolute path of the file (used by Chdir and ReadDir).
+	dirEntries []fs.DirEntry // dirEntries stores the merged entries of a directory returned by ReadDir.
+	dirNames   []string      // dirNames stores the merged names of a directory returned by Readdirnames.
+	dirIndex   int           // dirIndex is the position of the current index for dirEntries ou dirNames slices.
+	mu         sync.Mutex    // mu is the mutex used to access dirEntries and dirNames.
+}
+
+// OverlayInfo is the fs.FileInfo returned by Stat for a symbolic link,
+// it describes the target of the link under the name of the link.
+type OverlayInfo struct {
+	fs.FileInfo        // FileInfo is the file information from the layer of the file.
+	name        string // name is the base name of the symbolic link.
+}
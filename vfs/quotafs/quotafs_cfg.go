@@ -0,0 +1,58 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package quotafs
+
+import "github.com/avfs/avfs"
+
+// New returns a new QuotaFS file system from a baseFS file system.
+func New(baseFS avfs.VFS, opts Options) *QuotaFS {
+	vfs := &QuotaFS{
+		baseFS: baseFS,
+		quota: &quota{
+			maxBytes:  opts.MaxBytes,
+			maxInodes: opts.MaxInodes,
+			bytes:     opts.UsedBytes,
+			inodes:    opts.UsedInodes,
+		},
+	}
+
+	vfs.err.SetOSType(baseFS.OSType())
+	_ = vfs.SetFeatures(baseFS.Features())
+
+	return vfs
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *QuotaFS) Name() string {
+	return vfs.baseFS.Name()
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*QuotaFS) Type() string {
+	return "QuotaFS"
+}
+
+// Usage returns the total size of the regular files and the number of files
+// (including directories and symbolic links) created through the file system.
+func (vfs *QuotaFS) Usage() (bytes, inodes int64) {
+	q := vfs.quota
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.bytes, q.inodes
+}
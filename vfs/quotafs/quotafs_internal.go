@@ -0,0 +1,175 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package quotafs
+
+import (
+	"io"
+	"io/fs"
+)
+
+// add adds bytes and inodes to the usage, it returns false without changing the usage
+// if one of the limits would be exceeded. Negative values release space and never fail,
+// the usage never falls below zero since the files existing before the creation of the file system are not counted.
+func (q *quota) add(bytes, inodes int64) bool {
+	if (bytes > 0 && q.maxBytes > 0 && q.bytes+bytes > q.maxBytes) ||
+		(inodes > 0 && q.maxInodes > 0 && q.inodes+inodes > q.maxInodes) {
+		return false
+	}
+
+	q.bytes = max(q.bytes+bytes, 0)
+	q.inodes = max(q.inodes+inodes, 0)
+
+	return true
+}
+
+// availBytes returns the number of bytes available before the limit is reached, -1 if there is no limit.
+func (q *quota) availBytes() int64 {
+	if q.maxBytes <= 0 {
+		return -1
+	}
+
+	return max(q.maxBytes-q.bytes, 0)
+}
+
+// exists returns true if the file name exists in the base file system.
+func (vfs *QuotaFS) exists(name string) bool {
+	_, err := vfs.baseFS.Lstat(name)
+
+	return err == nil
+}
+
+// missingDirs returns the number of directories of path which don't exist,
+// the number of directories created by MkdirAll(path).
+func (vfs *QuotaFS) missingDirs(path string) int64 {
+	absPath, _ := vfs.baseFS.Abs(path)
+
+	n := int64(0)
+
+	for {
+		if _, err := vfs.baseFS.Stat(absPath); err == nil {
+			return n
+		}
+
+		n++
+
+		parent := vfs.Dir(absPath)
+		if parent == absPath {
+			return n
+		}
+
+		absPath = parent
+	}
+}
+
+// released returns the number of bytes and inodes released by the removal of the file described by info.
+// The content of a regular file with other hard links is not released.
+func (vfs *QuotaFS) released(info fs.FileInfo) (bytes, inodes int64) {
+	if !info.Mode().IsRegular() {
+		return 0, 1
+	}
+
+	if vfs.baseFS.ToSysStat(info).Nlink() > 1 {
+		return 0, 0
+	}
+
+	return info.Size(), 1
+}
+
+// usageOf returns the number of bytes and inodes of the tree rooted at path,
+// symbolic links are not followed.
+func (vfs *QuotaFS) usageOf(path string) (bytes, inodes int64) {
+	_ = vfs.baseFS.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // Unreadable files are not counted.
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil //nolint:nilerr // Removed files are not counted.
+		}
+
+		b, i := vfs.released(info)
+		bytes += b
+		inodes += i
+
+		return nil
+	})
+
+	return bytes, inodes
+}
+
+// noSpace returns the error of the operation op on the file name when the quota is exceeded.
+func (vfs *QuotaFS) noSpace(op, name string) error {
+	return &fs.PathError{Op: op, Path: name, Err: vfs.err.NoSpace}
+}
+
+// write writes b to the file at the offset off (or at the current offset if at is false),
+// the bytes exceeding the quota are not written and an error is returned.
+func (f *QuotaFile) write(b []byte, off int64, at bool) (n int, err error) {
+	q := f.vfs.quota
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	write := func(b []byte) (int, error) {
+		if at {
+			return f.baseFile.WriteAt(b, off)
+		}
+
+		return f.baseFile.Write(b)
+	}
+
+	info, err := f.baseFile.Stat()
+	if err != nil {
+		return write(b)
+	}
+
+	size := info.Size()
+
+	switch {
+	case at:
+	case f.append:
+		off = size
+	default:
+		off, err = f.baseFile.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return write(b)
+		}
+	}
+
+	avail := q.availBytes()
+	if avail >= 0 && off+int64(len(b))-size > avail {
+		fit := max(size+avail-off, 0)
+		if fit == 0 {
+			return 0, f.vfs.noSpace("write", f.Name())
+		}
+
+		n, err = write(b[:fit])
+		q.add(max(off+int64(n)-size, 0), 0)
+
+		if err != nil {
+			return n, err
+		}
+
+		return n, f.vfs.noSpace("write", f.Name())
+	}
+
+	n, err = write(b)
+	q.add(max(off+int64(n)-size, 0), 0)
+
+	return n, err
+}
@@ -0,0 +1,157 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package quotafs_test
+
+import (
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/quotafs"
+)
+
+var (
+	// Tests that quotafs.QuotaFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &quotafs.QuotaFS{}
+
+	// Tests that quotafs.QuotaFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &quotafs.QuotaFS{}
+
+	// Tests that quotafs.QuotaFile struct implements avfs.File interface.
+	_ avfs.File = &quotafs.QuotaFile{}
+)
+
+func TestQuotaFS(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := quotafs.New(baseFS, quotafs.Options{})
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
+func TestQuotaFSConfig(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := quotafs.New(baseFS, quotafs.Options{UsedBytes: 10, UsedInodes: 2})
+
+	if vfs.Features() != baseFS.Features() {
+		t.Errorf("Features : want Features to be %s, got %s", baseFS.Features(), vfs.Features())
+	}
+
+	if vfs.Type() != "QuotaFS" {
+		t.Errorf("Type : want type to be QuotaFS, got %s", vfs.Type())
+	}
+
+	if bytes, inodes := vfs.Usage(); bytes != 10 || inodes != 2 {
+		t.Errorf("Usage : want usage to be 10 bytes and 2 inodes, got %d bytes and %d inodes", bytes, inodes)
+	}
+}
+
+func TestQuotaFSBytes(t *testing.T) {
+	baseFS := memfs.New()
+	dir := test.TempRoot(t, baseFS)
+	vfs := quotafs.New(baseFS, quotafs.Options{MaxBytes: 10})
+
+	path := vfs.Join(dir, "file")
+
+	f, err := vfs.Create(path)
+	test.RequireNoError(t, err, "Create %s", path)
+
+	defer f.Close()
+
+	n, err := f.Write([]byte("0123456"))
+	test.RequireNoError(t, err, "Write %s", path)
+
+	if n != 7 {
+		t.Errorf("Write : want 7 bytes written, got %d", n)
+	}
+
+	n, err = f.Write([]byte("789abc"))
+	test.AssertPathError(t, err).Op("write").Path(path).Err(avfs.ErrNoSpace).Test()
+
+	if n != 3 {
+		t.Errorf("Write : want the write to be shortened to 3 bytes, got %d", n)
+	}
+
+	_, err = f.WriteAt([]byte("AB"), 8)
+	test.RequireNoError(t, err, "WriteAt %s", path)
+
+	err = vfs.Truncate(path, 11)
+	test.AssertPathError(t, err).Op("truncate").Path(path).Err(avfs.ErrNoSpace).Test()
+
+	err = f.Truncate(4)
+	test.RequireNoError(t, err, "Truncate %s", path)
+
+	if bytes, _ := vfs.Usage(); bytes != 4 {
+		t.Errorf("Usage : want usage to be 4 bytes, got %d", bytes)
+	}
+
+	err = vfs.WriteFile(path, []byte("0123456789"), avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", path)
+
+	err = vfs.Remove(path)
+	test.RequireNoError(t, err, "Remove %s", path)
+
+	if bytes, _ := vfs.Usage(); bytes != 0 {
+		t.Errorf("Usage : want usage to be 0 bytes, got %d", bytes)
+	}
+}
+
+func TestQuotaFSInodes(t *testing.T) {
+	baseFS := memfs.New()
+	dir := test.TempRoot(t, baseFS)
+	vfs := quotafs.New(baseFS, quotafs.Options{MaxInodes: 3})
+
+	dirA := vfs.Join(dir, "a")
+	dirB := vfs.Join(dirA, "b")
+
+	err := vfs.MkdirAll(dirB, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", dirB)
+
+	file := vfs.Join(dirB, "file")
+
+	err = vfs.WriteFile(file, nil, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", file)
+
+	err = vfs.Mkdir(dirA, avfs.DefaultDirPerm)
+	test.AssertPathError(t, err).Op("mkdir").Path(dirA).Err(avfs.ErrFileExists).Test()
+
+	dirC := vfs.Join(dir, "c")
+
+	err = vfs.Mkdir(dirC, avfs.DefaultDirPerm)
+	test.AssertPathError(t, err).Op("mkdir").Path(dirC).Err(avfs.ErrNoSpace).Test()
+
+	_, err = vfs.Create(vfs.Join(dirA, "file"))
+	test.AssertPathError(t, err).Op("open").Err(avfs.ErrNoSpace).Test()
+
+	link := vfs.Join(dir, "link")
+
+	err = vfs.Symlink(dirA, link)
+	test.AssertLinkError(t, err).Op("symlink").New(link).Err(avfs.ErrNoSpace).Test()
+
+	err = vfs.RemoveAll(dirB)
+	test.RequireNoError(t, err, "RemoveAll %s", dirB)
+
+	if _, inodes := vfs.Usage(); inodes != 1 {
+		t.Errorf("Usage : want usage to be 1 inode, got %d", inodes)
+	}
+
+	err = vfs.Mkdir(dirC, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "Mkdir %s", dirC)
+}
@@ -0,0 +1,56 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package quotafs
+
+import (
+	"sync"
+
+	"github.com/avfs/avfs"
+)
+
+// QuotaFS implements a file system enforcing a maximum number of bytes and files of a base file system
+// using the avfs.VFS interface.
+type QuotaFS struct {
+	baseFS          avfs.VFS    // baseFS is the base file system.
+	quota           *quota      // quota is the usage and the limits of the file system, shared with the sub file systems.
+	err             avfs.Errors // err regroups errors depending on the OS emulated.
+	avfs.FeaturesFn             // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// QuotaFile represents an open file descriptor.
+type QuotaFile struct {
+	baseFile avfs.File // baseFile represents an open file descriptor from the base file system.
+	vfs      *QuotaFS  // vfs is the file system of the file.
+	append   bool      // append is true if the file is opened in append mode.
+}
+
+// Options defines the initialization options of QuotaFS.
+type Options struct {
+	MaxBytes   int64 // MaxBytes is the maximum total size of the regular files, no limit if zero.
+	MaxInodes  int64 // MaxInodes is the maximum number of files, directories and symbolic links, no limit if zero.
+	UsedBytes  int64 // UsedBytes is the initial total size of the regular files.
+	UsedInodes int64 // UsedInodes is the initial number of files, directories and symbolic links.
+}
+
+// quota is the usage and the limits of a file system.
+type quota struct {
+	maxBytes  int64      // maxBytes is the maximum total size of the regular files, no limit if zero.
+	maxInodes int64      // maxInodes is the maximum number of files, no limit if zero.
+	bytes     int64      // bytes is the total size of the regular files.
+	inodes    int64      // inodes is the number of files, directories and symbolic links.
+	mu        sync.Mutex // mu is the mutex serializing the mutations of the file system.
+}
@@ -0,0 +1,133 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package retryfs
+
+import (
+	"errors"
+	"time"
+
+	"github.com/avfs/avfs"
+)
+
+// DefaultMaxRetries is the default maximum number of retries of an operation.
+const DefaultMaxRetries = 3
+
+// New returns a new RetryFS file system from a baseFS file system with the default Options.
+func New(baseFS avfs.VFS) *RetryFS {
+	return NewWithOptions(baseFS, nil)
+}
+
+// NewWithOptions returns a new RetryFS file system from a baseFS file system with the selected Options.
+func NewWithOptions(baseFS avfs.VFS, opts *Options) *RetryFS {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	vfs := &RetryFS{
+		baseFS: baseFS,
+		stats:  &retryStats{},
+		opts:   *opts,
+	}
+
+	if vfs.opts.IsTransient == nil {
+		vfs.opts.IsTransient = IsTransient
+	}
+
+	if vfs.opts.MaxRetries == 0 {
+		vfs.opts.MaxRetries = DefaultMaxRetries
+	}
+
+	_ = vfs.SetFeatures(baseFS.Features())
+
+	return vfs
+}
+
+// IsTransient is the default function used to detect transient errors.
+// It returns true if err or one of the errors it wraps has a Timeout or a Temporary method returning true.
+func IsTransient(err error) bool {
+	var te interface{ Timeout() bool }
+	if errors.As(err, &te) && te.Timeout() {
+		return true
+	}
+
+	var tmp interface{ Temporary() bool }
+
+	return errors.As(err, &tmp) && tmp.Temporary()
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *RetryFS) Name() string {
+	return vfs.baseFS.Name()
+}
+
+// Stats returns the retry statistics of the file system and of its sub file systems.
+func (vfs *RetryFS) Stats() Stats {
+	return Stats{
+		Retries:         vfs.stats.retries.Load(),
+		Recovered:       vfs.stats.recovered.Load(),
+		GaveUp:          vfs.stats.gaveUp.Load(),
+		BudgetExhausted: vfs.stats.budgetExhausted.Load(),
+	}
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*RetryFS) Type() string {
+	return "RetryFS"
+}
+
+// retry calls fn until it succeeds, returns a non-transient error,
+// or exhausts the retries of the operation or the retry budget of the file system.
+func retry[T any](vfs *RetryFS, fn func() (T, error)) (T, error) {
+	res, err := fn()
+	if err == nil || !vfs.opts.IsTransient(err) {
+		return res, err
+	}
+
+	stats := vfs.stats
+	backoff := vfs.opts.Backoff
+
+	for i := 0; i < vfs.opts.MaxRetries; i++ {
+		if n := stats.retries.Add(1); vfs.opts.Budget > 0 && n > vfs.opts.Budget {
+			stats.retries.Add(-1)
+			stats.budgetExhausted.Add(1)
+
+			return res, err
+		}
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if vfs.opts.MaxBackoff > 0 && backoff > vfs.opts.MaxBackoff {
+			backoff = vfs.opts.MaxBackoff
+		}
+
+		res, err = fn()
+		if err == nil {
+			stats.recovered.Add(1)
+
+			return res, nil
+		}
+
+		if !vfs.opts.IsTransient(err) {
+			return res, err
+		}
+	}
+
+	stats.gaveUp.Add(1)
+
+	return res, err
+}
@@ -0,0 +1,146 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package retryfs_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/failfs"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/retryfs"
+)
+
+var (
+	// Tests that retryfs.RetryFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &retryfs.RetryFS{}
+
+	// Tests that retryfs.RetryFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &retryfs.RetryFS{}
+)
+
+func TestRetryFS(t *testing.T) {
+	baseFS := memfs.New()
+	vfs := retryfs.New(baseFS)
+
+	ts := test.NewSuiteFS(t, vfs, vfs)
+	ts.TestVFSAll(t)
+}
+
+// timeoutError is a transient error.
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "i/o timeout" }
+
+func (timeoutError) Timeout() bool { return true }
+
+// failStat returns a FailFunc failing the first n calls to Stat with err.
+func failStat(n int, err error) failfs.FailFunc {
+	return func(_ avfs.VFSBase, fn avfs.FnVFS, fp *failfs.FailParam) error {
+		if fn != avfs.FnStat || n <= 0 {
+			return nil
+		}
+
+		n--
+
+		return &fs.PathError{Op: fp.Op, Path: fp.Path, Err: err}
+	}
+}
+
+func TestRetryFSRetries(t *testing.T) {
+	baseFS := memfs.New()
+	path := baseFS.TempDir()
+
+	tests := []struct {
+		name      string
+		opts      retryfs.Options
+		fails     int
+		err       error
+		wantErr   bool
+		wantStats retryfs.Stats
+	}{
+		{name: "NoFailure", wantStats: retryfs.Stats{}},
+		{name: "Recovered", fails: 2, err: timeoutError{}, wantStats: retryfs.Stats{Retries: 2, Recovered: 1}},
+		{name: "GaveUp", fails: 10, err: timeoutError{}, wantErr: true, wantStats: retryfs.Stats{Retries: 3, GaveUp: 1}},
+		{name: "NotTransient", fails: 1, err: avfs.ErrPermDenied, wantErr: true, wantStats: retryfs.Stats{}},
+		{
+			name: "Budget", opts: retryfs.Options{Budget: 1}, fails: 10, err: timeoutError{}, wantErr: true,
+			wantStats: retryfs.Stats{Retries: 1, BudgetExhausted: 1},
+		},
+		{
+			name: "CustomTransient", fails: 1, err: avfs.ErrPermDenied,
+			opts:      retryfs.Options{IsTransient: func(err error) bool { return true }},
+			wantStats: retryfs.Stats{Retries: 1, Recovered: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failFS := failfs.New(baseFS)
+			_ = failFS.SetFailFunc(failStat(tt.fails, tt.err))
+
+			vfs := retryfs.NewWithOptions(failFS, &tt.opts)
+
+			_, err := vfs.Stat(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Stat : want error to be %t, got %v", tt.wantErr, err)
+			}
+
+			if stats := vfs.Stats(); stats != tt.wantStats {
+				t.Errorf("Stats : want %+v, got %+v", tt.wantStats, stats)
+			}
+		})
+	}
+}
+
+func TestRetryFSWriteNotRetried(t *testing.T) {
+	baseFS := memfs.New()
+	failFS := failfs.New(baseFS)
+
+	_ = failFS.SetFailFunc(func(_ avfs.VFSBase, fn avfs.FnVFS, fp *failfs.FailParam) error {
+		if fn == avfs.FnOpenFile {
+			return &fs.PathError{Op: fp.Op, Path: fp.Path, Err: timeoutError{}}
+		}
+
+		return nil
+	})
+
+	vfs := retryfs.New(failFS)
+	path := vfs.Join(vfs.TempDir(), "file")
+
+	_, err := vfs.Create(path)
+	if err == nil {
+		t.Errorf("Create : want error, got nil")
+	}
+
+	if stats := vfs.Stats(); stats.Retries != 0 {
+		t.Errorf("Stats : want no retry for a write, got %+v", stats)
+	}
+
+	_, err = vfs.Open(path)
+	if err == nil {
+		t.Errorf("Open : want error, got nil")
+	}
+
+	if stats := vfs.Stats(); stats.Retries != retryfs.DefaultMaxRetries {
+		t.Errorf("Stats : want %d retries for a read, got %+v", retryfs.DefaultMaxRetries, stats)
+	}
+}
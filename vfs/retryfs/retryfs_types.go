@@ -0,0 +1,57 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package retryfs
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/avfs/avfs"
+)
+
+// RetryFS implements a file system retrying idempotent operations on transient errors using the avfs.VFS interface.
+type RetryFS struct {
+	baseFS          avfs.VFS    // baseFS is the base file system.
+	stats           *retryStats // stats are the retry statistics, shared with the sub file systems.
+	opts            Options     // opts are the retry options.
+	avfs.FeaturesFn             // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// Options defines the retry options of RetryFS.
+type Options struct {
+	IsTransient func(err error) bool // IsTransient returns true if err is a transient error (default IsTransient).
+	MaxRetries  int                  // MaxRetries is the maximum number of retries of an operation (default 3).
+	Budget      int64                // Budget is the maximum number of retries of the file system, 0 for unlimited.
+	Backoff     time.Duration        // Backoff is the delay before the first retry, doubled after each retry.
+	MaxBackoff  time.Duration        // MaxBackoff is the maximum delay between two retries, 0 for unlimited.
+}
+
+// Stats are the retry statistics of a RetryFS file system.
+type Stats struct {
+	Retries         int64 // Retries is the number of retries.
+	Recovered       int64 // Recovered is the number of operations which succeeded after at least one retry.
+	GaveUp          int64 // GaveUp is the number of operations still failing with a transient error after their retries.
+	BudgetExhausted int64 // BudgetExhausted is the number of retries prevented by an exhausted retry budget.
+}
+
+// retryStats are the retry statistics updated concurrently.
+type retryStats struct {
+	retries         atomic.Int64
+	recovered       atomic.Int64
+	gaveUp          atomic.Int64
+	budgetExhausted atomic.Int64
+}
@@ -445,6 +445,12 @@ func (vfs *RoFS) SetUserByName(name string) error {
 	return avfs.ErrPermDenied
 }
 
+// SetUserByUid sets the current user by user id and primary group id.
+// If no user of the identity manager matches uid and gid, an ephemeral user is used.
+func (vfs *RoFS) SetUserByUid(uid, gid int) error {
+	return avfs.ErrPermDenied
+}
+
 // Split splits path immediately following the final Separator,
 // separating it into a directory and file name component.
 // If there is no Separator in path, Split returns an empty dir
@@ -461,8 +467,14 @@ func (vfs *RoFS) Stat(name string) (fs.FileInfo, error) {
 }
 
 // Sub returns an FS corresponding to the subtree rooted at dir.
+// The sub file system is read only like the file system.
 func (vfs *RoFS) Sub(dir string) (avfs.VFS, error) {
-	return vfs.baseFS.Sub(dir)
+	subFS, err := vfs.baseFS.Sub(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(subFS), nil
 }
 
 // Symlink creates newname as a symbolic link to oldname.
@@ -19,6 +19,8 @@
 package rofs_test
 
 import (
+	"errors"
+	"io/fs"
 	"testing"
 
 	"github.com/avfs/avfs"
@@ -71,3 +73,22 @@ func TestRoFSConfig(t *testing.T) {
 		t.Errorf("OSType : want os type to be %v, got %v", vfsWrite.OSType(), osType)
 	}
 }
+
+func TestRoFSSub(t *testing.T) {
+	vfsWrite := memfs.New()
+	vfs := rofs.New(vfsWrite)
+
+	dir := test.TempRoot(t, vfsWrite)
+
+	sub, err := vfs.Sub(dir)
+	test.RequireNoError(t, err, "Sub %s", dir)
+
+	if !sub.HasFeature(avfs.FeatReadOnly) {
+		t.Errorf("Sub : want sub file system to be read only, got features %s", sub.Features())
+	}
+
+	err = sub.Mkdir("/dir", avfs.DefaultDirPerm)
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Mkdir : want error to be ErrPermission, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package verifyfs
+
+import (
+	"crypto/sha256"
+	"hash"
+	"io/fs"
+
+	"github.com/avfs/avfs"
+)
+
+// New returns a new VerifyFS file system from a base file system and a manifest with the default Options.
+func New(baseFS avfs.VFS, manifest Manifest) *VerifyFS {
+	return NewWithOptions(baseFS, manifest, nil)
+}
+
+// NewWithOptions returns a new VerifyFS file system from a base file system and a manifest with the selected Options.
+// The paths of the manifest are converted to absolute paths of the base file system with their symbolic links evaluated.
+func NewWithOptions(baseFS avfs.VFS, manifest Manifest, opts *Options) *VerifyFS {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	vfs := &VerifyFS{
+		baseFS:            baseFS,
+		manifest:          make(Manifest, len(manifest)),
+		opts:              *opts,
+		errOpNotPermitted: avfs.ErrOpNotPermitted,
+		errPermDenied:     avfs.ErrPermDenied,
+	}
+
+	if vfs.opts.NewHash == nil {
+		vfs.opts.NewHash = sha256.New
+	}
+
+	for path, sum := range manifest {
+		realPath, err := vfs.realPath(path)
+		if err != nil {
+			// A missing file keeps its absolute path, it fails to be opened anyway.
+			realPath, _ = baseFS.Abs(path)
+		}
+
+		vfs.manifest[realPath] = sum
+	}
+
+	_ = vfs.SetFeatures(baseFS.Features()&^(avfs.FeatIdentityMgr|avfs.FeatSubFS) | avfs.FeatReadOnly)
+
+	if baseFS.OSType() == avfs.OsWindows {
+		vfs.errOpNotPermitted = avfs.ErrWinNotSupported
+		vfs.errPermDenied = avfs.ErrWinAccessDenied
+	}
+
+	return vfs
+}
+
+// BuildManifest returns the manifest of the regular files of the directory tree rooted at root.
+// If newHash is nil, sha256.New is used.
+func BuildManifest(vfs avfs.VFSBase, root string, newHash func() hash.Hash) (Manifest, error) {
+	if newHash == nil {
+		newHash = sha256.New
+	}
+
+	h := newHash()
+	manifest := make(Manifest)
+
+	err := vfs.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		sum, err := avfs.HashFile(vfs, path, h)
+		if err != nil {
+			return err
+		}
+
+		realPath, err := realPath(vfs, path)
+		if err != nil {
+			return err
+		}
+
+		manifest[realPath] = sum
+
+		return nil
+	})
+
+	return manifest, err
+}
+
+// realPath returns the absolute path of path with its symbolic links evaluated.
+func realPath(vfs avfs.VFSBase, path string) (string, error) {
+	absPath, err := vfs.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	return vfs.EvalSymlinks(absPath)
+}
+
+// realPath returns the absolute path of path in the base file system with its symbolic links evaluated.
+func (vfs *VerifyFS) realPath(path string) (string, error) {
+	return realPath(vfs.baseFS, path)
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *VerifyFS) Name() string {
+	return vfs.baseFS.Name()
+}
+
+// OSType returns the operating system type of the file system.
+func (vfs *VerifyFS) OSType() avfs.OSType {
+	return vfs.baseFS.OSType()
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*VerifyFS) Type() string {
+	return "VerifyFS"
+}
@@ -0,0 +1,159 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package verifyfs_test
+
+import (
+	"crypto/sha512"
+	"testing"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/test"
+	"github.com/avfs/avfs/vfs/memfs"
+	"github.com/avfs/avfs/vfs/verifyfs"
+)
+
+var (
+	// Tests that verifyfs.VerifyFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &verifyfs.VerifyFS{}
+
+	// Tests that verifyfs.VerifyFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &verifyfs.VerifyFS{}
+
+	// Tests that verifyfs.VerifyFile struct implements avfs.File interface.
+	_ avfs.File = &verifyfs.VerifyFile{}
+)
+
+func initTest(t *testing.T) *test.Suite {
+	vfsSetup := memfs.New()
+	vfs := verifyfs.New(vfsSetup, nil)
+
+	ts := test.NewSuiteFS(t, vfsSetup, vfs)
+
+	return ts
+}
+
+func TestVerifyFS(t *testing.T) {
+	ts := initTest(t)
+	ts.TestVFSAll(t)
+}
+
+func TestVerifyFSConfig(t *testing.T) {
+	vfsWrite := memfs.New()
+	vfs := verifyfs.New(vfsWrite, nil)
+
+	wantFeatures := vfsWrite.Features()&^(avfs.FeatIdentityMgr|avfs.FeatSubFS) | avfs.FeatReadOnly
+	if vfs.Features() != wantFeatures {
+		t.Errorf("Features : want Features to be %s, got %s", wantFeatures, vfs.Features())
+	}
+
+	name := vfs.Name()
+	if name != "" {
+		t.Errorf("Name : want name to be empty, got %v", name)
+	}
+
+	osType := vfs.OSType()
+	if osType != vfsWrite.OSType() {
+		t.Errorf("OSType : want os type to be %v, got %v", vfsWrite.OSType(), osType)
+	}
+}
+
+func TestVerifyFSManifest(t *testing.T) {
+	vfsWrite := memfs.New()
+	dir := vfsWrite.Join(vfsWrite.TempDir(), "manifest")
+	goodFile := vfsWrite.Join(dir, "good")
+	badFile := vfsWrite.Join(dir, "bad")
+	newFile := vfsWrite.Join(dir, "new")
+	content := []byte("content")
+
+	err := vfsWrite.MkdirAll(dir, avfs.DefaultDirPerm)
+	test.RequireNoError(t, err, "MkdirAll %s", dir)
+
+	for _, name := range []string{goodFile, badFile} {
+		err = vfsWrite.WriteFile(name, content, avfs.DefaultFilePerm)
+		test.RequireNoError(t, err, "WriteFile %s", name)
+	}
+
+	manifest, err := verifyfs.BuildManifest(vfsWrite, dir, sha512.New)
+	test.RequireNoError(t, err, "BuildManifest %s", dir)
+
+	if len(manifest) != 2 {
+		t.Fatalf("BuildManifest : want %d files, got %d", 2, len(manifest))
+	}
+
+	err = vfsWrite.WriteFile(badFile, []byte("tampered"), avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", badFile)
+
+	err = vfsWrite.WriteFile(newFile, content, avfs.DefaultFilePerm)
+	test.RequireNoError(t, err, "WriteFile %s", newFile)
+
+	vfs := verifyfs.NewWithOptions(vfsWrite, manifest, &verifyfs.Options{NewHash: sha512.New})
+
+	t.Run("VerifyFSGoodFile", func(t *testing.T) {
+		buf, err := vfs.ReadFile(goodFile)
+		test.RequireNoError(t, err, "ReadFile %s", goodFile)
+
+		if string(buf) != string(content) {
+			t.Errorf("ReadFile : want content to be %s, got %s", content, buf)
+		}
+	})
+
+	t.Run("VerifyFSBadFile", func(t *testing.T) {
+		_, err := vfs.Open(badFile)
+		test.AssertPathError(t, err).Op("open").Path(badFile).Err(avfs.ErrIntegrity).Test()
+
+		_, err = vfs.ReadFile(badFile)
+		test.AssertPathError(t, err).Op("open").Path(badFile).Err(avfs.ErrIntegrity).Test()
+	})
+
+	t.Run("VerifyFSSymlink", func(t *testing.T) {
+		fileLink := vfsWrite.Join(vfsWrite.TempDir(), "fileLink")
+		dirLink := vfsWrite.Join(vfsWrite.TempDir(), "dirLink")
+
+		err = vfsWrite.Symlink(badFile, fileLink)
+		test.RequireNoError(t, err, "Symlink %s", fileLink)
+
+		err = vfsWrite.Symlink(dir, dirLink)
+		test.RequireNoError(t, err, "Symlink %s", dirLink)
+
+		for _, alias := range []string{fileLink, vfsWrite.Join(dirLink, "bad")} {
+			_, err = vfs.ReadFile(alias)
+			test.AssertPathError(t, err).Op("open").Path(alias).Err(avfs.ErrIntegrity).Test()
+		}
+	})
+
+	t.Run("VerifyFSDir", func(t *testing.T) {
+		f, err := vfs.Open(dir)
+		test.RequireNoError(t, err, "Open %s", dir)
+
+		_ = f.Close()
+	})
+
+	t.Run("VerifyFSNotInManifest", func(t *testing.T) {
+		_, err := vfs.ReadFile(newFile)
+		test.RequireNoError(t, err, "ReadFile %s", newFile)
+
+		vfsStrict := verifyfs.NewWithOptions(vfsWrite, manifest, &verifyfs.Options{NewHash: sha512.New, Strict: true})
+
+		_, err = vfsStrict.ReadFile(newFile)
+		test.AssertPathError(t, err).Op("open").Path(newFile).Err(avfs.ErrIntegrity).Test()
+
+		_, err = vfsStrict.ReadFile(goodFile)
+		test.RequireNoError(t, err, "ReadFile %s", goodFile)
+	})
+}
@@ -0,0 +1,48 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package verifyfs
+
+import (
+	"hash"
+
+	"github.com/avfs/avfs"
+)
+
+// VerifyFS Represents the file system.
+type VerifyFS struct {
+	baseFS            avfs.VFS // baseFS is the base file system.
+	manifest          Manifest // manifest are the expected hash sums of the files.
+	opts              Options  // opts are the options of the file system.
+	errOpNotPermitted error    // errOpNotPermitted is the error operation not permitted from the base file system.
+	errPermDenied     error    // errPermDenied is the error permission denied from the base file system.
+	avfs.FeaturesFn            // FeaturesFn provides features functions to a file system or an identity manager.
+}
+
+// VerifyFile represents an open file descriptor.
+type VerifyFile struct {
+	baseFile avfs.File // baseFile represents an open file descriptor from the base file system.
+	vfs      *VerifyFS // vfs is the verified file system of the file.
+}
+
+// Manifest maps the absolute paths of files to their expected hash sums.
+type Manifest map[string][]byte
+
+// Options defines the initialization options of VerifyFS.
+type Options struct {
+	NewHash func() hash.Hash // NewHash returns the hash function of the manifest (default sha256.New).
+	Strict  bool             // Strict rejects regular files which are not in the manifest.
+}
@@ -0,0 +1,78 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package zipfs
+
+import (
+	"archive/zip"
+
+	"github.com/avfs/avfs"
+)
+
+// New returns a new read only file system (ZipFS) over the zip archive r with the default Options.
+func New(r *zip.Reader) *ZipFS {
+	return NewWithOptions(r, nil)
+}
+
+// NewWithOptions returns a new read only file system (ZipFS) over the zip archive r with the selected Options.
+// The archive is indexed once, the content of its files is only uncompressed when they are read.
+func NewWithOptions(r *zip.Reader, opts *Options) *ZipFS {
+	if opts == nil {
+		opts = &Options{OSType: avfs.OsUnknown}
+	}
+
+	idm := avfs.NotImplementedIdm
+
+	vfs := &ZipFS{
+		errOpNotPermitted: avfs.ErrOpNotPermitted,
+		errPermDenied:     avfs.ErrPermDenied,
+		name:              opts.Name,
+	}
+
+	_ = vfs.SetFeatures(avfs.FeatReadOnly | avfs.BuildFeatures())
+	_ = vfs.SetOSType(opts.OSType)
+	_ = vfs.SetIdm(idm)
+	_ = vfs.SetUser(idm.AdminUser())
+
+	vfs.err.SetOSType(vfs.OSType())
+
+	volumeName := ""
+	curDir := "/"
+
+	if vfs.OSType() == avfs.OsWindows {
+		vfs.errOpNotPermitted = avfs.ErrWinNotSupported
+		vfs.errPermDenied = avfs.ErrWinAccessDenied
+		volumeName = avfs.DefaultVolume
+		curDir = volumeName + string(vfs.PathSeparator())
+	}
+
+	vfs.buildNodes(r, volumeName)
+
+	_ = vfs.SetCurDir(curDir)
+	_ = vfs.SetUMask(avfs.UMask())
+
+	return vfs
+}
+
+// Name returns the name of the fileSystem.
+func (vfs *ZipFS) Name() string {
+	return vfs.name
+}
+
+// Type returns the type of the fileSystem or Identity manager.
+func (*ZipFS) Type() string {
+	return "ZipFS"
+}
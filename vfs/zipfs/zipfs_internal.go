@@ -0,0 +1,207 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package zipfs
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/avfs/avfs"
+)
+
+// buildNodes indexes the entries of the archive r, the root directory is in the volume volumeName.
+// Like archive/zip, entries with an invalid name are ignored, missing parent directories are created
+// and a directory takes precedence over a file of the same name.
+func (vfs *ZipFS) buildNodes(r *zip.Reader, volumeName string) {
+	root := volumeName + string(vfs.PathSeparator())
+	lastId := uint64(1)
+
+	vfs.nodes = nodes{root: {id: lastId, mode: fs.ModeDir | 0o555}}
+
+	if r == nil {
+		return
+	}
+
+	for _, zf := range r.File {
+		name := strings.TrimSuffix(zf.Name, "/")
+		if name == "." || !fs.ValidPath(name) {
+			continue
+		}
+
+		isDir := zf.FileInfo().IsDir()
+		parent := vfs.nodes[root]
+		absPath := root
+
+		for elems := strings.Split(name, "/"); len(elems) > 0; elems = elems[1:] {
+			elem := elems[0]
+			absPath = vfs.Join(absPath, elem)
+			last := len(elems) == 1
+
+			nd, ok := vfs.nodes[absPath]
+			if !ok || (!nd.mode.IsDir() && (!last || isDir)) {
+				lastId++
+				nd = &node{id: lastId, mode: fs.ModeDir | 0o555}
+
+				vfs.nodes[absPath] = nd
+				parent.addChild(elem, nd)
+
+				ok = false
+			}
+
+			if last && (!ok || isDir) {
+				nd.setEntry(zf, isDir)
+			}
+
+			parent = nd
+		}
+	}
+}
+
+// addChild adds a child to a node.
+func (nd *node) addChild(name string, child *node) {
+	if nd.children == nil {
+		nd.children = make(map[string]*node)
+	}
+
+	nd.children[name] = child
+}
+
+// setEntry sets the metadata of a node from the archive entry zf.
+// Only the permissions of the entry are kept, any other file type (symbolic link, device...)
+// is read as a regular file.
+func (nd *node) setEntry(zf *zip.File, isDir bool) {
+	nd.mtime = zf.Modified.UnixNano()
+	nd.mode = zf.Mode() & avfs.FileModeMask
+
+	if isDir {
+		nd.mode |= fs.ModeDir
+
+		return
+	}
+
+	nd.children = nil
+	nd.file = zf
+	nd.size = int64(zf.UncompressedSize64)
+}
+
+// checkPath returns the error of a path whose node nd was found or not (ok) by its absolute path.
+// Like the system calls, an empty path is never found and a path ending with a path separator,
+// "." or ".." can only name a directory.
+func (vfs *ZipFS) checkPath(path string, nd *node, ok bool) error {
+	if path == "" {
+		return vfs.err.NoSuchDir
+	}
+
+	if ok && !nd.mode.IsDir() && avfs.IsDirPath(vfs, path) {
+		return vfs.err.NotADirectory
+	}
+
+	return nil
+}
+
+// lookup returns the node of the file or directory path or the error of the operation op.
+func (vfs *ZipFS) lookup(path, op string) (*node, string, error) {
+	absPath, _ := vfs.Abs(path)
+	nd, ok := vfs.nodes[absPath]
+
+	if err := vfs.checkPath(path, nd, ok); err != nil {
+		return nil, absPath, &fs.PathError{Op: op, Path: path, Err: err}
+	}
+
+	if ok {
+		return nd, absPath, nil
+	}
+
+	parent, ok := vfs.nodes[vfs.Dir(absPath)]
+
+	switch {
+	case !ok:
+		return nil, absPath, &fs.PathError{Op: op, Path: path, Err: vfs.err.NoSuchDir}
+	case parent.mode.IsDir():
+		return nil, absPath, &fs.PathError{Op: op, Path: path, Err: vfs.err.NoSuchFile}
+	default:
+		return nil, absPath, &fs.PathError{Op: op, Path: path, Err: vfs.err.NotADirectory}
+	}
+}
+
+// fillStatFrom returns a ZipInfo (implementation of fs.FileInfo) from a node named name.
+func (nd *node) fillStatFrom(name string) *ZipInfo {
+	size := nd.size
+	if nd.mode.IsDir() {
+		size = int64(len(nd.children))
+	}
+
+	return &ZipInfo{
+		id:    nd.id,
+		name:  name,
+		size:  size,
+		mode:  nd.mode,
+		mtime: nd.mtime,
+	}
+}
+
+// dirEntries returns a slice of fs.DirEntry from a directory ordered by name.
+func (nd *node) dirEntries() []fs.DirEntry {
+	l := len(nd.children)
+	if l == 0 {
+		return nil
+	}
+
+	entries := make([]fs.DirEntry, 0, l)
+	for name, child := range nd.children {
+		entries = append(entries, child.fillStatFrom(name))
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries
+}
+
+// dirNames returns a slice of file names from a directory ordered by name.
+func (nd *node) dirNames() []string {
+	l := len(nd.children)
+	if l == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, l)
+	for name := range nd.children {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// readData returns the uncompressed content of the file.
+func (nd *node) readData() ([]byte, error) {
+	rc, err := nd.file.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(rc)
+	if cerr := rc.Close(); err == nil {
+		err = cerr
+	}
+
+	return data, err
+}
@@ -0,0 +1,298 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+//go:build !avfs_race
+
+package zipfs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/avfs/avfs"
+	"github.com/avfs/avfs/vfs/zipfs"
+)
+
+var (
+	// Tests that zipfs.ZipFS struct implements avfs.VFS interface.
+	_ avfs.VFS = &zipfs.ZipFS{}
+
+	// Tests that zipfs.ZipFS struct implements avfs.VFSBase interface.
+	_ avfs.VFSBase = &zipfs.ZipFS{}
+
+	// Tests that zipfs.ZipFile struct implements avfs.File interface.
+	_ avfs.File = &zipfs.ZipFile{}
+
+	// Tests that zipfs.ZipInfo struct implements avfs.InodeStater interface.
+	_ avfs.InodeStater = &zipfs.ZipInfo{}
+)
+
+// zipEntry is an entry of a test archive, a directory if its name ends with a slash.
+type zipEntry struct {
+	name    string
+	content string
+}
+
+// newZipFS returns a ZipFS over an archive containing the entries.
+func newZipFS(tb testing.TB, entries []zipEntry) *zipfs.ZipFS {
+	tb.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	mtime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, e := range entries {
+		fh := &zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: mtime}
+		fh.SetMode(0o644)
+
+		if e.name[len(e.name)-1] == '/' {
+			fh.SetMode(fs.ModeDir | 0o755)
+		}
+
+		w, err := zw.CreateHeader(fh)
+		if err != nil {
+			tb.Fatalf("CreateHeader %s : want error to be nil, got %v", e.name, err)
+		}
+
+		_, err = w.Write([]byte(e.content))
+		if err != nil {
+			tb.Fatalf("Write %s : want error to be nil, got %v", e.name, err)
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		tb.Fatalf("Close : want error to be nil, got %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		tb.Fatalf("NewReader : want error to be nil, got %v", err)
+	}
+
+	return zipfs.NewWithOptions(zr, &zipfs.Options{Name: "zip", OSType: avfs.OsLinux})
+}
+
+var testEntries = []zipEntry{ //nolint:gochecknoglobals // Test data.
+	{name: "dir/"},
+	{name: "dir/file.txt", content: "hello zip"},
+	{name: "implicit/sub/deep.txt", content: "deep"},
+	{name: "root.txt", content: "root"},
+	{name: "../invalid.txt", content: "ignored"},
+	{name: "conflict", content: "a file replaced by a directory"},
+	{name: "conflict/child.txt", content: "child"},
+}
+
+func TestZipFSConfig(t *testing.T) {
+	vfs := newZipFS(t, nil)
+
+	if !vfs.HasFeature(avfs.FeatReadOnly) {
+		t.Errorf("Features : want FeatReadOnly to be set, got %s", vfs.Features())
+	}
+
+	if vfs.Name() != "zip" {
+		t.Errorf("Name : want name to be %s, got %s", "zip", vfs.Name())
+	}
+
+	if vfs.Type() != "ZipFS" {
+		t.Errorf("Type : want type to be %s, got %s", "ZipFS", vfs.Type())
+	}
+
+	if vfs.OSType() != avfs.OsLinux {
+		t.Errorf("OSType : want os type to be %v, got %v", avfs.OsLinux, vfs.OSType())
+	}
+
+	info, err := vfs.Stat("/")
+	if err != nil || !info.IsDir() {
+		t.Errorf("Stat : want root to be a directory, got %v, %v", info, err)
+	}
+
+	entries, err := zipfs.New(nil).ReadDir("/")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("ReadDir : want an empty root directory, got %v, %v", entries, err)
+	}
+}
+
+func TestZipFSRead(t *testing.T) {
+	vfs := newZipFS(t, testEntries)
+
+	t.Run("ReadFile", func(t *testing.T) {
+		for _, e := range []zipEntry{
+			{name: "/dir/file.txt", content: "hello zip"},
+			{name: "/implicit/sub/deep.txt", content: "deep"},
+			{name: "/conflict/child.txt", content: "child"},
+			{name: "root.txt", content: "root"},
+		} {
+			data, err := vfs.ReadFile(e.name)
+			if err != nil {
+				t.Errorf("ReadFile %s : want error to be nil, got %v", e.name, err)
+			}
+
+			if string(data) != e.content {
+				t.Errorf("ReadFile %s : want content to be %q, got %q", e.name, e.content, data)
+			}
+		}
+	})
+
+	t.Run("ReadDir", func(t *testing.T) {
+		entries, err := vfs.ReadDir("/")
+		if err != nil {
+			t.Fatalf("ReadDir : want error to be nil, got %v", err)
+		}
+
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+
+		wantNames := []string{"conflict", "dir", "implicit", "root.txt"}
+		if !slices.Equal(names, wantNames) {
+			t.Errorf("ReadDir : want names to be %v, got %v", wantNames, names)
+		}
+
+		if !entries[0].IsDir() {
+			t.Errorf("ReadDir : want %s to be a directory", entries[0].Name())
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := vfs.Stat("/dir/file.txt")
+		if err != nil {
+			t.Fatalf("Stat : want error to be nil, got %v", err)
+		}
+
+		if info.Name() != "file.txt" || info.Size() != int64(len("hello zip")) || info.Mode() != 0o644 {
+			t.Errorf("Stat : want file.txt, %d, %s, got %s, %d, %s",
+				len("hello zip"), fs.FileMode(0o644), info.Name(), info.Size(), info.Mode())
+		}
+
+		wantTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		if !info.ModTime().Equal(wantTime) {
+			t.Errorf("Stat : want modification time to be %v, got %v", wantTime, info.ModTime())
+		}
+
+		info, err = vfs.Stat("/implicit/sub")
+		if err != nil || !info.IsDir() {
+			t.Errorf("Stat : want implicit directory, got %v, %v", info, err)
+		}
+
+		_, err = vfs.Stat("/invalid.txt")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat : want error to be %v, got %v", fs.ErrNotExist, err)
+		}
+
+		_, err = vfs.Stat("/root.txt/")
+		if !errors.Is(err, avfs.ErrNotADirectory) {
+			t.Errorf("Stat : want error to be %v, got %v", avfs.ErrNotADirectory, err)
+		}
+
+		fi1, _ := vfs.Stat("/dir")
+		fi2, _ := vfs.Lstat("dir/.")
+
+		if !vfs.SameFile(fi1, fi2) {
+			t.Errorf("SameFile : want same file to be true, got false")
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		err := vfs.Chdir("/dir")
+		if err != nil {
+			t.Fatalf("Chdir : want error to be nil, got %v", err)
+		}
+
+		f, err := vfs.Open("file.txt")
+		if err != nil {
+			t.Fatalf("Open : want error to be nil, got %v", err)
+		}
+
+		defer f.Close()
+
+		pos, err := f.Seek(-3, io.SeekEnd)
+		if err != nil || pos != 6 {
+			t.Errorf("Seek : want position to be 6, got %d, %v", pos, err)
+		}
+
+		b := make([]byte, 10)
+
+		n, err := f.Read(b)
+		if err != nil || string(b[:n]) != "zip" {
+			t.Errorf("Read : want %q, got %q, %v", "zip", b[:n], err)
+		}
+
+		n, err = f.ReadAt(b[:5], 0)
+		if err != nil || string(b[:n]) != "hello" {
+			t.Errorf("ReadAt : want %q, got %q, %v", "hello", b[:n], err)
+		}
+
+		_, err = f.Write(b)
+		if !errors.Is(err, avfs.ErrPermDenied) {
+			t.Errorf("Write : want error to be %v, got %v", avfs.ErrPermDenied, err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			t.Errorf("Close : want error to be nil, got %v", err)
+		}
+
+		_, err = f.Read(b)
+		if !errors.Is(err, fs.ErrClosed) {
+			t.Errorf("Read : want error to be %v, got %v", fs.ErrClosed, err)
+		}
+	})
+}
+
+func TestZipFSReadOnly(t *testing.T) {
+	vfs := newZipFS(t, testEntries)
+
+	const name = "/root.txt"
+
+	for _, tc := range []struct {
+		op string
+		fn func() error
+	}{
+		{op: "Chmod", fn: func() error { return vfs.Chmod(name, 0o777) }},
+		{op: "Chtimes", fn: func() error { return vfs.Chtimes(name, time.Now(), time.Now()) }},
+		{op: "Mkdir", fn: func() error { return vfs.Mkdir("/new", 0o755) }},
+		{op: "Remove", fn: func() error { return vfs.Remove(name) }},
+		{op: "RemoveAll", fn: func() error { return vfs.RemoveAll("/dir") }},
+		{op: "Rename", fn: func() error { return vfs.Rename(name, "/new") }},
+		{op: "Truncate", fn: func() error { return vfs.Truncate(name, 0) }},
+		{op: "WriteFile", fn: func() error { return vfs.WriteFile("/new", nil, 0o644) }},
+		{op: "OpenFile", fn: func() error {
+			_, err := vfs.OpenFile(name, os.O_RDWR, 0)
+
+			return err
+		}},
+	} {
+		err := tc.fn()
+		if !errors.Is(err, avfs.ErrPermDenied) {
+			t.Errorf("%s : want error to be %v, got %v", tc.op, avfs.ErrPermDenied, err)
+		}
+	}
+
+	err := vfs.Chown(name, 0, 0)
+	if !errors.Is(err, avfs.ErrOpNotPermitted) {
+		t.Errorf("Chown : want error to be %v, got %v", avfs.ErrOpNotPermitted, err)
+	}
+}
@@ -0,0 +1,83 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package zipfs
+
+import (
+	"archive/zip"
+	"io/fs"
+	"sync"
+
+	"github.com/avfs/avfs"
+)
+
+// ZipFS implements a read only file system over a zip archive using the avfs.VFS interface.
+type ZipFS struct {
+	nodes             nodes       // nodes is the map of nodes (files or directories) where the key is the absolute path.
+	err               avfs.Errors // err regroups errors depending on the OS emulated.
+	errOpNotPermitted error       // errOpNotPermitted is the error operation not permitted depending on the OS emulated.
+	errPermDenied     error       // errPermDenied is the error permission denied depending on the OS emulated.
+	name              string      // name is the name of the file system.
+	avfs.CurDirFn                 // CurDirFn provides current directory functions to a file system.
+	avfs.CurUserFn                // CurUserFn provides current user functions to a file system.
+	avfs.IdmFn                    // IdmFn provides identity manager functions to a file system.
+	avfs.UMaskFn                  // UMaskFn provides UMask functions to file systems.
+	avfs.FeaturesFn               // FeaturesFn provides features functions to a file system or an identity manager.
+	avfs.OSTypeFn                 // OSTypeFn provides OS type functions to a file system or an identity manager.
+}
+
+// ZipFile represents an open file descriptor.
+type ZipFile struct {
+	vfs        *ZipFS        // vfs is the zip file system of the file.
+	nd         *node         // nd is node of the file.
+	name       string        // name is the name of the file.
+	absPath    string        // absPath is the absolute path of the file when it was opened (used by Chdir).
+	data       []byte        // data is the uncompressed content of the file, read from the archive by the first read.
+	dirEntries []fs.DirEntry // dirEntries stores the file information returned by ReadDir function.
+	dirNames   []string      // dirNames stores the names of the file returned by Readdirnames function.
+	at         int64         // at is current position in the file used by Read function.
+	dirIndex   int           // dirIndex is the position of the current index for dirEntries ou dirNames slices.
+	loaded     bool          // loaded is true if the content of the file has been read from the archive.
+	mu         sync.Mutex    // mu is the Mutex used to access content of ZipFile.
+}
+
+// Options defines the initialization options of ZipFS.
+type Options struct {
+	Name   string      // Name is the name of the file system.
+	OSType avfs.OSType // OSType defines the operating system type.
+}
+
+// nodes is the map of nodes (files or directories) where the key is the absolute path.
+type nodes map[string]*node
+
+// node is a file or a directory of the archive, nodes are never modified once the file system is created.
+type node struct {
+	children map[string]*node // children are the files and directories of a directory, indexed by name.
+	file     *zip.File        // file is the archive entry of a file, nil for a directory.
+	id       uint64           // id is a unique id to identify a file (used by SameFile function).
+	mtime    int64            // mtime is the modification time.
+	size     int64            // size is the uncompressed size of a file.
+	mode     fs.FileMode      // mode represents a file's mode and permission bits.
+}
+
+// ZipInfo is the implementation of fs.DirEntry (returned by ReadDir) and fs.FileInfo (returned by Stat and Lstat).
+type ZipInfo struct {
+	name  string      // name is the name of the file.
+	id    uint64      // id is a unique id to identify a file (used by SameFile function).
+	size  int64       // size is the size of the file.
+	mtime int64       // mtime is the modification time.
+	mode  fs.FileMode // mode represents a file's mode and permission bits.
+}
@@ -24,6 +24,3 @@ import _ "unsafe" // for go:linkname only.
 //
 //go:linkname nextRandom os.nextRandom
 func nextRandom() string
-
-//go:linkname volumeNameLen path/filepath.volumeNameLen
-func volumeNameLen(path string) int
@@ -157,5 +157,5 @@ func ToSlash[T VFSBase](_ T, path string) string {
 // VolumeNameLen returns length of the leading volume name on Windows.
 // It returns 0 elsewhere.
 func VolumeNameLen[T VFSBase](_ T, path string) int {
-	return volumeNameLen(path)
+	return len(filepath.VolumeName(path))
 }
@@ -28,6 +28,7 @@ const (
 	DefaultName     = "Default"          // DefaultName is the default name.
 	DefaultVolume   = "C:"               // DefaultVolume is the default volume name for Windows.
 	NotImplemented  = "not implemented"  // NotImplemented is the return string of a non-implemented feature.
+	TempMaxTries    = 10000              // TempMaxTries is the number of names tried by CreateTemp and MkdirTemp.
 
 	// FileModeMask is the bitmask used for permissions.
 	FileModeMask = fs.ModePerm | fs.ModeSticky | fs.ModeSetuid | fs.ModeSetgid
@@ -42,7 +43,7 @@ type Cloner interface {
 // ChRooter is the interface that wraps the Chroot method.
 type ChRooter interface {
 	// Chroot changes the root to that specified in path.
-	// If the user has not root privileges avfs.errPermDenied is returned.
+	// If the user has not root privileges avfs.ErrOpNotPermitted is returned.
 	// If there is an error, it will be of type *PathError.
 	Chroot(path string) error
 }
@@ -66,6 +67,9 @@ type File interface {
 
 	// Chdir changes the current working directory to the file,
 	// which must be a directory.
+	// The new current directory is the directory as it was resolved when the file was opened,
+	// even if it was opened with a relative path and the current directory changed since.
+	// Emulated file systems support it for all OS types, OsFS on Windows returns ErrWinNotSupported.
 	// If there is an error, it will be of type *PathError.
 	Chdir() error
 
@@ -113,11 +117,312 @@ type File interface {
 	Truncate(size int64) error
 }
 
+// FileLocker is the optional interface implemented by file systems supporting advisory locks
+// on open files, like flock on Linux or LockFileEx on Windows.
+// A lock belongs to the open file : other files opened on the same path (even by the same process)
+// conflict with it, locking an already locked file converts its lock, closing the file releases it.
+// Advisory locks only prevent other locks, they don't prevent reading or writing the file.
+type FileLocker interface {
+	// Lock places an exclusive lock on the open file f, waiting until no other file holds a lock.
+	// If there is an error, it will be of type *PathError.
+	Lock(f File) error
+
+	// RLock places a shared lock on the open file f, waiting until no other file holds an exclusive lock.
+	// If there is an error, it will be of type *PathError.
+	RLock(f File) error
+
+	// TryLock tries to place an exclusive lock on the open file f without waiting,
+	// it returns false if another file holds a lock.
+	// If there is an error, it will be of type *PathError.
+	TryLock(f File) (bool, error)
+
+	// Unlock removes the lock held by the open file f.
+	// If there is an error, it will be of type *PathError.
+	Unlock(f File) error
+}
+
+// DeviceStater is the optional interface implemented by the SysStater of file systems
+// reporting the device number of the device files.
+type DeviceStater interface {
+	// Rdev returns the device number of a device file, 0 for other files.
+	Rdev() uint64
+}
+
+// InodeStater is the optional interface implemented by the SysStater of file systems
+// identifying files by device and inode.
+type InodeStater interface {
+	// Dev returns the device id of the file system containing the file.
+	Dev() uint64
+
+	// Ino returns the inode number of the file.
+	Ino() uint64
+}
+
+// Lchtimer is the interface that wraps the Lchtimes method.
+type Lchtimer interface {
+	// Lchtimes changes the access and modification times of the named file like Chtimes,
+	// but if the file is a symbolic link, it changes the times of the link itself and not of its target.
+	// A zero time.Time value leaves the corresponding file time unchanged.
+	// If there is an error, it will be of type *PathError.
+	Lchtimes(name string, atime, mtime time.Time) error
+}
+
+// MetadataSet selects the metadata changed by a MetadataOp.
+type MetadataSet uint8
+
+const (
+	MetadataOwner    MetadataSet = 1 << iota // MetadataOwner changes the owner of the file (Uid and Gid).
+	MetadataMode                             // MetadataMode changes the mode of the file (Mode).
+	MetadataTimes                            // MetadataTimes changes the access and modification times of the file (Atime and Mtime).
+	MetadataNoFollow                         // MetadataNoFollow changes the owner and the times of a symbolic link instead of its target.
+)
+
+// MetadataOp is a change of the metadata of a file applied by ApplyMetadata.
+type MetadataOp struct {
+	Atime time.Time   // Atime is the access time of the file.
+	Mtime time.Time   // Mtime is the modification time of the file.
+	Path  string      // Path is the path of the file.
+	Uid   int         // Uid is the user id of the owner, -1 to keep it.
+	Gid   int         // Gid is the group id of the owner, -1 to keep it.
+	Mode  fs.FileMode // Mode is the mode of the file.
+	Set   MetadataSet // Set selects the metadata to change.
+}
+
+// MetadataApplier is the optional interface implemented by file systems applying
+// a batch of metadata changes faster than individual calls to Chown, Chmod and Chtimes.
+type MetadataApplier interface {
+	// ApplyMetadata applies the metadata changes of ops (see avfs.ApplyMetadata).
+	ApplyMetadata(ops []MetadataOp) error
+}
+
+// Mknoder is the interface that wraps the Mknod method.
+type Mknoder interface {
+	// Mknod creates the special file name with the type and the permissions of mode :
+	// a block device (fs.ModeDevice), a character device (fs.ModeDevice | fs.ModeCharDevice),
+	// a named pipe (fs.ModeNamedPipe), a socket (fs.ModeSocket) or an empty regular file if mode has no type.
+	// dev is the device number of a device file (see DeviceStater), it is ignored for the other types.
+	// If there is an error, it will be of type *PathError.
+	Mknod(name string, mode fs.FileMode, dev uint64) error
+}
+
 // Namer is the interface that wraps the Name method.
 type Namer interface {
 	Name() string
 }
 
+// NetworkDriveMapper is the interface that maps network shares to drive letters for Windows file systems.
+type NetworkDriveMapper interface {
+	// MapNetworkDrive maps the network share unc (like `\\server\share`) to the drive drive (like "Z:"),
+	// like the "net use" command. The share is created if it doesn't exist.
+	// If there is an error, it will be of type *PathError.
+	MapNetworkDrive(drive, unc string) error
+
+	// NetworkDrives returns the mapped network drives and their network shares.
+	NetworkDrives() map[string]string
+
+	// UniversalName returns the UNC form of the absolute path of path if path lies on a mapped network drive,
+	// or the absolute path of path otherwise.
+	// If there is an error, it will be of type *PathError.
+	UniversalName(path string) (string, error)
+
+	// UnmapNetworkDrive removes the mapping of a network drive. The files of the network share are not deleted.
+	// If there is an error, it will be of type *PathError.
+	UnmapNetworkDrive(drive string) error
+}
+
+// RenameFlag modifies the behavior of Rename2, the values are those of renameat2 on Linux.
+type RenameFlag uint8
+
+const (
+	RenameNoReplace RenameFlag = 1 << iota // RenameNoReplace fails if newpath already exists (RENAME_NOREPLACE).
+	RenameExchange                         // RenameExchange atomically exchanges oldpath and newpath (RENAME_EXCHANGE).
+)
+
+// Renamer2 is the interface that wraps the Rename2 method.
+type Renamer2 interface {
+	// Rename2 renames (moves) oldpath to newpath like Rename, flags modifies its behavior :
+	// with RenameNoReplace it fails if newpath already exists,
+	// with RenameExchange it atomically exchanges oldpath and newpath, both of them must exist.
+	// RenameNoReplace and RenameExchange can't be combined.
+	// If there is an error, it will be of type *LinkError.
+	Rename2(oldpath, newpath string, flags RenameFlag) error
+}
+
+// ACLType is the type of a POSIX access control list (ACL).
+type ACLType uint8
+
+const (
+	ACLAccess  ACLType = iota + 1 // ACLAccess is the access ACL of a file (ACL_TYPE_ACCESS).
+	ACLDefault                    // ACLDefault is the default ACL of a directory, inherited by the files created in it (ACL_TYPE_DEFAULT).
+)
+
+// ACLTag is the tag type of an entry of a POSIX ACL.
+type ACLTag uint8
+
+const (
+	ACLUserObj  ACLTag = iota + 1 // ACLUserObj is the entry of the owner of the file (ACL_USER_OBJ).
+	ACLUser                       // ACLUser is the entry of a named user identified by its uid (ACL_USER).
+	ACLGroupObj                   // ACLGroupObj is the entry of the group of the file (ACL_GROUP_OBJ).
+	ACLGroup                      // ACLGroup is the entry of a named group identified by its gid (ACL_GROUP).
+	ACLMask                       // ACLMask limits the permissions granted by the entries of the group class (ACL_MASK).
+	ACLOther                      // ACLOther is the entry of the other users (ACL_OTHER).
+)
+
+// ACLEntry is an entry of a POSIX ACL.
+type ACLEntry struct {
+	Tag  ACLTag   // Tag is the tag type of the entry.
+	Id   int      // Id is the uid or the gid of the entry for ACLUser and ACLGroup, unused otherwise.
+	Perm OpenMode // Perm is a combination of OpenRead, OpenWrite and OpenLookup permissions.
+}
+
+// ACLManager is the interface that manages POSIX ACLs of Linux file systems.
+type ACLManager interface {
+	// GetACL returns the ACL of type typ of the named file.
+	// The access ACL of a file without extended entries is the minimal ACL derived from its permission bits,
+	// the default ACL of a directory without default ACL is nil.
+	// If there is an error, it will be of type *PathError.
+	GetACL(name string, typ ACLType) ([]ACLEntry, error)
+
+	// SetACL sets the ACL of type typ of the named file.
+	// A valid ACL contains exactly one ACLUserObj, ACLGroupObj and ACLOther entry,
+	// an ACLMask entry is required if the ACL contains ACLUser or ACLGroup entries.
+	// Like Linux, the permission bits of the file reflect the access ACL :
+	// the owner bits are the ACLUserObj entry, the group bits are the ACLMask entry (or the ACLGroupObj entry
+	// without mask) and the other bits are the ACLOther entry. Chmod changes these entries.
+	// A nil default ACL removes the default ACL of the directory.
+	// Only the owner of the file or an administrator can set an ACL.
+	// If there is an error, it will be of type *PathError.
+	SetACL(name string, typ ACLType, acl []ACLEntry) error
+}
+
+// AceType is the type of access control entry (ACE).
+type AceType uint8
+
+const (
+	AceAllow AceType = iota + 1 // AceAllow grants the access rights of the entry to its trustee.
+	AceDeny                     // AceDeny denies the access rights of the entry to its trustee.
+)
+
+// TrusteeType is the type of the trustee of an access control entry.
+type TrusteeType uint8
+
+const (
+	TrusteeUser     TrusteeType = iota + 1 // TrusteeUser is a user identified by its uid.
+	TrusteeGroup                           // TrusteeGroup is a group identified by its gid.
+	TrusteeEveryone                        // TrusteeEveryone is any user.
+)
+
+// ACE is an access control entry of a discretionary access control list (DACL).
+type ACE struct {
+	Type        AceType     // Type is the type of the entry (AceAllow or AceDeny).
+	TrusteeType TrusteeType // TrusteeType is the type of the trustee of the entry.
+	Id          int         // Id is the uid or the gid of the trustee, unused for TrusteeEveryone.
+	Mask        OpenMode    // Mask is a combination of OpenRead, OpenWrite and OpenLookup access rights.
+}
+
+// SecurityInfo is a minimal model of a Windows security descriptor : an owner and a DACL.
+type SecurityInfo struct {
+	Dacl  []ACE // Dacl is the discretionary access control list, entries are evaluated in order.
+	Owner int   // Owner is the uid of the owner of the file.
+}
+
+// SecurityManager is the interface that manages security descriptors of Windows file systems.
+type SecurityManager interface {
+	// GetSecurityInfo returns the security descriptor of the named file.
+	// If the file has no explicit DACL, the returned DACL is derived from the permission bits of the file.
+	// If there is an error, it will be of type *PathError.
+	GetSecurityInfo(name string) (*SecurityInfo, error)
+
+	// SetSecurityInfo sets the owner and the DACL of the named file.
+	// Access checks then evaluate the entries of the DACL matching the user in order :
+	// a deny entry matching one of the requested rights not granted yet denies the access,
+	// the access is granted as soon as the allow entries grant all the requested rights,
+	// so an allow entry listed before a deny entry takes precedence.
+	// A nil DACL removes the explicit DACL, access checks then use the permission bits of the file.
+	// Only the owner of the file or an administrator can set the DACL, only an administrator can change the owner.
+	// If there is an error, it will be of type *PathError.
+	SetSecurityInfo(name string, si *SecurityInfo) error
+}
+
+// ShortPathNamer is the interface that wraps the ShortPathName method.
+type ShortPathNamer interface {
+	// ShortPathName returns the DOS 8.3 short form of the named path (Windows only).
+	// Short names are also accepted wherever a path is expected.
+	// If there is an error, it will be of type *PathError.
+	ShortPathName(path string) (string, error)
+}
+
+// SizeEvent describes a change of the size of a watched file.
+type SizeEvent struct {
+	Name    string // Name is the name of the file as presented to WatchSize.
+	OldSize int64  // OldSize is the size of the file before the change.
+	NewSize int64  // NewSize is the size of the file after the change.
+}
+
+// SizeWatcher is the interface that wraps the WatchSize method.
+type SizeWatcher interface {
+	// WatchSize subscribes to the size changes of the named file.
+	// Each Write, WriteAt or Truncate modifying the size of the file sends a SizeEvent
+	// on the returned channel until cancel is called.
+	// Like inotify, the subscription follows the file and not its name :
+	// a renamed file still sends events, a new file created with the same name does not.
+	// If the receiver is too slow, the oldest pending events are dropped.
+	// If there is an error, it will be of type *PathError.
+	WatchSize(name string) (events <-chan SizeEvent, cancel func(), err error)
+}
+
+// WatchOp is a set of operations reported by a Watcher.
+type WatchOp uint8
+
+const (
+	WatchCreate WatchOp = 1 << iota // WatchCreate is the creation of a file, a directory or a link, or the new name of a renamed file.
+	WatchWrite                      // WatchWrite is a write or a truncation of a file.
+	WatchRemove                     // WatchRemove is the removal of a file or a directory.
+	WatchRename                     // WatchRename is the old name of a renamed file or directory.
+	WatchChmod                      // WatchChmod is a change of the metadata of a file (permissions, owner, times or ACL).
+)
+
+// WatchEvent describes a change of a watched file or of a file in a watched directory.
+type WatchEvent struct {
+	Name string  // Name is the watched path, or the watched directory joined with the name of the changed file.
+	Op   WatchOp // Op is the operation.
+}
+
+// Watcher watches the changes of files and directories.
+type Watcher interface {
+	// Add starts watching the named file or directory.
+	// The changes of a directory and of its direct children are reported, the subdirectories are not watched.
+	// If there is an error, it will be of type *PathError.
+	Add(name string) error
+
+	// Close removes all the watches and closes the Events and Errors channels.
+	Close() error
+
+	// Errors returns the channel receiving the errors of the watcher, like a lost event.
+	Errors() <-chan error
+
+	// Events returns the channel receiving the changes of the watched files and directories.
+	Events() <-chan WatchEvent
+
+	// Remove stops watching the named file or directory.
+	// If there is an error, it will be of type *PathError.
+	Remove(name string) error
+}
+
+// WatcherMaker is the interface that wraps the NewWatcher method.
+type WatcherMaker interface {
+	// NewWatcher returns a new Watcher without any watched file.
+	NewWatcher() (Watcher, error)
+}
+
+// StreamLister is the interface that wraps the Streams method.
+type StreamLister interface {
+	// Streams returns the sorted names of the alternate data streams of the named file (Windows only).
+	// A stream is opened, read, written and removed with the name "file:stream".
+	// If there is an error, it will be of type *PathError.
+	Streams(name string) ([]string, error)
+}
+
 // SysStater is the interface returned by ToSysStat on all file systems.
 type SysStater interface {
 	GroupIdentifier
@@ -139,6 +444,25 @@ type VolumeManager interface {
 	VolumeList() []string
 }
 
+// VolumeInfoManager is the interface that manage the metadata of the volumes of Windows file systems.
+type VolumeInfoManager interface {
+	// VolumeInfo returns the metadata of a volume of a Windows file system.
+	// If there is an error, it will be of type *PathError.
+	VolumeInfo(name string) (*VolumeInfo, error)
+
+	// VolumeSetInfo sets the label, the file system type and the serial number of a volume of a Windows file system.
+	// If there is an error, it will be of type *PathError.
+	VolumeSetInfo(name string, info *VolumeInfo) error
+}
+
+// VolumeInfo contains the metadata of a volume.
+type VolumeInfo struct {
+	Name   string // Name is the name of the volume (like "C:").
+	Label  string // Label is the label of the volume.
+	FSType string // FSType is the file system type of the volume (like "NTFS").
+	Serial uint32 // Serial is the serial number of the volume.
+}
+
 // OpenMode defines constants used by OpenFile and CheckPermission functions.
 type OpenMode uint16
 
@@ -150,6 +474,7 @@ const (
 	OpenCreate                          // OpenCreate creates a file (os.O_CREATE).
 	OpenCreateExcl                      // OpenCreateExcl creates a non existing file (os.O_EXCL).
 	OpenTruncate                        // OpenTruncate truncates a file (os.O_TRUNC).
+	OpenTmpFile                         // OpenTmpFile creates an unnamed temporary file (O_TMPFILE).
 )
 
 // IOFS is the virtual file system interface implementing io/fs interfaces.
@@ -324,6 +649,9 @@ type VFSBase interface {
 	// Glob ignores file system errors such as I/O errors reading directories.
 	// The only possible returned error is ErrBadPattern, when pattern
 	// is malformed.
+	//
+	// The matches are sorted in lexical order of their path elements,
+	// implementations must keep this order (see avfs.Glob).
 	Glob(pattern string) (matches []string, err error)
 
 	// Idm returns the identity manager of the file system.
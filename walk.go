@@ -0,0 +1,194 @@
+//
+//  Copyright 2026 The AVFS authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//  	http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+//
+
+package avfs
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// WalkType is a bitmask of the types of files passed to the function of Walk.
+type WalkType uint8
+
+const (
+	WalkTypeRegular WalkType = 1 << iota // WalkTypeRegular selects regular files.
+	WalkTypeDir                          // WalkTypeDir selects directories.
+	WalkTypeSymlink                      // WalkTypeSymlink selects symbolic links (not followed).
+	WalkTypeOther                        // WalkTypeOther selects other files (devices, named pipes, sockets...).
+)
+
+// WalkOptions defines the options of Walk.
+type WalkOptions struct {
+	// MaxDepth is the maximum depth of the walked files, root being at depth 0.
+	// The directories at MaxDepth are not read. There is no limit if MaxDepth <= 0.
+	MaxDepth int
+
+	// TypesMask selects the types of the files passed to the function, all types if 0.
+	// Directories not selected are still walked.
+	TypesMask WalkType
+
+	// FollowSymlinks follows the symbolic links, a link to a directory being walked like a directory.
+	// Links to one of their parent directories and dangling links are not followed.
+	FollowSymlinks bool
+
+	// SkipHidden skips the files and directories whose name starts with a dot, except root.
+	SkipHidden bool
+}
+
+// Walk walks the file tree rooted at root like WalkDir, calling fn for each file or directory
+// selected by opts, opts can be nil to use the default options.
+//
+// Like WalkDir, fn can return fs.SkipDir to skip a directory or the remaining files of a directory,
+// or fs.SkipAll to stop the walk.
+// Pruned directories (beyond MaxDepth or hidden) are never read, skipped files are never stat-ed.
+func Walk[T VFSBase](vfs T, root string, opts *WalkOptions, fn fs.WalkDirFunc) error {
+	w := &walker[T]{vfs: vfs, fn: fn}
+	if opts != nil {
+		w.opts = *opts
+	}
+
+	info, err := vfs.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walk(root, &statDirEntry{info}, 0)
+	}
+
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+
+	return err
+}
+
+// walker holds the state of Walk.
+type walker[T VFSBase] struct {
+	vfs     T
+	fn      fs.WalkDirFunc
+	opts    WalkOptions
+	parents []fs.FileInfo // parents are the infos of the directories being walked, used to detect symlink loops.
+}
+
+// walk recursively descends path at the given depth, calling fn for the selected files.
+func (w *walker[T]) walk(path string, d fs.DirEntry, depth int) error {
+	if w.opts.FollowSymlinks && d.Type()&fs.ModeSymlink != 0 {
+		d = w.follow(path, d)
+	}
+
+	if w.selected(d) {
+		if err := w.fn(path, d, nil); err != nil || !d.IsDir() {
+			if err == fs.SkipDir && d.IsDir() {
+				err = nil
+			}
+
+			return err
+		}
+	} else if !d.IsDir() {
+		return nil
+	}
+
+	if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+		return nil
+	}
+
+	dirs, err := ReadDir(w.vfs, path)
+	if err != nil {
+		// Second call, to report ReadDir error.
+		err = w.fn(path, d, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.opts.FollowSymlinks {
+		info, err := d.Info()
+		if err != nil {
+			return w.fn(path, d, err)
+		}
+
+		w.parents = append(w.parents, info)
+		defer func() { w.parents = w.parents[:len(w.parents)-1] }()
+	}
+
+	for _, d1 := range dirs {
+		if w.opts.SkipHidden && strings.HasPrefix(d1.Name(), ".") {
+			continue
+		}
+
+		if err := w.walk(Join(w.vfs, path, d1.Name()), d1, depth+1); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// follow returns the directory entry of the target of the symbolic link path,
+// or d if the link is dangling or points to one of the directories being walked.
+func (w *walker[T]) follow(path string, d fs.DirEntry) fs.DirEntry {
+	info, err := w.vfs.Stat(path)
+	if err != nil {
+		return d
+	}
+
+	if info.IsDir() {
+		for _, parent := range w.parents {
+			if w.vfs.SameFile(parent, info) {
+				return d
+			}
+		}
+	}
+
+	return &followedDirEntry{name: d.Name(), info: info}
+}
+
+// selected reports whether the type of d is selected by TypesMask.
+func (w *walker[T]) selected(d fs.DirEntry) bool {
+	if w.opts.TypesMask == 0 {
+		return true
+	}
+
+	var wt WalkType
+
+	switch t := d.Type(); {
+	case t.IsRegular():
+		wt = WalkTypeRegular
+	case t.IsDir():
+		wt = WalkTypeDir
+	case t&fs.ModeSymlink != 0:
+		wt = WalkTypeSymlink
+	default:
+		wt = WalkTypeOther
+	}
+
+	return w.opts.TypesMask&wt != 0
+}
+
+// followedDirEntry is the directory entry of the target of a followed symbolic link.
+type followedDirEntry struct {
+	name string
+	info fs.FileInfo
+}
+
+func (d *followedDirEntry) Name() string               { return d.name }
+func (d *followedDirEntry) IsDir() bool                { return d.info.IsDir() }
+func (d *followedDirEntry) Type() fs.FileMode          { return d.info.Mode().Type() }
+func (d *followedDirEntry) Info() (fs.FileInfo, error) { return d.info, nil }